@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// commandUsage describes the non-interactive subcommands
+const commandUsage = `Commands:
+  list [--json] [--tag T]
+                         List tunnels with their status
+  status <name|id>       Show a tunnel's status (exit 0 running, 1 stopped, 2 error)
+  start [--tags T,...] <name|id>...
+                         Start tunnels, including those with any of the tags
+  stop [--tags T,...] <name|id>...
+                         Stop tunnels, including those with any of the tags
+  restart <name|id>...   Restart tunnels
+  add --name N --host H -L|-R|-D SPEC...
+                         Add a tunnel
+  edit <name|id> [flags] Change a tunnel's settings
+  rm <name|id>...        Remove tunnels
+  show [--command] <name|id>
+                         Show a tunnel and the ssh command it runs, without starting it
+  export [--profile P]   Write tunnel definitions and profile settings as JSON
+  export --format ssh [--write] [name|id...]
+                         Write tunnels as ~/.ssh/config Host blocks, or into its tunnelman section
+  import [--merge|--replace] [--conflict skip|overwrite|rename] [--profile P] FILE
+                         Add tunnel definitions from an export, optionally into profile P
+  import --putty [--profile P] [SESSION...]
+                         Add the port forwardings of PuTTY saved sessions as tunnels
+  import --ssh-config [--profile P] [HOST]
+                         Import ~/.ssh/config forwards again, updating and removing old imports
+  teleport ls|add        List Teleport nodes with tsh, or add tunnels to them
+  fwd [-R|-D] SPEC HOST  Run an unsaved tunnel in the foreground until Ctrl-C
+  prune [-adopt] [-kill] Remove stale PID entries and adopt or kill untracked ssh processes
+  logs [-f] <name|id>    Show a tunnel's captured ssh output
+  keys                   List ssh-agent keys and key files for --identity
+  secret set|get|rm NAME Store, print or remove a keychain secret for --secret
+  config encrypt|decrypt|status
+                         Encrypt the config file with a keychain key, or decrypt it
+  config storage json|sqlite [DATABASE]
+                         Keep tunnels in the config file or a SQLite database
+  config history         List the saved versions of the config
+  config rollback [N|ID] Restore a saved version, or undo the last change
+  validate [FILE]        Check the config, or an export, for problems (exit 1 if any)
+  wait <name|id>...      Block until tunnels accept connections (--timeout, --profile)
+  exec --tunnel N -- CMD Run CMD with tunnels up, then stop them
+  supervise [--profile P]
+                         Keep a profile's tunnels running, restarting them with backoff
+  service install|uninstall|start|stop|status [--profile P]
+                         Run supervise for a profile as a background service
+  daemon                 Run in the background, owning tunnels for other commands
+  serve [--listen ADDR]  Serve the REST control API (default 127.0.0.1:7070)
+  grpc [--listen ADDR]   Serve the gRPC control API (default 127.0.0.1:7071)
+  completion bash|zsh|fish
+                         Print a shell completion script
+
+list, status, start, stop and restart go through the daemon when it is running.
+start, stop and restart exit with 1 if a tunnel fails and 3 if one is unknown;
+starting a running tunnel or stopping a stopped one is not a failure.
+`
+
+// lifecycleExitUnknown is the exit code of start, stop and restart when a
+// tunnel does not exist, matching the status command
+const lifecycleExitUnknown = statusExitUnknown
+
+// runCommand dispatches a non-interactive subcommand and returns the process exit code
+func runCommand(tunnelManager *core.TunnelManager, configStore *store.ConfigStore, args []string) int {
+	switch args[0] {
+	case "list", "ls":
+		return cmdList(newController(tunnelManager), args[1:])
+	case "status":
+		return cmdStatus(newController(tunnelManager), args[1:])
+	case "start", "stop", "restart":
+		return cmdLifecycle(newController(tunnelManager), args[0], args[1:])
+	case "add":
+		return cmdAdd(tunnelManager, args[1:])
+	case "edit":
+		return cmdEdit(tunnelManager, args[1:])
+	case "rm", "remove":
+		return cmdRemove(tunnelManager, args[1:])
+	case "show":
+		return cmdShow(tunnelManager, args[1:])
+	case "keys":
+		return cmdKeys(args[1:])
+	case "secret":
+		return cmdSecret(args[1:])
+	case "config":
+		return cmdConfig(configStore, args[1:])
+	case "validate":
+		return cmdValidate(configStore, args[1:])
+	case "logs":
+		return cmdLogs(tunnelManager, args[1:])
+	case "export":
+		return cmdExport(tunnelManager, args[1:])
+	case "import":
+		return cmdImport(tunnelManager, args[1:])
+	case "teleport":
+		return cmdTeleport(tunnelManager, args[1:])
+	case "wait":
+		return cmdWait(tunnelManager, args[1:])
+	case "exec":
+		return cmdExec(tunnelManager, args[1:])
+	case "fwd":
+		return cmdFwd(args[1:])
+	case "supervise":
+		return cmdSupervise(tunnelManager, args[1:])
+	case "service":
+		return cmdService(configStore, args[1:])
+	case "daemon":
+		return cmdDaemon(tunnelManager, args[1:])
+	case "serve":
+		return cmdServe(tunnelManager, configStore, args[1:])
+	case "grpc":
+		return cmdGRPC(tunnelManager, args[1:])
+	case "completion":
+		return cmdCompletion(args[1:])
+	case "__complete":
+		return cmdComplete(tunnelManager, args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", args[0])
+		fmt.Fprint(os.Stderr, commandUsage)
+		return 2
+	}
+}
+
+// cmdLifecycle applies a start/stop/restart action to each named tunnel and,
+// for start and stop, each tunnel with one of the given tags
+func cmdLifecycle(ctrl controller, action string, args []string) int {
+	fs := flag.NewFlagSet(action, flag.ContinueOnError)
+	var tags *string
+	if action != "restart" {
+		tags = fs.String("tags", "", "Also "+action+" tunnels with any of these comma-separated tags")
+	}
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	if tags != nil && *tags != "" {
+		tagged, err := taggedTunnels(ctrl, core.ParseTags(*tags))
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		if len(tagged) == 0 {
+			core.Error("No tunnels tagged %s", *tags)
+			return lifecycleExitUnknown
+		}
+		names = append(names, tagged...)
+	}
+
+	if len(names) == 0 {
+		if tags != nil {
+			fmt.Fprintf(os.Stderr, "Usage: tunnelman %s [--tags T,...] <name|id>...\n", action)
+		} else {
+			fmt.Fprintf(os.Stderr, "Usage: tunnelman %s <name|id>...\n", action)
+		}
+		return 2
+	}
+
+	fn := ctrl.Start
+	switch action {
+	case "stop":
+		fn = ctrl.Stop
+	case "restart":
+		fn = ctrl.Restart
+	}
+
+	exitCode := 0
+	for _, name := range names {
+		tunnel, err := fn(name)
+
+		// Asking for the state a tunnel is already in is not a failure
+		if action == "start" && errors.Is(err, core.ErrAlreadyRunning) ||
+			action == "stop" && errors.Is(err, core.ErrNotRunning) {
+			if tunnel.Name != "" {
+				name = tunnel.Name
+			}
+			fmt.Printf("%s: %v\n", name, err)
+			continue
+		}
+
+		if err != nil {
+			if tunnel.Name != "" {
+				core.Error("Failed to %s tunnel %s: %v", action, tunnel.Name, err)
+			} else {
+				core.Error("%v", err)
+			}
+			if tunnel.Hint != "" {
+				fmt.Fprintf(os.Stderr, "  hint: %s\n", tunnel.Hint)
+			}
+			if errors.Is(err, core.ErrTunnelNotFound) {
+				exitCode = lifecycleExitUnknown
+			} else if exitCode == 0 {
+				exitCode = 1
+			}
+			continue
+		}
+
+		if tunnel.PID > 0 {
+			fmt.Printf("%s: %s (PID %d)\n", pastTense(action), tunnel.Name, tunnel.PID)
+		} else {
+			fmt.Printf("%s: %s\n", pastTense(action), tunnel.Name)
+		}
+	}
+
+	return exitCode
+}
+
+// taggedTunnels returns the IDs of the tunnels with any of tags
+func taggedTunnels(ctrl controller, tags []string) ([]string, error) {
+	tunnels, err := ctrl.List("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tunnels: %w", err)
+	}
+
+	var ids []string
+	for _, t := range tunnels {
+		for _, tag := range tags {
+			if slices.Contains(t.Tags, tag) {
+				ids = append(ids, t.ID)
+				break
+			}
+		}
+	}
+	return ids, nil
+}
+
+// pastTense returns the past tense of a lifecycle action for reporting
+func pastTense(action string) string {
+	switch action {
+	case "start":
+		return "Started"
+	case "stop":
+		return "Stopped"
+	case "restart":
+		return "Restarted"
+	default:
+		return action
+	}
+}
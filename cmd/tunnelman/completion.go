@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// commandInfo names a subcommand for shell completion
+type commandInfo struct {
+	name        string
+	description string
+	tunnelArgs  bool
+}
+
+// completionCommands lists the subcommands offered by shell completion
+var completionCommands = []commandInfo{
+	{"list", "List tunnels with their status", false},
+	{"status", "Show a tunnel's status", true},
+	{"start", "Start tunnels", true},
+	{"stop", "Stop tunnels", true},
+	{"restart", "Restart tunnels", true},
+	{"add", "Add a tunnel", false},
+	{"edit", "Change a tunnel's settings", true},
+	{"rm", "Remove tunnels", true},
+	{"show", "Show a tunnel and its ssh command", true},
+	{"prune", "Clean up stale tunnel state", false},
+	{"logs", "Show a tunnel's ssh output", true},
+	{"keys", "List SSH keys for --identity", false},
+	{"secret", "Manage keychain secrets for --secret", false},
+	{"config", "Encrypt the config file or change its storage", false},
+	{"validate", "Check the config for problems", false},
+	{"export", "Write tunnel definitions as JSON or ssh config", true},
+	{"import", "Add tunnel definitions from an export or PuTTY", false},
+	{"teleport", "List Teleport nodes or add tunnels to them", false},
+	{"wait", "Wait until tunnels accept connections", true},
+	{"exec", "Run a command with tunnels up", false},
+	{"fwd", "Run an unsaved tunnel in the foreground", false},
+	{"supervise", "Keep a profile's tunnels running", false},
+	{"service", "Manage the background service for a profile", false},
+	{"daemon", "Run the control socket daemon", false},
+	{"serve", "Serve the REST control API", false},
+	{"grpc", "Serve the gRPC control API", false},
+	{"completion", "Print a shell completion script", false},
+}
+
+// profileFlags are the flags whose value is a profile name
+var profileFlags = []string{"profile", "auto", "stop-profile"}
+
+// cmdCompletion prints a completion script for the given shell
+func cmdCompletion(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman completion bash|zsh|fish")
+		return 2
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Print(bashCompletion())
+	case "zsh":
+		fmt.Print(zshCompletion())
+	case "fish":
+		fmt.Print(fishCompletion())
+	default:
+		fmt.Fprintf(os.Stderr, "Unsupported shell: %s (expected bash, zsh or fish)\n", args[0])
+		return 2
+	}
+	return 0
+}
+
+// cmdComplete prints candidate tunnel or profile names for completion scripts, one per line
+func cmdComplete(tunnelManager *core.TunnelManager, args []string) int {
+	if len(args) != 1 {
+		return 2
+	}
+
+	switch args[0] {
+	case "tunnels":
+		for _, t := range tunnelManager.GetTunnels() {
+			fmt.Println(t.Name)
+		}
+	case "profiles":
+		for _, name := range tunnelManager.GetProfileNames() {
+			fmt.Println(name)
+		}
+	default:
+		return 2
+	}
+	return 0
+}
+
+// tunnelCommands returns the names of subcommands that take tunnel names
+func tunnelCommands() []string {
+	var names []string
+	for _, c := range completionCommands {
+		if c.tunnelArgs {
+			names = append(names, c.name)
+		}
+	}
+	return names
+}
+
+// commandNames returns the names of all completable subcommands
+func commandNames() []string {
+	names := make([]string, 0, len(completionCommands))
+	for _, c := range completionCommands {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// bashCompletion returns the bash completion script
+func bashCompletion() string {
+	var profileCases []string
+	for _, f := range profileFlags {
+		profileCases = append(profileCases, "-"+f, "--"+f)
+	}
+
+	return fmt.Sprintf(`# bash completion for tunnelman
+# Install with: source <(tunnelman completion bash)
+
+_tunnelman() {
+    local cur prev cmd i
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+
+    cmd=""
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            %[1]s|-config|--config) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    local IFS=$'\n'
+    case "$prev" in
+        %[1]s)
+            COMPREPLY=($(compgen -W "$(tunnelman __complete profiles 2>/dev/null)" -- "$cur"))
+            return
+            ;;
+        -config|--config)
+            COMPREPLY=($(compgen -f -- "$cur"))
+            return
+            ;;
+    esac
+
+    case "$cmd" in
+        "")
+            COMPREPLY=($(compgen -W $'%[2]s' -- "$cur"))
+            ;;
+        %[3]s)
+            COMPREPLY=($(compgen -W "$(tunnelman __complete tunnels 2>/dev/null)" -- "$cur"))
+            ;;
+        completion)
+            COMPREPLY=($(compgen -W $'bash\nzsh\nfish' -- "$cur"))
+            ;;
+    esac
+}
+
+complete -F _tunnelman tunnelman
+`, strings.Join(profileCases, "|"), strings.Join(commandNames(), `\n`), strings.Join(tunnelCommands(), "|"))
+}
+
+// zshCompletion returns the zsh completion script
+func zshCompletion() string {
+	var commands strings.Builder
+	for _, c := range completionCommands {
+		fmt.Fprintf(&commands, "        '%s:%s'\n", c.name, strings.ReplaceAll(c.description, "'", "'\\''"))
+	}
+
+	var profileCases []string
+	for _, f := range profileFlags {
+		profileCases = append(profileCases, "-"+f, "--"+f)
+	}
+
+	return fmt.Sprintf(`#compdef tunnelman
+# zsh completion for tunnelman
+# Install with: source <(tunnelman completion zsh)
+
+_tunnelman() {
+    local -a commands tunnels profiles
+    commands=(
+%[1]s    )
+
+    case ${words[CURRENT-1]} in
+        %[2]s)
+            profiles=(${(f)"$(tunnelman __complete profiles 2>/dev/null)"})
+            compadd -a profiles
+            return
+            ;;
+    esac
+
+    if (( CURRENT == 2 )); then
+        _describe 'command' commands
+        return
+    fi
+
+    case ${words[2]} in
+        %[3]s)
+            tunnels=(${(f)"$(tunnelman __complete tunnels 2>/dev/null)"})
+            compadd -a tunnels
+            ;;
+        completion)
+            compadd bash zsh fish
+            ;;
+    esac
+}
+
+compdef _tunnelman tunnelman
+`, commands.String(), strings.Join(profileCases, "|"), strings.Join(tunnelCommands(), "|"))
+}
+
+// fishCompletion returns the fish completion script
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# fish completion for tunnelman\n")
+	b.WriteString("# Install with: tunnelman completion fish > ~/.config/fish/completions/tunnelman.fish\n\n")
+	b.WriteString("complete -c tunnelman -f\n")
+
+	for _, c := range completionCommands {
+		fmt.Fprintf(&b, "complete -c tunnelman -n __fish_use_subcommand -a %s -d '%s'\n",
+			c.name, strings.ReplaceAll(c.description, "'", "\\'"))
+	}
+
+	fmt.Fprintf(&b, "complete -c tunnelman -n '__fish_seen_subcommand_from %s' -a '(tunnelman __complete tunnels 2>/dev/null)'\n",
+		strings.Join(tunnelCommands(), " "))
+	b.WriteString("complete -c tunnelman -n '__fish_seen_subcommand_from completion' -a 'bash zsh fish'\n")
+
+	for _, f := range profileFlags {
+		fmt.Fprintf(&b, "complete -c tunnelman -l %s -x -a '(tunnelman __complete profiles 2>/dev/null)'\n", f)
+	}
+	b.WriteString("complete -c tunnelman -l config -r -F\n")
+	return b.String()
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// configUsage describes the config subcommand
+const configUsage = `Usage: tunnelman config encrypt|decrypt|status
+       tunnelman config storage json|sqlite [DATABASE]
+       tunnelman config history
+       tunnelman config rollback [N|ID]
+
+  encrypt   Encrypt the config file with a key kept in the keychain
+  decrypt   Store the config file in plaintext again
+  status    Show whether the config file is encrypted and where tunnels are kept
+  storage   Move tunnels, profiles, defaults and statistics to a SQLite
+            database (default tunnelman.db next to the config), or back to
+            the config file with json
+  history   List the saved versions of the config, newest first
+  rollback  Restore version N or ID of the history, or without one undo the
+            last change
+
+An encrypted config is decrypted transparently when loaded and stays
+encrypted when saved. Its key is the keychain secret "config-key"; without
+it the config cannot be read. An encrypted config cannot use SQLite storage.
+
+Every save keeps a snapshot of the config in the state directory; the last
+20 are kept. A rollback is itself saved, so it can be undone.
+`
+
+// cmdConfig encrypts and decrypts the config file at rest
+func cmdConfig(configStore *store.ConfigStore, args []string) int {
+	if len(args) >= 1 && args[0] == "storage" {
+		return cmdConfigStorage(configStore, args[1:])
+	}
+	if len(args) >= 1 && args[0] == "rollback" {
+		return cmdConfigRollback(configStore, args[1:])
+	}
+	if len(args) != 1 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	configPath, _ := configStore.GetConfigPath()
+	switch args[0] {
+	case "encrypt":
+		if err := core.EnsureConfigKey(); err != nil {
+			core.Error("Failed to create config key: %v", err)
+			return 1
+		}
+		if err := configStore.SetEncrypted(true); err != nil {
+			core.Error("Failed to encrypt config: %v", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Encrypted %s with the keychain secret %s\n", configPath, core.ConfigKeySecret)
+	case "decrypt":
+		if err := configStore.SetEncrypted(false); err != nil {
+			core.Error("Failed to decrypt config: %v", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Decrypted %s\n", configPath)
+	case "history":
+		snapshots, err := configStore.History()
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		if len(snapshots) == 0 {
+			fmt.Println("No config history yet")
+			return 0
+		}
+		for i, snapshot := range snapshots {
+			tunnels := "unreadable"
+			if snapshot.Tunnels >= 0 {
+				tunnels = fmt.Sprintf("%d tunnel(s)", snapshot.Tunnels)
+			}
+			current := ""
+			if i == 0 {
+				current = "  (latest)"
+			}
+			fmt.Printf("%3d  %s  %s  %s%s\n", i+1, snapshot.ID, snapshot.Time.Local().Format("2006-01-02 15:04:05"), tunnels, current)
+		}
+	case "status":
+		if configStore.Encrypted() {
+			fmt.Printf("%s is encrypted\n", configPath)
+		} else {
+			fmt.Printf("%s is not encrypted\n", configPath)
+		}
+		if config, err := configStore.LoadConfig(); err == nil && config.Storage.IsSQLite() {
+			fmt.Printf("Tunnels are stored in %s\n", configStore.DatabasePath(config.Storage))
+		}
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+	return 0
+}
+
+// cmdConfigStorage moves the tunnels to a storage backend
+func cmdConfigStorage(configStore *store.ConfigStore, args []string) int {
+	if len(args) < 1 || len(args) > 2 || (args[0] == store.StorageJSON && len(args) == 2) {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	storage := &store.Storage{Backend: args[0]}
+	if len(args) == 2 {
+		storage.Path = args[1]
+	}
+	if err := configStore.SetStorage(storage); err != nil {
+		core.Error("Failed to change storage: %v", err)
+		return 1
+	}
+	if storage.IsSQLite() {
+		fmt.Fprintf(os.Stderr, "Tunnels are now stored in %s\n", configStore.DatabasePath(storage))
+	} else {
+		configPath, _ := configStore.GetConfigPath()
+		fmt.Fprintf(os.Stderr, "Tunnels are now stored in %s\n", configPath)
+	}
+	return 0
+}
+
+// cmdConfigRollback restores a version of the config from its history
+func cmdConfigRollback(configStore *store.ConfigStore, args []string) int {
+	if len(args) > 1 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	if len(args) == 0 {
+		snapshot, err := configStore.Undo()
+		if err != nil {
+			core.Error("Failed to undo: %v", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Restored the config of %s\n", snapshot.Time.Local().Format("2006-01-02 15:04:05"))
+		return 0
+	}
+
+	id := args[0]
+	if n, err := strconv.Atoi(id); err == nil {
+		snapshots, err := configStore.History()
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		if n < 1 || n > len(snapshots) {
+			core.Error("No version %d in the config history (see tunnelman config history)", n)
+			return 1
+		}
+		id = snapshots[n-1].ID
+	}
+	if err := configStore.Rollback(id); err != nil {
+		core.Error("Failed to roll back: %v", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Restored config version %s\n", id)
+	return 0
+}
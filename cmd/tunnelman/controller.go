@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/daemon"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// controller performs tunnel operations either in-process or through a running daemon
+type controller interface {
+	List(profile string) ([]core.TunnelSnapshot, error)
+	Find(nameOrID string) (core.TunnelSnapshot, error)
+	Start(nameOrID string) (core.TunnelSnapshot, error)
+	Stop(nameOrID string) (core.TunnelSnapshot, error)
+	Restart(nameOrID string) (core.TunnelSnapshot, error)
+}
+
+// newController returns a daemon client if a daemon is listening, otherwise a local controller
+func newController(tunnelManager *core.TunnelManager) controller {
+	if socketPath, err := store.GetSocketPath(); err == nil {
+		if client, err := daemon.Dial(socketPath); err == nil {
+			return client
+		}
+	}
+	return &localController{manager: tunnelManager}
+}
+
+// followDaemon makes the TUI a client of a running daemon: tunnels are
+// started and stopped by the daemon, and its status changes are shown as they
+// happen. If the daemon goes away, the TUI runs tunnels itself again.
+func followDaemon(ctx context.Context, tunnelManager *core.TunnelManager) {
+	socketPath, err := store.GetSocketPath()
+	if err != nil {
+		return
+	}
+	client, err := daemon.Dial(socketPath)
+	if err != nil {
+		return
+	}
+
+	core.Info("Starting and stopping tunnels through the daemon on %s", socketPath)
+	tunnelManager.SetRemoteControl(client)
+	go func() {
+		err := client.Watch(ctx, func(event core.StatusEvent) {
+			if snapshot, err := client.Find(event.TunnelID); err == nil {
+				tunnelManager.ApplySnapshot(snapshot)
+			}
+		})
+		tunnelManager.SetRemoteControl(nil)
+		if err != nil {
+			core.Warn("Lost the daemon, running tunnels in this process: %v", err)
+		}
+	}()
+}
+
+// localController operates on the tunnel manager of the current process
+type localController struct {
+	manager *core.TunnelManager
+}
+
+// List returns snapshots of all tunnels, or only those in profile if non-empty
+func (c *localController) List(profile string) ([]core.TunnelSnapshot, error) {
+	var tunnels []*core.Tunnel
+	if profile != "" {
+		tunnels = c.manager.GetTunnelsByProfile(profile)
+	} else {
+		tunnels = c.manager.GetTunnels()
+	}
+
+	snapshots := make([]core.TunnelSnapshot, 0, len(tunnels))
+	for _, t := range tunnels {
+		snapshots = append(snapshots, t.Snapshot())
+	}
+	return snapshots, nil
+}
+
+// Find returns a snapshot of the tunnel with the given name or ID
+func (c *localController) Find(nameOrID string) (core.TunnelSnapshot, error) {
+	tunnel, err := c.manager.FindTunnel(nameOrID)
+	if err != nil {
+		return core.TunnelSnapshot{}, err
+	}
+	return tunnel.Snapshot(), nil
+}
+
+// Start starts a tunnel by name or ID
+func (c *localController) Start(nameOrID string) (core.TunnelSnapshot, error) {
+	return c.apply(nameOrID, c.manager.StartTunnel)
+}
+
+// Stop stops a tunnel by name or ID
+func (c *localController) Stop(nameOrID string) (core.TunnelSnapshot, error) {
+	return c.apply(nameOrID, c.manager.StopTunnel)
+}
+
+// Restart restarts a tunnel by name or ID
+func (c *localController) Restart(nameOrID string) (core.TunnelSnapshot, error) {
+	return c.apply(nameOrID, c.manager.RestartTunnel)
+}
+
+// apply resolves a tunnel and runs a lifecycle action on it
+func (c *localController) apply(nameOrID string, fn func(id string) error) (core.TunnelSnapshot, error) {
+	tunnel, err := c.manager.FindTunnel(nameOrID)
+	if err != nil {
+		return core.TunnelSnapshot{}, err
+	}
+	err = fn(tunnel.ID)
+
+	// Report the state after the action rather than the copy taken before it
+	if current, getErr := c.manager.GetTunnel(tunnel.ID); getErr == nil {
+		tunnel = current
+	}
+	return tunnel.Snapshot(), err
+}
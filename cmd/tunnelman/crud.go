@@ -0,0 +1,395 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// forwardFlags holds the -L/-R/-D forwarding specification flags in the order given
+type forwardFlags struct {
+	specs []forwardSpec
+}
+
+// forwardSpec is one forwarding flag value
+type forwardSpec struct {
+	tunnelType core.TunnelType
+	spec       string
+}
+
+// register adds the forwarding flags to a flag set. Each flag may be repeated.
+func (f *forwardFlags) register(fs *flag.FlagSet) {
+	fs.Func("L", "Local forward `[bindAddress:]localPort:remoteHost:remotePort`; ports may be ranges or lists like 9000-9005 (repeatable)", f.adder(core.LocalForward))
+	fs.Func("R", "Remote forward `[bindAddress:]remotePort:localPort` (repeatable)", f.adder(core.RemoteForward))
+	fs.Func("D", "Dynamic (SOCKS) forward `[bindAddress:]localPort` (repeatable)", f.adder(core.DynamicForward))
+	fs.Func("RD", "Reverse dynamic (SOCKS on the SSH host) forward `[bindAddress:]remotePort` (repeatable)", f.adder(core.ReverseDynamicForward))
+}
+
+// adder returns a flag function recording specifications of the given type
+func (f *forwardFlags) adder(tunnelType core.TunnelType) func(string) error {
+	return func(spec string) error {
+		f.add(tunnelType, spec)
+		return nil
+	}
+}
+
+// add records a forwarding specification
+func (f *forwardFlags) add(tunnelType core.TunnelType, spec string) {
+	f.specs = append(f.specs, forwardSpec{tunnelType: tunnelType, spec: spec})
+}
+
+// apply parses the given forwarding specifications into the tunnel. The first
+// becomes the tunnel's own forward and the rest share its SSH session.
+// It reports false when no forwarding flag was set.
+func (f *forwardFlags) apply(tunnel *core.Tunnel) (bool, error) {
+	if len(f.specs) == 0 {
+		return false, nil
+	}
+
+	forwards := make([]core.Forward, 0, len(f.specs))
+	for _, s := range f.specs {
+		expanded, err := core.ParseForwardSpec(s.spec, s.tunnelType)
+		if err != nil {
+			return false, err
+		}
+		forwards = append(forwards, expanded...)
+	}
+
+	primary := forwards[0]
+	tunnel.Type = primary.Type
+	tunnel.LocalHost = primary.LocalHost
+	tunnel.LocalPort = primary.LocalPort
+	tunnel.RemoteHost = primary.RemoteHost
+	tunnel.RemotePort = primary.RemotePort
+	tunnel.RemoteBindAddress = primary.RemoteBindAddress
+	tunnel.Forwards = forwards[1:]
+	if len(tunnel.Forwards) == 0 {
+		tunnel.Forwards = nil
+	}
+	return true, nil
+}
+
+// cmdAdd creates a new tunnel from command-line flags
+func cmdAdd(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	var forward forwardFlags
+	forward.register(fs)
+	name := fs.String("name", "", "Tunnel name (required)")
+	host := fs.String("host", "", "SSH host (required)")
+	jump := fs.String("jump", "", "Jump host(s) to connect through, comma-separated (ssh -J)")
+	chain := fs.String("chain", "", "Hosts to connect through in order, ending with the SSH host (e.g. bastion,gw,db)")
+	bind := fs.String("bind", "", "Local address to listen on (default from the config, else "+core.DefaultBindAddress+"; 0.0.0.0 for all interfaces)")
+	profile := fs.String("profile", "default", "Profile to add the tunnel to")
+	tags := fs.String("tags", "", "Comma-separated tags, e.g. to start and stop tunnels together")
+	description := fs.String("description", "", "Note on why the tunnel exists or who owns the remote service")
+	autoConnect := fs.Bool("auto-connect", false, "Start the tunnel automatically")
+	compress := fs.Bool("compress", false, "Compress the SSH session (ssh -C), for slow links")
+	multiplex := fs.Bool("multiplex", false, "Share one SSH connection with other multiplexed tunnels to the host")
+	identity := fs.String("identity", "", "Private key to authenticate with, instead of every key the agent holds")
+	secret := fs.String("secret", "", "Keychain secret answering ssh's password or passphrase prompts (see tunnelman secret)")
+	hostKey := fs.String("host-key", "", "Accept only the host key with this SHA256 fingerprint, from ~/.ssh/known_hosts")
+	certificate := fs.String("certificate", "", "SSH certificate to authenticate with")
+	certificateCommand := fs.String("certificate-command", "", "Shell command that renews the certificate when it is missing or about to expire")
+	keepalive := fs.Int("keepalive", 0, "Keepalive interval in seconds (0 uses the default, -1 disables keepalives)")
+	keepaliveCount := fs.Int("keepalive-count", 0, "Unanswered keepalives before disconnecting (0 uses the default)")
+	exitOnFailure := fs.String("exit-on-forward-failure", "default", "Exit when a forward cannot be set up: yes, no or default")
+	onConnect := fs.String("on-connect", "", "Shell command run after the tunnel connects")
+	onDisconnect := fs.String("on-disconnect", "", "Shell command run after the tunnel stops or drops")
+	onFailure := fs.String("on-failure", "", "Shell command run when the tunnel fails")
+	sshArgs := fs.String("ssh-args", "", "Extra SSH arguments")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return 2
+	}
+
+	tunnel := core.NewTunnel(*name, core.LocalForward)
+	tunnel.SSHHost = *host
+	tunnel.JumpHost = *jump
+	if *chain != "" {
+		if *host != "" || *jump != "" {
+			fmt.Fprintln(os.Stderr, "--chain cannot be combined with --host or --jump")
+			return 2
+		}
+		if err := setChain(tunnel, *chain); err != nil {
+			core.Error("%v", err)
+			return 2
+		}
+	}
+	tunnel.Profile = *profile
+	tunnel.Tags = core.ParseTags(*tags)
+	tunnel.Description = strings.TrimSpace(*description)
+	tunnel.AutoConnect = *autoConnect
+	tunnel.Compression = *compress
+	tunnel.Multiplex = *multiplex
+	tunnel.IdentityFile = *identity
+	tunnel.Secret = *secret
+	tunnel.HostKey = *hostKey
+	tunnel.CertificateFile = *certificate
+	tunnel.CertificateCommand = *certificateCommand
+	tunnel.ServerAliveInterval = *keepalive
+	tunnel.ServerAliveCountMax = *keepaliveCount
+	exit, err := parseDefaultableBool(*exitOnFailure)
+	if err != nil {
+		core.Error("--exit-on-forward-failure: %v", err)
+		return 2
+	}
+	tunnel.ExitOnForwardFailure = exit
+	tunnel.Hooks = core.Hooks{OnConnect: *onConnect, OnDisconnect: *onDisconnect, OnFailure: *onFailure}
+	if *sshArgs != "" {
+		tunnel.ExtraArgs = strings.Fields(*sshArgs)
+	}
+
+	ok, err := forward.apply(tunnel)
+	if err != nil {
+		core.Error("%v", err)
+		return 2
+	}
+	if !ok {
+		fmt.Fprintln(os.Stderr, "One of -L, -R, -D or -RD is required")
+		return 2
+	}
+	if *bind != "" {
+		tunnel.LocalHost = core.NormalizeHost(*bind)
+	} else if tunnel.LocalHost == core.DefaultBindAddress && tunnel.Type != core.RemoteForward && tunnel.Type != core.ReverseDynamicForward {
+		// Forwards without a bind address listen where the config says
+		tunnel.LocalHost = tunnelManager.DefaultBindAddress()
+	}
+
+	if err := tunnelManager.AddTunnel(tunnel); err != nil {
+		core.Error("Failed to add tunnel: %v", err)
+		return 1
+	}
+
+	fmt.Printf("Added: %s (%s) [%s]\n", tunnel.Name, tunnel.ForwardSummary(), tunnel.ID)
+	return 0
+}
+
+// cmdRemove deletes tunnels by name or ID
+func cmdRemove(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	force := fs.Bool("force", false, "Stop running tunnels before removing them")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(names) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman rm [--force] <name|id>...")
+		return 2
+	}
+
+	exitCode := 0
+	for _, name := range names {
+		tunnel, err := tunnelManager.FindTunnel(name)
+		if err != nil {
+			core.Error("%v", err)
+			exitCode = 1
+			continue
+		}
+
+		if tunnel.Status == core.StatusRunning {
+			if !*force {
+				core.Error("Tunnel %s is running, stop it first or use --force", tunnel.Name)
+				exitCode = 1
+				continue
+			}
+			if err := tunnelManager.StopTunnel(tunnel.ID); err != nil {
+				core.Error("Failed to stop tunnel %s: %v", tunnel.Name, err)
+				exitCode = 1
+				continue
+			}
+		}
+
+		if err := tunnelManager.DeleteTunnel(tunnel.ID); err != nil {
+			core.Error("Failed to remove tunnel %s: %v", tunnel.Name, err)
+			exitCode = 1
+			continue
+		}
+		fmt.Printf("Removed: %s\n", tunnel.Name)
+	}
+
+	return exitCode
+}
+
+// cmdEdit updates fields of an existing tunnel
+func cmdEdit(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	var forward forwardFlags
+	forward.register(fs)
+	name := fs.String("name", "", "New tunnel name")
+	host := fs.String("host", "", "SSH host")
+	jump := fs.String("jump", "", "Jump host(s), comma-separated (empty string clears them)")
+	chain := fs.String("chain", "", "Hosts to connect through in order, ending with the SSH host")
+	bind := fs.String("bind", "", "Local address to listen on")
+	localPort := fs.Int("local-port", 0, "Local port")
+	remoteHost := fs.String("remote-host", "", "Remote host")
+	remotePort := fs.Int("remote-port", 0, "Remote port")
+	remoteBind := fs.String("remote-bind", "", "Address remote forwards listen on at the SSH host (empty string uses the server default)")
+	profile := fs.String("profile", "", "Profile")
+	tags := fs.String("tags", "", "Comma-separated tags (empty string clears them)")
+	description := fs.String("description", "", "Note on why the tunnel exists (empty string clears it)")
+	autoConnect := fs.Bool("auto-connect", false, "Start the tunnel automatically")
+	compress := fs.Bool("compress", false, "Compress the SSH session (ssh -C)")
+	multiplex := fs.Bool("multiplex", false, "Share one SSH connection with other multiplexed tunnels to the host")
+	identity := fs.String("identity", "", "Private key to authenticate with (empty string uses the agent and default keys)")
+	secret := fs.String("secret", "", "Keychain secret answering ssh's password or passphrase prompts (empty string clears it)")
+	hostKey := fs.String("host-key", "", "Accept only the host key with this SHA256 fingerprint (empty string unpins it)")
+	certificate := fs.String("certificate", "", "SSH certificate to authenticate with (empty string clears it)")
+	certificateCommand := fs.String("certificate-command", "", "Shell command that renews the certificate (empty string clears it)")
+	keepalive := fs.Int("keepalive", 0, "Keepalive interval in seconds (0 uses the default, -1 disables keepalives)")
+	keepaliveCount := fs.Int("keepalive-count", 0, "Unanswered keepalives before disconnecting (0 uses the default)")
+	exitOnFailure := fs.String("exit-on-forward-failure", "default", "Exit when a forward cannot be set up: yes, no or default")
+	onConnect := fs.String("on-connect", "", "Shell command run after the tunnel connects (empty string clears it)")
+	onDisconnect := fs.String("on-disconnect", "", "Shell command run after the tunnel stops or drops (empty string clears it)")
+	onFailure := fs.String("on-failure", "", "Shell command run when the tunnel fails (empty string clears it)")
+	sshArgs := fs.String("ssh-args", "", "Extra SSH arguments (empty string clears them)")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman edit <name|id> [flags]")
+		return 2
+	}
+
+	tunnel, err := tunnelManager.FindTunnel(positional[0])
+	if err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+
+	if _, err := forward.apply(tunnel); err != nil {
+		core.Error("%v", err)
+		return 2
+	}
+
+	if *chain != "" {
+		if err := setChain(tunnel, *chain); err != nil {
+			core.Error("%v", err)
+			return 2
+		}
+	}
+
+	exit, err := parseDefaultableBool(*exitOnFailure)
+	if err != nil {
+		core.Error("--exit-on-forward-failure: %v", err)
+		return 2
+	}
+
+	changed := 0
+	fs.Visit(func(f *flag.Flag) {
+		changed++
+		switch f.Name {
+		case "name":
+			tunnel.Name = *name
+		case "host":
+			tunnel.SSHHost = *host
+		case "jump":
+			tunnel.JumpHost = *jump
+		case "bind":
+			tunnel.LocalHost = core.NormalizeHost(*bind)
+		case "local-port":
+			tunnel.LocalPort = *localPort
+		case "remote-host":
+			tunnel.RemoteHost = core.NormalizeHost(*remoteHost)
+		case "remote-port":
+			tunnel.RemotePort = *remotePort
+		case "remote-bind":
+			tunnel.RemoteBindAddress = core.NormalizeHost(*remoteBind)
+		case "profile":
+			tunnel.Profile = *profile
+		case "tags":
+			tunnel.Tags = core.ParseTags(*tags)
+		case "description":
+			tunnel.Description = strings.TrimSpace(*description)
+		case "auto-connect":
+			tunnel.AutoConnect = *autoConnect
+		case "compress":
+			tunnel.Compression = *compress
+		case "multiplex":
+			tunnel.Multiplex = *multiplex
+		case "identity":
+			tunnel.IdentityFile = *identity
+		case "secret":
+			tunnel.Secret = *secret
+		case "host-key":
+			tunnel.HostKey = *hostKey
+		case "certificate":
+			tunnel.CertificateFile = *certificate
+		case "certificate-command":
+			tunnel.CertificateCommand = *certificateCommand
+		case "keepalive":
+			tunnel.ServerAliveInterval = *keepalive
+		case "keepalive-count":
+			tunnel.ServerAliveCountMax = *keepaliveCount
+		case "exit-on-forward-failure":
+			tunnel.ExitOnForwardFailure = exit
+		case "on-connect":
+			tunnel.Hooks.OnConnect = *onConnect
+		case "on-disconnect":
+			tunnel.Hooks.OnDisconnect = *onDisconnect
+		case "on-failure":
+			tunnel.Hooks.OnFailure = *onFailure
+		case "ssh-args":
+			tunnel.ExtraArgs = strings.Fields(*sshArgs)
+		}
+	})
+	if changed == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to change")
+		return 2
+	}
+
+	if err := tunnelManager.UpdateTunnel(tunnel); err != nil {
+		core.Error("Failed to update tunnel: %v", err)
+		return 1
+	}
+
+	fmt.Printf("Updated: %s (%s)\n", tunnel.Name, tunnel.ForwardSummary())
+	return 0
+}
+
+// setChain sets a tunnel's SSH host and jump hosts from a --chain value
+func setChain(tunnel *core.Tunnel, spec string) error {
+	hops, err := core.ParseHopChain(spec)
+	if err != nil {
+		return err
+	}
+	return tunnel.SetHopChain(hops)
+}
+
+// parseInterspersed parses flags that may appear before or after positional arguments
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseDefaultableBool parses "yes", "no" or "default", returning nil for the
+// default
+func parseDefaultableBool(value string) (*bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "true":
+		b := true
+		return &b, nil
+	case "no", "false":
+		b := false
+		return &b, nil
+	case "default", "":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("expected yes, no or default, got %q", value)
+}
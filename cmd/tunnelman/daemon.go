@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/daemon"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// cmdDaemon runs the control socket server in the foreground until interrupted
+func cmdDaemon(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	socket := fs.String("socket", "", "Control socket path (default: $XDG_STATE_HOME/tunnelman/tunnelman.sock)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman daemon [--socket PATH]")
+		return 2
+	}
+
+	socketPath := *socket
+	if socketPath == "" {
+		var err error
+		socketPath, err = store.GetSocketPath()
+		if err != nil {
+			core.Error("Failed to resolve socket path: %v", err)
+			return 1
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Probe forwards so clients see the health of running tunnels and
+	// whether they listen
+	go core.NewHealthChecker(tunnelManager).Run(ctx)
+	go core.NewListenChecker(tunnelManager).Run(ctx)
+
+	// Pick up edits of the config file by other programs
+	go core.NewConfigWatcher(tunnelManager).Run(ctx)
+
+	server := daemon.NewServer(tunnelManager)
+	if err := server.Serve(ctx, socketPath); err != nil {
+		core.Error("Daemon failed: %v", err)
+		return 1
+	}
+
+	// Like the TUI, leave tunnels running when the daemon exits
+	core.Info("Daemon exiting. SSH tunnels remain running.")
+	return 0
+}
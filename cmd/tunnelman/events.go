@@ -0,0 +1,45 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// handleEvents streams tunnel status changes to stdout, one JSON object per line,
+// until interrupted
+func handleEvents(tunnelManager *core.TunnelManager) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	// Poll the PID store so tunnels started or stopped by other
+	// tunnelman processes are reported as well
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	encoder := json.NewEncoder(os.Stdout)
+	statusChanges := tunnelManager.Subscribe()
+	defer tunnelManager.Unsubscribe(statusChanges)
+
+	for {
+		select {
+		case change := <-statusChanges:
+			if err := encoder.Encode(tunnelManager.StatusEvent(change)); err != nil {
+				core.Error("Failed to write event: %v", err)
+				os.Exit(1)
+			}
+
+		case <-ticker.C:
+			tunnelManager.RefreshStates()
+
+		case sig := <-sigChan:
+			core.Debug("Received signal: %v", sig)
+			return
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// stringList is a flag that may be repeated or given as a comma-separated list
+type stringList []string
+
+// String returns the flag value for help output
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+// Set appends one or more comma-separated values
+func (s *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
+
+// cmdExec starts tunnels, runs a command once they accept connections, then stops
+// the tunnels it started and exits with the command's exit code
+func cmdExec(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+	var names stringList
+	fs.Var(&names, "tunnel", "Tunnel name or ID to bring up (repeatable)")
+	timeout := fs.Duration("timeout", 15*time.Second, "How long to wait for tunnels to accept connections")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	command := fs.Args()
+	if len(names) == 0 || len(command) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman exec --tunnel NAME [--tunnel NAME]... -- COMMAND [ARGS]...")
+		return 2
+	}
+
+	var tunnels []*core.Tunnel
+	for _, name := range names {
+		tunnel, err := tunnelManager.FindTunnel(name)
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+
+	// Only tear down what we started; tunnels that were already up stay up
+	var started []*core.Tunnel
+	defer func() {
+		for _, t := range started {
+			if err := tunnelManager.StopTunnel(t.ID); err != nil {
+				core.Error("Failed to stop tunnel %s: %v", t.Name, err)
+			}
+		}
+	}()
+
+	for _, t := range tunnels {
+		if t.Status == core.StatusRunning {
+			continue
+		}
+		if err := tunnelManager.StartTunnel(t.ID); err != nil {
+			core.Error("Failed to start tunnel %s: %v", t.Name, err)
+			return 1
+		}
+		started = append(started, t)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	for _, t := range tunnels {
+		if err := tunnelManager.WaitReady(ctx, t.ID); err != nil {
+			cancel()
+			core.Error("%v", err)
+			return 1
+		}
+	}
+	cancel()
+
+	return runChild(command)
+}
+
+// runChild runs a command attached to the terminal and returns its exit code
+func runChild(command []string) int {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	// The child shares our terminal and receives Ctrl-C itself; stay alive so
+	// tunnels are torn down after it exits
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	if err := cmd.Start(); err != nil {
+		core.Error("Failed to run %s: %v", command[0], err)
+		return 127
+	}
+
+	go func() {
+		for sig := range sigChan {
+			cmd.Process.Signal(sig)
+		}
+	}()
+
+	err := cmd.Wait()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return 128 + int(status.Signal())
+		}
+		return exitErr.ExitCode()
+	default:
+		core.Error("Failed to run %s: %v", command[0], err)
+		return 1
+	}
+}
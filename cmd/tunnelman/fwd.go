@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// cmdFwd runs a transient tunnel in the foreground until interrupted, without saving it
+func cmdFwd(args []string) int {
+	fs := flag.NewFlagSet("fwd", flag.ContinueOnError)
+	var forward forwardFlags
+	forward.register(fs)
+	jump := fs.String("jump", "", "Jump host(s) to connect through, comma-separated (ssh -J)")
+	compress := fs.Bool("compress", false, "Compress the SSH session (ssh -C)")
+	sshArgs := fs.String("ssh-args", "", "Extra SSH arguments")
+	positional, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	tunnel := core.NewTunnel("adhoc", core.LocalForward)
+
+	ok, err := forward.apply(tunnel)
+	if err != nil {
+		core.Error("%v", err)
+		return 2
+	}
+
+	// A bare spec is a local forward, like ssh -L
+	if !ok && len(positional) == 2 {
+		forward.add(core.LocalForward, positional[0])
+		positional = positional[1:]
+		if _, err := forward.apply(tunnel); err != nil {
+			core.Error("%v", err)
+			return 2
+		}
+	}
+
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman fwd [-R|-D] SPEC HOST")
+		return 2
+	}
+	tunnel.SSHHost = positional[0]
+	tunnel.JumpHost = *jump
+	tunnel.Compression = *compress
+	if *sshArgs != "" {
+		tunnel.ExtraArgs = strings.Fields(*sshArgs)
+	}
+
+	processManager := core.NewProcessManager()
+	processInfo, err := processManager.Connect(tunnel)
+	if err != nil {
+		core.Error("Failed to start tunnel: %v", err)
+		return 1
+	}
+
+	fmt.Printf("Forwarding %s via %s (PID %d), press Ctrl-C to stop\n",
+		tunnel.ForwardSummary(), tunnel.SSHHost, processInfo.PID)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	select {
+	case <-processInfo.Done():
+		if err := processInfo.Exit().Err; err != nil {
+			core.Error("SSH process exited: %v", err)
+		} else {
+			core.Error("SSH process exited")
+		}
+		return 1
+
+	case <-ctx.Done():
+		if err := processManager.Disconnect(tunnel.ID, processInfo.PID); err != nil {
+			core.Error("Failed to stop tunnel: %v", err)
+			return 1
+		}
+		fmt.Println("Stopped")
+		return 0
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/grpcapi"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// cmdGRPC runs the gRPC control API in the foreground until interrupted
+func cmdGRPC(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("grpc", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:7071", "Address to listen on")
+	allowRemote := fs.Bool("allow-remote", false, "Allow listening on an address other machines can reach")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman grpc [--listen ADDR] [--allow-remote]")
+		return 2
+	}
+	// The API is plaintext, so only the token keeps others from using it
+	if !*allowRemote && !isLoopbackAddr(*listen) {
+		fmt.Fprintf(os.Stderr, "Refusing to listen on %s, which other machines can reach; pass --allow-remote to do so anyway\n", *listen)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	token, err := store.GetAPIToken()
+	if err != nil {
+		core.Error("Failed to set up API token: %v", err)
+		return 1
+	}
+	if os.Getenv(store.APITokenEnv) == "" {
+		if path, err := store.GetAPITokenPath(); err == nil {
+			core.Info("Calls need the bearer token in %s as authorization metadata", path)
+		}
+	}
+
+	server := grpcapi.NewServer(tunnelManager, grpcapi.WithToken(token))
+	if err := server.ListenAndServe(ctx, *listen); err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+
+	core.Info("gRPC server exiting. SSH tunnels remain running.")
+	return 0
+}
+
+// isLoopbackAddr reports whether a listen address only accepts connections
+// from this machine. An empty host listens on all interfaces.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// cmdKeys lists the SSH keys tunnels can authenticate with: those held by
+// ssh-agent and the key files in ~/.ssh
+func cmdKeys(args []string) int {
+	fs := flag.NewFlagSet("keys", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	agent, agentErr := core.ListAgentIdentities()
+	switch {
+	case agentErr != nil:
+		fmt.Printf("Agent: %v\n", agentErr)
+	case core.AgentSocket() != "":
+		fmt.Printf("Agent: %s (%d keys)\n", core.AgentSocket(), len(agent))
+	default:
+		fmt.Printf("Agent: %d keys\n", len(agent))
+	}
+
+	files, err := core.ListKeyFiles(agent)
+	if err != nil {
+		core.Error("Failed to list key files: %v", err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nSOURCE\tTYPE\tFINGERPRINT\tCOMMENT")
+
+	// Keys only the agent holds, then key files, marking those it holds too
+	listed := make(map[string]bool)
+	for _, key := range files {
+		listed[key.Fingerprint] = true
+	}
+	for _, key := range agent {
+		if !listed[key.Fingerprint] {
+			fmt.Fprintf(w, "agent\t%s\t%s\t%s\n", key.Type, key.Fingerprint, key.Comment)
+		}
+	}
+	for _, key := range files {
+		source := key.Path
+		if key.InAgent {
+			source += " (in agent)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", source, key.Type, key.Fingerprint, key.Comment)
+	}
+	w.Flush()
+
+	fmt.Println("\nPin a key to a tunnel with: tunnelman edit NAME --identity PATH")
+	return 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// cmdList prints all tunnels as a table or JSON
+func cmdList(ctrl controller, args []string) int {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	profile := fs.String("profile", "", "Only list tunnels in this profile")
+	tag := fs.String("tag", "", "Only list tunnels with this tag")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	tunnels, err := ctrl.List(*profile)
+	if err != nil {
+		core.Error("Failed to list tunnels: %v", err)
+		return 1
+	}
+	if *tag != "" {
+		tunnels = slices.DeleteFunc(tunnels, func(t core.TunnelSnapshot) bool {
+			return !slices.Contains(t.Tags, *tag)
+		})
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(tunnels); err != nil {
+			core.Error("Failed to encode tunnels: %v", err)
+			return 1
+		}
+		return 0
+	}
+
+	if len(tunnels) == 0 {
+		fmt.Println("No tunnels configured")
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tTYPE\tPORTS\tHOST\tPROFILE\tSTATUS\tHEALTH\tPID\tUPTIME")
+	for _, t := range tunnels {
+		pid := "-"
+		if t.PID > 0 {
+			pid = fmt.Sprintf("%d", t.PID)
+		}
+		health := "-"
+		if t.Health != core.HealthUnknown {
+			health = string(t.Health)
+		}
+		if t.NotListening != "" {
+			health = "not listening"
+		}
+		uptime := "-"
+		if t.StartedAt != nil {
+			uptime = core.FormatDuration(time.Since(*t.StartedAt))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.Name, t.Type, t.Forward, t.SSHHost, t.Profile, t.Status, health, pid, uptime)
+	}
+	return flushOrFail(w)
+}
+
+// flushOrFail flushes a tabwriter and converts write errors into an exit code
+func flushOrFail(w *tabwriter.Writer) int {
+	if err := w.Flush(); err != nil {
+		core.Error("Failed to write output: %v", err)
+		return 1
+	}
+	return 0
+}
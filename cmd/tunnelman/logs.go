@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// cmdLogs prints the captured SSH output of a tunnel, optionally following it
+func cmdLogs(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	follow := fs.Bool("f", false, "Keep printing new output until interrupted")
+	lines := fs.Int("n", 50, "Number of lines to show (0 for all)")
+	hooks := fs.Bool("hooks", false, "Show the output of the tunnel's hooks instead")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(names) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman logs [-f] [-n N] [-hooks] <name|id>")
+		return 2
+	}
+
+	tunnel, err := tunnelManager.FindTunnel(names[0])
+	if err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+	logPath := tunnelManager.LogPath
+	if *hooks {
+		logPath = tunnelManager.HookLogPath
+	}
+	path, err := logPath(tunnel.ID)
+	if err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+
+	file, err := os.Open(path)
+	if err != nil && !(os.IsNotExist(err) && *follow) {
+		if os.IsNotExist(err) {
+			core.Error("No output captured for tunnel %s yet", tunnel.Name)
+		} else {
+			core.Error("Failed to open log: %v", err)
+		}
+		return 1
+	}
+
+	var offset int64
+	if file != nil {
+		offset, err = printTail(file, *lines)
+		file.Close()
+		if err != nil {
+			core.Error("Failed to read log: %v", err)
+			return 1
+		}
+	}
+	if !*follow {
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	followLog(ctx, path, offset)
+	return 0
+}
+
+// printTail prints the last n lines of r (all lines if n <= 0) and returns the
+// number of bytes read
+func printTail(r io.Reader, n int) (int64, error) {
+	var tail []string
+	var read int64
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		read += int64(len(line))
+		if line != "" {
+			tail = append(tail, line)
+			if n > 0 && len(tail) > n {
+				tail = tail[1:]
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return read, err
+		}
+	}
+
+	for _, line := range tail {
+		fmt.Print(line)
+	}
+	return read, nil
+}
+
+// followLog prints data appended to the log at path after offset until ctx is
+// cancelled. A log that shrinks has been rotated, so it is read from the start.
+func followLog(ctx context.Context, path string, offset int64) {
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if info, err := os.Stat(path); err == nil {
+			if info.Size() < offset {
+				offset = 0
+			}
+			if info.Size() > offset {
+				if file, err := os.Open(path); err == nil {
+					if _, err := file.Seek(offset, io.SeekStart); err == nil {
+						n, _ := io.Copy(os.Stdout, file)
+						offset += n
+					}
+					file.Close()
+				}
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
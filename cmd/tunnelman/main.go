@@ -23,6 +23,12 @@ var (
 )
 
 func main() {
+	// ssh runs tunnelman as its SSH_ASKPASS program, through a wrapper script,
+	// to answer passwords from the keychain or ask the TUI for them
+	if len(os.Args) > 1 && os.Args[1] == core.AskpassCommand {
+		os.Exit(core.RunAskpass(os.Args[2:]))
+	}
+
 	// Parse command-line flags
 	var (
 		showVersion  = flag.Bool("version", false, "Show version information")
@@ -31,7 +37,23 @@ func main() {
 		autoProfile  = flag.String("auto", "", "Auto-connect tunnels in specified profile")
 		listProfiles = flag.Bool("list-profiles", false, "List available profiles")
 		profile      = flag.String("profile", "default", "Initial profile to load")
+		events       = flag.Bool("events", false, "Stream tunnel status changes as JSON lines until interrupted")
+		stopAll      = flag.Bool("stop-all", false, "Stop all running tunnels and exit")
+		stopProfile  = flag.String("stop-profile", "", "Stop all running tunnels in specified profile and exit")
+		supervise    = flag.Bool("supervise", false, "With --auto, stay in the foreground and restart tunnels that die")
+		ascii        = flag.Bool("ascii", false, "Draw the TUI with ASCII characters instead of unicode icons and borders")
+
+		mock            = flag.Bool("mock", false, "Simulate ssh instead of running it, for demos and tests")
+		mockDelay       = flag.Duration("mock-delay", time.Second, "With --mock, how long connecting takes")
+		mockFailureRate = flag.Float64("mock-failure-rate", 0.1, "With --mock, the chance from 0 to 1 that connecting fails")
+		mockLifetime    = flag.Duration("mock-lifetime", 0, "With --mock, the average time before a connection drops (0 never)")
 	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: tunnelman [flags] [command] [args]\n\n")
+		fmt.Fprint(flag.CommandLine.Output(), commandUsage)
+		fmt.Fprintf(flag.CommandLine.Output(), "\nFlags:\n")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
 	// Handle version flag
@@ -49,6 +71,7 @@ func main() {
 		core.Error("Failed to initialize config store: %v", err)
 		os.Exit(1)
 	}
+	configStore.SetKeyFunc(core.ConfigKey)
 
 	// Handle list-profiles flag
 	if *listProfiles {
@@ -68,6 +91,16 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Simulated tunnels keep their PIDs, logs and statistics apart from real ones
+	if *mock && os.Getenv(store.StateDirEnv) == "" {
+		stateDir, err := os.MkdirTemp("", "tunnelman-mock-")
+		if err != nil {
+			core.Error("Failed to create state directory for --mock: %v", err)
+			os.Exit(1)
+		}
+		os.Setenv(store.StateDirEnv, stateDir)
+	}
+
 	// Initialize PID store for tracking running tunnels
 	pidStore, err := store.NewPIDStore()
 	if err != nil {
@@ -80,27 +113,69 @@ func main() {
 	if *debug {
 		tunnelManagerOpts = append(tunnelManagerOpts, core.WithDebugMode(true))
 	}
+	if *mock {
+		tunnelManagerOpts = append(tunnelManagerOpts, core.WithMockProcesses(core.MockConfig{
+			ConnectDelay: *mockDelay,
+			FailureRate:  *mockFailureRate,
+			MeanLifetime: *mockLifetime,
+		}))
+	}
+
+	// prune has to inspect the PID store before the tunnel manager reconciles it
+	if flag.NArg() > 0 && flag.Arg(0) == "prune" {
+		os.Exit(cmdPrune(configStore, pidStore, tunnelManagerOpts, flag.Args()[1:]))
+	}
 	tunnelManager := core.NewTunnelManager(configStore, pidStore, tunnelManagerOpts...)
 
+	// Handle non-interactive subcommands
+	if flag.NArg() > 0 {
+		exitAfterHooks(tunnelManager, runCommand(tunnelManager, configStore, flag.Args()))
+	}
+
+	// Handle stop flags
+	if *stopAll {
+		handleStopAll(tunnelManager)
+		exitAfterHooks(tunnelManager, 0)
+	}
+	if *stopProfile != "" {
+		handleStopProfile(tunnelManager, *stopProfile)
+		exitAfterHooks(tunnelManager, 0)
+	}
+
+	// Handle events streaming
+	if *events {
+		handleEvents(tunnelManager)
+		exitAfterHooks(tunnelManager, 0)
+	}
+
 	// Handle auto-connect profile
+	if *autoProfile != "" && *supervise {
+		exitAfterHooks(tunnelManager, superviseProfile(tunnelManager, *autoProfile, 0))
+	}
 	if *autoProfile != "" {
 		core.Info("Starting all tunnels in profile: %s", *autoProfile)
 		if err := tunnelManager.StartProfileTunnels(*autoProfile); err != nil {
 			core.Error("Failed to start tunnels: %v", err)
-			os.Exit(1)
+			exitAfterHooks(tunnelManager, 1)
 		}
 		core.Info("Successfully started tunnels in profile: %s", *autoProfile)
 		// Exit after auto-connecting, don't start TUI
-		os.Exit(0)
+		exitAfterHooks(tunnelManager, 0)
 	}
 
 	// Setup signal handlers for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// With a daemon running, the TUI leaves running tunnels to it
+	followCtx, stopFollowing := context.WithCancel(context.Background())
+	defer stopFollowing()
+	followDaemon(followCtx, tunnelManager)
+
 	// Create and run TUI application in a goroutine
 	app := tui.NewApp(tunnelManager, configStore)
 	app.SetInitialProfile(*profile)
+	app.SetASCII(*ascii)
 
 	appErr := make(chan error, 1)
 	go func() {
@@ -128,10 +203,33 @@ func main() {
 	// Clean shutdown - tunnels keep running unless explicitly stopped
 	core.Info("Tunnelman exiting. SSH tunnels remain running.")
 	core.Info("To stop all tunnels, run: tunnelman --stop-all")
+	waitForHooks(tunnelManager)
 }
 
-// handleStopAll stops all running tunnels
+// exitAfterHooks exits with code once hooks started by this process finish
+func exitAfterHooks(tunnelManager *core.TunnelManager, code int) {
+	waitForHooks(tunnelManager)
+	os.Exit(code)
+}
+
+// waitForHooks waits for running hooks, giving up after the hook timeout. Hooks
+// still running keep running after tunnelman exits.
+func waitForHooks(tunnelManager *core.TunnelManager) {
+	ctx, cancel := context.WithTimeout(context.Background(), core.HookTimeout)
+	defer cancel()
+	if err := tunnelManager.WaitHooks(ctx); err != nil {
+		core.Warn("Hooks are still running")
+	}
+}
+
+// handleStopAll stops all running tunnels and reports what was stopped
 func handleStopAll(tunnelManager *core.TunnelManager) {
+	running := runningTunnels(tunnelManager.GetTunnels())
+	if len(running) == 0 {
+		fmt.Println("No running tunnels")
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -140,5 +238,45 @@ func handleStopAll(tunnelManager *core.TunnelManager) {
 		core.Error("Failed to stop all tunnels: %v", err)
 		os.Exit(1)
 	}
-	core.Info("All tunnels stopped")
-}
\ No newline at end of file
+	reportStopped(tunnelManager, running)
+}
+
+// handleStopProfile stops all running tunnels in a profile and reports what was stopped
+func handleStopProfile(tunnelManager *core.TunnelManager, profileName string) {
+	running := runningTunnels(tunnelManager.GetTunnelsByProfile(profileName))
+	if len(running) == 0 {
+		fmt.Printf("No running tunnels in profile '%s'\n", profileName)
+		return
+	}
+
+	core.Info("Stopping running tunnels in profile: %s", profileName)
+	err := tunnelManager.StopProfileTunnels(profileName)
+	reportStopped(tunnelManager, running)
+	if err != nil {
+		core.Error("Failed to stop some tunnels: %v", err)
+		os.Exit(1)
+	}
+}
+
+// runningTunnels returns the tunnels that are currently running
+func runningTunnels(tunnels []*core.Tunnel) []*core.Tunnel {
+	var running []*core.Tunnel
+	for _, t := range tunnels {
+		if t.Status == core.StatusRunning {
+			running = append(running, t)
+		}
+	}
+	return running
+}
+
+// reportStopped prints which of the previously running tunnels are now stopped
+func reportStopped(tunnelManager *core.TunnelManager, tunnels []*core.Tunnel) {
+	for _, t := range tunnels {
+		current, err := tunnelManager.GetTunnel(t.ID)
+		if err == nil && current.Status == core.StatusRunning {
+			fmt.Printf("Failed to stop %s (PID %d)\n", t.Name, t.PID)
+			continue
+		}
+		fmt.Printf("Stopped %s (PID %d)\n", t.Name, t.PID)
+	}
+}
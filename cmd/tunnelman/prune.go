@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// cmdPrune removes stale PID entries and reports ssh tunnel processes that are
+// running but not tracked, optionally adopting or terminating them. It runs
+// before the tunnel manager is created, since creating one already drops stale
+// entries from the PID store.
+func cmdPrune(configStore *store.ConfigStore, pidStore *store.PIDStore, opts []core.TunnelManagerOption, args []string) int {
+	fs := flag.NewFlagSet("prune", flag.ContinueOnError)
+	adopt := fs.Bool("adopt", false, "Track untracked processes that match a configured tunnel")
+	kill := fs.Bool("kill", false, "Terminate untracked processes that are not adopted")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman prune [-adopt] [-kill]")
+		return 2
+	}
+
+	removed, err := pidStore.CleanupStalePids()
+	if err != nil {
+		core.Error("Failed to clean up PID store: %v", err)
+		return 1
+	}
+
+	tunnelManager := core.NewTunnelManager(configStore, pidStore, opts...)
+
+	ids := make([]string, 0, len(removed))
+	for id := range removed {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		name := id
+		if tunnel, err := tunnelManager.GetTunnel(id); err == nil {
+			name = tunnel.Name
+		}
+		fmt.Printf("Removed stale PID entry: %s (PID %d)\n", name, removed[id].PID)
+	}
+	if len(removed) == 0 {
+		fmt.Println("No stale PID entries")
+	}
+
+	orphans, err := tunnelManager.FindOrphanedProcesses()
+	if err != nil {
+		core.Error("Failed to look for untracked ssh processes: %v", err)
+		return 1
+	}
+	exitCode := 0
+	for _, orphan := range orphans {
+		match := "no matching tunnel"
+		if orphan.TunnelName != "" {
+			match = "matches tunnel " + orphan.TunnelName
+		}
+		fmt.Printf("Untracked ssh process: PID %d (%s): %s\n", orphan.PID, match, strings.Join(orphan.Args, " "))
+
+		switch {
+		case *adopt && orphan.TunnelID != "":
+			if err := tunnelManager.AdoptOrphan(orphan); err != nil {
+				core.Error("Failed to adopt PID %d: %v", orphan.PID, err)
+				exitCode = 1
+				continue
+			}
+			fmt.Printf("Adopted PID %d as tunnel %s\n", orphan.PID, orphan.TunnelName)
+		case *kill:
+			if err := tunnelManager.KillOrphan(orphan); err != nil {
+				core.Error("Failed to terminate PID %d: %v", orphan.PID, err)
+				exitCode = 1
+				continue
+			}
+			fmt.Printf("Terminated PID %d\n", orphan.PID)
+		}
+	}
+	if len(orphans) == 0 {
+		fmt.Println("No untracked ssh processes")
+	}
+
+	return exitCode
+}
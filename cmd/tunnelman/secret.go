@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// secretUsage describes the secret subcommand
+const secretUsage = `Usage: tunnelman secret set|get|rm NAME
+
+  set  Store a secret, read from the terminal without echo or from stdin
+  get  Print a secret, e.g. for a ProxyCommand that needs a credential
+  rm   Remove a secret
+
+Tunnels answer ssh's password and passphrase prompts with the secret named by
+their --secret.
+`
+
+// cmdSecret stores, prints and removes the keychain secrets tunnels reference
+func cmdSecret(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprint(os.Stderr, secretUsage)
+		return 2
+	}
+	action, name := args[0], args[1]
+
+	var err error
+	switch action {
+	case "set":
+		var secret string
+		if secret, err = readSecret(fmt.Sprintf("Secret for %s: ", name)); err == nil {
+			err = core.SetSecret(name, secret)
+		}
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "Stored secret %s in the keychain\n", name)
+		}
+	case "get":
+		var secret string
+		if secret, err = core.GetSecret(name); err == nil {
+			fmt.Println(secret)
+		}
+	case "rm", "delete":
+		if err = core.DeleteSecret(name); err == nil {
+			fmt.Fprintf(os.Stderr, "Removed secret %s\n", name)
+		}
+	default:
+		fmt.Fprint(os.Stderr, secretUsage)
+		return 2
+	}
+
+	if errors.Is(err, core.ErrSecretNotFound) {
+		core.Error("No secret named %s in the keychain", name)
+		return 1
+	}
+	if err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+	return 0
+}
+
+// readSecret reads a secret from the terminal without echoing it, or the
+// first line of stdin when it is not a terminal
+func readSecret(prompt string) (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return "", err
+	}
+	terminal := info.Mode()&os.ModeCharDevice != 0
+
+	if terminal {
+		fmt.Fprint(os.Stderr, prompt)
+		restore, err := disableEcho()
+		if err != nil {
+			return "", fmt.Errorf("failed to hide input: %w", err)
+		}
+		defer func() {
+			restore()
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
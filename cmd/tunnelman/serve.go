@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/takaaki-s/tunnelman/internal/api"
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// cmdServe runs the REST control API in the foreground until interrupted
+func cmdServe(tunnelManager *core.TunnelManager, configStore *store.ConfigStore, args []string) int {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	listen := fs.String("listen", "127.0.0.1:7070", "Address to listen on")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman serve [--listen ADDR]")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	token, err := store.GetAPIToken()
+	if err != nil {
+		core.Error("Failed to set up API token: %v", err)
+		return 1
+	}
+	if os.Getenv(store.APITokenEnv) == "" {
+		if path, err := store.GetAPITokenPath(); err == nil {
+			core.Info("Requests that start or stop tunnels need the bearer token in %s", path)
+		}
+	}
+
+	server := api.NewServer(tunnelManager, configStore, version, api.WithToken(token))
+	if err := server.ListenAndServe(ctx, *listen); err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+
+	core.Info("API server exiting. SSH tunnels remain running.")
+	return 0
+}
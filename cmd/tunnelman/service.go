@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/service"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// cmdService installs, removes or reports on the background service for a profile
+func cmdService(configStore *store.ConfigStore, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman service install|uninstall|start|stop|status [--profile P]")
+		return 2
+	}
+	action := args[0]
+
+	fs := flag.NewFlagSet("service "+action, flag.ContinueOnError)
+	profile := fs.String("profile", "default", "Profile whose tunnels the service supervises")
+	printOnly := fs.Bool("print", false, "Print the service definition instead of installing it")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	manager, err := service.NewManager()
+	if err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+
+	switch action {
+	case "install":
+		configPath, err := configStore.GetConfigPath()
+		if err != nil {
+			core.Error("Failed to resolve config path: %v", err)
+			return 1
+		}
+		opts, err := service.DefaultOptions(*profile, configPath)
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+
+		if *printOnly {
+			definition, err := manager.Definition(opts)
+			if err != nil {
+				core.Error("%v", err)
+				return 1
+			}
+			fmt.Print(definition)
+			return 0
+		}
+
+		if err := manager.Install(opts); err != nil {
+			core.Error("Failed to install service: %v", err)
+			return 1
+		}
+		fmt.Printf("Installed service for profile %s\n", *profile)
+
+	case "uninstall":
+		if err := manager.Uninstall(*profile); err != nil {
+			core.Error("Failed to uninstall service: %v", err)
+			return 1
+		}
+		fmt.Printf("Uninstalled service for profile %s\n", *profile)
+
+	case "start":
+		if err := manager.Start(*profile); err != nil {
+			core.Error("Failed to start service: %v", err)
+			return 1
+		}
+		fmt.Printf("Started service for profile %s\n", *profile)
+
+	case "stop":
+		if err := manager.Stop(*profile); err != nil {
+			core.Error("Failed to stop service: %v", err)
+			return 1
+		}
+		fmt.Printf("Stopped service for profile %s\n", *profile)
+
+	case "status":
+		status, err := manager.Status(*profile)
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		fmt.Println(status)
+
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown service action: %s\n", action)
+		return 2
+	}
+	return 0
+}
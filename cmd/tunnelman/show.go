@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// cmdShow prints a tunnel's settings and the ssh command it would run,
+// without starting it
+func cmdShow(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("show", flag.ContinueOnError)
+	commandOnly := fs.Bool("command", false, "Print only the ssh command, quoted for the shell")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(names) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman show [--command] <name|id>")
+		return 2
+	}
+
+	tunnel, err := tunnelManager.FindTunnel(names[0])
+	if err != nil {
+		core.Error("%v", err)
+		return lifecycleExitUnknown
+	}
+	command, err := tunnelManager.Preview(tunnel.ID)
+	if err != nil {
+		core.Error("Tunnel %s would not start: %v", tunnel.Name, err)
+		return 1
+	}
+
+	if *commandOnly {
+		fmt.Println(core.QuoteCommand(command))
+		return 0
+	}
+
+	fmt.Printf("Name:     %s\n", tunnel.Name)
+	fmt.Printf("ID:       %s\n", tunnel.ID)
+	if tunnel.Description != "" {
+		fmt.Printf("About:    %s\n", tunnel.Description)
+	}
+	fmt.Printf("Profile:  %s\n", tunnel.Profile)
+	if tunnel.Source != "" {
+		fmt.Printf("From:     %s\n", tunnel.Source)
+	}
+	if len(tunnel.Tags) > 0 {
+		fmt.Printf("Tags:     %s\n", strings.Join(tunnel.Tags, ", "))
+	}
+	fmt.Printf("Host:     %s\n", strings.Join(tunnel.HopChain(), " → "))
+	if settings, err := tunnelManager.HostSettings(tunnel); err == nil {
+		fmt.Printf("Resolves: %s (%s)\n", settings.Address(), settings.Source)
+		if settings.ProxyJump != "" {
+			fmt.Printf("Jump:     %s\n", settings.ProxyJump)
+		}
+		if len(settings.IdentityFiles) > 0 {
+			fmt.Printf("Keys:     %s\n", strings.Join(settings.IdentityFiles, ", "))
+		}
+	} else {
+		core.Warn("Failed to resolve %s: %v", tunnel.SSHHost, err)
+	}
+	fmt.Printf("Forwards: %s\n", tunnel.ForwardSummary())
+	if tunnel.Secret != "" {
+		fmt.Printf("Secret:   %s (keychain)\n", tunnel.Secret)
+	}
+	if tunnel.HostKey != "" {
+		fmt.Printf("Host key: %s (pinned)\n", tunnel.HostKey)
+	}
+	if tunnel.CertificateFile != "" {
+		fmt.Printf("Cert:     %s\n", describeCertificate(tunnel.CertificateFile))
+	}
+	fmt.Printf("Command:  %s\n", core.QuoteCommand(command))
+	return 0
+}
+
+// describeCertificate names a certificate file and when it expires
+func describeCertificate(path string) string {
+	cert, err := core.ReadCertificate(path)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s (%v)", path, err)
+	case cert.ValidBefore.IsZero():
+		return fmt.Sprintf("%s (never expires)", path)
+	case cert.Expired(time.Now()):
+		return fmt.Sprintf("%s (expired %s)", path, cert.ValidBefore.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("%s (expires %s)", path, cert.ValidBefore.Format(time.RFC3339))
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// Exit codes reported by the status command
+const (
+	statusExitRunning = 0
+	statusExitStopped = 1
+	statusExitError   = 2
+	statusExitUnknown = 3
+)
+
+// cmdStatus reports a single tunnel's status, using the exit code to signal its state
+func cmdStatus(ctrl controller, args []string) int {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	jsonOutput := fs.Bool("json", false, "Output as JSON")
+	quiet := fs.Bool("quiet", false, "Print nothing, only set the exit code")
+	if err := fs.Parse(args); err != nil {
+		return statusExitUnknown
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman status [--json] [--quiet] <name|id>")
+		return statusExitUnknown
+	}
+
+	tunnel, err := ctrl.Find(fs.Arg(0))
+	if err != nil {
+		core.Error("%v", err)
+		return statusExitUnknown
+	}
+
+	exitCode := statusExitStopped
+	switch tunnel.Status {
+	case core.StatusRunning:
+		exitCode = statusExitRunning
+	case core.StatusError:
+		exitCode = statusExitError
+	}
+
+	switch {
+	case *quiet:
+	case *jsonOutput:
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(tunnel); err != nil {
+			core.Error("Failed to encode status: %v", err)
+		}
+	default:
+		line := fmt.Sprintf("%s: %s", tunnel.Name, tunnel.Status)
+		if tunnel.PID > 0 {
+			line += fmt.Sprintf(" (PID %d", tunnel.PID)
+			if tunnel.StartedAt != nil {
+				line += fmt.Sprintf(", up %s", core.FormatDuration(time.Since(*tunnel.StartedAt)))
+			}
+			line += ")"
+		}
+		if tunnel.Error != "" {
+			line += fmt.Sprintf(": %s", tunnel.Error)
+		}
+		fmt.Println(line)
+		if tunnel.Hint != "" {
+			fmt.Printf("  hint: %s\n", tunnel.Hint)
+		}
+		if len(tunnel.Hops) > 0 {
+			hops := make([]string, len(tunnel.Hops))
+			for i, hop := range tunnel.Hops {
+				hops[i] = fmt.Sprintf("%s (%s)", hop.Host, hop.State)
+			}
+			fmt.Printf("  chain: %s\n", strings.Join(hops, " -> "))
+		}
+	}
+
+	return exitCode
+}
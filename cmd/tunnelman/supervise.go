@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/service"
+)
+
+// cmdSupervise keeps the tunnels of a profile running in the foreground until
+// SIGINT or SIGTERM, then stops them
+func cmdSupervise(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("supervise", flag.ContinueOnError)
+	profile := fs.String("profile", "default", "Profile whose tunnels are supervised")
+	maxBackoff := fs.Duration("max-backoff", 0, "Maximum delay between restart attempts (default from the config's reconnect policy, else 1m)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman supervise [--profile P] [--max-backoff D]")
+		return 2
+	}
+	return superviseProfile(tunnelManager, *profile, *maxBackoff)
+}
+
+// superviseProfile runs a supervisor for every tunnel in profile until
+// interrupted. A zero maxBackoff uses the config's reconnect policy.
+func superviseProfile(tunnelManager *core.TunnelManager, profile string, maxBackoff time.Duration) int {
+	tunnels := tunnelManager.GetTunnelsByProfile(profile)
+	if len(tunnels) == 0 {
+		core.Error("No tunnels in profile: %s", profile)
+		return 1
+	}
+
+	ids := make([]string, 0, len(tunnels))
+	for _, t := range tunnels {
+		ids = append(ids, t.ID)
+	}
+
+	core.Info("Supervising %d tunnel(s) in profile: %s", len(ids), profile)
+	var opts []core.SupervisorOption
+	if maxBackoff > 0 {
+		opts = append(opts, core.WithMaxBackoff(maxBackoff))
+	}
+	supervisor := core.NewSupervisor(tunnelManager, ids, opts...)
+
+	// Under the Windows service control manager there are no signals; the
+	// service handler cancels the context when the service is stopped
+	if service.IsWindowsService() {
+		if err := service.RunWindowsService(profile, supervisor.Run); err != nil {
+			core.Error("Service failed: %v", err)
+			return 1
+		}
+		core.Info("Supervisor exiting")
+		return 0
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	supervisor.Run(ctx)
+
+	core.Info("Supervisor exiting")
+	return 0
+}
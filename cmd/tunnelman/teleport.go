@@ -0,0 +1,160 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// teleportUsage describes the teleport subcommand
+const teleportUsage = `Usage: tunnelman teleport ls [--cluster C] [LABEL=VALUE...]
+       tunnelman teleport add [--cluster C] [--login USER] [--name N] [--profile P] -L|-R|-D SPEC... NODE...
+
+  ls   List the nodes of the Teleport cluster you are logged in to with tsh
+  add  Add a tunnel to each node, reaching it through the cluster's proxy
+       with tsh proxy ssh
+
+Log in with tsh login first; ssh authenticates with the keys tsh adds to
+ssh-agent.
+`
+
+// cmdTeleport lists the nodes of a Teleport cluster and adds tunnels to them
+func cmdTeleport(tunnelManager *core.TunnelManager, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, teleportUsage)
+		return 2
+	}
+	switch args[0] {
+	case "ls", "list":
+		return teleportList(args[1:])
+	case "add":
+		return teleportAdd(tunnelManager, args[1:])
+	default:
+		fmt.Fprint(os.Stderr, teleportUsage)
+		return 2
+	}
+}
+
+// teleportList prints the nodes of a cluster with their labels
+func teleportList(args []string) int {
+	fs := flag.NewFlagSet("teleport ls", flag.ContinueOnError)
+	cluster := fs.String("cluster", "", "Teleport cluster (default: the current one)")
+	labels, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+
+	nodes, err := core.ListTeleportNodes(*cluster, labels)
+	if err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+	if len(nodes) == 0 {
+		fmt.Println("No nodes found")
+		return 0
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NODE\tADDRESS\tLABELS")
+	for _, node := range nodes {
+		addr := node.Addr
+		if addr == "" {
+			addr = "(reverse tunnel)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", node.Hostname, addr, formatLabels(node.Labels))
+	}
+	return flushOrFail(w)
+}
+
+// teleportAdd adds a tunnel with the given forwards to each named node
+func teleportAdd(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("teleport add", flag.ContinueOnError)
+	var forward forwardFlags
+	forward.register(fs)
+	cluster := fs.String("cluster", "", "Teleport cluster (default: the current one)")
+	login := fs.String("login", "", "User to log in to the nodes as (default: the first login tsh allows)")
+	name := fs.String("name", "", "Tunnel name, with a single node (default: the node's host name)")
+	profile := fs.String("profile", "default", "Profile to add the tunnels to")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(names) == 0 || len(forward.specs) == 0 || (*name != "" && len(names) > 1) {
+		fmt.Fprint(os.Stderr, teleportUsage)
+		return 2
+	}
+
+	// The tunnels keep the current cluster, so they work after switching to another
+	if *login == "" || *cluster == "" {
+		status, err := core.GetTeleportStatus()
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		if *login == "" && len(status.Logins) > 0 {
+			*login = status.Logins[0]
+		}
+		if *cluster == "" {
+			*cluster = status.Cluster
+		}
+	}
+
+	nodes, err := core.ListTeleportNodes(*cluster, nil)
+	if err != nil {
+		core.Error("%v", err)
+		return 1
+	}
+	byName := make(map[string]core.TeleportNode)
+	for _, node := range nodes {
+		byName[node.ID] = node
+		byName[node.Hostname] = node
+	}
+
+	var tunnels []*core.Tunnel
+	for _, nodeName := range names {
+		node, ok := byName[nodeName]
+		if !ok {
+			core.Error("No Teleport node named %s", nodeName)
+			return 1
+		}
+		tunnel := core.NewTeleportTunnel(node, *cluster, *login)
+		if *name != "" {
+			tunnel.Name = *name
+		}
+		tunnel.Profile = *profile
+		if _, err := forward.apply(tunnel); err != nil {
+			core.Error("%v", err)
+			return 2
+		}
+		if err := tunnel.Validate(); err != nil {
+			core.Error("Invalid tunnel to %s: %v", nodeName, err)
+			return 1
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+
+	added, err := tunnelManager.AddImportedTunnels(tunnels, *profile)
+	if err != nil {
+		core.Error("Failed to add tunnels: %v", err)
+		return 1
+	}
+	for _, tunnel := range added {
+		fmt.Printf("Added tunnel %s: %s via %s\n", tunnel.Name, tunnel.ForwardSummary(), tunnel.SSHHost)
+	}
+	return 0
+}
+
+// formatLabels joins labels as key=value pairs in key order
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, " ")
+}
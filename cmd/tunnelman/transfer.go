@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// exportUsage describes the export command
+const exportUsage = `Usage: tunnelman export [--profile P] [-o FILE]
+       tunnelman export --format ssh [--profile P] [--prefix PREFIX] [-o FILE] [--write] [name|id...]`
+
+// cmdExport writes tunnel definitions as JSON for sharing with other machines,
+// or as SSH config Host blocks
+func cmdExport(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	profile := fs.String("profile", "", "Only export tunnels in this profile")
+	output := fs.String("o", "", "Write to `file` instead of stdout")
+	format := fs.String("format", "json", "Export as json or as ssh config Host blocks")
+	prefix := fs.String("prefix", core.DefaultSSHHostPrefix, "Start the Host aliases of --format ssh with `prefix`")
+	write := fs.Bool("write", false, "Replace the tunnelman section of ~/.ssh/config, or of -o FILE, with --format ssh")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	switch {
+	case *format == "ssh":
+		return exportSSHConfig(tunnelManager, names, *profile, *prefix, *output, *write)
+	case *format != "json" || len(names) > 0 || *write:
+		fmt.Fprintln(os.Stderr, exportUsage)
+		return 2
+	}
+
+	data, err := json.MarshalIndent(tunnelManager.ExportConfig(*profile), "", "  ")
+	if err != nil {
+		core.Error("Failed to encode tunnels: %v", err)
+		return 1
+	}
+	data = append(data, '\n')
+
+	if *output == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := os.WriteFile(*output, data, 0644); err != nil {
+		core.Error("Failed to write %s: %v", *output, err)
+		return 1
+	}
+	return 0
+}
+
+// exportSSHConfig writes tunnels as SSH config Host blocks: all of them, those
+// of a profile or those named
+func exportSSHConfig(tunnelManager *core.TunnelManager, names []string, profile, prefix, output string, write bool) int {
+	var tunnels []*core.Tunnel
+	if len(names) == 0 {
+		for _, tunnel := range tunnelManager.GetTunnels() {
+			if profile == "" || tunnel.Profile == profile {
+				tunnels = append(tunnels, tunnel)
+			}
+		}
+	}
+	for _, name := range names {
+		tunnel, err := tunnelManager.FindTunnel(name)
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+
+	content := core.NewSSHConfigExporter(core.WithSSHHostPrefix(prefix)).Render(tunnels)
+	switch {
+	case write:
+		path := output
+		if path == "" {
+			path = core.DefaultSSHConfigPath()
+		}
+		if err := core.WriteSSHConfigSection(path, content); err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Wrote %d tunnel(s) to %s\n", len(tunnels), path)
+	case output != "":
+		if err := os.WriteFile(output, []byte(content), 0600); err != nil {
+			core.Error("Failed to write %s: %v", output, err)
+			return 1
+		}
+	default:
+		fmt.Print(content)
+	}
+	return 0
+}
+
+// cmdImport reads tunnel definitions and profile settings written by export
+// and adds them to the config
+func cmdImport(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	merge := fs.Bool("merge", false, "Add the imported tunnels to the existing ones (default)")
+	replace := fs.Bool("replace", false, "Remove existing tunnels that are not running first")
+	conflict := fs.String("conflict", string(core.ConflictSkip), "On an ID or name clash: skip, overwrite or rename")
+	profile := fs.String("profile", "", "Import the tunnels of a single exported profile into `profile`")
+	putty := fs.Bool("putty", false, "Import the port forwardings of PuTTY saved sessions, all or the named ones")
+	sshConfig := fs.Bool("ssh-config", false, "Import ~/.ssh/config hosts again, or the named one, updating the tunnels imported before")
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if *putty {
+		return importPuTTY(tunnelManager, files, *profile)
+	}
+	if *sshConfig {
+		return syncSSHConfig(tunnelManager, files, *profile)
+	}
+	if len(files) != 1 || (*merge && *replace) {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman import [--merge|--replace] [--conflict skip|overwrite|rename] [--profile P] <file|->")
+		fmt.Fprintln(os.Stderr, "       tunnelman import --putty [--profile P] [session...]")
+		fmt.Fprintln(os.Stderr, "       tunnelman import --ssh-config [--profile P] [host]")
+		return 2
+	}
+
+	mode := core.ImportMerge
+	if *replace {
+		mode = core.ImportReplace
+	}
+
+	var data []byte
+	if files[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(files[0])
+	}
+	if err != nil {
+		core.Error("Failed to read %s: %v", files[0], err)
+		return 1
+	}
+
+	config, err := store.ParseConfig(data)
+	if err != nil {
+		core.Error("Failed to parse %s: %v", files[0], err)
+		return 1
+	}
+
+	result, err := tunnelManager.ImportConfig(config, *profile, mode, core.ConflictPolicy(*conflict))
+	if err != nil {
+		core.Error("Import failed: %v", err)
+		return 1
+	}
+
+	printImportGroup("Removed", result.Removed)
+	printImportGroup("Added", result.Added)
+	printImportGroup("Updated", result.Updated)
+	printImportGroup("Renamed", result.Renamed)
+	printImportGroup("Skipped", result.Skipped)
+	printImportGroup("Profiles", result.Profiles)
+	return 0
+}
+
+// importPuTTY adds a tunnel for each PuTTY saved session with port
+// forwardings, or for the named sessions, skipping those imported before
+func importPuTTY(tunnelManager *core.TunnelManager, sessions []string, profile string) int {
+	tunnels, notes, err := core.PuTTYTunnels(sessions)
+	if err != nil {
+		core.Error("Failed to read PuTTY sessions: %v", err)
+		return 1
+	}
+	for _, note := range notes {
+		core.Warn("%s", note)
+	}
+	if len(tunnels) == 0 {
+		fmt.Println("No PuTTY sessions with port forwardings found")
+		return 0
+	}
+
+	added, err := tunnelManager.AddImportedTunnels(tunnels, profile)
+	if err != nil {
+		core.Error("Import failed: %v", err)
+		return 1
+	}
+	imported := make(map[string]bool)
+	var addedNames, skippedNames []string
+	for _, tunnel := range added {
+		imported[tunnel.ID] = true
+		addedNames = append(addedNames, tunnel.Name)
+	}
+	for _, tunnel := range tunnels {
+		if !imported[tunnel.ID] {
+			skippedNames = append(skippedNames, tunnel.Name)
+		}
+	}
+	printImportGroup("Added", addedNames)
+	printImportGroup("Skipped", skippedNames)
+	return 0
+}
+
+// syncSSHConfig imports the forwards of a host of the SSH config, or of all
+// hosts, updating and removing the tunnels imported from it before
+func syncSSHConfig(tunnelManager *core.TunnelManager, hosts []string, profile string) int {
+	if len(hosts) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman import --ssh-config [--profile P] [host]")
+		return 2
+	}
+	host := ""
+	if len(hosts) == 1 {
+		host = hosts[0]
+	}
+
+	result, err := tunnelManager.SyncSSHConfig(host, profile)
+	if err != nil {
+		core.Error("Import failed: %v", err)
+		return 1
+	}
+	printImportGroup("Added", result.Added)
+	printImportGroup("Updated", result.Updated)
+	printImportGroup("Removed", result.Removed)
+	printImportGroup("Skipped (running)", result.Skipped)
+	if len(result.Added)+len(result.Updated)+len(result.Removed)+len(result.Skipped) == 0 {
+		fmt.Println("The imported tunnels match the SSH config")
+	}
+	return 0
+}
+
+// printImportGroup prints one line per category of an import result
+func printImportGroup(label string, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d): %s\n", label, len(names), strings.Join(names, ", "))
+}
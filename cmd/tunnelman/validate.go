@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// cmdValidate checks the config, or a config file such as an export, for
+// problems and exits with 1 if it finds any
+func cmdValidate(configStore *store.ConfigStore, args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	files, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(files) > 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman validate [FILE]")
+		return 2
+	}
+
+	var config *store.AppConfig
+	path, _ := configStore.GetConfigPath()
+	if len(files) == 1 {
+		path = files[0]
+		data, err := os.ReadFile(path)
+		if err == nil {
+			config, err = store.ParseConfig(data)
+		}
+		if err != nil {
+			core.Error("Failed to load %s: %v", path, err)
+			return 1
+		}
+	} else if config, err = configStore.LoadConfig(); err != nil {
+		core.Error("Failed to load config: %v", err)
+		return 1
+	}
+
+	issues := core.ValidateConfig(config)
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+	if len(issues) > 0 {
+		fmt.Fprintf(os.Stderr, "%s: %d problem(s)\n", path, len(issues))
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%s: no problems found\n", path)
+	return 0
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// cmdWait blocks until the given tunnels accept connections on their local ports
+func cmdWait(tunnelManager *core.TunnelManager, args []string) int {
+	fs := flag.NewFlagSet("wait", flag.ContinueOnError)
+	timeout := fs.Duration("timeout", 30*time.Second, "Give up after this long")
+	profile := fs.String("profile", "", "Wait for every tunnel in this profile")
+	quiet := fs.Bool("quiet", false, "Print nothing, only set the exit code")
+	names, err := parseInterspersed(fs, args)
+	if err != nil {
+		return 2
+	}
+	if len(names) == 0 && *profile == "" {
+		fmt.Fprintln(os.Stderr, "Usage: tunnelman wait [--timeout D] [--profile P] [<name|id>...]")
+		return 2
+	}
+
+	var tunnels []*core.Tunnel
+	if *profile != "" {
+		tunnels = tunnelManager.GetTunnelsByProfile(*profile)
+		if len(tunnels) == 0 {
+			core.Error("No tunnels in profile: %s", *profile)
+			return 1
+		}
+	}
+	for _, name := range names {
+		tunnel, err := tunnelManager.FindTunnel(name)
+		if err != nil {
+			core.Error("%v", err)
+			return 1
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	for _, t := range tunnels {
+		if err := tunnelManager.WaitReady(ctx, t.ID); err != nil {
+			if !*quiet {
+				core.Error("%v", err)
+			}
+			return 1
+		}
+		if !*quiet {
+			fmt.Printf("Ready: %s (%s)\n", t.Name, t.ForwardSummary())
+		}
+	}
+	return 0
+}
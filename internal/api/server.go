@@ -0,0 +1,344 @@
+// Package api provides the REST control API served by `tunnelman serve`.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// Server exposes a TunnelManager over HTTP
+type Server struct {
+	manager     *core.TunnelManager
+	configStore *store.ConfigStore
+	version     string
+	startedAt   time.Time
+
+	// Bearer token required by requests that change tunnels; empty refuses them
+	token string
+}
+
+// ServerOption is a functional option for Server
+type ServerOption func(*Server)
+
+// WithToken sets the bearer token that requests starting or stopping tunnels
+// must present
+func WithToken(token string) ServerOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// ProfileInfo is the JSON representation of a profile
+type ProfileInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	AutoConnect bool   `json:"auto_connect"`
+	Tunnels     int    `json:"tunnels"`
+	Running     int    `json:"running"`
+}
+
+// HealthInfo is the JSON body returned by the health endpoint
+type HealthInfo struct {
+	Status        string `json:"status"`
+	Version       string `json:"version"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+	Tunnels       int    `json:"tunnels"`
+	Running       int    `json:"running"`
+}
+
+// errorBody is the JSON body returned for failed requests
+type errorBody struct {
+	Error string `json:"error"`
+	Code  string `json:"code,omitempty"`
+}
+
+// NewServer creates a new REST API server
+func NewServer(manager *core.TunnelManager, configStore *store.ConfigStore, version string, opts ...ServerOption) *Server {
+	s := &Server{
+		manager:     manager,
+		configStore: configStore,
+		version:     version,
+		startedAt:   time.Now(),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Handler returns the HTTP handler serving the API routes. Requests must be
+// addressed to a loopback host, and those changing tunnels must present the
+// server's bearer token.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/health", s.handleHealth)
+	mux.HandleFunc("GET /api/tunnels", s.handleList)
+	mux.HandleFunc("GET /api/tunnels/{tunnel}", s.handleGet)
+	mux.HandleFunc("POST /api/tunnels/{tunnel}/start", s.authorized(s.lifecycle(s.manager.StartTunnel)))
+	mux.HandleFunc("POST /api/tunnels/{tunnel}/stop", s.authorized(s.lifecycle(s.manager.StopTunnel)))
+	mux.HandleFunc("POST /api/tunnels/{tunnel}/restart", s.authorized(s.lifecycle(s.manager.RestartTunnel)))
+	mux.HandleFunc("GET /api/profiles", s.handleProfiles)
+	mux.HandleFunc("POST /api/profiles/{profile}/start", s.authorized(s.profileAction(s.manager.StartProfileTunnels)))
+	mux.HandleFunc("POST /api/profiles/{profile}/stop", s.authorized(s.profileAction(s.manager.StopProfileTunnels)))
+	return loopbackOnly(mux)
+}
+
+// loopbackOnly refuses requests whose Host header is not localhost or a
+// loopback address. A web page could otherwise reach the API by pointing a
+// name it controls at 127.0.0.1 (DNS rebinding).
+func loopbackOnly(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host not allowed: %s", r.Host))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether a Host header names this machine by its
+// loopback name or address
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// authorized returns a handler that runs next only for requests presenting
+// the server's bearer token, which a browser cannot send on a page's behalf
+func (s *Server) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if s.token == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, errors.New("missing or wrong API token"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	// Keep states in sync with other tunnelman processes
+	go core.NewStatusBroadcaster(s.manager).Run(ctx)
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	core.Info("REST API listening on http://%s", listener.Addr())
+
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
+
+// reload picks up tunnels added or edited by other processes
+func (s *Server) reload() {
+	if err := s.manager.ReloadConfig(); err != nil {
+		core.Warn("Failed to reload config: %v", err)
+	}
+}
+
+// handleHealth reports server liveness and a tunnel summary
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	tunnels := s.manager.GetTunnels()
+	running := 0
+	for _, t := range tunnels {
+		if t.Status == core.StatusRunning {
+			running++
+		}
+	}
+
+	writeJSON(w, http.StatusOK, HealthInfo{
+		Status:        "ok",
+		Version:       s.version,
+		UptimeSeconds: int64(time.Since(s.startedAt).Seconds()),
+		Tunnels:       len(tunnels),
+		Running:       running,
+	})
+}
+
+// handleList returns all tunnels, optionally filtered by the profile query parameter
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.reload()
+
+	var tunnels []*core.Tunnel
+	if profile := r.URL.Query().Get("profile"); profile != "" {
+		tunnels = s.manager.GetTunnelsByProfile(profile)
+	} else {
+		tunnels = s.manager.GetTunnels()
+	}
+
+	snapshots := make([]core.TunnelSnapshot, 0, len(tunnels))
+	for _, t := range tunnels {
+		snapshots = append(snapshots, t.Snapshot())
+	}
+	writeJSON(w, http.StatusOK, snapshots)
+}
+
+// handleGet returns a single tunnel by name or ID
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	s.reload()
+
+	tunnel, err := s.manager.FindTunnel(r.PathValue("tunnel"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tunnel.Snapshot())
+}
+
+// lifecycle returns a handler applying a start/stop/restart action to the named tunnel
+func (s *Server) lifecycle(fn func(id string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.reload()
+
+		tunnel, err := s.manager.FindTunnel(r.PathValue("tunnel"))
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		if err := fn(tunnel.ID); err != nil {
+			writeError(w, statusForError(err), err)
+			return
+		}
+		if current, err := s.manager.GetTunnel(tunnel.ID); err == nil {
+			tunnel = current
+		}
+		writeJSON(w, http.StatusOK, tunnel.Snapshot())
+	}
+}
+
+// profileAction returns a handler applying an action to all tunnels in a profile
+func (s *Server) profileAction(fn func(profile string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.reload()
+
+		profile := r.PathValue("profile")
+		tunnels := s.manager.GetTunnelsByProfile(profile)
+		if len(tunnels) == 0 {
+			writeError(w, http.StatusNotFound, fmt.Errorf("no tunnels in profile: %s", profile))
+			return
+		}
+		if err := fn(profile); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+
+		tunnels = s.manager.GetTunnelsByProfile(profile)
+		snapshots := make([]core.TunnelSnapshot, 0, len(tunnels))
+		for _, t := range tunnels {
+			snapshots = append(snapshots, t.Snapshot())
+		}
+		writeJSON(w, http.StatusOK, snapshots)
+	}
+}
+
+// handleProfiles lists configured profiles along with profiles referenced by tunnels
+func (s *Server) handleProfiles(w http.ResponseWriter, r *http.Request) {
+	s.reload()
+
+	config, err := s.configStore.LoadConfig()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	profiles := map[string]*ProfileInfo{
+		"default": {Name: "default"},
+	}
+	for _, p := range config.Profiles {
+		profiles[p.Name] = &ProfileInfo{
+			Name:        p.Name,
+			Description: p.Description,
+			AutoConnect: p.AutoConnect,
+		}
+	}
+	for _, t := range s.manager.GetTunnels() {
+		name := t.Profile
+		if name == "" {
+			name = "default"
+		}
+		info, exists := profiles[name]
+		if !exists {
+			info = &ProfileInfo{Name: name}
+			profiles[name] = info
+		}
+		info.Tunnels++
+		if t.Status == core.StatusRunning {
+			info.Running++
+		}
+	}
+
+	result := make([]ProfileInfo, 0, len(profiles))
+	for _, info := range profiles {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	writeJSON(w, http.StatusOK, result)
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		core.Debug("Failed to write response: %v", err)
+	}
+}
+
+// writeError writes an error as a JSON response
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorBody{Error: err.Error(), Code: core.ErrorCode(err)})
+}
+
+// statusForError returns the HTTP status for a failed tunnel action
+func statusForError(err error) int {
+	switch {
+	case errors.Is(err, core.ErrTunnelNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, core.ErrAuthFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusConflict
+	}
+}
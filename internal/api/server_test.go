@@ -0,0 +1,113 @@
+// Package api provides REST control API tests.
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// newTestServer creates an API server over a config with one stopped tunnel
+func newTestServer(t *testing.T, opts ...ServerOption) http.Handler {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+
+	configPath := filepath.Join(dir, "config.json")
+	configJSON := `{
+  "version": "2.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.example.com", "localPort": 15432, "remotePort": 5432, "mode": "local"}
+  ]
+}`
+	if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	configStore, err := store.NewConfigStore(configPath)
+	if err != nil {
+		t.Fatalf("Failed to create config store: %v", err)
+	}
+	pidStore, err := store.NewPIDStore()
+	if err != nil {
+		t.Fatalf("Failed to create PID store: %v", err)
+	}
+
+	manager := core.NewTunnelManager(configStore, pidStore)
+	return NewServer(manager, configStore, "test", opts...).Handler()
+}
+
+// TestLoopbackHosts tests that requests addressed to other host names are
+// refused, so web pages cannot reach the API through DNS rebinding
+func TestLoopbackHosts(t *testing.T) {
+	handler := newTestServer(t)
+
+	tests := []struct {
+		host string
+		want int
+	}{
+		{"127.0.0.1:7070", http.StatusOK},
+		{"localhost:7070", http.StatusOK},
+		{"[::1]:7070", http.StatusOK},
+		{"localhost", http.StatusOK},
+		{"attacker.example.com:7070", http.StatusForbidden},
+		{"192.168.1.10:7070", http.StatusForbidden},
+		{"localhost.attacker.example.com", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		req.Host = tt.host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Host %s: expected %d, got %d", tt.host, tt.want, rec.Code)
+		}
+	}
+}
+
+// TestTokenRequired tests that requests changing tunnels are refused without
+// the bearer token
+func TestTokenRequired(t *testing.T) {
+	handler := newTestServer(t, WithToken("secret-token"))
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		want          int
+	}{
+		{"no token", "/api/tunnels/db/stop", "", http.StatusUnauthorized},
+		{"wrong token", "/api/tunnels/db/stop", "Bearer wrong", http.StatusUnauthorized},
+		{"not a bearer token", "/api/tunnels/db/stop", "Basic secret-token", http.StatusUnauthorized},
+		{"profile without token", "/api/profiles/default/stop", "", http.StatusUnauthorized},
+		{"token", "/api/tunnels/missing/stop", "Bearer secret-token", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		req.Host = "127.0.0.1:7070"
+		if tt.authorization != "" {
+			req.Header.Set("Authorization", tt.authorization)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, rec.Code)
+		}
+	}
+
+	// A server without a token refuses every change
+	handler = newTestServer(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/tunnels/db/stop", nil)
+	req.Host = "localhost:7070"
+	req.Header.Set("Authorization", "Bearer ")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected a server without a token to refuse changes, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,202 @@
+// Package core provides ssh-agent detection and SSH key listing.
+package core
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Identity is an SSH key that tunnels can authenticate with
+type Identity struct {
+	// Type is the key type as ssh-add prints it, e.g. "ED25519" or "RSA"
+	Type string
+	// Bits is the key size, or 0 if unknown
+	Bits int
+	// Fingerprint is the SHA256 fingerprint, e.g. "SHA256:..."
+	Fingerprint string
+	// Comment is the key's comment, usually user@host or its file
+	Comment string
+
+	// Path is the private key file, for keys found in ~/.ssh
+	Path string
+	// InAgent reports whether ssh-agent holds the key
+	InAgent bool
+}
+
+// AgentSocket returns the socket of the running ssh-agent, or "" if
+// SSH_AUTH_SOCK is not set
+func AgentSocket() string {
+	return os.Getenv("SSH_AUTH_SOCK")
+}
+
+// ListAgentIdentities returns the keys held by ssh-agent, as listed by
+// ssh-add -l. An agent without keys returns no identities and no error.
+func ListAgentIdentities() ([]Identity, error) {
+	out, err := exec.Command("ssh-add", "-l").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		switch exitErr.ExitCode() {
+		case 1:
+			// The agent has no identities
+			return nil, nil
+		case 2:
+			return nil, fmt.Errorf("cannot connect to ssh-agent (is SSH_AUTH_SOCK set?)")
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent keys: %w", err)
+	}
+	return parseAgentList(string(out)), nil
+}
+
+// agentLinePattern matches a line of ssh-add -l, e.g.
+// "256 SHA256:abc user@host (ED25519)"
+var agentLinePattern = regexp.MustCompile(`^(\d+) (\S+) (.*?) ?\((\S+)\)$`)
+
+// parseAgentList parses the output of ssh-add -l
+func parseAgentList(output string) []Identity {
+	var identities []Identity
+	for _, line := range strings.Split(output, "\n") {
+		match := agentLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		bits, _ := strconv.Atoi(match[1])
+		identities = append(identities, Identity{
+			Type:        match[4],
+			Bits:        bits,
+			Fingerprint: match[2],
+			Comment:     match[3],
+			InAgent:     true,
+		})
+	}
+	return identities
+}
+
+// ListKeyFiles returns the private keys in ~/.ssh that have a public key
+// next to them, marking those that ssh-agent also holds
+func ListKeyFiles(agent []Identity) ([]Identity, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	pubs, err := filepath.Glob(filepath.Join(home, ".ssh", "*.pub"))
+	if err != nil {
+		return nil, err
+	}
+
+	inAgent := make(map[string]bool)
+	for _, identity := range agent {
+		inAgent[identity.Fingerprint] = true
+	}
+
+	var identities []Identity
+	for _, pub := range pubs {
+		private := strings.TrimSuffix(pub, ".pub")
+		if _, err := os.Stat(private); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(pub)
+		if err != nil {
+			continue
+		}
+		identity, ok := parsePublicKey(data)
+		if !ok {
+			continue
+		}
+		identity.Path = private
+		identity.InAgent = inAgent[identity.Fingerprint]
+		identities = append(identities, identity)
+	}
+	return identities, nil
+}
+
+// parsePublicKey reads the type, fingerprint and comment of an OpenSSH
+// public key line, e.g. "ssh-ed25519 AAAA... user@host"
+func parsePublicKey(data []byte) (Identity, bool) {
+	fields := strings.Fields(string(bytes.TrimSpace(data)))
+	if len(fields) < 2 {
+		return Identity{}, false
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return Identity{}, false
+	}
+	sum := sha256.Sum256(blob)
+	return Identity{
+		Type:        publicKeyType(fields[0]),
+		Fingerprint: "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]),
+		Comment:     strings.Join(fields[2:], " "),
+	}, true
+}
+
+// publicKeyType converts a public key algorithm to the type ssh-add prints
+func publicKeyType(algorithm string) string {
+	switch {
+	case algorithm == "ssh-ed25519":
+		return "ED25519"
+	case algorithm == "ssh-rsa":
+		return "RSA"
+	case strings.HasPrefix(algorithm, "ecdsa-sha2-"):
+		return "ECDSA"
+	case strings.HasPrefix(algorithm, "sk-ssh-ed25519"):
+		return "ED25519-SK"
+	case strings.HasPrefix(algorithm, "sk-ecdsa-"):
+		return "ECDSA-SK"
+	}
+	return algorithm
+}
+
+// ExpandHome replaces a leading ~ in a path with the home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// checkIdentityFile reports a pinned key file that does not exist, which ssh
+// would only warn about before falling back to other keys
+func checkIdentityFile(t *Tunnel) error {
+	t.mu.RLock()
+	path := t.IdentityFile
+	t.mu.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(ExpandHome(path)); err != nil {
+		return fmt.Errorf("identity file %s: %w", path, err)
+	}
+	return nil
+}
+
+// authPattern matches the line ssh logs at verbose level once authenticated, e.g.
+// `Authenticated to db.example.com ([10.0.0.5]:22) using "publickey".`
+var authPattern = regexp.MustCompile(`Authenticated to \S+ \(.*\) using "([^"]+)"`)
+
+// AuthMethod returns the method the tunnel's ssh last authenticated with, e.g.
+// "publickey", or "" if its output does not say. ssh only logs it at verbose
+// level, as in debug mode or with -o LogLevel=VERBOSE in the extra arguments.
+func (tm *TunnelManager) AuthMethod(id string) string {
+	output := lastRunOutput(tm.processManager.RecentOutput(id))
+	for i := len(output) - 1; i >= 0; i-- {
+		if match := authPattern.FindStringSubmatch(output[i]); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+// Package core provides ssh-agent and key listing tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseAgentList tests parsing the keys listed by ssh-add -l
+func TestParseAgentList(t *testing.T) {
+	output := `256 SHA256:bZsBM8f5bEaEBSjE+7zgzCt4LtnhYBBVS/LsrevreNM test@example (ED25519)
+3072 SHA256:q1w2e3r4t5y6u7i8o9p0 /home/user/.ssh/id_rsa (RSA)
+not a key line
+`
+	identities := parseAgentList(output)
+	if len(identities) != 2 {
+		t.Fatalf("Expected 2 identities, got %d: %+v", len(identities), identities)
+	}
+	first := identities[0]
+	if first.Type != "ED25519" || first.Bits != 256 || first.Comment != "test@example" || !first.InAgent {
+		t.Errorf("Unexpected first identity: %+v", first)
+	}
+	if identities[1].Comment != "/home/user/.ssh/id_rsa" || identities[1].Bits != 3072 {
+		t.Errorf("Unexpected second identity: %+v", identities[1])
+	}
+}
+
+// TestParsePublicKey tests that public key fingerprints match ssh-keygen -l
+func TestParsePublicKey(t *testing.T) {
+	key := "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBA7xr7sobszN5IW5K/hDDf9b0/wggYmmd0r8wUXY1na test@example\n"
+	identity, ok := parsePublicKey([]byte(key))
+	if !ok {
+		t.Fatal("Failed to parse public key")
+	}
+	if identity.Fingerprint != "SHA256:bZsBM8f5bEaEBSjE+7zgzCt4LtnhYBBVS/LsrevreNM" {
+		t.Errorf("Unexpected fingerprint %s", identity.Fingerprint)
+	}
+	if identity.Type != "ED25519" || identity.Comment != "test@example" {
+		t.Errorf("Unexpected identity: %+v", identity)
+	}
+
+	if _, ok := parsePublicKey([]byte("garbage")); ok {
+		t.Error("Expected a line without a key to be rejected")
+	}
+}
+
+// TestIdentityFile tests that a pinned key is passed to ssh alone and must exist
+func TestIdentityFile(t *testing.T) {
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	tunnel := &Tunnel{
+		ID:           "db",
+		Name:         "db",
+		Type:         LocalForward,
+		LocalHost:    "127.0.0.1",
+		LocalPort:    15432,
+		RemoteHost:   "127.0.0.1",
+		RemotePort:   5432,
+		SSHHost:      "db.example.com",
+		IdentityFile: keyPath,
+	}
+
+	args := strings.Join(NewProcessManager().buildSSHArgs(tunnel), " ")
+	if !strings.Contains(args, "-i "+keyPath+" -o IdentitiesOnly=yes") {
+		t.Errorf("Expected the pinned key in ssh args, got %s", args)
+	}
+
+	if err := checkIdentityFile(tunnel); err == nil {
+		t.Error("Expected a missing identity file to be reported")
+	}
+	if err := os.WriteFile(keyPath, []byte("key"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	if err := checkIdentityFile(tunnel); err != nil {
+		t.Errorf("Expected an existing identity file to pass, got %v", err)
+	}
+}
+
+// TestAuthMethod tests reading the authentication method from ssh's output
+func TestAuthMethod(t *testing.T) {
+	port := freePort(t)
+	tm, _ := newTestManager(t, mockConfigJSON(port), WithMockProcesses(MockConfig{ConnectDelay: 10 * time.Millisecond}))
+
+	if method := tm.AuthMethod("db"); method != "" {
+		t.Errorf("Expected no method before connecting, got %q", method)
+	}
+	if err := tm.StartTunnel("db"); err != nil {
+		t.Fatalf("Failed to start simulated tunnel: %v", err)
+	}
+	defer tm.StopTunnel("db")
+
+	if method := tm.AuthMethod("db"); method != "publickey" {
+		t.Errorf("Expected publickey, got %q", method)
+	}
+}
@@ -0,0 +1,359 @@
+// Package core provides interactive password prompts for ssh processes.
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// AskpassCommand is the first argument that runs tunnelman as ssh's
+// SSH_ASKPASS helper. ssh runs a wrapper script that passes it, so the helper
+// is chosen explicitly rather than from inherited environment variables.
+const AskpassCommand = "__askpass"
+
+const (
+	// askpassSocketEnv holds the socket on which tunnelman answers prompts
+	askpassSocketEnv = "TUNNELMAN_ASKPASS_SOCKET"
+	// askpassTokenEnv holds the secret a helper presents to the socket
+	askpassTokenEnv = "TUNNELMAN_ASKPASS_TOKEN"
+	// askpassTunnelEnv holds the ID of the tunnel whose ssh is asking
+	askpassTunnelEnv = "TUNNELMAN_ASKPASS_TUNNEL"
+	// askpassSecretEnv names the keychain secret answering password prompts
+	askpassSecretEnv = "TUNNELMAN_ASKPASS_SECRET"
+)
+
+// secretPromptPattern matches the prompts a tunnel's keychain secret answers,
+// e.g. "user@host's password: " or "Enter passphrase for key '...': "
+var secretPromptPattern = regexp.MustCompile(`(?i)password|passphrase`)
+
+// askpassTimeout is how long a prompt waits for an answer before ssh is told
+// that authentication was cancelled
+const askpassTimeout = 5 * time.Minute
+
+// ErrPromptCancelled is returned by a Prompter when the user dismisses a prompt
+var ErrPromptCancelled = errors.New("prompt cancelled")
+
+// PromptKind describes what ssh is asking for
+type PromptKind string
+
+const (
+	// PromptSecret asks for a password, passphrase or one-time code
+	PromptSecret PromptKind = "secret"
+	// PromptConfirm asks a yes/no question, answered with "yes" or "no"
+	PromptConfirm PromptKind = "confirm"
+	// PromptNotice shows a message while ssh waits, e.g. to touch a security
+	// key; ssh withdraws it once done
+	PromptNotice PromptKind = "notice"
+)
+
+// Prompt is a question an ssh process asks while connecting
+type Prompt struct {
+	TunnelID   string
+	TunnelName string
+	// Message is the prompt as ssh prints it, e.g. "user@host's password: "
+	Message string
+	Kind    PromptKind
+}
+
+// Prompter asks the user to answer a prompt and returns the answer, or
+// ErrPromptCancelled. ctx is done when ssh stops waiting for the answer.
+type Prompter func(ctx context.Context, prompt Prompt) (string, error)
+
+// askpassRequest is sent by the helper to the prompt socket
+type askpassRequest struct {
+	Token   string     `json:"token"`
+	Tunnel  string     `json:"tunnel"`
+	Message string     `json:"message"`
+	Kind    PromptKind `json:"kind"`
+}
+
+// askpassResponse is the answer to an askpassRequest
+type askpassResponse struct {
+	Answer string `json:"answer,omitempty"`
+	OK     bool   `json:"ok"`
+}
+
+// askpassServer answers prompts of the ssh processes started by a tunnel manager
+type askpassServer struct {
+	tm       *TunnelManager
+	listener net.Listener
+	token    string
+	prompter Prompter
+
+	// mu shows one prompt at a time
+	mu sync.Mutex
+}
+
+// EnablePrompts answers password, passphrase and confirmation prompts of
+// tunnels started from now on with prompter. ssh has no terminal to ask on, so
+// it runs tunnelman as its SSH_ASKPASS program, which passes each prompt back
+// over a socket only this user can open. The returned function stops answering
+// prompts, after which ssh fails to authenticate as it did before.
+func (tm *TunnelManager) EnablePrompts(prompter Prompter) (func(), error) {
+	socketPath, err := store.GetAskpassSocketPath()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	os.Remove(socketPath)
+
+	listener, err := ListenPrivate(socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &askpassServer{
+		tm:       tm,
+		listener: listener,
+		token:    generateID(),
+		prompter: prompter,
+	}
+	go server.serve()
+
+	tm.processManager.setAskpass([]string{
+		askpassSocketEnv + "=" + socketPath,
+		askpassTokenEnv + "=" + server.token,
+	})
+
+	return func() {
+		tm.processManager.setAskpass(nil)
+		listener.Close()
+		os.Remove(socketPath)
+	}, nil
+}
+
+// WithAskpassDir sets the directory where the askpass wrapper scripts of
+// tunnels are written; empty disables prompts and keychain secrets
+func WithAskpassDir(dir string) ProcessManagerOption {
+	return func(pm *ProcessManager) {
+		pm.askpassDir = dir
+	}
+}
+
+// askpassProgramEnv returns the environment that makes ssh run program to
+// ask for passwords
+func askpassProgramEnv(program string) []string {
+	env := []string{
+		"SSH_ASKPASS=" + program,
+		"SSH_ASKPASS_REQUIRE=force",
+	}
+	// ssh before 8.4 ignores SSH_ASKPASS_REQUIRE and only asks without a
+	// terminal if DISPLAY is set
+	if os.Getenv("DISPLAY") == "" {
+		env = append(env, "DISPLAY=tunnelman:0")
+	}
+	return env
+}
+
+// serve handles helper connections until the listener is closed
+func (s *askpassServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				Error("Failed to accept prompt connection: %v", err)
+			}
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle asks the user one helper's question and writes back the answer
+func (s *askpassServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	var req askpassRequest
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		Debug("Invalid prompt request: %v", err)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(req.Token), []byte(s.token)) != 1 {
+		Warn("Rejected a prompt request with a wrong token")
+		return
+	}
+
+	// The helper only closes the connection early when ssh gives up on it
+	ctx, cancel := context.WithTimeout(context.Background(), askpassTimeout)
+	defer cancel()
+	go func() {
+		io.Copy(io.Discard, conn)
+		cancel()
+	}()
+
+	prompt := Prompt{TunnelID: req.Tunnel, TunnelName: req.Tunnel, Message: req.Message, Kind: req.Kind}
+	if tunnel, err := s.tm.GetTunnel(req.Tunnel); err == nil {
+		prompt.TunnelName = tunnel.Name
+	}
+
+	s.mu.Lock()
+	answer, err := s.prompter(ctx, prompt)
+	s.mu.Unlock()
+
+	resp := askpassResponse{Answer: answer, OK: err == nil}
+	if err != nil && !errors.Is(err, ErrPromptCancelled) && ctx.Err() == nil {
+		Warn("Failed to prompt for tunnel %s: %v", prompt.TunnelName, err)
+	}
+	json.NewEncoder(conn).Encode(resp)
+}
+
+// askpassEnv returns the environment that makes a tunnel's ssh ask tunnelman
+// for passwords, or nil if prompts are not enabled and the tunnel has no
+// keychain secret to answer them with. The socket token and secret name are
+// kept in the wrapper script, out of the environment of ssh and whatever it
+// runs, such as a ProxyCommand.
+func (pm *ProcessManager) askpassEnv(tunnel *Tunnel) []string {
+	helper := pm.askpassHelperEnv(tunnel)
+	if helper == nil {
+		return nil
+	}
+	wrapper, err := pm.writeAskpassWrapper(tunnel.ID, helper)
+	if err != nil {
+		Warn("Cannot answer password prompts of tunnel %s: %v", tunnel.Name, err)
+		return nil
+	}
+	return askpassProgramEnv(wrapper)
+}
+
+// askpassHelperEnv returns the environment the askpass helper of a tunnel
+// needs, or nil if it has nothing to answer prompts with
+func (pm *ProcessManager) askpassHelperEnv(tunnel *Tunnel) []string {
+	pm.mu.RLock()
+	env := append([]string(nil), pm.askpass...)
+	pm.mu.RUnlock()
+
+	// A secret is answered by the helper itself, so it needs no prompts
+	if tunnel.Secret != "" {
+		env = append(env, askpassSecretEnv+"="+tunnel.Secret)
+	}
+
+	if len(env) == 0 {
+		return nil
+	}
+	return append(env, askpassTunnelEnv+"="+tunnel.ID)
+}
+
+// writeAskpassWrapper writes the script ssh runs as a tunnel's SSH_ASKPASS
+// program, which runs tunnelman with AskpassCommand and env, and returns its
+// path. Only this user can read it, as it holds the socket token.
+func (pm *ProcessManager) writeAskpassWrapper(id string, env []string) (string, error) {
+	if pm.askpassDir == "" {
+		return "", errors.New("no directory for askpass scripts")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the tunnelman executable: %w", err)
+	}
+	if err := os.MkdirAll(pm.askpassDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create askpass directory: %w", err)
+	}
+
+	// The script is replaced rather than rewritten in place, as an ssh
+	// process started earlier may be running it
+	path := filepath.Join(pm.askpassDir, id+askpassScriptExt)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(askpassScript(executable, env)), 0o700); err != nil {
+		return "", fmt.Errorf("failed to write askpass script: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", fmt.Errorf("failed to write askpass script: %w", err)
+	}
+	return path, nil
+}
+
+// setAskpass sets the environment passed to ssh for prompts, nil disabling them
+func (pm *ProcessManager) setAskpass(env []string) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.askpass = env
+}
+
+// RunAskpass runs tunnelman as ssh's SSH_ASKPASS program: it answers the
+// prompt in args with the tunnel's keychain secret, or passes it to the
+// tunnelman process that started ssh, and prints the answer. It returns the
+// exit code, non-zero if the prompt was cancelled.
+func RunAskpass(args []string) int {
+	answer, err := askpass(strings.Join(args, " "), os.Getenv("SSH_ASKPASS_PROMPT"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tunnelman askpass: %v\n", err)
+		return 1
+	}
+	fmt.Println(answer)
+	return 0
+}
+
+// askpass answers a password prompt with the keychain secret named in the
+// environment, or sends the prompt to the socket named there and waits for the
+// answer. hint is ssh's SSH_ASKPASS_PROMPT.
+func askpass(message, hint string) (string, error) {
+	req := askpassRequest{
+		Token:   os.Getenv(askpassTokenEnv),
+		Tunnel:  os.Getenv(askpassTunnelEnv),
+		Message: message,
+		Kind:    promptKind(message, hint),
+	}
+	socketPath := os.Getenv(askpassSocketEnv)
+
+	if name := os.Getenv(askpassSecretEnv); name != "" && req.Kind == PromptSecret && secretPromptPattern.MatchString(message) {
+		secret, err := GetSecret(name)
+		if err == nil {
+			return secret, nil
+		}
+		// The user is asked instead when the TUI is running
+		if socketPath == "" {
+			return "", fmt.Errorf("secret %s: %w", name, err)
+		}
+	}
+	if socketPath == "" {
+		return "", ErrPromptCancelled
+	}
+
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return "", fmt.Errorf("tunnelman is no longer running: %w", err)
+	}
+	defer conn.Close()
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return "", err
+	}
+	var resp askpassResponse
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return "", fmt.Errorf("no answer: %w", err)
+	}
+	if !resp.OK {
+		return "", ErrPromptCancelled
+	}
+	return resp.Answer, nil
+}
+
+// promptKind tells what a prompt asks for from ssh's SSH_ASKPASS_PROMPT hint
+// and, for questions ssh asks as plain text, its wording
+func promptKind(message, hint string) PromptKind {
+	switch {
+	case hint == "confirm":
+		return PromptConfirm
+	case hint == "none":
+		return PromptNotice
+	case strings.Contains(message, "(yes/no"):
+		return PromptConfirm
+	}
+	return PromptSecret
+}
@@ -0,0 +1,95 @@
+// Package core provides password prompt tests.
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// setAskpassEnv sets the environment the wrapper script passes to the
+// askpass helper
+func setAskpassEnv(t *testing.T, tm *TunnelManager, tunnel *Tunnel) {
+	t.Helper()
+	env := tm.processManager.(*ProcessManager).askpassHelperEnv(tunnel)
+	if env == nil {
+		t.Fatal("Expected prompts to be enabled")
+	}
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		t.Setenv(name, value)
+	}
+}
+
+// TestAskpass tests that ssh's prompts reach the prompter with the tunnel's name
+func TestAskpass(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "database", "host": "db.example.com", "localPort": 15432, "remotePort": 5432, "mode": "local"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+
+	var prompts []Prompt
+	stop, err := tm.EnablePrompts(func(ctx context.Context, prompt Prompt) (string, error) {
+		prompts = append(prompts, prompt)
+		if prompt.Kind == PromptConfirm {
+			return "", ErrPromptCancelled
+		}
+		return "hunter2", nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to enable prompts: %v", err)
+	}
+	defer stop()
+
+	tunnel, _ := tm.GetTunnel("db")
+	setAskpassEnv(t, tm, tunnel)
+
+	answer, err := askpass("user@db.example.com's password: ", "")
+	if err != nil || answer != "hunter2" {
+		t.Fatalf("Expected the prompter's answer, got %q (%v)", answer, err)
+	}
+	if len(prompts) != 1 || prompts[0].TunnelName != "database" || prompts[0].Kind != PromptSecret {
+		t.Errorf("Unexpected prompts: %+v", prompts)
+	}
+
+	if _, err := askpass("Allow use of key?", "confirm"); !errors.Is(err, ErrPromptCancelled) {
+		t.Errorf("Expected a cancelled prompt, got %v", err)
+	}
+
+	t.Setenv(askpassTokenEnv, "wrong")
+	if _, err := askpass("password: ", ""); err == nil {
+		t.Error("Expected a wrong token to be rejected")
+	}
+	if len(prompts) != 2 {
+		t.Errorf("Expected the rejected request not to prompt, got %d prompts", len(prompts))
+	}
+
+	stop()
+	if env := tm.processManager.(*ProcessManager).askpassEnv(tunnel); env != nil {
+		t.Errorf("Expected no askpass environment once prompts stop, got %v", env)
+	}
+}
+
+// TestPromptKind tests telling what ssh asks for
+func TestPromptKind(t *testing.T) {
+	tests := []struct {
+		message string
+		hint    string
+		want    PromptKind
+	}{
+		{"user@host's password: ", "", PromptSecret},
+		{"Verification code: ", "", PromptSecret},
+		{"Allow use of key id_ed25519?", "confirm", PromptConfirm},
+		{"Confirm user presence for key ED25519-SK", "none", PromptNotice},
+		{"Are you sure you want to continue connecting (yes/no/[fingerprint])? ", "", PromptConfirm},
+	}
+	for _, tt := range tests {
+		if got := promptKind(tt.message, tt.hint); got != tt.want {
+			t.Errorf("promptKind(%q, %q) = %s, want %s", tt.message, tt.hint, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Package core provides the interface to the processes that carry tunnels.
+package core
+
+import (
+	"context"
+)
+
+// ProcessBackend starts, stops and tracks the processes that carry tunnels.
+// ProcessManager runs ssh; MockProcessManager simulates it. Implementations
+// live in this package, as the tunnel manager relies on unexported methods.
+type ProcessBackend interface {
+	// Connect starts a tunnel's process
+	Connect(tunnel *Tunnel) (*ProcessInfo, error)
+	// Disconnect stops a tunnel's process, falling back to pid if it is not tracked
+	Disconnect(id string, pid int) error
+	// CancelSharedForwards releases a tunnel's forwards on a shared connection
+	CancelSharedForwards(tunnel *Tunnel) error
+	// Cleanup stops every tracked process
+	Cleanup(ctx context.Context) error
+
+	GetProcessInfo(id string) (*ProcessInfo, bool)
+	GetAllProcesses() map[string]*ProcessInfo
+	IsProcessRunning(pid int) bool
+
+	LogPath(id string) string
+	RecentOutput(id string) []string
+
+	SSHPath() string
+	SetSSHPath(configured string) error
+	SSHVersion() (SSHVersion, error)
+	SetConnectionDefaults(defaults ConnectionDefaults)
+
+	buildSSHArgs(tunnel *Tunnel) []string
+	killProcessByPID(pid int) error
+	findTunnelProcesses() ([]SSHProcess, error)
+	setAskpass(env []string)
+	pinnedHostsPath(id string) string
+}
+
+// findTunnelProcesses lists running ssh processes that look like tunnels
+func (pm *ProcessManager) findTunnelProcesses() ([]SSHProcess, error) {
+	return FindTunnelProcesses()
+}
@@ -0,0 +1,78 @@
+// Package core provides fan-out of status changes to the clients of the control servers.
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// statusRefreshInterval is how often a StatusBroadcaster picks up tunnels
+// started or stopped by other tunnelman processes
+const statusRefreshInterval = time.Second
+
+// StatusBroadcaster fans the status changes of a TunnelManager out to the
+// clients of a control server, such as the daemon or the gRPC API, and keeps
+// states in sync with tunnels managed by other processes
+type StatusBroadcaster struct {
+	manager *TunnelManager
+
+	mu       sync.Mutex
+	watchers map[chan StatusEvent]struct{}
+}
+
+// NewStatusBroadcaster creates a broadcaster for the given tunnel manager
+func NewStatusBroadcaster(manager *TunnelManager) *StatusBroadcaster {
+	return &StatusBroadcaster{
+		manager:  manager,
+		watchers: make(map[chan StatusEvent]struct{}),
+	}
+}
+
+// Run delivers status changes to watchers and refreshes states every second
+// until ctx is cancelled
+func (b *StatusBroadcaster) Run(ctx context.Context) {
+	ticker := time.NewTicker(statusRefreshInterval)
+	defer ticker.Stop()
+
+	statusChanges := b.manager.Subscribe()
+	defer b.manager.Unsubscribe(statusChanges)
+
+	for {
+		select {
+		case change := <-statusChanges:
+			event := b.manager.StatusEvent(change)
+			b.mu.Lock()
+			for watcher := range b.watchers {
+				select {
+				case watcher <- event:
+				default:
+					// Slow watcher, drop the event rather than block others
+				}
+			}
+			b.mu.Unlock()
+
+		case <-ticker.C:
+			b.manager.RefreshStates()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Watch returns a channel receiving status changes from now on, and a
+// function that stops delivering them
+func (b *StatusBroadcaster) Watch() (<-chan StatusEvent, func()) {
+	events := make(chan StatusEvent, 100)
+
+	b.mu.Lock()
+	b.watchers[events] = struct{}{}
+	b.mu.Unlock()
+
+	return events, func() {
+		b.mu.Lock()
+		delete(b.watchers, events)
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,56 @@
+// Package core provides status broadcast tests.
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestStatusBroadcaster tests that status changes reach every watcher until
+// it stops watching
+func TestStatusBroadcaster(t *testing.T) {
+	tm, _ := newTestManager(t, mockConfigJSON(freePort(t)))
+	broadcaster := NewStatusBroadcaster(tm)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go broadcaster.Run(ctx)
+
+	first, stopFirst := broadcaster.Watch()
+	second, stopSecond := broadcaster.Watch()
+	defer stopSecond()
+
+	// Run subscribes asynchronously, so publish until the change gets through
+	deadline := time.After(2 * time.Second)
+	for received := false; !received; {
+		tm.notifyStatusChange("db", StatusStopped, StatusRunning, nil)
+		select {
+		case event := <-first:
+			if event.TunnelID != "db" || event.NewStatus != StatusRunning {
+				t.Errorf("Unexpected event: %+v", event)
+			}
+			received = true
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Expected the status change to be delivered")
+		}
+	}
+	select {
+	case <-second:
+	case <-time.After(time.Second):
+		t.Error("Expected every watcher to receive the status change")
+	}
+
+	stopFirst()
+	for len(first) > 0 {
+		<-first
+	}
+	tm.notifyStatusChange("db", StatusRunning, StatusStopped, nil)
+	<-second
+	select {
+	case event := <-first:
+		t.Errorf("Expected no events after stopping, got %+v", event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
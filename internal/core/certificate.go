@@ -0,0 +1,202 @@
+// Package core provides SSH certificate handling for tunnels.
+package core
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+// CertificateRenewMargin is how long before its certificate expires a tunnel
+// renews it when connecting, so it does not expire mid-handshake
+const CertificateRenewMargin = 5 * time.Minute
+
+// HookRenewCertificate is the event passed to a tunnel's certificate command
+const HookRenewCertificate HookEvent = "renew-certificate"
+
+// Certificate is an OpenSSH user certificate
+type Certificate struct {
+	KeyID      string
+	Principals []string
+	Serial     uint64
+
+	// ValidAfter and ValidBefore bound the validity period; a zero
+	// ValidBefore means the certificate never expires
+	ValidAfter  time.Time
+	ValidBefore time.Time
+}
+
+// Expired reports whether the certificate is no longer valid at t
+func (c *Certificate) Expired(t time.Time) bool {
+	return !c.ValidBefore.IsZero() && !t.Before(c.ValidBefore)
+}
+
+// ExpiresWithin reports whether the certificate expires within d from now
+func (c *Certificate) ExpiresWithin(d time.Duration) bool {
+	return c.Expired(time.Now().Add(d))
+}
+
+// certificateKeyFields is how many public key fields each key type puts
+// between a certificate's nonce and its serial
+var certificateKeyFields = map[string]int{
+	"ssh-rsa":                            2, // e, n
+	"ssh-dss":                            4, // p, q, g, y
+	"ecdsa-sha2-nistp256":                2, // curve, point
+	"ecdsa-sha2-nistp384":                2,
+	"ecdsa-sha2-nistp521":                2,
+	"ssh-ed25519":                        1, // key
+	"sk-ecdsa-sha2-nistp256@openssh.com": 3, // curve, point, application
+	"sk-ssh-ed25519@openssh.com":         2, // key, application
+}
+
+// ReadCertificate reads an OpenSSH certificate file, e.g. id_ed25519-cert.pub
+func ReadCertificate(path string) (*Certificate, error) {
+	data, err := os.ReadFile(ExpandHome(path))
+	if err != nil {
+		return nil, err
+	}
+	cert, err := parseCertificate(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cert, nil
+}
+
+// parseCertificate parses a certificate line, e.g.
+// "ssh-ed25519-cert-v01@openssh.com AAAA... user@host"
+func parseCertificate(data []byte) (*Certificate, error) {
+	fields := strings.Fields(string(bytes.TrimSpace(data)))
+	if len(fields) < 2 || !strings.HasSuffix(fields[0], "-cert-v01@openssh.com") {
+		return nil, errors.New("not an OpenSSH certificate")
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate encoding: %w", err)
+	}
+
+	r := &certReader{data: blob}
+	keyType := strings.TrimSuffix(string(r.bytes()), "-cert-v01@openssh.com")
+	count, ok := certificateKeyFields[keyType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported certificate key type %q", keyType)
+	}
+	r.bytes() // nonce
+	for i := 0; i < count; i++ {
+		r.bytes()
+	}
+
+	cert := &Certificate{Serial: r.uint64()}
+	r.uint32() // user or host certificate
+	cert.KeyID = string(r.bytes())
+	principals := &certReader{data: r.bytes()}
+	validAfter := r.uint64()
+	validBefore := r.uint64()
+	if r.err != nil {
+		return nil, errors.New("truncated certificate")
+	}
+
+	for len(principals.data) > 0 && principals.err == nil {
+		cert.Principals = append(cert.Principals, string(principals.bytes()))
+	}
+	cert.ValidAfter = certificateTime(validAfter)
+	if validBefore != math.MaxUint64 {
+		cert.ValidBefore = certificateTime(validBefore)
+	}
+	return cert, nil
+}
+
+// certificateTime converts a certificate timestamp, capping it at the largest
+// time representable as Unix seconds
+func certificateTime(seconds uint64) time.Time {
+	if seconds > math.MaxInt64 {
+		seconds = math.MaxInt64
+	}
+	return time.Unix(int64(seconds), 0)
+}
+
+// certReader reads the SSH wire encoding, remembering the first error
+type certReader struct {
+	data []byte
+	err  error
+}
+
+// bytes reads a length-prefixed string
+func (r *certReader) bytes() []byte {
+	n := int(r.uint32())
+	if r.err != nil || n > len(r.data) {
+		r.err = errors.New("truncated")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// uint32 reads a big-endian 32-bit integer
+func (r *certReader) uint32() uint32 {
+	if r.err != nil || len(r.data) < 4 {
+		r.err = errors.New("truncated")
+		return 0
+	}
+	v := binary.BigEndian.Uint32(r.data)
+	r.data = r.data[4:]
+	return v
+}
+
+// uint64 reads a big-endian 64-bit integer
+func (r *certReader) uint64() uint64 {
+	if r.err != nil || len(r.data) < 8 {
+		r.err = errors.New("truncated")
+		return 0
+	}
+	v := binary.BigEndian.Uint64(r.data)
+	r.data = r.data[8:]
+	return v
+}
+
+// renewCertificate runs a tunnel's certificate command when its certificate
+// is missing or about to expire, and checks that ssh will have a valid one.
+// Without a command an expired certificate fails the start, as ssh would.
+func (tm *TunnelManager) renewCertificate(tunnel *Tunnel) error {
+	tm.mu.RLock()
+	path := tunnel.CertificateFile
+	command := tunnel.CertificateCommand
+	name := tunnel.Name
+	env := append(hookEnv(tunnel, HookRenewCertificate, nil), "TUNNELMAN_CERTIFICATE_FILE="+ExpandHome(path))
+	tm.mu.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+	cert, err := ReadCertificate(path)
+	if err == nil && !cert.ExpiresWithin(CertificateRenewMargin) {
+		return nil
+	}
+	if command == "" {
+		if err != nil {
+			return fmt.Errorf("certificate: %w", err)
+		}
+		if cert.Expired(time.Now()) {
+			return fmt.Errorf("certificate %s expired at %s", path, cert.ValidBefore.Format(time.RFC3339))
+		}
+		return nil
+	}
+
+	Info("Renewing certificate of tunnel '%s'", name)
+	if err := runHook(command, env, tm.hookLogPath(tunnel.ID)); err != nil {
+		return fmt.Errorf("certificate command failed: %w", err)
+	}
+	if cert, err = ReadCertificate(path); err != nil {
+		return fmt.Errorf("certificate command did not write a certificate: %w", err)
+	}
+	if cert.Expired(time.Now()) {
+		return fmt.Errorf("certificate command wrote a certificate that expired at %s", cert.ValidBefore.Format(time.RFC3339))
+	}
+	return nil
+}
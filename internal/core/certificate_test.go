@@ -0,0 +1,91 @@
+// Package core provides SSH certificate tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCertificate is valid from 2026-01-01 to 2026-01-02 UTC for principals deploy and admin
+const testCertificate = "ssh-ed25519-cert-v01@openssh.com AAAAIHNzaC1lZDI1NTE5LWNlcnQtdjAxQG9wZW5zc2guY29tAAAAIJr3SxJVEadKYVi9L198y08QTLJHWoDAW98boX7OlnEQAAAAIBA7xr7sobszN5IW5K/hDDf9b0/wggYmmd0r8wUXY1naAAAAAAAAACoAAAABAAAADmRlcGxveUBleGFtcGxlAAAAEwAAAAZkZXBsb3kAAAAFYWRtaW4AAAAAaVW5AAAAAABpVwqAAAAAAAAAAIIAAAAVcGVybWl0LVgxMS1mb3J3YXJkaW5nAAAAAAAAABdwZXJtaXQtYWdlbnQtZm9yd2FyZGluZwAAAAAAAAAWcGVybWl0LXBvcnQtZm9yd2FyZGluZwAAAAAAAAAKcGVybWl0LXB0eQAAAAAAAAAOcGVybWl0LXVzZXItcmMAAAAAAAAAAAAAADMAAAALc3NoLWVkMjU1MTkAAAAgYXSp6OOoK/MQBL+IADt21ZNoDHf7Gu3fszNONhvl5MUAAABTAAAAC3NzaC1lZDI1NTE5AAAAQODERaRSm1wWnJ4ZwFzAj/Poj7qOikmcFLtutRbEcHm9fuKd2m71gW5v9TuPJgKjhOgBdBsgXRuHzrqUilmHGA8= test@example"
+
+// testForeverCertificate never expires
+const testForeverCertificate = "ssh-ed25519-cert-v01@openssh.com AAAAIHNzaC1lZDI1NTE5LWNlcnQtdjAxQG9wZW5zc2guY29tAAAAIObSy2CzUwHyiNXNP3ThWNv0zz+15TLB9ZGgK+EqX6keAAAAIBA7xr7sobszN5IW5K/hDDf9b0/wggYmmd0r8wUXY1naAAAAAAAAAAAAAAABAAAAB2ZvcmV2ZXIAAAAKAAAABmRlcGxveQAAAAAAAAAA//////////8AAAAAAAAAggAAABVwZXJtaXQtWDExLWZvcndhcmRpbmcAAAAAAAAAF3Blcm1pdC1hZ2VudC1mb3J3YXJkaW5nAAAAAAAAABZwZXJtaXQtcG9ydC1mb3J3YXJkaW5nAAAAAAAAAApwZXJtaXQtcHR5AAAAAAAAAA5wZXJtaXQtdXNlci1yYwAAAAAAAAAAAAAAMwAAAAtzc2gtZWQyNTUxOQAAACBhdKno46gr8xAEv4gAO3bVk2gMd/sa7d+zM042G+XkxQAAAFMAAAALc3NoLWVkMjU1MTkAAABA3EOx7oUGcCkOXnhsV+wLvfYkj+jVPyxnS1uV5qOCxDsc2eH9rlZUxrcScuPTrC9gYRrap/zpDsm/n7EV0JmSDg== test@example"
+
+// TestParseCertificate tests reading the identity and validity of a certificate
+func TestParseCertificate(t *testing.T) {
+	cert, err := parseCertificate([]byte(testCertificate + "\n"))
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	if cert.KeyID != "deploy@example" || cert.Serial != 42 || strings.Join(cert.Principals, ",") != "deploy,admin" {
+		t.Errorf("Unexpected certificate: %+v", cert)
+	}
+	if want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC); !cert.ValidAfter.Equal(want) {
+		t.Errorf("Expected valid after %s, got %s", want, cert.ValidAfter)
+	}
+	if want := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC); !cert.ValidBefore.Equal(want) {
+		t.Errorf("Expected valid before %s, got %s", want, cert.ValidBefore)
+	}
+	if !cert.Expired(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)) || cert.Expired(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Expected the certificate to expire at the end of its validity")
+	}
+
+	forever, err := parseCertificate([]byte(testForeverCertificate))
+	if err != nil {
+		t.Fatalf("Failed to parse certificate: %v", err)
+	}
+	if !forever.ValidBefore.IsZero() || forever.ExpiresWithin(100*365*24*time.Hour) {
+		t.Errorf("Expected the certificate never to expire, got %s", forever.ValidBefore)
+	}
+
+	if _, err := parseCertificate([]byte(testHostKey)); err == nil {
+		t.Error("Expected a plain public key to be rejected")
+	}
+	if _, err := parseCertificate([]byte(testCertificate[:120])); err == nil {
+		t.Error("Expected a truncated certificate to be rejected")
+	}
+}
+
+// TestRenewCertificate tests that an expired certificate is renewed by the
+// tunnel's certificate command before connecting
+func TestRenewCertificate(t *testing.T) {
+	tm, _ := newTestManager(t, "")
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "id_ed25519-cert.pub")
+	if err := os.WriteFile(certPath, []byte(testCertificate+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	tunnel := &Tunnel{ID: "db", Name: "db", SSHHost: "db.example.com", CertificateFile: certPath}
+
+	if err := tm.renewCertificate(tunnel); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected an expired certificate without a command to fail, got %v", err)
+	}
+
+	renewed := filepath.Join(dir, "renewed-cert.pub")
+	if err := os.WriteFile(renewed, []byte(testForeverCertificate+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	tunnel.CertificateCommand = `cp "` + renewed + `" "$TUNNELMAN_CERTIFICATE_FILE"`
+	if IsWindows() {
+		tunnel.CertificateCommand = `copy "` + renewed + `" "%TUNNELMAN_CERTIFICATE_FILE%"`
+	}
+	if err := tm.renewCertificate(tunnel); err != nil {
+		t.Fatalf("Expected the certificate to be renewed, got %v", err)
+	}
+	cert, err := ReadCertificate(certPath)
+	if err != nil || cert.KeyID != "forever" {
+		t.Errorf("Expected the renewed certificate, got %+v (%v)", cert, err)
+	}
+
+	tunnel.CertificateCommand = "exit 1"
+	if err := os.WriteFile(certPath, []byte(testCertificate+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write certificate: %v", err)
+	}
+	if err := tm.renewCertificate(tunnel); err == nil {
+		t.Error("Expected a failing certificate command to fail the start")
+	}
+}
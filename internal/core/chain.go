@@ -0,0 +1,127 @@
+// Package core provides multi-hop tunnel chains.
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HopState is the state of one hop in a chained tunnel
+type HopState string
+
+const (
+	// HopUp indicates the hop is part of a running connection
+	HopUp HopState = "up"
+	// HopDown indicates the hop is not connected
+	HopDown HopState = "down"
+	// HopConnecting indicates the chain is being established
+	HopConnecting HopState = "connecting"
+	// HopFailed indicates the chain broke at this hop when ssh last exited
+	HopFailed HopState = "failed"
+)
+
+// Hop is one host of a tunnel's SSH chain and its state
+type Hop struct {
+	Host  string   `json:"host"`
+	State HopState `json:"state"`
+}
+
+// ParseHopChain splits a chain such as "bastion,gw,db" or "bastion -> gw -> db"
+// into its hosts, the last of which is the SSH host
+func ParseHopChain(spec string) ([]string, error) {
+	spec = strings.ReplaceAll(spec, "->", ",")
+	var hops []string
+	for _, hop := range strings.Split(spec, ",") {
+		hop = strings.TrimSpace(hop)
+		if hop == "" {
+			return nil, fmt.Errorf("invalid chain %q: empty hop", spec)
+		}
+		if strings.ContainsAny(hop, " \t") {
+			return nil, fmt.Errorf("invalid chain %q: hop %q contains spaces", spec, hop)
+		}
+		hops = append(hops, hop)
+	}
+	return hops, nil
+}
+
+// SetHopChain makes the tunnel connect to the last host of hops through the
+// others, in order
+func (t *Tunnel) SetHopChain(hops []string) error {
+	if len(hops) == 0 {
+		return fmt.Errorf("chain must have at least one host")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.SSHHost = hops[len(hops)-1]
+	t.JumpHost = strings.Join(hops[:len(hops)-1], ",")
+	return nil
+}
+
+// Hops returns the tunnel's chain with the combined state of each hop. Tunnels
+// without a jump host have a single hop.
+func (t *Tunnel) Hops() []Hop {
+	chain := t.HopChain()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	state := HopDown
+	switch t.Status {
+	case StatusRunning:
+		state = HopUp
+	case StatusConnecting:
+		state = HopConnecting
+	}
+
+	hops := make([]Hop, len(chain))
+	for i, host := range chain {
+		hops[i] = Hop{Host: host, State: state}
+		if state == HopDown && host == t.FailedHop {
+			hops[i].State = HopFailed
+		}
+	}
+	return hops
+}
+
+// findFailedHop returns the hop of chain named in ssh's error output, or ""
+// if the output does not say where the chain broke. When several hops are
+// named the last one is used, since hops are connected in order.
+func findFailedHop(output []string, chain []string) string {
+	failed := ""
+	for _, line := range output {
+		lower := strings.ToLower(line)
+		if !strings.Contains(lower, "ssh:") && !strings.Contains(lower, "open failed") &&
+			!strings.Contains(lower, "connection closed by") && !strings.Contains(lower, "permission denied") {
+			continue
+		}
+		for _, hop := range chain {
+			if strings.Contains(line, hopHostname(hop)) {
+				failed = hop
+			}
+		}
+	}
+	return failed
+}
+
+// hopHostname strips the user and port from a hop such as "admin@gw:2222"
+func hopHostname(hop string) string {
+	if i := strings.LastIndex(hop, "@"); i >= 0 {
+		hop = hop[i+1:]
+	}
+	if i := strings.LastIndex(hop, ":"); i >= 0 && !strings.Contains(hop[:i], ":") {
+		hop = hop[:i]
+	}
+	return hop
+}
+
+// lastRunOutput returns the lines of a tunnel log written since ssh was last started
+func lastRunOutput(lines []string) []string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.Contains(lines[i], "tunnelman: starting ssh") {
+			return lines[i+1:]
+		}
+	}
+	return lines
+}
@@ -0,0 +1,68 @@
+// Package core provides tunnel chain tests.
+package core
+
+import (
+	"testing"
+)
+
+// TestSetHopChain tests that a chain becomes jump hosts and an SSH host
+func TestSetHopChain(t *testing.T) {
+	hops, err := ParseHopChain("bastion -> admin@gw:2222, db")
+	if err != nil {
+		t.Fatalf("Failed to parse chain: %v", err)
+	}
+
+	tunnel := NewTunnel("db", LocalForward)
+	if err := tunnel.SetHopChain(hops); err != nil {
+		t.Fatalf("Failed to set chain: %v", err)
+	}
+	if tunnel.SSHHost != "db" || tunnel.JumpHost != "bastion,admin@gw:2222" {
+		t.Errorf("Unexpected host %q via %q", tunnel.SSHHost, tunnel.JumpHost)
+	}
+
+	for _, spec := range []string{"bastion,,db", "bastion, my host"} {
+		if _, err := ParseHopChain(spec); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+// TestHopsCombinedState tests that hop states follow the tunnel status and the
+// hop recorded as failed
+func TestHopsCombinedState(t *testing.T) {
+	tunnel := &Tunnel{SSHHost: "db", JumpHost: "bastion,gw", Status: StatusRunning}
+	for _, hop := range tunnel.Hops() {
+		if hop.State != HopUp {
+			t.Errorf("Expected %s to be up, got %s", hop.Host, hop.State)
+		}
+	}
+
+	tunnel.Status = StatusStopped
+	tunnel.FailedHop = "gw"
+	expected := []HopState{HopDown, HopFailed, HopDown}
+	for i, hop := range tunnel.Hops() {
+		if hop.State != expected[i] {
+			t.Errorf("Hop %s: expected %s, got %s", hop.Host, expected[i], hop.State)
+		}
+	}
+}
+
+// TestFindFailedHop tests locating the broken hop in ssh error output
+func TestFindFailedHop(t *testing.T) {
+	chain := []string{"bastion", "admin@gw:2222", "db"}
+	log := []string{
+		"[2026-01-01 00:00:00] tunnelman: starting ssh -J bastion,admin@gw:2222 db",
+		"ssh: connect to host db port 22: Connection refused",
+		"[2026-01-01 00:00:05] tunnelman: starting ssh -J bastion,admin@gw:2222 db",
+		"ssh: Could not resolve hostname gw: Name or service not known",
+		"Connection closed by UNKNOWN port 65535",
+		"[2026-01-01 00:00:06] tunnelman: ssh exited: exit status 255",
+	}
+
+	if got := findFailedHop(lastRunOutput(log), chain); got != "admin@gw:2222" {
+		t.Errorf("Expected gw to have failed, got %q", got)
+	}
+	if got := findFailedHop([]string{"Permission denied (publickey)."}, chain); got != "" {
+		t.Errorf("Expected no failed hop, got %q", got)
+	}
+}
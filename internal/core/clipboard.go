@@ -0,0 +1,81 @@
+// Package core provides copying text to the system clipboard.
+package core
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrNoClipboard is returned when no clipboard tool can be used, e.g. in a
+// session over SSH, where the terminal's clipboard should be used instead
+var ErrNoClipboard = errors.New("no clipboard tool available")
+
+// clipboardCommands returns the commands that can set the clipboard here, in
+// order of preference
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbcopy"}}
+	case "windows":
+		return [][]string{{"clip"}}
+	}
+
+	var commands [][]string
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		commands = append(commands, []string{"wl-copy"})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		commands = append(commands,
+			[]string{"xclip", "-selection", "clipboard"},
+			[]string{"xsel", "--clipboard", "--input"})
+	}
+	return commands
+}
+
+// InRemoteSession reports whether tunnelman runs in an SSH session, where
+// clipboard tools would set the clipboard of the wrong machine
+func InRemoteSession() bool {
+	return os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != ""
+}
+
+// CopyToClipboard sets the system clipboard to text with pbcopy, clip,
+// wl-copy, xclip or xsel and returns the tool used. It returns ErrNoClipboard
+// in SSH sessions and when none of the tools is found.
+func CopyToClipboard(text string) (string, error) {
+	if InRemoteSession() {
+		return "", ErrNoClipboard
+	}
+
+	for _, command := range clipboardCommands() {
+		path, err := exec.LookPath(command[0])
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, command[1:]...)
+		cmd.Stdin = strings.NewReader(text)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("%s failed: %w: %s", command[0], err, strings.TrimSpace(string(out)))
+		}
+		return command[0], nil
+	}
+	return "", ErrNoClipboard
+}
+
+// OSC52 returns the escape sequence asking the terminal to set its clipboard
+// to text, which also works through SSH. Inside tmux and GNU screen the
+// sequence is wrapped to be passed on to the outer terminal.
+func OSC52(text string) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	switch {
+	case os.Getenv("TMUX") != "":
+		return "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		return "\x1bP" + sequence + "\x1b\\"
+	}
+	return sequence
+}
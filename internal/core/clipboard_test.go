@@ -0,0 +1,29 @@
+// Package core provides clipboard tests.
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestOSC52 tests the clipboard escape sequence, plain and wrapped for tmux
+func TestOSC52(t *testing.T) {
+	t.Setenv("TMUX", "")
+	t.Setenv("TERM", "xterm-256color")
+	if got := OSC52("localhost:8080"); got != "\x1b]52;c;bG9jYWxob3N0OjgwODA=\x07" {
+		t.Errorf("Unexpected sequence: %q", got)
+	}
+
+	t.Setenv("TMUX", "/tmp/tmux-1000/default,1234,0")
+	if got := OSC52("hi"); got != "\x1bPtmux;\x1b\x1b]52;c;aGk=\x07\x1b\\" {
+		t.Errorf("Unexpected tmux sequence: %q", got)
+	}
+}
+
+// TestCopyToClipboardOverSSH tests that clipboard tools are not used in SSH sessions
+func TestCopyToClipboardOverSSH(t *testing.T) {
+	t.Setenv("SSH_TTY", "/dev/pts/3")
+	if _, err := CopyToClipboard("text"); !errors.Is(err, ErrNoClipboard) {
+		t.Errorf("Expected ErrNoClipboard over SSH, got %v", err)
+	}
+}
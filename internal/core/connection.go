@@ -0,0 +1,95 @@
+// Package core provides keepalive and forward failure settings for SSH connections.
+package core
+
+import (
+	"strconv"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// Built-in connection settings, used when neither the tunnel nor the global
+// defaults set them
+const (
+	defaultServerAliveInterval  = 60
+	defaultServerAliveCountMax  = 3
+	defaultExitOnForwardFailure = true
+)
+
+// ConnectionDefaults holds the global keepalive and forward failure settings
+// for tunnels that do not set their own. Zero values use the built-in settings.
+type ConnectionDefaults struct {
+	// ServerAliveInterval is the keepalive interval in seconds; negative disables keepalives
+	ServerAliveInterval int
+
+	// ServerAliveCountMax is the number of unanswered keepalives before ssh disconnects
+	ServerAliveCountMax int
+
+	// ExitOnForwardFailure makes ssh exit when a forward cannot be set up
+	ExitOnForwardFailure *bool
+
+	// StrictHostKeyChecking is the policy for unknown host keys; empty
+	// picks one by whether prompts are enabled
+	StrictHostKeyChecking string
+}
+
+// connectionDefaultsFromConfig converts the stored global defaults
+func connectionDefaultsFromConfig(defaults *store.Defaults) ConnectionDefaults {
+	if defaults == nil {
+		return ConnectionDefaults{}
+	}
+	return ConnectionDefaults{
+		ServerAliveInterval:  defaults.ServerAliveInterval,
+		ServerAliveCountMax:  defaults.ServerAliveCountMax,
+		ExitOnForwardFailure: defaults.ExitOnForwardFailure,
+
+		StrictHostKeyChecking: defaults.StrictHostKeyChecking,
+	}
+}
+
+// resolve fills settings the tunnel leaves unset from the defaults, then from
+// the built-in settings
+func (d ConnectionDefaults) resolve(t *Tunnel) (interval, countMax int, exitOnFailure bool) {
+	interval = firstNonZero(t.ServerAliveInterval, d.ServerAliveInterval, defaultServerAliveInterval)
+	if interval < 0 {
+		interval = 0 // ssh disables keepalives at 0
+	}
+	countMax = firstNonZero(t.ServerAliveCountMax, d.ServerAliveCountMax, defaultServerAliveCountMax)
+
+	exitOnFailure = defaultExitOnForwardFailure
+	switch {
+	case t.ExitOnForwardFailure != nil:
+		exitOnFailure = *t.ExitOnForwardFailure
+	case d.ExitOnForwardFailure != nil:
+		exitOnFailure = *d.ExitOnForwardFailure
+	}
+	return interval, countMax, exitOnFailure
+}
+
+// args returns the keepalive and forward failure options for a tunnel. The
+// caller must hold t.mu or own t.
+func (d ConnectionDefaults) args(t *Tunnel) []string {
+	interval, countMax, exitOnFailure := d.resolve(t)
+	return []string{
+		"-o", "ServerAliveInterval=" + strconv.Itoa(interval), // Keep connection alive
+		"-o", "ServerAliveCountMax=" + strconv.Itoa(countMax), // Max keepalive attempts
+		"-o", "ExitOnForwardFailure=" + yesNo(exitOnFailure), // Exit if port forwarding fails
+	}
+}
+
+// firstNonZero returns the first of values that is not zero
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// yesNo formats a boolean as an ssh option value
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
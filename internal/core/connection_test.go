@@ -0,0 +1,106 @@
+// Package core provides connection setting tests.
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestConnectionArgs tests that keepalive and forward failure settings come
+// from the tunnel, then the global defaults, then the built-in values
+func TestConnectionArgs(t *testing.T) {
+	no := false
+	yes := true
+
+	tests := []struct {
+		name     string
+		tunnel   *Tunnel
+		defaults ConnectionDefaults
+		expected []string
+	}{
+		{
+			name:     "Built-in values",
+			tunnel:   &Tunnel{},
+			expected: []string{"-o", "ServerAliveInterval=60", "-o", "ServerAliveCountMax=3", "-o", "ExitOnForwardFailure=yes"},
+		},
+		{
+			name:     "Global defaults",
+			tunnel:   &Tunnel{},
+			defaults: ConnectionDefaults{ServerAliveInterval: 15, ServerAliveCountMax: 8, ExitOnForwardFailure: &no},
+			expected: []string{"-o", "ServerAliveInterval=15", "-o", "ServerAliveCountMax=8", "-o", "ExitOnForwardFailure=no"},
+		},
+		{
+			name:     "Tunnel overrides defaults",
+			tunnel:   &Tunnel{ServerAliveInterval: 300, ExitOnForwardFailure: &yes},
+			defaults: ConnectionDefaults{ServerAliveInterval: 15, ServerAliveCountMax: 8, ExitOnForwardFailure: &no},
+			expected: []string{"-o", "ServerAliveInterval=300", "-o", "ServerAliveCountMax=8", "-o", "ExitOnForwardFailure=yes"},
+		},
+		{
+			name:     "Keepalives disabled",
+			tunnel:   &Tunnel{ServerAliveInterval: -1},
+			defaults: ConnectionDefaults{ServerAliveInterval: 15},
+			expected: []string{"-o", "ServerAliveInterval=0", "-o", "ServerAliveCountMax=3", "-o", "ExitOnForwardFailure=yes"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.defaults.args(tt.tunnel); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// TestConnectionDefaultsFromConfig tests that global defaults are loaded from
+// the config, applied to ssh commands and kept when tunnels are saved
+func TestConnectionDefaultsFromConfig(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "defaults": {"serverAliveInterval": 20, "exitOnForwardFailure": false},
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "bastion", "localPort": 5432, "remotePort": 5432, "mode": "local", "serverAliveCountMax": 6}
+  ]
+}`
+	tm, configStore := newTestManager(t, configJSON)
+
+	tunnel, err := tm.GetTunnel("db")
+	if err != nil {
+		t.Fatalf("Failed to get tunnel: %v", err)
+	}
+	args := tm.processManager.buildSSHArgs(tunnel)
+	for _, want := range []string{"ServerAliveInterval=20", "ServerAliveCountMax=6", "ExitOnForwardFailure=no"} {
+		if !containsArg(args, want) {
+			t.Errorf("Expected %s in %v", want, args)
+		}
+	}
+
+	tunnel.Name = "database"
+	if err := tm.UpdateTunnel(tunnel); err != nil {
+		t.Fatalf("Failed to update tunnel: %v", err)
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	no := false
+	expected := &store.Defaults{ServerAliveInterval: 20, ExitOnForwardFailure: &no}
+	if !reflect.DeepEqual(config.Defaults, expected) {
+		t.Errorf("Expected defaults %+v to be kept, got %+v", expected, config.Defaults)
+	}
+	if config.Tunnels[0].ServerAliveCountMax != 6 {
+		t.Errorf("Expected keepalive count 6 to be saved, got %d", config.Tunnels[0].ServerAliveCountMax)
+	}
+}
+
+// containsArg reports whether args contains arg
+func containsArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
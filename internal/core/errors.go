@@ -0,0 +1,62 @@
+// Package core provides the error values returned by the tunnel manager.
+package core
+
+import (
+	"errors"
+)
+
+// Errors returned by TunnelManager, possibly wrapped with details; test for
+// them with errors.Is
+var (
+	// ErrTunnelNotFound is returned for an unknown tunnel name or ID
+	ErrTunnelNotFound = errors.New("tunnel not found")
+	// ErrAlreadyRunning is returned when a tunnel must be stopped for the operation
+	ErrAlreadyRunning = errors.New("tunnel is already running")
+	// ErrNotRunning is returned when stopping a tunnel that is not running
+	ErrNotRunning = errors.New("tunnel is not running")
+	// ErrPortInUse matches failures caused by a local port that is already taken
+	ErrPortInUse = errors.New("port already in use")
+	// ErrAuthFailed matches ssh failures caused by the host rejecting authentication
+	ErrAuthFailed = errors.New("authentication failed")
+	// ErrProfileNotFound is returned for a profile no tunnel or config entry has
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrProfileExists is returned when a profile name is already taken
+	ErrProfileExists = errors.New("profile already exists")
+	// ErrTrashEmpty is returned when restoring with no deleted tunnel to restore
+	ErrTrashEmpty = errors.New("no deleted tunnel to restore")
+)
+
+// errorCodes names each error for APIs that carry errors as text
+var errorCodes = map[error]string{
+	ErrTunnelNotFound: "not_found",
+	ErrAlreadyRunning: "already_running",
+	ErrNotRunning:     "not_running",
+	ErrPortInUse:      "port_in_use",
+	ErrAuthFailed:     "auth_failed",
+
+	ErrProfileNotFound: "profile_not_found",
+	ErrProfileExists:   "profile_exists",
+
+	ErrTrashEmpty: "trash_empty",
+}
+
+// ErrorCode returns a stable name for the kind of err, or "" if it is not one
+// of the manager's errors
+func ErrorCode(err error) string {
+	for target, code := range errorCodes {
+		if errors.Is(err, target) {
+			return code
+		}
+	}
+	return ""
+}
+
+// ErrorForCode returns the error named by a code from ErrorCode, or nil
+func ErrorForCode(code string) error {
+	for target, c := range errorCodes {
+		if c == code {
+			return target
+		}
+	}
+	return nil
+}
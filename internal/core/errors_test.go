@@ -0,0 +1,75 @@
+// Package core provides error value tests.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestManagerErrors tests that lifecycle operations return the manager's error values
+func TestManagerErrors(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "example.com", "localPort": 1080, "mode": "dynamic"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+
+	if err := tm.StartTunnel("missing"); !errors.Is(err, ErrTunnelNotFound) {
+		t.Errorf("Expected ErrTunnelNotFound, got %v", err)
+	}
+	if _, err := tm.FindTunnel("missing"); !errors.Is(err, ErrTunnelNotFound) {
+		t.Errorf("Expected ErrTunnelNotFound from FindTunnel, got %v", err)
+	}
+	if err := tm.StopTunnel("db"); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Expected ErrNotRunning, got %v", err)
+	}
+
+	tm.tunnels["db"].Status = StatusRunning
+	if err := tm.StartTunnel("db"); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Expected ErrAlreadyRunning, got %v", err)
+	}
+	if err := tm.DeleteTunnel("db"); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Expected ErrAlreadyRunning from DeleteTunnel, got %v", err)
+	}
+}
+
+// TestFailureErrors tests that classified failures match the manager's error values
+func TestFailureErrors(t *testing.T) {
+	auth := fmt.Errorf("failed to start tunnel: %w", &SSHError{Kind: FailureAuth})
+	if !errors.Is(auth, ErrAuthFailed) || errors.Is(auth, ErrPortInUse) {
+		t.Errorf("Expected an auth failure to match only ErrAuthFailed")
+	}
+	if !errors.Is(&SSHError{Kind: FailureAddressInUse}, ErrPortInUse) {
+		t.Error("Expected address-in-use failure to match ErrPortInUse")
+	}
+	if errors.Is(&SSHError{Kind: FailureNetwork}, ErrAuthFailed) {
+		t.Error("Expected network failure not to match ErrAuthFailed")
+	}
+	if !errors.Is(&PortConflictError{Port: 8080}, ErrPortInUse) {
+		t.Error("Expected port conflict to match ErrPortInUse")
+	}
+}
+
+// TestErrorCodes tests that error codes name the manager's errors and map back to them
+func TestErrorCodes(t *testing.T) {
+	for _, target := range []error{ErrTunnelNotFound, ErrAlreadyRunning, ErrNotRunning, ErrPortInUse, ErrAuthFailed} {
+		code := ErrorCode(fmt.Errorf("wrapped: %w", target))
+		if code == "" {
+			t.Errorf("Expected a code for %v", target)
+			continue
+		}
+		if back := ErrorForCode(code); back != target {
+			t.Errorf("Expected code %q to map back to %v, got %v", code, target, back)
+		}
+	}
+
+	if code := ErrorCode(errors.New("other")); code != "" {
+		t.Errorf("Expected no code for an unrelated error, got %q", code)
+	}
+	if err := ErrorForCode(""); err != nil {
+		t.Errorf("Expected no error for an empty code, got %v", err)
+	}
+}
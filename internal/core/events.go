@@ -0,0 +1,127 @@
+// Package core provides the status change event bus.
+package core
+
+import (
+	"sync"
+)
+
+// maxQueuedEvents limits how many status changes are kept for a subscriber
+// that does not keep up; the oldest are dropped beyond it
+const maxQueuedEvents = 1000
+
+// subscriber queues status changes for one consumer, so a slow consumer
+// neither blocks the manager nor loses events to the others
+type subscriber struct {
+	out chan TunnelStatusChange
+
+	mu    sync.Mutex
+	queue []TunnelStatusChange
+
+	// wake signals queued events; done is closed on unsubscribe
+	wake chan struct{}
+	done chan struct{}
+}
+
+// newSubscriber creates a subscriber and starts delivering its events
+func newSubscriber(buffer int) *subscriber {
+	s := &subscriber{
+		out:  make(chan TunnelStatusChange, buffer),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// push queues a status change without blocking
+func (s *subscriber) push(change TunnelStatusChange) {
+	s.mu.Lock()
+	if len(s.queue) >= maxQueuedEvents {
+		Debug("Status subscriber is not keeping up, dropping oldest event")
+		s.queue = s.queue[1:]
+	}
+	s.queue = append(s.queue, change)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued events in order until the subscriber is removed
+func (s *subscriber) run() {
+	defer close(s.out)
+
+	for {
+		s.mu.Lock()
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		next := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.out <- next:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Subscribe returns a channel receiving every subsequent status change, in
+// order. Pass it to Unsubscribe when done, which closes it.
+func (tm *TunnelManager) Subscribe() <-chan TunnelStatusChange {
+	s := newSubscriber(100)
+
+	tm.subMu.Lock()
+	tm.subscribers[s.out] = s
+	tm.subMu.Unlock()
+
+	return s.out
+}
+
+// Unsubscribe stops delivering status changes to a channel from Subscribe
+func (tm *TunnelManager) Unsubscribe(ch <-chan TunnelStatusChange) {
+	tm.subMu.Lock()
+	s, exists := tm.subscribers[ch]
+	delete(tm.subscribers, ch)
+	tm.subMu.Unlock()
+
+	if exists {
+		close(s.done)
+	}
+}
+
+// SubscribeFunc calls fn for every subsequent status change, one at a time,
+// until the returned function is called
+func (tm *TunnelManager) SubscribeFunc(fn func(TunnelStatusChange)) (unsubscribe func()) {
+	ch := tm.Subscribe()
+	go func() {
+		for change := range ch {
+			fn(change)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { tm.Unsubscribe(ch) })
+	}
+}
+
+// publish hands a status change to every subscriber
+func (tm *TunnelManager) publish(change TunnelStatusChange) {
+	tm.subMu.Lock()
+	defer tm.subMu.Unlock()
+
+	for _, s := range tm.subscribers {
+		s.push(change)
+	}
+}
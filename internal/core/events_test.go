@@ -0,0 +1,89 @@
+// Package core provides status event bus tests.
+package core
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// receiveChange returns the next status change from ch or fails the test
+func receiveChange(t *testing.T, ch <-chan TunnelStatusChange) TunnelStatusChange {
+	t.Helper()
+	select {
+	case change := <-ch:
+		return change
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for a status change")
+		return TunnelStatusChange{}
+	}
+}
+
+// TestSubscribersReceiveEveryChange tests that each subscriber gets every
+// status change in order, even one that starts reading late
+func TestSubscribersReceiveEveryChange(t *testing.T) {
+	tm, _ := newTestManager(t, "")
+
+	first := tm.Subscribe()
+	defer tm.Unsubscribe(first)
+	second := tm.Subscribe()
+	defer tm.Unsubscribe(second)
+
+	// More than the channel buffer, so the events have to be queued
+	const count = 250
+	for i := 0; i < count; i++ {
+		tm.notifyStatusChange(fmt.Sprintf("t%d", i), StatusStopped, StatusRunning, nil)
+	}
+
+	for _, ch := range []<-chan TunnelStatusChange{first, second} {
+		for i := 0; i < count; i++ {
+			if change := receiveChange(t, ch); change.TunnelID != fmt.Sprintf("t%d", i) {
+				t.Fatalf("Expected event for t%d, got %s", i, change.TunnelID)
+			}
+		}
+	}
+}
+
+// TestUnsubscribe tests that unsubscribing closes the channel and stops delivery
+func TestUnsubscribe(t *testing.T) {
+	tm, _ := newTestManager(t, "")
+
+	ch := tm.Subscribe()
+	tm.Unsubscribe(ch)
+	tm.notifyStatusChange("db", StatusStopped, StatusRunning, nil)
+
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("Expected no events after unsubscribing")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the channel to be closed")
+	}
+
+	// Unsubscribing twice is harmless
+	tm.Unsubscribe(ch)
+}
+
+// TestSubscribeFunc tests that callbacks receive status changes until unsubscribed
+func TestSubscribeFunc(t *testing.T) {
+	tm, _ := newTestManager(t, "")
+
+	received := make(chan string, 10)
+	unsubscribe := tm.SubscribeFunc(func(change TunnelStatusChange) {
+		received <- change.TunnelID
+	})
+	tm.notifyStatusChange("db", StatusStopped, StatusRunning, nil)
+
+	select {
+	case id := <-received:
+		if id != "db" {
+			t.Errorf("Expected event for db, got %s", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the callback")
+	}
+
+	unsubscribe()
+	unsubscribe()
+}
@@ -0,0 +1,158 @@
+// Package core provides ${VAR} expansion of tunnel configurations.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// ExpandEnv replaces ${VAR} references in s with the values of environment
+// variables, and $$ with a literal $. Any other $ is kept as is. Unset
+// variables and unterminated references are errors.
+func ExpandEnv(s string) (string, error) {
+	var b strings.Builder
+	for {
+		i := strings.IndexByte(s, '$')
+		if i < 0 || i == len(s)-1 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			s = s[i+2:]
+
+		case '{':
+			end := strings.IndexByte(s[i+2:], '}')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated ${ in %q", s)
+			}
+			name := s[i+2 : i+2+end]
+			value, ok := os.LookupEnv(name)
+			if name == "" || !ok {
+				return "", fmt.Errorf("environment variable %q is not set", name)
+			}
+			b.WriteString(value)
+			s = s[i+3+end:]
+
+		default:
+			b.WriteByte('$')
+			s = s[i+1:]
+		}
+	}
+}
+
+// configTemplates are the config values of a tunnel that were written with
+// ${VAR} references, kept to save them unexpanded
+type configTemplates struct {
+	host     string
+	jumpHost string
+	options  []string
+	ports    map[string]string
+}
+
+// expandConfig expands the host, jump host, options and ports of a tunnel
+// config in place, returning the templates they were expanded from. Values
+// that fail to expand are kept as written.
+func expandConfig(tc *store.TunnelConfig) (*configTemplates, error) {
+	templates := &configTemplates{ports: make(map[string]string)}
+	var errs []error
+	expand := func(value string, template *string) string {
+		if !strings.Contains(value, "$") {
+			return value
+		}
+		expanded, err := ExpandEnv(value)
+		if err != nil {
+			errs = append(errs, err)
+			return value
+		}
+		*template = value
+		return expanded
+	}
+
+	tc.Host = expand(tc.Host, &templates.host)
+	tc.JumpHost = expand(tc.JumpHost, &templates.jumpHost)
+
+	options := make([]string, len(tc.Options))
+	expanded := false
+	for i, option := range tc.Options {
+		var template string
+		options[i] = expand(option, &template)
+		expanded = expanded || template != ""
+	}
+	if expanded {
+		templates.options, tc.Options = tc.Options, options
+	}
+
+	for key, value := range tc.PortTemplates {
+		var template string
+		value = expand(value, &template)
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s %q is not a number", key, value))
+			continue
+		}
+		if key == "remotePort" {
+			tc.RemotePort = port
+		} else {
+			tc.LocalPort = port
+		}
+		if template != "" {
+			templates.ports[key] = template
+		}
+	}
+	tc.PortTemplates = nil
+
+	if templates.host == "" && templates.jumpHost == "" && templates.options == nil && len(templates.ports) == 0 {
+		templates = nil
+	}
+	return templates, errors.Join(errs...)
+}
+
+// apply writes the templates of values that still expand to the tunnel's
+// settings back into its stored config, so edited values replace them
+func (t *configTemplates) apply(tc *store.TunnelConfig) {
+	if t == nil {
+		return
+	}
+	matches := func(template, value string) bool {
+		expanded, err := ExpandEnv(template)
+		return template != "" && err == nil && expanded == value
+	}
+
+	if matches(t.host, tc.Host) {
+		tc.Host = t.host
+	}
+	if matches(t.jumpHost, tc.JumpHost) {
+		tc.JumpHost = t.jumpHost
+	}
+	if t.options != nil && len(t.options) == len(tc.Options) {
+		expanded := make([]string, len(t.options))
+		for i, option := range t.options {
+			expanded[i], _ = ExpandEnv(option)
+		}
+		if slices.Equal(expanded, tc.Options) {
+			tc.Options = t.options
+		}
+	}
+	for key, template := range t.ports {
+		port := tc.LocalPort
+		if key == "remotePort" {
+			port = tc.RemotePort
+		}
+		if matches(template, strconv.Itoa(port)) {
+			if tc.PortTemplates == nil {
+				tc.PortTemplates = make(map[string]string)
+			}
+			tc.PortTemplates[key] = template
+		}
+	}
+}
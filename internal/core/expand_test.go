@@ -0,0 +1,86 @@
+// Package core provides config expansion tests.
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestExpandEnv tests ${VAR} references, the $$ escape and errors
+func TestExpandEnv(t *testing.T) {
+	t.Setenv("TUNNELMAN_TEST_BASTION", "bastion.example.com")
+
+	tests := []struct {
+		input    string
+		expected string
+		fails    bool
+	}{
+		{"${TUNNELMAN_TEST_BASTION}", "bastion.example.com", false},
+		{"user@${TUNNELMAN_TEST_BASTION}:22", "user@bastion.example.com:22", false},
+		{"$${TUNNELMAN_TEST_BASTION}", "${TUNNELMAN_TEST_BASTION}", false},
+		{"cost$5 $HOME $", "cost$5 $HOME $", false},
+		{"${TUNNELMAN_TEST_UNSET}", "", true},
+		{"${TUNNELMAN_TEST_BASTION", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ExpandEnv(tt.input)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("ExpandEnv(%q): expected an error, got %q", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.expected {
+			t.Errorf("ExpandEnv(%q) = %q (%v), expected %q", tt.input, got, err, tt.expected)
+		}
+	}
+}
+
+// TestConfigTemplates tests that references are expanded on load and saved
+// unexpanded until the value is edited
+func TestConfigTemplates(t *testing.T) {
+	t.Setenv("TUNNELMAN_TEST_BASTION", "bastion.example.com")
+	t.Setenv("TUNNELMAN_TEST_PORT", "15432")
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "${TUNNELMAN_TEST_BASTION}", "localPort": "${TUNNELMAN_TEST_PORT}", "remotePort": 5432, "mode": "local", "options": ["-o", "SetEnv=COST=$$5"]}
+  ]
+}`
+	tm, configStore := newTestManager(t, configJSON)
+
+	db, _ := tm.GetTunnel("db")
+	if db.SSHHost != "bastion.example.com" || db.LocalPort != 15432 || !slices.Equal(db.ExtraArgs, []string{"-o", "SetEnv=COST=$5"}) {
+		t.Fatalf("Expected the config to be expanded, got %s, %d, %q", db.SSHHost, db.LocalPort, db.ExtraArgs)
+	}
+
+	db.Description = "Reporting database"
+	if err := tm.UpdateTunnel(db); err != nil {
+		t.Fatalf("UpdateTunnel failed: %v", err)
+	}
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	saved := config.Tunnels[0]
+	if saved.Host != "${TUNNELMAN_TEST_BASTION}" || saved.PortTemplates["localPort"] != "${TUNNELMAN_TEST_PORT}" || saved.Options[1] != "SetEnv=COST=$$5" {
+		t.Errorf("Expected the references to be saved, got %+v", saved)
+	}
+
+	// A tunnel edited without its references, as the TUI form does, keeps
+	// those of the values it does not change
+	edited := NewTunnel("db", LocalForward)
+	edited.ID = "db"
+	edited.SSHHost = "other.example.com"
+	edited.LocalPort = 15432
+	edited.RemoteHost = "localhost"
+	edited.RemotePort = 5432
+	if err := tm.UpdateTunnel(edited); err != nil {
+		t.Fatalf("UpdateTunnel failed: %v", err)
+	}
+	config, _ = configStore.LoadConfig()
+	if saved := config.Tunnels[0]; saved.Host != "other.example.com" || saved.PortTemplates["localPort"] != "${TUNNELMAN_TEST_PORT}" {
+		t.Errorf("Expected only the edited host to lose its reference, got %+v", saved)
+	}
+}
@@ -0,0 +1,157 @@
+// Package core provides classification of ssh failures.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// FailureKind classifies why a tunnel's ssh process failed
+type FailureKind string
+
+const (
+	// FailureUnknown indicates ssh failed for a reason that was not recognized
+	FailureUnknown FailureKind = "unknown"
+	// FailureAuth indicates the SSH host rejected every authentication method
+	FailureAuth FailureKind = "auth"
+	// FailureHostKey indicates the SSH host's key could not be verified
+	FailureHostKey FailureKind = "host-key"
+	// FailureNetwork indicates the SSH host could not be resolved or reached,
+	// or the connection dropped
+	FailureNetwork FailureKind = "network"
+	// FailureAddressInUse indicates a port the tunnel listens on was taken
+	FailureAddressInUse FailureKind = "address-in-use"
+	// FailureForward indicates the SSH server refused to set up a forward
+	FailureForward FailureKind = "forward"
+)
+
+// SSHError is the failure of a tunnel's ssh process, classified from its
+// output and exit code
+type SSHError struct {
+	Kind     FailureKind
+	ExitCode int
+
+	// Message is the line of ssh output explaining the failure, if any
+	Message string
+}
+
+// Error implements the error interface
+func (e *SSHError) Error() string {
+	summary := failureSummaries[e.Kind]
+	if summary == "" {
+		summary = fmt.Sprintf("ssh exited with status %d", e.ExitCode)
+	}
+	if e.Message == "" {
+		return summary
+	}
+	return fmt.Sprintf("%s: %s", summary, e.Message)
+}
+
+// Is matches the manager's error values for the failures they describe
+func (e *SSHError) Is(target error) bool {
+	switch target {
+	case ErrAuthFailed:
+		return e.Kind == FailureAuth
+	case ErrPortInUse:
+		return e.Kind == FailureAddressInUse
+	}
+	return false
+}
+
+// Hint suggests how to fix the failure
+func (e *SSHError) Hint() string {
+	return failureHints[e.Kind]
+}
+
+// failureSummaries describes each kind of failure
+var failureSummaries = map[FailureKind]string{
+	FailureAuth:         "authentication failed",
+	FailureHostKey:      "host key verification failed",
+	FailureNetwork:      "host unreachable",
+	FailureAddressInUse: "address already in use",
+	FailureForward:      "forward failed",
+}
+
+// failureHints suggests a remedy for each kind of failure
+var failureHints = map[FailureKind]string{
+	FailureAuth:         "Load your key with ssh-add, or check the user and identity file for the host in ~/.ssh/config",
+	FailureHostKey:      "Verify the host's key, then update ~/.ssh/known_hosts (ssh-keygen -R HOST removes a stale entry)",
+	FailureNetwork:      "Check that the host name resolves and the host is reachable (VPN, firewall, jump hosts)",
+	FailureAddressInUse: "Stop whatever holds the port, or choose a different port for the tunnel",
+	FailureForward:      "Check AllowTcpForwarding and GatewayPorts in the server's sshd_config, and that the remote port is free",
+}
+
+// failurePatterns maps ssh output to failure kinds, in order of precedence:
+// a dropped connection, for example, is reported after the reason for it
+var failurePatterns = []struct {
+	kind    FailureKind
+	pattern *regexp.Regexp
+}{
+	{FailureHostKey, regexp.MustCompile(`(?i)host key verification failed|remote host identification has changed`)},
+	{FailureAuth, regexp.MustCompile(`(?i)permission denied|too many authentication failures|no supported authentication methods|authentication failed`)},
+	{FailureAddressInUse, regexp.MustCompile(`(?i)address already in use|cannot listen to port|could not request local forwarding`)},
+	{FailureForward, regexp.MustCompile(`(?i)remote port forwarding failed|administratively prohibited|forwarding failed|open failed`)},
+	{FailureNetwork, regexp.MustCompile(`(?i)could not resolve hostname|name or service not known|connection refused|connection timed out|operation timed out|no route to host|network is unreachable|connection closed by|connection reset|broken pipe|timeout, server .* not responding|kex_exchange_identification`)},
+}
+
+// classifySSHFailure returns the failure of an ssh process that exited with
+// exitCode after writing output, or nil if it exited cleanly without
+// reporting a failure
+func classifySSHFailure(output []string, exitCode int) *SSHError {
+	for _, p := range failurePatterns {
+		for _, line := range output {
+			if p.pattern.MatchString(line) {
+				return &SSHError{Kind: p.kind, ExitCode: exitCode, Message: sshMessage(line)}
+			}
+		}
+	}
+
+	if exitCode == 0 {
+		return nil
+	}
+	failure := &SSHError{Kind: FailureUnknown, ExitCode: exitCode}
+	for i := len(output) - 1; i >= 0; i-- {
+		if line := sshMessage(output[i]); line != "" && !strings.Contains(line, "tunnelman:") {
+			failure.Message = line
+			break
+		}
+	}
+	return failure
+}
+
+// sshMessage strips the timestamp tunnelman prefixes log lines with
+func sshMessage(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "[") {
+		if i := strings.Index(line, "] "); i >= 0 {
+			line = line[i+2:]
+		}
+	}
+	return line
+}
+
+// FailureKindOf returns the kind of a tunnel failure, or FailureUnknown if err
+// was not classified
+func FailureKindOf(err error) FailureKind {
+	var sshErr *SSHError
+	if errors.As(err, &sshErr) {
+		return sshErr.Kind
+	}
+	var conflict *PortConflictError
+	if errors.As(err, &conflict) {
+		return FailureAddressInUse
+	}
+	return FailureUnknown
+}
+
+// FailureHint returns a suggestion for fixing a tunnel failure, or "" if
+// there is none
+func FailureHint(err error) string {
+	var hinted interface{ Hint() string }
+	if errors.As(err, &hinted) {
+		return hinted.Hint()
+	}
+	return ""
+}
@@ -0,0 +1,120 @@
+// Package core provides ssh failure classification tests.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestClassifySSHFailure tests that ssh output and exit codes map to failure kinds
+func TestClassifySSHFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   []string
+		exitCode int
+		kind     FailureKind
+		message  string
+	}{
+		{
+			name:     "Authentication",
+			output:   []string{"admin@db: Permission denied (publickey)."},
+			exitCode: 255,
+			kind:     FailureAuth,
+			message:  "admin@db: Permission denied (publickey).",
+		},
+		{
+			name:     "Unresolvable host",
+			output:   []string{"ssh: Could not resolve hostname nowhere: Name or service not known"},
+			exitCode: 255,
+			kind:     FailureNetwork,
+		},
+		{
+			name:     "Unreachable host",
+			output:   []string{"ssh: connect to host 10.0.0.9 port 22: Connection timed out"},
+			exitCode: 255,
+			kind:     FailureNetwork,
+		},
+		{
+			name:     "Local port taken",
+			output:   []string{"bind [127.0.0.1]:8080: Address already in use", "channel_setup_fwd_listener_tcpip: cannot listen to port: 8080", "Could not request local forwarding."},
+			exitCode: 255,
+			kind:     FailureAddressInUse,
+			message:  "bind [127.0.0.1]:8080: Address already in use",
+		},
+		{
+			name:     "Remote forward refused",
+			output:   []string{"Error: remote port forwarding failed for listen port 9000"},
+			exitCode: 255,
+			kind:     FailureForward,
+		},
+		{
+			name:     "Host key changed",
+			output:   []string{"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@", "Host key verification failed."},
+			exitCode: 255,
+			kind:     FailureHostKey,
+		},
+		{
+			name:     "Cause wins over dropped connection",
+			output:   []string{"admin@db: Permission denied (publickey).", "Connection closed by 10.0.0.9 port 22"},
+			exitCode: 255,
+			kind:     FailureAuth,
+		},
+		{
+			name:     "Unrecognized",
+			output:   []string{"something odd", "[2026-01-02 03:04:05] tunnelman: ssh exited: exit status 1"},
+			exitCode: 1,
+			kind:     FailureUnknown,
+			message:  "something odd",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failure := classifySSHFailure(tt.output, tt.exitCode)
+			if failure == nil {
+				t.Fatal("Expected a failure")
+			}
+			if failure.Kind != tt.kind {
+				t.Errorf("Expected kind %s, got %s", tt.kind, failure.Kind)
+			}
+			if tt.message != "" && failure.Message != tt.message {
+				t.Errorf("Expected message %q, got %q", tt.message, failure.Message)
+			}
+			if tt.kind != FailureUnknown && failure.Hint() == "" {
+				t.Error("Expected a hint")
+			}
+		})
+	}
+
+	if failure := classifySSHFailure([]string{"Transferred: sent 1, received 2 bytes"}, 0); failure != nil {
+		t.Errorf("Expected a clean exit not to be a failure, got %v", failure)
+	}
+}
+
+// TestFailureKindOf tests that wrapped tunnel errors keep their kind and hint
+func TestFailureKindOf(t *testing.T) {
+	err := fmt.Errorf("tunnel db failed: %w", &SSHError{Kind: FailureAuth, ExitCode: 255, Message: "Permission denied (publickey)."})
+	if kind := FailureKindOf(err); kind != FailureAuth {
+		t.Errorf("Expected %s, got %s", FailureAuth, kind)
+	}
+	if !strings.HasPrefix(err.Error(), "tunnel db failed: authentication failed: ") {
+		t.Errorf("Unexpected message: %v", err)
+	}
+	if FailureHint(err) == "" {
+		t.Error("Expected a hint for an authentication failure")
+	}
+
+	conflict := fmt.Errorf("failed to start tunnel: %w", &PortConflictError{Port: 8080, TunnelName: "web"})
+	if kind := FailureKindOf(conflict); kind != FailureAddressInUse {
+		t.Errorf("Expected %s, got %s", FailureAddressInUse, kind)
+	}
+	if hint := FailureHint(conflict); !strings.Contains(hint, "web") {
+		t.Errorf("Expected the hint to name the tunnel, got %q", hint)
+	}
+
+	if kind := FailureKindOf(errors.New("boom")); kind != FailureUnknown {
+		t.Errorf("Expected %s, got %s", FailureUnknown, kind)
+	}
+}
@@ -0,0 +1,590 @@
+// Package core provides the port forwards carried by a tunnel's SSH session.
+package core
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Forward is one port forwarding specification. A tunnel's own type and port
+// fields are its primary forward; Tunnel.Forwards holds any additional ones.
+type Forward struct {
+	Type       TunnelType `json:"type"`
+	LocalHost  string     `json:"local_host,omitempty"`
+	LocalPort  int        `json:"local_port"`
+	RemoteHost string     `json:"remote_host,omitempty"`
+	RemotePort int        `json:"remote_port,omitempty"`
+
+	// RemoteBindAddress is the address a remote forward listens on at the SSH
+	// host. Empty uses the server's default, usually loopback only.
+	RemoteBindAddress string `json:"remote_bind_address,omitempty"`
+}
+
+// splitForwardSpec splits a forwarding specification at the colons that are
+// not inside a bracketed IPv6 address, e.g. "[::1]:8080:db:5432". The fields
+// keep their brackets.
+func splitForwardSpec(spec string) ([]string, error) {
+	var fields []string
+	start, depth := 0, 0
+	for i, c := range spec {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				fields = append(fields, spec[start:i])
+				start = i + 1
+			}
+		}
+		if depth < 0 || depth > 1 {
+			return nil, fmt.Errorf("unbalanced brackets in %q", spec)
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unbalanced brackets in %q", spec)
+	}
+	return append(fields, spec[start:]), nil
+}
+
+// NormalizeHost trims spaces and the brackets around an IPv6 address, so
+// "[::1]" is stored as "::1"
+func NormalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	if strings.HasPrefix(host, "[") && strings.HasSuffix(host, "]") {
+		return host[1 : len(host)-1]
+	}
+	return host
+}
+
+// BracketHost puts brackets around an IPv6 address so it can be followed by a
+// colon and port, as ssh expects in forwarding specifications
+func BracketHost(host string) string {
+	if strings.Contains(host, ":") && !strings.HasPrefix(host, "[") {
+		return "[" + host + "]"
+	}
+	return host
+}
+
+// validateHost checks a host or bind address of a forward. Addresses with
+// colons must be IPv6 addresses, optionally with a zone.
+func validateHost(host, what string) error {
+	if strings.ContainsAny(host, " \t[]") {
+		return fmt.Errorf("invalid %s: %q", what, host)
+	}
+	if strings.Contains(host, ":") {
+		addr, _, _ := strings.Cut(host, "%")
+		if ip := net.ParseIP(addr); ip == nil || ip.To4() != nil {
+			return fmt.Errorf("invalid %s: %q is not an IPv6 address", what, host)
+		}
+	}
+	return nil
+}
+
+// ParseForward parses a forwarding specification in the format accepted by
+// ParseForwardingSpec. Local and dynamic forwards may be prefixed with a
+// local bind address, e.g. "0.0.0.0:8080:db:5432", and remote and reverse
+// dynamic forwards with a remote bind address, e.g. "0.0.0.0:8080:3000".
+// IPv6 addresses are written in brackets, e.g. "[::1]:8080:[fd00::5]:5432".
+func ParseForward(spec string, tunnelType TunnelType) (Forward, error) {
+	parts, err := splitForwardSpec(spec)
+	if err != nil {
+		return Forward{}, err
+	}
+
+	var localBind string
+	if tunnelType == LocalForward && len(parts) == 4 ||
+		tunnelType == DynamicForward && len(parts) == 2 {
+		localBind, parts = NormalizeHost(parts[0]), parts[1:]
+		if localBind == "" {
+			return Forward{}, fmt.Errorf("empty bind address (use * or 0.0.0.0 for all interfaces)")
+		}
+	}
+
+	var bindAddress string
+	if tunnelType == RemoteForward && len(parts) == 3 ||
+		tunnelType == ReverseDynamicForward && len(parts) == 2 {
+		bindAddress, parts = NormalizeHost(parts[0]), parts[1:]
+		if bindAddress == "" {
+			return Forward{}, fmt.Errorf("empty remote bind address (use * or 0.0.0.0 for all interfaces)")
+		}
+	}
+
+	localHost, localPort, remoteHost, remotePort, err := ParseForwardingSpec(strings.Join(parts, ":"), tunnelType)
+	if err != nil {
+		return Forward{}, err
+	}
+	if tunnelType == RemoteForward {
+		// For RemoteForward, LocalHost is the destination
+		localHost = "127.0.0.1"
+	}
+	if localBind != "" {
+		localHost = localBind
+	}
+	return Forward{
+		Type:              tunnelType,
+		LocalHost:         localHost,
+		LocalPort:         localPort,
+		RemoteHost:        remoteHost,
+		RemotePort:        remotePort,
+		RemoteBindAddress: bindAddress,
+	}, nil
+}
+
+// maxForwardsPerSpec limits how many forwards a port range or list may expand to
+const maxForwardsPerSpec = 256
+
+// ParseForwardSpec parses a forwarding specification whose ports may be
+// ranges or lists, e.g. "9000-9005:db:9000-9005" or "-D 1080,1081", into one
+// forward per port. Both ports of a local or remote forward must list the
+// same number of ports, or the destination a single one; a local forward
+// written "9000-9005:db", or a remote one written "9000-9005", forwards to
+// the same ports on the other side.
+func ParseForwardSpec(spec string, tunnelType TunnelType) ([]Forward, error) {
+	parts, err := splitForwardSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// Indices of the listening and destination port fields, -1 if absent
+	listen, dest := -1, -1
+	switch {
+	case tunnelType == LocalForward && len(parts) == 2:
+		listen = 0
+		parts = append(parts, parts[0])
+		dest = 2
+	case tunnelType == LocalForward && len(parts) == 3:
+		listen, dest = 0, 2
+	case tunnelType == LocalForward && len(parts) == 4:
+		listen, dest = 1, 3
+	case tunnelType == RemoteForward && len(parts) == 1:
+		listen = 0
+		parts = append(parts, parts[0])
+		dest = 1
+	case tunnelType == RemoteForward && len(parts) == 2:
+		listen, dest = 0, 1
+	case tunnelType == RemoteForward && len(parts) == 3:
+		listen, dest = 1, 2
+	case (tunnelType == DynamicForward || tunnelType == ReverseDynamicForward) && len(parts) <= 2:
+		listen = len(parts) - 1
+	default:
+		// Let ParseForward report the malformed specification
+		forward, err := ParseForward(spec, tunnelType)
+		if err != nil {
+			return nil, err
+		}
+		return []Forward{forward}, nil
+	}
+
+	listenPorts, err := parsePortList(parts[listen])
+	if err != nil {
+		return nil, err
+	}
+	var destPorts []string
+	if dest >= 0 {
+		if destPorts, err = parsePortList(parts[dest]); err != nil {
+			return nil, err
+		}
+		switch {
+		case len(destPorts) == 1:
+			for len(destPorts) < len(listenPorts) {
+				destPorts = append(destPorts, destPorts[0])
+			}
+		case len(destPorts) != len(listenPorts):
+			return nil, fmt.Errorf("%s and %s list different numbers of ports", parts[listen], parts[dest])
+		}
+	}
+
+	forwards := make([]Forward, 0, len(listenPorts))
+	for i, port := range listenPorts {
+		single := append([]string(nil), parts...)
+		single[listen] = port
+		if dest >= 0 {
+			single[dest] = destPorts[i]
+		}
+		forward, err := ParseForward(strings.Join(single, ":"), tunnelType)
+		if err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, forward)
+	}
+	return forwards, nil
+}
+
+// parsePortList expands a port field such as "9000-9005" or "8080,8443" into
+// its individual ports. Fields that are not ranges or lists are returned as
+// they are, for ParseForward to check.
+func parsePortList(field string) ([]string, error) {
+	var ports []string
+	for _, item := range strings.Split(field, ",") {
+		first, last, isRange := strings.Cut(item, "-")
+		if !isRange {
+			ports = append(ports, item)
+			continue
+		}
+
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port range %q", item)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil || to < from {
+			return nil, fmt.Errorf("invalid port range %q", item)
+		}
+		if to-from >= maxForwardsPerSpec {
+			return nil, fmt.Errorf("port range %q is too large (at most %d ports)", item, maxForwardsPerSpec)
+		}
+		for port := from; port <= to; port++ {
+			ports = append(ports, strconv.Itoa(port))
+		}
+	}
+	if len(ports) > maxForwardsPerSpec {
+		return nil, fmt.Errorf("too many ports in %q (at most %d)", field, maxForwardsPerSpec)
+	}
+	return ports, nil
+}
+
+// ParseForwards parses additional forwards written as ssh flags, e.g.
+// "-L 6379:redis:6379 -D 1080 -R 9000:3000". Ports may be ranges or lists,
+// as accepted by ParseForwardSpec.
+func ParseForwards(text string) ([]Forward, error) {
+	fields := strings.Fields(text)
+	var forwards []Forward
+	for i := 0; i < len(fields); i += 2 {
+		var tunnelType TunnelType
+		switch fields[i] {
+		case "-L":
+			tunnelType = LocalForward
+		case "-R":
+			tunnelType = RemoteForward
+		case "-D":
+			tunnelType = DynamicForward
+		case "-RD":
+			tunnelType = ReverseDynamicForward
+		default:
+			return nil, fmt.Errorf("expected -L, -R, -D or -RD, got %q", fields[i])
+		}
+		if i+1 >= len(fields) {
+			return nil, fmt.Errorf("%s requires a forwarding specification", fields[i])
+		}
+
+		expanded, err := ParseForwardSpec(fields[i+1], tunnelType)
+		if err != nil {
+			return nil, err
+		}
+		forwards = append(forwards, expanded...)
+	}
+	return forwards, nil
+}
+
+// FormatForwards writes forwards in the form read by ParseForwards, joining
+// forwards on consecutive ports into ranges
+func FormatForwards(forwards []Forward) string {
+	runs := forwardRuns(forwards)
+	specs := make([]string, 0, len(runs))
+	for _, r := range runs {
+		f := r.first
+		switch f.Type {
+		case LocalForward:
+			specs = append(specs, fmt.Sprintf("-L %s%s:%s:%s", f.localBindPrefix(), r.localPorts(), BracketHost(f.RemoteHost), r.remotePorts()))
+		case RemoteForward:
+			specs = append(specs, fmt.Sprintf("-R %s%s:%s", f.remoteBindPrefix(), r.remotePorts(), r.localPorts()))
+		case DynamicForward:
+			specs = append(specs, fmt.Sprintf("-D %s%s", f.localBindPrefix(), r.localPorts()))
+		case ReverseDynamicForward:
+			specs = append(specs, fmt.Sprintf("-RD %s%s", f.remoteBindPrefix(), r.remotePorts()))
+		}
+	}
+	return strings.Join(specs, " ")
+}
+
+// SummarizeForwards returns compact descriptions of forwards, with forwards
+// on consecutive ports summarized as one range
+func SummarizeForwards(forwards []Forward) []string {
+	runs := forwardRuns(forwards)
+	summaries := make([]string, 0, len(runs))
+	for _, r := range runs {
+		summaries = append(summaries, r.summary())
+	}
+	return summaries
+}
+
+// LeadingRunLength returns how many forwards, starting with the first, are on
+// consecutive ports and would be summarized as one range
+func LeadingRunLength(forwards []Forward) int {
+	if len(forwards) == 0 {
+		return 0
+	}
+	run := forwardRun{first: forwards[0], last: forwards[0]}
+	n := 1
+	for _, f := range forwards[1:] {
+		if !run.extends(f) {
+			break
+		}
+		run.last = f
+		n++
+	}
+	return n
+}
+
+// forwardRun is a sequence of forwards that differ only in their ports, each
+// one higher than in the previous forward
+type forwardRun struct {
+	first, last Forward
+}
+
+// forwardRuns groups forwards into runs on consecutive ports, keeping their order
+func forwardRuns(forwards []Forward) []forwardRun {
+	var runs []forwardRun
+	for _, f := range forwards {
+		if n := len(runs); n > 0 && runs[n-1].extends(f) {
+			runs[n-1].last = f
+			continue
+		}
+		runs = append(runs, forwardRun{first: f, last: f})
+	}
+	return runs
+}
+
+// extends reports whether f continues the run on the next ports
+func (r forwardRun) extends(f Forward) bool {
+	last := r.last
+	if f.Type != last.Type || f.LocalHost != last.LocalHost || f.RemoteHost != last.RemoteHost ||
+		f.RemoteBindAddress != last.RemoteBindAddress {
+		return false
+	}
+	switch f.Type {
+	case LocalForward, RemoteForward:
+		return f.LocalPort == last.LocalPort+1 && f.RemotePort == last.RemotePort+1
+	case DynamicForward:
+		return f.LocalPort == last.LocalPort+1
+	case ReverseDynamicForward:
+		return f.RemotePort == last.RemotePort+1
+	}
+	return false
+}
+
+// localPorts returns the local ports of the run, e.g. "9000-9005"
+func (r forwardRun) localPorts() string {
+	return portRange(r.first.LocalPort, r.last.LocalPort)
+}
+
+// remotePorts returns the remote ports of the run
+func (r forwardRun) remotePorts() string {
+	return portRange(r.first.RemotePort, r.last.RemotePort)
+}
+
+// portRange formats the ports from first to last
+func portRange(first, last int) string {
+	if first == last {
+		return strconv.Itoa(first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
+
+// summary returns a compact description of the forwarded ports of the run
+func (r forwardRun) summary() string {
+	f := r.first
+	switch f.Type {
+	case LocalForward:
+		return fmt.Sprintf("L:%s%s→%s:%s", f.localBindPrefix(), r.localPorts(), BracketHost(f.RemoteHost), r.remotePorts())
+	case RemoteForward:
+		return fmt.Sprintf("R:%s%s→%s", f.remoteBindPrefix(), r.remotePorts(), r.localPorts())
+	case DynamicForward:
+		return fmt.Sprintf("D:%s%s", f.localBindPrefix(), r.localPorts())
+	case ReverseDynamicForward:
+		return fmt.Sprintf("RD:%s%s", f.remoteBindPrefix(), r.remotePorts())
+	}
+	return ""
+}
+
+// Validate checks the ports, hosts and type of the forward
+func (f Forward) Validate() error {
+	if err := f.validateHosts(); err != nil {
+		return err
+	}
+
+	switch f.Type {
+	case LocalForward, RemoteForward:
+		if f.LocalPort <= 0 || f.LocalPort > 65535 {
+			return fmt.Errorf("invalid local port: %d", f.LocalPort)
+		}
+		if f.RemotePort <= 0 || f.RemotePort > 65535 {
+			return fmt.Errorf("invalid remote port: %d", f.RemotePort)
+		}
+		if f.Type == LocalForward && f.RemoteHost == "" {
+			return fmt.Errorf("remote host is required for local forward %d", f.LocalPort)
+		}
+
+	case DynamicForward:
+		if f.LocalPort <= 0 || f.LocalPort > 65535 {
+			return fmt.Errorf("invalid local port: %d", f.LocalPort)
+		}
+
+	case ReverseDynamicForward:
+		if f.RemotePort <= 0 || f.RemotePort > 65535 {
+			return fmt.Errorf("invalid remote port: %d", f.RemotePort)
+		}
+
+	default:
+		return fmt.Errorf("invalid forward type: %s", f.Type)
+	}
+	return nil
+}
+
+// validateHosts checks the addresses the forward listens on and connects to
+func (f Forward) validateHosts() error {
+	switch f.Type {
+	case LocalForward, DynamicForward:
+		if err := validateHost(f.LocalHost, "bind address"); err != nil {
+			return err
+		}
+	case RemoteForward:
+		if err := validateHost(f.LocalHost, "local host"); err != nil {
+			return err
+		}
+	}
+	if f.Type == LocalForward {
+		if err := validateHost(f.RemoteHost, "remote host"); err != nil {
+			return err
+		}
+	}
+	if f.IsRemote() {
+		if err := validateHost(f.RemoteBindAddress, "remote bind address"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Args returns the ssh flag and specification for the forward
+func (f Forward) Args() []string {
+	switch f.Type {
+	case LocalForward:
+		// -L [bind_address:]port:host:hostport
+		return []string{"-L", fmt.Sprintf("%s:%d:%s:%d", BracketHost(f.LocalHost), f.LocalPort, BracketHost(f.RemoteHost), f.RemotePort)}
+
+	case RemoteForward:
+		// -R [bind_address:]port:host:hostport
+		// RemotePort on remote side forwards to LocalHost:LocalPort
+		// Omitting bind address to use server's default (usually 127.0.0.1)
+		// For external access, server must have GatewayPorts enabled
+		localHost := f.LocalHost
+		if localHost == "" || localHost == "0.0.0.0" {
+			// For RemoteForward, we need a valid destination address
+			localHost = "127.0.0.1"
+		}
+		return []string{"-R", fmt.Sprintf("%s%d:%s:%d", f.remoteBindPrefix(), f.RemotePort, BracketHost(localHost), f.LocalPort)}
+
+	case DynamicForward:
+		// -D [bind_address:]port
+		return []string{"-D", fmt.Sprintf("%s:%d", BracketHost(f.LocalHost), f.LocalPort)}
+
+	case ReverseDynamicForward:
+		// -R [bind_address:]port with no destination runs a SOCKS proxy on
+		// the SSH host, like -D in reverse
+		return []string{"-R", f.remoteBindPrefix() + strconv.Itoa(f.RemotePort)}
+	}
+	return nil
+}
+
+// Summary returns a compact description of the forwarded ports
+func (f Forward) Summary() string {
+	return forwardRun{first: f, last: f}.summary()
+}
+
+// localBindPrefix returns the local bind address of a local or dynamic
+// forward followed by a colon, or "" when it listens on DefaultBindAddress
+func (f Forward) localBindPrefix() string {
+	if f.IsRemote() || f.LocalHost == "" || f.LocalHost == DefaultBindAddress {
+		return ""
+	}
+	return BracketHost(f.LocalHost) + ":"
+}
+
+// remoteBindPrefix returns the remote bind address followed by a colon, or ""
+// when the server's default is used
+func (f Forward) remoteBindPrefix() string {
+	if f.RemoteBindAddress == "" {
+		return ""
+	}
+	return BracketHost(f.RemoteBindAddress) + ":"
+}
+
+// NeedsGatewayPorts reports whether the forward asks the SSH server to listen
+// on a non-loopback address, which sshd only allows with GatewayPorts set to
+// yes or clientspecified
+func (f Forward) NeedsGatewayPorts() bool {
+	if !f.IsRemote() {
+		return false
+	}
+	switch f.RemoteBindAddress {
+	case "", "localhost", "127.0.0.1", "::1":
+		return false
+	}
+	return true
+}
+
+// IsRemote reports whether the forward listens on the SSH host rather than locally
+func (f Forward) IsRemote() bool {
+	return f.Type == RemoteForward || f.Type == ReverseDynamicForward
+}
+
+// DialAddress returns the local address the forward listens on, or false for
+// remote forwards, which listen on the SSH host
+func (f Forward) DialAddress() (string, bool) {
+	if f.IsRemote() {
+		return "", false
+	}
+
+	host := f.LocalHost
+	switch host {
+	case "", "0.0.0.0", "*":
+		host = "127.0.0.1"
+	case "::":
+		host = "::1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(f.LocalPort)), true
+}
+
+// primaryForward returns the forward described by the tunnel's own fields.
+// The caller must hold t.mu.
+func (t *Tunnel) primaryForward() Forward {
+	return Forward{
+		Type:       t.Type,
+		LocalHost:  t.LocalHost,
+		LocalPort:  t.LocalPort,
+		RemoteHost: t.RemoteHost,
+		RemotePort: t.RemotePort,
+
+		RemoteBindAddress: t.RemoteBindAddress,
+	}
+}
+
+// NeedsGatewayPorts reports whether any forward of the tunnel asks the SSH
+// server to listen on a non-loopback address
+func (t *Tunnel) NeedsGatewayPorts() bool {
+	for _, f := range t.AllForwards() {
+		if f.NeedsGatewayPorts() {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardsLocked returns the primary forward followed by any additional ones.
+// The caller must hold t.mu.
+func (t *Tunnel) forwardsLocked() []Forward {
+	return append([]Forward{t.primaryForward()}, t.Forwards...)
+}
+
+// AllForwards returns the primary forward followed by any additional ones
+func (t *Tunnel) AllForwards() []Forward {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.forwardsLocked()
+}
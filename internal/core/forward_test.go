@@ -0,0 +1,334 @@
+// Package core provides tests for tunnels with several forwards.
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestParseForwards tests parsing and formatting of additional forwards
+func TestParseForwards(t *testing.T) {
+	forwards, err := ParseForwards("-L 6379:redis:6379  -D 1080 -R 9000:3000")
+	if err != nil {
+		t.Fatalf("Failed to parse forwards: %v", err)
+	}
+
+	expected := []Forward{
+		{Type: LocalForward, LocalHost: DefaultBindAddress, LocalPort: 6379, RemoteHost: "redis", RemotePort: 6379},
+		{Type: DynamicForward, LocalHost: DefaultBindAddress, LocalPort: 1080},
+		{Type: RemoteForward, LocalHost: "127.0.0.1", LocalPort: 3000, RemotePort: 9000},
+	}
+	if !reflect.DeepEqual(forwards, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, forwards)
+	}
+	if got := FormatForwards(forwards); got != "-L 6379:redis:6379 -D 1080 -R 9000:3000" {
+		t.Errorf("Unexpected formatting: %q", got)
+	}
+
+	for _, text := range []string{"-L", "6379:redis:6379", "-X 80"} {
+		if _, err := ParseForwards(text); err == nil {
+			t.Errorf("Expected %q to be rejected", text)
+		}
+	}
+}
+
+// TestParseForwardSpecRanges tests that port ranges and lists expand into one
+// forward per port and are summarized back as ranges
+func TestParseForwardSpecRanges(t *testing.T) {
+	forwards, err := ParseForwardSpec("9000-9002:db", LocalForward)
+	if err != nil {
+		t.Fatalf("Failed to parse range: %v", err)
+	}
+	if len(forwards) != 3 {
+		t.Fatalf("Expected 3 forwards, got %d", len(forwards))
+	}
+	for i, f := range forwards {
+		if f.LocalPort != 9000+i || f.RemotePort != 9000+i || f.RemoteHost != "db" {
+			t.Errorf("Unexpected forward %d: %+v", i, f)
+		}
+	}
+	if got := FormatForwards(forwards); got != "-L 9000-9002:db:9000-9002" {
+		t.Errorf("Unexpected formatting: %q", got)
+	}
+	if got := SummarizeForwards(forwards); !reflect.DeepEqual(got, []string{"L:9000-9002→db:9000-9002"}) {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+
+	tests := []struct {
+		spec       string
+		tunnelType TunnelType
+		expected   string
+	}{
+		{"0.0.0.0:8000-8001:web:80", LocalForward, "-L 0.0.0.0:8000:web:80 -L 0.0.0.0:8001:web:80"},
+		{"8080,8443:web:80,443", LocalForward, "-L 8080:web:80 -L 8443:web:443"},
+		{"9000-9001", RemoteForward, "-R 9000-9001:9000-9001"},
+		{"7000-7001:3000-3001", RemoteForward, "-R 7000-7001:3000-3001"},
+		{"1080,1081,1090", DynamicForward, "-D 1080-1081 -D 1090"},
+		{"2000-2001", ReverseDynamicForward, "-RD 2000-2001"},
+	}
+	for _, tt := range tests {
+		forwards, err := ParseForwardSpec(tt.spec, tt.tunnelType)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", tt.spec, err)
+			continue
+		}
+		if got := FormatForwards(forwards); got != tt.expected {
+			t.Errorf("Expected %q to format as %q, got %q", tt.spec, tt.expected, got)
+		}
+	}
+
+	for _, spec := range []string{"9000-9002:db:80-81", "9005-9000:db:9005", "1-1000:db:1-1000", "x-2:db:80"} {
+		if _, err := ParseForwardSpec(spec, LocalForward); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+
+	parsed, err := ParseForwards("-L 9000-9001:db:9000-9001 -D 1080")
+	if err != nil || len(parsed) != 3 {
+		t.Errorf("Expected ParseForwards to expand ranges, got %+v, %v", parsed, err)
+	}
+}
+
+// TestTunnelWithSeveralForwards tests that additional forwards share the ssh
+// command, are validated, and survive a save and reload
+func TestTunnelWithSeveralForwards(t *testing.T) {
+	tm, _ := newTestManager(t, "")
+
+	tunnel := NewTunnel("stack", LocalForward)
+	tunnel.SSHHost = "app.example.com"
+	tunnel.LocalHost = "127.0.0.1"
+	tunnel.LocalPort = 8080
+	tunnel.RemoteHost = "localhost"
+	tunnel.RemotePort = 80
+	tunnel.Forwards = []Forward{
+		{Type: LocalForward, LocalHost: "127.0.0.1", LocalPort: 5432, RemoteHost: "db", RemotePort: 5432},
+		{Type: DynamicForward, LocalHost: "127.0.0.1", LocalPort: 1080},
+	}
+
+	args := tm.processManager.buildSSHArgs(tunnel)
+	expected := []string{"-L", "127.0.0.1:8080:localhost:80", "-L", "127.0.0.1:5432:db:5432", "-D", "127.0.0.1:1080"}
+	if !reflect.DeepEqual(args[:len(expected)], expected) {
+		t.Errorf("Expected forwards %v, got %v", expected, args)
+	}
+	if got := tunnel.ForwardSummary(); got != "L:8080→localhost:80, L:5432→db:5432, D:1080" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+
+	if err := tm.AddTunnel(tunnel); err != nil {
+		t.Fatalf("Failed to add tunnel: %v", err)
+	}
+	tm.loadTunnels()
+	reloaded, err := tm.GetTunnel(tunnel.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload tunnel: %v", err)
+	}
+	if !reflect.DeepEqual(reloaded.Forwards, tunnel.Forwards) {
+		t.Errorf("Expected forwards %+v after reload, got %+v", tunnel.Forwards, reloaded.Forwards)
+	}
+
+	tunnel.Forwards = append(tunnel.Forwards, Forward{Type: DynamicForward, LocalPort: 8080})
+	if err := tunnel.Validate(); err == nil {
+		t.Error("Expected a forward reusing local port 8080 to be rejected")
+	}
+}
+
+// TestReverseDynamicForward tests the ssh arguments and validation of remote
+// SOCKS proxies
+func TestReverseDynamicForward(t *testing.T) {
+	tunnel := &Tunnel{
+		Name:       "remote-socks",
+		Type:       ReverseDynamicForward,
+		SSHHost:    "example.com",
+		RemotePort: 1080,
+	}
+	if err := tunnel.Validate(); err != nil {
+		t.Fatalf("Expected a valid tunnel, got %v", err)
+	}
+
+	args := NewProcessManager().buildSSHArgs(tunnel)
+	if args[0] != "-R" || args[1] != "1080" {
+		t.Errorf("Expected -R 1080, got %v", args[:2])
+	}
+	if _, ok := tunnel.DialAddress(); ok {
+		t.Error("Expected no local listener")
+	}
+	if got := tunnel.ForwardSummary(); got != "RD:1080" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+
+	forwards, err := ParseForwards("-RD 1081")
+	if err != nil || len(forwards) != 1 || forwards[0].RemotePort != 1081 {
+		t.Errorf("Expected a reverse dynamic forward on 1081, got %+v (%v)", forwards, err)
+	}
+
+	tunnel.RemotePort = 0
+	if err := tunnel.Validate(); err == nil {
+		t.Error("Expected a missing remote port to be rejected")
+	}
+}
+
+// TestRemoteBindAddress tests remote bind addresses in specs, ssh arguments and
+// the GatewayPorts hint
+func TestRemoteBindAddress(t *testing.T) {
+	forwards, err := ParseForwards("-R 0.0.0.0:8080:3000 -RD *:1080 -R 9000:3000")
+	if err != nil {
+		t.Fatalf("Failed to parse forwards: %v", err)
+	}
+
+	expected := []struct {
+		args     []string
+		gateways bool
+	}{
+		{[]string{"-R", "0.0.0.0:8080:127.0.0.1:3000"}, true},
+		{[]string{"-R", "*:1080"}, true},
+		{[]string{"-R", "9000:127.0.0.1:3000"}, false},
+	}
+	for i, want := range expected {
+		if got := forwards[i].Args(); !reflect.DeepEqual(got, want.args) {
+			t.Errorf("Forward %d: expected %v, got %v", i, want.args, got)
+		}
+		if got := forwards[i].NeedsGatewayPorts(); got != want.gateways {
+			t.Errorf("Forward %d: expected NeedsGatewayPorts %v, got %v", i, want.gateways, got)
+		}
+	}
+	if got := FormatForwards(forwards); got != "-R 0.0.0.0:8080:3000 -RD *:1080 -R 9000:3000" {
+		t.Errorf("Unexpected formatting: %q", got)
+	}
+
+	if _, err := ParseForward(":8080:3000", RemoteForward); err == nil {
+		t.Error("Expected an empty bind address to be rejected")
+	}
+
+	tunnel := &Tunnel{Type: RemoteForward, RemotePort: 8080, LocalPort: 3000, RemoteBindAddress: "127.0.0.1"}
+	if tunnel.NeedsGatewayPorts() {
+		t.Error("Expected loopback binds not to need GatewayPorts")
+	}
+}
+
+// TestLocalBindAddress tests local bind addresses in specs and that they are
+// persisted, with legacy configs keeping their wildcard bind
+func TestLocalBindAddress(t *testing.T) {
+	forwards, err := ParseForwards("-L 0.0.0.0:5432:db:5432 -D *:1080 -L 8080:web:80")
+	if err != nil {
+		t.Fatalf("Failed to parse forwards: %v", err)
+	}
+	expected := [][]string{
+		{"-L", "0.0.0.0:5432:db:5432"},
+		{"-D", "*:1080"},
+		{"-L", "127.0.0.1:8080:web:80"},
+	}
+	for i, want := range expected {
+		if got := forwards[i].Args(); !reflect.DeepEqual(got, want) {
+			t.Errorf("Forward %d: expected %v, got %v", i, want, got)
+		}
+	}
+	if got := FormatForwards(forwards); got != "-L 0.0.0.0:5432:db:5432 -D *:1080 -L 8080:web:80" {
+		t.Errorf("Unexpected formatting: %q", got)
+	}
+	if _, err := ParseForward(":1080", DynamicForward); err == nil {
+		t.Error("Expected an empty bind address to be rejected")
+	}
+
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "legacy", "name": "legacy", "host": "a.example.com", "localPort": 8080, "remotePort": 80, "mode": "local"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+	legacy, err := tm.GetTunnel("legacy")
+	if err != nil {
+		t.Fatalf("Failed to get tunnel: %v", err)
+	}
+	if legacy.LocalHost != "0.0.0.0" {
+		t.Errorf("Expected legacy tunnel to bind 0.0.0.0, got %q", legacy.LocalHost)
+	}
+
+	tunnel := NewTunnel("private", DynamicForward)
+	tunnel.SSHHost = "proxy.example.com"
+	tunnel.LocalPort = 1080
+	if tunnel.LocalHost != DefaultBindAddress {
+		t.Errorf("Expected new tunnels to bind %s, got %q", DefaultBindAddress, tunnel.LocalHost)
+	}
+	tunnel.LocalHost = "10.0.0.5"
+	if err := tm.AddTunnel(tunnel); err != nil {
+		t.Fatalf("Failed to add tunnel: %v", err)
+	}
+	if got := tunnel.ForwardSummary(); got != "D:10.0.0.5:1080" {
+		t.Errorf("Unexpected summary: %q", got)
+	}
+
+	reloaded := NewTunnelManager(tm.configStore, tm.pidStore)
+	saved, err := reloaded.GetTunnel(tunnel.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload tunnel: %v", err)
+	}
+	if saved.LocalHost != "10.0.0.5" {
+		t.Errorf("Expected bind address to be persisted, got %q", saved.LocalHost)
+	}
+
+	tunnel.LocalHost = "10.0.0.5 10.0.0.6"
+	if err := tunnel.Validate(); err == nil {
+		t.Error("Expected a bind address with spaces to be rejected")
+	}
+}
+
+// TestIPv6Forwards tests that bracketed IPv6 addresses are parsed, validated
+// and passed to ssh in brackets
+func TestIPv6Forwards(t *testing.T) {
+	tests := []struct {
+		spec       string
+		tunnelType TunnelType
+		expected   Forward
+		args       string
+	}{
+		{
+			"[::1]:8080:[fd00::5]:5432", LocalForward,
+			Forward{Type: LocalForward, LocalHost: "::1", LocalPort: 8080, RemoteHost: "fd00::5", RemotePort: 5432},
+			"[::1]:8080:[fd00::5]:5432",
+		},
+		{
+			"8080:[fd00::5]:5432", LocalForward,
+			Forward{Type: LocalForward, LocalHost: DefaultBindAddress, LocalPort: 8080, RemoteHost: "fd00::5", RemotePort: 5432},
+			"127.0.0.1:8080:[fd00::5]:5432",
+		},
+		{
+			"[::]:9000:3000", RemoteForward,
+			Forward{Type: RemoteForward, LocalHost: "127.0.0.1", LocalPort: 3000, RemotePort: 9000, RemoteBindAddress: "::"},
+			"[::]:9000:127.0.0.1:3000",
+		},
+		{
+			"[::1]:1080", DynamicForward,
+			Forward{Type: DynamicForward, LocalHost: "::1", LocalPort: 1080},
+			"[::1]:1080",
+		},
+	}
+	for _, tt := range tests {
+		forward, err := ParseForward(tt.spec, tt.tunnelType)
+		if err != nil {
+			t.Errorf("Failed to parse %q: %v", tt.spec, err)
+			continue
+		}
+		if forward != tt.expected {
+			t.Errorf("Expected %q to parse as %+v, got %+v", tt.spec, tt.expected, forward)
+		}
+		if err := forward.Validate(); err != nil {
+			t.Errorf("Expected %q to be valid: %v", tt.spec, err)
+		}
+		if args := forward.Args(); args[1] != tt.args {
+			t.Errorf("Expected %q to pass %q to ssh, got %q", tt.spec, tt.args, args[1])
+		}
+		if again, err := ParseForwards(FormatForwards([]Forward{forward})); err != nil || again[0] != forward {
+			t.Errorf("Expected %q to survive formatting, got %+v, %v", tt.spec, again, err)
+		}
+	}
+
+	for _, spec := range []string{"[::1:8080:db:80", "::1:8080:db:80", "8080:[db]x:80"} {
+		if f, err := ParseForward(spec, LocalForward); err == nil && f.Validate() == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+	if err := (Forward{Type: LocalForward, LocalHost: "fd00:zz", LocalPort: 80, RemoteHost: "db", RemotePort: 80}).Validate(); err == nil {
+		t.Error("Expected an invalid IPv6 bind address to be rejected")
+	}
+}
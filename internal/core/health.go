@@ -0,0 +1,235 @@
+// Package core provides health checks that probe forwarded ports.
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TunnelHealth is the result of probing a running tunnel's forward
+type TunnelHealth string
+
+const (
+	// HealthUnknown indicates the tunnel has not been probed, or is not running
+	HealthUnknown TunnelHealth = ""
+	// HealthHealthy indicates the forward accepted the last probe
+	HealthHealthy TunnelHealth = "healthy"
+	// HealthDegraded indicates the forward failed recent probes
+	HealthDegraded TunnelHealth = "degraded"
+	// HealthUnhealthy indicates the forward has stopped accepting connections
+	HealthUnhealthy TunnelHealth = "unhealthy"
+)
+
+// errProbeUnsupported is returned when a forward cannot be probed, e.g. when
+// nc is missing on the SSH host of a remote forward
+var errProbeUnsupported = errors.New("probe not supported")
+
+// HealthChecker periodically probes the forwards of running tunnels
+type HealthChecker struct {
+	manager *TunnelManager
+
+	interval       time.Duration
+	timeout        time.Duration
+	unhealthyAfter int
+	onChange       func(tunnelID string, health TunnelHealth)
+
+	// Consecutive probe failures per tunnel ID
+	failures map[string]int
+}
+
+// HealthCheckerOption is a functional option for HealthChecker
+type HealthCheckerOption func(*HealthChecker)
+
+// WithHealthInterval sets the delay between probe rounds
+func WithHealthInterval(d time.Duration) HealthCheckerOption {
+	return func(h *HealthChecker) {
+		h.interval = d
+	}
+}
+
+// WithHealthTimeout sets how long a single probe may take
+func WithHealthTimeout(d time.Duration) HealthCheckerOption {
+	return func(h *HealthChecker) {
+		h.timeout = d
+	}
+}
+
+// WithUnhealthyAfter sets how many consecutive failed probes mark a tunnel
+// unhealthy; fewer failures mark it degraded
+func WithUnhealthyAfter(n int) HealthCheckerOption {
+	return func(h *HealthChecker) {
+		h.unhealthyAfter = n
+	}
+}
+
+// WithHealthChangeFunc sets a function called whenever a tunnel's health changes
+func WithHealthChangeFunc(fn func(tunnelID string, health TunnelHealth)) HealthCheckerOption {
+	return func(h *HealthChecker) {
+		h.onChange = fn
+	}
+}
+
+// NewHealthChecker creates a health checker for the manager's tunnels
+func NewHealthChecker(manager *TunnelManager, opts ...HealthCheckerOption) *HealthChecker {
+	h := &HealthChecker{
+		manager:        manager,
+		interval:       30 * time.Second,
+		timeout:        5 * time.Second,
+		unhealthyAfter: 3,
+		failures:       make(map[string]int),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Run probes running tunnels every interval until ctx is cancelled
+func (h *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.Check(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check probes every running tunnel once, concurrently, and records the results
+func (h *HealthChecker) Check(ctx context.Context) {
+	type result struct {
+		id  string
+		err error
+	}
+
+	var running []*Tunnel
+	for _, t := range h.manager.effectiveTunnels() {
+		if t.Status == StatusRunning {
+			running = append(running, t)
+			continue
+		}
+		delete(h.failures, t.ID)
+		h.record(t.ID, HealthUnknown, nil)
+	}
+
+	// Remote forwards of simulated tunnels have nothing to probe over ssh
+	sshPath := h.manager.processManager.SSHPath()
+	if _, simulated := h.manager.processManager.(*MockProcessManager); simulated {
+		sshPath = ""
+	}
+	results := make(chan result, len(running))
+	var wg sync.WaitGroup
+	for _, t := range running {
+		wg.Add(1)
+		go func(t *Tunnel) {
+			defer wg.Done()
+			probeCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+			results <- result{id: t.ID, err: probeTunnel(probeCtx, sshPath, t)}
+		}(t)
+	}
+	wg.Wait()
+	close(results)
+
+	for r := range results {
+		switch {
+		case errors.Is(r.err, errProbeUnsupported):
+			h.record(r.id, HealthUnknown, r.err)
+		case r.err == nil:
+			delete(h.failures, r.id)
+			h.record(r.id, HealthHealthy, nil)
+		default:
+			h.failures[r.id]++
+			health := HealthDegraded
+			if h.failures[r.id] >= h.unhealthyAfter {
+				health = HealthUnhealthy
+			}
+			h.record(r.id, health, r.err)
+		}
+	}
+}
+
+// record stores a probe result and reports a change to onChange
+func (h *HealthChecker) record(id string, health TunnelHealth, err error) {
+	if h.manager.setHealth(id, health, err) && h.onChange != nil {
+		h.onChange(id, health)
+	}
+}
+
+// probeTunnel checks that every forward of a tunnel accepts connections. It
+// only reports errProbeUnsupported if no forward failed.
+func probeTunnel(ctx context.Context, sshPath string, t *Tunnel) error {
+	var unsupported error
+	for _, f := range t.AllForwards() {
+		err := probeForward(ctx, sshPath, t, f)
+		if errors.Is(err, errProbeUnsupported) {
+			unsupported = err
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return unsupported
+}
+
+// probeForward checks that a forward accepts connections. Local and dynamic
+// forwards are dialed locally; remote forwards are dialed from the SSH host
+// with nc over a separate SSH session.
+func probeForward(ctx context.Context, sshPath string, t *Tunnel, f Forward) error {
+	if address, hasListener := f.DialAddress(); hasListener {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	if sshPath == "" {
+		return fmt.Errorf("no ssh to probe remote port %d: %w", f.RemotePort, errProbeUnsupported)
+	}
+
+	timeout := 5
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = max(1, int(time.Until(deadline).Seconds()))
+	}
+	args := []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=" + strconv.Itoa(timeout)}
+	if t.JumpHost != "" {
+		args = append(args, "-J", t.JumpHost)
+	}
+	args = append(args, t.ExtraArgs...)
+	host := "127.0.0.1"
+	if f.NeedsGatewayPorts() && f.RemoteBindAddress != "*" && f.RemoteBindAddress != "0.0.0.0" {
+		host = f.RemoteBindAddress
+	}
+	args = append(args, t.SSHHost, "nc", "-z", host, strconv.Itoa(f.RemotePort))
+
+	err := exec.CommandContext(ctx, sshPath, args...).Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		switch exitErr.ExitCode() {
+		case 127:
+			return fmt.Errorf("nc not found on %s: %w", t.SSHHost, errProbeUnsupported)
+		case 255:
+			return fmt.Errorf("ssh to %s failed", t.SSHHost)
+		default:
+			return fmt.Errorf("remote port %d is not accepting connections", f.RemotePort)
+		}
+	}
+	return err
+}
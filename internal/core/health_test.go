@@ -0,0 +1,74 @@
+// Package core provides health checker tests.
+package core
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// TestHealthCheckerMarksClosedForwardUnhealthy tests that a running tunnel whose
+// local port stops accepting connections becomes degraded, then unhealthy
+func TestHealthCheckerMarksClosedForwardUnhealthy(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tm, _ := newTestManager(t, "")
+	tunnel := &Tunnel{
+		ID:         "probe",
+		Name:       "probe",
+		Type:       LocalForward,
+		LocalHost:  "127.0.0.1",
+		LocalPort:  port,
+		RemoteHost: "localhost",
+		RemotePort: 80,
+		SSHHost:    "example.com",
+		Status:     StatusRunning,
+	}
+	tm.tunnels[tunnel.ID] = tunnel
+
+	var changes []TunnelHealth
+	checker := NewHealthChecker(tm,
+		WithHealthTimeout(time.Second),
+		WithUnhealthyAfter(2),
+		WithHealthChangeFunc(func(id string, health TunnelHealth) {
+			changes = append(changes, health)
+		}))
+
+	expectHealth := func(want TunnelHealth) {
+		t.Helper()
+		checker.Check(context.Background())
+		got, err := tm.GetTunnel(tunnel.ID)
+		if err != nil {
+			t.Fatalf("Failed to get tunnel: %v", err)
+		}
+		if got.Health != want {
+			t.Errorf("Expected health %q, got %q (probe error: %v)", want, got.Health, got.HealthError)
+		}
+	}
+
+	expectHealth(HealthHealthy)
+	listener.Close()
+	expectHealth(HealthDegraded)
+	expectHealth(HealthUnhealthy)
+	expectHealth(HealthUnhealthy)
+
+	tm.mu.Lock()
+	tunnel.Status = StatusStopped
+	tm.mu.Unlock()
+	expectHealth(HealthUnknown)
+
+	want := []TunnelHealth{HealthHealthy, HealthDegraded, HealthUnhealthy, HealthUnknown}
+	if len(changes) != len(want) {
+		t.Fatalf("Expected changes %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Change %d: expected %q, got %q", i, want[i], changes[i])
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Package core provides undoing config changes from the config history.
+package core
+
+import (
+	"fmt"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// UndoConfigChange restores the config before the last change from the
+// history and reloads it. Running tunnels the restored config lacks keep
+// running until stopped.
+func (tm *TunnelManager) UndoConfigChange() (*store.Snapshot, error) {
+	snapshot, err := tm.configStore.Undo()
+	if err != nil {
+		return nil, err
+	}
+	if err := tm.ReloadConfig(); err != nil {
+		return nil, fmt.Errorf("restored the config but failed to reload it: %w", err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,105 @@
+// Package core provides config history tests.
+package core
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestUndoConfigChange tests that saves are kept in the history and undone
+// one after the other, and that a rollback can itself be undone
+func TestUndoConfigChange(t *testing.T) {
+	configJSON := `{
+  "version": "2.0",
+  "tunnels": [
+    {"id": "web", "name": "Web", "host": "web.example.com", "localPort": 8080, "remotePort": 80, "mode": "local"}
+  ]
+}`
+	tm, configStore := newTestManager(t, configJSON)
+
+	added := NewTunnel("db", LocalForward)
+	added.SSHHost = "db.example.com"
+	added.LocalPort = 5432
+	added.RemotePort = 5432
+	if err := tm.AddTunnel(added); err != nil {
+		t.Fatalf("AddTunnel failed: %v", err)
+	}
+	web, _ := tm.GetTunnel("web")
+	update := web.Clone()
+	update.LocalPort = 8081
+	if err := tm.UpdateTunnel(update); err != nil {
+		t.Fatalf("UpdateTunnel failed: %v", err)
+	}
+
+	// The loaded config, the added tunnel and the update, newest first
+	history, err := configStore.History()
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+	if len(history) != 3 || history[0].Tunnels != 2 || history[2].Tunnels != 1 {
+		t.Fatalf("Expected 3 snapshots with 2, 2 and 1 tunnels, got %+v", history)
+	}
+
+	// Saving an unchanged config adds no snapshot
+	if err := tm.UpdateTunnel(update); err != nil {
+		t.Fatalf("UpdateTunnel failed: %v", err)
+	}
+	if history, _ := configStore.History(); len(history) != 3 {
+		t.Errorf("Expected an unchanged save to add no snapshot, got %d", len(history))
+	}
+
+	// Rolling back to the loaded config can itself be undone
+	if err := configStore.Rollback(history[2].ID); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if err := tm.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if len(tm.GetTunnels()) != 1 {
+		t.Fatalf("Expected the rollback to restore 1 tunnel, got %d", len(tm.GetTunnels()))
+	}
+	if err := configStore.Rollback("20000101T000000.000000000Z"); err == nil {
+		t.Error("Expected rolling back to an unknown snapshot to fail")
+	}
+	if _, err := tm.UndoConfigChange(); err != nil {
+		t.Fatalf("UndoConfigChange failed: %v", err)
+	}
+	if web, _ := tm.GetTunnel("web"); web.LocalPort != 8081 || len(tm.GetTunnels()) != 2 {
+		t.Errorf("Expected the rollback to be undone, got port %d and %d tunnels", web.LocalPort, len(tm.GetTunnels()))
+	}
+
+	// Undos go back one save at a time
+	if _, err := tm.UndoConfigChange(); err != nil {
+		t.Fatalf("UndoConfigChange failed: %v", err)
+	}
+	if web, _ := tm.GetTunnel("web"); web.LocalPort != 8080 {
+		t.Errorf("Expected the update to be undone, got port %d", web.LocalPort)
+	}
+	if _, err := tm.UndoConfigChange(); err != nil {
+		t.Fatalf("UndoConfigChange failed: %v", err)
+	}
+	if _, err := tm.GetTunnel(added.ID); err == nil {
+		t.Error("Expected the added tunnel to be undone")
+	}
+	if _, err := tm.UndoConfigChange(); !errors.Is(err, store.ErrNoHistory) {
+		t.Errorf("Expected nothing left to undo, got %v", err)
+	}
+
+	// A config edited by hand is restored to the last save
+	configPath, _ := configStore.GetConfigPath()
+	if err := os.WriteFile(configPath, []byte(`{"version": "2.0", "tunnels": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if _, err := tm.UndoConfigChange(); err != nil {
+		t.Fatalf("UndoConfigChange failed: %v", err)
+	}
+	if _, err := tm.GetTunnel("web"); err != nil {
+		t.Errorf("Expected the hand edit to be undone: %v", err)
+	}
+}
@@ -0,0 +1,215 @@
+// Package core provides hook commands run on tunnel lifecycle events.
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// HookTimeout is how long a hook may run before it is killed
+const HookTimeout = 30 * time.Second
+
+// HookEvent is a tunnel lifecycle event that runs hooks
+type HookEvent string
+
+const (
+	// HookConnect runs after a tunnel's ssh process has started
+	HookConnect HookEvent = "connect"
+	// HookDisconnect runs after a tunnel stops, whether stopped or dropped
+	HookDisconnect HookEvent = "disconnect"
+	// HookFailure runs after a tunnel fails to start or its ssh fails
+	HookFailure HookEvent = "failure"
+)
+
+// Hooks holds shell commands run on tunnel lifecycle events. Empty commands
+// are skipped.
+type Hooks struct {
+	OnConnect    string `json:"on_connect,omitempty"`
+	OnDisconnect string `json:"on_disconnect,omitempty"`
+	OnFailure    string `json:"on_failure,omitempty"`
+}
+
+// Command returns the command run on event
+func (h Hooks) Command(event HookEvent) string {
+	switch event {
+	case HookConnect:
+		return h.OnConnect
+	case HookDisconnect:
+		return h.OnDisconnect
+	case HookFailure:
+		return h.OnFailure
+	}
+	return ""
+}
+
+// IsZero reports whether no hooks are set
+func (h Hooks) IsZero() bool {
+	return h == Hooks{}
+}
+
+// hooksFromConfig converts stored hooks
+func hooksFromConfig(hc *store.HookConfig) Hooks {
+	if hc == nil {
+		return Hooks{}
+	}
+	return Hooks{OnConnect: hc.OnConnect, OnDisconnect: hc.OnDisconnect, OnFailure: hc.OnFailure}
+}
+
+// config converts hooks for storage, returning nil if none are set
+func (h Hooks) config() *store.HookConfig {
+	if h.IsZero() {
+		return nil
+	}
+	return &store.HookConfig{OnConnect: h.OnConnect, OnDisconnect: h.OnDisconnect, OnFailure: h.OnFailure}
+}
+
+// hookEnv returns the environment variables describing a tunnel to its hooks.
+// The caller must hold t.mu or tm.mu.
+func hookEnv(t *Tunnel, event HookEvent, cause error) []string {
+	env := []string{
+		"TUNNELMAN_EVENT=" + string(event),
+		"TUNNELMAN_TUNNEL_ID=" + t.ID,
+		"TUNNELMAN_TUNNEL_NAME=" + t.Name,
+		"TUNNELMAN_TUNNEL_TYPE=" + string(t.Type),
+		"TUNNELMAN_PROFILE=" + t.Profile,
+		"TUNNELMAN_SSH_HOST=" + t.SSHHost,
+		"TUNNELMAN_JUMP_HOST=" + t.JumpHost,
+		"TUNNELMAN_LOCAL_HOST=" + t.LocalHost,
+		"TUNNELMAN_LOCAL_PORT=" + strconv.Itoa(t.LocalPort),
+		"TUNNELMAN_REMOTE_HOST=" + t.RemoteHost,
+		"TUNNELMAN_REMOTE_PORT=" + strconv.Itoa(t.RemotePort),
+		"TUNNELMAN_FORWARDS=" + FormatForwards(t.forwardsLocked()),
+	}
+	if t.PID > 0 {
+		env = append(env, "TUNNELMAN_PID="+strconv.Itoa(t.PID))
+	}
+	if cause != nil {
+		env = append(env,
+			"TUNNELMAN_ERROR="+cause.Error(),
+			"TUNNELMAN_ERROR_KIND="+string(FailureKindOf(cause)))
+	}
+	return env
+}
+
+// runHooks starts the global and then the tunnel's hooks for event in the
+// background, and posts it to the webhooks. The caller must hold tm.mu.
+func (tm *TunnelManager) runHooks(tunnel *Tunnel, event HookEvent, cause error) {
+	tm.sendWebhooks(tunnel, event, cause)
+
+	var commands []string
+	if tm.defaults != nil {
+		if command := hooksFromConfig(tm.defaults.Hooks).Command(event); command != "" {
+			commands = append(commands, command)
+		}
+	}
+	if command := tunnel.Hooks.Command(event); command != "" {
+		commands = append(commands, command)
+	}
+	if len(commands) == 0 {
+		return
+	}
+
+	env := hookEnv(tunnel, event, cause)
+	name := tunnel.Name
+	logPath := tm.hookLogPath(tunnel.ID)
+
+	tm.hooks.Add(1)
+	go func() {
+		defer tm.hooks.Done()
+		// Hooks run one at a time, global hooks before the tunnel's own
+		for _, command := range commands {
+			if err := runHook(command, env, logPath); err != nil {
+				Warn("Tunnel '%s': %s hook failed: %v", name, event, err)
+			}
+		}
+	}()
+}
+
+// runHook runs a hook command through the shell, appending its output to logPath
+func runHook(command string, env []string, logPath string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), HookTimeout)
+	defer cancel()
+
+	cmd := hookCommand(ctx, command)
+	cmd.Env = append(os.Environ(), env...)
+
+	// Run the hook in its own process group so a timeout kills what it started
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd.Process.Pid)
+	}
+
+	if logPath != "" {
+		logFile, err := openTunnelLog(logPath)
+		if err != nil {
+			return fmt.Errorf("failed to open hook log: %w", err)
+		}
+		defer logFile.Close()
+		cmd.Stdout = logFile
+		cmd.Stderr = logFile
+	}
+
+	writeLogMarker(logPath, "running hook: %s", command)
+	Debug("Running hook: %s", command)
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s", HookTimeout)
+		}
+		writeLogMarker(logPath, "hook failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// hookCommand returns the shell invocation of a hook command
+func hookCommand(ctx context.Context, command string) *exec.Cmd {
+	if IsWindows() {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "/bin/sh", "-c", command)
+}
+
+// hookLogPath returns the path of the log of a tunnel's hooks, or "" if
+// logging to files is disabled
+func (tm *TunnelManager) hookLogPath(id string) string {
+	path := tm.processManager.LogPath(id)
+	if path == "" {
+		return ""
+	}
+	return strings.TrimSuffix(path, ".log") + ".hooks.log"
+}
+
+// HookLogPath returns the path of the log of a tunnel's hooks
+func (tm *TunnelManager) HookLogPath(id string) (string, error) {
+	if _, err := tm.GetTunnel(id); err != nil {
+		return "", err
+	}
+	path := tm.hookLogPath(id)
+	if path == "" {
+		return "", fmt.Errorf("output logging is disabled")
+	}
+	return path, nil
+}
+
+// WaitHooks waits until running hooks finish or ctx is done
+func (tm *TunnelManager) WaitHooks(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		tm.hooks.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,120 @@
+// Package core provides lifecycle hook tests.
+package core
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// waitForTestHooks waits for hooks started by tm to finish
+func waitForTestHooks(t *testing.T, tm *TunnelManager) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tm.WaitHooks(ctx); err != nil {
+		t.Fatalf("Hooks did not finish: %v", err)
+	}
+}
+
+// TestFailureHookRuns tests that global and tunnel hooks run with the tunnel's
+// environment when a tunnel fails to start
+func TestFailureHookRuns(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	out := filepath.Join(t.TempDir(), "hooks.out")
+	tm, _ := newTestManager(t, "")
+	tm.defaults = &store.Defaults{Hooks: &store.HookConfig{
+		OnFailure: `echo "global $TUNNELMAN_EVENT" >> ` + out,
+	}}
+	tm.tunnels["web"] = &Tunnel{
+		ID:         "web",
+		Name:       "web",
+		Type:       LocalForward,
+		LocalHost:  "127.0.0.1",
+		LocalPort:  port,
+		RemoteHost: "localhost",
+		RemotePort: 80,
+		SSHHost:    "example.com",
+		Status:     StatusStopped,
+		Hooks: Hooks{
+			OnConnect: `echo connect >> ` + out,
+			OnFailure: `echo "$TUNNELMAN_TUNNEL_NAME $TUNNELMAN_SSH_HOST $TUNNELMAN_LOCAL_PORT $TUNNELMAN_ERROR_KIND" >> ` + out,
+		},
+	}
+
+	if err := tm.StartTunnel("web"); err == nil {
+		t.Fatal("Expected the start to fail")
+	}
+	waitForTestHooks(t, tm)
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Hooks did not run: %v", err)
+	}
+	want := "global failure\nweb example.com " + strconv.Itoa(port) + " address-in-use\n"
+	if string(data) != want {
+		t.Errorf("Expected hook output %q, got %q", want, data)
+	}
+
+	logPath, err := tm.HookLogPath("web")
+	if err != nil {
+		t.Fatalf("HookLogPath: %v", err)
+	}
+	if log, _ := os.ReadFile(logPath); !strings.Contains(string(log), "tunnelman: running hook:") {
+		t.Errorf("Expected the hook log to record the hooks, got %q", log)
+	}
+}
+
+// TestHookFailureIsLogged tests that a failing hook is recorded in the hook log
+func TestHookFailureIsLogged(t *testing.T) {
+	tm, _ := newTestManager(t, "")
+	tunnel := &Tunnel{
+		ID:      "db",
+		Name:    "db",
+		Type:    DynamicForward,
+		SSHHost: "example.com",
+		Hooks:   Hooks{OnDisconnect: "echo unmounting; exit 3"},
+	}
+	tm.tunnels[tunnel.ID] = tunnel
+
+	tm.mu.Lock()
+	tm.runHooks(tunnel, HookDisconnect, nil)
+	tm.runHooks(tunnel, HookConnect, nil) // no hook set
+	tm.mu.Unlock()
+	waitForTestHooks(t, tm)
+
+	log, err := os.ReadFile(tm.hookLogPath("db"))
+	if err != nil {
+		t.Fatalf("Failed to read hook log: %v", err)
+	}
+	for _, want := range []string{"unmounting", "hook failed: exit status 3"} {
+		if !strings.Contains(string(log), want) {
+			t.Errorf("Expected hook log to contain %q, got %q", want, log)
+		}
+	}
+}
+
+// TestHooksConfigRoundTrip tests that hooks survive saving and loading
+func TestHooksConfigRoundTrip(t *testing.T) {
+	hooks := Hooks{OnConnect: "kubectl config use-context dev"}
+	tunnel := tunnelFromConfig(configFromTunnel(&Tunnel{ID: "a", Name: "a", Type: DynamicForward, Hooks: hooks}))
+	if tunnel.Hooks != hooks {
+		t.Errorf("Expected %+v, got %+v", hooks, tunnel.Hooks)
+	}
+	if config := configFromTunnel(&Tunnel{ID: "b", Name: "b"}); config.Hooks != nil {
+		t.Errorf("Expected no stored hooks, got %+v", config.Hooks)
+	}
+}
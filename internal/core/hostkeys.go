@@ -0,0 +1,293 @@
+// Package core provides host key verification for tunnels.
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// WithHostKeyDir sets the directory holding the known_hosts files of tunnels
+// with pinned host keys
+func WithHostKeyDir(dir string) ProcessManagerOption {
+	return func(pm *ProcessManager) {
+		pm.hostKeyDir = dir
+	}
+}
+
+// hostKeyArgs returns the ssh options verifying the host's key. A tunnel with
+// a pinned key accepts that key only. Others are asked about new keys when
+// prompts are enabled, and accept them otherwise, as nobody could be asked.
+func (pm *ProcessManager) hostKeyArgs(tunnel *Tunnel) []string {
+	if path := pm.pinnedHostsPath(tunnel.ID); tunnel.HostKey != "" && path != "" {
+		return []string{
+			"-o", "StrictHostKeyChecking=yes",
+			"-o", "UserKnownHostsFile=" + path,
+		}
+	}
+
+	pm.mu.RLock()
+	prompts := pm.askpass != nil
+	policy := pm.defaults.StrictHostKeyChecking
+	pm.mu.RUnlock()
+
+	switch {
+	case policy == "ask" && !prompts:
+		// Nothing can answer ssh's question, so unknown hosts are refused
+		policy = "yes"
+	case policy == "" && prompts:
+		policy = "ask"
+	case policy == "":
+		policy = "accept-new" // Auto-accept new host keys
+	}
+	return []string{"-o", "StrictHostKeyChecking=" + policy}
+}
+
+// pinnedHostsPath returns the known_hosts file holding a tunnel's pinned host
+// key, or "" if pinning is not available
+func (pm *ProcessManager) pinnedHostsPath(id string) string {
+	if pm.hostKeyDir == "" {
+		return ""
+	}
+	return filepath.Join(pm.hostKeyDir, id)
+}
+
+// preparePinnedHostKey writes the known_hosts file of a tunnel with a pinned
+// host key, copying the entries of that key from the user's known_hosts
+func (pm *ProcessManager) preparePinnedHostKey(tunnel *Tunnel) error {
+	path := pm.pinnedHostsPath(tunnel.ID)
+	if tunnel.HostKey == "" || path == "" {
+		return nil
+	}
+	if entries, _ := knownHostsEntries(path, tunnel.HostKey); len(entries) > 0 {
+		return nil
+	}
+
+	var entries []string
+	for _, file := range userKnownHostsFiles() {
+		found, _ := knownHostsEntries(file, tunnel.HostKey)
+		entries = append(entries, found...)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("pinned host key %s is not in ~/.ssh/known_hosts; unpin it, connect and accept the key first", tunnel.HostKey)
+	}
+
+	if err := os.MkdirAll(pm.hostKeyDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create host key directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(entries, "\n")+"\n"), 0o600); err != nil {
+		return fmt.Errorf("failed to write pinned host key: %w", err)
+	}
+	return nil
+}
+
+// userKnownHostsFiles returns ssh's default known_hosts files
+func userKnownHostsFiles() []string {
+	return []string{ExpandHome("~/.ssh/known_hosts"), ExpandHome("~/.ssh/known_hosts2")}
+}
+
+// knownHostsEntries returns the lines of a known_hosts file holding the key
+// with the given fingerprint
+func knownHostsEntries(path, fingerprint string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if knownHostsFingerprint(line) == fingerprint {
+			entries = append(entries, line)
+		}
+	}
+	return entries, nil
+}
+
+// knownHostsFingerprint returns the fingerprint of the key on a known_hosts
+// line, or "" for comments, markers and malformed lines
+func knownHostsFingerprint(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "@") {
+		return ""
+	}
+	identity, ok := parsePublicKey([]byte(fields[1] + " " + fields[2]))
+	if !ok {
+		return ""
+	}
+	return identity.Fingerprint
+}
+
+// removeKnownHostsLines rewrites a known_hosts file without the lines drop
+// selects, keeping the previous file as path.old like ssh-keygen -R does
+func removeKnownHostsLines(path string, drop func(number int, line string) bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(string(data), "\n")
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if !drop(i+1, strings.TrimRight(line, "\r\n")) {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == len(lines) {
+		return nil
+	}
+
+	if err := os.WriteFile(path+".old", data, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	return os.WriteFile(path, []byte(strings.Join(kept, "")), info.Mode().Perm())
+}
+
+// HostKeyPrompt is ssh's question whether to trust a host it has no key for
+type HostKeyPrompt struct {
+	// Host is the host as ssh names it, e.g. "db.example.com (10.0.0.5)"
+	Host        string
+	KeyType     string
+	Fingerprint string
+}
+
+var (
+	// hostKeyPromptPattern matches ssh's question about an unknown host key
+	hostKeyPromptPattern = regexp.MustCompile(`(?s)authenticity of host '([^']+)' can't be established.*?(\S+) key fingerprint is (SHA256:[A-Za-z0-9+/=]+)`)
+
+	// Lines ssh prints when refusing a host key
+	hostKeyChangedPattern   = regexp.MustCompile(`Host key for (\S+) has changed`)
+	hostKeyUnknownPattern   = regexp.MustCompile(`No \S+ host key is known for (\S+) and you have requested strict checking`)
+	hostKeySentPattern      = regexp.MustCompile(`^(SHA256:[A-Za-z0-9+/=]+)\.?$`)
+	hostKeyOffendingPattern = regexp.MustCompile(`Offending \S+ key in (.+):(\d+)$`)
+)
+
+// ParseHostKeyPrompt recognizes ssh's question about an unknown host key
+func ParseHostKeyPrompt(message string) (HostKeyPrompt, bool) {
+	match := hostKeyPromptPattern.FindStringSubmatch(message)
+	if match == nil {
+		return HostKeyPrompt{}, false
+	}
+	return HostKeyPrompt{Host: match[1], KeyType: match[2], Fingerprint: match[3]}, true
+}
+
+// HostKeyProblem describes a host key ssh refused to connect with
+type HostKeyProblem struct {
+	// Host is the host as ssh names it
+	Host string
+	// Changed is true if the host sent a different key than known_hosts has
+	// for it; otherwise it sent a key other than the tunnel's pinned one
+	Changed bool
+	// Fingerprint is the key the host sent, if ssh printed it
+	Fingerprint string
+	// KnownHostsFile and Line locate the entry ssh checked the key against
+	KnownHostsFile string
+	Line           int
+}
+
+// HostKeyProblem returns the host key the tunnel's ssh last refused, or nil if
+// it did not refuse one
+func (tm *TunnelManager) HostKeyProblem(id string) *HostKeyProblem {
+	return parseHostKeyProblem(lastRunOutput(tm.processManager.RecentOutput(id)))
+}
+
+// parseHostKeyProblem reads a refused host key from ssh's output
+func parseHostKeyProblem(output []string) *HostKeyProblem {
+	var problem HostKeyProblem
+	refused := false
+	for _, line := range output {
+		line = sshMessage(line)
+		if match := hostKeyChangedPattern.FindStringSubmatch(line); match != nil {
+			problem.Host, problem.Changed, refused = match[1], true, true
+		} else if match := hostKeyUnknownPattern.FindStringSubmatch(line); match != nil {
+			problem.Host, refused = match[1], true
+		} else if match := hostKeySentPattern.FindStringSubmatch(line); match != nil {
+			problem.Fingerprint = match[1]
+		} else if match := hostKeyOffendingPattern.FindStringSubmatch(line); match != nil {
+			problem.KnownHostsFile = match[1]
+			problem.Line, _ = strconv.Atoi(match[2])
+		}
+	}
+	if !refused {
+		return nil
+	}
+	return &problem
+}
+
+// PinHostKey makes a tunnel accept only the host key with the given SHA256
+// fingerprint, which must be in ~/.ssh/known_hosts by the time the tunnel
+// next starts. An empty fingerprint unpins the key.
+func (tm *TunnelManager) PinHostKey(id, fingerprint string) error {
+	if fingerprint != "" && !strings.HasPrefix(fingerprint, "SHA256:") {
+		return fmt.Errorf("invalid host key: %q (expected a SHA256:... fingerprint)", fingerprint)
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tunnel, exists := tm.tunnels[id]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTunnelNotFound, id)
+	}
+
+	previous := tunnel.HostKey
+	tunnel.HostKey = fingerprint
+	if err := tm.saveTunnels(); err != nil {
+		tunnel.HostKey = previous
+		return fmt.Errorf("failed to save tunnel: %w", err)
+	}
+	return nil
+}
+
+// ForgetHostKey removes the known host key ssh last refused for a tunnel, so
+// that the host's current key is asked about when the tunnel next starts. A
+// pinned key is unpinned, and its entries are removed from known_hosts too.
+func (tm *TunnelManager) ForgetHostKey(id string) error {
+	tunnel, err := tm.GetTunnel(id)
+	if err != nil {
+		return err
+	}
+	problem := tm.HostKeyProblem(id)
+	pinnedPath := tm.processManager.pinnedHostsPath(id)
+
+	// Entries copied into the tunnel's pinned known_hosts are removed from the
+	// user's file as well, or ssh would refuse the new key next time
+	pinned := map[string]bool{}
+	if tunnel.HostKey != "" && pinnedPath != "" {
+		entries, _ := knownHostsEntries(pinnedPath, tunnel.HostKey)
+		for _, entry := range entries {
+			pinned[entry] = true
+		}
+	}
+
+	files := userKnownHostsFiles()
+	if problem != nil && problem.KnownHostsFile != "" && problem.KnownHostsFile != pinnedPath &&
+		problem.KnownHostsFile != files[0] && problem.KnownHostsFile != files[1] {
+		files = append(files, problem.KnownHostsFile)
+	}
+	for _, file := range files {
+		err := removeKnownHostsLines(file, func(number int, line string) bool {
+			if pinned[line] {
+				return true
+			}
+			return problem != nil && problem.KnownHostsFile == file && problem.Line == number
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to update %s: %w", file, err)
+		}
+	}
+
+	if tunnel.HostKey != "" {
+		if pinnedPath != "" {
+			os.Remove(pinnedPath)
+		}
+		return tm.PinHostKey(id, "")
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package core provides host key verification tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	testHostKey            = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIBA7xr7sobszN5IW5K/hDDf9b0/wggYmmd0r8wUXY1na"
+	testHostKeyFingerprint = "SHA256:bZsBM8f5bEaEBSjE+7zgzCt4LtnhYBBVS/LsrevreNM"
+)
+
+// writeKnownHosts writes ~/.ssh/known_hosts in a temporary home directory
+func writeKnownHosts(t *testing.T, lines ...string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(home, ".ssh", "known_hosts")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("Failed to create .ssh: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write known_hosts: %v", err)
+	}
+	return path
+}
+
+// TestParseHostKeyPrompt tests recognizing ssh's question about unknown keys
+func TestParseHostKeyPrompt(t *testing.T) {
+	message := "The authenticity of host 'db.example.com (10.0.0.5)' can't be established.\n" +
+		"ED25519 key fingerprint is " + testHostKeyFingerprint + ".\n" +
+		"This key is not known by any other names.\n" +
+		"Are you sure you want to continue connecting (yes/no/[fingerprint])? "
+
+	prompt, ok := ParseHostKeyPrompt(message)
+	if !ok {
+		t.Fatal("Expected a host key prompt")
+	}
+	if prompt.Host != "db.example.com (10.0.0.5)" || prompt.KeyType != "ED25519" || prompt.Fingerprint != testHostKeyFingerprint {
+		t.Errorf("Unexpected prompt: %+v", prompt)
+	}
+
+	if _, ok := ParseHostKeyPrompt("user@db's password: "); ok {
+		t.Error("Expected a password prompt not to be a host key prompt")
+	}
+}
+
+// TestParseHostKeyProblem tests reading a changed host key from ssh's output
+func TestParseHostKeyProblem(t *testing.T) {
+	output := []string{
+		"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@",
+		"@    WARNING: REMOTE HOST IDENTIFICATION HAS CHANGED!     @",
+		"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@",
+		"The fingerprint for the ED25519 key sent by the remote host is",
+		testHostKeyFingerprint + ".",
+		"Offending ED25519 key in /home/user/.ssh/known_hosts:3",
+		"Host key for db.example.com has changed and you have requested strict checking.",
+		"Host key verification failed.",
+	}
+	problem := parseHostKeyProblem(output)
+	if problem == nil {
+		t.Fatal("Expected a host key problem")
+	}
+	want := HostKeyProblem{
+		Host:           "db.example.com",
+		Changed:        true,
+		Fingerprint:    testHostKeyFingerprint,
+		KnownHostsFile: "/home/user/.ssh/known_hosts",
+		Line:           3,
+	}
+	if *problem != want {
+		t.Errorf("Expected %+v, got %+v", want, *problem)
+	}
+
+	pinned := parseHostKeyProblem([]string{
+		"No ED25519 host key is known for db.example.com and you have requested strict checking.",
+		"Host key verification failed.",
+	})
+	if pinned == nil || pinned.Changed || pinned.Host != "db.example.com" {
+		t.Errorf("Unexpected pinned key problem: %+v", pinned)
+	}
+
+	if problem := parseHostKeyProblem([]string{"Permission denied (publickey)."}); problem != nil {
+		t.Errorf("Expected no host key problem, got %+v", problem)
+	}
+}
+
+// TestPinnedHostKey tests that a pinned key is copied from known_hosts and
+// is the only key ssh accepts
+func TestPinnedHostKey(t *testing.T) {
+	writeKnownHosts(t,
+		"other.example.com ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl",
+		"db.example.com "+testHostKey,
+	)
+	pm := NewProcessManager(WithHostKeyDir(t.TempDir()))
+	tunnel := &Tunnel{ID: "db", SSHHost: "db.example.com", HostKey: testHostKeyFingerprint}
+
+	args := strings.Join(pm.hostKeyArgs(tunnel), " ")
+	if !strings.Contains(args, "StrictHostKeyChecking=yes") || !strings.Contains(args, "UserKnownHostsFile="+pm.pinnedHostsPath("db")) {
+		t.Errorf("Expected strict checking against the pinned key, got %s", args)
+	}
+
+	if err := pm.preparePinnedHostKey(tunnel); err != nil {
+		t.Fatalf("Failed to prepare pinned key: %v", err)
+	}
+	data, err := os.ReadFile(pm.pinnedHostsPath("db"))
+	if err != nil {
+		t.Fatalf("Failed to read pinned key: %v", err)
+	}
+	if string(data) != "db.example.com "+testHostKey+"\n" {
+		t.Errorf("Expected only the pinned entry, got %q", data)
+	}
+
+	tunnel.HostKey = "SHA256:unknown"
+	if err := pm.preparePinnedHostKey(tunnel); err == nil {
+		t.Error("Expected a key missing from known_hosts to be reported")
+	}
+
+	unpinned := &Tunnel{ID: "web", SSHHost: "web.example.com"}
+	if args := strings.Join(pm.hostKeyArgs(unpinned), " "); args != "-o StrictHostKeyChecking=accept-new" {
+		t.Errorf("Expected new keys to be accepted without prompts, got %s", args)
+	}
+	pm.setAskpass([]string{askpassSocketEnv + "=askpass.sock"})
+	if args := strings.Join(pm.hostKeyArgs(unpinned), " "); args != "-o StrictHostKeyChecking=ask" {
+		t.Errorf("Expected new keys to be asked about with prompts, got %s", args)
+	}
+}
+
+// TestForgetHostKey tests removing the known key ssh refused
+func TestForgetHostKey(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.example.com", "localPort": 15432, "remotePort": 5432, "mode": "local"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+	knownHosts := writeKnownHosts(t, "web.example.com "+testHostKey, "db.example.com "+testHostKey)
+
+	logPath := tm.processManager.LogPath("db")
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o700); err != nil {
+		t.Fatalf("Failed to create log directory: %v", err)
+	}
+	output := "Offending ED25519 key in " + knownHosts + ":2\n" +
+		"Host key for db.example.com has changed and you have requested strict checking.\n" +
+		"Host key verification failed.\n"
+	if err := os.WriteFile(logPath, []byte(output), 0o600); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	if err := tm.ForgetHostKey("db"); err != nil {
+		t.Fatalf("ForgetHostKey failed: %v", err)
+	}
+	data, _ := os.ReadFile(knownHosts)
+	if string(data) != "web.example.com "+testHostKey+"\n" {
+		t.Errorf("Expected only the offending entry to be removed, got %q", data)
+	}
+	if _, err := os.Stat(knownHosts + ".old"); err != nil {
+		t.Errorf("Expected a backup of known_hosts: %v", err)
+	}
+}
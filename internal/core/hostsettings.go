@@ -0,0 +1,213 @@
+// Package core provides resolving the settings ssh uses for a tunnel's host.
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostSettingsTTL is how long resolved host settings are reused before the
+// SSH config is read again
+const hostSettingsTTL = time.Minute
+
+// hostSettingsTimeout bounds how long ssh -G may take
+const hostSettingsTimeout = 5 * time.Second
+
+// HostSettings are the settings ssh ends up using for a tunnel's SSH host,
+// after applying the SSH config and the tunnel's own options
+type HostSettings struct {
+	HostName  string
+	User      string
+	Port      int
+	ProxyJump string
+
+	// IdentityFiles are the keys ssh offers: those of the configured files
+	// that exist
+	IdentityFiles []string
+
+	// Source tells how the settings were resolved: "ssh -G", or "ssh config"
+	// when they were read by tunnelman because ssh -G failed
+	Source string
+}
+
+// Address returns where the connection goes, as user@hostname:port
+func (s *HostSettings) Address() string {
+	address := BracketHost(s.HostName)
+	if s.Port != 0 {
+		address = fmt.Sprintf("%s:%d", address, s.Port)
+	}
+	if s.User != "" {
+		address = s.User + "@" + address
+	}
+	return address
+}
+
+// hostSettingsCache keeps resolved host settings by the ssh arguments they
+// were resolved for
+type hostSettingsCache struct {
+	mu      sync.Mutex
+	entries map[string]*hostSettingsEntry
+}
+
+// hostSettingsEntry is a cached resolution, or one still in progress
+type hostSettingsEntry struct {
+	settings  *HostSettings
+	err       error
+	resolved  time.Time
+	resolving bool
+}
+
+// HostSettings returns the effective settings of the tunnel's SSH host,
+// resolving them with ssh -G unless they were resolved recently
+func (tm *TunnelManager) HostSettings(tunnel *Tunnel) (*HostSettings, error) {
+	tunnel = tm.effectiveTunnel(tunnel)
+	args := tm.processManager.buildSSHArgs(tunnel)
+	key := strings.Join(args, "\x00")
+
+	tm.hostSettings.mu.Lock()
+	entry := tm.hostSettings.entries[key]
+	if entry != nil && !entry.resolving && time.Since(entry.resolved) < hostSettingsTTL {
+		tm.hostSettings.mu.Unlock()
+		return entry.settings, entry.err
+	}
+	tm.hostSettings.mu.Unlock()
+
+	settings, err := tm.resolveHostSettings(tunnel, args)
+	tm.hostSettings.mu.Lock()
+	tm.hostSettings.entries[key] = &hostSettingsEntry{settings: settings, err: err, resolved: time.Now()}
+	tm.hostSettings.mu.Unlock()
+	return settings, err
+}
+
+// CachedHostSettings returns the effective settings of the tunnel's SSH host
+// without waiting for ssh. If they were not resolved recently, nil is
+// returned and they are resolved in the background, calling done when they
+// are.
+func (tm *TunnelManager) CachedHostSettings(tunnel *Tunnel, done func()) (*HostSettings, error) {
+	tunnel = tm.effectiveTunnel(tunnel)
+	args := tm.processManager.buildSSHArgs(tunnel)
+	key := strings.Join(args, "\x00")
+
+	tm.hostSettings.mu.Lock()
+	defer tm.hostSettings.mu.Unlock()
+	entry := tm.hostSettings.entries[key]
+	if entry != nil && (entry.resolving || time.Since(entry.resolved) < hostSettingsTTL) {
+		// A stale entry still serves while it is resolved again
+		return entry.settings, entry.err
+	}
+
+	if entry == nil {
+		entry = &hostSettingsEntry{}
+		tm.hostSettings.entries[key] = entry
+	}
+	entry.resolving = true
+	go func() {
+		settings, err := tm.resolveHostSettings(tunnel, args)
+		tm.hostSettings.mu.Lock()
+		entry.settings, entry.err = settings, err
+		entry.resolved, entry.resolving = time.Now(), false
+		tm.hostSettings.mu.Unlock()
+		if done != nil {
+			done()
+		}
+	}()
+	return entry.settings, entry.err
+}
+
+// resolveHostSettings asks ssh -G for the settings it would connect with,
+// falling back to reading the SSH config when ssh cannot tell
+func (tm *TunnelManager) resolveHostSettings(tunnel *Tunnel, args []string) (*HostSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), hostSettingsTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, tm.processManager.SSHPath(), append([]string{"-G"}, args...)...).Output()
+	if err == nil {
+		return hostSettingsFromDump(string(out)), nil
+	}
+	Debug("ssh -G failed for %s, reading the SSH config instead: %v", tunnel.SSHHost, err)
+	return hostSettingsFromConfig(NewSSHConfigParser(), tunnel)
+}
+
+// hostSettingsFromDump reads host settings from the output of ssh -G
+func hostSettingsFromDump(output string) *HostSettings {
+	config := parseSSHConfigDump(output)
+	settings := &HostSettings{
+		HostName:  config["hostname"],
+		User:      config["user"],
+		ProxyJump: config["proxyjump"],
+		Source:    "ssh -G",
+	}
+	settings.Port, _ = strconv.Atoi(config["port"])
+	if settings.ProxyJump == "none" {
+		settings.ProxyJump = ""
+	}
+
+	// IdentityFile is printed once per file, which the map keeps only one of
+	var identityFiles []string
+	for _, line := range strings.Split(output, "\n") {
+		if key, value, ok := strings.Cut(strings.TrimSpace(line), " "); ok && strings.EqualFold(key, "identityfile") {
+			identityFiles = append(identityFiles, ExpandHome(value))
+		}
+	}
+	settings.IdentityFiles = existingFiles(identityFiles)
+	return settings
+}
+
+// hostSettingsFromConfig resolves host settings by reading the SSH config,
+// with the tunnel's own options taking precedence as they do for ssh
+func hostSettingsFromConfig(parser *SSHConfigParser, tunnel *Tunnel) (*HostSettings, error) {
+	user, host := "", tunnel.SSHHost
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		user, host = host[:at], host[at+1:]
+	}
+
+	resolved, err := parser.ParseHost(host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	if resolved == nil {
+		resolved = &SSHConfigHost{Name: host}
+	}
+
+	settings := &HostSettings{
+		HostName:      resolved.targetHost(),
+		User:          user,
+		Port:          resolved.Port,
+		IdentityFiles: existingFiles(resolved.IdentityFiles),
+		ProxyJump:     resolved.ProxyJump,
+		Source:        "ssh config",
+	}
+	if settings.User == "" {
+		settings.User = resolved.remoteUser()
+	}
+	if settings.Port == 0 {
+		settings.Port = 22
+	}
+	if tunnel.IdentityFile != "" {
+		// IdentitiesOnly leaves the pinned key the only one offered
+		settings.IdentityFiles = existingFiles([]string{ExpandHome(tunnel.IdentityFile)})
+	}
+	if tunnel.JumpHost != "" {
+		settings.ProxyJump = tunnel.JumpHost
+	}
+	return settings, nil
+}
+
+// existingFiles returns the paths that exist, in order and without repeats
+func existingFiles(paths []string) []string {
+	var existing []string
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil && !seen[path] {
+			existing = append(existing, path)
+			seen[path] = true
+		}
+	}
+	return existing
+}
@@ -0,0 +1,60 @@
+// Package core provides effective host settings tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestHostSettings tests that host settings are read from ssh -G output and,
+// failing that, from the SSH config with the tunnel's options on top
+func TestHostSettings(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "id_ed25519")
+	pinned := filepath.Join(dir, "id_pinned")
+	for _, path := range []string{key, pinned} {
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dump := "user ops\nhostname db.internal\nport 2222\nproxyjump none\n" +
+		"identityfile " + key + "\nidentityfile " + filepath.Join(dir, "id_missing") + "\n"
+	settings := hostSettingsFromDump(dump)
+	if settings.Address() != "ops@db.internal:2222" || settings.ProxyJump != "" {
+		t.Errorf("Unexpected settings from ssh -G: %s via %q", settings.Address(), settings.ProxyJump)
+	}
+	if !reflect.DeepEqual(settings.IdentityFiles, []string{key}) {
+		t.Errorf("Expected only the existing key, got %v", settings.IdentityFiles)
+	}
+
+	sshConfig := filepath.Join(dir, "config")
+	content := "Host db\n    HostName db.internal\n    Port 2222\n    ProxyJump bastion\n    IdentityFile " + key + "\n"
+	if err := os.WriteFile(sshConfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	parser := &SSHConfigParser{configPath: sshConfig}
+
+	tunnel := NewTunnel("db", LocalForward)
+	tunnel.SSHHost = "admin@db"
+	settings, err := hostSettingsFromConfig(parser, tunnel)
+	if err != nil {
+		t.Fatalf("hostSettingsFromConfig failed: %v", err)
+	}
+	if settings.Address() != "admin@db.internal:2222" || settings.ProxyJump != "bastion" || !reflect.DeepEqual(settings.IdentityFiles, []string{key}) {
+		t.Errorf("Unexpected settings from the SSH config: %+v", settings)
+	}
+
+	// The tunnel's own jump host and key take precedence
+	tunnel.JumpHost = "jump.example.com"
+	tunnel.IdentityFile = pinned
+	settings, err = hostSettingsFromConfig(parser, tunnel)
+	if err != nil {
+		t.Fatalf("hostSettingsFromConfig failed: %v", err)
+	}
+	if settings.ProxyJump != "jump.example.com" || !reflect.DeepEqual(settings.IdentityFiles, []string{pinned}) {
+		t.Errorf("Expected the tunnel's options to win, got %+v", settings)
+	}
+}
@@ -0,0 +1,110 @@
+// Package core provides tests of configs split across included files.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestConfigIncludes tests that tunnels of included files are loaded with
+// their source and saved back to the file they came from
+func TestConfigIncludes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+
+	files := map[string]string{
+		"config.json": `{"version": "2.0", "includes": ["work.json", "conf.d/*.json", "missing.json"], "tunnels": [
+			{"id": "main", "name": "Main", "host": "main.example.com", "localPort": 8080, "remotePort": 80, "mode": "local"}
+		]}`,
+		"work.json": `{"version": "2.0", "tunnels": [
+			{"id": "work", "name": "Work", "host": "work.example.com", "localPort": 5432, "remotePort": 5432, "mode": "local", "profile": "work"}
+		], "profiles": [{"name": "work", "description": "Work tunnels", "tunnelIds": null}]}`,
+		"conf.d/home.json": `{"version": "2.0", "tunnels": [
+			{"id": "home", "name": "Home", "host": "home.example.com", "localPort": 1080, "mode": "dynamic"}
+		]}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", name, err)
+		}
+		return string(data)
+	}
+
+	configStore, err := store.NewConfigStore(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pidStore, err := store.NewPIDStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm := NewTunnelManager(configStore, pidStore)
+
+	sources := map[string]string{"main": "", "work": "work.json", "home": filepath.Join("conf.d", "home.json")}
+	for id, source := range sources {
+		tunnel, err := tm.GetTunnel(id)
+		if err != nil {
+			t.Fatalf("Expected tunnel %s to be loaded: %v", id, err)
+		}
+		if tunnel.Source != source {
+			t.Errorf("Expected tunnel %s from %q, got %q", id, source, tunnel.Source)
+		}
+	}
+
+	// Changes go to the file a tunnel came from, and other files are left alone
+	work, _ := tm.GetTunnel("work")
+	update := work.Clone()
+	update.Source = ""
+	update.LocalPort = 15432
+	if err := tm.UpdateTunnel(update); err != nil {
+		t.Fatalf("UpdateTunnel failed: %v", err)
+	}
+	added := NewTunnel("new", LocalForward)
+	added.SSHHost = "new.example.com"
+	added.LocalPort = 9090
+	added.RemotePort = 90
+	if err := tm.AddTunnel(added); err != nil {
+		t.Fatalf("AddTunnel failed: %v", err)
+	}
+
+	if content := read("work.json"); !strings.Contains(content, "15432") || !strings.Contains(content, "Work tunnels") {
+		t.Errorf("Expected work.json to hold the updated tunnel and its profile, got %s", content)
+	}
+	home := read("conf.d/home.json")
+	if !strings.Contains(home, "home.example.com") || strings.Contains(home, "new.example.com") {
+		t.Errorf("Expected home.json to keep only its own tunnel, got %s", home)
+	}
+	main := read("config.json")
+	if strings.Contains(main, "work.example.com") || strings.Contains(main, "Work tunnels") || !strings.Contains(main, "new.example.com") {
+		t.Errorf("Expected only main tunnels in config.json, got %s", main)
+	}
+	if !strings.Contains(main, `"conf.d/*.json"`) {
+		t.Errorf("Expected the includes to be kept, got %s", main)
+	}
+
+	// Includes whose tunnels did not change are not rewritten
+	info, err := os.Stat(filepath.Join(dir, "conf.d", "home.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.DeleteTunnel(added.ID); err != nil {
+		t.Fatalf("DeleteTunnel failed: %v", err)
+	}
+	if after, err := os.Stat(filepath.Join(dir, "conf.d", "home.json")); err != nil || !after.ModTime().Equal(info.ModTime()) || read("conf.d/home.json") != home {
+		t.Error("Expected the unchanged include not to be rewritten")
+	}
+}
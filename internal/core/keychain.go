@@ -0,0 +1,101 @@
+// Package core provides secret storage in the operating system's keychain.
+package core
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// keychainService is the service secrets are stored under in the keychain
+const keychainService = "tunnelman"
+
+// ConfigKeySecret is the name of the keychain secret holding the key an
+// encrypted config is sealed with
+const ConfigKeySecret = "config-key"
+
+// ErrSecretNotFound is returned when the keychain has no secret by a name
+var ErrSecretNotFound = errors.New("secret not found")
+
+// keychain stores secrets by name in the operating system's credential store:
+// the macOS Keychain, the Secret Service (GNOME Keyring, KWallet) through
+// libsecret, or the Windows Credential Manager
+type keychain interface {
+	get(name string) (string, error)
+	set(name, secret string) error
+	delete(name string) error
+}
+
+// systemKeychain is the keychain of the operating system; tests replace it
+var systemKeychain = newSystemKeychain()
+
+// GetSecret returns the secret stored in the keychain under name, or
+// ErrSecretNotFound
+func GetSecret(name string) (string, error) {
+	if err := ValidateSecretName(name); err != nil {
+		return "", err
+	}
+	return systemKeychain.get(name)
+}
+
+// SetSecret stores a secret in the keychain under name, replacing any
+// secret stored under it before
+func SetSecret(name, secret string) error {
+	if err := ValidateSecretName(name); err != nil {
+		return err
+	}
+	if secret == "" {
+		return errors.New("secret is empty")
+	}
+	return systemKeychain.set(name, secret)
+}
+
+// DeleteSecret removes the secret stored in the keychain under name
+func DeleteSecret(name string) error {
+	if err := ValidateSecretName(name); err != nil {
+		return err
+	}
+	return systemKeychain.delete(name)
+}
+
+// ValidateSecretName checks that a secret name can be passed to ssh's
+// askpass helper and the keychain tools
+func ValidateSecretName(name string) error {
+	if name == "" {
+		return errors.New("secret name is required")
+	}
+	if strings.ContainsAny(name, " \t\r\n") {
+		return fmt.Errorf("invalid secret name: %q (must not contain spaces)", name)
+	}
+	return nil
+}
+
+// ConfigKey returns the key of an encrypted config from the keychain
+func ConfigKey() ([]byte, error) {
+	encoded, err := GetSecret(ConfigKeySecret)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config key in the keychain: %w", err)
+	}
+	return key, nil
+}
+
+// EnsureConfigKey stores a new random config key in the keychain unless it
+// already has one
+func EnsureConfigKey() error {
+	if _, err := ConfigKey(); !errors.Is(err, ErrSecretNotFound) {
+		return err
+	}
+	key := make([]byte, store.ConfigKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+	return SetSecret(ConfigKeySecret, base64.StdEncoding.EncodeToString(key))
+}
@@ -0,0 +1,222 @@
+// Package core provides keychain secret tests.
+package core
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// memoryKeychain is a keychain kept in memory
+type memoryKeychain map[string]string
+
+func (k memoryKeychain) get(name string) (string, error) {
+	secret, ok := k[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return secret, nil
+}
+
+func (k memoryKeychain) set(name, secret string) error {
+	k[name] = secret
+	return nil
+}
+
+func (k memoryKeychain) delete(name string) error {
+	if _, ok := k[name]; !ok {
+		return ErrSecretNotFound
+	}
+	delete(k, name)
+	return nil
+}
+
+// useMemoryKeychain replaces the system keychain for the duration of a test
+func useMemoryKeychain(t *testing.T) memoryKeychain {
+	t.Helper()
+	k := memoryKeychain{}
+	previous := systemKeychain
+	systemKeychain = k
+	t.Cleanup(func() { systemKeychain = previous })
+	return k
+}
+
+// TestSecrets tests storing, reading and removing secrets
+func TestSecrets(t *testing.T) {
+	useMemoryKeychain(t)
+
+	if err := SetSecret("db-password", "hunter2"); err != nil {
+		t.Fatalf("SetSecret failed: %v", err)
+	}
+	if secret, err := GetSecret("db-password"); err != nil || secret != "hunter2" {
+		t.Errorf("Expected the stored secret, got %q (%v)", secret, err)
+	}
+	if err := DeleteSecret("db-password"); err != nil {
+		t.Errorf("DeleteSecret failed: %v", err)
+	}
+	if _, err := GetSecret("db-password"); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected a removed secret not to be found, got %v", err)
+	}
+
+	if err := SetSecret("db password", "hunter2"); err == nil {
+		t.Error("Expected a name with spaces to be rejected")
+	}
+	if err := SetSecret("db-password", ""); err == nil {
+		t.Error("Expected an empty secret to be rejected")
+	}
+
+	tunnel := &Tunnel{Name: "db", Type: LocalForward, SSHHost: "db.example.com", LocalPort: 15432, RemoteHost: "localhost", RemotePort: 5432, Secret: "db password"}
+	if err := tunnel.Validate(); err == nil {
+		t.Error("Expected a tunnel with an invalid secret name to be rejected")
+	}
+}
+
+// TestAskpassSecret tests that password prompts are answered from the
+// keychain without prompts being enabled, and fall back to the prompter
+func TestAskpassSecret(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "database", "host": "db.example.com", "localPort": 15432, "remotePort": 5432, "mode": "local", "secret": "db-passphrase"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+	keychain := useMemoryKeychain(t)
+	keychain["db-passphrase"] = "correct horse"
+
+	tunnel, _ := tm.GetTunnel("db")
+	setAskpassEnv(t, tm, tunnel)
+
+	answer, err := askpass("Enter passphrase for key '/home/user/.ssh/id_ed25519': ", "")
+	if err != nil || answer != "correct horse" {
+		t.Errorf("Expected the keychain secret, got %q (%v)", answer, err)
+	}
+	if _, err := askpass("Verification code: ", ""); !errors.Is(err, ErrPromptCancelled) {
+		t.Errorf("Expected other prompts to be cancelled without the TUI, got %v", err)
+	}
+
+	delete(keychain, "db-passphrase")
+	if _, err := askpass("user@db.example.com's password: ", ""); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected a missing secret to fail, got %v", err)
+	}
+
+	stop, err := tm.EnablePrompts(func(ctx context.Context, prompt Prompt) (string, error) {
+		return "typed", nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to enable prompts: %v", err)
+	}
+	defer stop()
+	setAskpassEnv(t, tm, tunnel)
+
+	if answer, err := askpass("user@db.example.com's password: ", ""); err != nil || answer != "typed" {
+		t.Errorf("Expected a missing secret to be asked for, got %q (%v)", answer, err)
+	}
+	// ssh only learns of a wrapper script, which holds the token and secret
+	// name and asks for the helper by argument
+	env := tm.processManager.(*ProcessManager).askpassEnv(tunnel)
+	joined := strings.Join(env, " ")
+	if strings.Count(joined, "SSH_ASKPASS=") != 1 || strings.Contains(joined, askpassTokenEnv) || strings.Contains(joined, askpassSecretEnv) {
+		t.Errorf("Unexpected ssh environment: %s", joined)
+	}
+	wrapper, _ := strings.CutPrefix(env[0], "SSH_ASKPASS=")
+	script, err := os.ReadFile(wrapper)
+	if err != nil || !strings.Contains(string(script), AskpassCommand) || !strings.Contains(string(script), "db-passphrase") {
+		t.Errorf("Expected the askpass wrapper to run the helper with the secret name, got %q (%v)", script, err)
+	}
+}
+
+// TestEncryptedConfig tests that an encrypted config keeps host names out of
+// the file, loads transparently and is not overwritten without its key
+func TestEncryptedConfig(t *testing.T) {
+	keychain := useMemoryKeychain(t)
+
+	configJSON := `{
+  "version": "2.0",
+  "tunnels": [
+    {"id": "db", "name": "DB", "host": "db.secret.example.com", "localPort": 15432, "remotePort": 5432, "mode": "local"}
+  ]
+}`
+	_, configStore := newTestManager(t, configJSON)
+	configStore.SetKeyFunc(ConfigKey)
+	if err := configStore.BackupConfig(); err != nil {
+		t.Fatalf("BackupConfig failed: %v", err)
+	}
+
+	if err := EnsureConfigKey(); err != nil {
+		t.Fatalf("EnsureConfigKey failed: %v", err)
+	}
+	if err := configStore.SetEncrypted(true); err != nil {
+		t.Fatalf("SetEncrypted failed: %v", err)
+	}
+	configPath, _ := configStore.GetConfigPath()
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if strings.Contains(string(data), "db.secret.example.com") || !configStore.Encrypted() {
+		t.Fatalf("Expected the config to be encrypted, got %s", data)
+	}
+
+	// Snapshots and backups written before are sealed too, and still
+	// readable. The test's directory holds the state directory as well.
+	filepath.WalkDir(filepath.Dir(configPath), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if data, _ := os.ReadFile(path); strings.Contains(string(data), "db.secret.example.com") {
+			t.Errorf("Expected no plaintext copy of the config, found %s", path)
+		}
+		return nil
+	})
+	snapshots, err := configStore.History()
+	if err != nil || len(snapshots) == 0 {
+		t.Fatalf("Expected config snapshots, got %v (%v)", snapshots, err)
+	}
+	for _, snapshot := range snapshots {
+		if snapshot.Tunnels != 1 {
+			t.Errorf("Expected snapshot %s to be readable, got %d tunnels", snapshot.ID, snapshot.Tunnels)
+		}
+	}
+
+	// Loads decrypt, and saves keep the config encrypted
+	pidStore, err := store.NewPIDStore()
+	if err != nil {
+		t.Fatalf("Failed to create PID store: %v", err)
+	}
+	tm := NewTunnelManager(configStore, pidStore)
+	tunnel, err := tm.GetTunnel("db")
+	if err != nil || tunnel.SSHHost != "db.secret.example.com" {
+		t.Fatalf("Expected the tunnel to be decrypted, got %v", err)
+	}
+	if err := tm.DeleteTunnel("db"); err != nil {
+		t.Fatalf("DeleteTunnel failed: %v", err)
+	}
+	if !configStore.Encrypted() {
+		t.Error("Expected the config to stay encrypted when saved")
+	}
+
+	// Without the key the config is neither read nor overwritten
+	key := keychain[ConfigKeySecret]
+	delete(keychain, ConfigKeySecret)
+	if _, err := configStore.LoadConfig(); err == nil {
+		t.Error("Expected loading without the key to fail")
+	}
+	if err := configStore.SaveConfig(&store.AppConfig{}); !errors.Is(err, store.ErrConfigLocked) {
+		t.Errorf("Expected saving over a locked config to fail, got %v", err)
+	}
+
+	keychain[ConfigKeySecret] = key
+	if err := configStore.SetEncrypted(false); err != nil {
+		t.Fatalf("Decrypting failed: %v", err)
+	}
+	if configStore.Encrypted() {
+		t.Error("Expected the config to be stored in plaintext")
+	}
+}
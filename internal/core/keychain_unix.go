@@ -0,0 +1,119 @@
+//go:build !windows
+
+// Package core provides keychain access on macOS and other Unix-like systems.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// newSystemKeychain returns the macOS Keychain, or the Secret Service through
+// libsecret's secret-tool elsewhere
+func newSystemKeychain() keychain {
+	if runtime.GOOS == "darwin" {
+		return macKeychain{}
+	}
+	return secretService{}
+}
+
+// macKeychain stores secrets as generic passwords in the login keychain
+// using the security tool
+type macKeychain struct{}
+
+// securityNotFound is the exit code of security when no item matches
+const securityNotFound = 44
+
+func (macKeychain) get(name string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", keychainService, "-a", name, "-w").Output()
+	if err != nil {
+		return "", keychainError("security", err, securityNotFound)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (macKeychain) set(name, secret string) error {
+	// security -i reads the command from stdin, keeping the secret out of
+	// the process list
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -l %s -w %s\n",
+		quoteArg(keychainService), quoteArg(name), quoteArg(keychainService+": "+name), quoteArg(secret)))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return keychainError("security", err, 0)
+	}
+	return nil
+}
+
+func (macKeychain) delete(name string) error {
+	err := exec.Command("security", "delete-generic-password", "-s", keychainService, "-a", name).Run()
+	return keychainError("security", err, securityNotFound)
+}
+
+// secretService stores secrets in the Secret Service, e.g. GNOME Keyring or
+// KWallet, using libsecret's secret-tool
+type secretService struct{}
+
+func (secretService) get(name string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keychainService, "account", name).Output()
+	var exitErr *exec.ExitError
+	if err == nil && len(out) == 0 || errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+		// lookup exits quietly when nothing matches
+		return "", ErrSecretNotFound
+	}
+	if err != nil {
+		return "", keychainError("secret-tool", err, 0)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (secretService) set(name, secret string) error {
+	// store reads the secret from stdin
+	cmd := exec.Command("secret-tool", "store", "--label", keychainService+": "+name, "service", keychainService, "account", name)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			err = fmt.Errorf("%w: %s", err, msg)
+		}
+		return keychainError("secret-tool", err, 0)
+	}
+	return nil
+}
+
+func (s secretService) delete(name string) error {
+	// clear succeeds whether or not anything matched
+	if _, err := s.get(name); err != nil {
+		return err
+	}
+	err := exec.Command("secret-tool", "clear", "service", keychainService, "account", name).Run()
+	return keychainError("secret-tool", err, 0)
+}
+
+// keychainError describes a failed keychain tool, returning
+// ErrSecretNotFound for its notFound exit code (0 for none) and nil for no
+// error
+func keychainError(tool string, err error, notFound int) error {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, exec.ErrNotFound):
+		if tool == "secret-tool" {
+			return errors.New("secret-tool not found; install libsecret-tools to store secrets")
+		}
+		return fmt.Errorf("%s not found", tool)
+	case errors.As(err, &exitErr):
+		if notFound != 0 && exitErr.ExitCode() == notFound {
+			return ErrSecretNotFound
+		}
+		if msg := strings.TrimSpace(string(exitErr.Stderr)); msg != "" {
+			return fmt.Errorf("%s failed: %s", tool, msg)
+		}
+	}
+	return fmt.Errorf("%s failed: %w", tool, err)
+}
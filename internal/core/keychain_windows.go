@@ -0,0 +1,119 @@
+//go:build windows
+
+// Package core provides keychain access through the Windows Credential Manager.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// Credential Manager constants from wincred.h
+const (
+	credTypeGeneric          = 1
+	credPersistLocalMachine  = 2
+	credMaxCredentialBlobLen = 5 * 512
+)
+
+var (
+	advapi32        = windows.NewLazySystemDLL("advapi32.dll")
+	procCredReadW   = advapi32.NewProc("CredReadW")
+	procCredWriteW  = advapi32.NewProc("CredWriteW")
+	procCredDeleteW = advapi32.NewProc("CredDeleteW")
+	procCredFree    = advapi32.NewProc("CredFree")
+)
+
+// credential is the CREDENTIALW structure
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// newSystemKeychain returns the Windows Credential Manager
+func newSystemKeychain() keychain {
+	return credentialManager{}
+}
+
+// credentialManager stores secrets as generic credentials named
+// "tunnelman:<name>"
+type credentialManager struct{}
+
+// target returns the credential name of a secret
+func (credentialManager) target(name string) (*uint16, error) {
+	return windows.UTF16PtrFromString(keychainService + ":" + name)
+}
+
+func (c credentialManager) get(name string) (string, error) {
+	target, err := c.target(name)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		return "", credentialError(err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
+
+func (c credentialManager) set(name, secret string) error {
+	if len(secret) > credMaxCredentialBlobLen {
+		return fmt.Errorf("secret is longer than %d bytes", credMaxCredentialBlobLen)
+	}
+	target, err := c.target(name)
+	if err != nil {
+		return err
+	}
+	user, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		CredentialBlob:     unsafe.SliceData(blob),
+		Persist:            credPersistLocalMachine,
+		UserName:           user,
+	}
+	if r, _, err := procCredWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); r == 0 {
+		return credentialError(err)
+	}
+	return nil
+}
+
+func (c credentialManager) delete(name string) error {
+	target, err := c.target(name)
+	if err != nil {
+		return err
+	}
+	if r, _, err := procCredDeleteW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); r == 0 {
+		return credentialError(err)
+	}
+	return nil
+}
+
+// credentialError converts a Credential Manager error, returning
+// ErrSecretNotFound when the credential does not exist
+func credentialError(err error) error {
+	if errors.Is(err, windows.ERROR_NOT_FOUND) {
+		return ErrSecretNotFound
+	}
+	return fmt.Errorf("credential manager: %w", err)
+}
@@ -0,0 +1,225 @@
+// Package core provides latency monitoring of SSH hosts.
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxProxyJumpDepth limits how many ProxyJump hosts from the SSH config are
+// followed to find the host tunnelman connects to first
+const maxProxyJumpDepth = 5
+
+// LatencyMonitor periodically measures the TCP connect time to the first
+// host of each tunnel's SSH chain, which is the jump host if there is one
+type LatencyMonitor struct {
+	manager *TunnelManager
+
+	interval time.Duration
+	timeout  time.Duration
+	onChange func()
+
+	// resolve returns the address ssh would connect to for a host; a field so
+	// tests can avoid running ssh
+	resolve func(ctx context.Context, host string, extraArgs []string) (string, error)
+}
+
+// LatencyMonitorOption is a functional option for LatencyMonitor
+type LatencyMonitorOption func(*LatencyMonitor)
+
+// WithLatencyInterval sets the delay between measurement rounds
+func WithLatencyInterval(d time.Duration) LatencyMonitorOption {
+	return func(m *LatencyMonitor) {
+		m.interval = d
+	}
+}
+
+// WithLatencyTimeout sets how long a single measurement may take
+func WithLatencyTimeout(d time.Duration) LatencyMonitorOption {
+	return func(m *LatencyMonitor) {
+		m.timeout = d
+	}
+}
+
+// WithLatencyChangeFunc sets a function called after each measurement round
+func WithLatencyChangeFunc(fn func()) LatencyMonitorOption {
+	return func(m *LatencyMonitor) {
+		m.onChange = fn
+	}
+}
+
+// NewLatencyMonitor creates a latency monitor for the manager's tunnels
+func NewLatencyMonitor(manager *TunnelManager, opts ...LatencyMonitorOption) *LatencyMonitor {
+	m := &LatencyMonitor{
+		manager:  manager,
+		interval: 30 * time.Second,
+		timeout:  5 * time.Second,
+	}
+	m.resolve = m.resolveAddress
+
+	// Apply options
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Run measures latency every interval until ctx is cancelled
+func (m *LatencyMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.Check(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// latencyTarget is a first hop shared by one or more tunnels
+type latencyTarget struct {
+	host      string
+	extraArgs []string
+	tunnelIDs []string
+}
+
+// Check measures every first hop once, concurrently, and records the results
+func (m *LatencyMonitor) Check(ctx context.Context) {
+	targets := make(map[string]*latencyTarget)
+	for _, t := range m.manager.effectiveTunnels() {
+		host, extraArgs := firstHop(t)
+		if host == "" {
+			continue
+		}
+		key := host + "\x00" + strings.Join(extraArgs, " ")
+		if targets[key] == nil {
+			targets[key] = &latencyTarget{host: host, extraArgs: extraArgs}
+		}
+		targets[key].tunnelIDs = append(targets[key].tunnelIDs, t.ID)
+	}
+
+	var wg sync.WaitGroup
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target *latencyTarget) {
+			defer wg.Done()
+			measureCtx, cancel := context.WithTimeout(ctx, m.timeout)
+			defer cancel()
+			latency, err := m.measure(measureCtx, target)
+			m.manager.setLatency(target.tunnelIDs, latency, err)
+		}(target)
+	}
+	wg.Wait()
+
+	if len(targets) > 0 && m.onChange != nil {
+		m.onChange()
+	}
+}
+
+// measure returns the time taken to open a TCP connection to a target
+func (m *LatencyMonitor) measure(ctx context.Context, target *latencyTarget) (time.Duration, error) {
+	address, err := m.resolve(ctx, target.host, target.extraArgs)
+	if err != nil {
+		return 0, err
+	}
+	return measureTCPConnect(ctx, address)
+}
+
+// measureTCPConnect returns how long it takes to connect to address
+func measureTCPConnect(ctx context.Context, address string) (time.Duration, error) {
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
+
+// firstHop returns the host a tunnel's ssh connects to first, and the extra
+// arguments that apply to it
+func firstHop(t *Tunnel) (string, []string) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if t.JumpHost != "" {
+		return strings.Split(t.JumpHost, ",")[0], nil
+	}
+	return t.SSHHost, append([]string(nil), t.ExtraArgs...)
+}
+
+// resolveAddress asks ssh -G for the address it would connect to for host,
+// following ProxyJump settings from the SSH config to the first jump host
+func (m *LatencyMonitor) resolveAddress(ctx context.Context, host string, extraArgs []string) (string, error) {
+	sshPath := m.manager.processManager.SSHPath()
+	for depth := 0; depth < maxProxyJumpDepth; depth++ {
+		args := []string{"-G"}
+		args = append(args, extraArgs...)
+		if user, hostname, port := splitHop(host); port != "" {
+			args = append(args, "-p", port, user+hostname)
+		} else {
+			args = append(args, host)
+		}
+
+		out, err := exec.CommandContext(ctx, sshPath, args...).Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", host, err)
+		}
+		config := parseSSHConfigDump(string(out))
+
+		if jump := config["proxyjump"]; jump != "" && jump != "none" {
+			host, extraArgs = strings.Split(jump, ",")[0], nil
+			continue
+		}
+		if command := config["proxycommand"]; command != "" && command != "none" {
+			return "", fmt.Errorf("%s is reached through a ProxyCommand", host)
+		}
+		if config["hostname"] == "" {
+			return "", fmt.Errorf("failed to resolve %s", host)
+		}
+		port := config["port"]
+		if port == "" {
+			port = "22"
+		}
+		return net.JoinHostPort(config["hostname"], port), nil
+	}
+	return "", fmt.Errorf("too many ProxyJump hosts before %s", host)
+}
+
+// splitHop splits a hop such as "admin@gw:2222" into "admin@", "gw" and "2222"
+func splitHop(hop string) (user, hostname, port string) {
+	if i := strings.LastIndex(hop, "@"); i >= 0 {
+		user, hop = hop[:i+1], hop[i+1:]
+	}
+	hostname = hopHostname(hop)
+	if len(hop) > len(hostname) {
+		port = hop[len(hostname)+1:]
+	}
+	return user, hostname, port
+}
+
+// parseSSHConfigDump parses the "key value" lines printed by ssh -G
+func parseSSHConfigDump(output string) map[string]string {
+	config := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), " ")
+		if ok {
+			config[strings.ToLower(key)] = strings.TrimSpace(value)
+		}
+	}
+	return config
+}
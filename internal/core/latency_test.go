@@ -0,0 +1,89 @@
+// Package core provides latency monitor tests.
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// TestLatencyMonitorCheck tests that tunnels sharing a first hop are measured once
+func TestLatencyMonitorCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	tm, _ := newTestManager(t, "")
+	tm.tunnels["a"] = &Tunnel{ID: "a", Name: "a", SSHHost: "db", JumpHost: "bastion,gw"}
+	tm.tunnels["b"] = &Tunnel{ID: "b", Name: "b", SSHHost: "cache", JumpHost: "bastion"}
+	tm.tunnels["c"] = &Tunnel{ID: "c", Name: "c", SSHHost: "down"}
+
+	var mu sync.Mutex
+	var resolved []string
+	changes := 0
+	monitor := NewLatencyMonitor(tm, WithLatencyChangeFunc(func() { changes++ }))
+	monitor.resolve = func(ctx context.Context, host string, extraArgs []string) (string, error) {
+		mu.Lock()
+		resolved = append(resolved, host)
+		mu.Unlock()
+		if host == "down" {
+			return "", errors.New("no such host")
+		}
+		return listener.Addr().String(), nil
+	}
+	monitor.Check(context.Background())
+
+	if len(resolved) != 2 {
+		t.Errorf("Expected bastion and down to be resolved once each, got %v", resolved)
+	}
+	for _, id := range []string{"a", "b"} {
+		if tunnel := tm.tunnels[id]; tunnel.Latency <= 0 || tunnel.LatencyError != nil {
+			t.Errorf("Tunnel %s: expected a latency, got %s (%v)", id, tunnel.Latency, tunnel.LatencyError)
+		}
+	}
+	if tunnel := tm.tunnels["c"]; tunnel.LatencyError == nil || tunnel.Latency != 0 {
+		t.Errorf("Expected tunnel c to fail, got %s (%v)", tunnel.Latency, tunnel.LatencyError)
+	}
+	if changes != 1 {
+		t.Errorf("Expected one change notification, got %d", changes)
+	}
+}
+
+// TestSplitHop tests splitting jump host specifications
+func TestSplitHop(t *testing.T) {
+	tests := []struct {
+		hop                  string
+		user, hostname, port string
+	}{
+		{"bastion", "", "bastion", ""},
+		{"admin@gw:2222", "admin@", "gw", "2222"},
+		{"gw:2222", "", "gw", "2222"},
+		{"admin@gw", "admin@", "gw", ""},
+	}
+	for _, tt := range tests {
+		user, hostname, port := splitHop(tt.hop)
+		if user != tt.user || hostname != tt.hostname || port != tt.port {
+			t.Errorf("splitHop(%q) = %q, %q, %q", tt.hop, user, hostname, port)
+		}
+	}
+}
+
+// TestParseSSHConfigDump tests parsing ssh -G output
+func TestParseSSHConfigDump(t *testing.T) {
+	config := parseSSHConfigDump("user admin\nhostname 10.0.0.5\nport 2222\nproxyjump none\nlocalforward 8080 [localhost]:80\n")
+	want := map[string]string{
+		"user":         "admin",
+		"hostname":     "10.0.0.5",
+		"port":         "2222",
+		"proxyjump":    "none",
+		"localforward": "8080 [localhost]:80",
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("Expected %v, got %v", want, config)
+	}
+}
@@ -0,0 +1,162 @@
+// Package core provides checks that running tunnels really listen locally.
+package core
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// ListenChecker periodically dials the local ports of running tunnels, to
+// catch ssh processes that are up while their forwards are not, e.g. because
+// a forward failed without ExitOnForwardFailure
+type ListenChecker struct {
+	manager *TunnelManager
+
+	interval     time.Duration
+	timeout      time.Duration
+	missingAfter int
+	onChange     func(tunnelID string)
+
+	// Consecutive refused checks per tunnel ID
+	failures map[string]int
+}
+
+// ListenCheckerOption is a functional option for ListenChecker
+type ListenCheckerOption func(*ListenChecker)
+
+// WithListenInterval sets the delay between check rounds
+func WithListenInterval(d time.Duration) ListenCheckerOption {
+	return func(c *ListenChecker) {
+		c.interval = d
+	}
+}
+
+// WithListenTimeout sets how long dialing a local port may take
+func WithListenTimeout(d time.Duration) ListenCheckerOption {
+	return func(c *ListenChecker) {
+		c.timeout = d
+	}
+}
+
+// WithMissingAfter sets how many consecutive refused checks mark a tunnel as
+// not listening, so ports that are still being set up are not reported
+func WithMissingAfter(n int) ListenCheckerOption {
+	return func(c *ListenChecker) {
+		c.missingAfter = n
+	}
+}
+
+// WithListenChangeFunc sets a function called whenever a tunnel starts or
+// stops accepting connections
+func WithListenChangeFunc(fn func(tunnelID string)) ListenCheckerOption {
+	return func(c *ListenChecker) {
+		c.onChange = fn
+	}
+}
+
+// NewListenChecker creates a listen checker for the manager's tunnels
+func NewListenChecker(manager *TunnelManager, opts ...ListenCheckerOption) *ListenChecker {
+	c := &ListenChecker{
+		manager:      manager,
+		interval:     5 * time.Second,
+		timeout:      time.Second,
+		missingAfter: 2,
+		failures:     make(map[string]int),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Run checks running tunnels every interval until ctx is cancelled
+func (c *ListenChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.Check(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check dials the local ports of every running tunnel once, concurrently, and
+// records the first address that refused a connection
+func (c *ListenChecker) Check(ctx context.Context) {
+	type result struct {
+		id      string
+		address string
+	}
+
+	var running []*Tunnel
+	for _, t := range c.manager.GetTunnels() {
+		if t.Status == StatusRunning {
+			running = append(running, t)
+			continue
+		}
+		delete(c.failures, t.ID)
+		c.record(t.ID, "")
+	}
+
+	results := make(chan result, len(running))
+	var wg sync.WaitGroup
+	for _, t := range running {
+		wg.Add(1)
+		go func(t *Tunnel) {
+			defer wg.Done()
+			results <- result{id: t.ID, address: c.refusedAddress(ctx, t)}
+		}(t)
+	}
+	wg.Wait()
+	close(results)
+
+	for r := range results {
+		if r.address == "" {
+			delete(c.failures, r.id)
+			c.record(r.id, "")
+			continue
+		}
+		c.failures[r.id]++
+		if c.failures[r.id] >= c.missingAfter {
+			c.record(r.id, r.address)
+		}
+	}
+}
+
+// refusedAddress returns the first local address of a tunnel that does not
+// accept connections, or an empty string. Remote forwards listen on the SSH
+// host and are left to the health checker.
+func (c *ListenChecker) refusedAddress(ctx context.Context, t *Tunnel) string {
+	for _, f := range t.AllForwards() {
+		address, hasListener := f.DialAddress()
+		if !hasListener {
+			continue
+		}
+		dialCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(dialCtx, "tcp", address)
+		cancel()
+		if err != nil {
+			return address
+		}
+		conn.Close()
+	}
+	return ""
+}
+
+// record stores a check result and reports a change to onChange
+func (c *ListenChecker) record(id, address string) {
+	if c.manager.setNotListening(id, address) && c.onChange != nil {
+		c.onChange(id)
+	}
+}
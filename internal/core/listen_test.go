@@ -0,0 +1,58 @@
+// Package core provides listen checker tests.
+package core
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+// TestListenCheckerReportsMissingListener tests that a running tunnel whose
+// local port refuses connections is reported once the refusal persists, and
+// cleared when the tunnel stops
+func TestListenCheckerReportsMissingListener(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	address := listener.Addr().String()
+
+	tm, _ := newTestManager(t, "")
+	tunnel := &Tunnel{
+		ID:        "web",
+		Name:      "web",
+		Type:      LocalForward,
+		LocalHost: "127.0.0.1",
+		LocalPort: port,
+		SSHHost:   "example.com",
+		Status:    StatusRunning,
+		Forwards:  []Forward{{Type: RemoteForward, LocalPort: 1, RemotePort: 9000}},
+	}
+	tm.tunnels[tunnel.ID] = tunnel
+
+	changes := 0
+	checker := NewListenChecker(tm, WithMissingAfter(2), WithListenChangeFunc(func(string) { changes++ }))
+	expect := func(want string) {
+		t.Helper()
+		checker.Check(context.Background())
+		got, _ := tm.GetTunnel(tunnel.ID)
+		if got.NotListening != want {
+			t.Errorf("Expected not listening on %q, got %q", want, got.NotListening)
+		}
+	}
+
+	expect("")
+	listener.Close()
+	expect("")
+	expect(address)
+	expect(address)
+
+	tm.mu.Lock()
+	tunnel.Status = StatusStopped
+	tm.mu.Unlock()
+	expect("")
+	if changes != 2 {
+		t.Errorf("Expected 2 changes, got %d", changes)
+	}
+}
@@ -0,0 +1,68 @@
+// Package core provides tests of the locking shared by tunnelman processes.
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestConcurrentStoreUpdates tests that updates through separate stores, as
+// made by two tunnelman processes, are not lost
+func TestConcurrentStoreUpdates(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", filepath.Join(t.TempDir(), "state"))
+
+	const writers, updates = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*updates*2)
+	for w := 0; w < writers; w++ {
+		pidStore, err := store.NewPIDStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		statsStore, err := store.NewFileStatsStore()
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < updates; i++ {
+				// The test process stands in for the ssh processes, since it is sure to be running
+				errs <- pidStore.AddPid(fmt.Sprintf("tunnel-%d-%d", w, i), os.Getpid())
+				errs <- statsStore.Update("shared", func(stats *store.TunnelStats) {
+					stats.Connects++
+				})
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+
+	pidStore, _ := store.NewPIDStore()
+	pids, err := pidStore.LoadPids()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pids.Pids) != writers*updates {
+		t.Errorf("Expected %d PIDs, got %d", writers*updates, len(pids.Pids))
+	}
+
+	statsStore, _ := store.NewFileStatsStore()
+	stats, err := statsStore.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := stats.Stats["shared"].Connects; got != writers*updates {
+		t.Errorf("Expected %d connects, got %d", writers*updates, got)
+	}
+}
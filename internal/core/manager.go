@@ -5,12 +5,13 @@ import (
 	"bufio"
 	"context"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
 
 	"github.com/takaaki-s/tunnelman/internal/store"
@@ -24,21 +25,73 @@ type TunnelManager struct {
 	mu          sync.RWMutex
 
 	// Process manager for SSH connections
-	processManager *ProcessManager
+	processManager ProcessBackend
+
+	// Starts and stops tunnels in the process that owns them, such as a
+	// running daemon; nil runs them in this process
+	remote RemoteControl
+
+	// Global defaults from the config, written back when tunnels are saved
+	defaults *store.Defaults
+
+	// SSH settings tunnels inherit from their profile, by profile name
+	profileDefaults map[string]*store.ProfileDefaults
+
+	// Problems found in the config when it was last loaded or saved
+	configIssues []ConfigIssue
+
+	// Stamp of the config file as last loaded or saved, to tell changes by
+	// other programs from our own
+	configStamp store.FileStamp
+
+	// Files the config includes and its storage backend, written back
+	// when tunnels are saved
+	includes []string
+	storage  *store.Storage
 
 	// Debug mode flag
 	debug bool
 
-	// Event channels for UI updates
-	statusChanges chan TunnelStatusChange
+	// Hooks and webhooks still running in the background
+	hooks sync.WaitGroup
+
+	// Shows desktop notifications
+	notifier Notifier
+
+	// Connection statistics kept across restarts; nil if unavailable
+	statsStore store.StatsStore
+
+	// Effective SSH host settings resolved for the detail view
+	hostSettings hostSettingsCache
+
+	// Tunnels deleted since the manager was created, oldest first
+	trash []DeletedTunnel
+
+	// Subscribers to status changes, by the channel handed out to them
+	subMu       sync.Mutex
+	subscribers map[<-chan TunnelStatusChange]*subscriber
+
+	// Simulates ssh processes instead of running them when set
+	mockConfig *MockConfig
 }
 
 // TunnelStatusChange represents a tunnel status change event
 type TunnelStatusChange struct {
-	TunnelID string
+	TunnelID  string
 	OldStatus TunnelStatus
 	NewStatus TunnelStatus
 	Error     error
+	Time      time.Time
+}
+
+// StatusEvent is the serializable form of a TunnelStatusChange
+type StatusEvent struct {
+	Time       time.Time    `json:"time"`
+	TunnelID   string       `json:"tunnel_id"`
+	TunnelName string       `json:"tunnel_name,omitempty"`
+	OldStatus  TunnelStatus `json:"old_status"`
+	NewStatus  TunnelStatus `json:"new_status"`
+	Error      string       `json:"error,omitempty"`
 }
 
 // TunnelManagerOption is a functional option for TunnelManager
@@ -54,10 +107,12 @@ func WithDebugMode(debug bool) TunnelManagerOption {
 // NewTunnelManager creates a new tunnel manager instance
 func NewTunnelManager(configStore *store.ConfigStore, pidStore *store.PIDStore, opts ...TunnelManagerOption) *TunnelManager {
 	tm := &TunnelManager{
-		tunnels:       make(map[string]*Tunnel),
-		configStore:   configStore,
-		pidStore:      pidStore,
-		statusChanges: make(chan TunnelStatusChange, 100),
+		tunnels:      make(map[string]*Tunnel),
+		configStore:  configStore,
+		pidStore:     pidStore,
+		subscribers:  make(map[<-chan TunnelStatusChange]*subscriber),
+		notifier:     DesktopNotify,
+		hostSettings: hostSettingsCache{entries: make(map[string]*hostSettingsEntry)},
 	}
 
 	// Apply options
@@ -65,12 +120,43 @@ func NewTunnelManager(configStore *store.ConfigStore, pidStore *store.PIDStore,
 		opt(tm)
 	}
 
-	// Initialize process manager with debug mode
-	tm.processManager = NewProcessManager(WithDebug(tm.debug))
+	// Initialize process manager with debug mode, logging SSH output under the state directory
+	pmOpts := []ProcessManagerOption{WithDebug(tm.debug)}
+	if logDir, err := store.GetLogDir(); err == nil {
+		pmOpts = append(pmOpts, WithLogDir(logDir))
+	}
+	if controlDir, err := store.GetControlDir(); err == nil {
+		pmOpts = append(pmOpts, WithControlDir(controlDir))
+	}
+	if hostKeyDir, err := store.GetHostKeyDir(); err == nil {
+		pmOpts = append(pmOpts, WithHostKeyDir(hostKeyDir))
+	}
+	if askpassDir, err := store.GetAskpassDir(); err == nil {
+		pmOpts = append(pmOpts, WithAskpassDir(askpassDir))
+	}
+	pm := NewProcessManager(pmOpts...)
+	tm.processManager = pm
+	if tm.mockConfig != nil {
+		tm.processManager = NewMockProcessManager(pm, *tm.mockConfig)
+	}
 
 	// Load tunnels from config
 	tm.loadTunnels()
 
+	// Statistics are kept with the tunnels by the SQLite storage backend
+	if statsStore, err := tm.configStore.StatsStore(); err == nil {
+		tm.statsStore = statsStore
+	} else {
+		Warn("Statistics are not saved: %v", err)
+	}
+
+	// Check the ssh executable up front rather than when a tunnel first starts
+	if path, version, err := tm.SSHVersion(); err != nil {
+		Warn("%v", err)
+	} else {
+		Debug("Using %s (OpenSSH %s)", path, version)
+	}
+
 	// Restore running tunnel states from PID store
 	tm.restoreTunnelStates()
 
@@ -102,11 +188,37 @@ func (tm *TunnelManager) GetTunnel(id string) (*Tunnel, error) {
 
 	tunnel, exists := tm.tunnels[id]
 	if !exists {
-		return nil, fmt.Errorf("tunnel not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrTunnelNotFound, id)
 	}
 	return tunnel.Clone(), nil
 }
 
+// FindTunnel returns a tunnel by ID or, failing that, by name
+func (tm *TunnelManager) FindTunnel(nameOrID string) (*Tunnel, error) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tunnel, exists := tm.tunnels[nameOrID]; exists {
+		return tunnel.Clone(), nil
+	}
+
+	var matches []*Tunnel
+	for _, tunnel := range tm.tunnels {
+		if tunnel.Name == nameOrID {
+			matches = append(matches, tunnel)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrTunnelNotFound, nameOrID)
+	case 1:
+		return matches[0].Clone(), nil
+	default:
+		return nil, fmt.Errorf("tunnel name %q is ambiguous (%d matches), use the tunnel ID", nameOrID, len(matches))
+	}
+}
+
 // AddTunnel adds a new tunnel configuration
 func (tm *TunnelManager) AddTunnel(tunnel *Tunnel) error {
 	if err := tunnel.Validate(); err != nil {
@@ -131,6 +243,46 @@ func (tm *TunnelManager) AddTunnel(tunnel *Tunnel) error {
 	return nil
 }
 
+// DuplicateTunnel adds a stopped copy of a tunnel's configuration under a new
+// ID, named "<name> (copy)", and returns it. The copy does not auto-connect,
+// as it would compete with the original for the same local port.
+func (tm *TunnelManager) DuplicateTunnel(id string) (*Tunnel, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	source, exists := tm.tunnels[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrTunnelNotFound, id)
+	}
+
+	duplicate := NewTunnel("", source.Type)
+	duplicate.applyConfig(source)
+	duplicate.Name = tm.copyName(source.Name)
+	duplicate.AutoConnect = false
+
+	tm.tunnels[duplicate.ID] = duplicate
+	if err := tm.saveTunnels(); err != nil {
+		delete(tm.tunnels, duplicate.ID)
+		return nil, fmt.Errorf("failed to save tunnel: %w", err)
+	}
+	return duplicate.Clone(), nil
+}
+
+// copyName returns "<name> (copy)", numbering it if a tunnel already has
+// that name. Callers must hold tm.mu.
+func (tm *TunnelManager) copyName(name string) string {
+	taken := make(map[string]bool, len(tm.tunnels))
+	for _, tunnel := range tm.tunnels {
+		taken[tunnel.Name] = true
+	}
+
+	candidate := name + " (copy)"
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s (copy %d)", name, i)
+	}
+	return candidate
+}
+
 // UpdateTunnel updates an existing tunnel configuration
 func (tm *TunnelManager) UpdateTunnel(tunnel *Tunnel) error {
 	if err := tunnel.Validate(); err != nil {
@@ -142,14 +294,24 @@ func (tm *TunnelManager) UpdateTunnel(tunnel *Tunnel) error {
 
 	existing, exists := tm.tunnels[tunnel.ID]
 	if !exists {
-		return fmt.Errorf("tunnel not found: %s", tunnel.ID)
+		return fmt.Errorf("%w: %s", ErrTunnelNotFound, tunnel.ID)
 	}
 
 	// Don't allow updating a running tunnel
 	if existing.Status == StatusRunning {
-		return fmt.Errorf("cannot update running tunnel")
+		return fmt.Errorf("cannot update tunnel %s: %w", existing.Name, ErrAlreadyRunning)
 	}
 
+	// Values the update leaves unchanged keep their ${VAR} references
+	if tunnel.templates == nil {
+		tunnel.templates = existing.templates
+	}
+
+	// The tunnel stays in the file it was loaded from, and linked to what
+	// it was imported from
+	tunnel.Source = existing.Source
+	tunnel.ImportedFrom = existing.ImportedFrom
+
 	tm.tunnels[tunnel.ID] = tunnel
 
 	// Save to config store
@@ -168,12 +330,12 @@ func (tm *TunnelManager) DeleteTunnel(id string) error {
 
 	tunnel, exists := tm.tunnels[id]
 	if !exists {
-		return fmt.Errorf("tunnel not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTunnelNotFound, id)
 	}
 
 	// Don't allow deleting a running tunnel
 	if tunnel.Status == StatusRunning {
-		return fmt.Errorf("cannot delete running tunnel")
+		return fmt.Errorf("cannot delete tunnel %s: %w", tunnel.Name, ErrAlreadyRunning)
 	}
 
 	delete(tm.tunnels, id)
@@ -183,39 +345,77 @@ func (tm *TunnelManager) DeleteTunnel(id string) error {
 		tm.tunnels[id] = tunnel
 		return fmt.Errorf("failed to save config: %w", err)
 	}
+	tm.addToTrashLocked(tunnel)
+
+	if tm.statsStore != nil {
+		if err := tm.statsStore.Remove(id); err != nil {
+			Debug("Failed to remove statistics of tunnel %s: %v", tunnel.Name, err)
+		}
+	}
+	if path := tm.processManager.pinnedHostsPath(id); path != "" {
+		os.Remove(path)
+	}
 
 	return nil
 }
 
 // StartTunnel starts an SSH tunnel
 func (tm *TunnelManager) StartTunnel(id string) error {
+	if remote := tm.remoteControl(); remote != nil {
+		return tm.applyRemote(id, remote.Start)
+	}
+
 	tm.mu.Lock()
 	tunnel, exists := tm.tunnels[id]
 	if !exists {
 		tm.mu.Unlock()
-		return fmt.Errorf("tunnel not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTunnelNotFound, id)
 	}
 
-	if tunnel.Status == StatusRunning {
+	if tunnel.Status == StatusRunning || tunnel.Status == StatusConnecting {
 		tm.mu.Unlock()
-		return fmt.Errorf("tunnel is already running")
+		return ErrAlreadyRunning
 	}
-
-	// Update status
 	oldStatus := tunnel.Status
+	effective := tm.withProfileDefaults(tunnel)
+
+	// Update status while still holding the lock, so a concurrent start
+	// sees this one in progress
 	tunnel.Status = StatusConnecting
+	tunnel.FailedHop = ""
 	tm.mu.Unlock()
 
 	// Notify status change
 	tm.notifyStatusChange(id, oldStatus, StatusConnecting, nil)
 
-	// Use process manager to connect
-	pidEntry, err := tm.processManager.Connect(tunnel)
+	// Fail early with the port's owner rather than an opaque ssh bind error
+	if err := tm.checkPortConflict(effective); err != nil {
+		tm.mu.Lock()
+		tunnel.Status = StatusError
+		tunnel.LastError = err
+		tm.runHooks(tunnel, HookFailure, err)
+		tm.mu.Unlock()
+		tm.recordFailure(id)
+
+		Error("FAILED to start tunnel '%s': %v", tunnel.Name, err)
+
+		tm.notifyStatusChange(id, StatusConnecting, StatusError, err)
+		return fmt.Errorf("failed to start tunnel: %w", err)
+	}
+
+	// Renew a short-lived certificate, then use process manager to connect
+	var processInfo *ProcessInfo
+	err := tm.renewCertificate(effective)
+	if err == nil {
+		processInfo, err = tm.processManager.Connect(effective)
+	}
 	if err != nil {
 		tm.mu.Lock()
 		tunnel.Status = StatusError
 		tunnel.LastError = err
+		tm.runHooks(tunnel, HookFailure, err)
 		tm.mu.Unlock()
+		tm.recordFailure(id)
 
 		// Log the failure
 		Error("FAILED to start tunnel '%s': %v", tunnel.Name, err)
@@ -226,20 +426,19 @@ func (tm *TunnelManager) StartTunnel(id string) error {
 
 	// Update tunnel state
 	tm.mu.Lock()
-	tunnel.PID = pidEntry.PID
+	tunnel.PID = processInfo.PID
 	now := time.Now()
 	tunnel.StartedAt = &now
 	tunnel.Status = StatusRunning
 	tunnel.LastError = nil
-
-	// Get process info for monitoring
-	if processInfo, exists := tm.processManager.GetProcessInfo(id); exists {
-		tunnel.process = processInfo.Cmd
-	}
+	tunnel.NotListening = ""
+	tunnel.process = processInfo.Cmd
+	tm.runHooks(tunnel, HookConnect, nil)
 	tm.mu.Unlock()
+	tm.recordConnect(id)
 
 	// Save PID for recovery
-	if err := tm.pidStore.AddPid(id, pidEntry.PID); err != nil {
+	if err := tm.pidStore.AddPid(id, processInfo.PID); err != nil {
 		// Log error but don't fail the start
 		if tm.debug {
 			fmt.Printf("Warning: failed to save PID: %v\n", err)
@@ -250,27 +449,32 @@ func (tm *TunnelManager) StartTunnel(id string) error {
 	tm.notifyStatusChange(id, StatusConnecting, StatusRunning, nil)
 
 	// Monitor the process in a goroutine
-	go tm.monitorTunnel(id)
+	go tm.monitorTunnel(id, processInfo)
 
 	return nil
 }
 
 // StopTunnel stops a running SSH tunnel
 func (tm *TunnelManager) StopTunnel(id string) error {
+	if remote := tm.remoteControl(); remote != nil {
+		return tm.applyRemote(id, remote.Stop)
+	}
+
 	tm.mu.Lock()
 	tunnel, exists := tm.tunnels[id]
 	if !exists {
 		tm.mu.Unlock()
-		return fmt.Errorf("tunnel not found: %s", id)
+		return fmt.Errorf("%w: %s", ErrTunnelNotFound, id)
 	}
 
 	if tunnel.Status != StatusRunning {
 		tm.mu.Unlock()
-		return fmt.Errorf("tunnel is not running")
+		return ErrNotRunning
 	}
 
 	pid := tunnel.PID
 	oldStatus := tunnel.Status
+	multiplex := tunnel.Multiplex
 	tm.mu.Unlock()
 
 	// Use process manager to disconnect
@@ -281,13 +485,23 @@ func (tm *TunnelManager) StopTunnel(id string) error {
 		}
 	}
 
+	// A shared master connection outlives the tunnel's ssh, so release its ports
+	if multiplex {
+		if err := tm.processManager.CancelSharedForwards(tm.effectiveTunnel(tunnel)); err != nil {
+			Debug("Tunnel %s: %v", tunnel.Name, err)
+		}
+	}
+
 	// Update tunnel state
 	tm.mu.Lock()
+	startedAt := tunnel.StartedAt
 	tunnel.Status = StatusStopped
 	tunnel.process = nil
 	tunnel.PID = 0
 	tunnel.StartedAt = nil
+	tm.runHooks(tunnel, HookDisconnect, nil)
 	tm.mu.Unlock()
+	tm.recordUptime(id, startedAt)
 
 	// Remove PID from store
 	tm.pidStore.RemovePid(id)
@@ -316,7 +530,11 @@ func (tm *TunnelManager) RestartTunnel(id string) error {
 	}
 
 	// Start the tunnel
-	return tm.StartTunnel(id)
+	if err := tm.StartTunnel(id); err != nil {
+		return err
+	}
+	tm.recordRestart(id)
+	return nil
 }
 
 // StartAutoConnectTunnels starts all tunnels marked for auto-connect
@@ -347,24 +565,62 @@ func (tm *TunnelManager) StopAllTunnels(ctx context.Context) error {
 		}
 	}
 
-	// Update all tunnel states
+	// Collect running tunnels, then stop each the way StopTunnel does, so
+	// process, stats and PID store IO happen outside tm.mu
 	tm.mu.Lock()
-	for id, tunnel := range tm.tunnels {
+	var running []*Tunnel
+	for _, tunnel := range tm.tunnels {
 		if tunnel.Status == StatusRunning {
-			oldStatus := tunnel.Status
-			tunnel.Status = StatusStopped
-			tunnel.process = nil
-			tunnel.PID = 0
-			tunnel.StartedAt = nil
+			running = append(running, tunnel)
+		}
+	}
+	tm.mu.Unlock()
 
-			// Remove from PID store
-			tm.pidStore.RemovePid(id)
+	for _, tunnel := range running {
+		tm.mu.RLock()
+		id := tunnel.ID
+		pid := tunnel.PID
+		multiplex := tunnel.Multiplex
+		tm.mu.RUnlock()
+
+		// Tunnels restored from the PID store are not owned by the
+		// process manager, so terminate them by PID
+		if pid > 0 && tm.processManager.IsProcessRunning(pid) {
+			if err := tm.processManager.Disconnect(id, pid); err != nil {
+				Error("Failed to stop tunnel %s: %v", tunnel.Name, err)
+				continue
+			}
+		}
 
-			// Notify status change
-			tm.notifyStatusChange(id, oldStatus, StatusStopped, nil)
+		// A shared master connection outlives the tunnel's ssh, so release its ports
+		if multiplex {
+			if err := tm.processManager.CancelSharedForwards(tm.effectiveTunnel(tunnel)); err != nil {
+				Debug("Tunnel %s: %v", tunnel.Name, err)
+			}
 		}
+
+		tm.mu.Lock()
+		if tunnel.Status != StatusRunning {
+			// Stopped meanwhile by someone else
+			tm.mu.Unlock()
+			continue
+		}
+		oldStatus := tunnel.Status
+		startedAt := tunnel.StartedAt
+		tunnel.Status = StatusStopped
+		tunnel.process = nil
+		tunnel.PID = 0
+		tunnel.StartedAt = nil
+		tm.runHooks(tunnel, HookDisconnect, nil)
+		tm.mu.Unlock()
+		tm.recordUptime(id, startedAt)
+
+		// Remove from PID store
+		tm.pidStore.RemovePid(id)
+
+		// Notify status change
+		tm.notifyStatusChange(id, oldStatus, StatusStopped, nil)
 	}
-	tm.mu.Unlock()
 
 	return nil
 }
@@ -389,6 +645,73 @@ func (tm *TunnelManager) GetTunnelsByProfile(profileName string) []*Tunnel {
 	return tunnels
 }
 
+// WaitReady blocks until the tunnel is running and, for local and dynamic
+// forwards, its local port accepts connections
+func (tm *TunnelManager) WaitReady(ctx context.Context, id string) error {
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		tm.RefreshStates()
+
+		tunnel, err := tm.GetTunnel(id)
+		if err != nil {
+			return err
+		}
+
+		switch tunnel.Status {
+		case StatusError:
+			if tunnel.LastError != nil {
+				return fmt.Errorf("tunnel %s failed: %w", tunnel.Name, tunnel.LastError)
+			}
+			return fmt.Errorf("tunnel %s failed", tunnel.Name)
+		case StatusRunning:
+			address, hasListener := tunnel.DialAddress()
+			if !hasListener {
+				return nil
+			}
+			dialer := net.Dialer{Timeout: time.Second}
+			if conn, err := dialer.DialContext(ctx, "tcp", address); err == nil {
+				conn.Close()
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for tunnel %s: %w", tunnel.Name, ctx.Err())
+		}
+	}
+}
+
+// GetProfileNames returns the names of configured profiles and profiles referenced
+// by tunnels, sorted, always including "default"
+func (tm *TunnelManager) GetProfileNames() []string {
+	seen := map[string]bool{"default": true}
+
+	if config, err := tm.configStore.LoadConfig(); err == nil {
+		for _, p := range config.Profiles {
+			seen[p.Name] = true
+		}
+	}
+
+	tm.mu.RLock()
+	for _, tunnel := range tm.tunnels {
+		if tunnel.Profile != "" {
+			seen[tunnel.Profile] = true
+		}
+	}
+	tm.mu.RUnlock()
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // StartProfileTunnels starts all tunnels in a profile
 func (tm *TunnelManager) StartProfileTunnels(profileName string) error {
 	tunnels := tm.GetTunnelsByProfile(profileName)
@@ -447,44 +770,195 @@ func (tm *TunnelManager) AutoConnectProfile(profileName string) {
 	}
 }
 
-// GetStatusChanges returns the channel for status change notifications
-func (tm *TunnelManager) GetStatusChanges() <-chan TunnelStatusChange {
-	return tm.statusChanges
+// setHealth records a health check result, reporting whether the health changed
+func (tm *TunnelManager) setHealth(id string, health TunnelHealth, err error) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tunnel, exists := tm.tunnels[id]
+	if !exists {
+		return false
+	}
+
+	changed := tunnel.Health != health
+	tunnel.Health = health
+	tunnel.HealthError = err
+	if health == HealthUnknown {
+		tunnel.HealthCheckedAt = nil
+	} else {
+		now := time.Now()
+		tunnel.HealthCheckedAt = &now
+	}
+	return changed
+}
+
+// setNotListening records the local address of a tunnel that refused
+// connections, or clears it, reporting whether it changed
+func (tm *TunnelManager) setNotListening(id, address string) bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tunnel, exists := tm.tunnels[id]
+	if !exists {
+		return false
+	}
+
+	changed := tunnel.NotListening != address
+	tunnel.NotListening = address
+	return changed
 }
 
-// monitorTunnel monitors a running tunnel process
-func (tm *TunnelManager) monitorTunnel(id string) {
-	// Wait for process to be removed from process manager
-	for {
-		time.Sleep(1 * time.Second)
+// setLatency records a latency measurement for tunnels sharing a first hop
+func (tm *TunnelManager) setLatency(ids []string, latency time.Duration, err error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
 
-		// Check if process still exists in process manager
-		if _, exists := tm.processManager.GetProcessInfo(id); !exists {
-			break
+	for _, id := range ids {
+		if tunnel, exists := tm.tunnels[id]; exists {
+			tunnel.Latency = latency
+			tunnel.LatencyError = err
 		}
 	}
+}
+
+// LogPath returns the path of a tunnel's SSH output log
+func (tm *TunnelManager) LogPath(id string) (string, error) {
+	if _, err := tm.GetTunnel(id); err != nil {
+		return "", err
+	}
+	path := tm.processManager.LogPath(id)
+	if path == "" {
+		return "", fmt.Errorf("output logging is disabled")
+	}
+	return path, nil
+}
+
+// RecentOutput returns the most recent SSH output lines captured by this
+// process for a tunnel
+func (tm *TunnelManager) RecentOutput(id string) []string {
+	return tm.processManager.RecentOutput(id)
+}
+
+// StatusEvent converts a status change into its serializable form, resolving the tunnel name
+func (tm *TunnelManager) StatusEvent(change TunnelStatusChange) StatusEvent {
+	event := StatusEvent{
+		Time:      change.Time,
+		TunnelID:  change.TunnelID,
+		OldStatus: change.OldStatus,
+		NewStatus: change.NewStatus,
+	}
+	if tunnel, err := tm.GetTunnel(change.TunnelID); err == nil {
+		event.TunnelName = tunnel.Name
+	}
+	if change.Error != nil {
+		event.Error = change.Error.Error()
+	}
+	return event
+}
+
+// RefreshStates reconciles tunnel states with the PID store so that tunnels
+// started or stopped by another tunnelman process are reflected here
+func (tm *TunnelManager) RefreshStates() {
+	pids, err := tm.pidStore.LoadPids()
+	if err != nil {
+		return
+	}
+
+	var changes []TunnelStatusChange
+	tm.mu.Lock()
+	for id, tunnel := range tm.tunnels {
+		// Processes owned by this instance are tracked by monitorTunnel
+		if _, owned := tm.processManager.GetProcessInfo(id); owned {
+			continue
+		}
+		if tunnel.Status == StatusConnecting {
+			continue
+		}
+
+		pidInfo, tracked := pids.Pids[id]
+		switch {
+		case tracked && tunnel.Status != StatusRunning:
+			changes = append(changes, TunnelStatusChange{TunnelID: id, OldStatus: tunnel.Status, NewStatus: StatusRunning})
+			tunnel.Status = StatusRunning
+			tunnel.PID = pidInfo.PID
+			tunnel.LastError = nil
+			if startTime, err := time.Parse(time.RFC3339, pidInfo.Started); err == nil {
+				tunnel.StartedAt = &startTime
+			} else {
+				now := time.Now()
+				tunnel.StartedAt = &now
+			}
+
+		case !tracked && tunnel.Status == StatusRunning:
+			changes = append(changes, TunnelStatusChange{TunnelID: id, OldStatus: tunnel.Status, NewStatus: StatusStopped})
+			tunnel.Status = StatusStopped
+			tunnel.process = nil
+			tunnel.PID = 0
+			tunnel.StartedAt = nil
+		}
+	}
+	tm.mu.Unlock()
+
+	for _, change := range changes {
+		tm.notifyStatusChange(change.TunnelID, change.OldStatus, change.NewStatus, nil)
+	}
+}
+
+// monitorTunnel waits for a tunnel's ssh process to exit and updates the
+// tunnel if it died on its own. Deliberate stops are handled by their callers.
+func (tm *TunnelManager) monitorTunnel(id string, processInfo *ProcessInfo) {
+	exit := processInfo.Exit()
+	if !exit.Unexpected {
+		return
+	}
 
 	tm.mu.Lock()
 	tunnel, exists := tm.tunnels[id]
-	if !exists {
+	if !exists || tunnel.Status != StatusRunning || tunnel.PID != processInfo.PID {
 		tm.mu.Unlock()
 		return
 	}
 
 	oldStatus := tunnel.Status
 
-	// Only update status if it's still running
-	if tunnel.Status == StatusRunning {
-		tunnel.Status = StatusStopped
-		tunnel.process = nil
-		tunnel.PID = 0
-		tunnel.StartedAt = nil
+	// Record where a chained tunnel broke
+	if chain := tm.withProfileDefaults(tunnel).HopChain(); len(chain) > 1 {
+		if lines, err := ReadLogTail(tm.processManager.LogPath(id), 50); err == nil {
+			tunnel.FailedHop = findFailedHop(lastRunOutput(lines), chain)
+		}
+	}
+
+	startedAt := tunnel.StartedAt
+	tunnel.Status = StatusStopped
+	tunnel.process = nil
+	tunnel.PID = 0
+	tunnel.StartedAt = nil
+
+	// ssh died on its own; work out why from its output, falling back to how it exited
+	if failure := classifySSHFailure(lastRunOutput(tm.processManager.RecentOutput(id)), exit.ExitCode); failure != nil {
+		if failure.Message == "" && exit.Err != nil {
+			failure.Message = exit.Err.Error()
+		}
+		tunnel.Status = StatusError
+		tunnel.LastError = failure
+		Error("Tunnel '%s' failed: %v", tunnel.Name, failure)
 	}
 
+	tm.runHooks(tunnel, HookDisconnect, nil)
+	if tunnel.Status == StatusError {
+		tm.runHooks(tunnel, HookFailure, tunnel.LastError)
+	}
+	tm.notifyDropped(tunnel)
+
 	newStatus := tunnel.Status
 	lastError := tunnel.LastError
 	tm.mu.Unlock()
 
+	tm.recordUptime(id, startedAt)
+	if newStatus == StatusError {
+		tm.recordFailure(id)
+	}
+
 	// Remove PID from store
 	tm.pidStore.RemovePid(id)
 
@@ -494,88 +968,326 @@ func (tm *TunnelManager) monitorTunnel(id string) {
 	}
 }
 
-// notifyStatusChange sends a status change notification
+// notifyStatusChange sends a status change notification to all subscribers
 func (tm *TunnelManager) notifyStatusChange(tunnelID string, oldStatus, newStatus TunnelStatus, err error) {
-	select {
-	case tm.statusChanges <- TunnelStatusChange{
+	tm.publish(TunnelStatusChange{
 		TunnelID:  tunnelID,
 		OldStatus: oldStatus,
 		NewStatus: newStatus,
 		Error:     err,
-	}:
-	default:
-		// Channel full, skip notification
-	}
+		Time:      time.Now(),
+	})
 }
 
 // loadTunnels loads tunnel configurations from the config store
 func (tm *TunnelManager) loadTunnels() {
+	tm.configStamp = tm.configStore.Stamp()
 	config, err := tm.configStore.LoadConfig()
 	if err != nil {
-		// If config doesn't exist, start with empty tunnels
+		// Start with no tunnels; a missing config is not an error, and an
+		// encrypted one that cannot be decrypted is not overwritten
+		Error("Failed to load config: %v", err)
 		return
 	}
 
-	// Convert TunnelConfig to Tunnel
-	for _, tc := range config.Tunnels {
-		// Map mode values for backward compatibility
-		mode := tc.Mode
-		if mode == "forward" {
-			mode = "local"
-		} else if mode == "reverse" {
-			mode = "remote"
-		}
+	tm.setConfigIssues(ValidateConfig(config), true)
+	tunnels, repaired := tunnelsFromConfig(config)
+	tm.tunnels = tunnels
+	tm.includes = config.Includes
+	tm.storage = config.Storage
+	tm.setDefaults(config.Defaults) // a missing ssh is reported by NewTunnelManager
+	tm.setProfileDefaults(config.Profiles)
+
+	if config.MigratedFrom != "" {
+		Info("Migrated config from version %s to %s, keeping the original as %s",
+			config.MigratedFrom, store.ConfigVersion, tm.configStore.MigrationBackupPath(config.MigratedFrom))
+	}
 
-		tunnel := &Tunnel{
-			ID:          tc.ID,
-			Name:        tc.Name,
-			SSHHost:     tc.Host,
-			LocalPort:   tc.LocalPort,
-			RemotePort:  tc.RemotePort,
-			Type:        TunnelType(mode),
-			ExtraArgs:   tc.Options,
-			Profile:     tc.Profile,
-			AutoConnect: tc.AutoConnect,
-			Status:      StatusStopped,
-			LocalHost:   "0.0.0.0",
+	// Persist repaired IDs so they stay stable across restarts
+	if repaired {
+		if err := tm.saveTunnels(); err != nil {
+			Error("Failed to save repaired tunnel IDs: %v", err)
 		}
+	}
+}
+
+// ReloadConfig re-reads tunnel configurations from the config store. Tunnels
+// that are still configured keep their runtime state, and running tunnels that
+// were removed from the config stay managed until they stop.
+func (tm *TunnelManager) ReloadConfig() error {
+	// Take the stamp first, so a change made while loading is seen later
+	stamp := tm.configStore.Stamp()
+	tm.mu.Lock()
+	tm.configStamp = stamp
+	tm.mu.Unlock()
+
+	config, err := tm.configStore.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	issues := ValidateConfig(config)
+	loaded, _ := tunnelsFromConfig(config)
 
-		// Set default profile if not specified
-		if tunnel.Profile == "" {
-			tunnel.Profile = "default"
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if err := tm.setDefaults(config.Defaults); err != nil {
+		Warn("%v", err)
+	}
+	tm.setProfileDefaults(config.Profiles)
+	tm.setConfigIssues(issues, true)
+	tm.includes = config.Includes
+	tm.storage = config.Storage
+
+	for id, fresh := range loaded {
+		if existing, exists := tm.tunnels[id]; exists {
+			existing.applyConfig(fresh)
+		} else {
+			tm.tunnels[id] = fresh
 		}
+	}
+	for id, existing := range tm.tunnels {
+		if _, exists := loaded[id]; !exists && existing.Status != StatusRunning && existing.Status != StatusConnecting {
+			delete(tm.tunnels, id)
+		}
+	}
 
-		// Set default remote host for local forward
-		if tunnel.Type == LocalForward && tunnel.RemoteHost == "" {
-			tunnel.RemoteHost = "127.0.0.1"
+	return nil
+}
+
+// setDefaults applies the global defaults from the config, returning an error
+// if the configured ssh executable does not exist. The caller must hold tm.mu
+// or be initializing tm.
+func (tm *TunnelManager) setDefaults(defaults *store.Defaults) error {
+	tm.defaults = defaults
+	tm.processManager.SetConnectionDefaults(connectionDefaultsFromConfig(defaults))
+
+	var sshPath string
+	if defaults != nil {
+		sshPath = defaults.SSHPath
+	}
+	return tm.processManager.SetSSHPath(sshPath)
+}
+
+// SSHCommand returns the ssh command line a tunnel is started with
+func (tm *TunnelManager) SSHCommand(tunnel *Tunnel) []string {
+	tunnel = tm.effectiveTunnel(tunnel)
+	return append([]string{tm.processManager.SSHPath()}, tm.processManager.buildSSHArgs(tunnel)...)
+}
+
+// SSHVersion returns the path and version of the ssh executable tunnels are
+// started with, or an error if it does not exist
+func (tm *TunnelManager) SSHVersion() (string, SSHVersion, error) {
+	version, err := tm.processManager.SSHVersion()
+	return tm.processManager.SSHPath(), version, err
+}
+
+// tunnelsFromConfig converts stored tunnel configurations into tunnels keyed by
+// ID, reporting whether any IDs had to be regenerated
+func tunnelsFromConfig(config *store.AppConfig) (map[string]*Tunnel, bool) {
+	tunnels := make(map[string]*Tunnel)
+	repaired := false
+	for _, tc := range config.Tunnels {
+		tunnel := tunnelFromConfig(tc)
+
+		// Duplicate IDs would silently overwrite an earlier tunnel, so give the
+		// later one a fresh ID to keep both
+		if existing, exists := tunnels[tunnel.ID]; exists || tunnel.ID == "" {
+			newID := generateID()
+			if exists {
+				Warn("Duplicate tunnel ID %q for '%s' (already used by '%s'), assigning new ID %s",
+					tunnel.ID, tunnel.Name, existing.Name, newID)
+			} else {
+				Warn("Tunnel '%s' has no ID, assigning new ID %s", tunnel.Name, newID)
+			}
+			tunnel.ID = newID
+			repaired = true
 		}
 
-		tm.tunnels[tunnel.ID] = tunnel
+		tunnels[tunnel.ID] = tunnel
+	}
+
+	return tunnels, repaired
+}
+
+// tunnelFromConfig converts a stored tunnel configuration into a stopped
+// tunnel, expanding ${VAR} references
+func tunnelFromConfig(tc store.TunnelConfig) *Tunnel {
+	tunnel, err := parseTunnelConfig(tc)
+	if err != nil {
+		Warn("Tunnel '%s': %v", tc.Name, err)
+	}
+	return tunnel
+}
+
+// parseTunnelConfig converts a stored tunnel configuration like
+// tunnelFromConfig, returning the error of expanding its references
+func parseTunnelConfig(tc store.TunnelConfig) (*Tunnel, error) {
+	templates, err := expandConfig(&tc)
+
+	// Map mode values for backward compatibility
+	mode := tc.Mode
+	if mode == "forward" {
+		mode = "local"
+	} else if mode == "reverse" {
+		mode = "remote"
+	}
+
+	tunnel := &Tunnel{
+		ID:          tc.ID,
+		Name:        tc.Name,
+		SSHHost:     tc.Host,
+		JumpHost:    tc.JumpHost,
+		LocalPort:   tc.LocalPort,
+		RemoteHost:  tc.RemoteHost,
+		RemotePort:  tc.RemotePort,
+		Type:        TunnelType(mode),
+		ExtraArgs:   tc.Options,
+		Profile:     tc.Profile,
+		Tags:        tc.Tags,
+		Description: tc.Description,
+		AutoConnect: tc.AutoConnect,
+		Status:      StatusStopped,
+		LocalHost:   tc.BindAddress,
+
+		RemoteBindAddress: tc.RemoteBindAddress,
+		Compression:       tc.Compression,
+		Multiplex:         tc.Multiplex,
+		IdentityFile:      tc.IdentityFile,
+		Secret:            tc.Secret,
+		HostKey:           tc.HostKey,
+
+		CertificateFile:    tc.CertificateFile,
+		CertificateCommand: tc.CertificateCommand,
+
+		ServerAliveInterval:  tc.ServerAliveInterval,
+		ServerAliveCountMax:  tc.ServerAliveCountMax,
+		ExitOnForwardFailure: tc.ExitOnForwardFailure,
+
+		Hooks: hooksFromConfig(tc.Hooks),
+
+		ImportedFrom: tc.ImportedFrom,
+
+		Source:    tc.Source,
+		templates: templates,
+	}
+
+	// Configs written before the bind address was stored always listened
+	// on all interfaces
+	if tunnel.LocalHost == "" {
+		tunnel.LocalHost = "0.0.0.0"
+	}
+
+	for _, fc := range tc.Forwards {
+		tunnel.Forwards = append(tunnel.Forwards, Forward{
+			Type:       TunnelType(fc.Mode),
+			LocalHost:  fc.BindAddress,
+			LocalPort:  fc.LocalPort,
+			RemoteHost: fc.RemoteHost,
+			RemotePort: fc.RemotePort,
+
+			RemoteBindAddress: fc.RemoteBindAddress,
+		})
+	}
+
+	// Set default profile if not specified
+	if tunnel.Profile == "" {
+		tunnel.Profile = "default"
+	}
+
+	// Set default remote host for local forward
+	if tunnel.Type == LocalForward && tunnel.RemoteHost == "" {
+		tunnel.RemoteHost = "127.0.0.1"
 	}
+
+	return tunnel, err
+}
+
+// configFromTunnel converts a tunnel into its stored configuration
+func configFromTunnel(t *Tunnel) store.TunnelConfig {
+	var forwards []store.ForwardConfig
+	for _, f := range t.Forwards {
+		forwards = append(forwards, store.ForwardConfig{
+			Mode:        string(f.Type),
+			BindAddress: f.LocalHost,
+			LocalPort:   f.LocalPort,
+			RemoteHost:  f.RemoteHost,
+			RemotePort:  f.RemotePort,
+
+			RemoteBindAddress: f.RemoteBindAddress,
+		})
+	}
+
+	tc := store.TunnelConfig{
+		ID:          t.ID,
+		Name:        t.Name,
+		Host:        t.SSHHost,
+		JumpHost:    t.JumpHost,
+		LocalPort:   t.LocalPort,
+		RemoteHost:  t.RemoteHost,
+		RemotePort:  t.RemotePort,
+		Mode:        string(t.Type),
+		Options:     t.ExtraArgs,
+		Forwards:    forwards,
+		Profile:     t.Profile,
+		Tags:        t.Tags,
+		Description: t.Description,
+		AutoConnect: t.AutoConnect,
+
+		BindAddress:       t.LocalHost,
+		RemoteBindAddress: t.RemoteBindAddress,
+		Compression:       t.Compression,
+		Multiplex:         t.Multiplex,
+		IdentityFile:      t.IdentityFile,
+		Secret:            t.Secret,
+		HostKey:           t.HostKey,
+
+		CertificateFile:    t.CertificateFile,
+		CertificateCommand: t.CertificateCommand,
+
+		ServerAliveInterval:  t.ServerAliveInterval,
+		ServerAliveCountMax:  t.ServerAliveCountMax,
+		ExitOnForwardFailure: t.ExitOnForwardFailure,
+
+		Hooks: t.Hooks.config(),
+
+		ImportedFrom: t.ImportedFrom,
+	}
+	t.templates.apply(&tc)
+	tc.Source = t.Source
+	return tc
 }
 
 // saveTunnels saves tunnel configurations to the config store
 func (tm *TunnelManager) saveTunnels() error {
+	// Profiles declared in the config keep their settings, even without tunnels
+	var declared []store.Profile
+	if config, err := tm.configStore.LoadConfig(); err == nil {
+		declared = config.Profiles
+	}
+	return tm.saveTunnelsWithProfiles(declared)
+}
 
+// saveTunnelsWithProfiles saves the tunnel configurations along with the
+// declared profiles, adding those tunnels reference but nothing declares
+func (tm *TunnelManager) saveTunnelsWithProfiles(declared []store.Profile) error {
 	config := &store.AppConfig{
-		Version: "1.0",
+		Version:  store.ConfigVersion,
+		Defaults: tm.defaults,
+		Includes: tm.includes,
+		Storage:  tm.storage,
 	}
 
-	// Convert tunnels to TunnelConfig
+	// Convert tunnels to TunnelConfig, in ID order so that saving unchanged
+	// tunnels writes the same files
 	var tunnelConfigs []store.TunnelConfig
 	for _, t := range tm.tunnels {
-		tunnelConfigs = append(tunnelConfigs, store.TunnelConfig{
-			ID:          t.ID,
-			Name:        t.Name,
-			Host:        t.SSHHost,
-			LocalPort:   t.LocalPort,
-			RemotePort:  t.RemotePort,
-			Mode:        string(t.Type),
-			Options:     t.ExtraArgs,
-			Profile:     t.Profile,
-			AutoConnect: t.AutoConnect,
-		})
+		tunnelConfigs = append(tunnelConfigs, configFromTunnel(t))
 	}
+	sort.Slice(tunnelConfigs, func(i, j int) bool {
+		return tunnelConfigs[i].ID < tunnelConfigs[j].ID
+	})
 	config.Tunnels = tunnelConfigs
 
 	// Collect unique profiles from tunnels
@@ -587,9 +1299,24 @@ func (tm *TunnelManager) saveTunnels() error {
 		}
 	}
 
-	// Convert profile map to slice
 	var profiles []store.Profile
+	kept := make(map[string]bool)
+	for _, p := range declared {
+		if p.Name == "" || kept[p.Name] {
+			continue
+		}
+		kept[p.Name] = true
+		profiles = append(profiles, p)
+		delete(profileMap, p.Name)
+	}
+
+	// Profiles only referenced by tunnels are added in name order
+	names := make([]string, 0, len(profileMap))
 	for name := range profileMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
 		profiles = append(profiles, store.Profile{
 			Name:        name,
 			Description: fmt.Sprintf("%s profile", name),
@@ -597,7 +1324,31 @@ func (tm *TunnelManager) saveTunnels() error {
 	}
 	config.Profiles = profiles
 
-	return tm.configStore.SaveConfig(config)
+	if err := tm.configStore.SaveConfig(config); err != nil {
+		return err
+	}
+	tm.configStamp = tm.configStore.Stamp()
+	tm.setProfileDefaults(profiles)
+	tm.setConfigIssues(ValidateConfig(config), false)
+	return nil
+}
+
+// setConfigIssues records the problems found in the config, optionally
+// logging how many there are. The caller must hold tm.mu or be
+// initializing tm.
+func (tm *TunnelManager) setConfigIssues(issues []ConfigIssue, log bool) {
+	tm.configIssues = issues
+	if log && len(issues) > 0 {
+		Warn("Config has %d problem(s), run 'tunnelman validate' for details", len(issues))
+	}
+}
+
+// ConfigIssues returns the problems found in the config when it was last
+// loaded or saved
+func (tm *TunnelManager) ConfigIssues() []ConfigIssue {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	return slices.Clone(tm.configIssues)
 }
 
 // restoreTunnelStates attempts to restore running tunnel states from PID store
@@ -616,14 +1367,7 @@ func (tm *TunnelManager) restoreTunnelStates() {
 		}
 
 		// Check if process is still running
-		process, err := os.FindProcess(pidInfo.PID)
-		if err != nil {
-			tm.pidStore.RemovePid(tunnelID)
-			continue
-		}
-
-		// Send signal 0 to check if process exists
-		if err := process.Signal(syscall.Signal(0)); err != nil {
+		if !store.IsProcessRunning(pidInfo.PID) {
 			// Process doesn't exist
 			tm.pidStore.RemovePid(tunnelID)
 		} else {
@@ -643,6 +1387,16 @@ func (tm *TunnelManager) restoreTunnelStates() {
 
 // ImportFromSSHConfig imports tunnel configurations from SSH config for a specific host
 func (tm *TunnelManager) ImportFromSSHConfig(hostAlias string) ([]*Tunnel, error) {
+	tunnels, err := tm.SSHConfigHostTunnels(hostAlias)
+	if err != nil {
+		return nil, err
+	}
+	return tm.AddImportedTunnels(tunnels, "")
+}
+
+// SSHConfigHostTunnels returns the tunnels an import of a host from the SSH
+// config would create, without adding them
+func (tm *TunnelManager) SSHConfigHostTunnels(hostAlias string) ([]*Tunnel, error) {
 	parser := NewSSHConfigParser()
 	hostConfig, err := parser.ParseHost(hostAlias)
 	if err != nil {
@@ -658,7 +1412,41 @@ func (tm *TunnelManager) ImportFromSSHConfig(hostAlias string) ([]*Tunnel, error
 	if len(tunnels) == 0 {
 		return nil, fmt.Errorf("no tunnel configurations found for host %s", hostAlias)
 	}
+	return tunnels, nil
+}
 
+// DiscoverSSHConfigTunnels returns the tunnels of every host in the SSH
+// config with forward directives, in the order of the config. Tunnels
+// already imported have the ID of an existing tunnel.
+func (tm *TunnelManager) DiscoverSSHConfigTunnels() ([]*Tunnel, error) {
+	hosts, err := tm.LoadSSHConfigHosts()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := NewSSHConfigParser()
+	var tunnels []*Tunnel
+	seen := make(map[string]bool)
+	for _, host := range hosts {
+		if seen[host] {
+			continue
+		}
+		seen[host] = true
+		hostConfig, err := parser.ParseHost(host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SSH config: %w", err)
+		}
+		if hostConfig != nil {
+			tunnels = append(tunnels, hostConfig.ConvertToTunnels()...)
+		}
+	}
+	return tunnels, nil
+}
+
+// AddImportedTunnels adds tunnels, such as those discovered in the SSH
+// config, in one save, skipping those whose ID already exists. A non-empty
+// profile is set on the imported tunnels.
+func (tm *TunnelManager) AddImportedTunnels(tunnels []*Tunnel, profile string) ([]*Tunnel, error) {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
@@ -666,6 +1454,9 @@ func (tm *TunnelManager) ImportFromSSHConfig(hostAlias string) ([]*Tunnel, error
 	for _, tunnel := range tunnels {
 		// Check if tunnel with same ID already exists
 		if _, exists := tm.tunnels[tunnel.ID]; !exists {
+			if profile != "" {
+				tunnel.Profile = profile
+			}
 			tm.tunnels[tunnel.ID] = tunnel
 			imported = append(imported, tunnel)
 		}
@@ -705,12 +1496,10 @@ func (tm *TunnelManager) LoadSSHConfigHosts() ([]string, error) {
 	var hosts []string
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if strings.HasPrefix(strings.ToLower(line), "host ") {
-			hostLine := strings.TrimSpace(line[5:])
-			for _, h := range strings.Fields(hostLine) {
-				// Skip wildcards and patterns
-				if !strings.Contains(h, "*") && !strings.Contains(h, "?") {
+		if key, value := splitConfigLine(scanner.Text()); key == "host" {
+			for _, h := range strings.Fields(value) {
+				// Skip wildcards, patterns and negations
+				if !strings.ContainsAny(h, "*?!") {
 					hosts = append(hosts, h)
 				}
 			}
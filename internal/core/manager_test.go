@@ -0,0 +1,366 @@
+// Package core provides tunnel manager tests.
+package core
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// newTestManager creates a tunnel manager backed by temporary config and state files
+func newTestManager(t *testing.T, configJSON string, opts ...TunnelManagerOption) (*TunnelManager, *store.ConfigStore) {
+	t.Helper()
+
+	dir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(dir, "state"))
+
+	configPath := filepath.Join(dir, "config.json")
+	if configJSON != "" {
+		if err := os.WriteFile(configPath, []byte(configJSON), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	configStore, err := store.NewConfigStore(configPath)
+	if err != nil {
+		t.Fatalf("Failed to create config store: %v", err)
+	}
+	pidStore, err := store.NewPIDStore()
+	if err != nil {
+		t.Fatalf("Failed to create PID store: %v", err)
+	}
+
+	return NewTunnelManager(configStore, pidStore, opts...), configStore
+}
+
+// TestLoadTunnelsRepairsDuplicateIDs tests that duplicate IDs in config are regenerated
+func TestLoadTunnelsRepairsDuplicateIDs(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "dup", "name": "First", "host": "a.example.com", "localPort": 8080, "remotePort": 80, "mode": "local"},
+    {"id": "dup", "name": "Second", "host": "b.example.com", "localPort": 8081, "remotePort": 81, "mode": "local"},
+    {"id": "unique", "name": "Third", "host": "c.example.com", "localPort": 1080, "mode": "dynamic"}
+  ]
+}`
+
+	tm, configStore := newTestManager(t, configJSON)
+
+	tunnels := tm.GetTunnels()
+	if len(tunnels) != 3 {
+		t.Fatalf("Expected 3 tunnels, got %d", len(tunnels))
+	}
+
+	ids := make(map[string]string)
+	for _, tunnel := range tunnels {
+		if other, exists := ids[tunnel.ID]; exists {
+			t.Errorf("Tunnels %q and %q share ID %q", other, tunnel.Name, tunnel.ID)
+		}
+		ids[tunnel.ID] = tunnel.Name
+	}
+
+	if ids["dup"] != "First" {
+		t.Errorf("Expected first tunnel to keep ID 'dup', got %q", ids["dup"])
+	}
+	if ids["unique"] != "Third" {
+		t.Errorf("Expected unique ID to be preserved, got %q", ids["unique"])
+	}
+
+	// Repaired IDs should be persisted
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	for _, tc := range config.Tunnels {
+		if _, exists := ids[tc.ID]; !exists {
+			t.Errorf("Saved tunnel %q has unexpected ID %q", tc.Name, tc.ID)
+		}
+	}
+}
+
+// TestGenerateID tests that generated IDs are unique UUIDs
+func TestGenerateID(t *testing.T) {
+	uuidPattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id := generateID()
+		if !uuidPattern.MatchString(id) {
+			t.Fatalf("ID %q is not a UUID v4", id)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate ID generated: %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+// TestDuplicateTunnel tests that a duplicate is a saved, independent copy
+// with a new ID and name
+func TestDuplicateTunnel(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.example.com", "localPort": 15432, "remotePort": 5432, "mode": "local", "auto_connect": true,
+     "tags": ["prod"], "options": ["-v"], "forwards": [{"mode": "local", "localPort": 16379, "remoteHost": "cache", "remotePort": 6379}]}
+  ]
+}`
+	tm, configStore := newTestManager(t, configJSON)
+
+	duplicate, err := tm.DuplicateTunnel("db")
+	if err != nil {
+		t.Fatalf("DuplicateTunnel failed: %v", err)
+	}
+	if duplicate.ID == "db" || duplicate.Name != "db (copy)" || duplicate.AutoConnect || duplicate.Status != StatusStopped {
+		t.Errorf("Unexpected duplicate: id %s, name %q, auto-connect %v, status %s", duplicate.ID, duplicate.Name, duplicate.AutoConnect, duplicate.Status)
+	}
+	if duplicate.SSHHost != "db.example.com" || duplicate.LocalPort != 15432 || len(duplicate.Forwards) != 1 || !duplicate.HasTag("prod") {
+		t.Errorf("Expected the configuration to be copied, got %+v", duplicate)
+	}
+
+	// Changing the copy leaves the original alone
+	duplicate.Tags[0] = "staging"
+	duplicate.ExtraArgs[0] = "-q"
+	if original, _ := tm.GetTunnel("db"); original.Tags[0] != "prod" || original.ExtraArgs[0] != "-v" {
+		t.Errorf("Expected the original to be unchanged, got tags %q and args %q", original.Tags, original.ExtraArgs)
+	}
+
+	second, err := tm.DuplicateTunnel("db")
+	if err != nil || second.Name != "db (copy 2)" {
+		t.Errorf("Expected a numbered second copy, got %v (%v)", second, err)
+	}
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if len(config.Tunnels) != 3 {
+		t.Errorf("Expected the copies to be saved, got %d tunnels", len(config.Tunnels))
+	}
+
+	if _, err := tm.DuplicateTunnel("missing"); !errors.Is(err, ErrTunnelNotFound) {
+		t.Errorf("Expected an unknown tunnel to fail, got %v", err)
+	}
+}
+
+// TestDescriptionPersisted tests that a tunnel's description is saved and
+// loaded with the config
+func TestDescriptionPersisted(t *testing.T) {
+	tm, configStore := newTestManager(t, "")
+
+	tunnel := NewTunnel("db", LocalForward)
+	tunnel.SSHHost = "db.example.com"
+	tunnel.LocalPort = 15432
+	tunnel.RemoteHost = "localhost"
+	tunnel.RemotePort = 5432
+	tunnel.Description = "Reporting replica, owned by the data team"
+	if err := tm.AddTunnel(tunnel); err != nil {
+		t.Fatalf("AddTunnel failed: %v", err)
+	}
+
+	pidStore, err := store.NewPIDStore()
+	if err != nil {
+		t.Fatalf("Failed to create PID store: %v", err)
+	}
+	reloaded, err := NewTunnelManager(configStore, pidStore).GetTunnel(tunnel.ID)
+	if err != nil {
+		t.Fatalf("Expected the tunnel to be saved: %v", err)
+	}
+	if reloaded.Description != tunnel.Description || reloaded.Snapshot().Description != tunnel.Description {
+		t.Errorf("Expected description %q, got %q", tunnel.Description, reloaded.Description)
+	}
+}
+
+// TestSaveDeterministic tests that saving unchanged tunnels writes the same
+// bytes, with tunnels in ID order
+func TestSaveDeterministic(t *testing.T) {
+	tm, configStore := newTestManager(t, `{
+  "version": "2.0",
+  "tunnels": [
+    {"id": "f", "name": "F", "host": "f.example.com", "localPort": 8006, "remotePort": 80, "mode": "local"},
+    {"id": "b", "name": "B", "host": "b.example.com", "localPort": 8002, "remotePort": 80, "mode": "local", "profile": "work"},
+    {"id": "d", "name": "D", "host": "d.example.com", "localPort": 8004, "remotePort": 80, "mode": "local"},
+    {"id": "a", "name": "A", "host": "a.example.com", "localPort": 8001, "remotePort": 80, "mode": "local"},
+    {"id": "e", "name": "E", "host": "e.example.com", "localPort": 8005, "remotePort": 80, "mode": "local", "profile": "home"},
+    {"id": "c", "name": "C", "host": "c.example.com", "localPort": 8003, "remotePort": 80, "mode": "local"}
+  ]
+}`)
+	configPath, err := configStore.GetConfigPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	save := func() string {
+		t.Helper()
+		tm.mu.Lock()
+		defer tm.mu.Unlock()
+		if err := tm.saveTunnels(); err != nil {
+			t.Fatalf("Failed to save: %v", err)
+		}
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			t.Fatalf("Failed to read config: %v", err)
+		}
+		return string(data)
+	}
+
+	first := save()
+	for i := 0; i < 5; i++ {
+		if again := save(); again != first {
+			t.Fatalf("Expected saving again to write the same config, got:\n%s\nthen:\n%s", first, again)
+		}
+	}
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, tc := range config.Tunnels {
+		ids = append(ids, tc.ID)
+	}
+	if got := strings.Join(ids, ","); got != "a,b,c,d,e,f" {
+		t.Errorf("Expected tunnels in ID order, got %s", got)
+	}
+}
+
+// TestMigrateConfigV1 tests that a version 1 config is rewritten in the
+// current format with the settings it implied, keeping a backup
+func TestMigrateConfigV1(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "web", "name": "Web", "host": "web.example.com", "localPort": 8080, "remotePort": 80, "mode": "forward"}
+  ]
+}`
+
+	tm, configStore := newTestManager(t, configJSON)
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if config.Version != store.ConfigVersion || config.MigratedFrom != "" {
+		t.Fatalf("Expected the config to be saved as version %s, got %q", store.ConfigVersion, config.Version)
+	}
+	tc := config.Tunnels[0]
+	if tc.Mode != "local" || tc.BindAddress != "0.0.0.0" || tc.RemoteHost != "127.0.0.1" {
+		t.Errorf("Expected implied settings to be written out, got %+v", tc)
+	}
+	if _, err := os.Stat(configStore.MigrationBackupPath("1.0")); err != nil {
+		t.Errorf("Expected the version 1 config to be backed up: %v", err)
+	}
+
+	// The remote host now survives a save and reload
+	tunnel, err := tm.GetTunnel("web")
+	if err != nil {
+		t.Fatalf("GetTunnel failed: %v", err)
+	}
+	update := tunnel.Clone()
+	update.RemoteHost = "db.internal"
+	if err := tm.UpdateTunnel(update); err != nil {
+		t.Fatalf("UpdateTunnel failed: %v", err)
+	}
+	pidStore, err := store.NewPIDStore()
+	if err != nil {
+		t.Fatalf("Failed to create PID store: %v", err)
+	}
+	reloaded, err := NewTunnelManager(configStore, pidStore).GetTunnel("web")
+	if err != nil {
+		t.Fatalf("Expected the tunnel to be saved: %v", err)
+	}
+	if reloaded.RemoteHost != "db.internal" {
+		t.Errorf("Expected remote host db.internal, got %q", reloaded.RemoteHost)
+	}
+}
+
+// TestConfigTooNew tests that a config from a newer version is neither
+// loaded nor overwritten
+func TestConfigTooNew(t *testing.T) {
+	configJSON := `{"version": "3.0", "tunnels": [{"id": "web", "name": "Web", "host": "web.example.com", "localPort": 8080, "remotePort": 80, "mode": "local", "future": true}]}`
+	tm, configStore := newTestManager(t, configJSON)
+
+	if _, err := configStore.LoadConfig(); !errors.Is(err, store.ErrConfigTooNew) {
+		t.Errorf("Expected ErrConfigTooNew, got %v", err)
+	}
+	if tunnels := tm.GetTunnels(); len(tunnels) != 0 {
+		t.Errorf("Expected no tunnels to be loaded, got %d", len(tunnels))
+	}
+
+	tunnel := NewTunnel("db", LocalForward)
+	tunnel.SSHHost = "db.example.com"
+	tunnel.LocalPort = 15432
+	tunnel.RemotePort = 5432
+	if err := tm.AddTunnel(tunnel); !errors.Is(err, store.ErrConfigLocked) {
+		t.Errorf("Expected saving to be refused, got %v", err)
+	}
+	configPath, _ := configStore.GetConfigPath()
+	if data, _ := os.ReadFile(configPath); string(data) != configJSON {
+		t.Errorf("Expected the config to be left as it was, got %s", data)
+	}
+}
+
+// TestMonitorTunnelReportsExit tests that a tunnel whose ssh dies is marked
+// failed as soon as ssh exits, with the reason taken from its output
+func TestMonitorTunnelReportsExit(t *testing.T) {
+	fakeSSH := filepath.Join(t.TempDir(), "ssh")
+	script := `#!/bin/sh
+if [ "$1" = "-V" ]; then echo "OpenSSH_9.6p1" >&2; exit 0; fi
+echo "user@example.com: Permission denied (publickey)." >&2
+exit 255
+`
+	if err := os.WriteFile(fakeSSH, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ssh: %v", err)
+	}
+	t.Setenv(SSHPathEnv, fakeSSH)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	tm, _ := newTestManager(t, "")
+	tm.tunnels["db"] = &Tunnel{
+		ID:        "db",
+		Name:      "db",
+		Type:      DynamicForward,
+		LocalHost: "127.0.0.1",
+		LocalPort: port,
+		SSHHost:   "example.com",
+		Status:    StatusStopped,
+	}
+
+	statusChanges := tm.Subscribe()
+	defer tm.Unsubscribe(statusChanges)
+
+	if err := tm.StartTunnel("db"); err != nil {
+		t.Fatalf("StartTunnel failed: %v", err)
+	}
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case change := <-statusChanges:
+			if change.TunnelID != "db" || change.NewStatus == StatusConnecting || change.NewStatus == StatusRunning {
+				continue
+			}
+			if change.NewStatus != StatusError {
+				t.Fatalf("Expected status error, got %s", change.NewStatus)
+			}
+			if kind := FailureKindOf(change.Error); kind != FailureAuth {
+				t.Errorf("Expected an authentication failure, got %q (%v)", kind, change.Error)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timed out waiting for the tunnel to fail")
+		}
+	}
+}
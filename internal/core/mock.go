@@ -0,0 +1,319 @@
+// Package core provides simulated ssh processes for demos and tests.
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mockFirstPID is the first PID given to simulated processes, above the PIDs
+// real systems hand out so that they are never mistaken for real processes
+const mockFirstPID = 1 << 30
+
+// MockConfig controls how simulated ssh processes behave
+type MockConfig struct {
+	// ConnectDelay is how long a simulated connection takes to come up
+	ConnectDelay time.Duration
+
+	// FailureRate is the chance, from 0 to 1, that a connection fails while
+	// connecting
+	FailureRate float64
+
+	// MeanLifetime is the average time before an established connection
+	// drops; zero keeps connections up until they are stopped
+	MeanLifetime time.Duration
+}
+
+// mockFailures are ssh outputs of failed connections, as classified by classifySSHFailure
+var mockFailures = []string{
+	"%[1]s: Permission denied (publickey).",
+	"ssh: connect to host %[1]s port 22: Connection refused",
+	"ssh: connect to host %[1]s port 22: Operation timed out",
+	"ssh: Could not resolve hostname %[1]s: Name or service not known",
+}
+
+// mockDrops are ssh outputs of established connections that dropped
+var mockDrops = []string{
+	"Timeout, server %[1]s not responding.",
+	"client_loop: send disconnect: Broken pipe",
+	"Connection to %[1]s closed by remote host.",
+}
+
+// MockProcessManager simulates ssh processes instead of running them, so
+// tunnels can be demoed and tested without SSH servers. Local and dynamic
+// forwards really listen on their ports, accepting and closing connections,
+// so readiness waits and health checks behave as with ssh. Output is written
+// to the tunnel logs like ssh's.
+type MockProcessManager struct {
+	*ProcessManager
+	config MockConfig
+
+	// mockMu guards nextPID; processes are tracked in the embedded manager
+	mockMu  sync.Mutex
+	nextPID int
+}
+
+// NewMockProcessManager creates a simulated backend that takes its log
+// directory and ssh command settings from pm
+func NewMockProcessManager(pm *ProcessManager, config MockConfig) *MockProcessManager {
+	return &MockProcessManager{
+		ProcessManager: pm,
+		config:         config,
+		nextPID:        mockFirstPID,
+	}
+}
+
+// WithMockProcesses makes the tunnel manager simulate ssh processes instead
+// of running them
+func WithMockProcesses(config MockConfig) TunnelManagerOption {
+	return func(tm *TunnelManager) {
+		tm.mockConfig = &config
+	}
+}
+
+// SetSSHPath records the configured ssh executable, which does not have to
+// exist when ssh is simulated
+func (mp *MockProcessManager) SetSSHPath(configured string) error {
+	if err := mp.ProcessManager.SetSSHPath(configured); err != nil {
+		Debug("Simulating ssh without an executable: %v", err)
+	}
+	return nil
+}
+
+// SSHVersion reports the ssh version when it is installed, and a recent
+// OpenSSH otherwise, so no tunnel is refused for its options
+func (mp *MockProcessManager) SSHVersion() (SSHVersion, error) {
+	if version, err := mp.ProcessManager.SSHVersion(); err == nil && version.Known() {
+		return version, nil
+	}
+	return SSHVersion{Major: 9, Minor: 9, Raw: "OpenSSH_9.9 (simulated)"}, nil
+}
+
+// Connect simulates starting ssh for a tunnel. It returns once the simulated
+// connection is up, or has failed, like ssh exiting during authentication.
+func (mp *MockProcessManager) Connect(tunnel *Tunnel) (*ProcessInfo, error) {
+	if tunnel == nil {
+		return nil, fmt.Errorf("tunnel cannot be nil")
+	}
+	if err := tunnel.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tunnel configuration: %w", err)
+	}
+	if err := checkIdentityFile(tunnel); err != nil {
+		return nil, err
+	}
+
+	args := mp.buildSSHArgs(tunnel)
+	logPath := mp.LogPath(tunnel.ID)
+	if logPath != "" {
+		if file, err := openTunnelLog(logPath); err == nil {
+			file.Close()
+		}
+	}
+	writeLogMarker(logPath, "starting ssh %s (simulated)", strings.Join(args, " "))
+
+	mp.mockMu.Lock()
+	pid := mp.nextPID
+	mp.nextPID++
+	mp.mockMu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	info := &ProcessInfo{
+		PID:       pid,
+		Tunnel:    tunnel,
+		StartedAt: time.Now(),
+		ctx:       ctx,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+
+	mp.mu.Lock()
+	mp.processes[tunnel.ID] = info
+	mp.mu.Unlock()
+
+	// Authenticate, or fail to
+	select {
+	case <-time.After(mp.config.ConnectDelay):
+	case <-ctx.Done():
+		mp.exit(info, nil, errors.New("signal: terminated"))
+		return info, nil
+	}
+	if rand.Float64() < mp.config.FailureRate {
+		output := fmt.Sprintf(mockFailures[rand.IntN(len(mockFailures))], tunnel.SSHHost)
+		mp.exit(info, nil, mockExitError(output, logPath))
+		return info, nil
+	}
+
+	listeners, err := mockListen(tunnel)
+	if err != nil {
+		mp.exit(info, nil, mockExitError(err.Error()+"\nCould not request local forwarding.", logPath))
+		return info, nil
+	}
+	appendLog(logPath, fmt.Sprintf("Authenticated to %s ([127.0.0.1]:22) using \"publickey\".", tunnel.SSHHost))
+
+	go mp.run(info, listeners, logPath)
+	return info, nil
+}
+
+// run keeps a simulated connection up until it is disconnected or drops
+func (mp *MockProcessManager) run(info *ProcessInfo, listeners []net.Listener, logPath string) {
+	var drop <-chan time.Time
+	if mp.config.MeanLifetime > 0 {
+		lifetime := time.Duration(rand.ExpFloat64() * float64(mp.config.MeanLifetime))
+		drop = time.After(lifetime)
+	}
+
+	select {
+	case <-info.ctx.Done():
+		mp.exit(info, listeners, errors.New("signal: terminated"))
+	case <-drop:
+		output := fmt.Sprintf(mockDrops[rand.IntN(len(mockDrops))], info.Tunnel.SSHHost)
+		mp.exit(info, listeners, mockExitError(output, logPath))
+	}
+}
+
+// exit ends a simulated process like monitorProcess ends a real one
+func (mp *MockProcessManager) exit(info *ProcessInfo, listeners []net.Listener, err error) {
+	for _, listener := range listeners {
+		listener.Close()
+	}
+	writeLogMarker(mp.LogPath(info.Tunnel.ID), "ssh exited: %v", err)
+
+	mp.mu.Lock()
+	if mp.processes[info.Tunnel.ID] == info {
+		delete(mp.processes, info.Tunnel.ID)
+	}
+	mp.mu.Unlock()
+
+	code := -1
+	var exitErr *mockExit
+	if errors.As(err, &exitErr) {
+		code = 255
+	}
+	info.exit = ProcessExit{
+		Err:        err,
+		ExitCode:   code,
+		Unexpected: info.ctx.Err() == nil,
+	}
+	close(info.done)
+}
+
+// mockExit is the error of a simulated ssh that exited with status 255
+type mockExit struct{}
+
+func (*mockExit) Error() string { return "exit status 255" }
+
+// mockExitError writes the output of a failed ssh to the log and returns its exit error
+func mockExitError(output, logPath string) error {
+	appendLog(logPath, strings.Split(output, "\n")...)
+	return &mockExit{}
+}
+
+// mockListen listens on the local ports of a tunnel's local and dynamic
+// forwards, accepting and closing connections
+func mockListen(tunnel *Tunnel) ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, f := range tunnel.AllForwards() {
+		if f.IsRemote() {
+			continue
+		}
+		address := net.JoinHostPort(listenHost(f.LocalHost), strconv.Itoa(f.LocalPort))
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("bind [%s]:%d: Address already in use", f.LocalHost, f.LocalPort)
+		}
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				conn.Close()
+			}
+		}()
+		listeners = append(listeners, listener)
+	}
+	return listeners, nil
+}
+
+// appendLog appends lines of simulated ssh output to a tunnel log
+func appendLog(path string, lines ...string) {
+	if path == "" {
+		return
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	for _, line := range lines {
+		fmt.Fprintln(file, line)
+	}
+}
+
+// Disconnect stops a simulated process. Untracked PIDs are never real
+// processes of this backend, so they are left alone.
+func (mp *MockProcessManager) Disconnect(id string, pid int) error {
+	info, exists := mp.GetProcessInfo(id)
+	if !exists {
+		return nil
+	}
+	info.cancel()
+	<-info.done
+	return nil
+}
+
+// CancelSharedForwards does nothing, as simulated tunnels share no connection
+func (mp *MockProcessManager) CancelSharedForwards(tunnel *Tunnel) error {
+	return nil
+}
+
+// Cleanup stops every simulated process
+func (mp *MockProcessManager) Cleanup(ctx context.Context) error {
+	for id, info := range mp.GetAllProcesses() {
+		if err := mp.Disconnect(id, info.PID); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// IsProcessRunning reports whether a simulated process is running
+func (mp *MockProcessManager) IsProcessRunning(pid int) bool {
+	for _, info := range mp.GetAllProcesses() {
+		if info.PID == pid {
+			return true
+		}
+	}
+	return false
+}
+
+// killProcessByPID stops the simulated process with a PID, leaving real
+// processes alone
+func (mp *MockProcessManager) killProcessByPID(pid int) error {
+	for id, info := range mp.GetAllProcesses() {
+		if info.PID == pid {
+			return mp.Disconnect(id, pid)
+		}
+	}
+	return nil
+}
+
+// findTunnelProcesses reports no processes, so real ssh processes are never
+// offered for adoption while ssh is simulated
+func (mp *MockProcessManager) findTunnelProcesses() ([]SSHProcess, error) {
+	return nil, nil
+}
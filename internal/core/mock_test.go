@@ -0,0 +1,84 @@
+// Package core provides simulated ssh backend tests.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+// freePort returns a local port that nothing listens on
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// mockConfigJSON returns a config with one local forward on port
+func mockConfigJSON(port int) string {
+	return fmt.Sprintf(`{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.example.com", "bindAddress": "127.0.0.1", "localPort": %d, "remotePort": 5432, "mode": "local"}
+  ]
+}`, port)
+}
+
+// TestMockProcesses tests that simulated tunnels start, listen on their local
+// ports and stop without running ssh
+func TestMockProcesses(t *testing.T) {
+	t.Setenv(SSHPathEnv, "/nonexistent/ssh")
+	port := freePort(t)
+	tm, _ := newTestManager(t, mockConfigJSON(port), WithMockProcesses(MockConfig{ConnectDelay: 10 * time.Millisecond}))
+
+	if err := tm.StartTunnel("db"); err != nil {
+		t.Fatalf("Failed to start simulated tunnel: %v", err)
+	}
+	tunnel, _ := tm.GetTunnel("db")
+	if tunnel.Status != StatusRunning || tunnel.PID < mockFirstPID {
+		t.Fatalf("Expected a running tunnel with a simulated PID, got %s with PID %d", tunnel.Status, tunnel.PID)
+	}
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatalf("Expected the simulated forward to accept connections: %v", err)
+	}
+	conn.Close()
+
+	if err := tm.StopTunnel("db"); err != nil {
+		t.Fatalf("Failed to stop simulated tunnel: %v", err)
+	}
+	if _, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port)); err == nil {
+		t.Error("Expected the forward to stop listening")
+	}
+}
+
+// TestMockFailures tests that simulated failures are classified like ssh's
+func TestMockFailures(t *testing.T) {
+	port := freePort(t)
+	tm, _ := newTestManager(t, mockConfigJSON(port), WithMockProcesses(MockConfig{FailureRate: 1}))
+
+	changes := tm.Subscribe()
+	defer tm.Unsubscribe(changes)
+
+	if err := tm.StartTunnel("db"); err != nil {
+		t.Fatalf("Expected the simulated ssh to start before failing: %v", err)
+	}
+	for {
+		change := receiveChange(t, changes)
+		if change.NewStatus != StatusError {
+			continue
+		}
+		var sshErr *SSHError
+		if !errors.As(change.Error, &sshErr) || sshErr.Kind == "" {
+			t.Errorf("Expected a classified ssh failure, got %v", change.Error)
+		}
+		return
+	}
+}
@@ -0,0 +1,91 @@
+// Package core provides SSH connection multiplexing for tunnels.
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// muxControlPersist is how long, in seconds, a shared master connection stays
+// up after its last tunnel disconnects
+const muxControlPersist = "60"
+
+// WithControlDir sets the directory holding the control sockets shared by
+// multiplexed tunnels
+func WithControlDir(dir string) ProcessManagerOption {
+	return func(pm *ProcessManager) {
+		pm.controlDir = dir
+	}
+}
+
+// multiplexes reports whether the tunnel's ssh shares a master connection
+func (pm *ProcessManager) multiplexes(tunnel *Tunnel) bool {
+	return tunnel.Multiplex && pm.controlDir != "" && !IsWindows()
+}
+
+// controlPath returns the control socket for a multiplexed tunnel. ssh expands
+// %C to a hash of the destination, so tunnels to the same host share it.
+func (pm *ProcessManager) controlPath() string {
+	return filepath.Join(pm.controlDir, "%C")
+}
+
+// controlArgs returns the ssh options for connection sharing. Multiplexed
+// tunnels to the same host reuse one master connection, which ssh starts in
+// the background for the first of them; others get a connection of their own.
+func (pm *ProcessManager) controlArgs(tunnel *Tunnel) []string {
+	if !pm.multiplexes(tunnel) {
+		return []string{
+			"-o", "ControlMaster=no", // Don't use connection sharing
+			"-o", "ControlPath=none", // No control socket
+		}
+	}
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + pm.controlPath(),
+		"-o", "ControlPersist=" + muxControlPersist,
+	}
+}
+
+// prepareControlDir creates the control socket directory, readable only by the user
+func (pm *ProcessManager) prepareControlDir() error {
+	if err := os.MkdirAll(pm.controlDir, 0o700); err != nil {
+		return fmt.Errorf("failed to create control socket directory: %w", err)
+	}
+	return nil
+}
+
+// CancelSharedForwards asks the master connection a multiplexed tunnel shares
+// to drop the tunnel's forwards, which it would otherwise keep listening on
+// after the tunnel's own ssh process is gone
+func (pm *ProcessManager) CancelSharedForwards(tunnel *Tunnel) error {
+	if !pm.multiplexes(tunnel) {
+		return nil
+	}
+
+	args := []string{"-O", "cancel", "-o", "ControlPath=" + pm.controlPath()}
+	for _, forward := range tunnel.AllForwards() {
+		args = append(args, forward.Args()...)
+	}
+	if tunnel.JumpHost != "" {
+		args = append(args, "-J", tunnel.JumpHost)
+	}
+	args = append(args, tunnel.ExtraArgs...)
+	args = append(args, tunnel.SSHHost)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, pm.SSHPath(), args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("failed to cancel shared forwards: %s", msg)
+		}
+		return fmt.Errorf("failed to cancel shared forwards: %w", err)
+	}
+	return nil
+}
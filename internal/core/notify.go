@@ -0,0 +1,94 @@
+// Package core provides desktop notifications for tunnels that change state in the background.
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Notifier shows a desktop notification
+type Notifier func(title, message string) error
+
+// WithNotifier sets how desktop notifications are shown
+func WithNotifier(notifier Notifier) TunnelManagerOption {
+	return func(tm *TunnelManager) {
+		tm.notifier = notifier
+	}
+}
+
+// windowsToastScript shows a toast with the title and message passed in the
+// environment, which avoids quoting them into the script
+const windowsToastScript = `[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $template.GetElementsByTagName('text')
+$text.Item(0).AppendChild($template.CreateTextNode($env:TUNNELMAN_NOTIFY_TITLE)) > $null
+$text.Item(1).AppendChild($template.CreateTextNode($env:TUNNELMAN_NOTIFY_MESSAGE)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('tunnelman').Show([Windows.UI.Notifications.ToastNotification]::new($template))`
+
+// DesktopNotify shows a notification with notify-send, osascript on macOS,
+// or a toast on Windows
+func DesktopNotify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message)
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-Command", windowsToastScript)
+		cmd.Env = append(os.Environ(), "TUNNELMAN_NOTIFY_TITLE="+title, "TUNNELMAN_NOTIFY_MESSAGE="+message)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=tunnelman", title, message)
+	}
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to show notification: %w: %s", err, out)
+	}
+	return nil
+}
+
+// notificationsEnabled reports whether desktop notifications are on, which
+// they are unless the config turns them off. The caller must hold tm.mu.
+func (tm *TunnelManager) notificationsEnabled() bool {
+	if tm.notifier == nil {
+		return false
+	}
+	return tm.defaults == nil || tm.defaults.Notifications == nil || *tm.defaults.Notifications
+}
+
+// notify shows a desktop notification in the background. The caller must hold tm.mu.
+func (tm *TunnelManager) notify(title, message string) {
+	if !tm.notificationsEnabled() {
+		return
+	}
+	notifier := tm.notifier
+	go func() {
+		if err := notifier(title, message); err != nil {
+			Debug("%v", err)
+		}
+	}()
+}
+
+// notifyDropped tells the user a running tunnel went down on its own. The
+// caller must hold tm.mu.
+func (tm *TunnelManager) notifyDropped(tunnel *Tunnel) {
+	if tunnel.Status == StatusError && tunnel.LastError != nil {
+		tm.notify(fmt.Sprintf("Tunnel %s failed", tunnel.Name), tunnel.LastError.Error())
+		return
+	}
+	tm.notify(fmt.Sprintf("Tunnel %s disconnected", tunnel.Name), "The ssh connection to "+tunnel.SSHHost+" closed")
+}
+
+// notifyReconnected tells the user a dropped tunnel is back up
+func (tm *TunnelManager) notifyReconnected(id string) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tunnel, exists := tm.tunnels[id]; exists {
+		tm.notify(fmt.Sprintf("Tunnel %s reconnected", tunnel.Name), tunnel.ForwardSummary())
+	}
+}
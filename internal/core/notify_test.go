@@ -0,0 +1,59 @@
+// Package core provides desktop notification tests.
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestNotifyDropped tests the notifications for dropped tunnels and the config opt-out
+func TestNotifyDropped(t *testing.T) {
+	tm, _ := newTestManager(t, "")
+	shown := make(chan string, 1)
+	tm.notifier = func(title, message string) error {
+		shown <- title + ": " + message
+		return nil
+	}
+
+	receive := func() string {
+		t.Helper()
+		select {
+		case notification := <-shown:
+			return notification
+		case <-time.After(5 * time.Second):
+			t.Fatal("No notification was shown")
+			return ""
+		}
+	}
+
+	tunnel := &Tunnel{ID: "db", Name: "db", Type: DynamicForward, SSHHost: "bastion", Status: StatusStopped}
+	tm.mu.Lock()
+	tm.notifyDropped(tunnel)
+	tm.mu.Unlock()
+	if got := receive(); got != "Tunnel db disconnected: The ssh connection to bastion closed" {
+		t.Errorf("Unexpected notification %q", got)
+	}
+
+	tunnel.Status = StatusError
+	tunnel.LastError = &SSHError{Kind: FailureNetwork, ExitCode: 255, Message: "Connection timed out"}
+	tm.mu.Lock()
+	tm.notifyDropped(tunnel)
+	tm.mu.Unlock()
+	if got := receive(); !strings.HasPrefix(got, "Tunnel db failed: host unreachable") {
+		t.Errorf("Unexpected notification %q", got)
+	}
+
+	off := false
+	tm.defaults = &store.Defaults{Notifications: &off}
+	tm.mu.Lock()
+	tm.notifyDropped(tunnel)
+	tm.mu.Unlock()
+	select {
+	case notification := <-shown:
+		t.Errorf("Expected notifications to be off, got %q", notification)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,226 @@
+// Package core provides discovery of ssh tunnel processes that tunnelman does not track.
+package core
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSHProcess is an ssh process running on the system
+type SSHProcess struct {
+	PID  int
+	Args []string
+}
+
+// OrphanProcess is a tunnelman-style ssh process missing from the PID store
+type OrphanProcess struct {
+	SSHProcess
+
+	// TunnelID and TunnelName identify the configured tunnel whose ssh command
+	// line the process matches, if any
+	TunnelID   string
+	TunnelName string
+}
+
+// FindTunnelProcesses returns running ssh processes whose command line carries
+// the options tunnelman starts tunnels with
+func FindTunnelProcesses() ([]SSHProcess, error) {
+	if IsWindows() {
+		return nil, fmt.Errorf("process discovery is not supported on windows")
+	}
+
+	out, err := exec.Command("ps", "-axo", "pid=,args=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list processes: %w", err)
+	}
+	return parseProcessList(string(out)), nil
+}
+
+// parseProcessList extracts tunnelman ssh processes from `ps -o pid=,args=` output
+func parseProcessList(output string) []SSHProcess {
+	var processes []SSHProcess
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		if isTunnelSSH(fields[1:]) {
+			processes = append(processes, SSHProcess{PID: pid, Args: fields[1:]})
+		}
+	}
+	return processes
+}
+
+// isTunnelSSH reports whether args look like an ssh command started by tunnelman.
+// Multiplexed tunnels are left out, since the master connection ssh keeps in
+// the background for them is not tracked and must not be taken for an orphan.
+func isTunnelSSH(args []string) bool {
+	if len(args) == 0 || filepath.Base(args[0]) != "ssh" {
+		return false
+	}
+
+	var noCommand, exitOnFailure, noControlPath bool
+	for _, arg := range args[1:] {
+		switch arg {
+		case "-N":
+			noCommand = true
+		case "ExitOnForwardFailure=yes", "ExitOnForwardFailure=no":
+			exitOnFailure = true
+		case "ControlPath=none":
+			noControlPath = true
+		}
+	}
+	return noCommand && exitOnFailure && noControlPath
+}
+
+// FindOrphanedProcesses returns tunnelman ssh processes that are running but
+// neither tracked in the PID store nor owned by this process
+func (tm *TunnelManager) FindOrphanedProcesses() ([]OrphanProcess, error) {
+	processes, err := tm.processManager.findTunnelProcesses()
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := make(map[int]bool)
+	if pids, err := tm.pidStore.LoadPids(); err == nil {
+		for _, info := range pids.Pids {
+			tracked[info.PID] = true
+		}
+	}
+	for _, info := range tm.processManager.GetAllProcesses() {
+		tracked[info.PID] = true
+	}
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	var orphans []OrphanProcess
+	for _, process := range processes {
+		if tracked[process.PID] {
+			continue
+		}
+
+		orphan := OrphanProcess{SSHProcess: process}
+		for id, tunnel := range tm.tunnels {
+			if sameSSHArgs(process.Args[1:], tm.processManager.buildSSHArgs(tm.withProfileDefaults(tunnel))) {
+				orphan.TunnelID = id
+				orphan.TunnelName = tunnel.Name
+				break
+			}
+		}
+		orphans = append(orphans, orphan)
+	}
+	return orphans, nil
+}
+
+// sameSSHArgs compares two ssh argument lists, ignoring verbosity flags
+func sameSSHArgs(a, b []string) bool {
+	strip := func(args []string) string {
+		kept := make([]string, 0, len(args))
+		for _, arg := range args {
+			if arg != "-v" {
+				kept = append(kept, arg)
+			}
+		}
+		return strings.Join(kept, "\x00")
+	}
+	return strip(a) == strip(b)
+}
+
+// AdoptOrphan brings the orphaned ssh process of a configured tunnel under
+// management, as if this tunnelman had started it
+func (tm *TunnelManager) AdoptOrphan(orphan OrphanProcess) error {
+	if orphan.TunnelID == "" {
+		return fmt.Errorf("process %d does not match a configured tunnel", orphan.PID)
+	}
+	if !tm.processManager.IsProcessRunning(orphan.PID) {
+		return fmt.Errorf("process %d is no longer running", orphan.PID)
+	}
+
+	started, err := processStartTime(orphan.PID)
+	if err != nil {
+		Debug("Failed to get start time of process %d: %v", orphan.PID, err)
+		started = time.Now()
+	}
+
+	tm.mu.Lock()
+	tunnel, exists := tm.tunnels[orphan.TunnelID]
+	if !exists {
+		tm.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrTunnelNotFound, orphan.TunnelID)
+	}
+	if tunnel.Status == StatusRunning || tunnel.Status == StatusConnecting {
+		tm.mu.Unlock()
+		return fmt.Errorf("cannot adopt process %d into tunnel %s: %w", orphan.PID, tunnel.Name, ErrAlreadyRunning)
+	}
+	oldStatus := tunnel.Status
+	tunnel.Status = StatusRunning
+	tunnel.PID = orphan.PID
+	tunnel.StartedAt = &started
+	tunnel.LastError = nil
+	tm.mu.Unlock()
+
+	if err := tm.pidStore.AddPidStartedAt(orphan.TunnelID, orphan.PID, started); err != nil {
+		Warn("Failed to save PID of adopted tunnel %s: %v", tunnel.Name, err)
+	}
+
+	Info("Adopted ssh process %d as tunnel %s", orphan.PID, tunnel.Name)
+	tm.notifyStatusChange(orphan.TunnelID, oldStatus, StatusRunning, nil)
+	return nil
+}
+
+// KillOrphan terminates an orphaned ssh process
+func (tm *TunnelManager) KillOrphan(orphan OrphanProcess) error {
+	if err := tm.processManager.killProcessByPID(orphan.PID); err != nil {
+		return err
+	}
+	Info("Terminated untracked ssh process %d", orphan.PID)
+	return nil
+}
+
+// processStartTime returns when a process started, from its elapsed time in ps
+func processStartTime(pid int) (time.Time, error) {
+	out, err := exec.Command("ps", "-o", "etime=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to run ps: %w", err)
+	}
+	elapsed, err := parseElapsedTime(strings.TrimSpace(string(out)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Now().Add(-elapsed), nil
+}
+
+// parseElapsedTime parses the [[dd-]hh:]mm:ss elapsed time format of ps
+func parseElapsedTime(etime string) (time.Duration, error) {
+	var days int
+	if d, rest, ok := strings.Cut(etime, "-"); ok {
+		n, err := strconv.Atoi(d)
+		if err != nil {
+			return 0, fmt.Errorf("invalid elapsed time %q", etime)
+		}
+		days, etime = n, rest
+	}
+
+	parts := strings.Split(etime, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid elapsed time %q", etime)
+	}
+	var seconds int
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return 0, fmt.Errorf("invalid elapsed time %q", etime)
+		}
+		seconds = seconds*60 + n
+	}
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds)*time.Second, nil
+}
@@ -0,0 +1,96 @@
+// Package core provides orphaned process discovery tests.
+package core
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestParseProcessList tests that only tunnelman-style ssh processes are picked out of ps output
+func TestParseProcessList(t *testing.T) {
+	output := `    1 /sbin/init
+  120 ssh -L 0.0.0.0:8080:127.0.0.1:80 -N -T -o ExitOnForwardFailure=yes -o ControlPath=none bastion
+  121 ssh bastion
+  122 /usr/bin/ssh -D 0.0.0.0:1080 -N -T -o ExitOnForwardFailure=yes -o ControlPath=none proxy
+  bad line
+`
+	processes := parseProcessList(output)
+	if len(processes) != 2 {
+		t.Fatalf("Expected 2 processes, got %d: %+v", len(processes), processes)
+	}
+	if processes[0].PID != 120 || processes[1].PID != 122 {
+		t.Errorf("Unexpected PIDs: %d, %d", processes[0].PID, processes[1].PID)
+	}
+	if last := processes[1].Args[len(processes[1].Args)-1]; last != "proxy" {
+		t.Errorf("Expected last argument proxy, got %s", last)
+	}
+}
+
+// TestAdoptOrphan tests that an adopted process is tracked like a started tunnel
+func TestAdoptOrphan(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "example.com", "localPort": 1080, "mode": "dynamic"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+
+	// The test process stands in for the ssh process, since it is sure to be running
+	orphan := OrphanProcess{SSHProcess: SSHProcess{PID: os.Getpid()}, TunnelID: "db", TunnelName: "db"}
+	if err := tm.AdoptOrphan(orphan); err != nil {
+		t.Fatalf("AdoptOrphan failed: %v", err)
+	}
+
+	tunnel, err := tm.GetTunnel("db")
+	if err != nil {
+		t.Fatalf("GetTunnel failed: %v", err)
+	}
+	if tunnel.Status != StatusRunning || tunnel.PID != os.Getpid() || tunnel.StartedAt == nil {
+		t.Errorf("Expected running tunnel with PID %d, got %s with PID %d", os.Getpid(), tunnel.Status, tunnel.PID)
+	}
+	pids, err := tm.pidStore.LoadPids()
+	if err != nil {
+		t.Fatalf("LoadPids failed: %v", err)
+	}
+	if pids.Pids["db"].PID != os.Getpid() {
+		t.Errorf("Expected PID %d in PID store, got %d", os.Getpid(), pids.Pids["db"].PID)
+	}
+
+	if err := tm.AdoptOrphan(orphan); err == nil {
+		t.Error("Expected adopting into a running tunnel to fail")
+	}
+	if err := tm.AdoptOrphan(OrphanProcess{SSHProcess: SSHProcess{PID: os.Getpid()}}); err == nil {
+		t.Error("Expected adopting a process without a tunnel to fail")
+	}
+}
+
+// TestParseElapsedTime tests parsing of the elapsed time printed by ps
+func TestParseElapsedTime(t *testing.T) {
+	tests := []struct {
+		etime    string
+		expected time.Duration
+	}{
+		{"00:05", 5 * time.Second},
+		{"12:34", 12*time.Minute + 34*time.Second},
+		{"01:02:03", time.Hour + 2*time.Minute + 3*time.Second},
+		{"2-03:00:00", 51 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := parseElapsedTime(tt.etime)
+		if err != nil {
+			t.Errorf("parseElapsedTime(%q) failed: %v", tt.etime, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("parseElapsedTime(%q) = %v, expected %v", tt.etime, got, tt.expected)
+		}
+	}
+
+	for _, etime := range []string{"", "5", "a:b", "x-01:00"} {
+		if _, err := parseElapsedTime(etime); err == nil {
+			t.Errorf("Expected parseElapsedTime(%q) to fail", etime)
+		}
+	}
+}
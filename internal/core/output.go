@@ -0,0 +1,178 @@
+// Package core provides capture of SSH process output.
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// outputBufferLines is the number of recent output lines returned per tunnel
+	outputBufferLines = 200
+
+	// maxLogSize is the size at which a tunnel log is rotated to <id>.log.1
+	maxLogSize = 1 << 20
+
+	// logRotateInterval is how often the log of a running ssh process is
+	// checked against maxLogSize
+	logRotateInterval = 30 * time.Second
+)
+
+// OutputBuffer is a fixed-size ring buffer of output lines
+type OutputBuffer struct {
+	mu    sync.RWMutex
+	lines []string
+	next  int
+	full  bool
+}
+
+// NewOutputBuffer creates a ring buffer holding up to capacity lines
+func NewOutputBuffer(capacity int) *OutputBuffer {
+	return &OutputBuffer{lines: make([]string, capacity)}
+}
+
+// Append adds a line, overwriting the oldest line when the buffer is full
+func (b *OutputBuffer) Append(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.lines) == 0 {
+		return
+	}
+	b.lines[b.next] = line
+	b.next = (b.next + 1) % len(b.lines)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// Lines returns the buffered lines, oldest first
+func (b *OutputBuffer) Lines() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if !b.full {
+		return append([]string(nil), b.lines[:b.next]...)
+	}
+	lines := make([]string, 0, len(b.lines))
+	lines = append(lines, b.lines[b.next:]...)
+	return append(lines, b.lines[:b.next]...)
+}
+
+// ReadLogTail returns the last n lines of a tunnel log. A missing log has no lines.
+func ReadLogTail(path string, n int) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	buffer := NewOutputBuffer(n)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLogSize)
+	for scanner.Scan() {
+		buffer.Append(scanner.Text())
+	}
+	return buffer.Lines(), scanner.Err()
+}
+
+// openTunnelLog opens a tunnel log for appending, rotating it first if it has grown too large
+func openTunnelLog(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxLogSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return nil, fmt.Errorf("failed to rotate log: %w", err)
+		}
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+// rotateOpenLog moves a log that ssh is still writing to into <path>.1 once it
+// has grown past maxLogSize. ssh holds the file open, so the log is copied and
+// emptied rather than renamed; ssh appends, so it carries on at the start.
+func rotateOpenLog(path string) error {
+	info, err := os.Stat(path)
+	if err != nil || info.Size() <= maxLogSize {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read log: %w", err)
+	}
+	if err := os.WriteFile(path+".1", data, 0644); err != nil {
+		return fmt.Errorf("failed to rotate log: %w", err)
+	}
+	if err := os.Truncate(path, 0); err != nil {
+		return fmt.Errorf("failed to truncate log: %w", err)
+	}
+	return nil
+}
+
+// rotateLogWhileRunning keeps the log at path within maxLogSize until done is
+// closed, so tunnels that stay up for long do not grow it without bound
+func rotateLogWhileRunning(path string, done <-chan struct{}) {
+	ticker := time.NewTicker(logRotateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := rotateOpenLog(path); err != nil {
+				Debug("Failed to rotate %s: %v", path, err)
+			}
+		}
+	}
+}
+
+// writeLogMarker appends a timestamped tunnelman message, such as process
+// start or exit, to a tunnel log
+func writeLogMarker(path, format string, args ...interface{}) {
+	if path == "" {
+		return
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintf(file, "[%s] tunnelman: %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// debugWriter forwards each line of an SSH output stream to the debug log
+type debugWriter struct {
+	tunnelID string
+	stream   string
+	partial  []byte
+}
+
+// Write logs every complete line in p and keeps any trailing partial line
+func (w *debugWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.partial[:i]), "\r")
+		if w.stream == "stdout" {
+			LogSSHOutput(w.tunnelID, line, "")
+		} else {
+			LogSSHOutput(w.tunnelID, "", line)
+		}
+		w.partial = w.partial[i+1:]
+	}
+	return len(p), nil
+}
@@ -0,0 +1,98 @@
+// Package core provides SSH output capture tests.
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestOutputBufferWraps tests that the ring buffer keeps only the newest lines in order
+func TestOutputBufferWraps(t *testing.T) {
+	buffer := NewOutputBuffer(3)
+	if lines := buffer.Lines(); len(lines) != 0 {
+		t.Fatalf("Expected empty buffer, got %v", lines)
+	}
+
+	buffer.Append("a")
+	buffer.Append("b")
+	if lines := buffer.Lines(); !reflect.DeepEqual(lines, []string{"a", "b"}) {
+		t.Errorf("Expected [a b], got %v", lines)
+	}
+
+	buffer.Append("c")
+	buffer.Append("d")
+	buffer.Append("e")
+	if lines := buffer.Lines(); !reflect.DeepEqual(lines, []string{"c", "d", "e"}) {
+		t.Errorf("Expected [c d e], got %v", lines)
+	}
+}
+
+// TestReadLogTail tests reading the last lines of a tunnel log
+func TestReadLogTail(t *testing.T) {
+	dir := t.TempDir()
+
+	lines, err := ReadLogTail(filepath.Join(dir, "missing.log"), 5)
+	if err != nil || len(lines) != 0 {
+		t.Fatalf("Expected no lines and no error for a missing log, got %v, %v", lines, err)
+	}
+
+	var content strings.Builder
+	for i := 1; i <= 10; i++ {
+		fmt.Fprintf(&content, "line %d\n", i)
+	}
+	path := filepath.Join(dir, "tunnel.log")
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	lines, err = ReadLogTail(path, 3)
+	if err != nil {
+		t.Fatalf("ReadLogTail failed: %v", err)
+	}
+	expected := []string{"line 8", "line 9", "line 10"}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("Expected %v, got %v", expected, lines)
+	}
+}
+
+// TestRotateOpenLog tests that a log still held open for appending is rotated
+// once too large and that later writes land at the start of the emptied log
+func TestRotateOpenLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tunnel.log")
+	file, err := openTunnelLog(path)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString("small\n"); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	if err := rotateOpenLog(path); err != nil {
+		t.Fatalf("rotateOpenLog failed: %v", err)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("Expected a small log not to be rotated")
+	}
+
+	if _, err := file.WriteString(strings.Repeat("x", maxLogSize) + "\n"); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+	if err := rotateOpenLog(path); err != nil {
+		t.Fatalf("rotateOpenLog failed: %v", err)
+	}
+	if _, err := file.WriteString("after\n"); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "after\n" {
+		t.Errorf("Expected the log to restart after rotation, got %d bytes", len(data))
+	}
+	if data, _ := os.ReadFile(path + ".1"); !strings.HasPrefix(string(data), "small\n") || len(data) <= maxLogSize {
+		t.Errorf("Expected the rotated log to keep the old output, got %d bytes", len(data))
+	}
+}
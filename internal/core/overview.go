@@ -0,0 +1,92 @@
+// Package core provides the aggregate figures of the dashboard.
+package core
+
+import (
+	"sort"
+	"time"
+)
+
+// ProfileSummary counts the tunnels of a profile by status
+type ProfileSummary struct {
+	Profile    string
+	Running    int
+	Connecting int
+	Stopped    int
+	Failed     int
+}
+
+// Total returns the number of tunnels in the profile
+func (s ProfileSummary) Total() int {
+	return s.Running + s.Connecting + s.Stopped + s.Failed
+}
+
+// SummarizeProfiles counts the tunnels of each profile by status, sorted by profile
+func SummarizeProfiles(tunnels []*Tunnel) []ProfileSummary {
+	byProfile := make(map[string]*ProfileSummary)
+	for _, tunnel := range tunnels {
+		name := profileName(tunnel)
+		summary, ok := byProfile[name]
+		if !ok {
+			summary = &ProfileSummary{Profile: name}
+			byProfile[name] = summary
+		}
+		switch tunnel.Status {
+		case StatusRunning:
+			summary.Running++
+		case StatusConnecting:
+			summary.Connecting++
+		case StatusError:
+			summary.Failed++
+		default:
+			summary.Stopped++
+		}
+	}
+
+	summaries := make([]ProfileSummary, 0, len(byProfile))
+	for _, summary := range byProfile {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Profile < summaries[j].Profile
+	})
+	return summaries
+}
+
+// TunnelFailure is the most recent failure of a tunnel
+type TunnelFailure struct {
+	Tunnel *Tunnel
+	Time   time.Time
+}
+
+// RecentFailures returns up to n tunnels that have failed, most recent
+// failure first, from their statistics
+func RecentFailures(tunnels []*Tunnel, stats map[string]TunnelStats, n int) []TunnelFailure {
+	var failures []TunnelFailure
+	for _, tunnel := range tunnels {
+		if last := stats[tunnel.ID].LastFailure; last != nil {
+			failures = append(failures, TunnelFailure{Tunnel: tunnel, Time: *last})
+		}
+	}
+	sort.SliceStable(failures, func(i, j int) bool {
+		return failures[i].Time.After(failures[j].Time)
+	})
+	if len(failures) > n {
+		failures = failures[:n]
+	}
+	return failures
+}
+
+// LongestRunning returns up to n running tunnels, longest running first
+func LongestRunning(tunnels []*Tunnel, n int) []*Tunnel {
+	var running []*Tunnel
+	for _, tunnel := range tunnels {
+		if tunnel.Status == StatusRunning && tunnel.StartedAt != nil {
+			running = append(running, tunnel)
+		}
+	}
+	SortTunnels(running, SortByUptime, true)
+	if len(running) > n {
+		running = running[:n]
+	}
+	return running
+}
@@ -0,0 +1,42 @@
+// Package core provides dashboard overview tests.
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+// TestOverview tests the per-profile counts, recent failures and longest
+// running tunnels
+func TestOverview(t *testing.T) {
+	now := time.Now()
+	hourAgo, minuteAgo := now.Add(-time.Hour), now.Add(-time.Minute)
+	tunnels := []*Tunnel{
+		{ID: "web", Name: "web", Status: StatusRunning, StartedAt: &minuteAgo},
+		{ID: "api", Name: "api", Status: StatusRunning, StartedAt: &hourAgo, Profile: "prod"},
+		{ID: "db", Name: "db", Status: StatusError, Profile: "prod"},
+		{ID: "cache", Name: "cache", Status: StatusStopped, Profile: "prod"},
+	}
+
+	summaries := SummarizeProfiles(tunnels)
+	if len(summaries) != 2 || summaries[0].Profile != "default" || summaries[1].Profile != "prod" {
+		t.Fatalf("Unexpected profiles: %+v", summaries)
+	}
+	if prod := summaries[1]; prod.Running != 1 || prod.Failed != 1 || prod.Stopped != 1 || prod.Total() != 3 {
+		t.Errorf("Unexpected prod counts: %+v", prod)
+	}
+
+	stats := map[string]TunnelStats{
+		"db":    {LastFailure: &minuteAgo},
+		"cache": {LastFailure: &hourAgo},
+	}
+	failures := RecentFailures(tunnels, stats, 1)
+	if len(failures) != 1 || failures[0].Tunnel.ID != "db" {
+		t.Errorf("Expected db as the most recent failure, got %+v", failures)
+	}
+
+	longest := LongestRunning(tunnels, 5)
+	if len(longest) != 2 || longest[0].ID != "api" || longest[1].ID != "web" {
+		t.Errorf("Expected api then web, got %v", longest)
+	}
+}
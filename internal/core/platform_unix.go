@@ -0,0 +1,67 @@
+//go:build !windows
+
+// Package core provides process and network specifics of Unix-like systems.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// setProcessGroup starts cmd in its own process group, so it can be stopped
+// together with anything it starts
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+}
+
+// terminateProcessGroup sends SIGTERM to the process group led by pid
+func terminateProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGTERM)
+}
+
+// killProcessGroup sends SIGKILL to the process group led by pid
+func killProcessGroup(pid int) error {
+	return syscall.Kill(-pid, syscall.SIGKILL)
+}
+
+// stopProcess asks a single process to exit with SIGTERM, falling back to SIGKILL
+func stopProcess(pid int) error {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process %d: %w", pid, err)
+	}
+
+	if err := process.Signal(syscall.SIGTERM); err != nil {
+		if err := process.Signal(syscall.SIGKILL); err != nil {
+			return fmt.Errorf("failed to kill process %d: %w", pid, err)
+		}
+	}
+	return nil
+}
+
+// isAddrInUse reports whether a listen error means the port is already taken
+func isAddrInUse(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE)
+}
+
+// askpassScriptExt is the file name extension of askpass wrapper scripts
+const askpassScriptExt = ""
+
+// askpassScript returns a shell script that runs executable as ssh's askpass
+// helper with env set, passing on the prompt
+func askpassScript(executable string, env []string) string {
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", name, quoteArg(value))
+	}
+	fmt.Fprintf(&b, "exec %s %s \"$@\"\n", quoteArg(executable), AskpassCommand)
+	return b.String()
+}
@@ -0,0 +1,72 @@
+//go:build windows
+
+// Package core provides process and network specifics of Windows.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// setProcessGroup starts cmd in a new process group, so it can be sent
+// CTRL_BREAK without interrupting tunnelman
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: windows.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// terminateProcessGroup asks the process group led by pid to exit with
+// CTRL_BREAK, which ssh treats like SIGTERM. Processes that do not share
+// tunnelman's console cannot receive it and have their tree killed instead.
+func terminateProcessGroup(pid int) error {
+	if err := windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(pid)); err == nil {
+		return nil
+	}
+	return killProcessGroup(pid)
+}
+
+// killProcessGroup kills pid and every process it started with taskkill
+func killProcessGroup(pid int) error {
+	out, err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(pid)).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("taskkill failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// stopProcess kills a process and its children; a process tunnelman did not
+// start cannot be sent CTRL_BREAK
+func stopProcess(pid int) error {
+	if err := killProcessGroup(pid); err != nil {
+		return fmt.Errorf("failed to kill process %d: %w", pid, err)
+	}
+	return nil
+}
+
+// isAddrInUse reports whether a listen error means the port is already taken
+func isAddrInUse(err error) bool {
+	return errors.Is(err, windows.WSAEADDRINUSE)
+}
+
+// askpassScriptExt is the file name extension of askpass wrapper scripts,
+// which ssh runs through cmd.exe
+const askpassScriptExt = ".cmd"
+
+// askpassScript returns a batch file that runs executable as ssh's askpass
+// helper with env set, passing on the prompt
+func askpassScript(executable string, env []string) string {
+	var b strings.Builder
+	b.WriteString("@echo off\r\n")
+	for _, entry := range env {
+		fmt.Fprintf(&b, "set \"%s\"\r\n", strings.ReplaceAll(entry, "%", "%%"))
+	}
+	fmt.Fprintf(&b, "\"%s\" %s %%*\r\n", executable, AskpassCommand)
+	return b.String()
+}
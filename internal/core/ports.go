@@ -0,0 +1,281 @@
+// Package core provides detection of local port conflicts before tunnels start.
+package core
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PortConflictError reports that a tunnel's local bind port is already in use
+type PortConflictError struct {
+	Port int
+
+	// TunnelName is set when the port is held by another tunnelman tunnel
+	TunnelName string
+
+	// Owner describes the foreign process holding the port, e.g. "nginx (pid 123)",
+	// or is empty if it could not be determined
+	Owner string
+}
+
+// Error implements the error interface
+func (e *PortConflictError) Error() string {
+	switch {
+	case e.TunnelName != "":
+		return fmt.Sprintf("local port %d is already used by tunnel '%s'", e.Port, e.TunnelName)
+	case e.Owner != "":
+		return fmt.Sprintf("local port %d is already in use by %s", e.Port, e.Owner)
+	default:
+		return fmt.Sprintf("local port %d is already in use by another process", e.Port)
+	}
+}
+
+// Is reports that the conflict is an ErrPortInUse
+func (e *PortConflictError) Is(target error) bool {
+	return target == ErrPortInUse
+}
+
+// Hint suggests how to resolve the conflict
+func (e *PortConflictError) Hint() string {
+	if e.TunnelName != "" {
+		return fmt.Sprintf("Stop tunnel '%s' first, or choose a different local port", e.TunnelName)
+	}
+	return failureHints[FailureAddressInUse]
+}
+
+// checkPortConflict returns a *PortConflictError if a local port tunnel would
+// listen on is held by another running tunnel or by a foreign process
+func (tm *TunnelManager) checkPortConflict(tunnel *Tunnel) error {
+	var listeners []Forward
+	for _, f := range tunnel.AllForwards() {
+		if !f.IsRemote() {
+			listeners = append(listeners, f)
+		}
+	}
+	if len(listeners) == 0 {
+		return nil
+	}
+
+	tm.mu.RLock()
+	pids := make(map[int]string)
+	for _, other := range tm.tunnels {
+		if other.ID == tunnel.ID {
+			continue
+		}
+		if other.Status != StatusRunning && other.Status != StatusConnecting {
+			continue
+		}
+		for _, theirs := range other.forwardsLocked() {
+			if theirs.IsRemote() {
+				continue
+			}
+			for _, ours := range listeners {
+				if theirs.LocalPort == ours.LocalPort && bindHostsOverlap(theirs.LocalHost, ours.LocalHost) {
+					tm.mu.RUnlock()
+					return &PortConflictError{Port: ours.LocalPort, TunnelName: other.Name}
+				}
+			}
+		}
+		if other.PID > 0 {
+			pids[other.PID] = other.Name
+		}
+	}
+	tm.mu.RUnlock()
+
+	for _, f := range listeners {
+		if err := checkPortFree(f, pids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckLocalPort reports whether a tunnel could listen on host:port. It
+// returns the names of other configured tunnels, running or not, that listen
+// on the same address, and a *PortConflictError if the port is taken right
+// now. id is the tunnel being edited and is ignored, or empty for a new one.
+func (tm *TunnelManager) CheckLocalPort(id, host string, port int) ([]string, error) {
+	var names []string
+	pids := make(map[int]string)
+	holding := false
+
+	tm.mu.RLock()
+	for _, other := range tm.tunnels {
+		if other.ID == id {
+			// A running tunnel holds its own port, which is no conflict
+			if other.Status == StatusRunning || other.Status == StatusConnecting {
+				for _, ours := range other.forwardsLocked() {
+					holding = holding || !ours.IsRemote() && ours.LocalPort == port && bindHostsOverlap(ours.LocalHost, host)
+				}
+			}
+			continue
+		}
+		for _, theirs := range other.forwardsLocked() {
+			if !theirs.IsRemote() && theirs.LocalPort == port && bindHostsOverlap(theirs.LocalHost, host) {
+				names = append(names, other.Name)
+				break
+			}
+		}
+		if other.PID > 0 && (other.Status == StatusRunning || other.Status == StatusConnecting) {
+			pids[other.PID] = other.Name
+		}
+	}
+	tm.mu.RUnlock()
+	sort.Strings(names)
+
+	if holding || port <= 0 || port > 65535 {
+		return names, nil
+	}
+	return names, checkPortFree(Forward{LocalHost: host, LocalPort: port}, pids)
+}
+
+// checkPortFree tries to listen on a forward's local port, identifying the
+// owner of the port if it is taken. pids maps the PIDs of running tunnels to
+// their names.
+func checkPortFree(f Forward, pids map[int]string) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort(listenHost(f.LocalHost), strconv.Itoa(f.LocalPort)))
+	if err == nil {
+		listener.Close()
+		return nil
+	}
+	// Other failures, such as privileged ports, are left for ssh to report
+	if !isAddrInUse(err) {
+		return nil
+	}
+
+	conflict := &PortConflictError{Port: f.LocalPort}
+	if pid, command := findPortOwner(f.LocalPort); pid > 0 {
+		if name, ok := pids[pid]; ok {
+			conflict.TunnelName = name
+		} else if command != "" {
+			conflict.Owner = fmt.Sprintf("%s (pid %d)", command, pid)
+		} else {
+			conflict.Owner = fmt.Sprintf("pid %d", pid)
+		}
+	}
+	return conflict
+}
+
+// listenHost returns the address to probe for an ssh bind address. An empty
+// bind address or "*" makes ssh listen on all interfaces.
+func listenHost(bindHost string) string {
+	if bindHost == "*" {
+		return ""
+	}
+	return bindHost
+}
+
+// bindHostsOverlap reports whether listeners on the two bind addresses would
+// collide on the same port
+func bindHostsOverlap(a, b string) bool {
+	normalize := func(host string) string {
+		switch host {
+		case "", "*", "0.0.0.0", "::":
+			return ""
+		case "localhost":
+			return "127.0.0.1"
+		}
+		return host
+	}
+	a, b = normalize(a), normalize(b)
+	return a == "" || b == "" || a == b
+}
+
+// findPortOwner returns the PID and command name of the process listening on
+// a TCP port, or 0 if it cannot be determined
+func findPortOwner(port int) (int, string) {
+	if IsWindows() {
+		out, err := exec.Command("netstat", "-ano", "-p", "TCP").Output()
+		if err != nil {
+			return 0, ""
+		}
+		pid := parseNetstatOwner(string(out), port)
+		if pid == 0 {
+			return 0, ""
+		}
+		out, err = exec.Command("tasklist", "/FI", fmt.Sprintf("PID eq %d", pid), "/FO", "CSV", "/NH").Output()
+		if err != nil {
+			return pid, ""
+		}
+		return pid, parseTasklistName(string(out))
+	}
+
+	if out, err := exec.Command("lsof", "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Fpc").Output(); err == nil {
+		if pid, command := parseLsofOwner(string(out)); pid > 0 {
+			return pid, command
+		}
+	}
+	if IsLinux() {
+		if out, err := exec.Command("ss", "-Hltnp", fmt.Sprintf("sport = :%d", port)).Output(); err == nil {
+			return parseSSOwner(string(out))
+		}
+	}
+	return 0, ""
+}
+
+// parseLsofOwner extracts the first process from `lsof -Fpc` output
+func parseLsofOwner(output string) (int, string) {
+	var pid int
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			if pid > 0 {
+				return pid, ""
+			}
+			pid, _ = strconv.Atoi(line[1:])
+		case 'c':
+			if pid > 0 {
+				return pid, line[1:]
+			}
+		}
+	}
+	return pid, ""
+}
+
+// parseNetstatOwner returns the PID listening on a TCP port from Windows
+// `netstat -ano` output, or 0 if none is
+func parseNetstatOwner(output string, port int) int {
+	suffix := ":" + strconv.Itoa(port)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 5 || fields[0] != "TCP" || fields[3] != "LISTENING" {
+			continue
+		}
+		if strings.HasSuffix(fields[1], suffix) {
+			pid, _ := strconv.Atoi(fields[4])
+			return pid
+		}
+	}
+	return 0
+}
+
+// parseTasklistName returns the image name from `tasklist /FO CSV /NH` output
+func parseTasklistName(output string) string {
+	line := strings.TrimSpace(strings.Split(output, "\n")[0])
+	if !strings.HasPrefix(line, `"`) {
+		return ""
+	}
+	name, _, _ := strings.Cut(line[1:], `"`)
+	return name
+}
+
+// ssUsersPattern matches the first process in the users column of `ss -p` output
+var ssUsersPattern = regexp.MustCompile(`users:\(\("([^"]*)",pid=(\d+)`)
+
+// parseSSOwner extracts the first process from `ss -ltnp` output
+func parseSSOwner(output string) (int, string) {
+	match := ssUsersPattern.FindStringSubmatch(output)
+	if match == nil {
+		return 0, ""
+	}
+	pid, _ := strconv.Atoi(match[2])
+	return pid, match[1]
+}
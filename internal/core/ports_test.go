@@ -0,0 +1,182 @@
+// Package core provides port conflict detection tests.
+package core
+
+import (
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestStartTunnelDetectsPortConflicts tests that a tunnel is not started when
+// its local port is held by another tunnel or a foreign process
+func TestStartTunnelDetectsPortConflicts(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tm, _ := newTestManager(t, "")
+	tunnel := &Tunnel{
+		ID:         "web",
+		Name:       "web",
+		Type:       LocalForward,
+		LocalHost:  "127.0.0.1",
+		LocalPort:  port,
+		RemoteHost: "localhost",
+		RemotePort: 80,
+		SSHHost:    "example.com",
+		Status:     StatusStopped,
+	}
+	tm.tunnels[tunnel.ID] = tunnel
+	changes := tm.Subscribe()
+	defer tm.Unsubscribe(changes)
+
+	var conflict *PortConflictError
+	err = tm.StartTunnel(tunnel.ID)
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected a port conflict, got %v", err)
+	}
+	if conflict.Port != port || conflict.TunnelName != "" {
+		t.Errorf("Unexpected conflict: %+v", conflict)
+	}
+	if conflict.Owner != "" && conflict.Owner[len(conflict.Owner)-1] != ')' {
+		t.Errorf("Expected owner with a pid, got %q", conflict.Owner)
+	}
+	if got, _ := tm.GetTunnel(tunnel.ID); got.Status != StatusError || got.LastError == nil {
+		t.Errorf("Expected error status, got %s (%v)", got.Status, got.LastError)
+	}
+
+	// The tunnel counts as connecting while its ports are checked
+	for _, want := range []TunnelStatus{StatusConnecting, StatusError} {
+		select {
+		case change := <-changes:
+			if change.NewStatus != want {
+				t.Errorf("Expected a change to %s, got %s", want, change.NewStatus)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected a change to %s", want)
+		}
+	}
+
+	tm.tunnels["db"] = &Tunnel{
+		ID:        "db",
+		Name:      "db",
+		Type:      DynamicForward,
+		LocalHost: "",
+		LocalPort: port,
+		SSHHost:   "example.com",
+		Status:    StatusRunning,
+		PID:       os.Getpid(),
+	}
+	err = tm.StartTunnel(tunnel.ID)
+	if !errors.As(err, &conflict) || conflict.TunnelName != "db" {
+		t.Fatalf("Expected a conflict with tunnel db, got %v", err)
+	}
+
+	// A tunnel that is still connecting is not started a second time
+	tunnel.Status = StatusConnecting
+	if err := tm.StartTunnel(tunnel.ID); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Expected a connecting tunnel to be refused, got %v", err)
+	}
+}
+
+// TestParsePortOwner tests parsing of lsof, ss, netstat and tasklist listener output
+func TestParsePortOwner(t *testing.T) {
+	if pid, command := parseLsofOwner("p1234\ncnginx\nf6\n"); pid != 1234 || command != "nginx" {
+		t.Errorf("lsof: expected nginx (1234), got %s (%d)", command, pid)
+	}
+	if pid, _ := parseLsofOwner(""); pid != 0 {
+		t.Errorf("lsof: expected no owner, got %d", pid)
+	}
+
+	ss := `LISTEN 0      4096   127.0.0.1:8080  0.0.0.0:*    users:(("python3",pid=42,fd=3))`
+	if pid, command := parseSSOwner(ss); pid != 42 || command != "python3" {
+		t.Errorf("ss: expected python3 (42), got %s (%d)", command, pid)
+	}
+	if pid, _ := parseSSOwner("LISTEN 0 4096 127.0.0.1:8080 0.0.0.0:*"); pid != 0 {
+		t.Errorf("ss: expected no owner, got %d", pid)
+	}
+
+	netstat := `
+Active Connections
+
+  Proto  Local Address          Foreign Address        State           PID
+  TCP    0.0.0.0:135            0.0.0.0:0              LISTENING       960
+  TCP    127.0.0.1:18080        0.0.0.0:0              LISTENING       77
+  TCP    127.0.0.1:8080         0.0.0.0:0              LISTENING       4321
+  TCP    127.0.0.1:8080         127.0.0.1:50000        ESTABLISHED     99
+`
+	if pid := parseNetstatOwner(netstat, 8080); pid != 4321 {
+		t.Errorf("netstat: expected 4321, got %d", pid)
+	}
+	if pid := parseNetstatOwner(netstat, 9090); pid != 0 {
+		t.Errorf("netstat: expected no owner, got %d", pid)
+	}
+
+	if name := parseTasklistName(`"nginx.exe","4321","Console","1","10,000 K"` + "\r\n"); name != "nginx.exe" {
+		t.Errorf("tasklist: expected nginx.exe, got %q", name)
+	}
+	if name := parseTasklistName("INFO: No tasks are running which match the specified criteria.\r\n"); name != "" {
+		t.Errorf("tasklist: expected no name, got %q", name)
+	}
+}
+
+// TestBindHostsOverlap tests which bind addresses collide on the same port
+func TestBindHostsOverlap(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"127.0.0.1", "127.0.0.1", true},
+		{"localhost", "127.0.0.1", true},
+		{"", "127.0.0.1", true},
+		{"0.0.0.0", "10.0.0.1", true},
+		{"127.0.0.1", "10.0.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := bindHostsOverlap(tt.a, tt.b); got != tt.want {
+			t.Errorf("bindHostsOverlap(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestCheckLocalPort tests that the port check reports configured tunnels on
+// the same address and ports that are taken right now
+func TestCheckLocalPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	tm, _ := newTestManager(t, "")
+	tm.tunnels["web"] = &Tunnel{ID: "web", Name: "web", Type: LocalForward, LocalPort: 8080, RemotePort: 80, Status: StatusStopped}
+	tm.tunnels["api"] = &Tunnel{ID: "api", Name: "api", Type: LocalForward, LocalHost: "127.0.0.2", LocalPort: 9000, RemotePort: 80,
+		Forwards: []Forward{{Type: LocalForward, LocalHost: "127.0.0.1", LocalPort: 8080, RemotePort: 81}}}
+	tm.tunnels["back"] = &Tunnel{ID: "back", Name: "back", Type: RemoteForward, LocalPort: 8080, RemotePort: 8080}
+
+	names, _ := tm.CheckLocalPort("", "127.0.0.1", 8080)
+	if len(names) != 2 || names[0] != "api" || names[1] != "web" {
+		t.Errorf("Expected [api web], got %v", names)
+	}
+	if names, _ := tm.CheckLocalPort("web", "127.0.0.1", 8080); len(names) != 1 || names[0] != "api" {
+		t.Errorf("Expected the edited tunnel to be ignored, got %v", names)
+	}
+	if names, _ := tm.CheckLocalPort("", "127.0.0.3", 9000); len(names) != 0 {
+		t.Errorf("Expected no tunnels on another address, got %v", names)
+	}
+
+	var conflict *PortConflictError
+	if _, err := tm.CheckLocalPort("", "127.0.0.1", port); !errors.As(err, &conflict) || conflict.Port != port {
+		t.Errorf("Expected a port conflict on %d, got %v", port, err)
+	}
+	listener.Close()
+	if _, err := tm.CheckLocalPort("", "127.0.0.1", port); err != nil {
+		t.Errorf("Expected a free port, got %v", err)
+	}
+}
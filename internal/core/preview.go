@@ -0,0 +1,68 @@
+// Package core provides dry runs of the ssh command a tunnel starts.
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preview returns the exact command line StartTunnel would execute for a
+// tunnel, with defaults, connection sharing and extra arguments resolved,
+// without starting anything. It fails for the same configuration and ssh
+// problems that would stop the tunnel from starting.
+func (tm *TunnelManager) Preview(id string) ([]string, error) {
+	tm.mu.RLock()
+	tunnel, exists := tm.tunnels[id]
+	if exists {
+		tunnel = tunnel.Clone()
+	}
+	tm.mu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrTunnelNotFound, id)
+	}
+
+	if err := tunnel.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid tunnel configuration: %w", err)
+	}
+	version, err := tm.processManager.SSHVersion()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSSHFeatures(tunnel, version); err != nil {
+		return nil, err
+	}
+	if err := checkIdentityFile(tunnel); err != nil {
+		return nil, err
+	}
+
+	return tm.SSHCommand(tunnel), nil
+}
+
+// QuoteCommand joins a command line into a string that can be pasted into a
+// POSIX shell, quoting arguments that need it
+func QuoteCommand(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		quoted = append(quoted, quoteArg(arg))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// quoteArg single-quotes an argument unless it only has characters the shell
+// leaves alone
+func quoteArg(arg string) string {
+	if arg == "" {
+		return "''"
+	}
+	safe := true
+	for _, c := range arg {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("@%_+=:,./-", c)) {
+			safe = false
+			break
+		}
+	}
+	if safe {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,63 @@
+// Package core provides ssh command preview tests.
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestPreview tests that a preview shows the resolved ssh command without
+// starting the tunnel, and reports tunnels that would not start
+func TestPreview(t *testing.T) {
+	fakeSSH := filepath.Join(t.TempDir(), "ssh")
+	script := "#!/bin/sh\nif [ \"$1\" = \"-V\" ]; then echo \"OpenSSH_9.6p1\" >&2; exit 0; fi\nexit 1\n"
+	if err := os.WriteFile(fakeSSH, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write fake ssh: %v", err)
+	}
+	t.Setenv(SSHPathEnv, fakeSSH)
+
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "example.com", "bindAddress": "127.0.0.1", "localPort": 5432, "remotePort": 5432, "mode": "local", "options": ["-i", "my key"]},
+    {"id": "bad", "name": "bad", "host": "example.com", "localPort": 0, "mode": "dynamic"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+
+	command, err := tm.Preview("db")
+	if err != nil {
+		t.Fatalf("Failed to preview: %v", err)
+	}
+	if command[0] != fakeSSH || command[len(command)-1] != "example.com" {
+		t.Errorf("Unexpected command: %q", command)
+	}
+	quoted := QuoteCommand(command)
+	for _, part := range []string{"-L 127.0.0.1:5432:127.0.0.1:5432", "-i 'my key'", "ServerAliveInterval="} {
+		if !strings.Contains(quoted, part) {
+			t.Errorf("Expected %q in %s", part, quoted)
+		}
+	}
+	if tunnel, _ := tm.GetTunnel("db"); tunnel.Status != StatusStopped || tunnel.PID != 0 {
+		t.Errorf("Expected the preview not to start the tunnel, got %s", tunnel.Status)
+	}
+
+	if _, err := tm.Preview("bad"); err == nil {
+		t.Error("Expected an invalid tunnel to be reported")
+	}
+	if _, err := tm.Preview("missing"); !errors.Is(err, ErrTunnelNotFound) {
+		t.Errorf("Expected ErrTunnelNotFound, got %v", err)
+	}
+}
+
+// TestQuoteCommand tests that arguments are quoted only when the shell needs it
+func TestQuoteCommand(t *testing.T) {
+	got := QuoteCommand([]string{"ssh", "-o", "ProxyCommand=nc %h %p", "", "it's", "user@host"})
+	expected := `ssh -o 'ProxyCommand=nc %h %p' '' 'it'\''s' user@host`
+	if got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
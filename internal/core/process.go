@@ -3,15 +3,18 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
-	"syscall"
 	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
 )
 
 // ProcessManager handles SSH process lifecycle operations
@@ -22,6 +25,29 @@ type ProcessManager struct {
 	// Logger for debug output
 	logger *log.Logger
 
+	// Directory for per-tunnel output logs; empty disables log files
+	logDir string
+
+	// Directory for control sockets of multiplexed tunnels; empty disables multiplexing
+	controlDir string
+
+	// Directory for known_hosts files of tunnels with pinned host keys; empty disables pinning
+	hostKeyDir string
+
+	// Keepalive and forward failure settings for tunnels that do not set their own
+	defaults ConnectionDefaults
+
+	// ssh executable, the error resolving it, and its version once detected
+	sshPath    string
+	sshErr     error
+	sshVersion *SSHVersion
+
+	// Directory for the askpass wrapper scripts of tunnels; empty disables prompts
+	askpassDir string
+
+	// Environment the askpass helper needs to reach tunnelman; nil if prompts are disabled
+	askpass []string
+
 	// Process tracking
 	mu        sync.RWMutex
 	processes map[string]*ProcessInfo
@@ -45,9 +71,35 @@ type ProcessInfo struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	// Output handlers for debug mode
-	stdoutReader io.ReadCloser
-	stderrReader io.ReadCloser
+	// Log file receiving the process output, closed once it exits
+	logFile *os.File
+
+	// done is closed once the process has exited, after exit is set
+	done chan struct{}
+	exit ProcessExit
+}
+
+// ProcessExit describes how an SSH process ended
+type ProcessExit struct {
+	// Err is the error returned by waiting for the process
+	Err error
+
+	// ExitCode is the exit status, or -1 if the process was killed by a signal
+	ExitCode int
+
+	// Unexpected is true if the process exited without being disconnected
+	Unexpected bool
+}
+
+// Done returns a channel that is closed once the process has exited
+func (info *ProcessInfo) Done() <-chan struct{} {
+	return info.done
+}
+
+// Exit waits for the process to exit and reports how it ended
+func (info *ProcessInfo) Exit() ProcessExit {
+	<-info.done
+	return info.exit
 }
 
 // ProcessManagerOption is a functional option for ProcessManager
@@ -67,6 +119,13 @@ func WithLogger(logger *log.Logger) ProcessManagerOption {
 	}
 }
 
+// WithLogDir sets the directory where per-tunnel output logs are written
+func WithLogDir(dir string) ProcessManagerOption {
+	return func(pm *ProcessManager) {
+		pm.logDir = dir
+	}
+}
+
 // NewProcessManager creates a new process manager instance
 func NewProcessManager(opts ...ProcessManagerOption) *ProcessManager {
 	pm := &ProcessManager{
@@ -74,6 +133,8 @@ func NewProcessManager(opts ...ProcessManagerOption) *ProcessManager {
 		logger:    log.New(os.Stderr, "[ProcessManager] ", log.LstdFlags),
 	}
 
+	pm.sshPath, pm.sshErr = ResolveSSHPath("")
+
 	// Apply options
 	for _, opt := range opts {
 		opt(pm)
@@ -82,8 +143,74 @@ func NewProcessManager(opts ...ProcessManagerOption) *ProcessManager {
 	return pm
 }
 
+// SetSSHPath sets the ssh executable from a configured path, which the
+// TUNNELMAN_SSH environment variable overrides. An empty path uses ssh from
+// PATH. It returns an error if the executable does not exist.
+func (pm *ProcessManager) SetSSHPath(configured string) error {
+	path, err := ResolveSSHPath(configured)
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.sshPath, pm.sshErr = path, err
+	pm.sshVersion = nil
+	return err
+}
+
+// SSHPath returns the ssh executable tunnels are started with
+func (pm *ProcessManager) SSHPath() string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+
+	if pm.sshPath == "" {
+		return "ssh"
+	}
+	return pm.sshPath
+}
+
+// SSHVersion returns the version of the ssh executable, detecting it on first
+// use. It returns an error if the executable does not exist.
+func (pm *ProcessManager) SSHVersion() (SSHVersion, error) {
+	pm.mu.RLock()
+	sshPath, sshErr, cached := pm.sshPath, pm.sshErr, pm.sshVersion
+	pm.mu.RUnlock()
+
+	if sshErr != nil {
+		return SSHVersion{}, sshErr
+	}
+	if cached != nil {
+		return *cached, nil
+	}
+
+	// Run ssh without the lock, so starts and stops are not held up by it
+	version, err := DetectSSHVersion(sshPath)
+	if err != nil {
+		Debug("Could not detect ssh version: %v", err)
+	} else if !version.Known() {
+		Debug("%s is not OpenSSH, skipping version checks", sshPath)
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	// Keep the version only if the executable was not changed meanwhile
+	if pm.sshPath == sshPath {
+		pm.sshVersion = &version
+	}
+	return version, nil
+}
+
+// SetConnectionDefaults sets the keepalive and forward failure settings used
+// for tunnels that do not set their own
+func (pm *ProcessManager) SetConnectionDefaults(defaults ConnectionDefaults) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	pm.defaults = defaults
+}
+
 // Connect establishes an SSH tunnel connection
-func (pm *ProcessManager) Connect(tunnel *Tunnel) (*PidEntry, error) {
+func (pm *ProcessManager) Connect(tunnel *Tunnel) (*ProcessInfo, error) {
 	if tunnel == nil {
 		return nil, fmt.Errorf("tunnel cannot be nil")
 	}
@@ -93,40 +220,66 @@ func (pm *ProcessManager) Connect(tunnel *Tunnel) (*PidEntry, error) {
 		return nil, fmt.Errorf("invalid tunnel configuration: %w", err)
 	}
 
+	// Make sure the ssh executable exists and supports the tunnel's options
+	version, err := pm.SSHVersion()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSSHFeatures(tunnel, version); err != nil {
+		return nil, err
+	}
+	if err := checkIdentityFile(tunnel); err != nil {
+		return nil, err
+	}
+	if err := pm.preparePinnedHostKey(tunnel); err != nil {
+		return nil, err
+	}
+	sshPath := pm.SSHPath()
+
+	if pm.multiplexes(tunnel) {
+		if err := pm.prepareControlDir(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build SSH command arguments
 	args := pm.buildSSHArgs(tunnel)
 
 	if pm.debug {
-		LogSSHCommand(tunnel.Name, append([]string{"ssh"}, args...))
+		LogSSHCommand(tunnel.Name, append([]string{sshPath}, args...))
 	}
 
 	// Create command
-	cmd := exec.Command("ssh", args...)
+	cmd := exec.Command(sshPath, args...)
 
 	// Set process group for clean termination
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
-	}
-
-	// Setup output handling for debug mode
-	if pm.debug {
-		stdout, err := cmd.StdoutPipe()
-		if err != nil {
-			return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	setProcessGroup(cmd)
+
+	// Let ssh ask for passwords through tunnelman, as it has no terminal
+	if env := pm.askpassEnv(tunnel); env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	// SSH writes straight to the tunnel's log file so its output is kept after
+	// tunnelman exits; debug mode also copies it to the debug log
+	logPath := pm.LogPath(tunnel.ID)
+	var logFile *os.File
+	if logPath != "" {
+		var err error
+		if logFile, err = openTunnelLog(logPath); err != nil {
+			Warn("Failed to open log for tunnel %s: %v", tunnel.Name, err)
+			logPath = ""
 		}
-
-		stderr, err := cmd.StderrPipe()
-		if err != nil {
-			return nil, fmt.Errorf("failed to create stderr pipe: %w", err)
-		}
-
-		// Start output monitoring goroutines
-		go pm.monitorOutput("stdout", tunnel.ID, stdout)
-		go pm.monitorOutput("stderr", tunnel.ID, stderr)
 	}
+	pm.setOutput(cmd, tunnel.ID, logFile)
+	writeLogMarker(logPath, "starting ssh %s", strings.Join(args, " "))
 
 	// Start the SSH process
 	if err := cmd.Start(); err != nil {
+		writeLogMarker(logPath, "failed to start ssh: %v", err)
+		if logFile != nil {
+			logFile.Close()
+		}
 		return nil, fmt.Errorf("failed to start SSH process: %w", err)
 	}
 
@@ -141,6 +294,8 @@ func (pm *ProcessManager) Connect(tunnel *Tunnel) (*PidEntry, error) {
 		StartedAt: time.Now(),
 		ctx:       ctx,
 		cancel:    cancel,
+		logFile:   logFile,
+		done:      make(chan struct{}),
 	}
 
 	pm.mu.Lock()
@@ -151,13 +306,13 @@ func (pm *ProcessManager) Connect(tunnel *Tunnel) (*PidEntry, error) {
 		pm.logger.Printf("SSH process started for tunnel %s (PID: %d)", tunnel.ID, cmd.Process.Pid)
 	}
 
-	// Create PID entry for storage
-	pidEntry := NewPidEntry(cmd.Process.Pid, tunnel.ID)
-
 	// Monitor process lifecycle in background
 	go pm.monitorProcess(tunnel.ID, processInfo)
+	if logFile != nil {
+		go rotateLogWhileRunning(logPath, processInfo.done)
+	}
 
-	return pidEntry, nil
+	return processInfo, nil
 }
 
 // Disconnect terminates an SSH tunnel connection
@@ -193,13 +348,8 @@ func (pm *ProcessManager) Disconnect(id string, pid int) error {
 	}
 
 	// Wait for process to exit with timeout
-	done := make(chan error, 1)
-	go func() {
-		done <- processInfo.Cmd.Wait()
-	}()
-
 	select {
-	case <-done:
+	case <-processInfo.done:
 		if pm.debug {
 			pm.logger.Printf("Process %d terminated successfully", processInfo.PID)
 		}
@@ -211,9 +361,11 @@ func (pm *ProcessManager) Disconnect(id string, pid int) error {
 		}
 	}
 
-	// Clean up process info
+	// Clean up process info, unless the tunnel has been started again meanwhile
 	pm.mu.Lock()
-	delete(pm.processes, id)
+	if pm.processes[id] == processInfo {
+		delete(pm.processes, id)
+	}
 	pm.mu.Unlock()
 
 	return nil
@@ -228,6 +380,49 @@ func (pm *ProcessManager) GetProcessInfo(id string) (*ProcessInfo, bool) {
 	return info, exists
 }
 
+// RecentOutput returns the most recent lines of a tunnel's log, including
+// output of processes started by other tunnelman instances
+func (pm *ProcessManager) RecentOutput(id string) []string {
+	path := pm.LogPath(id)
+	if path == "" {
+		return nil
+	}
+	lines, err := ReadLogTail(path, outputBufferLines)
+	if err != nil && pm.debug {
+		pm.logger.Printf("Failed to read log for tunnel %s: %v", id, err)
+	}
+	return lines
+}
+
+// LogPath returns the path of a tunnel's output log, or "" if logging to files is disabled
+func (pm *ProcessManager) LogPath(id string) string {
+	if pm.logDir == "" {
+		return ""
+	}
+	return filepath.Join(pm.logDir, id+".log")
+}
+
+// setOutput directs the SSH process output to logFile (which may be nil) and,
+// in debug mode, to the debug log
+func (pm *ProcessManager) setOutput(cmd *exec.Cmd, tunnelID string, logFile *os.File) {
+	if !pm.debug {
+		if logFile != nil {
+			cmd.Stdout = logFile
+			cmd.Stderr = logFile
+		}
+		return
+	}
+
+	stdout := io.Writer(&debugWriter{tunnelID: tunnelID, stream: "stdout"})
+	stderr := io.Writer(&debugWriter{tunnelID: tunnelID, stream: "stderr"})
+	if logFile != nil {
+		stdout = io.MultiWriter(logFile, stdout)
+		stderr = io.MultiWriter(logFile, stderr)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+}
+
 // GetAllProcesses returns all running processes
 func (pm *ProcessManager) GetAllProcesses() map[string]*ProcessInfo {
 	pm.mu.RLock()
@@ -245,46 +440,48 @@ func (pm *ProcessManager) GetAllProcesses() map[string]*ProcessInfo {
 func (pm *ProcessManager) buildSSHArgs(tunnel *Tunnel) []string {
 	var args []string
 
-	// Add tunnel type specific options
-	switch tunnel.Type {
-	case LocalForward:
-		// -L [bind_address:]port:host:hostport
-		forward := fmt.Sprintf("%s:%d:%s:%d",
-			tunnel.LocalHost, tunnel.LocalPort,
-			tunnel.RemoteHost, tunnel.RemotePort)
-		args = append(args, "-L", forward)
-
-	case RemoteForward:
-		// -R [bind_address:]port:host:hostport
-		// RemotePort on remote side forwards to LocalHost:LocalPort
-		// Omitting bind address to use server's default (usually 127.0.0.1)
-		// For external access, server must have GatewayPorts enabled
-		localHost := tunnel.LocalHost
-		if localHost == "" || localHost == "0.0.0.0" {
-			// For RemoteForward, we need a valid destination address
-			localHost = "127.0.0.1"
-		}
-		forward := fmt.Sprintf("%d:%s:%d",
-			tunnel.RemotePort, localHost, tunnel.LocalPort)
-		args = append(args, "-R", forward)
-
-	case DynamicForward:
-		// -D [bind_address:]port
-		args = append(args, "-D", fmt.Sprintf("%s:%d", tunnel.LocalHost, tunnel.LocalPort))
+	// Add tunnel type specific options, one flag per forward
+	for _, forward := range tunnel.AllForwards() {
+		args = append(args, forward.Args()...)
 	}
 
 	// Common SSH options for tunnel stability
 	args = append(args,
 		"-N",                             // No command execution (port forwarding only)
 		"-T",                             // Disable pseudo-terminal allocation
-		"-o", "ServerAliveInterval=60",  // Keep connection alive
-		"-o", "ServerAliveCountMax=3",   // Max keepalive attempts
-		"-o", "ExitOnForwardFailure=yes", // Exit if port forwarding fails
-		"-o", "StrictHostKeyChecking=accept-new", // Auto-accept new host keys
-		"-o", "ControlMaster=no",         // Don't use connection sharing
-		"-o", "ControlPath=none",         // No control socket
 	)
 
+	// Keepalives and forward failure handling, from the tunnel or the defaults
+	pm.mu.RLock()
+	args = append(args, pm.defaults.args(tunnel)...)
+	pm.mu.RUnlock()
+
+	// Verify the host's key against the pinned one, or ask about new keys
+	args = append(args, pm.hostKeyArgs(tunnel)...)
+
+	// Share a master connection with other tunnels to the host, or opt out
+	args = append(args, pm.controlArgs(tunnel)...)
+
+	// Authenticate with the pinned key only, not every key the agent holds
+	if tunnel.IdentityFile != "" {
+		args = append(args, "-i", ExpandHome(tunnel.IdentityFile), "-o", "IdentitiesOnly=yes")
+	}
+
+	// Present the certificate signed for the key
+	if tunnel.CertificateFile != "" {
+		args = append(args, "-o", "CertificateFile="+ExpandHome(tunnel.CertificateFile))
+	}
+
+	// Compress the session for low-bandwidth links
+	if tunnel.Compression {
+		args = append(args, "-C")
+	}
+
+	// Connect through the jump host chain
+	if tunnel.JumpHost != "" {
+		args = append(args, "-J", tunnel.JumpHost)
+	}
+
 	// Add any extra arguments
 	if len(tunnel.ExtraArgs) > 0 {
 		args = append(args, tunnel.ExtraArgs...)
@@ -301,16 +498,14 @@ func (pm *ProcessManager) buildSSHArgs(tunnel *Tunnel) []string {
 	return args
 }
 
-// terminateProcess sends SIGTERM to a process and its group
+// terminateProcess asks a process and its group to exit
 func (pm *ProcessManager) terminateProcess(process *os.Process) error {
-	// Send SIGTERM to the process group
-	return syscall.Kill(-process.Pid, syscall.SIGTERM)
+	return terminateProcessGroup(process.Pid)
 }
 
-// killProcess sends SIGKILL to a process and its group
+// killProcess forcibly kills a process and its group
 func (pm *ProcessManager) killProcess(process *os.Process) error {
-	// Send SIGKILL to the process group
-	return syscall.Kill(-process.Pid, syscall.SIGKILL)
+	return killProcessGroup(process.Pid)
 }
 
 // killProcessByPID attempts to kill a process by PID only
@@ -319,21 +514,7 @@ func (pm *ProcessManager) killProcessByPID(pid int) error {
 		return fmt.Errorf("invalid PID: %d", pid)
 	}
 
-	// Find process by PID
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return fmt.Errorf("failed to find process %d: %w", pid, err)
-	}
-
-	// Try SIGTERM first
-	if err := process.Signal(syscall.SIGTERM); err != nil {
-		// Try SIGKILL if SIGTERM fails
-		if err := process.Signal(syscall.SIGKILL); err != nil {
-			return fmt.Errorf("failed to kill process %d: %w", pid, err)
-		}
-	}
-
-	return nil
+	return stopProcess(pid)
 }
 
 // monitorProcess monitors a running SSH process
@@ -341,6 +522,15 @@ func (pm *ProcessManager) monitorProcess(tunnelID string, info *ProcessInfo) {
 	// Wait for process to exit
 	err := info.Cmd.Wait()
 
+	if info.logFile != nil {
+		info.logFile.Close()
+		if err != nil {
+			writeLogMarker(info.logFile.Name(), "ssh exited: %v", err)
+		} else {
+			writeLogMarker(info.logFile.Name(), "ssh exited")
+		}
+	}
+
 	if pm.debug {
 		if err != nil {
 			pm.logger.Printf("Process for tunnel %s exited with error: %v", tunnelID, err)
@@ -351,46 +541,36 @@ func (pm *ProcessManager) monitorProcess(tunnelID string, info *ProcessInfo) {
 
 	// Clean up process info
 	pm.mu.Lock()
-	delete(pm.processes, tunnelID)
+	if pm.processes[tunnelID] == info {
+		delete(pm.processes, tunnelID)
+	}
 	pm.mu.Unlock()
+
+	// Tell waiters how ssh exited, and whether it was disconnected on purpose
+	info.exit = ProcessExit{
+		Err:        err,
+		ExitCode:   exitCode(err),
+		Unexpected: info.ctx.Err() == nil,
+	}
+	close(info.done)
 }
 
-// monitorOutput monitors and logs process output in debug mode
-func (pm *ProcessManager) monitorOutput(streamName string, tunnelID string, reader io.ReadCloser) {
-	defer reader.Close()
-
-	var output strings.Builder
-	buffer := make([]byte, 4096)
-	for {
-		n, err := reader.Read(buffer)
-		if n > 0 {
-			output.Write(buffer[:n])
-			// Log the output using the logger
-			if streamName == "stdout" {
-				LogSSHOutput(tunnelID, string(buffer[:n]), "")
-			} else {
-				LogSSHOutput(tunnelID, "", string(buffer[:n]))
-			}
-		}
-		if err != nil {
-			if err != io.EOF && pm.debug {
-				Error("[%s][%s] Read error: %v", tunnelID, streamName, err)
-			}
-			break
-		}
+// exitCode returns the exit code of a process from the error of Cmd.Wait, or
+// -1 if it was killed by a signal
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
 	}
+	return -1
 }
 
 // IsProcessRunning checks if a process is still running
 func (pm *ProcessManager) IsProcessRunning(pid int) bool {
-	process, err := os.FindProcess(pid)
-	if err != nil {
-		return false
-	}
-
-	// Send signal 0 to check if process exists
-	err = process.Signal(syscall.Signal(0))
-	return err == nil
+	return store.IsProcessRunning(pid)
 }
 
 // Cleanup performs cleanup of all managed processes
@@ -3,6 +3,8 @@ package core
 
 import (
 	"context"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -67,6 +69,8 @@ func TestBuildSSHArgs(t *testing.T) {
 				"-o", "ServerAliveCountMax=3",
 				"-o", "ExitOnForwardFailure=yes",
 				"-o", "StrictHostKeyChecking=accept-new",
+				"-o", "ControlMaster=no",
+				"-o", "ControlPath=none",
 				"example.com",
 			},
 		},
@@ -88,6 +92,8 @@ func TestBuildSSHArgs(t *testing.T) {
 				"-o", "ServerAliveCountMax=3",
 				"-o", "ExitOnForwardFailure=yes",
 				"-o", "StrictHostKeyChecking=accept-new",
+				"-o", "ControlMaster=no",
+				"-o", "ControlPath=none",
 				"example.com",
 			},
 		},
@@ -108,6 +114,8 @@ func TestBuildSSHArgs(t *testing.T) {
 				"-o", "ServerAliveCountMax=3",
 				"-o", "ExitOnForwardFailure=yes",
 				"-o", "StrictHostKeyChecking=accept-new",
+				"-o", "ControlMaster=no",
+				"-o", "ControlPath=none",
 				"example.com",
 			},
 		},
@@ -131,10 +139,62 @@ func TestBuildSSHArgs(t *testing.T) {
 				"-o", "ServerAliveCountMax=3",
 				"-o", "ExitOnForwardFailure=yes",
 				"-o", "StrictHostKeyChecking=accept-new",
+				"-o", "ControlMaster=no",
+				"-o", "ControlPath=none",
 				"-p", "2222", "-l", "myuser",
 				"example.com",
 			},
 		},
+		{
+			name: "Tunnel through jump hosts",
+			tunnel: &Tunnel{
+				ID:         "test-jump",
+				Name:       "Test Jump",
+				Type:       LocalForward,
+				LocalHost:  "127.0.0.1",
+				LocalPort:  5432,
+				RemoteHost: "localhost",
+				RemotePort: 5432,
+				SSHHost:    "db.internal",
+				JumpHost:   "bastion,admin@gw:2222",
+			},
+			expected: []string{
+				"-L", "127.0.0.1:5432:localhost:5432",
+				"-N", "-T",
+				"-o", "ServerAliveInterval=60",
+				"-o", "ServerAliveCountMax=3",
+				"-o", "ExitOnForwardFailure=yes",
+				"-o", "StrictHostKeyChecking=accept-new",
+				"-o", "ControlMaster=no",
+				"-o", "ControlPath=none",
+				"-J", "bastion,admin@gw:2222",
+				"db.internal",
+			},
+		},
+		{
+			name: "Compressed tunnel",
+			tunnel: &Tunnel{
+				ID:          "test-compressed",
+				Name:        "Test Compressed",
+				Type:        DynamicForward,
+				LocalHost:   "127.0.0.1",
+				LocalPort:   1080,
+				SSHHost:     "proxy.example.com",
+				Compression: true,
+			},
+			expected: []string{
+				"-D", "127.0.0.1:1080",
+				"-N", "-T",
+				"-o", "ServerAliveInterval=60",
+				"-o", "ServerAliveCountMax=3",
+				"-o", "ExitOnForwardFailure=yes",
+				"-o", "StrictHostKeyChecking=accept-new",
+				"-o", "ControlMaster=no",
+				"-o", "ControlPath=none",
+				"-C",
+				"proxy.example.com",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -190,6 +250,44 @@ func TestBuildSSHArgsWithDebug(t *testing.T) {
 	}
 }
 
+// TestBuildSSHArgsWithMultiplex tests that multiplexed tunnels share a control
+// socket while other tunnels keep connection sharing off
+func TestBuildSSHArgsWithMultiplex(t *testing.T) {
+	controlDir := t.TempDir()
+	pm := NewProcessManager(WithControlDir(controlDir))
+
+	tunnel := &Tunnel{
+		ID:         "test-mux",
+		Name:       "Test Mux",
+		Type:       LocalForward,
+		LocalHost:  "127.0.0.1",
+		LocalPort:  8080,
+		RemoteHost: "localhost",
+		RemotePort: 80,
+		SSHHost:    "bastion",
+		Multiplex:  true,
+	}
+
+	args := strings.Join(pm.buildSSHArgs(tunnel), " ")
+	expected := "-o ControlMaster=auto -o ControlPath=" + filepath.Join(controlDir, "%C") + " -o ControlPersist=" + muxControlPersist
+	if !strings.Contains(args, expected) {
+		t.Errorf("Expected %q in %q", expected, args)
+	}
+
+	tunnel.Multiplex = false
+	args = strings.Join(pm.buildSSHArgs(tunnel), " ")
+	if !strings.Contains(args, "-o ControlMaster=no -o ControlPath=none") {
+		t.Errorf("Expected connection sharing to be off, got %q", args)
+	}
+
+	// Without a control directory tunnels never share connections
+	tunnel.Multiplex = true
+	args = strings.Join(NewProcessManager().buildSSHArgs(tunnel), " ")
+	if !strings.Contains(args, "ControlPath=none") {
+		t.Errorf("Expected connection sharing to be off without a control directory, got %q", args)
+	}
+}
+
 // TestProcessInfoManagement tests process info storage and retrieval
 func TestProcessInfoManagement(t *testing.T) {
 	pm := NewProcessManager()
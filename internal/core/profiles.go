@@ -0,0 +1,269 @@
+// Package core provides operations on whole profiles of tunnels.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// profileName returns the profile a tunnel belongs to, "" meaning default
+func profileName(tunnel *Tunnel) string {
+	if tunnel.Profile == "" {
+		return "default"
+	}
+	return tunnel.Profile
+}
+
+// declaredProfiles returns the profiles in the config file
+func (tm *TunnelManager) declaredProfiles() ([]store.Profile, error) {
+	config, err := tm.configStore.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return config.Profiles, nil
+}
+
+// profileExists reports whether a profile is declared or has tunnels.
+// Callers must hold tm.mu.
+func (tm *TunnelManager) profileExists(declared []store.Profile, name string) bool {
+	if name == "default" {
+		return true
+	}
+	for _, p := range declared {
+		if p.Name == name {
+			return true
+		}
+	}
+	for _, tunnel := range tm.tunnels {
+		if profileName(tunnel) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateProfileName checks a name for a new profile
+func validateProfileName(name string) error {
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if strings.TrimSpace(name) != name {
+		return fmt.Errorf("invalid profile name: %q (must not start or end with spaces)", name)
+	}
+	return nil
+}
+
+// RenameProfile renames a profile and moves all its tunnels, running or not,
+// to the new name in one save. The default profile cannot be renamed.
+func (tm *TunnelManager) RenameProfile(oldName, newName string) error {
+	if oldName == "default" {
+		return fmt.Errorf("the default profile cannot be renamed")
+	}
+	if err := validateProfileName(newName); err != nil {
+		return err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	declared, err := tm.declaredProfiles()
+	if err != nil {
+		return err
+	}
+	if !tm.profileExists(declared, oldName) {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, oldName)
+	}
+	if newName == oldName {
+		return nil
+	}
+	if tm.profileExists(declared, newName) {
+		return fmt.Errorf("%w: %s", ErrProfileExists, newName)
+	}
+
+	renamed := make([]store.Profile, len(declared))
+	copy(renamed, declared)
+	for i := range renamed {
+		if renamed[i].Name == oldName {
+			renamed[i].Name = newName
+			if renamed[i].Description == fmt.Sprintf("%s profile", oldName) {
+				renamed[i].Description = fmt.Sprintf("%s profile", newName)
+			}
+		}
+	}
+
+	var moved []*Tunnel
+	for _, tunnel := range tm.tunnels {
+		if tunnel.Profile == oldName {
+			tunnel.Profile = newName
+			moved = append(moved, tunnel)
+		}
+	}
+
+	if err := tm.saveTunnelsWithProfiles(renamed); err != nil {
+		for _, tunnel := range moved {
+			tunnel.Profile = oldName
+		}
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+	return nil
+}
+
+// CloneProfile copies the tunnels of a profile into a new profile with new IDs
+// and the same settings, e.g. to set up a staging variant of production
+// tunnels, and returns the copies. The copies do not auto-connect on startup,
+// as they would compete with the originals for their local ports.
+func (tm *TunnelManager) CloneProfile(source, target string) ([]*Tunnel, error) {
+	if err := validateProfileName(target); err != nil {
+		return nil, err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	declared, err := tm.declaredProfiles()
+	if err != nil {
+		return nil, err
+	}
+	if !tm.profileExists(declared, source) {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, source)
+	}
+	if tm.profileExists(declared, target) {
+		return nil, fmt.Errorf("%w: %s", ErrProfileExists, target)
+	}
+
+	profile := store.Profile{Name: target, Description: fmt.Sprintf("%s profile", target)}
+	for _, p := range declared {
+		if p.Name == source {
+			profile.AutoConnect = p.AutoConnect
+			profile.StopPrevious = p.StopPrevious
+			profile.Defaults = p.Defaults
+		}
+	}
+
+	var copies []*Tunnel
+	for _, tunnel := range tm.tunnels {
+		if profileName(tunnel) == source {
+			duplicate := NewTunnel("", tunnel.Type)
+			duplicate.applyConfig(tunnel)
+			duplicate.Profile = target
+			duplicate.AutoConnect = false
+			copies = append(copies, duplicate)
+		}
+	}
+	for _, duplicate := range copies {
+		tm.tunnels[duplicate.ID] = duplicate
+	}
+
+	if err := tm.saveTunnelsWithProfiles(append(append([]store.Profile(nil), declared...), profile)); err != nil {
+		for _, duplicate := range copies {
+			delete(tm.tunnels, duplicate.ID)
+		}
+		return nil, fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	clones := make([]*Tunnel, 0, len(copies))
+	for _, duplicate := range copies {
+		clones = append(clones, duplicate.Clone())
+	}
+	sort.Slice(clones, func(i, j int) bool {
+		return clones[i].Name < clones[j].Name
+	})
+	return clones, nil
+}
+
+// SwitchProfile applies the settings of profile to when switching to it from
+// profile from, which may be empty: an auto-connect profile starts its stopped
+// tunnels, and one that stops the previous profile stops the running tunnels
+// of from.
+func (tm *TunnelManager) SwitchProfile(from, to string) error {
+	declared, err := tm.declaredProfiles()
+	if err != nil {
+		return err
+	}
+
+	var profile store.Profile
+	for _, p := range declared {
+		if p.Name == to {
+			profile = p
+		}
+	}
+
+	var errs []error
+	if profile.StopPrevious && from != "" && from != to {
+		Info("Stopping tunnels in profile %s", from)
+		if err := tm.StopProfileTunnels(from); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if profile.AutoConnect {
+		Info("Auto-connecting tunnels in profile %s", to)
+		if err := tm.StartProfileTunnels(to); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// setProfileDefaults records the SSH settings profiles pass on to their
+// tunnels. The caller must hold tm.mu or be initializing tm.
+func (tm *TunnelManager) setProfileDefaults(profiles []store.Profile) {
+	tm.profileDefaults = make(map[string]*store.ProfileDefaults)
+	for _, p := range profiles {
+		if p.Defaults != nil {
+			tm.profileDefaults[p.Name] = p.Defaults
+		}
+	}
+}
+
+// withProfileDefaults returns a copy of the tunnel with the settings it leaves
+// unset taken from its profile, or the tunnel itself if the profile has no
+// defaults. The caller must hold tm.mu.
+func (tm *TunnelManager) withProfileDefaults(tunnel *Tunnel) *Tunnel {
+	defaults := tm.profileDefaults[profileName(tunnel)]
+	if defaults == nil {
+		return tunnel
+	}
+
+	effective := tunnel.Clone()
+	if effective.JumpHost == "" {
+		effective.JumpHost = defaults.JumpHost
+	}
+	if effective.IdentityFile == "" {
+		effective.IdentityFile = defaults.IdentityFile
+	}
+	effective.ExtraArgs = append(effective.ExtraArgs, defaults.Options...)
+	if effective.ServerAliveInterval == 0 {
+		effective.ServerAliveInterval = defaults.ServerAliveInterval
+	}
+	if effective.ServerAliveCountMax == 0 {
+		effective.ServerAliveCountMax = defaults.ServerAliveCountMax
+	}
+	return effective
+}
+
+// effectiveTunnel returns a copy of the tunnel with its profile's defaults
+// applied, for callers that do not hold tm.mu
+func (tm *TunnelManager) effectiveTunnel(tunnel *Tunnel) *Tunnel {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	return tm.withProfileDefaults(tunnel)
+}
+
+// effectiveTunnels returns copies of all tunnels with their profiles'
+// defaults applied
+func (tm *TunnelManager) effectiveTunnels() []*Tunnel {
+	tunnels := tm.GetTunnels()
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	for i, tunnel := range tunnels {
+		tunnels[i] = tm.withProfileDefaults(tunnel)
+	}
+	return tunnels
+}
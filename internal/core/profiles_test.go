@@ -0,0 +1,217 @@
+// Package core provides profile operation tests.
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// profilesConfigJSON has tunnels in the default, staging and work profiles,
+// and an empty declared profile
+const profilesConfigJSON = `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.example.com", "localPort": 15432, "remotePort": 5432, "mode": "local", "profile": "staging"},
+    {"id": "cache", "name": "cache", "host": "cache.example.com", "localPort": 16379, "remotePort": 6379, "mode": "local", "profile": "staging"},
+    {"id": "web", "name": "web", "host": "web.example.com", "localPort": 18080, "remotePort": 80, "mode": "local", "profile": "work"},
+    {"id": "home", "name": "home", "host": "home.example.com", "localPort": 2222, "remotePort": 22, "mode": "local"}
+  ],
+  "profiles": [
+    {"name": "staging", "description": "Staging databases", "tunnelIds": null, "autoConnect": true},
+    {"name": "empty", "description": "empty profile", "tunnelIds": null}
+  ]
+}`
+
+// TestRenameProfile tests that renaming a profile moves its tunnels and keeps
+// its settings
+func TestRenameProfile(t *testing.T) {
+	tm, configStore := newTestManager(t, profilesConfigJSON)
+
+	if err := tm.RenameProfile("staging", "preprod"); err != nil {
+		t.Fatalf("RenameProfile failed: %v", err)
+	}
+	if tunnels := tm.GetTunnelsByProfile("preprod"); len(tunnels) != 2 {
+		t.Errorf("Expected both tunnels in the renamed profile, got %d", len(tunnels))
+	}
+	if tunnels := tm.GetTunnelsByProfile("staging"); len(tunnels) != 0 {
+		t.Errorf("Expected no tunnels left in the old profile, got %d", len(tunnels))
+	}
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	found := map[string]bool{}
+	for _, p := range config.Profiles {
+		found[p.Name] = true
+		if p.Name == "preprod" && (p.Description != "Staging databases" || !p.AutoConnect) {
+			t.Errorf("Expected the profile's settings to be kept, got %+v", p)
+		}
+	}
+	if found["staging"] || !found["preprod"] || !found["empty"] || !found["work"] {
+		t.Errorf("Unexpected saved profiles: %+v", config.Profiles)
+	}
+
+	if err := tm.RenameProfile("preprod", "work"); !errors.Is(err, ErrProfileExists) {
+		t.Errorf("Expected renaming onto an existing profile to fail, got %v", err)
+	}
+	if err := tm.RenameProfile("missing", "other"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Expected renaming an unknown profile to fail, got %v", err)
+	}
+	if err := tm.RenameProfile("default", "main"); err == nil {
+		t.Error("Expected the default profile not to be renamable")
+	}
+
+	// Declared profiles without tunnels can be renamed too
+	if err := tm.RenameProfile("empty", "spare"); err != nil {
+		t.Errorf("Expected an empty profile to be renamed, got %v", err)
+	}
+}
+
+// TestCloneProfile tests that cloning a profile copies its tunnels with new
+// IDs into a new profile
+func TestCloneProfile(t *testing.T) {
+	tm, configStore := newTestManager(t, profilesConfigJSON)
+
+	copies, err := tm.CloneProfile("staging", "preprod")
+	if err != nil {
+		t.Fatalf("CloneProfile failed: %v", err)
+	}
+	if len(copies) != 2 || copies[0].Name != "cache" || copies[1].Name != "db" {
+		t.Fatalf("Expected copies of cache and db, got %+v", copies)
+	}
+	for _, tunnel := range copies {
+		if tunnel.ID == "db" || tunnel.ID == "cache" || tunnel.Profile != "preprod" || tunnel.AutoConnect {
+			t.Errorf("Unexpected copy: %+v", tunnel)
+		}
+	}
+	if copies[1].SSHHost != "db.example.com" || copies[1].LocalPort != 15432 {
+		t.Errorf("Expected the copy to keep the settings, got %+v", copies[1])
+	}
+	if tunnels := tm.GetTunnelsByProfile("staging"); len(tunnels) != 2 {
+		t.Errorf("Expected the source profile to keep its tunnels, got %d", len(tunnels))
+	}
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if len(config.Tunnels) != 6 {
+		t.Errorf("Expected the copies to be saved, got %d tunnels", len(config.Tunnels))
+	}
+	found := false
+	for _, p := range config.Profiles {
+		if p.Name == "preprod" {
+			found = p.AutoConnect
+		}
+	}
+	if !found {
+		t.Errorf("Expected the cloned profile to be declared with its settings: %+v", config.Profiles)
+	}
+
+	if _, err := tm.CloneProfile("staging", "work"); !errors.Is(err, ErrProfileExists) {
+		t.Errorf("Expected cloning onto an existing profile to fail, got %v", err)
+	}
+	if _, err := tm.CloneProfile("missing", "other"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Expected cloning an unknown profile to fail, got %v", err)
+	}
+}
+
+// TestSwitchProfile tests that switching to an auto-connect profile starts its
+// tunnels and that stopPrevious stops those of the previous profile
+func TestSwitchProfile(t *testing.T) {
+	t.Setenv(SSHPathEnv, "/nonexistent/ssh")
+	configJSON := fmt.Sprintf(`{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.example.com", "bindAddress": "127.0.0.1", "localPort": %d, "remotePort": 5432, "mode": "local", "profile": "staging"},
+    {"id": "web", "name": "web", "host": "web.example.com", "bindAddress": "127.0.0.1", "localPort": %d, "remotePort": 80, "mode": "local", "profile": "production"},
+    {"id": "home", "name": "home", "host": "home.example.com", "bindAddress": "127.0.0.1", "localPort": %d, "remotePort": 22, "mode": "local"}
+  ],
+  "profiles": [
+    {"name": "staging", "tunnelIds": null, "autoConnect": true},
+    {"name": "production", "tunnelIds": null, "autoConnect": true, "stopPrevious": true}
+  ]
+}`, freePort(t), freePort(t), freePort(t))
+	tm, _ := newTestManager(t, configJSON, WithMockProcesses(MockConfig{ConnectDelay: 10 * time.Millisecond}))
+	defer tm.StopAllTunnels(context.Background())
+
+	status := func(id string) TunnelStatus {
+		tunnel, _ := tm.GetTunnel(id)
+		return tunnel.Status
+	}
+
+	if err := tm.SwitchProfile("", "default"); err != nil || status("home") != StatusStopped {
+		t.Errorf("Expected switching to a plain profile to start nothing (%v)", err)
+	}
+	if err := tm.SwitchProfile("default", "staging"); err != nil {
+		t.Fatalf("SwitchProfile failed: %v", err)
+	}
+	if status("db") != StatusRunning {
+		t.Errorf("Expected db to be auto-connected, got %s", status("db"))
+	}
+
+	if err := tm.SwitchProfile("staging", "production"); err != nil {
+		t.Fatalf("SwitchProfile failed: %v", err)
+	}
+	if status("web") != StatusRunning || status("db") != StatusStopped {
+		t.Errorf("Expected web to run and db to be stopped, got %s and %s", status("web"), status("db"))
+	}
+}
+
+// TestProfileDefaults tests that tunnels inherit the SSH settings of their
+// profile unless they set their own
+func TestProfileDefaults(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.internal", "localPort": 15432, "remotePort": 5432, "mode": "local", "profile": "production", "options": ["-o", "ConnectTimeout=5"]},
+    {"id": "web", "name": "web", "host": "web.internal", "jumpHost": "gw", "identityFile": "/keys/web", "serverAliveInterval": 30, "localPort": 18080, "remotePort": 80, "mode": "local", "profile": "production"},
+    {"id": "home", "name": "home", "host": "home.example.com", "localPort": 2222, "remotePort": 22, "mode": "local"}
+  ],
+  "profiles": [
+    {"name": "production", "tunnelIds": null, "defaults": {"jumpHost": "bastion", "identityFile": "/keys/prod", "options": ["-o", "ConnectTimeout=10"], "serverAliveInterval": 15, "serverAliveCountMax": 5}}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+
+	command := func(id string) string {
+		tunnel, _ := tm.GetTunnel(id)
+		return strings.Join(tm.SSHCommand(tunnel), " ")
+	}
+
+	db := command("db")
+	for _, want := range []string{"-J bastion", "-i /keys/prod", "ServerAliveInterval=15", "ServerAliveCountMax=5", "ConnectTimeout=5 -o ConnectTimeout=10"} {
+		if !strings.Contains(db, want) {
+			t.Errorf("Expected %q in the inherited command: %s", want, db)
+		}
+	}
+
+	web := command("web")
+	for _, want := range []string{"-J gw", "-i /keys/web", "ServerAliveInterval=30", "ServerAliveCountMax=5"} {
+		if !strings.Contains(web, want) {
+			t.Errorf("Expected %q in the overriding command: %s", want, web)
+		}
+	}
+
+	if home := command("home"); strings.Contains(home, "bastion") {
+		t.Errorf("Expected tunnels in other profiles not to inherit: %s", home)
+	}
+
+	// The stored tunnel keeps its own settings only
+	if tunnel, _ := tm.GetTunnel("db"); tunnel.JumpHost != "" {
+		t.Errorf("Expected the inherited jump host not to be stored, got %q", tunnel.JumpHost)
+	}
+
+	// Defaults follow a renamed profile
+	if err := tm.RenameProfile("production", "prod"); err != nil {
+		t.Fatalf("RenameProfile failed: %v", err)
+	}
+	if db := command("db"); !strings.Contains(db, "-J bastion") {
+		t.Errorf("Expected the renamed profile to keep its defaults: %s", db)
+	}
+}
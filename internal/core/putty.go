@@ -0,0 +1,242 @@
+// Package core provides importing tunnels from PuTTY saved sessions.
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PuTTYSession is a session saved by PuTTY, with the settings tunnelman
+// imports
+type PuTTYSession struct {
+	Name     string
+	Protocol string
+	HostName string
+	Port     int
+	UserName string
+
+	// PortForwardings is PuTTY's list of forwards, e.g.
+	// "L8080=localhost:80,R9000=localhost:3000,D1080"
+	PortForwardings string
+
+	// LocalPortAcceptAll and RemotePortAcceptAll let other hosts connect to
+	// forwarded ports that have no bind address
+	LocalPortAcceptAll  bool
+	RemotePortAcceptAll bool
+
+	PublicKeyFile    string
+	Compression      bool
+	PingIntervalSecs int
+}
+
+// puttySessionFromValues builds a session from its saved values, which the
+// registry and PuTTY's session files on Unix both hold as name/value pairs
+func puttySessionFromValues(name string, values map[string]string) *PuTTYSession {
+	number := func(key string) int {
+		n, _ := strconv.Atoi(strings.TrimSpace(values[key]))
+		return n
+	}
+	return &PuTTYSession{
+		Name:                decodePuTTYSessionName(name),
+		Protocol:            values["Protocol"],
+		HostName:            strings.TrimSpace(values["HostName"]),
+		Port:                number("PortNumber"),
+		UserName:            strings.TrimSpace(values["UserName"]),
+		PortForwardings:     values["PortForwardings"],
+		LocalPortAcceptAll:  number("LocalPortAcceptAll") != 0,
+		RemotePortAcceptAll: number("RemotePortAcceptAll") != 0,
+		PublicKeyFile:       strings.TrimSpace(values["PublicKeyFile"]),
+		Compression:         number("Compression") != 0,
+		PingIntervalSecs:    number("PingIntervalSecs"),
+	}
+}
+
+// parsePuTTYSessionFile reads the "Name=value" lines of a session file as
+// PuTTY writes them on Unix
+func parsePuTTYSessionFile(data string) map[string]string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		if key, value, ok := strings.Cut(scanner.Text(), "="); ok {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// decodePuTTYSessionName undoes the %XX escaping of characters PuTTY cannot
+// use in registry keys and file names
+func decodePuTTYSessionName(name string) string {
+	if decoded, err := url.PathUnescape(name); err == nil {
+		return decoded
+	}
+	return name
+}
+
+// ConvertToTunnel converts the session to a tunnel with one forward per port
+// forwarding, and returns notes on the settings that could not be carried
+// over. Sessions that are not SSH or have no forwardings give no tunnel.
+func (s *PuTTYSession) ConvertToTunnel() (*Tunnel, []string) {
+	if s.Protocol != "" && s.Protocol != "ssh" {
+		return nil, nil
+	}
+
+	var forwards []Forward
+	var notes []string
+	for _, entry := range strings.Split(s.PortForwardings, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		forward, err := parsePuTTYForward(entry, s.LocalPortAcceptAll, s.RemotePortAcceptAll)
+		if err != nil {
+			notes = append(notes, err.Error())
+			continue
+		}
+		forwards = append(forwards, forward)
+	}
+	if len(forwards) == 0 || s.HostName == "" {
+		return nil, notes
+	}
+
+	first := forwards[0]
+	tunnel := &Tunnel{
+		ID:                "putty-" + slugify(s.Name),
+		Name:              s.Name,
+		Type:              first.Type,
+		LocalHost:         first.LocalHost,
+		LocalPort:         first.LocalPort,
+		RemoteHost:        first.RemoteHost,
+		RemotePort:        first.RemotePort,
+		RemoteBindAddress: first.RemoteBindAddress,
+		Forwards:          forwards[1:],
+		SSHHost:           s.HostName,
+		Description:       fmt.Sprintf("Imported from PuTTY session %s", s.Name),
+		ImportedFrom:      "putty:" + s.Name,
+		Compression:       s.Compression,
+		Status:            StatusStopped,
+	}
+	if s.UserName != "" && !strings.Contains(tunnel.SSHHost, "@") {
+		tunnel.SSHHost = s.UserName + "@" + tunnel.SSHHost
+	}
+	if s.Port != 0 && s.Port != 22 {
+		tunnel.ExtraArgs = []string{"-p", strconv.Itoa(s.Port)}
+	}
+	if s.PingIntervalSecs > 0 {
+		tunnel.ServerAliveInterval = s.PingIntervalSecs
+	}
+	if s.PublicKeyFile != "" {
+		if strings.HasSuffix(strings.ToLower(s.PublicKeyFile), ".ppk") {
+			// OpenSSH cannot read PuTTY's key format
+			notes = append(notes, fmt.Sprintf("key %s is a PuTTY key; convert it with puttygen -O private-openssh and set it as the tunnel's identity file", s.PublicKeyFile))
+		} else {
+			tunnel.IdentityFile = s.PublicKeyFile
+		}
+	}
+	return tunnel, notes
+}
+
+// parsePuTTYForward parses an entry of PuTTY's port forwardings, e.g.
+// "L8080=db:5432", "R127.0.0.1:9000=localhost:3000" or "4D1080". Forwards
+// without a bind address listen on loopback, or on all addresses when the
+// session accepts connections from other hosts.
+func parsePuTTYForward(entry string, localAcceptAll, remoteAcceptAll bool) (Forward, error) {
+	spec := entry
+	if strings.HasPrefix(spec, "4") || strings.HasPrefix(spec, "6") {
+		spec = spec[1:]
+	}
+	if spec == "" {
+		return Forward{}, fmt.Errorf("invalid port forwarding %q", entry)
+	}
+	kind, rest := spec[0], spec[1:]
+	source, destination, _ := strings.Cut(rest, "=")
+
+	bind, portText := "", source
+	if i := strings.LastIndex(source, ":"); i >= 0 {
+		bind, portText = NormalizeHost(source[:i]), source[i+1:]
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port <= 0 || port > 65535 {
+		return Forward{}, fmt.Errorf("invalid port in port forwarding %q", entry)
+	}
+
+	destHost, destPort := "", 0
+	if destination != "" {
+		i := strings.LastIndex(destination, ":")
+		if i < 0 {
+			return Forward{}, fmt.Errorf("invalid destination in port forwarding %q", entry)
+		}
+		destHost = NormalizeHost(destination[:i])
+		if destPort, err = strconv.Atoi(destination[i+1:]); err != nil {
+			return Forward{}, fmt.Errorf("invalid destination in port forwarding %q", entry)
+		}
+	}
+
+	localBind := bind
+	if localBind == "" {
+		localBind = DefaultBindAddress
+		if localAcceptAll {
+			localBind = "0.0.0.0"
+		}
+	}
+	remoteBind := bind
+	if remoteBind == "" && remoteAcceptAll {
+		remoteBind = "0.0.0.0"
+	}
+
+	switch {
+	case kind == 'L' && destination != "":
+		return Forward{Type: LocalForward, LocalHost: localBind, LocalPort: port, RemoteHost: destHost, RemotePort: destPort}, nil
+	case kind == 'D':
+		return Forward{Type: DynamicForward, LocalHost: localBind, LocalPort: port}, nil
+	case kind == 'R' && destination != "":
+		return Forward{Type: RemoteForward, RemotePort: port, RemoteBindAddress: remoteBind, LocalHost: destHost, LocalPort: destPort}, nil
+	case kind == 'R':
+		return Forward{Type: ReverseDynamicForward, RemotePort: port, RemoteBindAddress: remoteBind}, nil
+	}
+	return Forward{}, fmt.Errorf("invalid port forwarding %q", entry)
+}
+
+// PuTTYTunnels returns the tunnels of the saved PuTTY sessions with port
+// forwardings, or of the named ones only, with notes on what could not be
+// imported
+func PuTTYTunnels(names []string) ([]*Tunnel, []string, error) {
+	sessions, err := LoadPuTTYSessions()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[name] = true
+	}
+	var tunnels []*Tunnel
+	var notes []string
+	for _, session := range sessions {
+		if len(names) > 0 && !wanted[session.Name] {
+			continue
+		}
+		delete(wanted, session.Name)
+		tunnel, sessionNotes := session.ConvertToTunnel()
+		for _, note := range sessionNotes {
+			notes = append(notes, fmt.Sprintf("%s: %s", session.Name, note))
+		}
+		if tunnel != nil {
+			if err := tunnel.Validate(); err != nil {
+				notes = append(notes, fmt.Sprintf("%s: %v", session.Name, err))
+				continue
+			}
+			tunnels = append(tunnels, tunnel)
+		} else if len(names) > 0 {
+			notes = append(notes, fmt.Sprintf("%s: no SSH port forwardings to import", session.Name))
+		}
+	}
+	for _, name := range names {
+		if wanted[name] {
+			return nil, nil, fmt.Errorf("no PuTTY session named %s", name)
+		}
+	}
+	return tunnels, notes, nil
+}
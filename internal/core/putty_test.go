@@ -0,0 +1,65 @@
+// Package core provides PuTTY session import tests.
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestPuTTYSessionImport tests that a saved session converts to a tunnel with
+// a forward per port forwarding and its connection settings
+func TestPuTTYSessionImport(t *testing.T) {
+	values := parsePuTTYSessionFile(`HostName=bastion.example.com
+PortNumber=2222
+UserName=ops
+Protocol=ssh
+PortForwardings=L5432=db.internal:5432,4R127.0.0.1:9000=localhost:3000,D1080,6L[::1]:8080=[fd00::1]:80,X1=bad
+LocalPortAcceptAll=1
+PublicKeyFile=C:\Keys\ops.ppk
+Compression=1
+PingIntervalSecs=30
+`)
+	session := puttySessionFromValues("Prod%20DB", values)
+	if session.Name != "Prod DB" {
+		t.Errorf("Expected the session name to be unescaped, got %q", session.Name)
+	}
+
+	tunnel, notes := session.ConvertToTunnel()
+	if tunnel == nil {
+		t.Fatal("Expected a tunnel")
+	}
+	if tunnel.ID != "putty-prod-db" || tunnel.SSHHost != "ops@bastion.example.com" || !reflect.DeepEqual(tunnel.ExtraArgs, []string{"-p", "2222"}) {
+		t.Errorf("Unexpected tunnel %s to %s with %v", tunnel.ID, tunnel.SSHHost, tunnel.ExtraArgs)
+	}
+	if !tunnel.Compression || tunnel.ServerAliveInterval != 30 || tunnel.IdentityFile != "" {
+		t.Errorf("Unexpected options: compression %v, keepalive %d, key %q", tunnel.Compression, tunnel.ServerAliveInterval, tunnel.IdentityFile)
+	}
+
+	want := []Forward{
+		{Type: LocalForward, LocalHost: "0.0.0.0", LocalPort: 5432, RemoteHost: "db.internal", RemotePort: 5432},
+		{Type: RemoteForward, RemotePort: 9000, RemoteBindAddress: "127.0.0.1", LocalHost: "localhost", LocalPort: 3000},
+		{Type: DynamicForward, LocalHost: "0.0.0.0", LocalPort: 1080},
+		{Type: LocalForward, LocalHost: "::1", LocalPort: 8080, RemoteHost: "fd00::1", RemotePort: 80},
+	}
+	if got := tunnel.AllForwards(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Unexpected forwards:\n%+v\nwant:\n%+v", got, want)
+	}
+	if err := tunnel.Validate(); err != nil {
+		t.Errorf("Expected a valid tunnel: %v", err)
+	}
+
+	// The bad forwarding and the PuTTY key are reported
+	if len(notes) != 2 {
+		t.Errorf("Expected 2 notes, got %q", notes)
+	}
+
+	// Sessions of other protocols or without forwardings are not imported
+	for _, values := range []map[string]string{
+		{"HostName": "router", "Protocol": "telnet", "PortForwardings": "L23=localhost:23"},
+		{"HostName": "plain", "Protocol": "ssh"},
+	} {
+		if tunnel, _ := puttySessionFromValues("other", values).ConvertToTunnel(); tunnel != nil {
+			t.Errorf("Expected no tunnel for %v", values)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+// Package core provides reading PuTTY saved sessions from ~/.putty on Unix.
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LoadPuTTYSessions reads the sessions saved by PuTTY, in name order
+func LoadPuTTYSessions() ([]*PuTTYSession, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(homeDir, ".putty", "sessions")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list PuTTY sessions: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var sessions []*PuTTYSession
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			Warn("Skipping PuTTY session %s: %v", decodePuTTYSessionName(name), err)
+			continue
+		}
+		sessions = append(sessions, puttySessionFromValues(name, parsePuTTYSessionFile(string(data))))
+	}
+	return sessions, nil
+}
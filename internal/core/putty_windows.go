@@ -0,0 +1,68 @@
+//go:build windows
+
+// Package core provides reading PuTTY saved sessions from the Windows registry.
+package core
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// puttySessionsKey is where PuTTY saves its sessions under HKEY_CURRENT_USER
+const puttySessionsKey = `Software\SimonTatham\PuTTY\Sessions`
+
+// LoadPuTTYSessions reads the sessions saved by PuTTY, in name order
+func LoadPuTTYSessions() ([]*PuTTYSession, error) {
+	key, err := registry.OpenKey(registry.CURRENT_USER, puttySessionsKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open PuTTY sessions: %w", err)
+	}
+	defer key.Close()
+
+	names, err := key.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PuTTY sessions: %w", err)
+	}
+	sort.Strings(names)
+
+	var sessions []*PuTTYSession
+	for _, name := range names {
+		values, err := readPuTTYSessionValues(key, name)
+		if err != nil {
+			Warn("Skipping PuTTY session %s: %v", decodePuTTYSessionName(name), err)
+			continue
+		}
+		sessions = append(sessions, puttySessionFromValues(name, values))
+	}
+	return sessions, nil
+}
+
+// readPuTTYSessionValues reads the values of a session's key as strings
+func readPuTTYSessionValues(sessions registry.Key, name string) (map[string]string, error) {
+	key, err := registry.OpenKey(sessions, name, registry.QUERY_VALUE)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Close()
+
+	valueNames, err := key.ReadValueNames(-1)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(valueNames))
+	for _, valueName := range valueNames {
+		if value, _, err := key.GetStringValue(valueName); err == nil {
+			values[valueName] = value
+		} else if value, _, err := key.GetIntegerValue(valueName); err == nil {
+			values[valueName] = strconv.FormatUint(value, 10)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,71 @@
+// Package core provides delegation of tunnel starts and stops to a daemon.
+package core
+
+import (
+	"errors"
+)
+
+// RemoteControl starts and stops tunnels in another tunnelman process that
+// owns their ssh processes, such as a running daemon. daemon.Client
+// implements it.
+type RemoteControl interface {
+	Start(nameOrID string) (TunnelSnapshot, error)
+	Stop(nameOrID string) (TunnelSnapshot, error)
+}
+
+// SetRemoteControl makes StartTunnel and StopTunnel, and everything built on
+// them, go through remote, which then runs the ssh processes, hooks and
+// monitoring. Changes made there reach this manager through ApplySnapshot.
+// nil makes the manager run tunnels itself again.
+func (tm *TunnelManager) SetRemoteControl(remote RemoteControl) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	tm.remote = remote
+}
+
+// remoteControl returns the process tunnels are started and stopped in, or nil
+func (tm *TunnelManager) remoteControl() RemoteControl {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	return tm.remote
+}
+
+// applyRemote starts or stops a tunnel through the remote control and takes
+// over the state it reports. Failures are recorded on the tunnel by the
+// remote process, which reports them as a status change.
+func (tm *TunnelManager) applyRemote(id string, action func(nameOrID string) (TunnelSnapshot, error)) error {
+	snapshot, err := action(id)
+	if err != nil {
+		return err
+	}
+	tm.ApplySnapshot(snapshot)
+	return nil
+}
+
+// ApplySnapshot takes over the status of a tunnel as the process that owns
+// it reports it, publishing a status change if it changed
+func (tm *TunnelManager) ApplySnapshot(snapshot TunnelSnapshot) {
+	tm.mu.Lock()
+	tunnel, exists := tm.tunnels[snapshot.ID]
+	if !exists {
+		tm.mu.Unlock()
+		return
+	}
+	oldStatus := tunnel.Status
+	tunnel.Status = snapshot.Status
+	tunnel.PID = snapshot.PID
+	tunnel.StartedAt = snapshot.StartedAt
+	tunnel.process = nil
+	tunnel.LastError = nil
+	if snapshot.Error != "" {
+		tunnel.LastError = errors.New(snapshot.Error)
+	}
+	lastErr := tunnel.LastError
+	tm.mu.Unlock()
+
+	if oldStatus != snapshot.Status {
+		tm.notifyStatusChange(snapshot.ID, oldStatus, snapshot.Status, lastErr)
+	}
+}
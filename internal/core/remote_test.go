@@ -0,0 +1,67 @@
+// Package core provides tests of starting and stopping tunnels through a daemon.
+package core
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeRemote records the tunnels it is asked to start and stop
+type fakeRemote struct {
+	calls []string
+	err   error
+}
+
+func (r *fakeRemote) Start(id string) (TunnelSnapshot, error) {
+	r.calls = append(r.calls, "start "+id)
+	now := time.Now()
+	return TunnelSnapshot{ID: id, Status: StatusRunning, PID: 4242, StartedAt: &now}, r.err
+}
+
+func (r *fakeRemote) Stop(id string) (TunnelSnapshot, error) {
+	r.calls = append(r.calls, "stop "+id)
+	return TunnelSnapshot{ID: id, Status: StatusStopped}, r.err
+}
+
+// TestRemoteControl tests that tunnels are started and stopped by the remote
+// process while one is set, and take over the state it reports
+func TestRemoteControl(t *testing.T) {
+	tm, _ := newTestManager(t, mockConfigJSON(freePort(t)))
+	remote := &fakeRemote{}
+	tm.SetRemoteControl(remote)
+
+	changes := tm.Subscribe()
+	defer tm.Unsubscribe(changes)
+
+	if err := tm.StartTunnel("db"); err != nil {
+		t.Fatalf("StartTunnel failed: %v", err)
+	}
+	tunnel, _ := tm.GetTunnel("db")
+	if tunnel.Status != StatusRunning || tunnel.PID != 4242 {
+		t.Errorf("Expected the remote state, got %s with PID %d", tunnel.Status, tunnel.PID)
+	}
+	select {
+	case change := <-changes:
+		if change.NewStatus != StatusRunning {
+			t.Errorf("Expected a change to running, got %s", change.NewStatus)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the status change to be published")
+	}
+
+	if err := tm.StopTunnel("db"); err != nil {
+		t.Fatalf("StopTunnel failed: %v", err)
+	}
+	if len(remote.calls) != 2 || remote.calls[0] != "start db" || remote.calls[1] != "stop db" {
+		t.Errorf("Unexpected remote calls: %v", remote.calls)
+	}
+
+	remote.err = ErrAlreadyRunning
+	if err := tm.StartTunnel("db"); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Expected the remote error, got %v", err)
+	}
+	if tunnel, _ := tm.GetTunnel("db"); tunnel.Status != StatusStopped {
+		t.Errorf("Expected a failed remote start to leave the state alone, got %s", tunnel.Status)
+	}
+}
@@ -0,0 +1,95 @@
+// Package core provides the global defaults that apply to all tunnels.
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// HostKeyPolicies are the accepted StrictHostKeyChecking settings
+var HostKeyPolicies = []string{"ask", "accept-new", "yes", "no"}
+
+// validateDefaults checks the global defaults before they are saved
+func validateDefaults(d *store.Defaults) error {
+	if strings.ContainsAny(d.BindAddress, " \t") {
+		return fmt.Errorf("invalid bind address: %q", d.BindAddress)
+	}
+	if d.StrictHostKeyChecking != "" && !slices.Contains(HostKeyPolicies, d.StrictHostKeyChecking) {
+		return fmt.Errorf("invalid host key policy: %s (must be one of %s)", d.StrictHostKeyChecking, strings.Join(HostKeyPolicies, ", "))
+	}
+	if d.ServerAliveCountMax < 0 {
+		return fmt.Errorf("invalid keepalive count: %d", d.ServerAliveCountMax)
+	}
+	if r := d.Reconnect; r != nil {
+		if r.InitialBackoff < 0 || r.MaxBackoff < 0 || r.StableAfter < 0 {
+			return fmt.Errorf("reconnect delays cannot be negative")
+		}
+		if r.InitialBackoff > 0 && r.MaxBackoff > 0 && r.MaxBackoff < r.InitialBackoff {
+			return fmt.Errorf("maximum reconnect delay %ds is shorter than the initial delay %ds", r.MaxBackoff, r.InitialBackoff)
+		}
+	}
+	return nil
+}
+
+// GetDefaults returns a copy of the global defaults from the config
+func (tm *TunnelManager) GetDefaults() store.Defaults {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tm.defaults == nil {
+		return store.Defaults{}
+	}
+	defaults := *tm.defaults
+	if defaults.Reconnect != nil {
+		reconnect := *defaults.Reconnect
+		defaults.Reconnect = &reconnect
+	}
+	return defaults
+}
+
+// SetDefaults validates, applies and saves the global defaults. Running
+// tunnels keep the settings they were started with.
+func (tm *TunnelManager) SetDefaults(defaults store.Defaults) error {
+	if err := validateDefaults(&defaults); err != nil {
+		return err
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	previous := tm.defaults
+	if err := tm.setDefaults(&defaults); err != nil {
+		tm.setDefaults(previous)
+		return err
+	}
+	if err := tm.saveTunnels(); err != nil {
+		tm.setDefaults(previous)
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// DefaultBindAddress returns the address new tunnels listen on
+func (tm *TunnelManager) DefaultBindAddress() string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tm.defaults != nil && tm.defaults.BindAddress != "" {
+		return NormalizeHost(tm.defaults.BindAddress)
+	}
+	return DefaultBindAddress
+}
+
+// reconnectPolicy returns the configured restart delays, or nil
+func (tm *TunnelManager) reconnectPolicy() *store.ReconnectPolicy {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	if tm.defaults == nil {
+		return nil
+	}
+	return tm.defaults.Reconnect
+}
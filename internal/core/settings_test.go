@@ -0,0 +1,69 @@
+// Package core provides global defaults tests.
+package core
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestSetDefaults tests that the global defaults are validated, saved and
+// applied to ssh commands, supervisors and new tunnels
+func TestSetDefaults(t *testing.T) {
+	tm, configStore := newTestManager(t, transferTestConfig)
+
+	if err := tm.SetDefaults(store.Defaults{StrictHostKeyChecking: "maybe"}); err == nil {
+		t.Error("Expected an unknown host key policy to be rejected")
+	}
+	if err := tm.SetDefaults(store.Defaults{Reconnect: &store.ReconnectPolicy{InitialBackoff: 10, MaxBackoff: 5}}); err == nil {
+		t.Error("Expected a maximum delay below the initial one to be rejected")
+	}
+
+	defaults := store.Defaults{
+		BindAddress:           "0.0.0.0",
+		StrictHostKeyChecking: "yes",
+		ServerAliveInterval:   20,
+		Reconnect:             &store.ReconnectPolicy{InitialBackoff: 2, MaxBackoff: 300},
+	}
+	if err := tm.SetDefaults(defaults); err != nil {
+		t.Fatalf("SetDefaults failed: %v", err)
+	}
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if config.Defaults == nil || config.Defaults.StrictHostKeyChecking != "yes" || len(config.Tunnels) != 2 {
+		t.Errorf("Expected the defaults to be saved with the tunnels, got %+v", config.Defaults)
+	}
+
+	db, _ := tm.GetTunnel("db")
+	command := strings.Join(tm.SSHCommand(db), " ")
+	for _, want := range []string{"StrictHostKeyChecking=yes", "ServerAliveInterval=20"} {
+		if !strings.Contains(command, want) {
+			t.Errorf("Expected %q in the command: %s", want, command)
+		}
+	}
+
+	if bind := tm.DefaultBindAddress(); bind != "0.0.0.0" {
+		t.Errorf("Expected new tunnels to bind 0.0.0.0, got %s", bind)
+	}
+
+	s := NewSupervisor(tm, nil)
+	if s.initialBackoff != 2*time.Second || s.maxBackoff != 5*time.Minute || s.stableAfter != time.Minute {
+		t.Errorf("Unexpected supervisor delays: %s, %s, %s", s.initialBackoff, s.maxBackoff, s.stableAfter)
+	}
+	if s := NewSupervisor(tm, nil, WithMaxBackoff(time.Minute)); s.maxBackoff != time.Minute {
+		t.Errorf("Expected the option to override the config, got %s", s.maxBackoff)
+	}
+
+	// Asking needs the TUI to answer, so it falls back to refusing
+	if err := tm.SetDefaults(store.Defaults{StrictHostKeyChecking: "ask"}); err != nil {
+		t.Fatalf("SetDefaults failed: %v", err)
+	}
+	if command := strings.Join(tm.SSHCommand(db), " "); !strings.Contains(command, "StrictHostKeyChecking=yes") {
+		t.Errorf("Expected ask to refuse unknown hosts without prompts: %s", command)
+	}
+}
@@ -0,0 +1,39 @@
+// Package core provides private Unix sockets for local control connections.
+package core
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// ListenPrivate listens on a Unix socket at socketPath that only this user can
+// connect to. The socket is created in a directory only this user can enter
+// and moved into place once its permissions are restricted, so other users
+// cannot connect in between. The caller removes the socket file when done, as
+// closing the listener leaves it in place.
+func ListenPrivate(socketPath string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(socketPath), ".tunnelman-sock-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, filepath.Base(socketPath))
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmpPath, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	if err := os.Rename(tmpPath, socketPath); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to move socket into place: %w", err)
+	}
+	return listener, nil
+}
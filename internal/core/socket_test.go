@@ -0,0 +1,34 @@
+// Package core provides private socket tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestListenPrivate tests that the socket is only accessible to this user and
+// that no temporary directory is left behind
+func TestListenPrivate(t *testing.T) {
+	dir := t.TempDir()
+	socketPath := filepath.Join(dir, "test.sock")
+
+	listener, err := ListenPrivate(socketPath)
+	if err != nil {
+		t.Fatalf("ListenPrivate failed: %v", err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("Expected the socket at %s: %v", socketPath, err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %o", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected only the socket in %s, got %d entries", dir, len(entries))
+	}
+}
@@ -0,0 +1,108 @@
+// Package core provides the orders tunnel lists can be sorted in.
+package core
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SortField is a tunnel attribute a tunnel list can be sorted by
+type SortField string
+
+const (
+	// SortByName sorts by tunnel name
+	SortByName SortField = "name"
+	// SortByHost sorts by SSH host
+	SortByHost SortField = "host"
+	// SortByStatus sorts running tunnels before connecting, failed and stopped ones
+	SortByStatus SortField = "status"
+	// SortByPort sorts by local port, or remote port for forwards without one
+	SortByPort SortField = "port"
+	// SortByUptime sorts by how long tunnels have been running
+	SortByUptime SortField = "uptime"
+	// SortByProfile sorts by profile
+	SortByProfile SortField = "profile"
+)
+
+// SortFields are the sort fields in the order the TUI cycles through them
+var SortFields = []SortField{SortByName, SortByHost, SortByStatus, SortByPort, SortByUptime, SortByProfile}
+
+// ParseSortField returns the sort field named s; an empty name sorts by name
+func ParseSortField(s string) (SortField, error) {
+	if s == "" {
+		return SortByName, nil
+	}
+	field := SortField(strings.ToLower(s))
+	if !slices.Contains(SortFields, field) {
+		return "", fmt.Errorf("unknown sort field: %s", s)
+	}
+	return field, nil
+}
+
+// statusRanks orders statuses for SortByStatus
+var statusRanks = map[TunnelStatus]int{
+	StatusRunning:    0,
+	StatusConnecting: 1,
+	StatusError:      2,
+	StatusStopped:    3,
+}
+
+// SortTunnels sorts tunnels by field, breaking ties by name
+func SortTunnels(tunnels []*Tunnel, field SortField, descending bool) {
+	now := time.Now()
+	slices.SortStableFunc(tunnels, func(a, b *Tunnel) int {
+		c := compareTunnels(a, b, field, now)
+		if descending {
+			c = -c
+		}
+		if c == 0 {
+			c = cmp.Or(strings.Compare(a.Name, b.Name), strings.Compare(a.ID, b.ID))
+		}
+		return c
+	})
+}
+
+// compareTunnels compares two tunnels by field alone
+func compareTunnels(a, b *Tunnel, field SortField, now time.Time) int {
+	switch field {
+	case SortByHost:
+		return strings.Compare(a.SSHHost, b.SSHHost)
+	case SortByStatus:
+		return cmp.Compare(statusRank(a.Status), statusRank(b.Status))
+	case SortByPort:
+		return cmp.Compare(sortPort(a), sortPort(b))
+	case SortByUptime:
+		return cmp.Compare(uptime(a, now), uptime(b, now))
+	case SortByProfile:
+		return strings.Compare(profileName(a), profileName(b))
+	default:
+		return strings.Compare(a.Name, b.Name)
+	}
+}
+
+// statusRank returns the position of a status when sorting by status
+func statusRank(status TunnelStatus) int {
+	if rank, ok := statusRanks[status]; ok {
+		return rank
+	}
+	return len(statusRanks)
+}
+
+// sortPort returns the port a tunnel is sorted by when sorting by port
+func sortPort(t *Tunnel) int {
+	if t.Type == ReverseDynamicForward || t.LocalPort == 0 {
+		return t.RemotePort
+	}
+	return t.LocalPort
+}
+
+// uptime returns how long a tunnel has been running, or zero if it is not
+func uptime(t *Tunnel, now time.Time) time.Duration {
+	if t.Status != StatusRunning || t.StartedAt == nil {
+		return 0
+	}
+	return now.Sub(*t.StartedAt)
+}
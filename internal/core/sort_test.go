@@ -0,0 +1,57 @@
+// Package core provides tunnel list sorting tests.
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestSortTunnels tests sorting tunnels by each field
+func TestSortTunnels(t *testing.T) {
+	now := time.Now()
+	early, late := now.Add(-time.Hour), now.Add(-time.Minute)
+	tunnels := []*Tunnel{
+		{ID: "web", Name: "web", SSHHost: "b.example.com", Type: LocalForward, LocalPort: 8080, Profile: "work", Status: StatusRunning, StartedAt: &late},
+		{ID: "db", Name: "db", SSHHost: "c.example.com", Type: LocalForward, LocalPort: 5432, Status: StatusStopped},
+		{ID: "socks", Name: "socks", SSHHost: "a.example.com", Type: ReverseDynamicForward, RemotePort: 1080, Status: StatusError},
+		{ID: "api", Name: "api", SSHHost: "b.example.com", Type: LocalForward, LocalPort: 9000, Profile: "work", Status: StatusRunning, StartedAt: &early},
+	}
+
+	tests := []struct {
+		field      SortField
+		descending bool
+		want       []string
+	}{
+		{SortByName, false, []string{"api", "db", "socks", "web"}},
+		{SortByName, true, []string{"web", "socks", "db", "api"}},
+		{SortByHost, false, []string{"socks", "api", "web", "db"}},
+		{SortByStatus, false, []string{"api", "web", "socks", "db"}},
+		{SortByPort, false, []string{"socks", "db", "web", "api"}},
+		{SortByUptime, true, []string{"api", "web", "db", "socks"}},
+		{SortByProfile, false, []string{"db", "socks", "api", "web"}},
+	}
+	for _, tt := range tests {
+		SortTunnels(tunnels, tt.field, tt.descending)
+		var got []string
+		for _, tunnel := range tunnels {
+			got = append(got, tunnel.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Sorting by %s (descending %v): expected %v, got %v", tt.field, tt.descending, tt.want, got)
+		}
+	}
+}
+
+// TestParseSortField tests parsing sort field names
+func TestParseSortField(t *testing.T) {
+	if field, err := ParseSortField(""); err != nil || field != SortByName {
+		t.Errorf("Expected an empty field to sort by name, got %q, %v", field, err)
+	}
+	if field, err := ParseSortField("Uptime"); err != nil || field != SortByUptime {
+		t.Errorf("Expected uptime, got %q, %v", field, err)
+	}
+	if _, err := ParseSortField("color"); err == nil {
+		t.Error("Expected an unknown field to be rejected")
+	}
+}
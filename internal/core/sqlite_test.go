@@ -0,0 +1,96 @@
+// Package core provides tests of the SQLite storage backend.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestSQLiteStorage tests that tunnels and statistics of a config using the
+// sqlite backend are moved into and kept in its database
+func TestSQLiteStorage(t *testing.T) {
+	configJSON := `{
+  "version": "2.0",
+  "storage": {"backend": "sqlite"},
+  "tunnels": [
+    {"id": "web", "name": "Web", "host": "web.example.com", "localPort": 8080, "remotePort": 80, "mode": "local", "profile": "work"}
+  ],
+  "profiles": [{"name": "work", "tunnelIds": ["web"]}]
+}`
+	tm, configStore := newTestManager(t, configJSON)
+	configPath, _ := configStore.GetConfigPath()
+
+	if _, err := tm.GetTunnel("web"); err != nil {
+		t.Fatalf("Expected the tunnel of the config file to be loaded: %v", err)
+	}
+	if _, ok := tm.statsStore.(*store.SQLiteStore); !ok {
+		t.Fatalf("Expected statistics in the database, got %T", tm.statsStore)
+	}
+
+	// Saving moves the tunnels from the config file to the database
+	added := NewTunnel("db", LocalForward)
+	added.SSHHost = "db.example.com"
+	added.LocalPort = 5432
+	added.RemotePort = 5432
+	if err := tm.AddTunnel(added); err != nil {
+		t.Fatalf("AddTunnel failed: %v", err)
+	}
+	tm.recordConnect("web")
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "web.example.com") || !strings.Contains(string(data), `"sqlite"`) {
+		t.Errorf("Expected only the storage settings in the config file, got:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(configPath), store.DefaultDatabaseName)); err != nil {
+		t.Errorf("Expected the database next to the config: %v", err)
+	}
+
+	// A new store, as after restarting tunnelman, reads them from the database
+	reopened, err := store.NewConfigStore(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := NewTunnelManager(reopened, tm.pidStore)
+	if len(restarted.GetTunnels()) != 2 {
+		t.Fatalf("Expected 2 tunnels from the database, got %d", len(restarted.GetTunnels()))
+	}
+	if profiles, err := restarted.declaredProfiles(); err != nil || len(profiles) == 0 || profiles[0].Name != "work" {
+		t.Errorf("Expected the work profile from the database, got %+v", profiles)
+	}
+	stats, err := restarted.Stats("web")
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Connects != 1 || stats.LastConnected == nil {
+		t.Errorf("Expected the statistics from the database, got %+v", stats)
+	}
+
+	// Switching back to json writes the tunnels to the config file again
+	if err := reopened.SetStorage(&store.Storage{Backend: store.StorageJSON}); err != nil {
+		t.Fatalf("SetStorage failed: %v", err)
+	}
+	config, err := store.ParseConfig(mustReadFile(t, configPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Storage != nil || len(config.Tunnels) != 2 {
+		t.Errorf("Expected 2 tunnels in the config file without storage, got %d and %+v", len(config.Tunnels), config.Storage)
+	}
+}
+
+// mustReadFile reads a file or fails the test
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
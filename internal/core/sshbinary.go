@@ -0,0 +1,123 @@
+// Package core provides lookup and capability detection of the ssh executable.
+package core
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+)
+
+// SSHPathEnv is the environment variable that overrides the configured ssh executable
+const SSHPathEnv = "TUNNELMAN_SSH"
+
+// SSHVersion is the version of an OpenSSH client
+type SSHVersion struct {
+	Major int
+	Minor int
+
+	// Raw is the version banner printed by ssh -V
+	Raw string
+}
+
+// Known reports whether the version was recognized as OpenSSH
+func (v SSHVersion) Known() bool {
+	return v.Major > 0
+}
+
+// AtLeast reports whether the version is major.minor or newer
+func (v SSHVersion) AtLeast(major, minor int) bool {
+	return v.Major > major || v.Major == major && v.Minor >= minor
+}
+
+// String returns the version as major.minor
+func (v SSHVersion) String() string {
+	if !v.Known() {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// sshVersionPattern matches OpenSSH version banners such as
+// "OpenSSH_9.2p1 Debian-2" or "OpenSSH_for_Windows_8.1p1"
+var sshVersionPattern = regexp.MustCompile(`OpenSSH_(?:for_Windows_)?(\d+)\.(\d+)`)
+
+// parseSSHVersion extracts the OpenSSH version from ssh -V output
+func parseSSHVersion(output string) SSHVersion {
+	match := sshVersionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return SSHVersion{}
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return SSHVersion{Major: major, Minor: minor, Raw: match[0]}
+}
+
+// ResolveSSHPath returns the ssh executable to run: the TUNNELMAN_SSH
+// environment variable, else the configured path, else ssh from PATH. It
+// returns an error if the executable does not exist.
+func ResolveSSHPath(configured string) (string, error) {
+	name := "ssh"
+	switch {
+	case os.Getenv(SSHPathEnv) != "":
+		name = os.Getenv(SSHPathEnv)
+	case configured != "":
+		name = configured
+	}
+
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return "", fmt.Errorf("ssh executable %q not found: %w", name, err)
+	}
+	return path, nil
+}
+
+// DetectSSHVersion runs ssh -V to find the version of the ssh executable.
+// Clients other than OpenSSH yield an unknown version.
+func DetectSSHVersion(path string) (SSHVersion, error) {
+	out, err := exec.Command(path, "-V").CombinedOutput()
+	if err != nil {
+		return SSHVersion{}, fmt.Errorf("failed to run %s -V: %w", path, err)
+	}
+	return parseSSHVersion(string(out)), nil
+}
+
+// sshFeature is a tunnel option that needs a minimum OpenSSH version
+type sshFeature struct {
+	name         string
+	major, minor int
+	used         func(t *Tunnel) bool
+}
+
+// sshFeatures lists tunnel options newer than the oldest supported OpenSSH
+var sshFeatures = []sshFeature{
+	{"reverse dynamic forwards", 7, 6, func(t *Tunnel) bool {
+		for _, f := range t.forwardsLocked() {
+			if f.Type == ReverseDynamicForward {
+				return true
+			}
+		}
+		return false
+	}},
+	{"jump hosts (-J)", 7, 3, func(t *Tunnel) bool { return t.JumpHost != "" }},
+}
+
+// checkSSHFeatures returns an error naming the first option of the tunnel the
+// ssh version does not support. Unknown versions are not checked.
+func checkSSHFeatures(t *Tunnel, version SSHVersion) error {
+	if !version.Known() {
+		return nil
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	for _, feature := range sshFeatures {
+		if feature.used(t) && !version.AtLeast(feature.major, feature.minor) {
+			return fmt.Errorf("%s need OpenSSH %d.%d or newer, but %s is installed",
+				feature.name, feature.major, feature.minor, version.Raw)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+// Package core provides ssh executable detection tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestParseSSHVersion tests parsing of ssh -V banners
+func TestParseSSHVersion(t *testing.T) {
+	tests := []struct {
+		output       string
+		major, minor int
+	}{
+		{"OpenSSH_9.2p1 Debian-2+deb12u7, OpenSSL 3.0.17 1 Jul 2025", 9, 2},
+		{"OpenSSH_7.4p1, OpenSSL 1.0.2k-fips  26 Jan 2017", 7, 4},
+		{"OpenSSH_for_Windows_8.1p1, LibreSSL 3.0.2", 8, 1},
+		{"Dropbear SSH multi-purpose v2022.83", 0, 0},
+	}
+	for _, tt := range tests {
+		version := parseSSHVersion(tt.output)
+		if version.Major != tt.major || version.Minor != tt.minor {
+			t.Errorf("%q: expected %d.%d, got %s", tt.output, tt.major, tt.minor, version)
+		}
+	}
+}
+
+// TestCheckSSHFeatures tests that options are gated on the OpenSSH version
+func TestCheckSSHFeatures(t *testing.T) {
+	tunnel := &Tunnel{Type: ReverseDynamicForward, RemotePort: 1080, SSHHost: "host"}
+
+	old := parseSSHVersion("OpenSSH_7.4p1")
+	err := checkSSHFeatures(tunnel, old)
+	if err == nil || !strings.Contains(err.Error(), "reverse dynamic forwards need OpenSSH 7.6") {
+		t.Errorf("Expected reverse dynamic forwards to be rejected on 7.4, got %v", err)
+	}
+	if err := checkSSHFeatures(tunnel, parseSSHVersion("OpenSSH_7.6p1")); err != nil {
+		t.Errorf("Expected reverse dynamic forwards on 7.6 to pass, got %v", err)
+	}
+	if err := checkSSHFeatures(tunnel, SSHVersion{}); err != nil {
+		t.Errorf("Expected unknown versions not to be checked, got %v", err)
+	}
+
+	// Additional forwards count too
+	tunnel = &Tunnel{Type: LocalForward, Forwards: []Forward{{Type: ReverseDynamicForward, RemotePort: 1080}}}
+	if err := checkSSHFeatures(tunnel, old); err == nil {
+		t.Error("Expected an additional reverse dynamic forward to be rejected on 7.4")
+	}
+
+	tunnel = &Tunnel{Type: LocalForward, JumpHost: "bastion"}
+	if err := checkSSHFeatures(tunnel, parseSSHVersion("OpenSSH_7.2p2")); err == nil {
+		t.Error("Expected jump hosts to be rejected on 7.2")
+	}
+}
+
+// TestResolveSSHPath tests that the environment overrides the configured path
+// and that missing executables are reported
+func TestResolveSSHPath(t *testing.T) {
+	dir := t.TempDir()
+	configured := filepath.Join(dir, "configured-ssh")
+	override := filepath.Join(dir, "env-ssh")
+	for _, path := range []string{configured, override} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	t.Setenv(SSHPathEnv, "")
+	if path, err := ResolveSSHPath(configured); err != nil || path != configured {
+		t.Errorf("Expected %s, got %s (%v)", configured, path, err)
+	}
+
+	t.Setenv(SSHPathEnv, override)
+	if path, err := ResolveSSHPath(configured); err != nil || path != override {
+		t.Errorf("Expected %s from the environment, got %s (%v)", override, path, err)
+	}
+
+	t.Setenv(SSHPathEnv, "")
+	if _, err := ResolveSSHPath(filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected a missing executable to be reported")
+	}
+}
+
+// TestSSHVersionDetectedWithoutLock tests that detecting the ssh version does
+// not hold up other uses of the process manager
+func TestSSHVersionDetectedWithoutLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script as ssh")
+	}
+	dir := t.TempDir()
+	started := filepath.Join(dir, "started")
+	release := filepath.Join(dir, "release")
+	ssh := filepath.Join(dir, "ssh")
+	script := "#!/bin/sh\ntouch " + started + "\nwhile [ ! -e " + release + " ]; do sleep 0.01; done\necho OpenSSH_9.6p1 >&2\n"
+	if err := os.WriteFile(ssh, []byte(script), 0o755); err != nil {
+		t.Fatalf("Failed to write %s: %v", ssh, err)
+	}
+	t.Setenv(SSHPathEnv, ssh)
+
+	pm := NewProcessManager()
+	if err := pm.SetSSHPath(""); err != nil {
+		t.Fatalf("SetSSHPath failed: %v", err)
+	}
+	detected := make(chan SSHVersion, 1)
+	go func() {
+		version, _ := pm.SSHVersion()
+		detected <- version
+	}()
+	for {
+		if _, err := os.Stat(started); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	path := make(chan string, 1)
+	go func() { path <- pm.SSHPath() }()
+	select {
+	case got := <-path:
+		if got != ssh {
+			t.Errorf("Expected %s, got %s", ssh, got)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected SSHPath not to wait for the version detection")
+	}
+
+	if err := os.WriteFile(release, nil, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", release, err)
+	}
+	if version := <-detected; !version.Known() {
+		t.Errorf("Expected an OpenSSH version, got %+v", version)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -12,13 +13,16 @@ import (
 
 // SSHConfigHost represents a host configuration from SSH config
 type SSHConfigHost struct {
-	Name           string
-	HostName       string
-	User           string
-	Port           int
-	LocalForwards  []ForwardSpec
-	RemoteForwards []ForwardSpec
-	DynamicForwards []DynamicSpec
+	Name                   string
+	HostName               string
+	User                   string
+	Port                   int
+	LocalForwards          []ForwardSpec
+	RemoteForwards         []ForwardSpec
+	DynamicForwards        []DynamicSpec
+	ReverseDynamicForwards []DynamicSpec
+	IdentityFiles          []string
+	ProxyJump              string
 }
 
 // ForwardSpec represents a port forwarding specification
@@ -49,7 +53,10 @@ func NewSSHConfigParser() *SSHConfigParser {
 	}
 }
 
-// ParseHost parses SSH config for a specific host
+// ParseHost parses SSH config for a specific host the way OpenSSH reads it:
+// settings apply from every matching Host and Match block, the first value
+// of a setting wins, forwards add up, and %-tokens are expanded once the
+// whole file is read. It returns nil if no block matches the host.
 func (p *SSHConfigParser) ParseHost(hostAlias string) (*SSHConfigHost, error) {
 	file, err := os.Open(p.configPath)
 	if err != nil {
@@ -62,79 +69,252 @@ func (p *SSHConfigParser) ParseHost(hostAlias string) (*SSHConfigHost, error) {
 	defer file.Close()
 
 	scanner := bufio.NewScanner(file)
-	var currentHost *SSHConfigHost
-	inTargetHost := false
+	host := &SSHConfigHost{Name: hostAlias}
+	var forwards [][2]string
+	var identityFiles []string
 
+	// Settings before the first Host or Match line apply to all hosts
+	active, matched := true, false
 	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip comments and empty lines
-		if line == "" || strings.HasPrefix(line, "#") {
+		key, value := splitConfigLine(scanner.Text())
+		switch key {
+		case "":
 			continue
-		}
-
-		// Check for Host directive
-		if strings.HasPrefix(strings.ToLower(line), "host ") {
-			hostLine := strings.TrimSpace(line[5:])
-			hosts := strings.Fields(hostLine)
-
-			// Check if this is our target host
-			inTargetHost = false
-			for _, h := range hosts {
-				if h == hostAlias || matchesPattern(hostAlias, h) {
-					currentHost = &SSHConfigHost{
-						Name: hostAlias,
-					}
-					inTargetHost = true
-					break
-				}
-			}
+		case "host":
+			active = matchesPatternList(hostAlias, strings.Join(strings.Fields(value), ","))
+			matched = matched || active
 			continue
-		}
-
-		// Skip if not in target host
-		if !inTargetHost || currentHost == nil {
+		case "match":
+			active = host.matchCriteria(splitConfigArgs(value))
+			matched = matched || active
 			continue
 		}
-
-		// Parse host configuration
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
+		if !active || value == "" {
 			continue
 		}
 
-		key := strings.ToLower(parts[0])
-		value := strings.Join(parts[1:], " ")
-
 		switch key {
 		case "hostname":
-			currentHost.HostName = value
+			if host.HostName == "" {
+				host.HostName = value
+			}
 		case "user":
-			currentHost.User = value
+			if host.User == "" {
+				host.User = value
+			}
 		case "port":
-			if port, err := strconv.Atoi(value); err == nil {
-				currentHost.Port = port
+			if port, err := strconv.Atoi(value); err == nil && host.Port == 0 {
+				host.Port = port
+			}
+		case "identityfile":
+			identityFiles = append(identityFiles, value)
+		case "proxyjump":
+			if host.ProxyJump == "" {
+				host.ProxyJump = value
 			}
+		case "localforward", "remoteforward", "dynamicforward":
+			// Kept until the end, as their tokens expand to the final settings
+			forwards = append(forwards, [2]string{key, value})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading SSH config: %w", err)
+	}
+	if !matched {
+		return nil, nil
+	}
+
+	host.HostName = host.targetHost()
+	tokens := host.tokens()
+	for _, identityFile := range identityFiles {
+		host.IdentityFiles = append(host.IdentityFiles, ExpandHome(expandSSHTokens(identityFile, tokens)))
+	}
+	if host.ProxyJump == "none" {
+		host.ProxyJump = ""
+	}
+	for _, forward := range forwards {
+		value := expandSSHTokens(forward[1], tokens)
+		switch forward[0] {
 		case "localforward":
-			if forward := parseLocalForward(value); forward != nil {
-				currentHost.LocalForwards = append(currentHost.LocalForwards, *forward)
+			if spec := parseLocalForward(value); spec != nil {
+				host.LocalForwards = append(host.LocalForwards, *spec)
 			}
 		case "remoteforward":
-			if forward := parseRemoteForward(value); forward != nil {
-				currentHost.RemoteForwards = append(currentHost.RemoteForwards, *forward)
+			// A RemoteForward without a destination is a remote SOCKS proxy
+			if len(strings.Fields(value)) == 1 {
+				if dynamic := parseDynamicForward(value); dynamic != nil {
+					host.ReverseDynamicForwards = append(host.ReverseDynamicForwards, *dynamic)
+				}
+			} else if spec := parseRemoteForward(value); spec != nil {
+				host.RemoteForwards = append(host.RemoteForwards, *spec)
 			}
 		case "dynamicforward":
 			if dynamic := parseDynamicForward(value); dynamic != nil {
-				currentHost.DynamicForwards = append(currentHost.DynamicForwards, *dynamic)
+				host.DynamicForwards = append(host.DynamicForwards, *dynamic)
 			}
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading SSH config: %w", err)
+	return host, nil
+}
+
+// splitConfigLine returns the lowercased keyword of an SSH config line and
+// its value, which may be separated by spaces or "=". The keyword is empty
+// for blank lines and comments.
+func splitConfigLine(line string) (string, string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", ""
+	}
+	end := strings.IndexAny(line, " \t=")
+	if end < 0 {
+		return strings.ToLower(line), ""
+	}
+	value := strings.TrimSpace(line[end:])
+	value = strings.TrimSpace(strings.TrimPrefix(value, "="))
+	return strings.ToLower(line[:end]), value
+}
+
+// splitConfigArgs splits the arguments of an SSH config line at spaces,
+// keeping double-quoted arguments whole
+func splitConfigArgs(value string) []string {
+	var args []string
+	var arg strings.Builder
+	inArg, quoted := false, false
+	for _, r := range value {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inArg = true
+		case !quoted && (r == ' ' || r == '\t'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// matchCriteria reports whether the criteria of a Match line apply to the
+// host as configured so far. Match exec and other criteria that need more
+// than the config to decide never apply, as their commands are not run.
+func (h *SSHConfigHost) matchCriteria(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	for i := 0; i < len(args); i++ {
+		criterion := strings.ToLower(args[i])
+		negate := strings.HasPrefix(criterion, "!")
+		criterion = strings.TrimPrefix(criterion, "!")
+
+		var ok bool
+		switch criterion {
+		case "all":
+			ok = true
+		case "final":
+			// Reading the file once gives what OpenSSH's final pass sees
+			ok = true
+		case "canonical":
+			ok = false
+		case "host", "originalhost", "user", "localuser":
+			if i+1 >= len(args) {
+				return false
+			}
+			i++
+			switch criterion {
+			case "host":
+				ok = matchesPatternList(strings.ToLower(h.targetHost()), strings.ToLower(args[i]))
+			case "originalhost":
+				ok = matchesPatternList(strings.ToLower(h.Name), strings.ToLower(args[i]))
+			case "user":
+				ok = matchesPatternList(h.remoteUser(), args[i])
+			case "localuser":
+				ok = matchesPatternList(localUsername(), args[i])
+			}
+		default:
+			return false
+		}
+		if ok == negate {
+			return false
+		}
+	}
+	return true
+}
+
+// targetHost returns the host name ssh connects to: HostName with its
+// tokens expanded, or the alias itself
+func (h *SSHConfigHost) targetHost() string {
+	if h.HostName == "" {
+		return h.Name
+	}
+	return expandSSHTokens(h.HostName, map[byte]string{'h': h.Name, 'n': h.Name})
+}
+
+// remoteUser returns the user ssh logs in as
+func (h *SSHConfigHost) remoteUser() string {
+	if h.User != "" {
+		return h.User
+	}
+	return localUsername()
+}
+
+// tokens returns the values of the %-tokens of the host
+func (h *SSHConfigHost) tokens() map[byte]string {
+	port := h.Port
+	if port == 0 {
+		port = 22
+	}
+	homeDir, _ := os.UserHomeDir()
+	return map[byte]string{
+		'h': h.targetHost(),
+		'n': h.Name,
+		'p': strconv.Itoa(port),
+		'r': h.remoteUser(),
+		'u': localUsername(),
+		'd': homeDir,
+	}
+}
+
+// expandSSHTokens replaces the %-tokens of an SSH config value. Unknown
+// tokens are left as they are; "%%" is a literal percent sign.
+func expandSSHTokens(value string, tokens map[byte]string) string {
+	if !strings.Contains(value, "%") {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' || i+1 >= len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		if value[i] == '%' {
+			b.WriteByte('%')
+		} else if expansion, ok := tokens[value[i]]; ok {
+			b.WriteString(expansion)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(value[i])
+		}
 	}
+	return b.String()
+}
 
-	return currentHost, nil
+// localUsername returns the name of the user running tunnelman
+func localUsername() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
 }
 
 // parseLocalForward parses a LocalForward specification
@@ -146,12 +326,15 @@ func parseLocalForward(spec string) *ForwardSpec {
 	}
 
 	// Parse bind address and port
-	bindParts := strings.Split(parts[0], ":")
+	bindParts, err := splitForwardSpec(parts[0])
+	if err != nil {
+		return nil
+	}
 	var bindAddress string
 	var bindPort int
 
 	if len(bindParts) == 2 {
-		bindAddress = bindParts[0]
+		bindAddress = NormalizeHost(bindParts[0])
 		bindPort, _ = strconv.Atoi(bindParts[1])
 	} else {
 		bindAddress = "0.0.0.0"
@@ -159,8 +342,8 @@ func parseLocalForward(spec string) *ForwardSpec {
 	}
 
 	// Parse destination
-	destParts := strings.Split(parts[1], ":")
-	if len(destParts) != 2 {
+	destParts, err := splitForwardSpec(parts[1])
+	if err != nil || len(destParts) != 2 {
 		return nil
 	}
 
@@ -169,7 +352,7 @@ func parseLocalForward(spec string) *ForwardSpec {
 	return &ForwardSpec{
 		BindAddress: bindAddress,
 		BindPort:    bindPort,
-		Host:        destParts[0],
+		Host:        NormalizeHost(destParts[0]),
 		HostPort:    hostPort,
 	}
 }
@@ -184,12 +367,15 @@ func parseRemoteForward(spec string) *ForwardSpec {
 // parseDynamicForward parses a DynamicForward specification
 // Format: [bind_address:]port
 func parseDynamicForward(spec string) *DynamicSpec {
-	parts := strings.Split(spec, ":")
+	parts, err := splitForwardSpec(spec)
+	if err != nil {
+		return nil
+	}
 	var bindAddress string
 	var bindPort int
 
 	if len(parts) == 2 {
-		bindAddress = parts[0]
+		bindAddress = NormalizeHost(parts[0])
 		bindPort, _ = strconv.Atoi(parts[1])
 	} else {
 		bindAddress = "0.0.0.0"
@@ -202,19 +388,47 @@ func parseDynamicForward(spec string) *DynamicSpec {
 	}
 }
 
-// matchesPattern checks if a host matches a pattern (simple wildcard support)
-func matchesPattern(host, pattern string) bool {
-	if pattern == "*" {
-		return true
+// matchesPatternList checks if a host matches a comma-separated list of
+// patterns. A negated pattern, like "!bastion", that matches rules the host
+// out regardless of the others.
+func matchesPatternList(host, patterns string) bool {
+	matched := false
+	for _, pattern := range strings.Split(patterns, ",") {
+		if negated, ok := strings.CutPrefix(pattern, "!"); ok {
+			if matchesPattern(host, negated) {
+				return false
+			}
+		} else if pattern != "" && matchesPattern(host, pattern) {
+			matched = true
+		}
 	}
+	return matched
+}
 
-	// Simple wildcard matching (e.g., *.example.com)
-	if strings.HasPrefix(pattern, "*") {
-		suffix := pattern[1:]
-		return strings.HasSuffix(host, suffix)
+// matchesPattern checks if a host matches a pattern, where "*" matches any
+// run of characters and "?" any single one
+func matchesPattern(host, pattern string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			for i := len(host); i >= 0; i-- {
+				if matchesPattern(host[i:], pattern[1:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if host == "" {
+				return false
+			}
+		default:
+			if host == "" || host[0] != pattern[0] {
+				return false
+			}
+		}
+		host, pattern = host[1:], pattern[1:]
 	}
-
-	return host == pattern
+	return host == ""
 }
 
 // ConvertToTunnels converts SSH config host to Tunnelman tunnels
@@ -264,5 +478,22 @@ func (h *SSHConfigHost) ConvertToTunnels() []*Tunnel {
 		tunnels = append(tunnels, tunnel)
 	}
 
+	// Convert reverse DynamicForwards
+	for i, dyn := range h.ReverseDynamicForwards {
+		tunnel := &Tunnel{
+			ID:         fmt.Sprintf("%s-reverse-dynamic-%d", h.Name, i+1),
+			Name:       fmt.Sprintf("%s Remote SOCKS %d", h.Name, dyn.BindPort),
+			Type:       ReverseDynamicForward,
+			SSHHost:    h.Name,
+			RemotePort: dyn.BindPort,
+			Profile:    "ssh-config",
+		}
+		tunnels = append(tunnels, tunnel)
+	}
+
+	for _, tunnel := range tunnels {
+		tunnel.ImportedFrom = sshConfigOrigin(h.Name)
+	}
+
 	return tunnels
 }
\ No newline at end of file
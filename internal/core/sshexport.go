@@ -0,0 +1,272 @@
+// Package core provides exporting tunnels as SSH config Host blocks.
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markers around the section of an SSH config that tunnelman manages
+const (
+	SSHConfigSectionBegin = "# BEGIN tunnelman managed tunnels"
+	SSHConfigSectionEnd   = "# END tunnelman managed tunnels"
+)
+
+// DefaultSSHHostPrefix starts the Host aliases of exported tunnels, so they
+// do not clash with the user's own hosts
+const DefaultSSHHostPrefix = "tunnel-"
+
+// sshAliasUnsafe matches the characters replaced in exported Host aliases
+var sshAliasUnsafe = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// SSHConfigExporter renders tunnels as SSH config Host blocks, so they can be
+// brought up with plain ssh, e.g. "ssh -N tunnel-db"
+type SSHConfigExporter struct {
+	prefix string
+	parser *SSHConfigParser
+}
+
+// SSHConfigExporterOption configures an SSHConfigExporter
+type SSHConfigExporterOption func(*SSHConfigExporter)
+
+// WithSSHHostPrefix sets the prefix of the Host aliases
+func WithSSHHostPrefix(prefix string) SSHConfigExporterOption {
+	return func(e *SSHConfigExporter) {
+		e.prefix = prefix
+	}
+}
+
+// WithSSHConfigParser sets the SSH config that SSH hosts given as aliases
+// are resolved in
+func WithSSHConfigParser(parser *SSHConfigParser) SSHConfigExporterOption {
+	return func(e *SSHConfigExporter) {
+		e.parser = parser
+	}
+}
+
+// NewSSHConfigExporter creates an exporter resolving aliases in ~/.ssh/config
+func NewSSHConfigExporter(opts ...SSHConfigExporterOption) *SSHConfigExporter {
+	e := &SSHConfigExporter{
+		prefix: DefaultSSHHostPrefix,
+		parser: NewSSHConfigParser(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Render returns the Host blocks of tunnels, one per tunnel with a line per
+// forward. Aliases are made from the tunnel names and kept unique.
+func (e *SSHConfigExporter) Render(tunnels []*Tunnel) string {
+	var b strings.Builder
+	used := make(map[string]bool)
+	for i, tunnel := range tunnels {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		alias := e.alias(tunnel, used)
+		e.renderHost(&b, alias, tunnel)
+	}
+	return b.String()
+}
+
+// alias returns a unique Host alias for a tunnel
+func (e *SSHConfigExporter) alias(tunnel *Tunnel, used map[string]bool) string {
+	base := slugify(tunnel.Name)
+	if base == "" {
+		base = tunnel.ID
+	}
+	base = e.prefix + base
+
+	alias := base
+	for n := 2; used[alias]; n++ {
+		alias = fmt.Sprintf("%s-%d", base, n)
+	}
+	used[alias] = true
+	return alias
+}
+
+// slugify lowercases a name and replaces the runs of characters that are not
+// letters, digits, dots, dashes or underscores with dashes
+func slugify(name string) string {
+	return strings.Trim(sshAliasUnsafe.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// renderHost writes the Host block of a tunnel
+func (e *SSHConfigExporter) renderHost(b *strings.Builder, alias string, tunnel *Tunnel) {
+	line := func(key, value string) {
+		fmt.Fprintf(b, "    %s %s\n", key, value)
+	}
+
+	fmt.Fprintf(b, "# %s", tunnel.Name)
+	if tunnel.Description != "" {
+		fmt.Fprintf(b, ": %s", strings.ReplaceAll(tunnel.Description, "\n", " "))
+	}
+	fmt.Fprintf(b, "\nHost %s\n", alias)
+
+	// An SSH host that is itself an alias is replaced by what it stands for,
+	// as HostName is not looked up in the SSH config again
+	user, host := "", tunnel.SSHHost
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		user, host = host[:at], host[at+1:]
+	}
+	hostName, port := host, 0
+	if resolved, err := e.parser.ParseHost(host); err == nil && resolved != nil {
+		if resolved.HostName != "" {
+			hostName = resolved.HostName
+		}
+		if user == "" {
+			user = resolved.User
+		}
+		port = resolved.Port
+	}
+	line("HostName", hostName)
+	if user != "" {
+		line("User", user)
+	}
+	if port != 0 {
+		line("Port", strconv.Itoa(port))
+	}
+
+	for _, forward := range tunnel.AllForwards() {
+		key, spec := forward.sshConfigLine()
+		if key != "" {
+			line(key, spec)
+		}
+	}
+
+	if tunnel.JumpHost != "" {
+		line("ProxyJump", tunnel.JumpHost)
+	}
+	if tunnel.IdentityFile != "" {
+		line("IdentityFile", tunnel.IdentityFile)
+		line("IdentitiesOnly", "yes")
+	}
+	if tunnel.CertificateFile != "" {
+		line("CertificateFile", tunnel.CertificateFile)
+	}
+	if tunnel.Compression {
+		line("Compression", "yes")
+	}
+	if tunnel.ServerAliveInterval > 0 {
+		line("ServerAliveInterval", strconv.Itoa(tunnel.ServerAliveInterval))
+	}
+	if tunnel.ServerAliveCountMax > 0 {
+		line("ServerAliveCountMax", strconv.Itoa(tunnel.ServerAliveCountMax))
+	}
+	if tunnel.ExitOnForwardFailure != nil {
+		line("ExitOnForwardFailure", yesNo(*tunnel.ExitOnForwardFailure))
+	}
+
+	// -o options carry over; other ssh arguments have no config equivalent
+	var skipped []string
+	for i := 0; i < len(tunnel.ExtraArgs); i++ {
+		arg := tunnel.ExtraArgs[i]
+		option := ""
+		switch {
+		case arg == "-o" && i+1 < len(tunnel.ExtraArgs):
+			i++
+			option = tunnel.ExtraArgs[i]
+		case strings.HasPrefix(arg, "-o") && len(arg) > 2:
+			option = arg[2:]
+		default:
+			skipped = append(skipped, arg)
+			continue
+		}
+		if key, value, ok := strings.Cut(option, "="); ok {
+			line(key, value)
+		} else {
+			line(option, "")
+		}
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(b, "    # Not exported: %s\n", strings.Join(skipped, " "))
+	}
+}
+
+// sshConfigLine returns the SSH config keyword and value of the forward
+func (f Forward) sshConfigLine() (string, string) {
+	switch f.Type {
+	case LocalForward:
+		return "LocalForward", fmt.Sprintf("%s%d %s:%d", f.localBindPrefix(), f.LocalPort, BracketHost(f.RemoteHost), f.RemotePort)
+	case RemoteForward:
+		localHost := f.LocalHost
+		if localHost == "" || localHost == "0.0.0.0" {
+			localHost = "127.0.0.1"
+		}
+		return "RemoteForward", fmt.Sprintf("%s%d %s:%d", f.remoteBindPrefix(), f.RemotePort, BracketHost(localHost), f.LocalPort)
+	case DynamicForward:
+		return "DynamicForward", fmt.Sprintf("%s%d", f.localBindPrefix(), f.LocalPort)
+	case ReverseDynamicForward:
+		return "RemoteForward", fmt.Sprintf("%s%d", f.remoteBindPrefix(), f.RemotePort)
+	}
+	return "", ""
+}
+
+// DefaultSSHConfigPath returns ~/.ssh/config
+func DefaultSSHConfigPath() string {
+	return NewSSHConfigParser().configPath
+}
+
+// WriteSSHConfigSection replaces the tunnelman section of an SSH config file
+// with content, appending the section if the file has none. The file keeps
+// its permissions, or is created readable by the user only, and its previous
+// contents are kept in path.tunnelman.backup.
+func WriteSSHConfigSection(path, content string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	section := SSHConfigSectionBegin + "\n" + content
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		section += "\n"
+	}
+	section += SSHConfigSectionEnd + "\n"
+
+	existing := string(data)
+	var updated string
+	begin := strings.Index(existing, SSHConfigSectionBegin)
+	end := strings.Index(existing, SSHConfigSectionEnd)
+	switch {
+	case begin >= 0 && end > begin:
+		rest := existing[end+len(SSHConfigSectionEnd):]
+		updated = existing[:begin] + section + strings.TrimPrefix(rest, "\n")
+	case begin >= 0 || end >= 0:
+		return fmt.Errorf("%s has an incomplete tunnelman section; fix or remove its markers", path)
+	case existing == "":
+		updated = section
+	default:
+		if !strings.HasSuffix(existing, "\n") {
+			existing += "\n"
+		}
+		updated = existing + "\n" + section
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if len(data) > 0 {
+		if err := os.WriteFile(path+".tunnelman.backup", data, mode); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, []byte(updated), mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
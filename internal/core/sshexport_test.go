@@ -0,0 +1,83 @@
+// Package core provides SSH config export tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSSHConfigExport tests that tunnels render as Host blocks with a line
+// per forward, resolving SSH hosts given as aliases
+func TestSSHConfigExport(t *testing.T) {
+	dir := t.TempDir()
+	sshConfig := filepath.Join(dir, "config")
+	if err := os.WriteFile(sshConfig, []byte("Host bastion\n    HostName bastion.example.com\n    User ops\n    Port 2222\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	db := NewTunnel("Prod DB", LocalForward)
+	db.SSHHost = "bastion"
+	db.LocalPort = 5432
+	db.RemoteHost = "db.internal"
+	db.RemotePort = 5432
+	db.Forwards = []Forward{{Type: DynamicForward, LocalHost: DefaultBindAddress, LocalPort: 1080}}
+	db.JumpHost = "jump.example.com"
+	db.ExtraArgs = []string{"-o", "StrictHostKeyChecking=accept-new", "-4"}
+
+	web := NewTunnel("prod db", RemoteForward)
+	web.SSHHost = "deploy@web.example.com"
+	web.LocalHost = "0.0.0.0"
+	web.LocalPort = 3000
+	web.RemotePort = 8080
+
+	exporter := NewSSHConfigExporter(WithSSHConfigParser(&SSHConfigParser{configPath: sshConfig}))
+	got := exporter.Render([]*Tunnel{db, web})
+	want := `# Prod DB
+Host tunnel-prod-db
+    HostName bastion.example.com
+    User ops
+    Port 2222
+    LocalForward 5432 db.internal:5432
+    DynamicForward 1080
+    ProxyJump jump.example.com
+    StrictHostKeyChecking accept-new
+    # Not exported: -4
+
+# prod db
+Host tunnel-prod-db-2
+    HostName web.example.com
+    User deploy
+    RemoteForward 8080 127.0.0.1:3000
+`
+	if got != want {
+		t.Errorf("Unexpected export:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Writing replaces the managed section and keeps the rest of the file
+	if err := WriteSSHConfigSection(sshConfig, got); err != nil {
+		t.Fatalf("WriteSSHConfigSection failed: %v", err)
+	}
+	if err := WriteSSHConfigSection(sshConfig, exporter.Render([]*Tunnel{web})); err != nil {
+		t.Fatalf("WriteSSHConfigSection failed: %v", err)
+	}
+	data, err := os.ReadFile(sshConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.HasPrefix(content, "Host bastion\n") || strings.Count(content, SSHConfigSectionBegin) != 1 {
+		t.Errorf("Expected the user's hosts and one managed section, got:\n%s", content)
+	}
+	if strings.Contains(content, "LocalForward") || !strings.Contains(content, "Host tunnel-prod-db\n") {
+		t.Errorf("Expected the section to hold only the last export, got:\n%s", content)
+	}
+	info, err := os.Stat(sshConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the SSH config to stay private, got %v", info.Mode())
+	}
+}
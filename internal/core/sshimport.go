@@ -0,0 +1,190 @@
+// Package core provides re-importing tunnels from the SSH config.
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sshConfigImportPrefix starts the ImportedFrom of tunnels imported from
+// the SSH config, followed by the host
+const sshConfigImportPrefix = "ssh-config:"
+
+// legacySSHConfigID matches the IDs given to tunnels imported from the SSH
+// config before they recorded where they came from
+var legacySSHConfigID = regexp.MustCompile(`^(.+)-(local|remote|dynamic|reverse-dynamic)-[0-9]+$`)
+
+// sshConfigOrigin returns the ImportedFrom of tunnels imported from a host
+func sshConfigOrigin(host string) string {
+	return sshConfigImportPrefix + host
+}
+
+// sshConfigHost returns the SSH config host a tunnel was imported from, if any
+func (t *Tunnel) sshConfigHost() (string, bool) {
+	if host, ok := strings.CutPrefix(t.ImportedFrom, sshConfigImportPrefix); ok {
+		return host, true
+	}
+	if t.ImportedFrom == "" {
+		if m := legacySSHConfigID.FindStringSubmatch(t.ID); m != nil && m[1] == t.SSHHost {
+			return m[1], true
+		}
+	}
+	return "", false
+}
+
+// SyncSSHConfig imports a host from the SSH config again, or every host if
+// host is empty, so the tunnels imported from it match the config: new
+// forwards are added to profile, changed ones updated and those gone from
+// the config removed. Names, profiles and other settings changed since the
+// import are kept. Running tunnels are left alone and reported as skipped.
+func (tm *TunnelManager) SyncSSHConfig(host, profile string) (*ImportResult, error) {
+	var fresh []*Tunnel
+	var err error
+	if host == "" {
+		fresh, err = tm.DiscoverSSHConfigTunnels()
+	} else {
+		hostConfig, parseErr := NewSSHConfigParser().ParseHost(host)
+		if hostConfig != nil {
+			fresh = hostConfig.ConvertToTunnels()
+		}
+		err = parseErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH config: %w", err)
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	// Tunnels imported before from the hosts being synced, by ID
+	previous := make(map[string]*Tunnel)
+	for id, tunnel := range tm.tunnels {
+		if origin, ok := tunnel.sshConfigHost(); ok && (host == "" || origin == host) {
+			previous[id] = tunnel
+		}
+	}
+
+	result := &ImportResult{}
+	saved := make(map[string]*Tunnel, len(tm.tunnels))
+	for id, tunnel := range tm.tunnels {
+		saved[id] = tunnel
+	}
+	changed := false
+
+	// An imported tunnel with the same forwards is the same tunnel, even if
+	// its position among the host's forwards moved
+	var unmatched []*Tunnel
+	for _, tunnel := range fresh {
+		if match := findImportMatch(previous, tunnel); match != nil {
+			delete(previous, match.ID)
+			if match.ImportedFrom != tunnel.ImportedFrom {
+				// Mark tunnels imported before the origin was recorded,
+				// in place as they may be running
+				match.mu.Lock()
+				match.ImportedFrom = tunnel.ImportedFrom
+				match.mu.Unlock()
+				changed = true
+			}
+			continue
+		}
+		unmatched = append(unmatched, tunnel)
+	}
+
+	// Other forwards update the imported tunnel with their ID, or are new
+	for _, tunnel := range unmatched {
+		if existing := previous[tunnel.ID]; existing != nil {
+			delete(previous, tunnel.ID)
+			if existing.Status == StatusRunning {
+				result.Skipped = append(result.Skipped, existing.Name)
+				continue
+			}
+			update := existing.Clone()
+			update.SSHHost = tunnel.SSHHost
+			update.ImportedFrom = tunnel.ImportedFrom
+			update.setForwards(tunnel.AllForwards())
+			tm.tunnels[tunnel.ID] = update
+			result.Updated = append(result.Updated, update.Name)
+			changed = true
+			continue
+		}
+
+		// The ID may be held by a tunnel matched by its forwards, or one
+		// that was not imported
+		base := tunnel.ID
+		for n := 2; tm.tunnels[tunnel.ID] != nil; n++ {
+			tunnel.ID = fmt.Sprintf("%s-%d", base, n)
+		}
+		if profile != "" {
+			tunnel.Profile = profile
+		}
+		tm.tunnels[tunnel.ID] = tunnel
+		result.Added = append(result.Added, tunnel.Name)
+		changed = true
+	}
+
+	// What is left is gone from the SSH config
+	for id, tunnel := range previous {
+		if tunnel.Status == StatusRunning {
+			result.Skipped = append(result.Skipped, tunnel.Name)
+			continue
+		}
+		delete(tm.tunnels, id)
+		result.Removed = append(result.Removed, tunnel.Name)
+		changed = true
+	}
+
+	if changed {
+		if err := tm.saveTunnels(); err != nil {
+			tm.tunnels = saved
+			return nil, fmt.Errorf("failed to save configuration: %w", err)
+		}
+	}
+
+	for _, names := range [][]string{result.Added, result.Updated, result.Skipped, result.Removed} {
+		sort.Strings(names)
+	}
+	return result, nil
+}
+
+// findImportMatch returns the previously imported tunnel with the same SSH
+// host and forwards as an imported one, preferring the one with its ID
+func findImportMatch(previous map[string]*Tunnel, tunnel *Tunnel) *Tunnel {
+	forwards := tunnel.AllForwards()
+	same := func(candidate *Tunnel) bool {
+		return candidate.SSHHost == tunnel.SSHHost && reflect.DeepEqual(candidate.AllForwards(), forwards)
+	}
+	if candidate, ok := previous[tunnel.ID]; ok && same(candidate) {
+		return candidate
+	}
+
+	// Map order is random, so the lowest ID wins for repeatable results
+	var match *Tunnel
+	for _, candidate := range previous {
+		if same(candidate) && (match == nil || candidate.ID < match.ID) {
+			match = candidate
+		}
+	}
+	return match
+}
+
+// setForwards makes the first forward the tunnel's own and the rest
+// additional ones sharing its SSH session
+func (t *Tunnel) setForwards(forwards []Forward) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	primary := forwards[0]
+	t.Type = primary.Type
+	t.LocalHost = primary.LocalHost
+	t.LocalPort = primary.LocalPort
+	t.RemoteHost = primary.RemoteHost
+	t.RemotePort = primary.RemotePort
+	t.RemoteBindAddress = primary.RemoteBindAddress
+	t.Forwards = nil
+	if len(forwards) > 1 {
+		t.Forwards = append([]Forward(nil), forwards[1:]...)
+	}
+}
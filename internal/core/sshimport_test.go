@@ -0,0 +1,206 @@
+// Package core provides SSH config import tests.
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBulkSSHConfigImport tests that forwards are discovered across all
+// hosts and imported in one pass, skipping those imported before
+func TestBulkSSHConfigImport(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshConfig := `Host dev
+    HostName dev.example.com
+    LocalForward 5432 localhost:5432
+    DynamicForward 1080
+
+Host plain
+    HostName plain.example.com
+
+Host prod
+    HostName prod.example.com
+    RemoteForward 9000 localhost:3000
+`
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte(sshConfig), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tm, _ := newTestManager(t, `{"version": "2.0", "tunnels": []}`)
+
+	tunnels, err := tm.DiscoverSSHConfigTunnels()
+	if err != nil {
+		t.Fatalf("DiscoverSSHConfigTunnels failed: %v", err)
+	}
+	hosts := make(map[string]int)
+	for _, tunnel := range tunnels {
+		hosts[tunnel.SSHHost]++
+	}
+	if len(tunnels) != 3 || hosts["dev"] != 2 || hosts["prod"] != 1 {
+		t.Fatalf("Expected 2 forwards of dev and 1 of prod, got %v", hosts)
+	}
+
+	added, err := tm.AddImportedTunnels(tunnels[:1], "imported")
+	if err != nil {
+		t.Fatalf("AddImportedTunnels failed: %v", err)
+	}
+	if len(added) != 1 || added[0].Profile != "imported" {
+		t.Fatalf("Expected 1 tunnel imported into the profile, got %+v", added)
+	}
+
+	// Importing everything again only adds the rest
+	tunnels, err = tm.DiscoverSSHConfigTunnels()
+	if err != nil {
+		t.Fatalf("DiscoverSSHConfigTunnels failed: %v", err)
+	}
+	added, err = tm.AddImportedTunnels(tunnels, "imported")
+	if err != nil {
+		t.Fatalf("AddImportedTunnels failed: %v", err)
+	}
+	if len(added) != 2 || len(tm.GetTunnels()) != 3 {
+		t.Errorf("Expected 2 more tunnels for 3 in all, got %d and %d", len(added), len(tm.GetTunnels()))
+	}
+}
+
+// TestSSHConfigMatchAndTokens tests that Match blocks apply by host and
+// user, that the first value of a setting wins, and that tokens expand to
+// the final settings
+func TestSSHConfigMatchAndTokens(t *testing.T) {
+	sshConfig := filepath.Join(t.TempDir(), "config")
+	content := `Host db?
+    HostName %h.internal.example.com
+
+Host db1 !db2
+    User=dba
+    LocalForward 15432 %h:5432
+
+Match host *.internal.example.com user dba
+    Port 2222
+    RemoteForward 9000 localhost:%p
+
+Match originalhost db2
+    LocalForward 25432 %h:5432
+
+Match exec "test -e /"
+    Port 3333
+
+Host *
+    User nobody
+    Port 22
+`
+	if err := os.WriteFile(sshConfig, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	parser := &SSHConfigParser{configPath: sshConfig}
+
+	host, err := parser.ParseHost("db1")
+	if err != nil {
+		t.Fatalf("ParseHost failed: %v", err)
+	}
+	if host.HostName != "db1.internal.example.com" || host.User != "dba" || host.Port != 2222 {
+		t.Errorf("Expected db1.internal.example.com as dba on port 2222, got %s as %s on port %d", host.HostName, host.User, host.Port)
+	}
+	if len(host.LocalForwards) != 1 || host.LocalForwards[0].Host != "db1.internal.example.com" {
+		t.Errorf("Expected %%h to expand to the host name, got %+v", host.LocalForwards)
+	}
+	if len(host.RemoteForwards) != 1 || host.RemoteForwards[0].HostPort != 2222 {
+		t.Errorf("Expected %%p to expand to the final port, got %+v", host.RemoteForwards)
+	}
+
+	// db2 is ruled out of the Host block, so it is not dba and its Match
+	// host block does not apply
+	host, err = parser.ParseHost("db2")
+	if err != nil {
+		t.Fatalf("ParseHost failed: %v", err)
+	}
+	if host.User != "nobody" || host.Port != 22 || len(host.RemoteForwards) != 0 {
+		t.Errorf("Expected db2 as nobody on port 22 without remote forwards, got %s on port %d with %+v", host.User, host.Port, host.RemoteForwards)
+	}
+	if len(host.LocalForwards) != 1 || host.LocalForwards[0].BindPort != 25432 {
+		t.Errorf("Expected the Match originalhost forward, got %+v", host.LocalForwards)
+	}
+
+	if got := expandSSHTokens("%r@%h:%p 100%% %x", map[byte]string{'r': "ops", 'h': "web", 'p': "22"}); got != "ops@web:22 100% %x" {
+		t.Errorf("Unexpected token expansion: %s", got)
+	}
+}
+
+// TestSyncSSHConfig tests that importing the SSH config again updates and
+// removes the tunnels imported before, keeping their names, and that an
+// unchanged config changes nothing
+func TestSyncSSHConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeSSHConfig := func(content string) {
+		if err := os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSSHConfig(`Host dev
+    LocalForward 5432 localhost:5432
+    DynamicForward 1080
+
+Host old
+    LocalForward 7000 localhost:7000
+`)
+
+	// The SOCKS tunnel was imported before imports were recorded
+	tm, _ := newTestManager(t, `{
+  "version": "2.0",
+  "tunnels": [
+    {"id": "dev-dynamic-1", "name": "My SOCKS", "host": "dev", "localPort": 1080, "mode": "dynamic", "bindAddress": "0.0.0.0"}
+  ]
+}`)
+
+	result, err := tm.SyncSSHConfig("", "work")
+	if err != nil {
+		t.Fatalf("SyncSSHConfig failed: %v", err)
+	}
+	if len(result.Added) != 2 || len(result.Updated)+len(result.Removed)+len(result.Skipped) != 0 {
+		t.Fatalf("Expected 2 tunnels added, got %+v", result)
+	}
+	if socks, _ := tm.GetTunnel("dev-dynamic-1"); socks.ImportedFrom != "ssh-config:dev" {
+		t.Errorf("Expected the earlier import to be marked, got %q", socks.ImportedFrom)
+	}
+
+	renamed, _ := tm.GetTunnel("dev-local-1")
+	update := renamed.Clone()
+	update.Name = "Dev DB"
+	if err := tm.UpdateTunnel(update); err != nil {
+		t.Fatalf("UpdateTunnel failed: %v", err)
+	}
+
+	writeSSHConfig(`Host dev
+    DynamicForward 1080
+    LocalForward 6432 localhost:5432
+`)
+	result, err = tm.SyncSSHConfig("", "work")
+	if err != nil {
+		t.Fatalf("SyncSSHConfig failed: %v", err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != "Dev DB" || len(result.Removed) != 1 || len(result.Added) != 0 {
+		t.Fatalf("Expected Dev DB updated and old's tunnel removed, got %+v", result)
+	}
+	db, _ := tm.GetTunnel("dev-local-1")
+	if db.LocalPort != 6432 || db.Name != "Dev DB" || db.Profile != "work" {
+		t.Errorf("Expected the forward updated with the name and profile kept, got %s on %d in %s", db.Name, db.LocalPort, db.Profile)
+	}
+	if len(tm.GetTunnels()) != 2 {
+		t.Errorf("Expected 2 tunnels, got %d", len(tm.GetTunnels()))
+	}
+
+	result, err = tm.SyncSSHConfig("dev", "")
+	if err != nil {
+		t.Fatalf("SyncSSHConfig failed: %v", err)
+	}
+	if len(result.Added)+len(result.Updated)+len(result.Removed)+len(result.Skipped) != 0 {
+		t.Errorf("Expected an unchanged config to change nothing, got %+v", result)
+	}
+}
@@ -0,0 +1,126 @@
+// Package core provides persistent per-tunnel connection statistics.
+package core
+
+import (
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TunnelStats are the connection counters of a tunnel, kept across restarts
+// of tunnelman
+type TunnelStats struct {
+	Connects int
+	Failures int
+	Restarts int
+
+	// Uptime is the total time the tunnel has been connected, including the current run
+	Uptime time.Duration
+
+	LastConnected *time.Time
+	LastFailure   *time.Time
+}
+
+// Stats returns the statistics of a tunnel
+func (tm *TunnelManager) Stats(id string) (TunnelStats, error) {
+	tunnel, err := tm.GetTunnel(id)
+	if err != nil {
+		return TunnelStats{}, err
+	}
+
+	var stored store.TunnelStats
+	if tm.statsStore != nil {
+		data, err := tm.statsStore.Load()
+		if err != nil {
+			return TunnelStats{}, err
+		}
+		stored = data.Stats[id]
+	}
+	return statsOf(tunnel, stored), nil
+}
+
+// AllStats returns the statistics of all tunnels, reading the stored
+// counters once
+func (tm *TunnelManager) AllStats() (map[string]TunnelStats, error) {
+	var data *store.StatsData
+	if tm.statsStore != nil {
+		var err error
+		if data, err = tm.statsStore.Load(); err != nil {
+			return nil, err
+		}
+	}
+
+	tunnels := tm.GetTunnels()
+	all := make(map[string]TunnelStats, len(tunnels))
+	for _, tunnel := range tunnels {
+		var stored store.TunnelStats
+		if data != nil {
+			stored = data.Stats[tunnel.ID]
+		}
+		all[tunnel.ID] = statsOf(tunnel, stored)
+	}
+	return all, nil
+}
+
+// statsOf combines the stored counters of a tunnel with its current run
+func statsOf(tunnel *Tunnel, stored store.TunnelStats) TunnelStats {
+	stats := TunnelStats{
+		Connects:      stored.Connects,
+		Failures:      stored.Failures,
+		Restarts:      stored.Restarts,
+		Uptime:        time.Duration(stored.UptimeSeconds) * time.Second,
+		LastConnected: stored.LastConnected,
+		LastFailure:   stored.LastFailure,
+	}
+	if tunnel.Status == StatusRunning && tunnel.StartedAt != nil {
+		stats.Uptime += time.Since(*tunnel.StartedAt)
+	}
+	return stats
+}
+
+// updateStats applies fn to the stored statistics of a tunnel. Failures are
+// only logged, since statistics must never get in the way of the tunnels.
+func (tm *TunnelManager) updateStats(id string, fn func(stats *store.TunnelStats)) {
+	if tm.statsStore == nil {
+		return
+	}
+	if err := tm.statsStore.Update(id, fn); err != nil {
+		Debug("Failed to update statistics of tunnel %s: %v", id, err)
+	}
+}
+
+// recordConnect counts a successful start of a tunnel
+func (tm *TunnelManager) recordConnect(id string) {
+	tm.updateStats(id, func(stats *store.TunnelStats) {
+		now := time.Now()
+		stats.Connects++
+		stats.LastConnected = &now
+	})
+}
+
+// recordFailure counts a failed start or a failure of a running tunnel
+func (tm *TunnelManager) recordFailure(id string) {
+	tm.updateStats(id, func(stats *store.TunnelStats) {
+		now := time.Now()
+		stats.Failures++
+		stats.LastFailure = &now
+	})
+}
+
+// recordRestart counts a restart of a tunnel
+func (tm *TunnelManager) recordRestart(id string) {
+	tm.updateStats(id, func(stats *store.TunnelStats) {
+		stats.Restarts++
+	})
+}
+
+// recordUptime adds the time since startedAt, if known, to a tunnel's uptime
+func (tm *TunnelManager) recordUptime(id string, startedAt *time.Time) {
+	if startedAt == nil {
+		return
+	}
+	uptime := int64(time.Since(*startedAt).Seconds())
+	tm.updateStats(id, func(stats *store.TunnelStats) {
+		stats.UptimeSeconds += uptime
+	})
+}
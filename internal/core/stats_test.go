@@ -0,0 +1,98 @@
+// Package core provides connection statistics tests.
+package core
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// TestStatsSurviveRestart tests that counters are persisted in the state
+// directory and include the current run's uptime
+func TestStatsSurviveRestart(t *testing.T) {
+	configJSON := `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "example.com", "localPort": 1080, "mode": "dynamic"}
+  ]
+}`
+	tm, configStore := newTestManager(t, configJSON)
+
+	tm.recordConnect("db")
+	tm.recordConnect("db")
+	tm.recordFailure("db")
+	tm.recordRestart("db")
+	started := time.Now().Add(-90 * time.Second)
+	tm.recordUptime("db", &started)
+
+	// A new manager, as after restarting tunnelman, sees the same counters
+	pidStore := tm.pidStore
+	restarted := NewTunnelManager(configStore, pidStore)
+	running := time.Now().Add(-time.Minute)
+	restarted.tunnels["db"].Status = StatusRunning
+	restarted.tunnels["db"].StartedAt = &running
+
+	stats, err := restarted.Stats("db")
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	all, err := restarted.AllStats()
+	if err != nil {
+		t.Fatalf("AllStats: %v", err)
+	}
+	if all["db"].Connects != stats.Connects || all["db"].LastFailure == nil {
+		t.Errorf("Expected AllStats to match Stats, got %+v", all["db"])
+	}
+	if stats.Connects != 2 || stats.Failures != 1 || stats.Restarts != 1 {
+		t.Errorf("Unexpected counters: %+v", stats)
+	}
+	if stats.Uptime < 150*time.Second || stats.Uptime > 160*time.Second {
+		t.Errorf("Expected about 150s of uptime, got %s", stats.Uptime)
+	}
+	if stats.LastConnected == nil || stats.LastFailure == nil {
+		t.Errorf("Expected connect and failure times, got %+v", stats)
+	}
+
+	restarted.tunnels["db"].Status = StatusStopped
+	if err := restarted.DeleteTunnel("db"); err != nil {
+		t.Fatalf("DeleteTunnel: %v", err)
+	}
+	data, err := restarted.statsStore.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if _, exists := data.Stats["db"]; exists {
+		t.Error("Expected the statistics of a deleted tunnel to be removed")
+	}
+}
+
+// TestFailedStartIsCounted tests that a start refused for a port conflict counts as a failure
+func TestFailedStartIsCounted(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	tm, _ := newTestManager(t, "")
+	tm.tunnels["web"] = &Tunnel{
+		ID:        "web",
+		Name:      "web",
+		Type:      DynamicForward,
+		LocalHost: "127.0.0.1",
+		LocalPort: listener.Addr().(*net.TCPAddr).Port,
+		SSHHost:   "example.com",
+		Status:    StatusStopped,
+	}
+	if err := tm.StartTunnel("web"); err == nil {
+		t.Fatal("Expected the start to fail")
+	}
+
+	stats, err := tm.Stats("web")
+	if err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	if stats.Failures != 1 || stats.Connects != 0 {
+		t.Errorf("Expected one failure, got %+v", stats)
+	}
+}
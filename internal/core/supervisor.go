@@ -0,0 +1,179 @@
+// Package core provides tunnel supervision with restart backoff.
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Supervisor keeps a set of tunnels running, restarting them with exponential backoff
+type Supervisor struct {
+	manager   *TunnelManager
+	tunnelIDs []string
+
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	stableAfter    time.Duration
+	pollInterval   time.Duration
+
+	// Restart bookkeeping per tunnel ID
+	backoff     map[string]time.Duration
+	nextAttempt map[string]time.Time
+	startedAt   map[string]time.Time
+}
+
+// SupervisorOption is a functional option for Supervisor
+type SupervisorOption func(*Supervisor)
+
+// WithBackoff sets the initial and maximum delay between restart attempts
+func WithBackoff(initial, max time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.initialBackoff = initial
+		s.maxBackoff = max
+	}
+}
+
+// WithMaxBackoff sets the maximum delay between restart attempts
+func WithMaxBackoff(max time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.maxBackoff = max
+	}
+}
+
+// WithStableAfter sets how long a tunnel must stay up before its backoff resets
+func WithStableAfter(d time.Duration) SupervisorOption {
+	return func(s *Supervisor) {
+		s.stableAfter = d
+	}
+}
+
+// NewSupervisor creates a supervisor for the given tunnel IDs
+func NewSupervisor(manager *TunnelManager, tunnelIDs []string, opts ...SupervisorOption) *Supervisor {
+	s := &Supervisor{
+		manager:        manager,
+		tunnelIDs:      tunnelIDs,
+		initialBackoff: time.Second,
+		maxBackoff:     time.Minute,
+		stableAfter:    time.Minute,
+		pollInterval:   time.Second,
+		backoff:        make(map[string]time.Duration),
+		nextAttempt:    make(map[string]time.Time),
+		startedAt:      make(map[string]time.Time),
+	}
+
+	// The config's reconnect policy applies unless options override it
+	if policy := manager.reconnectPolicy(); policy != nil {
+		if policy.InitialBackoff > 0 {
+			s.initialBackoff = time.Duration(policy.InitialBackoff) * time.Second
+		}
+		if policy.MaxBackoff > 0 {
+			s.maxBackoff = time.Duration(policy.MaxBackoff) * time.Second
+		}
+		if policy.StableAfter > 0 {
+			s.stableAfter = time.Duration(policy.StableAfter) * time.Second
+		}
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Run starts the supervised tunnels and keeps them running until ctx is
+// cancelled, then stops them
+func (s *Supervisor) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	s.check(time.Now())
+
+	for {
+		select {
+		case <-ticker.C:
+			s.check(time.Now())
+
+		case <-ctx.Done():
+			s.stopAll()
+			return
+		}
+	}
+}
+
+// check restarts any supervised tunnel that is down and due for another attempt
+func (s *Supervisor) check(now time.Time) {
+	// Tunnels adopted from another process are not monitored, so their PIDs
+	// are checked to notice when they die
+	s.manager.RefreshStates()
+
+	for _, id := range s.tunnelIDs {
+		tunnel, err := s.manager.GetTunnel(id)
+		if err != nil {
+			// Removed from config while we were running
+			continue
+		}
+
+		switch tunnel.Status {
+		case StatusRunning:
+			if started, ok := s.startedAt[id]; ok && now.Sub(started) >= s.stableAfter {
+				delete(s.backoff, id)
+			}
+			continue
+		case StatusConnecting:
+			continue
+		}
+
+		if now.Before(s.nextAttempt[id]) {
+			continue
+		}
+
+		_, restarted := s.startedAt[id]
+		if restarted {
+			Warn("Tunnel %s is down, restarting", tunnel.Name)
+		}
+
+		delay := nextBackoff(s.backoff[id], s.initialBackoff, s.maxBackoff)
+		s.backoff[id] = delay
+		s.nextAttempt[id] = now.Add(delay)
+		s.startedAt[id] = now
+
+		if err := s.manager.StartTunnel(id); err != nil {
+			Error("Failed to start tunnel %s: %v (retrying in %s)", tunnel.Name, err, delay)
+			continue
+		}
+		Info("Started tunnel: %s", tunnel.Name)
+		if restarted {
+			s.manager.recordRestart(id)
+			s.manager.notifyReconnected(id)
+		}
+	}
+}
+
+// stopAll stops every supervised tunnel that is still running
+func (s *Supervisor) stopAll() {
+	for _, id := range s.tunnelIDs {
+		tunnel, err := s.manager.GetTunnel(id)
+		if err != nil || tunnel.Status != StatusRunning {
+			continue
+		}
+		if err := s.manager.StopTunnel(id); err != nil {
+			Error("Failed to stop tunnel %s: %v", tunnel.Name, err)
+			continue
+		}
+		Info("Stopped tunnel: %s", tunnel.Name)
+	}
+}
+
+// nextBackoff doubles the previous delay, starting at initial and capped at max
+func nextBackoff(previous, initial, max time.Duration) time.Duration {
+	if previous <= 0 {
+		return initial
+	}
+	next := previous * 2
+	if next > max {
+		return max
+	}
+	return next
+}
@@ -0,0 +1,67 @@
+// Package core provides supervisor tests.
+package core
+
+import (
+	"os/exec"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestNextBackoff tests that restart delays double up to the maximum
+func TestNextBackoff(t *testing.T) {
+	expected := []time.Duration{
+		time.Second,
+		2 * time.Second,
+		4 * time.Second,
+		8 * time.Second,
+		10 * time.Second,
+		10 * time.Second,
+	}
+
+	var delay time.Duration
+	for i, want := range expected {
+		delay = nextBackoff(delay, time.Second, 10*time.Second)
+		if delay != want {
+			t.Errorf("Attempt %d: expected %s, got %s", i+1, want, delay)
+		}
+	}
+}
+
+// TestSupervisorRestartsAdoptedTunnel tests that a tunnel adopted from
+// another process is restarted once its process dies
+func TestSupervisorRestartsAdoptedTunnel(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a sleep executable")
+	}
+	t.Setenv(SSHPathEnv, "/nonexistent/ssh")
+	tm, _ := newTestManager(t, mockConfigJSON(freePort(t)), WithMockProcesses(MockConfig{}))
+
+	adopted := exec.Command("sleep", "60")
+	if err := adopted.Start(); err != nil {
+		t.Fatalf("Failed to start a process to adopt: %v", err)
+	}
+	if err := tm.pidStore.AddPid("db", adopted.Process.Pid); err != nil {
+		t.Fatalf("Failed to record PID: %v", err)
+	}
+	tm.RefreshStates()
+	if tunnel, _ := tm.GetTunnel("db"); tunnel.Status != StatusRunning || tunnel.PID != adopted.Process.Pid {
+		t.Fatalf("Expected the tunnel to be adopted, got %s with PID %d", tunnel.Status, tunnel.PID)
+	}
+
+	supervisor := NewSupervisor(tm, []string{"db"})
+	supervisor.check(time.Now())
+	if tunnel, _ := tm.GetTunnel("db"); tunnel.PID != adopted.Process.Pid {
+		t.Fatalf("Expected a live adopted tunnel to be left alone, got PID %d", tunnel.PID)
+	}
+
+	adopted.Process.Kill()
+	adopted.Wait()
+	supervisor.check(time.Now())
+	defer tm.StopTunnel("db")
+
+	tunnel, _ := tm.GetTunnel("db")
+	if tunnel.Status != StatusRunning || tunnel.PID < mockFirstPID {
+		t.Errorf("Expected the dead adopted tunnel to be restarted, got %s with PID %d", tunnel.Status, tunnel.PID)
+	}
+}
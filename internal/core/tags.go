@@ -0,0 +1,122 @@
+// Package core provides tag-based selection of tunnels.
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ParseTags splits a comma-separated list of tags, dropping empty and
+// repeated ones
+func ParseTags(s string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range strings.Split(s, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// validateTags checks that tags can be written as a comma-separated list
+func validateTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" || strings.ContainsAny(tag, ", \t") {
+			return fmt.Errorf("invalid tag: %q (tags cannot be empty or contain commas or spaces)", tag)
+		}
+	}
+	return nil
+}
+
+// HasTag reports whether the tunnel is tagged with tag
+func (t *Tunnel) HasTag(tag string) bool {
+	for _, own := range t.Tags {
+		if own == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTunnelsByTag returns the tunnels tagged with tag sorted by name
+func (tm *TunnelManager) GetTunnelsByTag(tag string) []*Tunnel {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	var tunnels []*Tunnel
+	for _, tunnel := range tm.tunnels {
+		if tunnel.HasTag(tag) {
+			tunnels = append(tunnels, tunnel.Clone())
+		}
+	}
+
+	sort.Slice(tunnels, func(i, j int) bool {
+		return tunnels[i].Name < tunnels[j].Name
+	})
+	return tunnels
+}
+
+// GetTags returns the tags of all tunnels, sorted
+func (tm *TunnelManager) GetTags() []string {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, tunnel := range tm.tunnels {
+		for _, tag := range tunnel.Tags {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// StartByTag starts all stopped tunnels tagged with tag
+func (tm *TunnelManager) StartByTag(tag string) error {
+	tunnels := tm.GetTunnelsByTag(tag)
+	if len(tunnels) == 0 {
+		return fmt.Errorf("no tunnels tagged %s", tag)
+	}
+
+	var failedTunnels []string
+	for i, tunnel := range tunnels {
+		if tunnel.Status == StatusRunning {
+			continue
+		}
+		if err := tm.StartTunnel(tunnel.ID); err != nil {
+			failedTunnels = append(failedTunnels, tunnel.Name)
+			Error("Failed to start tunnel %s: %v", tunnel.Name, err)
+		} else if i < len(tunnels)-1 {
+			// Space out starts like StartProfileTunnels does
+			time.Sleep(200 * time.Millisecond)
+		}
+	}
+
+	if len(failedTunnels) > 0 {
+		return fmt.Errorf("failed to start %d tunnel(s): %v", len(failedTunnels), failedTunnels)
+	}
+	return nil
+}
+
+// StopByTag stops all running tunnels tagged with tag
+func (tm *TunnelManager) StopByTag(tag string) error {
+	var lastErr error
+	for _, tunnel := range tm.GetTunnelsByTag(tag) {
+		if tunnel.Status == StatusRunning {
+			if err := tm.StopTunnel(tunnel.ID); err != nil {
+				lastErr = err
+				Error("Failed to stop tunnel %s: %v", tunnel.Name, err)
+			}
+		}
+	}
+	return lastErr
+}
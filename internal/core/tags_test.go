@@ -0,0 +1,67 @@
+// Package core provides tunnel tag tests.
+package core
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestParseTags tests splitting a comma-separated tag list
+func TestParseTags(t *testing.T) {
+	if got := ParseTags(" web, db,,web ,prod"); !slices.Equal(got, []string{"web", "db", "prod"}) {
+		t.Errorf("Unexpected tags: %q", got)
+	}
+	if got := ParseTags(""); got != nil {
+		t.Errorf("Expected no tags, got %q", got)
+	}
+
+	tunnel := &Tunnel{Name: "db", Type: LocalForward, SSHHost: "db.example.com", LocalPort: 15432, RemoteHost: "localhost", RemotePort: 5432, Tags: []string{"two words"}}
+	if err := tunnel.Validate(); err == nil {
+		t.Error("Expected a tag with a space to be rejected")
+	}
+}
+
+// TestStartStopByTag tests starting and stopping the tunnels with a tag
+// across profiles
+func TestStartStopByTag(t *testing.T) {
+	t.Setenv(SSHPathEnv, "/nonexistent/ssh")
+	configJSON := fmt.Sprintf(`{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "db.example.com", "bindAddress": "127.0.0.1", "localPort": %d, "remotePort": 5432, "mode": "local", "tags": ["backend", "prod"]},
+    {"id": "cache", "name": "cache", "host": "cache.example.com", "bindAddress": "127.0.0.1", "localPort": %d, "remotePort": 6379, "mode": "local", "profile": "work", "tags": ["backend"]},
+    {"id": "web", "name": "web", "host": "web.example.com", "bindAddress": "127.0.0.1", "localPort": %d, "remotePort": 80, "mode": "local", "tags": ["frontend"]}
+  ]
+}`, freePort(t), freePort(t), freePort(t))
+	tm, _ := newTestManager(t, configJSON, WithMockProcesses(MockConfig{ConnectDelay: 10 * time.Millisecond}))
+
+	if tags := tm.GetTags(); !slices.Equal(tags, []string{"backend", "frontend", "prod"}) {
+		t.Errorf("Unexpected tags: %q", tags)
+	}
+
+	if err := tm.StartByTag("backend"); err != nil {
+		t.Fatalf("StartByTag failed: %v", err)
+	}
+	for id, want := range map[string]TunnelStatus{"db": StatusRunning, "cache": StatusRunning, "web": StatusStopped} {
+		if tunnel, _ := tm.GetTunnel(id); tunnel.Status != want {
+			t.Errorf("Expected %s to be %s, got %s", id, want, tunnel.Status)
+		}
+	}
+
+	if err := tm.StopByTag("prod"); err != nil {
+		t.Fatalf("StopByTag failed: %v", err)
+	}
+	if db, _ := tm.GetTunnel("db"); db.Status != StatusStopped {
+		t.Errorf("Expected db to be stopped, got %s", db.Status)
+	}
+	if cache, _ := tm.GetTunnel("cache"); cache.Status != StatusRunning {
+		t.Errorf("Expected cache to keep running, got %s", cache.Status)
+	}
+	tm.StopByTag("backend")
+
+	if err := tm.StartByTag("missing"); err == nil {
+		t.Error("Expected starting an unknown tag to fail")
+	}
+}
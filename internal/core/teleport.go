@@ -0,0 +1,173 @@
+// Package core provides tunnels to nodes of Teleport clusters.
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// teleportCommand is the Teleport client used to list and reach nodes
+const teleportCommand = "tsh"
+
+// teleportNodePort is the port Teleport's SSH service listens on by default
+const teleportNodePort = "3022"
+
+// TeleportNode is a node of a Teleport cluster
+type TeleportNode struct {
+	ID       string
+	Hostname string
+	Addr     string
+	Labels   map[string]string
+}
+
+// teleportNodeJSON is a node as printed by tsh ls --format=json
+type teleportNodeJSON struct {
+	Metadata struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	} `json:"metadata"`
+	Spec struct {
+		Hostname  string `json:"hostname"`
+		Addr      string `json:"addr"`
+		CmdLabels map[string]struct {
+			Result string `json:"result"`
+		} `json:"cmd_labels"`
+	} `json:"spec"`
+}
+
+// TeleportStatus is the login of the active tsh profile
+type TeleportStatus struct {
+	ProfileURL string   `json:"profile_url"`
+	Username   string   `json:"username"`
+	Cluster    string   `json:"cluster"`
+	Logins     []string `json:"logins"`
+}
+
+// ListTeleportNodes lists the nodes of a Teleport cluster, or of the current
+// one if cluster is empty, that have all the given "label=value" labels
+func ListTeleportNodes(cluster string, labels []string) ([]TeleportNode, error) {
+	args := []string{"ls", "--format=json"}
+	if cluster != "" {
+		args = append(args, "--cluster="+cluster)
+	}
+	if len(labels) > 0 {
+		args = append(args, strings.Join(labels, ","))
+	}
+	out, err := runTeleport(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseTeleportNodes(out)
+}
+
+// parseTeleportNodes parses the output of tsh ls --format=json, sorting the
+// nodes by host name
+func parseTeleportNodes(data []byte) ([]TeleportNode, error) {
+	var listed []teleportNodeJSON
+	if err := json.Unmarshal(data, &listed); err != nil {
+		return nil, fmt.Errorf("failed to parse tsh ls output: %w", err)
+	}
+
+	nodes := make([]TeleportNode, 0, len(listed))
+	for _, n := range listed {
+		node := TeleportNode{
+			ID:       n.Metadata.Name,
+			Hostname: n.Spec.Hostname,
+			Addr:     n.Spec.Addr,
+			Labels:   make(map[string]string),
+		}
+		for key, value := range n.Metadata.Labels {
+			node.Labels[key] = value
+		}
+		for key, label := range n.Spec.CmdLabels {
+			node.Labels[key] = label.Result
+		}
+		if node.Hostname == "" {
+			node.Hostname = node.ID
+		}
+		nodes = append(nodes, node)
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Hostname < nodes[j].Hostname
+	})
+	return nodes, nil
+}
+
+// GetTeleportStatus returns the login of the active tsh profile
+func GetTeleportStatus() (*TeleportStatus, error) {
+	out, err := runTeleport("status", "--format=json")
+	if err != nil {
+		return nil, err
+	}
+	var status struct {
+		Active *TeleportStatus `json:"active"`
+	}
+	if err := json.Unmarshal(out, &status); err != nil {
+		return nil, fmt.Errorf("failed to parse tsh status output: %w", err)
+	}
+	if status.Active == nil {
+		return nil, errors.New("not logged in to Teleport; run tsh login first")
+	}
+	return status.Active, nil
+}
+
+// runTeleport runs tsh and returns its output. tsh may ask to log in again,
+// so it shares the terminal.
+func runTeleport(args ...string) ([]byte, error) {
+	path, err := exec.LookPath(teleportCommand)
+	if err != nil {
+		return nil, fmt.Errorf("%s not found; install the Teleport client: %w", teleportCommand, err)
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s %s failed: %w", teleportCommand, args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// NewTeleportTunnel creates a tunnel to a Teleport node, without forwards.
+// ssh reaches the node through the cluster's proxy with tsh proxy ssh, and
+// checks its host certificate against the cluster's CA in tsh's known hosts.
+func NewTeleportTunnel(node TeleportNode, cluster, login string) *Tunnel {
+	tunnel := NewTunnel(node.Hostname, LocalForward)
+	tunnel.SSHHost = node.Hostname
+	if login != "" {
+		tunnel.SSHHost = login + "@" + node.Hostname
+	}
+
+	proxyCommand := teleportCommand + " proxy ssh"
+	if cluster != "" {
+		proxyCommand += " --cluster=" + cluster
+	}
+	proxyCommand += " %r@%h:%p"
+
+	// Nodes dialed directly give their port; those connected through a
+	// reverse tunnel have no address and listen on the default
+	port := teleportNodePort
+	if _, addrPort, err := net.SplitHostPort(node.Addr); err == nil && addrPort != "" {
+		port = addrPort
+	}
+	tunnel.ExtraArgs = []string{
+		"-o", "ProxyCommand=" + proxyCommand,
+		"-o", "UserKnownHostsFile=~/.tsh/known_hosts",
+		"-p", port,
+	}
+
+	tunnel.Tags = []string{"teleport"}
+	tunnel.Description = fmt.Sprintf("Teleport node %s", node.Hostname)
+	if cluster != "" {
+		tunnel.Description += " in cluster " + cluster
+	}
+	return tunnel
+}
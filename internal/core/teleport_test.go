@@ -0,0 +1,43 @@
+// Package core provides Teleport integration tests.
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTeleportNodes tests that nodes are read from tsh ls output and become
+// tunnels through the cluster's proxy
+func TestTeleportNodes(t *testing.T) {
+	output := `[
+  {"kind": "node", "metadata": {"name": "b1c2", "labels": {"env": "prod"}},
+   "spec": {"hostname": "web", "addr": "", "cmd_labels": {"arch": {"period": "1h0m0s", "result": "x86_64"}}}},
+  {"kind": "node", "metadata": {"name": "a9f0"},
+   "spec": {"hostname": "db", "addr": "10.0.0.5:2222"}}
+]`
+	nodes, err := parseTeleportNodes([]byte(output))
+	if err != nil {
+		t.Fatalf("parseTeleportNodes failed: %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].Hostname != "db" || nodes[1].ID != "b1c2" {
+		t.Fatalf("Expected db and web in order, got %+v", nodes)
+	}
+	if want := map[string]string{"env": "prod", "arch": "x86_64"}; !reflect.DeepEqual(nodes[1].Labels, want) {
+		t.Errorf("Expected labels %v, got %v", want, nodes[1].Labels)
+	}
+
+	tunnel := NewTeleportTunnel(nodes[0], "prod", "ubuntu")
+	want := []string{
+		"-o", "ProxyCommand=tsh proxy ssh --cluster=prod %r@%h:%p",
+		"-o", "UserKnownHostsFile=~/.tsh/known_hosts",
+		"-p", "2222",
+	}
+	if tunnel.SSHHost != "ubuntu@db" || !reflect.DeepEqual(tunnel.ExtraArgs, want) {
+		t.Errorf("Unexpected tunnel to %s with %q", tunnel.SSHHost, tunnel.ExtraArgs)
+	}
+
+	// Nodes behind a reverse tunnel have no address of their own
+	if tunnel := NewTeleportTunnel(nodes[1], "", ""); tunnel.SSHHost != "web" || tunnel.ExtraArgs[len(tunnel.ExtraArgs)-1] != teleportNodePort {
+		t.Errorf("Unexpected tunnel to %s with %q", tunnel.SSHHost, tunnel.ExtraArgs)
+	}
+}
@@ -0,0 +1,303 @@
+// Package core provides export and import of tunnel definitions.
+package core
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// ImportMode selects how imported tunnels are combined with existing ones
+type ImportMode string
+
+const (
+	// ImportMerge adds imported tunnels to the existing ones
+	ImportMerge ImportMode = "merge"
+	// ImportReplace removes existing tunnels before adding the imported ones
+	ImportReplace ImportMode = "replace"
+)
+
+// ConflictPolicy selects what happens when an imported tunnel has the ID of an
+// existing tunnel, or the name of one in the same profile
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps the existing tunnel and drops the imported one
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces the existing tunnel with the imported one
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictRename adds the imported tunnel under a new ID and name
+	ConflictRename ConflictPolicy = "rename"
+)
+
+// ImportResult describes what an import did, by tunnel name
+type ImportResult struct {
+	Added   []string
+	Updated []string
+	Renamed []string
+	Skipped []string
+	Removed []string
+
+	// Profiles are the profiles whose settings were added or updated
+	Profiles []string
+}
+
+// ExportConfig returns the stored form of all tunnels, or only those in
+// profile if it is not empty, sorted by name, along with the settings of
+// their profiles
+func (tm *TunnelManager) ExportConfig(profile string) *store.AppConfig {
+	tunnels := tm.GetTunnels()
+	if profile != "" {
+		tunnels = tm.GetTunnelsByProfile(profile)
+	}
+
+	config := &store.AppConfig{
+		Version: store.ConfigVersion,
+		Tunnels: make([]store.TunnelConfig, 0, len(tunnels)),
+	}
+	profiles := make(map[string][]string)
+	for _, t := range tunnels {
+		tc := configFromTunnel(t)
+		tc.Source = ""
+		config.Tunnels = append(config.Tunnels, tc)
+		profiles[profileName(t)] = append(profiles[profileName(t)], t.ID)
+	}
+
+	var declared []store.Profile
+	if stored, err := tm.configStore.LoadConfig(); err == nil {
+		declared = stored.Profiles
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p := store.Profile{Name: name, TunnelIDs: profiles[name]}
+		if i := slices.IndexFunc(declared, func(d store.Profile) bool { return d.Name == name }); i >= 0 {
+			p = declared[i]
+			p.TunnelIDs = profiles[name]
+			p.Source = ""
+		}
+		config.Profiles = append(config.Profiles, p)
+	}
+	return config
+}
+
+// ExportTunnel returns the stored form of a single tunnel, as it appears in
+// an export
+func (tm *TunnelManager) ExportTunnel(id string) (store.TunnelConfig, error) {
+	tunnel, err := tm.GetTunnel(id)
+	if err != nil {
+		return store.TunnelConfig{}, err
+	}
+	tc := configFromTunnel(tunnel)
+	tc.Source = ""
+	return tc, nil
+}
+
+// ImportConfig imports an exported config like ImportTunnels and declares the
+// profiles it describes with their description and auto-connect setting. The
+// settings of existing profiles are only replaced with ConflictOverwrite. If
+// profile is not empty, the tunnels are imported into it instead of the
+// profile they were exported from, which requires them to share one profile.
+func (tm *TunnelManager) ImportConfig(config *store.AppConfig, profile string, mode ImportMode, policy ConflictPolicy) (*ImportResult, error) {
+	tunnels, profiles := config.Tunnels, config.Profiles
+	if profile != "" {
+		if err := validateProfileName(profile); err != nil {
+			return nil, err
+		}
+
+		sources := make(map[string]bool)
+		tunnels = make([]store.TunnelConfig, 0, len(config.Tunnels))
+		for _, tc := range config.Tunnels {
+			if tc.Profile == "" {
+				tc.Profile = "default"
+			}
+			sources[tc.Profile] = true
+			tc.Profile = profile
+			tunnels = append(tunnels, tc)
+		}
+		if len(sources) > 1 {
+			return nil, fmt.Errorf("cannot import tunnels from %d profiles into profile %s", len(sources), profile)
+		}
+
+		profiles = nil
+		for _, p := range config.Profiles {
+			if sources[p.Name] || len(config.Profiles) == 1 {
+				p.Name = profile
+				profiles = append(profiles, p)
+				break
+			}
+		}
+	}
+	return tm.importTunnels(tunnels, profiles, mode, policy)
+}
+
+// ImportTunnels adds the given tunnel definitions, resolving ID and name
+// conflicts with policy. Running tunnels are never changed or removed. Nothing
+// is changed if any imported tunnel is invalid or the config cannot be saved.
+func (tm *TunnelManager) ImportTunnels(configs []store.TunnelConfig, mode ImportMode, policy ConflictPolicy) (*ImportResult, error) {
+	return tm.importTunnels(configs, nil, mode, policy)
+}
+
+// importTunnels adds tunnel definitions and the settings of their profiles
+func (tm *TunnelManager) importTunnels(configs []store.TunnelConfig, profiles []store.Profile, mode ImportMode, policy ConflictPolicy) (*ImportResult, error) {
+	switch mode {
+	case ImportMerge, ImportReplace:
+	default:
+		return nil, fmt.Errorf("invalid import mode: %s", mode)
+	}
+	switch policy {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+	default:
+		return nil, fmt.Errorf("invalid conflict policy: %s", policy)
+	}
+
+	imported := make([]*Tunnel, 0, len(configs))
+	for i, tc := range configs {
+		tunnel := tunnelFromConfig(tc)
+		if tunnel.ID == "" {
+			tunnel.ID = generateID()
+		}
+		if err := tunnel.Validate(); err != nil {
+			return nil, fmt.Errorf("tunnel %d (%s): %w", i+1, tunnel.Name, err)
+		}
+		imported = append(imported, tunnel)
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	previous := make(map[string]*Tunnel, len(tm.tunnels))
+	for id, t := range tm.tunnels {
+		previous[id] = t
+	}
+
+	result := &ImportResult{}
+	if mode == ImportReplace {
+		for id, t := range tm.tunnels {
+			if t.Status == StatusRunning || t.Status == StatusConnecting {
+				continue
+			}
+			delete(tm.tunnels, id)
+			result.Removed = append(result.Removed, t.Name)
+		}
+	}
+
+	for _, tunnel := range imported {
+		tm.importTunnel(tunnel, policy, result)
+	}
+
+	var declared []store.Profile
+	if config, err := tm.configStore.LoadConfig(); err == nil {
+		declared = config.Profiles
+	}
+	declared, result.Profiles = mergeImportedProfiles(declared, profiles, policy)
+
+	if err := tm.saveTunnelsWithProfiles(declared); err != nil {
+		tm.tunnels = previous
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	sort.Strings(result.Removed)
+	return result, nil
+}
+
+// mergeImportedProfiles adds the settings of imported profiles to the declared
+// ones, replacing existing settings only with ConflictOverwrite, and returns
+// the result and the names of the profiles added or updated
+func mergeImportedProfiles(declared, imported []store.Profile, policy ConflictPolicy) ([]store.Profile, []string) {
+	merged := append([]store.Profile(nil), declared...)
+	var changed []string
+	for _, p := range imported {
+		if p.Name == "" {
+			continue
+		}
+		// Tunnel IDs may change on import, and tunnels name their profile
+		p.TunnelIDs = nil
+
+		i := slices.IndexFunc(merged, func(d store.Profile) bool { return d.Name == p.Name })
+		if i < 0 {
+			merged = append(merged, p)
+			changed = append(changed, p.Name)
+			continue
+		}
+		existing := merged[i]
+		existing.TunnelIDs = nil
+		p.Source = existing.Source
+		if policy == ConflictOverwrite && !reflect.DeepEqual(existing, p) {
+			merged[i] = p
+			changed = append(changed, p.Name)
+		}
+	}
+	return merged, changed
+}
+
+// importTunnel adds one imported tunnel, resolving conflicts with policy.
+// The caller must hold tm.mu.
+func (tm *TunnelManager) importTunnel(tunnel *Tunnel, policy ConflictPolicy, result *ImportResult) {
+	conflict, exists := tm.tunnels[tunnel.ID]
+	if !exists {
+		conflict = tm.tunnelNamedLocked(tunnel.Profile, tunnel.Name)
+	}
+	if conflict == nil {
+		tm.tunnels[tunnel.ID] = tunnel
+		result.Added = append(result.Added, tunnel.Name)
+		return
+	}
+
+	// Running tunnels cannot be changed, so they are never overwritten
+	if policy == ConflictOverwrite && (conflict.Status == StatusRunning || conflict.Status == StatusConnecting) {
+		policy = ConflictSkip
+	}
+
+	switch policy {
+	case ConflictOverwrite:
+		delete(tm.tunnels, conflict.ID)
+		tm.tunnels[tunnel.ID] = tunnel
+		result.Updated = append(result.Updated, tunnel.Name)
+
+	case ConflictRename:
+		original := tunnel.Name
+		tunnel.ID = generateID()
+		if tm.tunnelNamedLocked(tunnel.Profile, tunnel.Name) != nil {
+			tunnel.Name = tm.uniqueNameLocked(tunnel.Profile, tunnel.Name)
+		}
+		tm.tunnels[tunnel.ID] = tunnel
+		if tunnel.Name == original {
+			result.Renamed = append(result.Renamed, fmt.Sprintf("%s (new ID %s)", original, tunnel.ID))
+		} else {
+			result.Renamed = append(result.Renamed, fmt.Sprintf("%s -> %s", original, tunnel.Name))
+		}
+
+	default:
+		result.Skipped = append(result.Skipped, tunnel.Name)
+	}
+}
+
+// tunnelNamedLocked returns the tunnel with the given name in profile, if any.
+// The caller must hold tm.mu.
+func (tm *TunnelManager) tunnelNamedLocked(profile, name string) *Tunnel {
+	for _, t := range tm.tunnels {
+		if t.Profile == profile && t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// uniqueNameLocked returns name with the first numeric suffix that no tunnel in
+// profile uses. The caller must hold tm.mu.
+func (tm *TunnelManager) uniqueNameLocked(profile, name string) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if tm.tunnelNamedLocked(profile, candidate) == nil {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,164 @@
+// Package core provides tunnel export and import tests.
+package core
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+const transferTestConfig = `{
+  "version": "1.0",
+  "tunnels": [
+    {"id": "db", "name": "db", "host": "bastion", "localPort": 5432, "remotePort": 5432, "mode": "local"},
+    {"id": "web", "name": "web", "host": "bastion", "localPort": 8080, "remotePort": 80, "mode": "local"}
+  ]
+}`
+
+// TestImportConflictPolicies tests how ID and name clashes are resolved
+func TestImportConflictPolicies(t *testing.T) {
+	incoming := []store.TunnelConfig{
+		{ID: "db", Name: "db", Host: "other", LocalPort: 5433, RemotePort: 5432, Mode: "local"},
+		{ID: "web-2", Name: "web", Host: "other", LocalPort: 8081, RemotePort: 80, Mode: "local"},
+		{ID: "socks", Name: "socks", Host: "other", LocalPort: 1080, Mode: "dynamic"},
+	}
+
+	tests := []struct {
+		policy   ConflictPolicy
+		expected ImportResult
+		count    int
+	}{
+		{ConflictSkip, ImportResult{Added: []string{"socks"}, Skipped: []string{"db", "web"}}, 3},
+		{ConflictOverwrite, ImportResult{Added: []string{"socks"}, Updated: []string{"db", "web"}}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			tm, _ := newTestManager(t, transferTestConfig)
+
+			result, err := tm.ImportTunnels(incoming, ImportMerge, tt.policy)
+			if err != nil {
+				t.Fatalf("Import failed: %v", err)
+			}
+			if !reflect.DeepEqual(*result, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *result)
+			}
+			if n := len(tm.GetTunnels()); n != tt.count {
+				t.Errorf("Expected %d tunnels, got %d", tt.count, n)
+			}
+		})
+	}
+
+	t.Run("rename", func(t *testing.T) {
+		tm, _ := newTestManager(t, transferTestConfig)
+
+		result, err := tm.ImportTunnels(incoming, ImportMerge, ConflictRename)
+		if err != nil {
+			t.Fatalf("Import failed: %v", err)
+		}
+		if len(result.Renamed) != 2 || len(result.Added) != 1 {
+			t.Errorf("Expected 2 renamed and 1 added, got %+v", *result)
+		}
+		if _, err := tm.FindTunnel("web-2"); err != nil {
+			t.Errorf("Expected renamed tunnel web-2: %v", err)
+		}
+		if n := len(tm.GetTunnels()); n != 5 {
+			t.Errorf("Expected 5 tunnels, got %d", n)
+		}
+	})
+}
+
+// TestImportReplaceAndExport tests that replace drops stopped tunnels and export round-trips
+func TestImportReplaceAndExport(t *testing.T) {
+	tm, configStore := newTestManager(t, transferTestConfig)
+
+	incoming := []store.TunnelConfig{
+		{ID: "socks", Name: "socks", Host: "other", LocalPort: 1080, Mode: "dynamic", Profile: "team"},
+	}
+	result, err := tm.ImportTunnels(incoming, ImportReplace, ConflictSkip)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Removed, []string{"db", "web"}) {
+		t.Errorf("Expected db and web removed, got %v", result.Removed)
+	}
+
+	exported := tm.ExportConfig("team")
+	if len(exported.Tunnels) != 1 || exported.Tunnels[0].ID != "socks" {
+		t.Fatalf("Unexpected export: %+v", exported.Tunnels)
+	}
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	if len(config.Tunnels) != 1 {
+		t.Errorf("Expected 1 saved tunnel, got %d", len(config.Tunnels))
+	}
+}
+
+// TestImportRejectsInvalidTunnels tests that an invalid tunnel aborts the whole import
+func TestImportRejectsInvalidTunnels(t *testing.T) {
+	tm, _ := newTestManager(t, transferTestConfig)
+
+	incoming := []store.TunnelConfig{
+		{ID: "ok", Name: "ok", Host: "other", LocalPort: 1080, Mode: "dynamic"},
+		{ID: "bad", Name: "bad", Host: "", LocalPort: 1081, Mode: "dynamic"},
+	}
+	if _, err := tm.ImportTunnels(incoming, ImportMerge, ConflictSkip); err == nil {
+		t.Fatal("Expected import of an invalid tunnel to fail")
+	}
+	if n := len(tm.GetTunnels()); n != 2 {
+		t.Errorf("Expected config to be unchanged, got %d tunnels", n)
+	}
+}
+
+// TestImportProfile tests that a profile's settings travel with its tunnels
+// and that a single profile can be imported under another name
+func TestImportProfile(t *testing.T) {
+	source, _ := newTestManager(t, profilesConfigJSON)
+	exported := source.ExportConfig("staging")
+	if len(exported.Profiles) != 1 || exported.Profiles[0].Description != "Staging databases" || !exported.Profiles[0].AutoConnect {
+		t.Fatalf("Expected the profile's settings to be exported, got %+v", exported.Profiles)
+	}
+
+	tm, configStore := newTestManager(t, transferTestConfig)
+	result, err := tm.ImportConfig(exported, "staging-eu", ImportMerge, ConflictRename)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !reflect.DeepEqual(result.Profiles, []string{"staging-eu"}) || len(result.Added) != 1 || len(result.Renamed) != 1 {
+		t.Errorf("Unexpected result: %+v", *result)
+	}
+	if tunnels := tm.GetTunnelsByProfile("staging-eu"); len(tunnels) != 2 {
+		t.Errorf("Expected both tunnels in the new profile, got %d", len(tunnels))
+	}
+
+	config, err := configStore.LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to reload config: %v", err)
+	}
+	found := false
+	for _, p := range config.Profiles {
+		if p.Name == "staging-eu" {
+			found = p.Description == "Staging databases" && p.AutoConnect
+		}
+	}
+	if !found {
+		t.Errorf("Expected the profile to be declared with its settings: %+v", config.Profiles)
+	}
+
+	// Existing settings are only replaced on overwrite
+	exported.Profiles[0].Description = "Changed"
+	if result, _ := tm.ImportConfig(exported, "staging-eu", ImportMerge, ConflictSkip); len(result.Profiles) != 0 {
+		t.Errorf("Expected the existing profile to be kept, got %+v", result.Profiles)
+	}
+	if result, _ := tm.ImportConfig(exported, "staging-eu", ImportMerge, ConflictOverwrite); !reflect.DeepEqual(result.Profiles, []string{"staging-eu"}) {
+		t.Errorf("Expected the existing profile to be overwritten, got %+v", result.Profiles)
+	}
+
+	if _, err := tm.ImportConfig(source.ExportConfig(""), "all", ImportMerge, ConflictSkip); err == nil {
+		t.Error("Expected importing several profiles into one to fail")
+	}
+}
@@ -0,0 +1,78 @@
+// Package core provides restoring deleted tunnels.
+package core
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// trashLimit is the number of deleted tunnels kept for restoring
+const trashLimit = 20
+
+// DeletedTunnel is a tunnel deleted by this manager, kept so it can be
+// restored
+type DeletedTunnel struct {
+	Config    store.TunnelConfig
+	DeletedAt time.Time
+}
+
+// addToTrashLocked keeps the configuration of a deleted tunnel, dropping the
+// oldest beyond trashLimit. The caller must hold tm.mu.
+func (tm *TunnelManager) addToTrashLocked(tunnel *Tunnel) {
+	tm.trash = append(tm.trash, DeletedTunnel{Config: configFromTunnel(tunnel), DeletedAt: time.Now()})
+	if len(tm.trash) > trashLimit {
+		tm.trash = tm.trash[len(tm.trash)-trashLimit:]
+	}
+}
+
+// DeletedTunnels returns the tunnels deleted since the manager was created,
+// newest first
+func (tm *TunnelManager) DeletedTunnels() []DeletedTunnel {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	deleted := make([]DeletedTunnel, 0, len(tm.trash))
+	for i := len(tm.trash) - 1; i >= 0; i-- {
+		deleted = append(deleted, tm.trash[i])
+	}
+	return deleted
+}
+
+// RestoreTunnel adds a deleted tunnel back with its exact configuration and
+// returns it. An empty id restores the most recently deleted tunnel.
+func (tm *TunnelManager) RestoreTunnel(id string) (*Tunnel, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	index := len(tm.trash) - 1
+	if id != "" {
+		for index >= 0 && tm.trash[index].Config.ID != id {
+			index--
+		}
+	}
+	if index < 0 {
+		if id == "" {
+			return nil, ErrTrashEmpty
+		}
+		return nil, fmt.Errorf("%w among deleted tunnels: %s", ErrTunnelNotFound, id)
+	}
+
+	// The tunnel may be back already, e.g. by undoing the config change
+	config := tm.trash[index].Config
+	if _, exists := tm.tunnels[config.ID]; exists {
+		tm.trash = slices.Delete(tm.trash, index, index+1)
+		return nil, fmt.Errorf("tunnel with ID %s already exists", config.ID)
+	}
+
+	tunnel := tunnelFromConfig(config)
+	tm.tunnels[tunnel.ID] = tunnel
+	if err := tm.saveTunnels(); err != nil {
+		delete(tm.tunnels, tunnel.ID)
+		return nil, fmt.Errorf("failed to save tunnel: %w", err)
+	}
+	tm.trash = slices.Delete(tm.trash, index, index+1)
+	return tunnel.Clone(), nil
+}
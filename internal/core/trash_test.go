@@ -0,0 +1,73 @@
+// Package core provides tests of restoring deleted tunnels.
+package core
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestRestoreTunnel tests that deleted tunnels come back with their exact
+// configuration, most recently deleted first
+func TestRestoreTunnel(t *testing.T) {
+	configJSON := `{
+  "version": "2.0",
+  "tunnels": [
+    {"id": "web", "name": "Web", "host": "web.example.com", "localPort": 8080, "remotePort": 80, "mode": "local", "tags": ["http"], "autoConnect": true},
+    {"id": "db", "name": "DB", "host": "db.example.com", "localPort": 5432, "remotePort": 5432, "mode": "local", "profile": "work"}
+  ]
+}`
+	tm, _ := newTestManager(t, configJSON)
+
+	if _, err := tm.RestoreTunnel(""); !errors.Is(err, ErrTrashEmpty) {
+		t.Fatalf("Expected ErrTrashEmpty before any deletion, got %v", err)
+	}
+
+	web, _ := tm.ExportTunnel("web")
+	for _, id := range []string{"web", "db"} {
+		if err := tm.DeleteTunnel(id); err != nil {
+			t.Fatalf("DeleteTunnel(%s) failed: %v", id, err)
+		}
+	}
+
+	deleted := tm.DeletedTunnels()
+	if len(deleted) != 2 || deleted[0].Config.ID != "db" || deleted[1].Config.ID != "web" {
+		t.Fatalf("Expected db and web in the trash, newest first, got %+v", deleted)
+	}
+
+	// A tunnel can be picked by ID
+	restored, err := tm.RestoreTunnel("web")
+	if err != nil {
+		t.Fatalf("RestoreTunnel(web) failed: %v", err)
+	}
+	if restored.Status != StatusStopped {
+		t.Errorf("Expected the restored tunnel to be stopped, got %s", restored.Status)
+	}
+	if got, _ := tm.ExportTunnel("web"); !reflect.DeepEqual(got, web) {
+		t.Errorf("Expected the exact configuration back\n got: %+v\nwant: %+v", got, web)
+	}
+
+	// An empty ID restores the most recently deleted tunnel
+	restored, err = tm.RestoreTunnel("")
+	if err != nil {
+		t.Fatalf("RestoreTunnel failed: %v", err)
+	}
+	if restored.ID != "db" || restored.Profile != "work" {
+		t.Errorf("Expected db in profile work, got %s in %q", restored.ID, restored.Profile)
+	}
+	if len(tm.DeletedTunnels()) != 0 {
+		t.Errorf("Expected restored tunnels to leave the trash")
+	}
+
+	// The restore was saved
+	if err := tm.ReloadConfig(); err != nil {
+		t.Fatalf("ReloadConfig failed: %v", err)
+	}
+	if len(tm.GetTunnels()) != 2 {
+		t.Errorf("Expected 2 tunnels after reloading, got %d", len(tm.GetTunnels()))
+	}
+
+	if _, err := tm.RestoreTunnel("web"); !errors.Is(err, ErrTunnelNotFound) {
+		t.Errorf("Expected ErrTunnelNotFound for a tunnel not in the trash, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@
 package core
 
 import (
+	"crypto/rand"
 	"fmt"
 	"os/exec"
 	"strconv"
@@ -20,6 +21,9 @@ const (
 	RemoteForward TunnelType = "remote"
 	// DynamicForward represents a dynamic port forwarding tunnel (-D)
 	DynamicForward TunnelType = "dynamic"
+	// ReverseDynamicForward represents a SOCKS proxy on the SSH host that
+	// connects out from the local side (-R port)
+	ReverseDynamicForward TunnelType = "reverse-dynamic"
 )
 
 // TunnelStatus represents the current state of a tunnel
@@ -47,32 +51,110 @@ type Tunnel struct {
 	RemoteHost  string     `json:"remote_host,omitempty"`
 	RemotePort  int        `json:"remote_port,omitempty"`
 	SSHHost     string     `json:"ssh_host"`
+	JumpHost    string     `json:"jump_host,omitempty"`
 	ExtraArgs   []string   `json:"extra_args,omitempty"`
+	Forwards    []Forward  `json:"forwards,omitempty"` // Additional forwards sharing the SSH session
 	AutoConnect bool       `json:"auto_connect"`
 	Profile     string     `json:"profile,omitempty"`
 
+	// Tags group tunnels across profiles, e.g. to start or stop them together
+	Tags []string `json:"tags,omitempty"`
+
+	// Description is a free-text note, e.g. why the tunnel exists and who
+	// owns the remote service
+	Description string `json:"description,omitempty"`
+
+	// RemoteBindAddress is the address remote forwards listen on at the SSH
+	// host. Empty uses the server's default, usually loopback only.
+	RemoteBindAddress string `json:"remote_bind_address,omitempty"`
+
+	// Compression makes ssh compress the session (-C), which helps on slow links
+	Compression bool `json:"compression,omitempty"`
+
+	// Multiplex shares one SSH connection (ControlMaster) between tunnels to
+	// the same host
+	Multiplex bool `json:"multiplex,omitempty"`
+
+	// IdentityFile pins the key ssh authenticates with (-i with
+	// IdentitiesOnly); empty lets ssh try the agent's and default keys
+	IdentityFile string `json:"identity_file,omitempty"`
+
+	// Secret names the keychain entry holding the password or key passphrase
+	// ssh asks for, so it is answered without a prompt or a plaintext config
+	Secret string `json:"secret,omitempty"`
+
+	// CertificateFile is an SSH certificate to authenticate with, and
+	// CertificateCommand a shell command that renews it when it is missing or
+	// about to expire, e.g. by having Vault sign the key
+	CertificateFile    string `json:"certificate_file,omitempty"`
+	CertificateCommand string `json:"certificate_command,omitempty"`
+
+	// HostKey pins the SSH host's key by its SHA256 fingerprint: ssh accepts
+	// only that key, copied from known_hosts, and never asks about new ones
+	HostKey string `json:"host_key,omitempty"`
+
+	// Keepalive and forward failure settings; zero values and nil use the
+	// global defaults. A negative ServerAliveInterval disables keepalives.
+	ServerAliveInterval  int   `json:"server_alive_interval,omitempty"`
+	ServerAliveCountMax  int   `json:"server_alive_count_max,omitempty"`
+	ExitOnForwardFailure *bool `json:"exit_on_forward_failure,omitempty"`
+
+	// Hooks are shell commands run when the tunnel connects, disconnects or fails
+	Hooks Hooks `json:"hooks,omitzero"`
+
 	// Runtime state fields (not persisted)
 	Status    TunnelStatus `json:"-"`
 	PID       int          `json:"-"`
 	StartedAt *time.Time   `json:"-"`
 	LastError error        `json:"-"`
 
+	// FailedHop is the hop of the chain that broke when ssh last exited, if known
+	FailedHop string `json:"-"`
+
+	// Health check state (not persisted)
+	Health          TunnelHealth `json:"-"`
+	HealthError     error        `json:"-"`
+	HealthCheckedAt *time.Time   `json:"-"`
+
+	// NotListening is the local address of a running tunnel that refused
+	// connections though ssh is up, or empty if its ports accept them or were
+	// not checked (not persisted)
+	NotListening string `json:"-"`
+
+	// Latency is the TCP connect time to the first SSH hop, or 0 if it has
+	// not been measured or LatencyError is set (not persisted)
+	Latency      time.Duration `json:"-"`
+	LatencyError error         `json:"-"`
+
+	// Source is the included config file the tunnel is saved in, relative
+	// to the config directory, or empty for the main config file
+	Source string `json:"source,omitempty"`
+
+	// ImportedFrom names what the tunnel was imported from, e.g.
+	// "ssh-config:db" for the host db of the SSH config, so a re-import can
+	// update it
+	ImportedFrom string `json:"imported_from,omitempty"`
+
 	// Internal fields
 	mu      sync.RWMutex
 	process *exec.Cmd
+
+	// Config values written with ${VAR} references, to save them unexpanded
+	templates *configTemplates
 }
 
+// DefaultBindAddress is the address local and dynamic forwards listen on
+// unless another is configured. Loopback keeps forwarded ports private to
+// this machine.
+const DefaultBindAddress = "127.0.0.1"
+
 // NewTunnel creates a new tunnel configuration with sensible defaults
 func NewTunnel(name string, tunnelType TunnelType) *Tunnel {
-	localHost := "0.0.0.0" // Default for LocalForward (bind address)
-	if tunnelType == RemoteForward {
-		localHost = "127.0.0.1" // For RemoteForward, this is the destination
-	}
 	return &Tunnel{
 		ID:        generateID(),
 		Name:      name,
 		Type:      tunnelType,
-		LocalHost: localHost,
+		LocalHost: DefaultBindAddress, // For RemoteForward, this is the destination
 		Status:    StatusStopped,
 	}
 }
@@ -90,6 +172,32 @@ func (t *Tunnel) Validate() error {
 		return fmt.Errorf("SSH host is required")
 	}
 
+	if t.ServerAliveCountMax < 0 {
+		return fmt.Errorf("invalid keepalive count: %d", t.ServerAliveCountMax)
+	}
+
+	if strings.ContainsAny(t.JumpHost, " \t") {
+		return fmt.Errorf("invalid jump host: %q (separate hops with commas)", t.JumpHost)
+	}
+
+	if err := t.primaryForward().validateHosts(); err != nil {
+		return err
+	}
+
+	if err := validateTags(t.Tags); err != nil {
+		return err
+	}
+
+	if t.Secret != "" {
+		if err := ValidateSecretName(t.Secret); err != nil {
+			return err
+		}
+	}
+
+	if t.HostKey != "" && !strings.HasPrefix(t.HostKey, "SHA256:") {
+		return fmt.Errorf("invalid host key: %q (expected a SHA256:... fingerprint)", t.HostKey)
+	}
+
 	switch t.Type {
 	case LocalForward:
 		if t.LocalPort <= 0 || t.LocalPort > 65535 {
@@ -115,10 +223,33 @@ func (t *Tunnel) Validate() error {
 			return fmt.Errorf("invalid local port: %d", t.LocalPort)
 		}
 
+	case ReverseDynamicForward:
+		if t.RemotePort <= 0 || t.RemotePort > 65535 {
+			return fmt.Errorf("invalid remote port: %d", t.RemotePort)
+		}
+
 	default:
 		return fmt.Errorf("invalid tunnel type: %s", t.Type)
 	}
 
+	// Additional forwards must be valid and must not listen on the same port
+	listeners := map[string]bool{}
+	for i, f := range t.forwardsLocked() {
+		if i > 0 {
+			if err := f.Validate(); err != nil {
+				return fmt.Errorf("forward %d: %w", i+1, err)
+			}
+		}
+		key := fmt.Sprintf("local:%d", f.LocalPort)
+		if f.IsRemote() {
+			key = fmt.Sprintf("remote:%d", f.RemotePort)
+		}
+		if listeners[key] {
+			return fmt.Errorf("forward %d: %s listens on the same port as another forward", i+1, f.Summary())
+		}
+		listeners[key] = true
+	}
+
 	return nil
 }
 
@@ -130,38 +261,32 @@ func (t *Tunnel) BuildSSHCommand() []string {
 	args := []string{"ssh"}
 
 	// Add tunnel-specific flags
-	switch t.Type {
-	case LocalForward:
-		forward := fmt.Sprintf("%s:%d:%s:%d",
-			t.LocalHost, t.LocalPort, t.RemoteHost, t.RemotePort)
-		args = append(args, "-L", forward)
-
-	case RemoteForward:
-		// -R [bind_address:]port:host:hostport
-		// RemotePort on remote side forwards to LocalHost:LocalPort
-		// Omitting bind address to use server's default (usually 127.0.0.1)
-		// For external access, server must have GatewayPorts enabled
-		localHost := t.LocalHost
-		if localHost == "" || localHost == "0.0.0.0" {
-			// For RemoteForward, we need a valid destination address
-			localHost = "127.0.0.1"
-		}
-		forward := fmt.Sprintf("%d:%s:%d",
-			t.RemotePort, localHost, t.LocalPort)
-		args = append(args, "-R", forward)
-
-	case DynamicForward:
-		args = append(args, "-D", fmt.Sprintf("%s:%d", t.LocalHost, t.LocalPort))
+	for _, forward := range t.forwardsLocked() {
+		args = append(args, forward.Args()...)
 	}
 
 	// Common SSH options for tunnel stability
 	args = append(args,
 		"-N",                    // No command execution
 		"-T",                    // Disable pseudo-terminal allocation
-		"-o", "ServerAliveInterval=60",  // Keep connection alive
-		"-o", "ServerAliveCountMax=3",   // Max keepalive attempts
-		"-o", "ExitOnForwardFailure=yes", // Exit if port forwarding fails
 	)
+	args = append(args, ConnectionDefaults{}.args(t)...)
+
+	if t.IdentityFile != "" {
+		args = append(args, "-i", ExpandHome(t.IdentityFile), "-o", "IdentitiesOnly=yes")
+	}
+	if t.CertificateFile != "" {
+		args = append(args, "-o", "CertificateFile="+ExpandHome(t.CertificateFile))
+	}
+
+	if t.Compression {
+		args = append(args, "-C")
+	}
+
+	// Connect through the jump host chain
+	if t.JumpHost != "" {
+		args = append(args, "-J", t.JumpHost)
+	}
 
 	// Add any extra arguments
 	args = append(args, t.ExtraArgs...)
@@ -172,22 +297,41 @@ func (t *Tunnel) BuildSSHCommand() []string {
 	return args
 }
 
+// HopChain returns the hosts an SSH connection passes through, ending with the
+// SSH host, e.g. ["bastion", "db-gw", "db"]
+func (t *Tunnel) HopChain() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var hops []string
+	for _, hop := range strings.Split(t.JumpHost, ",") {
+		if hop = strings.TrimSpace(hop); hop != "" {
+			hops = append(hops, hop)
+		}
+	}
+	return append(hops, t.SSHHost)
+}
+
 // GetDisplayName returns a formatted display name for the tunnel
 func (t *Tunnel) GetDisplayName() string {
+	return fmt.Sprintf("%s (%s)", t.Name, t.ForwardSummary())
+}
+
+// ForwardSummary returns a compact description of the forwarded ports
+func (t *Tunnel) ForwardSummary() string {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	var portInfo string
-	switch t.Type {
-	case LocalForward:
-		portInfo = fmt.Sprintf("L:%d→%s:%d", t.LocalPort, t.RemoteHost, t.RemotePort)
-	case RemoteForward:
-		portInfo = fmt.Sprintf("R:%d→%d", t.RemotePort, t.LocalPort)
-	case DynamicForward:
-		portInfo = fmt.Sprintf("D:%d", t.LocalPort)
-	}
+	return strings.Join(SummarizeForwards(t.forwardsLocked()), ", ")
+}
 
-	return fmt.Sprintf("%s (%s)", t.Name, portInfo)
+// DialAddress returns the local address a client connects to for this tunnel.
+// It reports false for remote forwards, which have no local listener.
+func (t *Tunnel) DialAddress() (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return t.primaryForward().DialAddress()
 }
 
 // Clone creates a deep copy of the tunnel configuration
@@ -204,37 +348,224 @@ func (t *Tunnel) Clone() *Tunnel {
 		RemoteHost:  t.RemoteHost,
 		RemotePort:  t.RemotePort,
 		SSHHost:     t.SSHHost,
+		JumpHost:    t.JumpHost,
 		AutoConnect: t.AutoConnect,
+		Profile:     t.Profile,
 		Status:      t.Status,
 		PID:         t.PID,
 		LastError:   t.LastError,
+		FailedHop:   t.FailedHop,
+		Health:      t.Health,
+		HealthError: t.HealthError,
 	}
+	clone.RemoteBindAddress = t.RemoteBindAddress
+	clone.Compression = t.Compression
+	clone.Multiplex = t.Multiplex
+	clone.IdentityFile = t.IdentityFile
+	clone.Secret = t.Secret
+	clone.CertificateFile = t.CertificateFile
+	clone.CertificateCommand = t.CertificateCommand
+	clone.HostKey = t.HostKey
+	clone.ServerAliveInterval = t.ServerAliveInterval
+	clone.ServerAliveCountMax = t.ServerAliveCountMax
+	clone.ExitOnForwardFailure = cloneBool(t.ExitOnForwardFailure)
+	clone.Hooks = t.Hooks
+	clone.templates = t.templates
+	clone.Source = t.Source
+	clone.ImportedFrom = t.ImportedFrom
+	clone.Latency = t.Latency
+	clone.LatencyError = t.LatencyError
+	clone.NotListening = t.NotListening
 
 	if len(t.ExtraArgs) > 0 {
 		clone.ExtraArgs = make([]string, len(t.ExtraArgs))
 		copy(clone.ExtraArgs, t.ExtraArgs)
 	}
 
+	if len(t.Tags) > 0 {
+		clone.Tags = append([]string(nil), t.Tags...)
+	}
+	clone.Description = t.Description
+
+	if len(t.Forwards) > 0 {
+		clone.Forwards = append([]Forward(nil), t.Forwards...)
+	}
+
 	if t.StartedAt != nil {
 		startedAt := *t.StartedAt
 		clone.StartedAt = &startedAt
 	}
 
+	if t.HealthCheckedAt != nil {
+		checkedAt := *t.HealthCheckedAt
+		clone.HealthCheckedAt = &checkedAt
+	}
+
 	return clone
 }
 
-// generateID creates a unique identifier for a tunnel
+// applyConfig copies the configuration fields of src into t, leaving runtime state untouched
+func (t *Tunnel) applyConfig(src *Tunnel) {
+	src.mu.RLock()
+	defer src.mu.RUnlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Name = src.Name
+	t.Type = src.Type
+	t.LocalHost = src.LocalHost
+	t.LocalPort = src.LocalPort
+	t.RemoteHost = src.RemoteHost
+	t.RemotePort = src.RemotePort
+	t.RemoteBindAddress = src.RemoteBindAddress
+	t.SSHHost = src.SSHHost
+	t.JumpHost = src.JumpHost
+	t.ExtraArgs = append([]string(nil), src.ExtraArgs...)
+	t.Forwards = append([]Forward(nil), src.Forwards...)
+	t.AutoConnect = src.AutoConnect
+	t.Profile = src.Profile
+	t.Tags = append([]string(nil), src.Tags...)
+	t.Description = src.Description
+	t.Compression = src.Compression
+	t.Multiplex = src.Multiplex
+	t.IdentityFile = src.IdentityFile
+	t.Secret = src.Secret
+	t.CertificateFile = src.CertificateFile
+	t.CertificateCommand = src.CertificateCommand
+	t.HostKey = src.HostKey
+	t.ServerAliveInterval = src.ServerAliveInterval
+	t.ServerAliveCountMax = src.ServerAliveCountMax
+	t.ExitOnForwardFailure = cloneBool(src.ExitOnForwardFailure)
+	t.Hooks = src.Hooks
+	t.templates = src.templates
+	t.Source = src.Source
+	t.ImportedFrom = src.ImportedFrom
+}
+
+// TunnelSnapshot is a point-in-time, serializable view of a tunnel's
+// configuration and runtime state
+type TunnelSnapshot struct {
+	ID            string       `json:"id"`
+	Name          string       `json:"name"`
+	Type          TunnelType   `json:"type"`
+	SSHHost       string       `json:"ssh_host"`
+	JumpHost      string       `json:"jump_host,omitempty"`
+	LocalHost     string       `json:"local_host,omitempty"`
+	LocalPort     int          `json:"local_port"`
+	RemoteHost    string       `json:"remote_host,omitempty"`
+	RemotePort    int          `json:"remote_port,omitempty"`
+	Profile       string       `json:"profile"`
+	Tags          []string     `json:"tags,omitempty"`
+	Description   string       `json:"description,omitempty"`
+	AutoConnect   bool         `json:"auto_connect"`
+	Forward       string       `json:"forward"`
+	Forwards      []Forward    `json:"forwards,omitempty"`
+	Status        TunnelStatus `json:"status"`
+	PID           int          `json:"pid,omitempty"`
+	StartedAt     *time.Time   `json:"started_at,omitempty"`
+	UptimeSeconds int64        `json:"uptime_seconds,omitempty"`
+	Error         string       `json:"error,omitempty"`
+	ErrorKind     FailureKind  `json:"error_kind,omitempty"`
+	Hint          string       `json:"hint,omitempty"`
+	Health        TunnelHealth `json:"health,omitempty"`
+	HealthError   string       `json:"health_error,omitempty"`
+	NotListening  string       `json:"not_listening,omitempty"`
+	Hops          []Hop        `json:"hops,omitempty"`
+
+	RemoteBindAddress string `json:"remote_bind_address,omitempty"`
+	Compression       bool   `json:"compression,omitempty"`
+	Multiplex         bool   `json:"multiplex,omitempty"`
+	IdentityFile      string `json:"identity_file,omitempty"`
+	Secret            string `json:"secret,omitempty"`
+	CertificateFile   string `json:"certificate_file,omitempty"`
+	HostKey           string `json:"host_key,omitempty"`
+	Source            string `json:"source,omitempty"`
+}
+
+// Snapshot returns a serializable view of the tunnel
+func (t *Tunnel) Snapshot() TunnelSnapshot {
+	forward := t.ForwardSummary()
+	hops := t.Hops()
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snapshot := TunnelSnapshot{
+		ID:          t.ID,
+		Name:        t.Name,
+		Type:        t.Type,
+		SSHHost:     t.SSHHost,
+		JumpHost:    t.JumpHost,
+		LocalHost:   t.LocalHost,
+		LocalPort:   t.LocalPort,
+		RemoteHost:  t.RemoteHost,
+		RemotePort:  t.RemotePort,
+		Profile:     t.Profile,
+		Tags:        append([]string(nil), t.Tags...),
+		Description: t.Description,
+		AutoConnect: t.AutoConnect,
+		Forward:     forward,
+		Status:      t.Status,
+		PID:         t.PID,
+
+		RemoteBindAddress: t.RemoteBindAddress,
+		Compression:       t.Compression,
+		Multiplex:         t.Multiplex,
+		IdentityFile:      t.IdentityFile,
+		Secret:            t.Secret,
+		CertificateFile:   t.CertificateFile,
+		HostKey:           t.HostKey,
+		Source:            t.Source,
+	}
+	if t.StartedAt != nil {
+		startedAt := *t.StartedAt
+		snapshot.StartedAt = &startedAt
+		snapshot.UptimeSeconds = int64(time.Since(startedAt).Seconds())
+	}
+	if t.LastError != nil {
+		snapshot.Error = t.LastError.Error()
+		snapshot.ErrorKind = FailureKindOf(t.LastError)
+		snapshot.Hint = FailureHint(t.LastError)
+	}
+	if t.JumpHost != "" {
+		snapshot.Hops = hops
+	}
+	if len(t.Forwards) > 0 {
+		snapshot.Forwards = append([]Forward(nil), t.Forwards...)
+	}
+	if t.Status == StatusRunning {
+		snapshot.Health = t.Health
+		if t.HealthError != nil {
+			snapshot.HealthError = t.HealthError.Error()
+		}
+		snapshot.NotListening = t.NotListening
+	}
+	return snapshot
+}
+
+// generateID creates a unique identifier for a tunnel (random UUID v4)
 func generateID() string {
-	return fmt.Sprintf("tunnel_%d", time.Now().UnixNano())
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		// crypto/rand never fails on supported platforms, but keep IDs unique regardless
+		return fmt.Sprintf("tunnel_%d", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
 }
 
 // ParseForwardingSpec parses a forwarding specification string
 // Format examples:
 //   - "8080:localhost:80" for local forward
+//   - "8080:[::1]:80" for local forward to an IPv6 address
 //   - "8080:80" for remote forward
 //   - "1080" for dynamic forward
 func ParseForwardingSpec(spec string, tunnelType TunnelType) (localHost string, localPort int, remoteHost string, remotePort int, err error) {
-	parts := strings.Split(spec, ":")
+	parts, err := splitForwardSpec(spec)
+	if err != nil {
+		return
+	}
 
 	switch tunnelType {
 	case LocalForward:
@@ -242,13 +573,13 @@ func ParseForwardingSpec(spec string, tunnelType TunnelType) (localHost string,
 			err = fmt.Errorf("local forward requires format: localPort:remoteHost:remotePort")
 			return
 		}
-		localHost = "0.0.0.0"
+		localHost = DefaultBindAddress
 		localPort, err = strconv.Atoi(parts[0])
 		if err != nil {
 			err = fmt.Errorf("invalid local port: %v", err)
 			return
 		}
-		remoteHost = parts[1]
+		remoteHost = NormalizeHost(parts[1])
 		remotePort, err = strconv.Atoi(parts[2])
 		if err != nil {
 			err = fmt.Errorf("invalid remote port: %v", err)
@@ -260,7 +591,7 @@ func ParseForwardingSpec(spec string, tunnelType TunnelType) (localHost string,
 			err = fmt.Errorf("remote forward requires format: remotePort:localPort")
 			return
 		}
-		localHost = "0.0.0.0"
+		localHost = "127.0.0.1"
 		remotePort, err = strconv.Atoi(parts[0])
 		if err != nil {
 			err = fmt.Errorf("invalid remote port: %v", err)
@@ -277,16 +608,36 @@ func ParseForwardingSpec(spec string, tunnelType TunnelType) (localHost string,
 			err = fmt.Errorf("dynamic forward requires format: localPort")
 			return
 		}
-		localHost = "0.0.0.0"
+		localHost = DefaultBindAddress
 		localPort, err = strconv.Atoi(parts[0])
 		if err != nil {
 			err = fmt.Errorf("invalid local port: %v", err)
 			return
 		}
 
+	case ReverseDynamicForward:
+		if len(parts) != 1 {
+			err = fmt.Errorf("reverse dynamic forward requires format: remotePort")
+			return
+		}
+		remotePort, err = strconv.Atoi(parts[0])
+		if err != nil {
+			err = fmt.Errorf("invalid remote port: %v", err)
+			return
+		}
+
 	default:
 		err = fmt.Errorf("unsupported tunnel type: %s", tunnelType)
 	}
 
 	return
-}
\ No newline at end of file
+}
+
+// cloneBool returns a copy of an optional boolean
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	v := *b
+	return &v
+}
@@ -2,6 +2,7 @@
 package core
 
 import (
+	"fmt"
 	"runtime"
 	"time"
 )
@@ -47,4 +48,23 @@ func IsMacOS() bool {
 // IsLinux returns true if running on Linux
 func IsLinux() bool {
 	return runtime.GOOS == "linux"
-}
\ No newline at end of file
+}
+
+// FormatDuration formats a duration compactly for uptime display (e.g. "1h 2m 3s")
+func FormatDuration(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds)
+	}
+	if minutes > 0 {
+		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
@@ -0,0 +1,181 @@
+// Package core provides validation of whole config files.
+package core
+
+import (
+	"fmt"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// ConfigIssue is a problem found in a config file
+type ConfigIssue struct {
+	// Tunnel is the name of the tunnel with the problem, or empty for
+	// problems with the config as a whole
+	Tunnel  string
+	Message string
+}
+
+// String describes the issue with the tunnel it is about
+func (i ConfigIssue) String() string {
+	if i.Tunnel == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("tunnel '%s': %s", i.Tunnel, i.Message)
+}
+
+// ValidateConfig checks a config for problems that loading it repairs or
+// hides: duplicate or missing IDs, tunnels with the same name in a profile,
+// invalid tunnels such as ones with an unknown mode, tunnels that clash with
+// another one, and profiles listing tunnels that do not exist.
+func ValidateConfig(config *store.AppConfig) []ConfigIssue {
+	var issues []ConfigIssue
+	report := func(tunnel, format string, args ...any) {
+		issues = append(issues, ConfigIssue{Tunnel: tunnel, Message: fmt.Sprintf(format, args...)})
+	}
+
+	ids := make(map[string]string)
+	names := make(map[string]bool)
+	var parsed []*Tunnel
+	for _, tc := range config.Tunnels {
+		tunnel, err := parseTunnelConfig(tc)
+		if err != nil {
+			report(tc.Name, "%v", err)
+		}
+
+		switch other, exists := ids[tc.ID]; {
+		case tc.ID == "":
+			report(tc.Name, "no ID")
+		case exists:
+			report(tc.Name, "ID %q is also used by '%s'", tc.ID, other)
+		default:
+			ids[tc.ID] = tc.Name
+		}
+
+		key := profileName(tunnel) + "\x00" + tc.Name
+		if names[key] {
+			report(tc.Name, "another tunnel in profile %s has the same name", profileName(tunnel))
+		}
+		names[key] = true
+
+		if err := tunnel.Validate(); err != nil {
+			report(tc.Name, "%v", err)
+			continue
+		}
+		parsed = append(parsed, tunnel)
+	}
+
+	for i, tunnel := range parsed {
+		for _, other := range parsed[:i] {
+			if clash := tunnelClash(tunnel, other); clash != "" {
+				report(tunnel.Name, "%s", clash)
+			}
+		}
+	}
+
+	declared := make(map[string]bool)
+	for _, p := range config.Profiles {
+		if declared[p.Name] {
+			report("", "profile %s is declared more than once", p.Name)
+		}
+		declared[p.Name] = true
+		for _, id := range p.TunnelIDs {
+			if _, exists := ids[id]; !exists {
+				report("", "profile %s lists tunnel ID %q, which does not exist", p.Name, id)
+			}
+		}
+	}
+
+	return issues
+}
+
+// ClashesWith returns the problems a tunnel would have running alongside the
+// configured ones, such as an edited tunnel before it is saved. The tunnel
+// itself is skipped by its ID.
+func (tm *TunnelManager) ClashesWith(tunnel *Tunnel) []ConfigIssue {
+	var issues []ConfigIssue
+	for _, other := range tm.GetTunnels() {
+		if other.ID == tunnel.ID {
+			continue
+		}
+		if clash := tunnelClash(tunnel, other); clash != "" {
+			issues = append(issues, ConfigIssue{Tunnel: tunnel.Name, Message: clash})
+		}
+	}
+	return issues
+}
+
+// tunnelClash describes why starting one of two tunnels would fail while the
+// other runs, or returns an empty string. They are duplicates with the same
+// SSH host and forwards, or listen on the same local port or on the same port
+// of the SSH host. Tunnels only clash within a profile or when both
+// auto-connect, since tunnels of different profiles usually do not run
+// together.
+func tunnelClash(tunnel, other *Tunnel) string {
+	if profileName(tunnel) != profileName(other) && !(tunnel.AutoConnect && other.AutoConnect) {
+		return ""
+	}
+	if sameForwards(tunnel, other) {
+		return fmt.Sprintf("has the same SSH host and forwards as '%s'", other.Name)
+	}
+	if port := sharedListenPort(tunnel, other); port > 0 {
+		return fmt.Sprintf("local port %d is also used by '%s'", port, other.Name)
+	}
+	if port := sharedRemotePort(tunnel, other); port > 0 {
+		return fmt.Sprintf("remote port %d on %s is also used by '%s'", port, tunnel.SSHHost, other.Name)
+	}
+	return ""
+}
+
+// sameForwards reports whether both tunnels define the same forwards through
+// the same SSH host, in any order
+func sameForwards(a, b *Tunnel) bool {
+	ours, theirs := a.AllForwards(), b.AllForwards()
+	if a.SSHHost != b.SSHHost || len(ours) != len(theirs) {
+		return false
+	}
+	counts := make(map[Forward]int)
+	for _, f := range ours {
+		counts[f]++
+	}
+	for _, f := range theirs {
+		if counts[f] == 0 {
+			return false
+		}
+		counts[f]--
+	}
+	return true
+}
+
+// sharedRemotePort returns a port both tunnels ask the same SSH host to listen
+// on, or 0. Port 0 lets the server pick one, which never collides.
+func sharedRemotePort(a, b *Tunnel) int {
+	if a.SSHHost != b.SSHHost {
+		return 0
+	}
+	for _, ours := range a.AllForwards() {
+		if !ours.IsRemote() || ours.RemotePort == 0 {
+			continue
+		}
+		for _, theirs := range b.AllForwards() {
+			if theirs.IsRemote() && theirs.RemotePort == ours.RemotePort && bindHostsOverlap(theirs.RemoteBindAddress, ours.RemoteBindAddress) {
+				return ours.RemotePort
+			}
+		}
+	}
+	return 0
+}
+
+// sharedListenPort returns a local port both tunnels listen on, or 0
+func sharedListenPort(a, b *Tunnel) int {
+	for _, ours := range a.AllForwards() {
+		if ours.IsRemote() {
+			continue
+		}
+		for _, theirs := range b.AllForwards() {
+			if !theirs.IsRemote() && theirs.LocalPort == ours.LocalPort && bindHostsOverlap(theirs.LocalHost, ours.LocalHost) {
+				return ours.LocalPort
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+// Package core provides config validation tests.
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestValidateConfig tests that each kind of problem is reported once
+func TestValidateConfig(t *testing.T) {
+	config := &store.AppConfig{
+		Version: store.ConfigVersion,
+		Tunnels: []store.TunnelConfig{
+			{ID: "a", Name: "db", Host: "db.example.com", LocalPort: 5432, RemotePort: 5432, Mode: "local", Profile: "prod"},
+			{ID: "a", Name: "cache", Host: "cache.example.com", LocalPort: 6379, RemotePort: 6379, Mode: "local", Profile: "prod"},
+			{ID: "c", Name: "db", Host: "db2.example.com", LocalPort: 5433, RemotePort: 5432, Mode: "local", Profile: "prod"},
+			{ID: "d", Name: "web", Host: "web.example.com", LocalPort: 5432, RemotePort: 80, Mode: "local", Profile: "prod"},
+			{ID: "e", Name: "staging-db", Host: "db.example.com", LocalPort: 5432, RemotePort: 5432, Mode: "local", Profile: "staging"},
+			{ID: "f", Name: "odd", Host: "odd.example.com", LocalPort: 1080, Mode: "sideways"},
+		},
+		Profiles: []store.Profile{
+			{Name: "prod", TunnelIDs: []string{"a", "gone"}},
+			{Name: "prod"},
+		},
+	}
+
+	var got []string
+	for _, issue := range ValidateConfig(config) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		`tunnel 'cache': ID "a" is also used by 'db'`,
+		"tunnel 'db': another tunnel in profile prod has the same name",
+		"tunnel 'odd': invalid tunnel type: sideways",
+		"tunnel 'web': local port 5432 is also used by 'db'",
+		`profile prod lists tunnel ID "gone", which does not exist`,
+		"profile prod is declared more than once",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The manager keeps the issues of the config it loaded
+	tm, _ := newTestManager(t, `{"version": "2.0", "tunnels": [
+		{"id": "x", "name": "one", "host": "h", "localPort": 8080, "remotePort": 80, "mode": "local"},
+		{"id": "y", "name": "two", "host": "h", "localPort": 8080, "remotePort": 81, "mode": "local"}
+	]}`)
+	if issues := tm.ConfigIssues(); len(issues) != 1 {
+		t.Errorf("Expected the port collision to be reported, got %v", issues)
+	}
+	if err := tm.DeleteTunnel("y"); err != nil {
+		t.Fatalf("DeleteTunnel failed: %v", err)
+	}
+	if issues := tm.ConfigIssues(); len(issues) != 0 {
+		t.Errorf("Expected no issues once the collision is removed, got %v", issues)
+	}
+}
+
+// TestValidateConfigClashes tests that duplicate tunnels and ones listening on
+// the same port of the SSH host are reported within a profile only
+func TestValidateConfigClashes(t *testing.T) {
+	config := &store.AppConfig{
+		Version: store.ConfigVersion,
+		Tunnels: []store.TunnelConfig{
+			{ID: "a", Name: "db", Host: "bastion", LocalPort: 5432, RemoteHost: "db", RemotePort: 5432, Mode: "local"},
+			{ID: "b", Name: "db-copy", Host: "bastion", LocalPort: 5432, RemoteHost: "db", RemotePort: 5432, Mode: "local"},
+			{ID: "c", Name: "share", Host: "bastion", LocalPort: 3000, RemotePort: 9000, Mode: "remote"},
+			{ID: "d", Name: "share-api", Host: "bastion", LocalPort: 4000, RemotePort: 9000, Mode: "remote"},
+			{ID: "e", Name: "share-elsewhere", Host: "gateway", LocalPort: 4000, RemotePort: 9000, Mode: "remote"},
+			{ID: "f", Name: "share-staging", Host: "bastion", LocalPort: 4000, RemotePort: 9000, Mode: "remote", Profile: "staging"},
+		},
+	}
+
+	var got []string
+	for _, issue := range ValidateConfig(config) {
+		got = append(got, issue.String())
+	}
+	want := []string{
+		"tunnel 'db-copy': has the same SSH host and forwards as 'db'",
+		"tunnel 'share-api': remote port 9000 on bastion is also used by 'share'",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// An edited tunnel is checked against the others but not itself
+	tm, _ := newTestManager(t, `{"version": "2.0", "tunnels": [
+		{"id": "x", "name": "one", "host": "h", "localPort": 8080, "remotePort": 80, "mode": "local"}
+	]}`)
+	edited := &Tunnel{ID: "x", Name: "one", Type: LocalForward, SSHHost: "h", LocalPort: 8080, RemotePort: 80}
+	if issues := tm.ClashesWith(edited); len(issues) != 0 {
+		t.Errorf("Expected no clashes with itself, got %v", issues)
+	}
+	edited.ID = "y"
+	if issues := tm.ClashesWith(edited); len(issues) != 1 {
+		t.Errorf("Expected a duplicate, got %v", issues)
+	}
+}
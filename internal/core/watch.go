@@ -0,0 +1,169 @@
+// Package core provides reloading of the config when other programs change it.
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// ConfigWatcher reloads the config when another program changes it, such as
+// an editor or a dotfile sync. It watches the directories of the config and
+// its includes with fsnotify, which also sees editors and syncs that replace
+// files instead of writing to them, and reloads once events have stopped
+// for a moment so half-written files are skipped. Where file events are
+// unavailable it polls the files instead.
+type ConfigWatcher struct {
+	manager *TunnelManager
+
+	interval time.Duration
+	settle   time.Duration
+	onReload func(err error)
+
+	// pending is a changed stamp waiting to settle while polling
+	pending store.FileStamp
+}
+
+// ConfigWatcherOption is a functional option for ConfigWatcher
+type ConfigWatcherOption func(*ConfigWatcher)
+
+// WithConfigPollInterval sets how often the config file is checked when it
+// is polled
+func WithConfigPollInterval(d time.Duration) ConfigWatcherOption {
+	return func(w *ConfigWatcher) {
+		w.interval = d
+	}
+}
+
+// WithConfigReloadFunc sets a function called after each reload with its error
+func WithConfigReloadFunc(fn func(err error)) ConfigWatcherOption {
+	return func(w *ConfigWatcher) {
+		w.onReload = fn
+	}
+}
+
+// NewConfigWatcher creates a watcher of the manager's config file
+func NewConfigWatcher(manager *TunnelManager, opts ...ConfigWatcherOption) *ConfigWatcher {
+	w := &ConfigWatcher{
+		manager:  manager,
+		interval: time.Second,
+		settle:   250 * time.Millisecond,
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Run reloads the config on changes until ctx is cancelled
+func (w *ConfigWatcher) Run(ctx context.Context) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		Debug("Polling the config for changes: %v", err)
+		w.poll(ctx)
+		return
+	}
+	defer watcher.Close()
+	w.watchDirs(watcher)
+
+	settled := time.NewTimer(w.settle)
+	settled.Stop()
+	defer settled.Stop()
+
+	for {
+		select {
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			settled.Reset(w.settle)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			Debug("Config watcher: %v", err)
+		case <-settled.C:
+			w.reloadIfChanged()
+			// The config may include other directories now
+			w.watchDirs(watcher)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchDirs adds the directories of the config and its includes to watcher
+func (w *ConfigWatcher) watchDirs(watcher *fsnotify.Watcher) {
+	for _, dir := range w.manager.configStore.WatchDirs() {
+		if err := watcher.Add(dir); err != nil {
+			Debug("Not watching %s for config changes: %v", dir, err)
+		}
+	}
+}
+
+// poll checks the config every interval until ctx is cancelled
+func (w *ConfigWatcher) poll(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.Check()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Check reloads the config if it changed and has settled since the last
+// check, reporting whether it did; it is how the config is polled
+func (w *ConfigWatcher) Check() bool {
+	stamp := w.manager.configStore.Stamp()
+	if !w.changed(stamp) {
+		w.pending = store.FileStamp{}
+		return false
+	}
+	if stamp != w.pending {
+		w.pending = stamp
+		return false
+	}
+
+	w.pending = store.FileStamp{}
+	w.reload()
+	return true
+}
+
+// changed reports whether a stamp differs from the config as last loaded or
+// saved by the manager, so its own saves are not reloaded
+func (w *ConfigWatcher) changed(stamp store.FileStamp) bool {
+	w.manager.mu.RLock()
+	defer w.manager.mu.RUnlock()
+	return stamp != w.manager.configStamp
+}
+
+// reloadIfChanged reloads the config if it changed since last loaded or saved
+func (w *ConfigWatcher) reloadIfChanged() {
+	if w.changed(w.manager.configStore.Stamp()) {
+		w.reload()
+	}
+}
+
+// reload reloads the config. Tunnels keep running through a reload and use
+// their new settings when next started.
+func (w *ConfigWatcher) reload() {
+	err := w.manager.ReloadConfig()
+	if err != nil {
+		Warn("Failed to reload changed config: %v", err)
+	} else {
+		Info("Reloaded config after it was changed")
+	}
+	if w.onReload != nil {
+		w.onReload(err)
+	}
+}
@@ -0,0 +1,89 @@
+// Package core provides config watcher tests.
+package core
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestConfigWatcher tests that external changes are reloaded once settled and
+// that the manager's own saves are not
+func TestConfigWatcher(t *testing.T) {
+	tm, configStore := newTestManager(t, `{"version": "2.0", "tunnels": [
+		{"id": "web", "name": "Web", "host": "web.example.com", "localPort": 8080, "remotePort": 80, "mode": "local"}
+	]}`)
+	watcher := NewConfigWatcher(tm)
+
+	tunnel := NewTunnel("db", LocalForward)
+	tunnel.SSHHost = "db.example.com"
+	tunnel.LocalPort = 15432
+	tunnel.RemotePort = 5432
+	if err := tm.AddTunnel(tunnel); err != nil {
+		t.Fatalf("AddTunnel failed: %v", err)
+	}
+	if watcher.Check() || watcher.Check() {
+		t.Error("Expected the manager's own save not to be reloaded")
+	}
+
+	configPath, _ := configStore.GetConfigPath()
+	edited := `{"version": "2.0", "tunnels": [
+		{"id": "web", "name": "Web", "host": "web2.example.com", "localPort": 8080, "remotePort": 80, "mode": "local"}
+	]}`
+	if err := os.WriteFile(configPath, []byte(edited), 0644); err != nil {
+		t.Fatalf("Failed to edit config: %v", err)
+	}
+	if watcher.Check() {
+		t.Error("Expected a change to settle before being reloaded")
+	}
+	if !watcher.Check() {
+		t.Fatal("Expected the changed config to be reloaded")
+	}
+
+	if _, err := tm.GetTunnel(tunnel.ID); err == nil {
+		t.Error("Expected the tunnel removed from the file to be dropped")
+	}
+	web, err := tm.GetTunnel("web")
+	if err != nil || web.SSHHost != "web2.example.com" {
+		t.Errorf("Expected the edited host, got %v", err)
+	}
+	if watcher.Check() {
+		t.Error("Expected no reload without a further change")
+	}
+}
+
+// TestConfigWatcherRun tests that the watcher reloads a config replaced by
+// another program, as editors and dotfile syncs do
+func TestConfigWatcherRun(t *testing.T) {
+	tm, configStore := newTestManager(t, `{"version": "2.0", "tunnels": []}`)
+
+	reloaded := make(chan error, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go NewConfigWatcher(tm, WithConfigReloadFunc(func(err error) { reloaded <- err })).Run(ctx)
+	time.Sleep(100 * time.Millisecond)
+
+	configPath, _ := configStore.GetConfigPath()
+	edited := `{"version": "2.0", "tunnels": [
+		{"id": "web", "name": "Web", "host": "web.example.com", "localPort": 8080, "remotePort": 80, "mode": "local"}
+	]}`
+	if err := os.WriteFile(configPath+".new", []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(configPath+".new", configPath); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-reloaded:
+		if err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the replaced config to be reloaded")
+	}
+	if _, err := tm.GetTunnel("web"); err != nil {
+		t.Errorf("Expected the new tunnel to be loaded: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package core provides webhook notifications of tunnel status transitions.
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"text/template"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// webhookTimeout is how long a webhook request may take
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload describes a tunnel status transition to a webhook. It is the
+// request body unless the webhook has a template, which receives it as data.
+type WebhookPayload struct {
+	Event      HookEvent   `json:"event"`
+	Time       time.Time   `json:"time"`
+	TunnelID   string      `json:"tunnel_id"`
+	TunnelName string      `json:"tunnel_name"`
+	Profile    string      `json:"profile,omitempty"`
+	SSHHost    string      `json:"ssh_host"`
+	Forward    string      `json:"forward"`
+	Error      string      `json:"error,omitempty"`
+	ErrorKind  FailureKind `json:"error_kind,omitempty"`
+	Hint       string      `json:"hint,omitempty"`
+}
+
+// webhookFuncs are available to webhook templates; json quotes a value for
+// embedding in a JSON body
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// newWebhookPayload describes a tunnel transition. The caller must hold tm.mu.
+func newWebhookPayload(t *Tunnel, event HookEvent, cause error) WebhookPayload {
+	payload := WebhookPayload{
+		Event:      event,
+		Time:       time.Now(),
+		TunnelID:   t.ID,
+		TunnelName: t.Name,
+		Profile:    t.Profile,
+		SSHHost:    t.SSHHost,
+		Forward:    FormatForwards(t.forwardsLocked()),
+	}
+	if cause != nil {
+		payload.Error = cause.Error()
+		payload.ErrorKind = FailureKindOf(cause)
+		payload.Hint = FailureHint(cause)
+	}
+	return payload
+}
+
+// webhookBody renders the request body of a webhook
+func webhookBody(webhook store.WebhookConfig, payload WebhookPayload) ([]byte, error) {
+	if webhook.Template == "" {
+		return json.Marshal(payload)
+	}
+
+	tmpl, err := template.New("webhook").Funcs(webhookFuncs).Parse(webhook.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, payload); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return body.Bytes(), nil
+}
+
+// sendWebhooks posts event to the configured webhooks in the background. The
+// caller must hold tm.mu.
+func (tm *TunnelManager) sendWebhooks(tunnel *Tunnel, event HookEvent, cause error) {
+	if tm.defaults == nil {
+		return
+	}
+
+	var webhooks []store.WebhookConfig
+	for _, webhook := range tm.defaults.Webhooks {
+		if len(webhook.Events) == 0 || slices.Contains(webhook.Events, string(event)) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload := newWebhookPayload(tunnel, event, cause)
+	for _, webhook := range webhooks {
+		tm.hooks.Add(1)
+		go func() {
+			defer tm.hooks.Done()
+			if err := postWebhook(webhook, payload); err != nil {
+				Warn("Tunnel '%s': webhook %s failed: %v", payload.TunnelName, webhook.URL, err)
+			}
+		}()
+	}
+}
+
+// postWebhook sends a payload to a webhook
+func postWebhook(webhook store.WebhookConfig, payload WebhookPayload) error {
+	body, err := webhookBody(webhook, payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tunnelman")
+	// Header values may reference environment variables, keeping tokens out of the config
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, os.ExpandEnv(value))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package core provides webhook tests.
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// TestSendWebhooks tests that webhooks receive matching events as JSON or templated bodies
+func TestSendWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string][]string)
+	headers := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests[r.URL.Path] = append(requests[r.URL.Path], string(body))
+		headers[r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_WEBHOOK_TOKEN", "secret")
+	tm, _ := newTestManager(t, "")
+	tm.defaults = &store.Defaults{Webhooks: []store.WebhookConfig{
+		{URL: server.URL + "/all"},
+		{
+			URL:      server.URL + "/slack",
+			Events:   []string{"failure"},
+			Headers:  map[string]string{"Authorization": "Bearer ${TEST_WEBHOOK_TOKEN}"},
+			Template: `{"text": {{json (printf "%s failed: %s" .TunnelName .Error)}}}`,
+		},
+	}}
+
+	tunnel := &Tunnel{ID: "db", Name: "db", Type: DynamicForward, LocalPort: 1080, SSHHost: "bastion", Profile: "work"}
+	tm.mu.Lock()
+	tm.runHooks(tunnel, HookConnect, nil)
+	tm.runHooks(tunnel, HookFailure, &SSHError{Kind: FailureAuth, ExitCode: 255, Message: `Permission denied "publickey"`})
+	tm.mu.Unlock()
+	waitForTestHooks(t, tm)
+
+	if len(requests["/all"]) != 2 {
+		t.Fatalf("Expected 2 requests to the catch-all webhook, got %d", len(requests["/all"]))
+	}
+	var payload WebhookPayload
+	for _, body := range requests["/all"] {
+		if err := json.Unmarshal([]byte(body), &payload); err != nil {
+			t.Fatalf("Invalid payload %q: %v", body, err)
+		}
+		if payload.Event == HookFailure {
+			break
+		}
+	}
+	if payload.TunnelName != "db" || payload.Profile != "work" || payload.ErrorKind != FailureAuth || payload.Hint == "" {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+
+	if len(requests["/slack"]) != 1 {
+		t.Fatalf("Expected only the failure to be sent to the filtered webhook, got %v", requests["/slack"])
+	}
+	var slack struct{ Text string }
+	if err := json.Unmarshal([]byte(requests["/slack"][0]), &slack); err != nil {
+		t.Fatalf("Template rendered invalid JSON %q: %v", requests["/slack"][0], err)
+	}
+	if want := `db failed: authentication failed: Permission denied "publickey"`; slack.Text != want {
+		t.Errorf("Expected text %q, got %q", want, slack.Text)
+	}
+	if headers["/slack"] != "Bearer secret" {
+		t.Errorf("Expected the header to expand the token, got %q", headers["/slack"])
+	}
+}
+
+// TestWebhookBodyRejectsBadTemplate tests that template errors are reported
+func TestWebhookBodyRejectsBadTemplate(t *testing.T) {
+	if _, err := webhookBody(store.WebhookConfig{Template: "{{.Nope"}, WebhookPayload{}); err == nil {
+		t.Error("Expected an invalid template to fail")
+	}
+}
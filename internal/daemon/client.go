@@ -0,0 +1,179 @@
+// Package daemon provides the control socket client.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// ErrNotRunning is returned when no daemon is listening on the control socket
+var ErrNotRunning = errors.New("daemon is not running")
+
+// Client sends control requests to a running daemon
+type Client struct {
+	socketPath string
+	timeout    time.Duration
+}
+
+// Dial connects to the daemon at socketPath, returning ErrNotRunning if it is unreachable
+func Dial(socketPath string) (*Client, error) {
+	c := &Client{
+		socketPath: socketPath,
+		timeout:    30 * time.Second,
+	}
+	if _, err := c.call(Request{Method: MethodPing}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// List returns snapshots of all tunnels, or only those in profile if non-empty
+func (c *Client) List(profile string) ([]core.TunnelSnapshot, error) {
+	resp, err := c.call(Request{Method: MethodList, Profile: profile})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Tunnels, nil
+}
+
+// Find returns a snapshot of the tunnel with the given name or ID
+func (c *Client) Find(nameOrID string) (core.TunnelSnapshot, error) {
+	return c.single(Request{Method: MethodStatus, Tunnel: nameOrID})
+}
+
+// Start starts a tunnel by name or ID
+func (c *Client) Start(nameOrID string) (core.TunnelSnapshot, error) {
+	return c.single(Request{Method: MethodStart, Tunnel: nameOrID})
+}
+
+// Stop stops a tunnel by name or ID
+func (c *Client) Stop(nameOrID string) (core.TunnelSnapshot, error) {
+	return c.single(Request{Method: MethodStop, Tunnel: nameOrID})
+}
+
+// Restart restarts a tunnel by name or ID
+func (c *Client) Restart(nameOrID string) (core.TunnelSnapshot, error) {
+	return c.single(Request{Method: MethodRestart, Tunnel: nameOrID})
+}
+
+// StartProfile starts all tunnels in a profile
+func (c *Client) StartProfile(profile string) ([]core.TunnelSnapshot, error) {
+	resp, err := c.call(Request{Method: MethodStartProfile, Profile: profile})
+	if resp != nil {
+		return resp.Tunnels, err
+	}
+	return nil, err
+}
+
+// StopProfile stops all tunnels in a profile
+func (c *Client) StopProfile(profile string) ([]core.TunnelSnapshot, error) {
+	resp, err := c.call(Request{Method: MethodStopProfile, Profile: profile})
+	if resp != nil {
+		return resp.Tunnels, err
+	}
+	return nil, err
+}
+
+// Watch streams status events to fn until ctx is cancelled or the daemon goes away
+func (c *Client) Watch(ctx context.Context, fn func(core.StatusEvent)) error {
+	conn, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	if err := json.NewEncoder(conn).Encode(Request{Method: MethodWatch}); err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+
+	decoder := json.NewDecoder(conn)
+	for {
+		var resp Response
+		if err := decoder.Decode(&resp); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("lost connection to daemon: %w", err)
+		}
+		if !resp.OK {
+			return errors.New(resp.Error)
+		}
+		if resp.Event != nil {
+			fn(*resp.Event)
+		}
+	}
+}
+
+// single performs a request expected to return exactly one tunnel
+func (c *Client) single(req Request) (core.TunnelSnapshot, error) {
+	resp, err := c.call(req)
+	if err != nil {
+		return core.TunnelSnapshot{}, err
+	}
+	if len(resp.Tunnels) != 1 {
+		return core.TunnelSnapshot{}, fmt.Errorf("unexpected response from daemon")
+	}
+	return resp.Tunnels[0], nil
+}
+
+// call performs a single request/response exchange. Failed requests return
+// both the response (if any) and an error carrying the daemon's message.
+func (c *Client) call(req Request) (*Response, error) {
+	conn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(c.timeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if !resp.OK {
+		return &resp, &remoteError{message: resp.Error, kind: core.ErrorForCode(resp.Code)}
+	}
+	return &resp, nil
+}
+
+// remoteError is an error reported by the daemon. It matches the manager's
+// error value of the same kind with errors.Is.
+type remoteError struct {
+	message string
+	kind    error
+}
+
+// Error implements the error interface
+func (e *remoteError) Error() string {
+	return e.message
+}
+
+// Unwrap returns the manager's error value for the kind of error, if any
+func (e *remoteError) Unwrap() error {
+	return e.kind
+}
+
+// dial opens a connection to the control socket
+func (c *Client) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", c.socketPath, time.Second)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	return conn, nil
+}
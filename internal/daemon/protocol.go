@@ -0,0 +1,39 @@
+// Package daemon provides the control socket server that owns tunnel processes
+// and the client used by the CLI to talk to it.
+package daemon
+
+import (
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// Control methods understood by the daemon
+const (
+	MethodPing         = "ping"
+	MethodList         = "list"
+	MethodStatus       = "status"
+	MethodStart        = "start"
+	MethodStop         = "stop"
+	MethodRestart      = "restart"
+	MethodStartProfile = "start-profile"
+	MethodStopProfile  = "stop-profile"
+	MethodWatch        = "watch"
+)
+
+// Request is a single control request sent over the socket as one JSON object
+type Request struct {
+	Method  string `json:"method"`
+	Tunnel  string `json:"tunnel,omitempty"`
+	Profile string `json:"profile,omitempty"`
+}
+
+// Response is the reply to a Request. Watch requests receive a stream of
+// responses, each carrying one event.
+type Response struct {
+	OK      bool                  `json:"ok"`
+	Error   string                `json:"error,omitempty"`
+	Tunnels []core.TunnelSnapshot `json:"tunnels,omitempty"`
+	Event   *core.StatusEvent     `json:"event,omitempty"`
+
+	// Code names the kind of error, as returned by core.ErrorCode
+	Code string `json:"code,omitempty"`
+}
@@ -0,0 +1,230 @@
+// Package daemon provides the control socket server.
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// Server owns a TunnelManager and serves control requests of the CLI and the
+// TUI over a Unix domain socket, which Windows 10 and later support as well
+type Server struct {
+	manager *core.TunnelManager
+	events  *core.StatusBroadcaster
+}
+
+// NewServer creates a new control server for the given tunnel manager
+func NewServer(manager *core.TunnelManager) *Server {
+	return &Server{
+		manager: manager,
+		events:  core.NewStatusBroadcaster(manager),
+	}
+}
+
+// Serve listens on socketPath and handles requests until ctx is cancelled
+func (s *Server) Serve(ctx context.Context, socketPath string) error {
+	if err := prepareSocket(socketPath); err != nil {
+		return err
+	}
+
+	listener, err := core.ListenPrivate(socketPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socketPath)
+
+	go s.events.Run(ctx)
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	core.Info("Daemon listening on %s", socketPath)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			core.Error("Failed to accept connection: %v", err)
+			continue
+		}
+		go s.handleConn(ctx, conn)
+	}
+}
+
+// prepareSocket removes a stale socket file, refusing if another daemon is still listening
+func prepareSocket(socketPath string) error {
+	if _, err := os.Stat(socketPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	if conn, err := net.DialTimeout("unix", socketPath, time.Second); err == nil {
+		conn.Close()
+		return fmt.Errorf("daemon already running on %s", socketPath)
+	}
+
+	if err := os.Remove(socketPath); err != nil {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
+
+// handleConn serves a single request on a connection
+func (s *Server) handleConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		writeResponse(conn, errorResponse(fmt.Errorf("invalid request: %w", err)))
+		return
+	}
+
+	if req.Method == MethodWatch {
+		s.watch(ctx, conn)
+		return
+	}
+
+	writeResponse(conn, s.handle(req))
+}
+
+// handle executes a non-streaming request
+func (s *Server) handle(req Request) Response {
+	// Pick up tunnels added or edited by other processes
+	if err := s.manager.ReloadConfig(); err != nil {
+		core.Warn("Failed to reload config: %v", err)
+	}
+
+	switch req.Method {
+	case MethodPing:
+		return Response{OK: true}
+
+	case MethodList:
+		var tunnels []*core.Tunnel
+		if req.Profile != "" {
+			tunnels = s.manager.GetTunnelsByProfile(req.Profile)
+		} else {
+			tunnels = s.manager.GetTunnels()
+		}
+		return snapshotResponse(tunnels...)
+
+	case MethodStatus:
+		tunnel, err := s.manager.FindTunnel(req.Tunnel)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return snapshotResponse(tunnel)
+
+	case MethodStart, MethodStop, MethodRestart:
+		tunnel, err := s.manager.FindTunnel(req.Tunnel)
+		if err != nil {
+			return errorResponse(err)
+		}
+		switch req.Method {
+		case MethodStart:
+			err = s.manager.StartTunnel(tunnel.ID)
+		case MethodStop:
+			err = s.manager.StopTunnel(tunnel.ID)
+		case MethodRestart:
+			err = s.manager.RestartTunnel(tunnel.ID)
+		}
+		if err != nil {
+			return errorResponse(err)
+		}
+		tunnel, err = s.manager.GetTunnel(tunnel.ID)
+		if err != nil {
+			return errorResponse(err)
+		}
+		return snapshotResponse(tunnel)
+
+	case MethodStartProfile:
+		err := s.manager.StartProfileTunnels(req.Profile)
+		resp := snapshotResponse(s.manager.GetTunnelsByProfile(req.Profile)...)
+		if err != nil {
+			resp.OK = false
+			resp.Error = err.Error()
+			resp.Code = core.ErrorCode(err)
+		}
+		return resp
+
+	case MethodStopProfile:
+		err := s.manager.StopProfileTunnels(req.Profile)
+		resp := snapshotResponse(s.manager.GetTunnelsByProfile(req.Profile)...)
+		if err != nil {
+			resp.OK = false
+			resp.Error = err.Error()
+			resp.Code = core.ErrorCode(err)
+		}
+		return resp
+
+	default:
+		return errorResponse(fmt.Errorf("unknown method: %s", req.Method))
+	}
+}
+
+// watch streams status events to the connection until it closes
+func (s *Server) watch(ctx context.Context, conn net.Conn) {
+	events, stopWatching := s.events.Watch()
+	defer stopWatching()
+
+	// Detect client disconnects, which otherwise go unnoticed until the next write
+	closed := make(chan struct{})
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := conn.Read(buf); err != nil {
+				close(closed)
+				return
+			}
+		}
+	}()
+
+	if !writeResponse(conn, Response{OK: true}) {
+		return
+	}
+
+	for {
+		select {
+		case event := <-events:
+			if !writeResponse(conn, Response{OK: true, Event: &event}) {
+				return
+			}
+		case <-closed:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// writeResponse encodes a response, reporting whether the write succeeded
+func writeResponse(conn net.Conn, resp Response) bool {
+	conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+	return json.NewEncoder(conn).Encode(resp) == nil
+}
+
+// snapshotResponse builds a successful response carrying tunnel snapshots
+func snapshotResponse(tunnels ...*core.Tunnel) Response {
+	resp := Response{OK: true, Tunnels: make([]core.TunnelSnapshot, 0, len(tunnels))}
+	for _, t := range tunnels {
+		resp.Tunnels = append(resp.Tunnels, t.Snapshot())
+	}
+	return resp
+}
+
+// errorResponse builds a failed response
+func errorResponse(err error) Response {
+	return Response{OK: false, Error: err.Error(), Code: core.ErrorCode(err)}
+}
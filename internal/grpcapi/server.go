@@ -0,0 +1,250 @@
+// Package grpcapi provides the gRPC control API served by `tunnelman grpc`.
+package grpcapi
+
+//go:generate goprotoc -I ../../proto --go_out=module=github.com/takaaki-s/tunnelman:../.. --go-grpc_out=module=github.com/takaaki-s/tunnelman:../.. ../../proto/tunnelman/v1/tunnelman.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+	pb "github.com/takaaki-s/tunnelman/internal/grpcapi/tunnelmanpb"
+)
+
+// Server implements the TunnelService gRPC API on top of a TunnelManager
+type Server struct {
+	pb.UnimplementedTunnelServiceServer
+
+	manager *core.TunnelManager
+	events  *core.StatusBroadcaster
+
+	// Bearer token every call must present; empty refuses all calls
+	token string
+}
+
+// ServerOption is a functional option for Server
+type ServerOption func(*Server)
+
+// WithToken sets the bearer token that calls must present in their
+// authorization metadata
+func WithToken(token string) ServerOption {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// NewServer creates a new gRPC API server
+func NewServer(manager *core.TunnelManager, opts ...ServerOption) *Server {
+	s := &Server{
+		manager: manager,
+		events:  core.NewStatusBroadcaster(manager),
+	}
+
+	// Apply options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// ListenAndServe serves the API on addr until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	grpcServer := grpc.NewServer(
+		grpc.UnaryInterceptor(s.authorizeUnary),
+		grpc.StreamInterceptor(s.authorizeStream),
+	)
+	pb.RegisterTunnelServiceServer(grpcServer, s)
+
+	go s.events.Run(ctx)
+
+	go func() {
+		<-ctx.Done()
+		// Streams never finish on their own, so don't wait for them
+		grpcServer.Stop()
+	}()
+
+	core.Info("gRPC API listening on %s", listener.Addr())
+
+	if err := grpcServer.Serve(listener); err != nil {
+		return fmt.Errorf("server failed: %w", err)
+	}
+	return nil
+}
+
+// authorize checks that a call presents the server's bearer token as
+// "authorization: Bearer TOKEN" metadata
+func (s *Server) authorize(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		token, ok := strings.CutPrefix(value, "Bearer ")
+		if s.token != "" && ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or wrong API token")
+}
+
+// authorizeUnary runs unary calls that present the bearer token
+func (s *Server) authorizeUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// authorizeStream runs streaming calls that present the bearer token
+func (s *Server) authorizeStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.authorize(stream.Context()); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+// ListTunnels returns all tunnels, optionally filtered by profile
+func (s *Server) ListTunnels(ctx context.Context, req *pb.ListTunnelsRequest) (*pb.ListTunnelsResponse, error) {
+	s.reload()
+
+	var tunnels []*core.Tunnel
+	if req.GetProfile() != "" {
+		tunnels = s.manager.GetTunnelsByProfile(req.GetProfile())
+	} else {
+		tunnels = s.manager.GetTunnels()
+	}
+
+	resp := &pb.ListTunnelsResponse{Tunnels: make([]*pb.Tunnel, 0, len(tunnels))}
+	for _, t := range tunnels {
+		resp.Tunnels = append(resp.Tunnels, toProto(t.Snapshot()))
+	}
+	return resp, nil
+}
+
+// GetTunnel returns a single tunnel by name or ID
+func (s *Server) GetTunnel(ctx context.Context, req *pb.TunnelRequest) (*pb.Tunnel, error) {
+	s.reload()
+
+	tunnel, err := s.manager.FindTunnel(req.GetTunnel())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return toProto(tunnel.Snapshot()), nil
+}
+
+// StartTunnel starts a tunnel by name or ID
+func (s *Server) StartTunnel(ctx context.Context, req *pb.TunnelRequest) (*pb.Tunnel, error) {
+	return s.lifecycle(req.GetTunnel(), s.manager.StartTunnel)
+}
+
+// StopTunnel stops a tunnel by name or ID
+func (s *Server) StopTunnel(ctx context.Context, req *pb.TunnelRequest) (*pb.Tunnel, error) {
+	return s.lifecycle(req.GetTunnel(), s.manager.StopTunnel)
+}
+
+// RestartTunnel restarts a tunnel by name or ID
+func (s *Server) RestartTunnel(ctx context.Context, req *pb.TunnelRequest) (*pb.Tunnel, error) {
+	return s.lifecycle(req.GetTunnel(), s.manager.RestartTunnel)
+}
+
+// StreamStatus pushes status changes to the client until it cancels
+func (s *Server) StreamStatus(req *pb.StreamStatusRequest, stream grpc.ServerStreamingServer[pb.StatusChange]) error {
+	filter := make(map[string]bool, len(req.GetTunnelIds()))
+	for _, id := range req.GetTunnelIds() {
+		filter[id] = true
+	}
+
+	events, stopWatching := s.events.Watch()
+	defer stopWatching()
+
+	for {
+		select {
+		case event := <-events:
+			if len(filter) > 0 && !filter[event.TunnelID] {
+				continue
+			}
+			if err := stream.Send(&pb.StatusChange{
+				Time:       timestamppb.New(event.Time),
+				TunnelId:   event.TunnelID,
+				TunnelName: event.TunnelName,
+				OldStatus:  string(event.OldStatus),
+				NewStatus:  string(event.NewStatus),
+				Error:      event.Error,
+			}); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+// lifecycle resolves a tunnel and applies a start/stop/restart action to it
+func (s *Server) lifecycle(nameOrID string, fn func(id string) error) (*pb.Tunnel, error) {
+	s.reload()
+
+	tunnel, err := s.manager.FindTunnel(nameOrID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err := fn(tunnel.ID); err != nil {
+		return nil, status.Error(codeForError(err), err.Error())
+	}
+	if current, err := s.manager.GetTunnel(tunnel.ID); err == nil {
+		tunnel = current
+	}
+	return toProto(tunnel.Snapshot()), nil
+}
+
+// codeForError returns the status code for a failed tunnel action
+func codeForError(err error) codes.Code {
+	if errors.Is(err, core.ErrTunnelNotFound) {
+		return codes.NotFound
+	}
+	return codes.FailedPrecondition
+}
+
+// reload picks up tunnels added or edited by other processes
+func (s *Server) reload() {
+	if err := s.manager.ReloadConfig(); err != nil {
+		core.Warn("Failed to reload config: %v", err)
+	}
+}
+
+// toProto converts a tunnel snapshot into its protobuf message
+func toProto(snapshot core.TunnelSnapshot) *pb.Tunnel {
+	t := &pb.Tunnel{
+		Id:            snapshot.ID,
+		Name:          snapshot.Name,
+		Type:          string(snapshot.Type),
+		SshHost:       snapshot.SSHHost,
+		LocalHost:     snapshot.LocalHost,
+		LocalPort:     int32(snapshot.LocalPort),
+		RemoteHost:    snapshot.RemoteHost,
+		RemotePort:    int32(snapshot.RemotePort),
+		Profile:       snapshot.Profile,
+		AutoConnect:   snapshot.AutoConnect,
+		Forward:       snapshot.Forward,
+		Status:        string(snapshot.Status),
+		Pid:           int32(snapshot.PID),
+		UptimeSeconds: snapshot.UptimeSeconds,
+		Error:         snapshot.Error,
+	}
+	if snapshot.StartedAt != nil {
+		t.StartedAt = timestamppb.New(*snapshot.StartedAt)
+	}
+	return t
+}
@@ -0,0 +1,67 @@
+// Package grpcapi provides gRPC control API tests.
+package grpcapi
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// TestTokenRequired tests that calls run only with the server's bearer token
+func TestTokenRequired(t *testing.T) {
+	handler := func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/tunnelman.v1.TunnelService/StartTunnel"}
+
+	tests := []struct {
+		name   string
+		token  string
+		header []string
+		want   codes.Code
+	}{
+		{"no metadata", "secret", nil, codes.Unauthenticated},
+		{"wrong token", "secret", []string{"authorization", "Bearer wrong"}, codes.Unauthenticated},
+		{"not bearer", "secret", []string{"authorization", "secret"}, codes.Unauthenticated},
+		{"no server token", "", []string{"authorization", "Bearer "}, codes.Unauthenticated},
+		{"right token", "secret", []string{"authorization", "Bearer secret"}, codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := NewServer(nil, WithToken(tt.token))
+			ctx := context.Background()
+			if tt.header != nil {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(tt.header...))
+			}
+
+			resp, err := server.authorizeUnary(ctx, nil, info, handler)
+			if code := status.Code(err); code != tt.want {
+				t.Fatalf("Expected %s, got %s (%v)", tt.want, code, err)
+			}
+			if tt.want == codes.OK && resp != "ok" {
+				t.Errorf("Expected the handler to run, got %v", resp)
+			}
+
+			err = server.authorizeStream(nil, &fakeStream{ctx: ctx}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+				return nil
+			})
+			if code := status.Code(err); code != tt.want {
+				t.Errorf("Stream: expected %s, got %s (%v)", tt.want, code, err)
+			}
+		})
+	}
+}
+
+// fakeStream is a server stream that only carries a context
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
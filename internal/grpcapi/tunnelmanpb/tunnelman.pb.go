@@ -0,0 +1,577 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        v3.5.1-go
+// source: tunnelman/v1/tunnelman.proto
+
+package tunnelmanpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Tunnel is a snapshot of a tunnel's configuration and runtime state
+type Tunnel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	SshHost       string                 `protobuf:"bytes,4,opt,name=ssh_host,json=sshHost,proto3" json:"ssh_host,omitempty"`
+	LocalHost     string                 `protobuf:"bytes,5,opt,name=local_host,json=localHost,proto3" json:"local_host,omitempty"`
+	LocalPort     int32                  `protobuf:"varint,6,opt,name=local_port,json=localPort,proto3" json:"local_port,omitempty"`
+	RemoteHost    string                 `protobuf:"bytes,7,opt,name=remote_host,json=remoteHost,proto3" json:"remote_host,omitempty"`
+	RemotePort    int32                  `protobuf:"varint,8,opt,name=remote_port,json=remotePort,proto3" json:"remote_port,omitempty"`
+	Profile       string                 `protobuf:"bytes,9,opt,name=profile,proto3" json:"profile,omitempty"`
+	AutoConnect   bool                   `protobuf:"varint,10,opt,name=auto_connect,json=autoConnect,proto3" json:"auto_connect,omitempty"`
+	Forward       string                 `protobuf:"bytes,11,opt,name=forward,proto3" json:"forward,omitempty"`
+	Status        string                 `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`
+	Pid           int32                  `protobuf:"varint,13,opt,name=pid,proto3" json:"pid,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	UptimeSeconds int64                  `protobuf:"varint,15,opt,name=uptime_seconds,json=uptimeSeconds,proto3" json:"uptime_seconds,omitempty"`
+	Error         string                 `protobuf:"bytes,16,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Tunnel) Reset() {
+	*x = Tunnel{}
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Tunnel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tunnel) ProtoMessage() {}
+
+func (x *Tunnel) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tunnel.ProtoReflect.Descriptor instead.
+func (*Tunnel) Descriptor() ([]byte, []int) {
+	return file_tunnelman_v1_tunnelman_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Tunnel) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Tunnel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Tunnel) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Tunnel) GetSshHost() string {
+	if x != nil {
+		return x.SshHost
+	}
+	return ""
+}
+
+func (x *Tunnel) GetLocalHost() string {
+	if x != nil {
+		return x.LocalHost
+	}
+	return ""
+}
+
+func (x *Tunnel) GetLocalPort() int32 {
+	if x != nil {
+		return x.LocalPort
+	}
+	return 0
+}
+
+func (x *Tunnel) GetRemoteHost() string {
+	if x != nil {
+		return x.RemoteHost
+	}
+	return ""
+}
+
+func (x *Tunnel) GetRemotePort() int32 {
+	if x != nil {
+		return x.RemotePort
+	}
+	return 0
+}
+
+func (x *Tunnel) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *Tunnel) GetAutoConnect() bool {
+	if x != nil {
+		return x.AutoConnect
+	}
+	return false
+}
+
+func (x *Tunnel) GetForward() string {
+	if x != nil {
+		return x.Forward
+	}
+	return ""
+}
+
+func (x *Tunnel) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Tunnel) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *Tunnel) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Tunnel) GetUptimeSeconds() int64 {
+	if x != nil {
+		return x.UptimeSeconds
+	}
+	return 0
+}
+
+func (x *Tunnel) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListTunnelsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only list tunnels in this profile when set
+	Profile       string `protobuf:"bytes,1,opt,name=profile,proto3" json:"profile,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTunnelsRequest) Reset() {
+	*x = ListTunnelsRequest{}
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTunnelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTunnelsRequest) ProtoMessage() {}
+
+func (x *ListTunnelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTunnelsRequest.ProtoReflect.Descriptor instead.
+func (*ListTunnelsRequest) Descriptor() ([]byte, []int) {
+	return file_tunnelman_v1_tunnelman_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListTunnelsRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+type ListTunnelsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tunnels       []*Tunnel              `protobuf:"bytes,1,rep,name=tunnels,proto3" json:"tunnels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTunnelsResponse) Reset() {
+	*x = ListTunnelsResponse{}
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTunnelsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTunnelsResponse) ProtoMessage() {}
+
+func (x *ListTunnelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTunnelsResponse.ProtoReflect.Descriptor instead.
+func (*ListTunnelsResponse) Descriptor() ([]byte, []int) {
+	return file_tunnelman_v1_tunnelman_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListTunnelsResponse) GetTunnels() []*Tunnel {
+	if x != nil {
+		return x.Tunnels
+	}
+	return nil
+}
+
+type TunnelRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Tunnel name or ID
+	Tunnel        string `protobuf:"bytes,1,opt,name=tunnel,proto3" json:"tunnel,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TunnelRequest) Reset() {
+	*x = TunnelRequest{}
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TunnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TunnelRequest) ProtoMessage() {}
+
+func (x *TunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TunnelRequest.ProtoReflect.Descriptor instead.
+func (*TunnelRequest) Descriptor() ([]byte, []int) {
+	return file_tunnelman_v1_tunnelman_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TunnelRequest) GetTunnel() string {
+	if x != nil {
+		return x.Tunnel
+	}
+	return ""
+}
+
+type StreamStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only stream changes for these tunnel IDs when set
+	TunnelIds     []string `protobuf:"bytes,1,rep,name=tunnel_ids,json=tunnelIds,proto3" json:"tunnel_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamStatusRequest) Reset() {
+	*x = StreamStatusRequest{}
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamStatusRequest) ProtoMessage() {}
+
+func (x *StreamStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamStatusRequest.ProtoReflect.Descriptor instead.
+func (*StreamStatusRequest) Descriptor() ([]byte, []int) {
+	return file_tunnelman_v1_tunnelman_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamStatusRequest) GetTunnelIds() []string {
+	if x != nil {
+		return x.TunnelIds
+	}
+	return nil
+}
+
+// StatusChange is emitted whenever a tunnel changes status
+type StatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	TunnelId      string                 `protobuf:"bytes,2,opt,name=tunnel_id,json=tunnelId,proto3" json:"tunnel_id,omitempty"`
+	TunnelName    string                 `protobuf:"bytes,3,opt,name=tunnel_name,json=tunnelName,proto3" json:"tunnel_name,omitempty"`
+	OldStatus     string                 `protobuf:"bytes,4,opt,name=old_status,json=oldStatus,proto3" json:"old_status,omitempty"`
+	NewStatus     string                 `protobuf:"bytes,5,opt,name=new_status,json=newStatus,proto3" json:"new_status,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusChange) Reset() {
+	*x = StatusChange{}
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusChange) ProtoMessage() {}
+
+func (x *StatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_tunnelman_v1_tunnelman_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusChange.ProtoReflect.Descriptor instead.
+func (*StatusChange) Descriptor() ([]byte, []int) {
+	return file_tunnelman_v1_tunnelman_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StatusChange) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *StatusChange) GetTunnelId() string {
+	if x != nil {
+		return x.TunnelId
+	}
+	return ""
+}
+
+func (x *StatusChange) GetTunnelName() string {
+	if x != nil {
+		return x.TunnelName
+	}
+	return ""
+}
+
+func (x *StatusChange) GetOldStatus() string {
+	if x != nil {
+		return x.OldStatus
+	}
+	return ""
+}
+
+func (x *StatusChange) GetNewStatus() string {
+	if x != nil {
+		return x.NewStatus
+	}
+	return ""
+}
+
+func (x *StatusChange) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_tunnelman_v1_tunnelman_proto protoreflect.FileDescriptor
+
+const file_tunnelman_v1_tunnelman_proto_rawDesc = "" +
+	"\n" +
+	"\x1ctunnelman/v1/tunnelman.proto\x12\ftunnelman.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd4\x03\n" +
+	"\x06Tunnel\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x19\n" +
+	"\bssh_host\x18\x04 \x01(\tR\asshHost\x12\x1d\n" +
+	"\n" +
+	"local_host\x18\x05 \x01(\tR\tlocalHost\x12\x1d\n" +
+	"\n" +
+	"local_port\x18\x06 \x01(\x05R\tlocalPort\x12\x1f\n" +
+	"\vremote_host\x18\a \x01(\tR\n" +
+	"remoteHost\x12\x1f\n" +
+	"\vremote_port\x18\b \x01(\x05R\n" +
+	"remotePort\x12\x18\n" +
+	"\aprofile\x18\t \x01(\tR\aprofile\x12!\n" +
+	"\fauto_connect\x18\n" +
+	" \x01(\bR\vautoConnect\x12\x18\n" +
+	"\aforward\x18\v \x01(\tR\aforward\x12\x16\n" +
+	"\x06status\x18\f \x01(\tR\x06status\x12\x10\n" +
+	"\x03pid\x18\r \x01(\x05R\x03pid\x129\n" +
+	"\n" +
+	"started_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12%\n" +
+	"\x0euptime_seconds\x18\x0f \x01(\x03R\ruptimeSeconds\x12\x14\n" +
+	"\x05error\x18\x10 \x01(\tR\x05error\".\n" +
+	"\x12ListTunnelsRequest\x12\x18\n" +
+	"\aprofile\x18\x01 \x01(\tR\aprofile\"E\n" +
+	"\x13ListTunnelsResponse\x12.\n" +
+	"\atunnels\x18\x01 \x03(\v2\x14.tunnelman.v1.TunnelR\atunnels\"'\n" +
+	"\rTunnelRequest\x12\x16\n" +
+	"\x06tunnel\x18\x01 \x01(\tR\x06tunnel\"4\n" +
+	"\x13StreamStatusRequest\x12\x1d\n" +
+	"\n" +
+	"tunnel_ids\x18\x01 \x03(\tR\ttunnelIds\"\xd0\x01\n" +
+	"\fStatusChange\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x1b\n" +
+	"\ttunnel_id\x18\x02 \x01(\tR\btunnelId\x12\x1f\n" +
+	"\vtunnel_name\x18\x03 \x01(\tR\n" +
+	"tunnelName\x12\x1d\n" +
+	"\n" +
+	"old_status\x18\x04 \x01(\tR\toldStatus\x12\x1d\n" +
+	"\n" +
+	"new_status\x18\x05 \x01(\tR\tnewStatus\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error2\xbb\x03\n" +
+	"\rTunnelService\x12R\n" +
+	"\vListTunnels\x12 .tunnelman.v1.ListTunnelsRequest\x1a!.tunnelman.v1.ListTunnelsResponse\x12>\n" +
+	"\tGetTunnel\x12\x1b.tunnelman.v1.TunnelRequest\x1a\x14.tunnelman.v1.Tunnel\x12@\n" +
+	"\vStartTunnel\x12\x1b.tunnelman.v1.TunnelRequest\x1a\x14.tunnelman.v1.Tunnel\x12?\n" +
+	"\n" +
+	"StopTunnel\x12\x1b.tunnelman.v1.TunnelRequest\x1a\x14.tunnelman.v1.Tunnel\x12B\n" +
+	"\rRestartTunnel\x12\x1b.tunnelman.v1.TunnelRequest\x1a\x14.tunnelman.v1.Tunnel\x12O\n" +
+	"\fStreamStatus\x12!.tunnelman.v1.StreamStatusRequest\x1a\x1a.tunnelman.v1.StatusChange0\x01B=Z;github.com/takaaki-s/tunnelman/internal/grpcapi/tunnelmanpbb\x06proto3"
+
+var (
+	file_tunnelman_v1_tunnelman_proto_rawDescOnce sync.Once
+	file_tunnelman_v1_tunnelman_proto_rawDescData []byte
+)
+
+func file_tunnelman_v1_tunnelman_proto_rawDescGZIP() []byte {
+	file_tunnelman_v1_tunnelman_proto_rawDescOnce.Do(func() {
+		file_tunnelman_v1_tunnelman_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tunnelman_v1_tunnelman_proto_rawDesc), len(file_tunnelman_v1_tunnelman_proto_rawDesc)))
+	})
+	return file_tunnelman_v1_tunnelman_proto_rawDescData
+}
+
+var file_tunnelman_v1_tunnelman_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_tunnelman_v1_tunnelman_proto_goTypes = []any{
+	(*Tunnel)(nil),                // 0: tunnelman.v1.Tunnel
+	(*ListTunnelsRequest)(nil),    // 1: tunnelman.v1.ListTunnelsRequest
+	(*ListTunnelsResponse)(nil),   // 2: tunnelman.v1.ListTunnelsResponse
+	(*TunnelRequest)(nil),         // 3: tunnelman.v1.TunnelRequest
+	(*StreamStatusRequest)(nil),   // 4: tunnelman.v1.StreamStatusRequest
+	(*StatusChange)(nil),          // 5: tunnelman.v1.StatusChange
+	(*timestamppb.Timestamp)(nil), // 6: google.protobuf.Timestamp
+}
+var file_tunnelman_v1_tunnelman_proto_depIdxs = []int32{
+	6, // 0: tunnelman.v1.Tunnel.started_at:type_name -> google.protobuf.Timestamp
+	0, // 1: tunnelman.v1.ListTunnelsResponse.tunnels:type_name -> tunnelman.v1.Tunnel
+	6, // 2: tunnelman.v1.StatusChange.time:type_name -> google.protobuf.Timestamp
+	1, // 3: tunnelman.v1.TunnelService.ListTunnels:input_type -> tunnelman.v1.ListTunnelsRequest
+	3, // 4: tunnelman.v1.TunnelService.GetTunnel:input_type -> tunnelman.v1.TunnelRequest
+	3, // 5: tunnelman.v1.TunnelService.StartTunnel:input_type -> tunnelman.v1.TunnelRequest
+	3, // 6: tunnelman.v1.TunnelService.StopTunnel:input_type -> tunnelman.v1.TunnelRequest
+	3, // 7: tunnelman.v1.TunnelService.RestartTunnel:input_type -> tunnelman.v1.TunnelRequest
+	4, // 8: tunnelman.v1.TunnelService.StreamStatus:input_type -> tunnelman.v1.StreamStatusRequest
+	2, // 9: tunnelman.v1.TunnelService.ListTunnels:output_type -> tunnelman.v1.ListTunnelsResponse
+	0, // 10: tunnelman.v1.TunnelService.GetTunnel:output_type -> tunnelman.v1.Tunnel
+	0, // 11: tunnelman.v1.TunnelService.StartTunnel:output_type -> tunnelman.v1.Tunnel
+	0, // 12: tunnelman.v1.TunnelService.StopTunnel:output_type -> tunnelman.v1.Tunnel
+	0, // 13: tunnelman.v1.TunnelService.RestartTunnel:output_type -> tunnelman.v1.Tunnel
+	5, // 14: tunnelman.v1.TunnelService.StreamStatus:output_type -> tunnelman.v1.StatusChange
+	9, // [9:15] is the sub-list for method output_type
+	3, // [3:9] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_tunnelman_v1_tunnelman_proto_init() }
+func file_tunnelman_v1_tunnelman_proto_init() {
+	if File_tunnelman_v1_tunnelman_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tunnelman_v1_tunnelman_proto_rawDesc), len(file_tunnelman_v1_tunnelman_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_tunnelman_v1_tunnelman_proto_goTypes,
+		DependencyIndexes: file_tunnelman_v1_tunnelman_proto_depIdxs,
+		MessageInfos:      file_tunnelman_v1_tunnelman_proto_msgTypes,
+	}.Build()
+	File_tunnelman_v1_tunnelman_proto = out.File
+	file_tunnelman_v1_tunnelman_proto_goTypes = nil
+	file_tunnelman_v1_tunnelman_proto_depIdxs = nil
+}
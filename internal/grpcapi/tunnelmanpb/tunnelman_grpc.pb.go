@@ -0,0 +1,331 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             v3.5.1-go
+// source: tunnelman/v1/tunnelman.proto
+
+package tunnelmanpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TunnelService_ListTunnels_FullMethodName   = "/tunnelman.v1.TunnelService/ListTunnels"
+	TunnelService_GetTunnel_FullMethodName     = "/tunnelman.v1.TunnelService/GetTunnel"
+	TunnelService_StartTunnel_FullMethodName   = "/tunnelman.v1.TunnelService/StartTunnel"
+	TunnelService_StopTunnel_FullMethodName    = "/tunnelman.v1.TunnelService/StopTunnel"
+	TunnelService_RestartTunnel_FullMethodName = "/tunnelman.v1.TunnelService/RestartTunnel"
+	TunnelService_StreamStatus_FullMethodName  = "/tunnelman.v1.TunnelService/StreamStatus"
+)
+
+// TunnelServiceClient is the client API for TunnelService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TunnelService controls tunnels managed by a running tunnelman instance
+type TunnelServiceClient interface {
+	// ListTunnels returns all tunnels, optionally filtered by profile
+	ListTunnels(ctx context.Context, in *ListTunnelsRequest, opts ...grpc.CallOption) (*ListTunnelsResponse, error)
+	// GetTunnel returns a single tunnel by name or ID
+	GetTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error)
+	// StartTunnel starts a tunnel by name or ID
+	StartTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error)
+	// StopTunnel stops a tunnel by name or ID
+	StopTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error)
+	// RestartTunnel restarts a tunnel by name or ID
+	RestartTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error)
+	// StreamStatus pushes tunnel status changes until the client cancels
+	StreamStatus(ctx context.Context, in *StreamStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusChange], error)
+}
+
+type tunnelServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTunnelServiceClient(cc grpc.ClientConnInterface) TunnelServiceClient {
+	return &tunnelServiceClient{cc}
+}
+
+func (c *tunnelServiceClient) ListTunnels(ctx context.Context, in *ListTunnelsRequest, opts ...grpc.CallOption) (*ListTunnelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTunnelsResponse)
+	err := c.cc.Invoke(ctx, TunnelService_ListTunnels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) GetTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tunnel)
+	err := c.cc.Invoke(ctx, TunnelService_GetTunnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) StartTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tunnel)
+	err := c.cc.Invoke(ctx, TunnelService_StartTunnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) StopTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tunnel)
+	err := c.cc.Invoke(ctx, TunnelService_StopTunnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) RestartTunnel(ctx context.Context, in *TunnelRequest, opts ...grpc.CallOption) (*Tunnel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Tunnel)
+	err := c.cc.Invoke(ctx, TunnelService_RestartTunnel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tunnelServiceClient) StreamStatus(ctx context.Context, in *StreamStatusRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[StatusChange], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TunnelService_ServiceDesc.Streams[0], TunnelService_StreamStatus_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamStatusRequest, StatusChange]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_StreamStatusClient = grpc.ServerStreamingClient[StatusChange]
+
+// TunnelServiceServer is the server API for TunnelService service.
+// All implementations must embed UnimplementedTunnelServiceServer
+// for forward compatibility.
+//
+// TunnelService controls tunnels managed by a running tunnelman instance
+type TunnelServiceServer interface {
+	// ListTunnels returns all tunnels, optionally filtered by profile
+	ListTunnels(context.Context, *ListTunnelsRequest) (*ListTunnelsResponse, error)
+	// GetTunnel returns a single tunnel by name or ID
+	GetTunnel(context.Context, *TunnelRequest) (*Tunnel, error)
+	// StartTunnel starts a tunnel by name or ID
+	StartTunnel(context.Context, *TunnelRequest) (*Tunnel, error)
+	// StopTunnel stops a tunnel by name or ID
+	StopTunnel(context.Context, *TunnelRequest) (*Tunnel, error)
+	// RestartTunnel restarts a tunnel by name or ID
+	RestartTunnel(context.Context, *TunnelRequest) (*Tunnel, error)
+	// StreamStatus pushes tunnel status changes until the client cancels
+	StreamStatus(*StreamStatusRequest, grpc.ServerStreamingServer[StatusChange]) error
+	mustEmbedUnimplementedTunnelServiceServer()
+}
+
+// UnimplementedTunnelServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTunnelServiceServer struct{}
+
+func (UnimplementedTunnelServiceServer) ListTunnels(context.Context, *ListTunnelsRequest) (*ListTunnelsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTunnels not implemented")
+}
+func (UnimplementedTunnelServiceServer) GetTunnel(context.Context, *TunnelRequest) (*Tunnel, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTunnel not implemented")
+}
+func (UnimplementedTunnelServiceServer) StartTunnel(context.Context, *TunnelRequest) (*Tunnel, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartTunnel not implemented")
+}
+func (UnimplementedTunnelServiceServer) StopTunnel(context.Context, *TunnelRequest) (*Tunnel, error) {
+	return nil, status.Error(codes.Unimplemented, "method StopTunnel not implemented")
+}
+func (UnimplementedTunnelServiceServer) RestartTunnel(context.Context, *TunnelRequest) (*Tunnel, error) {
+	return nil, status.Error(codes.Unimplemented, "method RestartTunnel not implemented")
+}
+func (UnimplementedTunnelServiceServer) StreamStatus(*StreamStatusRequest, grpc.ServerStreamingServer[StatusChange]) error {
+	return status.Error(codes.Unimplemented, "method StreamStatus not implemented")
+}
+func (UnimplementedTunnelServiceServer) mustEmbedUnimplementedTunnelServiceServer() {}
+func (UnimplementedTunnelServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeTunnelServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TunnelServiceServer will
+// result in compilation errors.
+type UnsafeTunnelServiceServer interface {
+	mustEmbedUnimplementedTunnelServiceServer()
+}
+
+func RegisterTunnelServiceServer(s grpc.ServiceRegistrar, srv TunnelServiceServer) {
+	// If the following call panics, it indicates UnimplementedTunnelServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TunnelService_ServiceDesc, srv)
+}
+
+func _TunnelService_ListTunnels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTunnelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).ListTunnels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_ListTunnels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).ListTunnels(ctx, req.(*ListTunnelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_GetTunnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TunnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).GetTunnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_GetTunnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).GetTunnel(ctx, req.(*TunnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_StartTunnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TunnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).StartTunnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_StartTunnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).StartTunnel(ctx, req.(*TunnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_StopTunnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TunnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).StopTunnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_StopTunnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).StopTunnel(ctx, req.(*TunnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_RestartTunnel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TunnelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TunnelServiceServer).RestartTunnel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TunnelService_RestartTunnel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TunnelServiceServer).RestartTunnel(ctx, req.(*TunnelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TunnelService_StreamStatus_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamStatusRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TunnelServiceServer).StreamStatus(m, &grpc.GenericServerStream[StreamStatusRequest, StatusChange]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TunnelService_StreamStatusServer = grpc.ServerStreamingServer[StatusChange]
+
+// TunnelService_ServiceDesc is the grpc.ServiceDesc for TunnelService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TunnelService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "tunnelman.v1.TunnelService",
+	HandlerType: (*TunnelServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTunnels",
+			Handler:    _TunnelService_ListTunnels_Handler,
+		},
+		{
+			MethodName: "GetTunnel",
+			Handler:    _TunnelService_GetTunnel_Handler,
+		},
+		{
+			MethodName: "StartTunnel",
+			Handler:    _TunnelService_StartTunnel_Handler,
+		},
+		{
+			MethodName: "StopTunnel",
+			Handler:    _TunnelService_StopTunnel_Handler,
+		},
+		{
+			MethodName: "RestartTunnel",
+			Handler:    _TunnelService_RestartTunnel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamStatus",
+			Handler:       _TunnelService_StreamStatus_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "tunnelman/v1/tunnelman.proto",
+}
@@ -0,0 +1,165 @@
+// Package service provides launchd agent management.
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// launchdManager manages per-user launchd agents
+type launchdManager struct{}
+
+// label returns the launchd label for a profile
+func (m *launchdManager) label(profile string) string {
+	return "com.github.takaaki-s.tunnelman." + sanitize(profile)
+}
+
+// plistPath returns the path of the agent plist for a profile
+func (m *launchdManager) plistPath(profile string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", m.label(profile)+".plist"), nil
+}
+
+// domain returns the launchctl domain of the current user's GUI session
+func (m *launchdManager) domain() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+// Definition returns the agent plist contents. tunnelman supervises the tunnels
+// itself, so launchd only restarts it if it exits unsuccessfully.
+func (m *launchdManager) Definition(opts Options) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	logPath := filepath.Join(home, "Library", "Logs", serviceName(opts.Profile)+".log")
+
+	var args strings.Builder
+	for _, arg := range opts.args() {
+		fmt.Fprintf(&args, "\t\t<string>%s</string>\n", xmlEscape(arg))
+	}
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>%s</string>
+	<key>ProgramArguments</key>
+	<array>
+%s	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ThrottleInterval</key>
+	<integer>5</integer>
+	<key>StandardOutPath</key>
+	<string>%s</string>
+	<key>StandardErrorPath</key>
+	<string>%s</string>
+</dict>
+</plist>
+`, xmlEscape(m.label(opts.Profile)), args.String(), xmlEscape(logPath), xmlEscape(logPath)), nil
+}
+
+// Install writes the plist and loads the agent
+func (m *launchdManager) Install(opts Options) error {
+	path, err := m.plistPath(opts.Profile)
+	if err != nil {
+		return err
+	}
+	plist, err := m.Definition(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create LaunchAgents directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(plist), 0644); err != nil {
+		return fmt.Errorf("failed to write plist: %w", err)
+	}
+
+	// Reinstalling replaces a loaded agent, so unload any previous version first
+	launchctl("bootout", m.domain()+"/"+m.label(opts.Profile))
+	return launchctl("bootstrap", m.domain(), path)
+}
+
+// Uninstall unloads the agent and removes its plist
+func (m *launchdManager) Uninstall(profile string) error {
+	path, err := m.plistPath(profile)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("service for profile %s is not installed", profile)
+	}
+
+	if err := launchctl("bootout", m.domain()+"/"+m.label(profile)); err != nil {
+		msg := strings.ToLower(err.Error())
+		// An agent that is installed but not loaded has nothing to boot out
+		if !strings.Contains(msg, "no such process") && !strings.Contains(msg, "could not find") {
+			return err
+		}
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove plist: %w", err)
+	}
+	return nil
+}
+
+// Status returns the output of launchctl print for the agent
+func (m *launchdManager) Status(profile string) (string, error) {
+	path, err := m.plistPath(profile)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Sprintf("%s: not installed", m.label(profile)), nil
+	}
+
+	out, err := exec.Command("launchctl", "print", m.domain()+"/"+m.label(profile)).CombinedOutput()
+	if err != nil {
+		return fmt.Sprintf("%s: installed, not loaded", m.label(profile)), nil
+	}
+	return string(out), nil
+}
+
+// Start starts the loaded agent, restarting it if it is already running
+func (m *launchdManager) Start(profile string) error {
+	return launchctl("kickstart", "-k", m.domain()+"/"+m.label(profile))
+}
+
+// Stop sends SIGTERM to the agent. supervise exits successfully on SIGTERM, so
+// KeepAlive does not restart it.
+func (m *launchdManager) Stop(profile string) error {
+	return launchctl("kill", "SIGTERM", m.domain()+"/"+m.label(profile))
+}
+
+// launchctl runs a launchctl command
+func launchctl(args ...string) error {
+	out, err := exec.Command("launchctl", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("launchctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// xmlEscape escapes a string for use as XML character data
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,107 @@
+// Package service installs tunnelman as a per-user background service that
+// runs `tunnelman supervise` for a profile.
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Options describes the service to install
+type Options struct {
+	// Profile whose tunnels are supervised
+	Profile string
+
+	// Executable is the absolute path to the tunnelman binary
+	Executable string
+
+	// ConfigPath is passed to tunnelman via --config
+	ConfigPath string
+}
+
+// Manager installs and controls a service on the current platform
+type Manager interface {
+	// Definition returns the service file contents that Install would write
+	Definition(opts Options) (string, error)
+
+	// Install writes the service definition and starts the service
+	Install(opts Options) error
+
+	// Uninstall stops the service and removes its definition
+	Uninstall(profile string) error
+
+	// Status returns a human-readable description of the service state
+	Status(profile string) (string, error)
+
+	// Start starts an installed service
+	Start(profile string) error
+
+	// Stop stops a running service, leaving it installed
+	Stop(profile string) error
+}
+
+// NewManager returns the service manager for the current platform
+func NewManager() (Manager, error) {
+	switch runtime.GOOS {
+	case "linux":
+		return &systemdManager{}, nil
+	case "darwin":
+		return &launchdManager{}, nil
+	case "windows":
+		return newWindowsManager()
+	default:
+		return nil, fmt.Errorf("services are not supported on %s", runtime.GOOS)
+	}
+}
+
+// DefaultOptions returns options for the running executable and the given config path
+func DefaultOptions(profile, configPath string) (Options, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return Options{}, fmt.Errorf("cannot determine executable path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+
+	if configPath != "" {
+		if abs, err := filepath.Abs(configPath); err == nil {
+			configPath = abs
+		}
+	}
+
+	return Options{
+		Profile:    profile,
+		Executable: executable,
+		ConfigPath: configPath,
+	}, nil
+}
+
+// args returns the tunnelman command line the service runs
+func (o Options) args() []string {
+	args := []string{o.Executable}
+	if o.ConfigPath != "" {
+		args = append(args, "--config", o.ConfigPath)
+	}
+	return append(args, "supervise", "--profile", o.Profile)
+}
+
+// serviceName returns the service name for a profile
+func serviceName(profile string) string {
+	return "tunnelman-" + sanitize(profile)
+}
+
+// sanitize replaces characters that are not safe in service and file names
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,138 @@
+// Package service provides systemd user unit management.
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// systemdManager manages systemd user units
+type systemdManager struct{}
+
+// unitPath returns the path of the user unit file for a profile
+func (m *systemdManager) unitPath(profile string) (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot determine home directory: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "systemd", "user", serviceName(profile)+".service"), nil
+}
+
+// Definition returns the unit file contents
+func (m *systemdManager) Definition(opts Options) (string, error) {
+	quoted := make([]string, 0, len(opts.args()))
+	for _, arg := range opts.args() {
+		quoted = append(quoted, systemdQuote(arg))
+	}
+
+	return fmt.Sprintf(`[Unit]
+Description=tunnelman SSH tunnels (profile %s)
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart=%s
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`, opts.Profile, strings.Join(quoted, " ")), nil
+}
+
+// Install writes the unit file, then enables and starts it
+func (m *systemdManager) Install(opts Options) error {
+	path, err := m.unitPath(opts.Profile)
+	if err != nil {
+		return err
+	}
+	unit, err := m.Definition(opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create unit directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return fmt.Errorf("failed to write unit file: %w", err)
+	}
+
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+	return systemctl("enable", "--now", serviceName(opts.Profile)+".service")
+}
+
+// Uninstall disables and stops the unit, then removes its file
+func (m *systemdManager) Uninstall(profile string) error {
+	path, err := m.unitPath(profile)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Errorf("service for profile %s is not installed", profile)
+	}
+
+	if err := systemctl("disable", "--now", serviceName(profile)+".service"); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove unit file: %w", err)
+	}
+	return systemctl("daemon-reload")
+}
+
+// Status returns the output of systemctl status for the unit
+func (m *systemdManager) Status(profile string) (string, error) {
+	path, err := m.unitPath(profile)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return fmt.Sprintf("%s: not installed", serviceName(profile)), nil
+	}
+
+	// systemctl status exits non-zero for inactive units, which is not an error here
+	out, err := exec.Command("systemctl", "--user", "status", "--no-pager", serviceName(profile)+".service").CombinedOutput()
+	if len(out) == 0 && err != nil {
+		return "", fmt.Errorf("systemctl status failed: %w", err)
+	}
+	return string(out), nil
+}
+
+// Start starts the unit
+func (m *systemdManager) Start(profile string) error {
+	return systemctl("start", serviceName(profile)+".service")
+}
+
+// Stop stops the unit
+func (m *systemdManager) Stop(profile string) error {
+	return systemctl("stop", serviceName(profile)+".service")
+}
+
+// systemctl runs a systemctl --user command
+func systemctl(args ...string) error {
+	out, err := exec.Command("systemctl", append([]string{"--user"}, args...)...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("systemctl %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// systemdQuote quotes an ExecStart argument if needed
+func systemdQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\%$") {
+		return arg
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `%`, `%%`, `$`, `$$`)
+	return `"` + replacer.Replace(arg) + `"`
+}
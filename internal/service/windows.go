@@ -0,0 +1,268 @@
+//go:build windows
+
+// Package service provides Windows service management.
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// windowsManager manages services through the Windows service control manager
+type windowsManager struct{}
+
+// newWindowsManager returns the Windows service manager
+func newWindowsManager() (Manager, error) {
+	return &windowsManager{}, nil
+}
+
+// Definition returns the equivalent sc.exe command, since Windows services have
+// no definition file
+func (m *windowsManager) Definition(opts Options) (string, error) {
+	quoted := make([]string, 0, len(opts.args()))
+	for _, arg := range opts.args() {
+		quoted = append(quoted, windowsQuote(arg))
+	}
+
+	return fmt.Sprintf("sc.exe create %s binPath= %s start= auto DisplayName= %s\n",
+		serviceName(opts.Profile),
+		windowsQuote(strings.Join(quoted, " ")),
+		windowsQuote(displayName(opts.Profile))), nil
+}
+
+// Install registers the service to start automatically and starts it. The
+// service control manager restarts it if it exits unsuccessfully.
+func (m *windowsManager) Install(opts Options) error {
+	scm, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	defer scm.Disconnect()
+
+	name := serviceName(opts.Profile)
+	if s, err := scm.OpenService(name); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s is already installed", name)
+	}
+
+	args := opts.args()
+	s, err := scm.CreateService(name, args[0], mgr.Config{
+		DisplayName: displayName(opts.Profile),
+		Description: "Keeps the tunnelman SSH tunnels of a profile running",
+		StartType:   mgr.StartAutomatic,
+	}, args[1:]...)
+	if err != nil {
+		return fmt.Errorf("failed to create service: %w", err)
+	}
+	defer s.Close()
+
+	restart := []mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: 5 * time.Second}}
+	if err := s.SetRecoveryActions(restart, uint32((24 * time.Hour).Seconds())); err != nil {
+		return fmt.Errorf("failed to set recovery actions: %w", err)
+	}
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+	return nil
+}
+
+// Uninstall stops the service and removes it from the service control manager
+func (m *windowsManager) Uninstall(profile string) error {
+	scm, s, err := m.open(profile)
+	if err != nil {
+		return err
+	}
+	defer scm.Disconnect()
+	defer s.Close()
+
+	if err := stopService(s); err != nil {
+		return err
+	}
+	if err := s.Delete(); err != nil {
+		return fmt.Errorf("failed to delete service: %w", err)
+	}
+	return nil
+}
+
+// Status returns the service name and its current state
+func (m *windowsManager) Status(profile string) (string, error) {
+	scm, err := mgr.Connect()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	defer scm.Disconnect()
+
+	name := serviceName(profile)
+	s, err := scm.OpenService(name)
+	if err != nil {
+		return fmt.Sprintf("%s: not installed", name), nil
+	}
+	defer s.Close()
+
+	status, err := s.Query()
+	if err != nil {
+		return "", fmt.Errorf("failed to query service: %w", err)
+	}
+	if status.State == svc.Running {
+		return fmt.Sprintf("%s: %s (PID %d)", name, stateName(status.State), status.ProcessId), nil
+	}
+	return fmt.Sprintf("%s: %s", name, stateName(status.State)), nil
+}
+
+// Start starts the service
+func (m *windowsManager) Start(profile string) error {
+	scm, s, err := m.open(profile)
+	if err != nil {
+		return err
+	}
+	defer scm.Disconnect()
+	defer s.Close()
+
+	if err := s.Start(); err != nil {
+		return fmt.Errorf("failed to start service: %w", err)
+	}
+	return nil
+}
+
+// Stop stops the service
+func (m *windowsManager) Stop(profile string) error {
+	scm, s, err := m.open(profile)
+	if err != nil {
+		return err
+	}
+	defer scm.Disconnect()
+	defer s.Close()
+
+	return stopService(s)
+}
+
+// open connects to the service control manager and opens the profile's service
+func (m *windowsManager) open(profile string) (*mgr.Mgr, *mgr.Service, error) {
+	scm, err := mgr.Connect()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	s, err := scm.OpenService(serviceName(profile))
+	if err != nil {
+		scm.Disconnect()
+		return nil, nil, fmt.Errorf("service for profile %s is not installed", profile)
+	}
+	return scm, s, nil
+}
+
+// stopService asks a service to stop and waits for it to do so
+func stopService(s *mgr.Service) error {
+	status, err := s.Control(svc.Stop)
+	if err != nil {
+		// Stopping a service that is not running is not an error here
+		if err == windows.ERROR_SERVICE_NOT_ACTIVE {
+			return nil
+		}
+		return fmt.Errorf("failed to stop service: %w", err)
+	}
+
+	deadline := time.Now().Add(30 * time.Second)
+	for status.State != svc.Stopped {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for service to stop")
+		}
+		time.Sleep(300 * time.Millisecond)
+		if status, err = s.Query(); err != nil {
+			return fmt.Errorf("failed to query service: %w", err)
+		}
+	}
+	return nil
+}
+
+// IsWindowsService reports whether the process was started by the service
+// control manager
+func IsWindowsService() bool {
+	isService, err := svc.IsWindowsService()
+	return err == nil && isService
+}
+
+// RunWindowsService runs fn under the service control manager until the
+// service is asked to stop, at which point fn's context is cancelled
+func RunWindowsService(profile string, fn func(ctx context.Context)) error {
+	return svc.Run(serviceName(profile), &handler{fn: fn})
+}
+
+// handler adapts a run function to svc.Handler
+type handler struct {
+	fn func(ctx context.Context)
+}
+
+// Execute reports the service as running, then cancels fn on Stop or Shutdown
+func (h *handler) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	changes <- svc.Status{State: svc.StartPending}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		h.fn(ctx)
+		close(done)
+	}()
+
+	changes <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				cancel()
+				<-done
+				return false, 0
+			}
+		case <-done:
+			// fn returned on its own, so report a failure for the recovery actions
+			return false, 1
+		}
+	}
+}
+
+// displayName returns the service display name for a profile
+func displayName(profile string) string {
+	return fmt.Sprintf("tunnelman SSH tunnels (profile %s)", profile)
+}
+
+// stateName returns a readable name for a service state
+func stateName(state svc.State) string {
+	switch state {
+	case svc.Stopped:
+		return "stopped"
+	case svc.StartPending:
+		return "starting"
+	case svc.StopPending:
+		return "stopping"
+	case svc.Running:
+		return "running"
+	case svc.ContinuePending:
+		return "resuming"
+	case svc.PausePending:
+		return "pausing"
+	case svc.Paused:
+		return "paused"
+	default:
+		return fmt.Sprintf("unknown state %d", state)
+	}
+}
+
+// windowsQuote quotes a command line argument if needed
+func windowsQuote(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"") {
+		return arg
+	}
+	return `"` + strings.ReplaceAll(arg, `"`, `\"`) + `"`
+}
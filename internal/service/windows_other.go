@@ -0,0 +1,24 @@
+//go:build !windows
+
+// Package service provides stubs for Windows service support on other platforms.
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// newWindowsManager is only reachable on Windows
+func newWindowsManager() (Manager, error) {
+	return nil, fmt.Errorf("windows services are not supported on this platform")
+}
+
+// IsWindowsService always reports false outside Windows
+func IsWindowsService() bool {
+	return false
+}
+
+// RunWindowsService is only supported on Windows
+func RunWindowsService(profile string, fn func(ctx context.Context)) error {
+	return fmt.Errorf("windows services are not supported on this platform")
+}
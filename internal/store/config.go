@@ -3,15 +3,35 @@ package store
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
 // FileConfigStore implements ConfigStore using file system storage
 type FileConfigStore struct {
 	configPath string
+
+	// key returns the key of an encrypted config, and locked is set when
+	// the config could not be decrypted
+	key    ConfigKeyFunc
+	locked bool
+
+	// includes are the include patterns of the config when last loaded,
+	// guarded by mu as watchers read them
+	mu       sync.Mutex
+	includes []string
+
+	// db is the SQLite database of the sqlite storage backend, and sqlite
+	// is set when the config last loaded uses it; both guarded by mu
+	db     *SQLiteStore
+	sqlite bool
 }
 
 // NewFileConfigStore creates a new file-based configuration store
@@ -63,40 +83,162 @@ func getConfigPath() (string, error) {
 
 // LoadConfig loads the tunnel configuration from the XDG-compliant config file
 func (fcs *FileConfigStore) LoadConfig() (*AppConfig, error) {
+	return fcs.loadConfig(false)
+}
+
+// loadConfig loads the configuration; holdsLock tells whether the caller
+// holds the lock file, which saving a migrated config then does not take
+func (fcs *FileConfigStore) loadConfig(holdsLock bool) (*AppConfig, error) {
 	// Read the configuration file
 	data, err := os.ReadFile(fcs.configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Return default configuration if file doesn't exist
 			return &AppConfig{
-				Version: "1.0.0",
+				Version: ConfigVersion,
 				Tunnels: []TunnelConfig{},
 			}, nil
 		}
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse the configuration
-	var config AppConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	// Decrypt an encrypted configuration
+	plaintext, err := fcs.decryptConfig(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the configuration, migrating older formats. A config from a
+	// newer version is not overwritten, as that would lose its settings.
+	config, err := ParseConfig(plaintext)
+	if err != nil {
+		if errors.Is(err, ErrConfigTooNew) {
+			fcs.locked = true
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &config, nil
+	// Save a migrated configuration so it is only migrated once
+	if config.MigratedFrom != "" {
+		if err := fcs.saveMigratedConfig(config, data, holdsLock); err != nil {
+			return nil, err
+		}
+	}
+
+	// Read the tunnels kept in a database; as with includes, saving
+	// without them would lose them
+	if err := config.Storage.Validate(); err != nil {
+		fcs.locked = true
+		return nil, err
+	}
+	fcs.mu.Lock()
+	fcs.sqlite = config.Storage.IsSQLite()
+	fcs.mu.Unlock()
+	if config.Storage.IsSQLite() {
+		if err := fcs.loadDatabase(config); err != nil {
+			fcs.locked = true
+			return nil, err
+		}
+	}
+
+	// Add the tunnels of included files; without them saving would lose
+	// the tunnels of an included file that could not be read
+	if err := fcs.loadIncludes(config); err != nil {
+		fcs.locked = true
+		return nil, err
+	}
+
+	// Keep the config as first loaded in the history, so the first change
+	// can be undone
+	_, encrypted := parseEncryptedConfig(data)
+	fcs.seedHistory(config, encrypted)
+
+	return config, nil
 }
 
-// SaveConfig saves the tunnel configuration to the XDG-compliant config file
+// SaveConfig saves the tunnel configuration to the XDG-compliant config
+// file, encrypted if the file is
 func (fcs *FileConfigStore) SaveConfig(config *AppConfig) error {
+	return fcs.writeConfig(config, fcs.Encrypted())
+}
+
+// writeConfig writes the configuration to the config file, optionally
+// encrypted, holding the lock file so that two tunnelman processes saving at
+// once do not interleave writing the config, its includes and its database
+func (fcs *FileConfigStore) writeConfig(config *AppConfig, encrypted bool) error {
 	if config == nil {
 		return fmt.Errorf("config cannot be nil")
 	}
 
+	lock, err := lockFile(fcs.configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return fcs.writeConfigLocked(config, encrypted)
+}
+
+// updateConfig loads the configuration, applies fn and saves it, encrypted as
+// fn reports, holding the lock file throughout so that changes saved by
+// another process in between are not lost
+func (fcs *FileConfigStore) updateConfig(fn func(config *AppConfig) (encrypted bool, err error)) error {
+	lock, err := lockFile(fcs.configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	config, err := fcs.loadConfig(true)
+	if err != nil {
+		return err
+	}
+	encrypted, err := fn(config)
+	if err != nil {
+		return err
+	}
+	return fcs.writeConfigLocked(config, encrypted)
+}
+
+// writeConfigLocked writes the configuration; the caller must hold the lock file
+func (fcs *FileConfigStore) writeConfigLocked(config *AppConfig, encrypted bool) error {
+
+	if fcs.locked {
+		return ErrConfigLocked
+	}
+	full := config
+
+	// Tunnels and profiles of included files are saved to those files
+	config, err := fcs.saveIncludes(config)
+	if err != nil {
+		return err
+	}
+
+	// The rest goes to the database with the sqlite storage backend,
+	// leaving the config file with the settings needed to find it
+	if config.Storage.IsSQLite() {
+		if encrypted {
+			return fmt.Errorf("an encrypted config cannot use sqlite storage")
+		}
+		if config, err = fcs.saveDatabase(config); err != nil {
+			return err
+		}
+	}
+
 	// Marshal configuration to JSON with pretty formatting
 	data, err := json.MarshalIndent(config, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
+	// Seal the configuration for an encrypted file
+	if encrypted {
+		if data, err = fcs.encryptConfig(data); err != nil {
+			return fmt.Errorf("failed to encrypt config: %w", err)
+		}
+	}
+
 	// Write to temporary file first for atomic operation
 	tempFile := fcs.configPath + ".tmp"
 	if err := os.WriteFile(tempFile, data, 0644); err != nil {
@@ -114,6 +256,11 @@ func (fcs *FileConfigStore) SaveConfig(config *AppConfig) error {
 		return fmt.Errorf("failed to save config file: %w", err)
 	}
 
+	// Keep the saved config in the history; the save itself succeeded
+	if err := fcs.recordHistory(full, encrypted); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: Failed to record config history: %v\n", err)
+	}
+
 	return nil
 }
 
@@ -122,6 +269,58 @@ func (fcs *FileConfigStore) GetConfigPath() (string, error) {
 	return fcs.configPath, nil
 }
 
+// Exists reports whether the config file exists, which it does not until
+// the first config is saved
+func (fcs *FileConfigStore) Exists() bool {
+	_, err := os.Stat(fcs.configPath)
+	return err == nil
+}
+
+// FileStamp identifies a version of files by their latest modification
+// time, total size and number
+type FileStamp struct {
+	ModTime time.Time
+	Size    int64
+	Files   int
+}
+
+// Stamp returns the stamp of the config file, or the zero stamp if it does
+// not exist. It also changes when files matching the config's includes as
+// last loaded change, appear or disappear.
+func (fcs *FileConfigStore) Stamp() FileStamp {
+	var stamp FileStamp
+	includeFiles, _ := fcs.includePaths(fcs.lastIncludes())
+	for _, path := range append([]string{fcs.configPath}, includeFiles...) {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(stamp.ModTime) {
+			stamp.ModTime = info.ModTime()
+		}
+		stamp.Size += info.Size()
+		stamp.Files++
+	}
+	return stamp
+}
+
+// WatchDirs returns the directories holding the config file and the files
+// it includes as last loaded, which change when those files are edited,
+// replaced or added
+func (fcs *FileConfigStore) WatchDirs() []string {
+	dirs := []string{filepath.Dir(fcs.configPath)}
+	for _, pattern := range fcs.lastIncludes() {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(fcs.configPath), pattern)
+		}
+		dir := filepath.Dir(pattern)
+		if !slices.Contains(dirs, dir) && !strings.ContainsAny(dir, "*?[") {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
 // BackupConfig creates a backup of the current configuration
 func (fcs *FileConfigStore) BackupConfig() error {
 	// Check if config file exists
@@ -167,6 +366,11 @@ func (fcs *FileConfigStore) RestoreConfig() error {
 	}
 
 	// Restore configuration
+	lock, err := lockFile(fcs.configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
 	if err := os.WriteFile(fcs.configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to restore config: %w", err)
 	}
@@ -0,0 +1,185 @@
+// Package store provides encryption of the config file at rest.
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// configCipher names the cipher of encrypted config files
+const configCipher = "aes-256-gcm"
+
+// ConfigKeySize is the size in bytes of the key config files are encrypted with
+const ConfigKeySize = 32
+
+// ErrConfigLocked is returned when saving over a config that could not be
+// read, because it is encrypted without its key at hand or from a newer
+// version, which would otherwise lose its tunnels
+var ErrConfigLocked = errors.New("config could not be read, so it is not overwritten")
+
+// ConfigKeyFunc returns the key encrypted config files are sealed with
+type ConfigKeyFunc func() ([]byte, error)
+
+// encryptedConfig is how an encrypted config file is stored
+type encryptedConfig struct {
+	Cipher string `json:"cipher"`
+	Nonce  []byte `json:"nonce"`
+	Data   []byte `json:"data"`
+}
+
+// SetKeyFunc sets where the store gets the key of an encrypted config from
+func (fcs *FileConfigStore) SetKeyFunc(key ConfigKeyFunc) {
+	fcs.key = key
+}
+
+// Encrypted reports whether the config file is encrypted, which keeps it
+// encrypted when saved
+func (fcs *FileConfigStore) Encrypted() bool {
+	data, err := os.ReadFile(fcs.configPath)
+	if err != nil {
+		return false
+	}
+	_, ok := parseEncryptedConfig(data)
+	return ok
+}
+
+// SetEncrypted rewrites the config file encrypted or in plaintext.
+// Encrypting also seals the history snapshots and backups written while the
+// config was in plaintext; decrypting leaves them encrypted.
+func (fcs *FileConfigStore) SetEncrypted(encrypted bool) error {
+	return fcs.updateConfig(func(config *AppConfig) (bool, error) {
+		if encrypted {
+			if err := fcs.sealCopies(); err != nil {
+				return false, err
+			}
+		}
+		return encrypted, nil
+	})
+}
+
+// sealCopies encrypts the history snapshots and backups of the config that
+// are still in plaintext. The caller must hold the lock file.
+func (fcs *FileConfigStore) sealCopies() error {
+	dir, names, err := fcs.historyFiles()
+	if err != nil {
+		return err
+	}
+	var paths []string
+	for _, name := range names {
+		paths = append(paths, filepath.Join(dir, name))
+	}
+
+	// Backups sit next to the config, as config.json.backup or, from
+	// migrations, config.json.v1.0.backup
+	entries, err := os.ReadDir(filepath.Dir(fcs.configPath))
+	if err != nil {
+		return fmt.Errorf("failed to read config directory: %w", err)
+	}
+	base := filepath.Base(fcs.configPath)
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasPrefix(name, base+".") && strings.HasSuffix(name, ".backup") {
+			paths = append(paths, filepath.Join(filepath.Dir(fcs.configPath), name))
+		}
+	}
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if _, ok := parseEncryptedConfig(data); ok {
+			continue
+		}
+		sealed, err := fcs.encryptConfig(data)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", path, err)
+		}
+		if err := writeFileAtomic(path, sealed); err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// parseEncryptedConfig decodes an encrypted config file, reporting false if
+// the file is not encrypted
+func parseEncryptedConfig(data []byte) (encryptedConfig, bool) {
+	var sealed encryptedConfig
+	if json.Unmarshal(data, &sealed) != nil || sealed.Cipher == "" {
+		return encryptedConfig{}, false
+	}
+	return sealed, true
+}
+
+// decryptConfig returns the plaintext of a config file, which is data itself
+// unless the file is encrypted
+func (fcs *FileConfigStore) decryptConfig(data []byte) ([]byte, error) {
+	plaintext, err := fcs.unsealConfig(data)
+	fcs.locked = err != nil
+	return plaintext, err
+}
+
+// unsealConfig returns the plaintext of an encrypted config file or snapshot,
+// which is data itself unless it is encrypted
+func (fcs *FileConfigStore) unsealConfig(data []byte) ([]byte, error) {
+	sealed, ok := parseEncryptedConfig(data)
+	if !ok {
+		return data, nil
+	}
+
+	if sealed.Cipher != configCipher {
+		return nil, fmt.Errorf("unsupported config cipher %q", sealed.Cipher)
+	}
+	aead, err := fcs.configAEAD()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, sealed.Nonce, sealed.Data, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt config: wrong key or corrupted file")
+	}
+	return plaintext, nil
+}
+
+// encryptConfig seals a config file's contents with the config key
+func (fcs *FileConfigStore) encryptConfig(data []byte) ([]byte, error) {
+	aead, err := fcs.configAEAD()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(encryptedConfig{
+		Cipher: configCipher,
+		Nonce:  nonce,
+		Data:   aead.Seal(nil, nonce, data, nil),
+	}, "", "  ")
+}
+
+// configAEAD returns the cipher for the config key
+func (fcs *FileConfigStore) configAEAD() (cipher.AEAD, error) {
+	if fcs.key == nil {
+		return nil, errors.New("config is encrypted but no key is available")
+	}
+	key, err := fcs.key()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config key: %w", err)
+	}
+	if len(key) != ConfigKeySize {
+		return nil, fmt.Errorf("config key must be %d bytes, got %d", ConfigKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,302 @@
+// Package store provides a history of config snapshots for undo and rollback.
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// HistorySize is the number of config snapshots kept
+const HistorySize = 20
+
+// historyTimeFormat names snapshot files so they sort by time
+const historyTimeFormat = "20060102T150405.000000000Z"
+
+// ErrNoHistory is returned when there is no earlier config to go back to
+var ErrNoHistory = errors.New("no earlier config in the history")
+
+// Snapshot is a version of the config kept in the history
+type Snapshot struct {
+	ID   string
+	Time time.Time
+
+	// Tunnels is the number of tunnels, or -1 if the snapshot cannot be read
+	Tunnels int
+}
+
+// historySnapshot is how a snapshot is stored: the whole config, including
+// the tunnels of included files and databases, and the files its tunnels and
+// profiles came from
+type historySnapshot struct {
+	Config         json.RawMessage `json:"config"`
+	TunnelSources  []string        `json:"tunnelSources,omitempty"`
+	ProfileSources []string        `json:"profileSources,omitempty"`
+}
+
+// historyDir returns the directory of the config's snapshots in the state
+// directory, one per config file
+func (fcs *FileConfigStore) historyDir() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.Abs(fcs.configPath)
+	if err != nil {
+		path = fcs.configPath
+	}
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(stateDir, "history", hex.EncodeToString(sum[:6])), nil
+}
+
+// History returns the snapshots of the config, newest first
+func (fcs *FileConfigStore) History() ([]Snapshot, error) {
+	dir, names, err := fcs.historyFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := make([]Snapshot, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		snapshot := snapshotFromName(names[i])
+		if config, err := fcs.readSnapshot(filepath.Join(dir, names[i])); err == nil {
+			snapshot.Tunnels = len(config.Tunnels)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// historyFiles returns the history directory and its snapshot files, oldest first
+func (fcs *FileConfigStore) historyFiles() (string, []string, error) {
+	dir, err := fcs.historyDir()
+	if err != nil {
+		return "", nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("failed to read config history: %w", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasPrefix(name, "config-") && strings.HasSuffix(name, ".json") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return dir, names, nil
+}
+
+// recordHistory adds a snapshot of config to the history, unless it equals
+// the newest one, and drops snapshots beyond HistorySize. The snapshot is
+// encrypted like the config file.
+func (fcs *FileConfigStore) recordHistory(config *AppConfig, encrypted bool) error {
+	dir, names, err := fcs.historyFiles()
+	if err != nil {
+		return err
+	}
+	plaintext, err := encodeSnapshot(config)
+	if err != nil {
+		return err
+	}
+	if len(names) > 0 {
+		if newest, err := fcs.readSnapshotData(filepath.Join(dir, names[len(names)-1])); err == nil && bytes.Equal(newest, plaintext) {
+			return nil
+		}
+	}
+
+	data := plaintext
+	if encrypted {
+		if data, err = fcs.encryptConfig(plaintext); err != nil {
+			return fmt.Errorf("failed to encrypt config snapshot: %w", err)
+		}
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create config history: %w", err)
+	}
+	name := "config-" + time.Now().UTC().Format(historyTimeFormat) + ".json"
+	if err := writeFileAtomic(filepath.Join(dir, name), data); err != nil {
+		return fmt.Errorf("failed to save config snapshot: %w", err)
+	}
+
+	names = append(names, name)
+	for len(names) > HistorySize {
+		os.Remove(filepath.Join(dir, names[0]))
+		names = names[1:]
+	}
+	return nil
+}
+
+// seedHistory records a loaded config when the history is empty, so the
+// first change made afterwards can be undone
+func (fcs *FileConfigStore) seedHistory(config *AppConfig, encrypted bool) {
+	if _, names, err := fcs.historyFiles(); err == nil && len(names) == 0 {
+		_ = fcs.recordHistory(config, encrypted)
+	}
+}
+
+// Rollback restores the config of a snapshot from the history. The restored
+// config becomes the newest snapshot, so a rollback can itself be undone.
+func (fcs *FileConfigStore) Rollback(id string) error {
+	lock, err := lockFile(fcs.configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	dir, names, err := fcs.historyFiles()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if name == "config-"+id+".json" {
+			config, err := fcs.readSnapshot(filepath.Join(dir, name))
+			if err != nil {
+				return err
+			}
+			return fcs.writeConfigLocked(config, fcs.Encrypted())
+		}
+	}
+	return fmt.Errorf("no config snapshot %q in the history", id)
+}
+
+// Undo restores the config before the last change and returns its snapshot.
+// A config changed since the newest snapshot, as by editing the file, is
+// restored to that snapshot; otherwise the newest snapshot is dropped and
+// the one before it restored, so repeated undos go further back.
+func (fcs *FileConfigStore) Undo() (*Snapshot, error) {
+	lock, err := lockFile(fcs.configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	current, err := fcs.loadConfig(true)
+	if err != nil {
+		return nil, err
+	}
+	currentData, err := encodeSnapshot(current)
+	if err != nil {
+		return nil, err
+	}
+	dir, names, err := fcs.historyFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, ErrNoHistory
+	}
+
+	newest := filepath.Join(dir, names[len(names)-1])
+	if data, err := fcs.readSnapshotData(newest); err == nil && bytes.Equal(data, currentData) {
+		if len(names) < 2 {
+			return nil, ErrNoHistory
+		}
+		if err := os.Remove(newest); err != nil {
+			return nil, fmt.Errorf("failed to drop config snapshot: %w", err)
+		}
+		names = names[:len(names)-1]
+	}
+
+	name := names[len(names)-1]
+	config, err := fcs.readSnapshot(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	if err := fcs.writeConfigLocked(config, fcs.Encrypted()); err != nil {
+		return nil, err
+	}
+
+	snapshot := snapshotFromName(name)
+	snapshot.Tunnels = len(config.Tunnels)
+	return &snapshot, nil
+}
+
+// snapshotFromName describes a snapshot file by its name
+func snapshotFromName(name string) Snapshot {
+	id := strings.TrimSuffix(strings.TrimPrefix(name, "config-"), ".json")
+	snapshot := Snapshot{ID: id, Tunnels: -1}
+	snapshot.Time, _ = time.Parse(historyTimeFormat, id)
+	return snapshot
+}
+
+// readSnapshot reads the config of a snapshot file
+func (fcs *FileConfigStore) readSnapshot(path string) (*AppConfig, error) {
+	data, err := fcs.readSnapshotData(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSnapshot(data)
+}
+
+// readSnapshotData reads a snapshot file, decrypting it if it is encrypted
+func (fcs *FileConfigStore) readSnapshotData(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config snapshot: %w", err)
+	}
+	return fcs.unsealConfig(data)
+}
+
+// encodeSnapshot encodes a config for the history. Tunnels are sorted by ID,
+// as saves write them, so a config whose tunnels were only reordered by hand
+// encodes the same.
+func encodeSnapshot(config *AppConfig) ([]byte, error) {
+	sorted := *config
+	sorted.Tunnels = append(make([]TunnelConfig, 0, len(config.Tunnels)), config.Tunnels...)
+	sort.SliceStable(sorted.Tunnels, func(i, j int) bool {
+		return sorted.Tunnels[i].ID < sorted.Tunnels[j].ID
+	})
+
+	data, err := json.Marshal(&sorted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config snapshot: %w", err)
+	}
+	snapshot := historySnapshot{Config: data}
+	sourced := false
+	for _, tc := range sorted.Tunnels {
+		snapshot.TunnelSources = append(snapshot.TunnelSources, tc.Source)
+		sourced = sourced || tc.Source != ""
+	}
+	for _, p := range sorted.Profiles {
+		snapshot.ProfileSources = append(snapshot.ProfileSources, p.Source)
+		sourced = sourced || p.Source != ""
+	}
+	if !sourced {
+		snapshot.TunnelSources, snapshot.ProfileSources = nil, nil
+	}
+	return json.MarshalIndent(snapshot, "", "  ")
+}
+
+// decodeSnapshot decodes a config from the history, migrating it if it is
+// from an older version
+func decodeSnapshot(data []byte) (*AppConfig, error) {
+	var snapshot historySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse config snapshot: %w", err)
+	}
+	config, err := ParseConfig(snapshot.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config snapshot: %w", err)
+	}
+	for i := range config.Tunnels {
+		if i < len(snapshot.TunnelSources) {
+			config.Tunnels[i].Source = snapshot.TunnelSources[i]
+		}
+	}
+	for i := range config.Profiles {
+		if i < len(snapshot.ProfileSources) {
+			config.Profiles[i].Source = snapshot.ProfileSources[i]
+		}
+	}
+	return config, nil
+}
@@ -0,0 +1,188 @@
+// Package store provides config files split across included files.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+// loadIncludes adds the tunnels and profiles of the files a config includes,
+// recording which file each came from. Profiles declared in the main file
+// take precedence over included ones of the same name. Included files are
+// read in plaintext, and their own includes and defaults are ignored.
+func (fcs *FileConfigStore) loadIncludes(config *AppConfig) error {
+	fcs.mu.Lock()
+	fcs.includes = config.Includes
+	fcs.mu.Unlock()
+
+	paths, err := fcs.includePaths(config.Includes)
+	if err != nil {
+		return err
+	}
+
+	declared := make(map[string]bool)
+	for _, p := range config.Profiles {
+		declared[p.Name] = true
+	}
+
+	for _, path := range paths {
+		included, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("include %s: %w", path, err)
+		}
+		source := fcs.includeSource(path)
+		for _, tc := range included.Tunnels {
+			tc.Source = source
+			config.Tunnels = append(config.Tunnels, tc)
+		}
+		for _, p := range included.Profiles {
+			if declared[p.Name] {
+				continue
+			}
+			declared[p.Name] = true
+			p.Source = source
+			config.Profiles = append(config.Profiles, p)
+		}
+	}
+	return nil
+}
+
+// lastIncludes returns the include patterns of the config when last loaded
+func (fcs *FileConfigStore) lastIncludes() []string {
+	fcs.mu.Lock()
+	defer fcs.mu.Unlock()
+	return fcs.includes
+}
+
+// includePaths resolves include patterns to existing files in the order
+// given, each pattern's matches sorted by name. A pattern matching nothing is
+// not an error, so a shared main config works where an include is missing.
+func (fcs *FileConfigStore) includePaths(patterns []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{fcs.configPath: true}
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(fcs.configPath), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include %q: %w", pattern, err)
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	return paths, nil
+}
+
+// includeSource returns how an included file is shown: relative to the
+// config directory when it is inside it
+func (fcs *FileConfigStore) includeSource(path string) string {
+	if rel, err := filepath.Rel(filepath.Dir(fcs.configPath), path); err == nil && filepath.IsLocal(rel) {
+		return rel
+	}
+	return path
+}
+
+// includePath returns the file of a source recorded by loadIncludes
+func (fcs *FileConfigStore) includePath(source string) string {
+	if filepath.IsAbs(source) {
+		return source
+	}
+	return filepath.Join(filepath.Dir(fcs.configPath), source)
+}
+
+// saveIncludes writes the tunnels and profiles loaded from included files
+// back to them and returns the config left for the main file. An included
+// file is only rewritten if its tunnels or profiles changed, keeping its
+// other settings.
+func (fcs *FileConfigStore) saveIncludes(config *AppConfig) (*AppConfig, error) {
+	main := *config
+	main.Tunnels = nil
+	main.Profiles = nil
+
+	included := make(map[string]*AppConfig)
+	var order []string
+	part := func(source string) *AppConfig {
+		if source == "" {
+			return &main
+		}
+		if included[source] == nil {
+			included[source] = &AppConfig{}
+			order = append(order, source)
+		}
+		return included[source]
+	}
+	for _, tc := range config.Tunnels {
+		p := part(tc.Source)
+		tc.Source = ""
+		p.Tunnels = append(p.Tunnels, tc)
+	}
+	for _, profile := range config.Profiles {
+		p := part(profile.Source)
+		profile.Source = ""
+		p.Profiles = append(p.Profiles, profile)
+	}
+
+	for _, source := range order {
+		if err := fcs.writeInclude(fcs.includePath(source), included[source]); err != nil {
+			return nil, fmt.Errorf("failed to save include %s: %w", source, err)
+		}
+	}
+	return &main, nil
+}
+
+// writeInclude replaces the tunnels and profiles of an included file
+func (fcs *FileConfigStore) writeInclude(path string, part *AppConfig) error {
+	existing, err := readConfigFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		existing, err = &AppConfig{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.MigratedFrom == "" && reflect.DeepEqual(existing.Tunnels, part.Tunnels) && reflect.DeepEqual(existing.Profiles, part.Profiles) {
+		return nil
+	}
+
+	existing.Version = ConfigVersion
+	existing.Tunnels = part.Tunnels
+	existing.Profiles = part.Profiles
+	data, err := json.MarshalIndent(existing, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// readConfigFile reads and parses a plaintext config file
+func readConfigFile(path string) (*AppConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(data)
+}
+
+// writeFileAtomic replaces a file through a temporary file, so readers never
+// see it half written
+func writeFileAtomic(path string, data []byte) error {
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Package store provides advisory file locks shared between processes.
+package store
+
+import (
+	"fmt"
+	"os"
+)
+
+// fileLock is an exclusive advisory lock on a file, held across processes
+// so two tunnelman instances, or the TUI and the CLI, do not interleave
+// their read-modify-write of the same store
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile takes the lock guarding path, waiting while another process holds
+// it. The lock is on a separate path.lock file, because stores replace their
+// files by renaming, which would leave a lock on the file itself behind.
+func lockFile(path string) (*fileLock, error) {
+	file, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	if err := lockExclusive(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+	return &fileLock{file: file}, nil
+}
+
+// Unlock releases the lock
+func (l *fileLock) Unlock() {
+	_ = unlockExclusive(l.file)
+	l.file.Close()
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+// Package store provides advisory file locks on Unix-like systems.
+package store
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockExclusive waits for an exclusive flock on file
+func lockExclusive(file *os.File) error {
+	for {
+		err := unix.Flock(int(file.Fd()), unix.LOCK_EX)
+		if err != unix.EINTR {
+			return err
+		}
+	}
+}
+
+// unlockExclusive releases the flock on file
+func unlockExclusive(file *os.File) error {
+	return unix.Flock(int(file.Fd()), unix.LOCK_UN)
+}
@@ -0,0 +1,121 @@
+// Package store provides migration of config files written by older versions.
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ConfigVersion is the config file format this version writes. Version 2
+// stores the remote host of a tunnel's main forward, and spells out the bind
+// address and forward mode that version 1 left implied.
+const ConfigVersion = "2.0"
+
+// ErrConfigTooNew is returned for config files written by a newer version of
+// tunnelman, which this version cannot read without losing settings
+var ErrConfigTooNew = errors.New("config was written by a newer version of tunnelman")
+
+// configMigration upgrades a config from one major version to the next
+type configMigration struct {
+	from    int
+	migrate func(config *AppConfig)
+}
+
+// configMigrations are the steps from each old major version to the next,
+// applied in order; the last one leads to ConfigVersion
+var configMigrations = []configMigration{
+	{from: 1, migrate: migrateV1},
+}
+
+// ParseConfig decodes a config file, migrating one written in an older
+// format step by step. Files from a newer version are refused.
+func ParseConfig(data []byte) (*AppConfig, error) {
+	var config AppConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	from := config.Version
+	if from == "" {
+		from = "1.0"
+	}
+	major, current := configMajorVersion(from), configMajorVersion(ConfigVersion)
+	if major > current {
+		return nil, fmt.Errorf("%w (version %s, this one reads up to %s); upgrade tunnelman to use it", ErrConfigTooNew, from, ConfigVersion)
+	}
+	if major == current {
+		return &config, nil
+	}
+
+	for _, step := range configMigrations {
+		if step.from >= major {
+			step.migrate(&config)
+		}
+	}
+	config.Version = ConfigVersion
+	config.MigratedFrom = from
+	return &config, nil
+}
+
+// configMajorVersion returns the major version of a config file, treating a
+// malformed version as 1
+func configMajorVersion(version string) int {
+	major, _, _ := strings.Cut(version, ".")
+	if n, err := strconv.Atoi(major); err == nil && n > 0 {
+		return n
+	}
+	return 1
+}
+
+// MigrationBackupPath returns where the config file is kept as it was before
+// being migrated from a version
+func (fcs *FileConfigStore) MigrationBackupPath(version string) string {
+	return fmt.Sprintf("%s.v%s.backup", fcs.configPath, version)
+}
+
+// saveMigratedConfig writes a migrated config, first keeping the original
+// file data as a backup
+func (fcs *FileConfigStore) saveMigratedConfig(config *AppConfig, original []byte, holdsLock bool) error {
+	if err := os.WriteFile(fcs.MigrationBackupPath(config.MigratedFrom), original, 0644); err != nil {
+		return fmt.Errorf("failed to back up config before migrating: %w", err)
+	}
+	save := fcs.SaveConfig
+	if holdsLock {
+		save = func(config *AppConfig) error {
+			return fcs.writeConfigLocked(config, fcs.Encrypted())
+		}
+	}
+	if err := save(config); err != nil {
+		return fmt.Errorf("failed to save migrated config: %w", err)
+	}
+	return nil
+}
+
+// migrateV1 makes the settings version 1 tunnels implied explicit
+func migrateV1(config *AppConfig) {
+	for i := range config.Tunnels {
+		tc := &config.Tunnels[i]
+		switch tc.Mode {
+		case "forward":
+			tc.Mode = "local"
+		case "reverse":
+			tc.Mode = "remote"
+		}
+
+		// Version 1 did not store the bind address at first, and always
+		// listened on all interfaces then
+		if tc.BindAddress == "" {
+			tc.BindAddress = "0.0.0.0"
+		}
+
+		// Version 1 did not store the remote host, so local forwards went
+		// to the SSH host itself
+		if tc.Mode == "local" && tc.RemoteHost == "" {
+			tc.RemoteHost = "127.0.0.1"
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
-	"syscall"
 	"time"
 )
 
@@ -31,10 +30,25 @@ func NewFilePidStore() (*FilePidStore, error) {
 
 // getPidPath returns the PID file path based on XDG Base Directory Specification
 func getPidPath() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "pids.json"), nil
+}
+
+// StateDirEnv is the environment variable that overrides the state directory
+const StateDirEnv = "TUNNELMAN_STATE_DIR"
+
+// getStateDir returns the state directory based on XDG Base Directory Specification
+func getStateDir() (string, error) {
 	var stateDir string
 
-	switch runtime.GOOS {
-	case "windows":
+	switch {
+	case os.Getenv(StateDirEnv) != "":
+		stateDir = os.Getenv(StateDirEnv)
+
+	case runtime.GOOS == "windows":
 		// Windows: Use %LocalAppData%
 		localAppData := os.Getenv("LOCALAPPDATA")
 		if localAppData == "" {
@@ -64,11 +78,41 @@ func getPidPath() (string, error) {
 		return "", fmt.Errorf("failed to create state directory: %w", err)
 	}
 
-	return filepath.Join(stateDir, "pids.json"), nil
+	return stateDir, nil
 }
 
-// LoadPids loads all stored PIDs from the XDG-compliant state file
+// LoadPids loads all stored PIDs from the XDG-compliant state file, dropping
+// entries whose process has exited
 func (fps *FilePidStore) LoadPids() (*PidData, error) {
+	pidData, err := fps.readPids()
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up stale PIDs (processes that no longer exist)
+	cleanedData := &PidData{
+		Pids: make(map[string]PidInfo),
+	}
+	for tunnelID, entry := range pidData.Pids {
+		if IsProcessRunning(entry.PID) {
+			cleanedData.Pids[tunnelID] = entry
+		}
+	}
+
+	// Remove them from the store asynchronously. They are dropped from the
+	// file as it is then, since saving cleanedData could lose a PID added
+	// meanwhile.
+	if len(cleanedData.Pids) != len(pidData.Pids) {
+		go func() {
+			_, _ = fps.CleanupStalePids()
+		}()
+	}
+
+	return cleanedData, nil
+}
+
+// readPids reads the PID file as stored, without checking the processes
+func (fps *FilePidStore) readPids() (*PidData, error) {
 	fps.mu.RLock()
 	defer fps.mu.RUnlock()
 
@@ -95,25 +139,7 @@ func (fps *FilePidStore) LoadPids() (*PidData, error) {
 		pidData.Pids = make(map[string]PidInfo)
 	}
 
-	// Clean up stale PIDs (processes that no longer exist)
-	cleanedData := &PidData{
-		Pids: make(map[string]PidInfo),
-	}
-	for tunnelID, entry := range pidData.Pids {
-		if isProcessRunning(entry.PID) {
-			cleanedData.Pids[tunnelID] = entry
-		}
-	}
-
-	// Save cleaned store if any PIDs were removed
-	if len(cleanedData.Pids) != len(pidData.Pids) {
-		// Save cleaned store asynchronously
-		go func() {
-			_ = fps.SavePids(cleanedData)
-		}()
-	}
-
-	return cleanedData, nil
+	return &pidData, nil
 }
 
 // SavePids saves all PIDs to the XDG-compliant state file
@@ -122,6 +148,37 @@ func (fps *FilePidStore) SavePids(pidData *PidData) error {
 		return fmt.Errorf("pidData cannot be nil")
 	}
 
+	lock, err := lockFile(fps.filePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	return fps.writePids(pidData)
+}
+
+// updatePids applies fn to the stored PIDs and saves them if it reports a
+// change, holding the lock file throughout so that an update by another
+// tunnelman process in between is not lost
+func (fps *FilePidStore) updatePids(fn func(pidData *PidData) bool) error {
+	lock, err := lockFile(fps.filePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	pidData, err := fps.readPids()
+	if err != nil {
+		return fmt.Errorf("failed to load PIDs: %w", err)
+	}
+	if !fn(pidData) {
+		return nil
+	}
+	return fps.writePids(pidData)
+}
+
+// writePids replaces the PID file; the caller must hold the lock file
+func (fps *FilePidStore) writePids(pidData *PidData) error {
 	fps.mu.Lock()
 	defer fps.mu.Unlock()
 
@@ -157,30 +214,53 @@ func (fps *FilePidStore) SavePids(pidData *PidData) error {
 	return nil
 }
 
+// dropStalePids removes the entries of processes that are no longer running
+// and returns them keyed by tunnel ID
+func dropStalePids(pidData *PidData) map[string]PidInfo {
+	removed := make(map[string]PidInfo)
+	for tunnelID, entry := range pidData.Pids {
+		if !IsProcessRunning(entry.PID) {
+			delete(pidData.Pids, tunnelID)
+			removed[tunnelID] = entry
+		}
+	}
+	return removed
+}
+
 // AddPid adds a new PID entry for a tunnel
 func (fps *FilePidStore) AddPid(tunnelID string, pid int) error {
-	pidData, err := fps.LoadPids()
-	if err != nil {
-		return fmt.Errorf("failed to load PIDs: %w", err)
-	}
+	return fps.updatePids(func(pidData *PidData) bool {
+		dropStalePids(pidData)
+
+		// Create new PID entry
+		entry := NewPidInfo(pid, tunnelID)
+		pidData.Pids[tunnelID] = *entry
+		return true
+	})
+}
 
-	// Create new PID entry
-	entry := NewPidInfo(pid, tunnelID)
-	pidData.Pids[tunnelID] = *entry
+// AddPidStartedAt adds a PID entry for a tunnel whose process started at the
+// given time, such as a process started by an earlier tunnelman
+func (fps *FilePidStore) AddPidStartedAt(tunnelID string, pid int, started time.Time) error {
+	return fps.updatePids(func(pidData *PidData) bool {
+		dropStalePids(pidData)
 
-	return fps.SavePids(pidData)
+		entry := NewPidInfo(pid, tunnelID)
+		entry.Started = started.UTC().Format(time.RFC3339)
+		pidData.Pids[tunnelID] = *entry
+		return true
+	})
 }
 
 // RemovePid removes a PID entry for a tunnel
 func (fps *FilePidStore) RemovePid(tunnelID string) error {
-	pidData, err := fps.LoadPids()
-	if err != nil {
-		return fmt.Errorf("failed to load PIDs: %w", err)
-	}
-
-	delete(pidData.Pids, tunnelID)
-
-	return fps.SavePids(pidData)
+	return fps.updatePids(func(pidData *PidData) bool {
+		if _, exists := pidData.Pids[tunnelID]; !exists {
+			return false
+		}
+		delete(pidData.Pids, tunnelID)
+		return true
+	})
 }
 
 // GetPid retrieves a PID entry for a tunnel
@@ -196,7 +276,7 @@ func (fps *FilePidStore) GetPid(tunnelID string) (*PidInfo, error) {
 	}
 
 	// Verify the process is still running
-	if !isProcessRunning(entry.PID) {
+	if !IsProcessRunning(entry.PID) {
 		// Clean up stale entry
 		_ = fps.RemovePid(tunnelID)
 		return nil, fmt.Errorf("process %d is no longer running", entry.PID)
@@ -205,31 +285,22 @@ func (fps *FilePidStore) GetPid(tunnelID string) (*PidInfo, error) {
 	return &entry, nil
 }
 
-// CleanupStalePids removes PID entries for processes that are no longer running
-func (fps *FilePidStore) CleanupStalePids() (int, error) {
-	pidData, err := fps.LoadPids()
+// CleanupStalePids removes PID entries for processes that are no longer
+// running and returns the removed entries keyed by tunnel ID
+func (fps *FilePidStore) CleanupStalePids() (map[string]PidInfo, error) {
+	var removed map[string]PidInfo
+	err := fps.updatePids(func(pidData *PidData) bool {
+		removed = dropStalePids(pidData)
+		return len(removed) > 0
+	})
 	if err != nil {
-		return 0, fmt.Errorf("failed to load PIDs: %w", err)
-	}
-
-	cleaned := 0
-
-	// Check each PID and remove if process is not running
-	for tunnelID, entry := range pidData.Pids {
-		if !isProcessRunning(entry.PID) {
-			delete(pidData.Pids, tunnelID)
-			cleaned++
-		}
-	}
-
-	// Save if any PIDs were cleaned
-	if cleaned > 0 {
-		if err := fps.SavePids(pidData); err != nil {
-			return cleaned, fmt.Errorf("cleaned %d stale PIDs but failed to save: %w", cleaned, err)
+		if len(removed) > 0 {
+			return removed, fmt.Errorf("cleaned %d stale PIDs but failed to save: %w", len(removed), err)
 		}
+		return nil, err
 	}
 
-	return cleaned, nil
+	return removed, nil
 }
 
 // GetPidPath returns the current PID file path
@@ -237,40 +308,6 @@ func (fps *FilePidStore) GetPidPath() (string, error) {
 	return fps.filePath, nil
 }
 
-// isProcessRunning checks if a process with the given PID is still running
-func isProcessRunning(pid int) bool {
-	if pid <= 0 {
-		return false
-	}
-
-	if runtime.GOOS == "windows" {
-		// On Windows, use os.FindProcess
-		process, err := os.FindProcess(pid)
-		if err != nil {
-			return false
-		}
-		// On Windows, FindProcess always succeeds if PID is positive
-		// We need to send a signal to check if process exists
-		err = process.Signal(syscall.Signal(0))
-		return err == nil
-	} else {
-		// On Unix-like systems, send signal 0 to check if process exists
-		process, err := os.FindProcess(pid)
-		if err != nil {
-			return false
-		}
-		err = process.Signal(syscall.Signal(0))
-		if err != nil {
-			// Process doesn't exist, log for debugging if not expected error
-			if err != syscall.ESRCH && err != os.ErrProcessDone {
-				fmt.Fprintf(os.Stderr, "DEBUG: Failed to signal process %d: %v\n", pid, err)
-			}
-			return false
-		}
-		return true
-	}
-}
-
 // Helper functions for backward compatibility
 
 // LoadPids loads PIDs using default path
@@ -319,10 +356,10 @@ func GetPid(tunnelID string) (*PidInfo, error) {
 }
 
 // CleanupStalePids cleans up stale PIDs using default path
-func CleanupStalePids() (int, error) {
+func CleanupStalePids() (map[string]PidInfo, error) {
 	store, err := NewFilePidStore()
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	return store.CleanupStalePids()
 }
@@ -332,6 +369,68 @@ func GetPidPath() (string, error) {
 	return getPidPath()
 }
 
+// GetStateDir returns the default state directory
+func GetStateDir() (string, error) {
+	return getStateDir()
+}
+
+// GetSocketPath returns the control socket path used by the daemon
+func GetSocketPath() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "tunnelman.sock"), nil
+}
+
+// GetLogDir returns the directory holding per-tunnel SSH output logs
+func GetLogDir() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "logs"), nil
+}
+
+// GetControlDir returns the directory holding control sockets of multiplexed tunnels
+func GetControlDir() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "mux"), nil
+}
+
+// GetHostKeyDir returns the directory holding known_hosts files of tunnels
+// with pinned host keys
+func GetHostKeyDir() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "known_hosts"), nil
+}
+
+// GetAskpassDir returns the directory holding the scripts ssh runs to ask
+// tunnelman for passwords
+func GetAskpassDir() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "askpass"), nil
+}
+
+// GetAskpassSocketPath returns the socket on which this process answers
+// password prompts of the ssh processes it starts
+func GetAskpassSocketPath() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, fmt.Sprintf("askpass-%d.sock", os.Getpid())), nil
+}
+
 // GetRunningTunnelCount returns the number of tunnels with running processes
 func GetRunningTunnelCount() (int, error) {
 	pidData, err := LoadPids()
@@ -341,7 +440,7 @@ func GetRunningTunnelCount() (int, error) {
 
 	count := 0
 	for _, entry := range pidData.Pids {
-		if isProcessRunning(entry.PID) {
+		if IsProcessRunning(entry.PID) {
 			count++
 		}
 	}
@@ -360,7 +459,7 @@ func GetOldestRunningTunnel() (*PidInfo, error) {
 	var oldestTime time.Time
 
 	for _, entry := range pidData.Pids {
-		if !isProcessRunning(entry.PID) {
+		if !IsProcessRunning(entry.PID) {
 			continue
 		}
 
@@ -0,0 +1,75 @@
+// Package store provides JSON encoding of ports written as templates.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// portKeys are the JSON keys of the ports a tunnel config may write as strings
+var portKeys = []string{"localPort", "remotePort"}
+
+// UnmarshalJSON reads a tunnel config whose ports may be numbers or strings,
+// keeping strings in PortTemplates
+func (tc *TunnelConfig) UnmarshalJSON(data []byte) error {
+	type plain TunnelConfig
+	aux := struct {
+		*plain
+		LocalPort  json.RawMessage `json:"localPort"`
+		RemotePort json.RawMessage `json:"remotePort"`
+	}{plain: (*plain)(tc)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	tc.PortTemplates = nil
+	for i, raw := range []json.RawMessage{aux.LocalPort, aux.RemotePort} {
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		var port int
+		if err := json.Unmarshal(raw, &port); err == nil {
+			*tc.port(portKeys[i]) = port
+			continue
+		}
+		var template string
+		if err := json.Unmarshal(raw, &template); err != nil {
+			return fmt.Errorf("%s must be a number or a string: %s", portKeys[i], raw)
+		}
+		if tc.PortTemplates == nil {
+			tc.PortTemplates = make(map[string]string)
+		}
+		tc.PortTemplates[portKeys[i]] = template
+	}
+	return nil
+}
+
+// MarshalJSON writes a tunnel config with its port templates in place of the
+// port numbers
+func (tc TunnelConfig) MarshalJSON() ([]byte, error) {
+	type plain TunnelConfig
+	if len(tc.PortTemplates) == 0 {
+		return json.Marshal(plain(tc))
+	}
+
+	ports := make([]any, len(portKeys))
+	for i, key := range portKeys {
+		ports[i] = *tc.port(key)
+		if template, ok := tc.PortTemplates[key]; ok {
+			ports[i] = template
+		}
+	}
+	return json.Marshal(struct {
+		plain
+		LocalPort  any `json:"localPort"`
+		RemotePort any `json:"remotePort"`
+	}{plain(tc), ports[0], ports[1]})
+}
+
+// port returns the field of the port with the given JSON key
+func (tc *TunnelConfig) port(key string) *int {
+	if key == "remotePort" {
+		return &tc.RemotePort
+	}
+	return &tc.LocalPort
+}
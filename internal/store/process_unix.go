@@ -0,0 +1,32 @@
+//go:build !windows
+
+// Package store provides process liveness checks on Unix-like systems.
+package store
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// IsProcessRunning checks if a process with the given PID is still running
+func IsProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	// Send signal 0 to check if process exists
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	if err != nil {
+		// Process doesn't exist, log for debugging if not expected error
+		if err != syscall.ESRCH && err != os.ErrProcessDone {
+			fmt.Fprintf(os.Stderr, "DEBUG: Failed to signal process %d: %v\n", pid, err)
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+// Package store provides process liveness checks on Windows.
+package store
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process
+const stillActive = 259
+
+// IsProcessRunning checks if a process with the given PID is still running.
+// Windows cannot signal a process to probe it, so its exit code is queried.
+func IsProcessRunning(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,492 @@
+// Package store provides a SQLite storage backend for large configs.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Storage backends
+const (
+	StorageJSON   = "json"
+	StorageSQLite = "sqlite"
+)
+
+// DefaultDatabaseName is the SQLite database used when storage sets no path
+const DefaultDatabaseName = "tunnelman.db"
+
+// sqliteSchemaVersion is the schema version kept in the database's
+// user_version, so later versions can migrate it
+const sqliteSchemaVersion = 1
+
+// sqliteSchema creates the tables of the database. Tunnels and profiles are
+// kept as JSON with the columns they are looked up by, keyed by position so
+// the config order and duplicates repaired on load survive a round trip.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tunnels (
+	position INTEGER PRIMARY KEY,
+	id TEXT NOT NULL,
+	name TEXT NOT NULL,
+	profile TEXT NOT NULL,
+	config TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS tunnels_id ON tunnels (id);
+CREATE INDEX IF NOT EXISTS tunnels_profile ON tunnels (profile);
+CREATE TABLE IF NOT EXISTS profiles (
+	position INTEGER PRIMARY KEY,
+	name TEXT NOT NULL,
+	config TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS settings (
+	key TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS stats (
+	tunnel_id TEXT PRIMARY KEY,
+	connects INTEGER NOT NULL DEFAULT 0,
+	failures INTEGER NOT NULL DEFAULT 0,
+	restarts INTEGER NOT NULL DEFAULT 0,
+	uptime_seconds INTEGER NOT NULL DEFAULT 0,
+	last_connected TIMESTAMP,
+	last_failure TIMESTAMP
+);
+`
+
+// IsSQLite reports whether storage selects the SQLite backend
+func (s *Storage) IsSQLite() bool {
+	return s != nil && s.Backend == StorageSQLite
+}
+
+// Validate checks that storage names a known backend
+func (s *Storage) Validate() error {
+	if s == nil {
+		return nil
+	}
+	switch s.Backend {
+	case StorageJSON, StorageSQLite:
+		return nil
+	default:
+		return fmt.Errorf("unknown storage backend %q (want %s or %s)", s.Backend, StorageJSON, StorageSQLite)
+	}
+}
+
+// SQLiteStore keeps tunnels, profiles, defaults and statistics in a SQLite
+// database, for configs with hundreds of tunnels and their history
+type SQLiteStore struct {
+	mu   sync.Mutex
+	db   *sql.DB
+	path string
+}
+
+// OpenSQLiteStore opens or creates a SQLite store. A new database takes
+// over the statistics kept in the state directory.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// A single connection serializes writers within the process
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStore{db: db, path: path}
+	created, err := s.createSchema()
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize database %s: %w", path, err)
+	}
+	if created {
+		if err := s.importFileStats(); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Path returns the database file
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// createSchema creates the tables of a new database, reporting whether it
+// was new, and refuses a database from a newer version
+func (s *SQLiteStore) createSchema() (bool, error) {
+	var version int
+	if err := s.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return false, err
+	}
+	if version > sqliteSchemaVersion {
+		return false, fmt.Errorf("database schema %d is newer than supported %d", version, sqliteSchemaVersion)
+	}
+	if version == sqliteSchemaVersion {
+		return false, nil
+	}
+	if _, err := s.db.Exec(sqliteSchema); err != nil {
+		return false, err
+	}
+	if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", sqliteSchemaVersion)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// importFileStats copies the statistics file of the state directory into a
+// new database
+func (s *SQLiteStore) importFileStats() error {
+	fileStats, err := NewFileStatsStore()
+	if err != nil {
+		return nil
+	}
+	statsData, err := fileStats.Load()
+	if err != nil {
+		return fmt.Errorf("failed to import statistics: %w", err)
+	}
+	for id, stats := range statsData.Stats {
+		if err := s.writeStats(s.db, id, stats); err != nil {
+			return fmt.Errorf("failed to import statistics: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadConfig fills the tunnels, profiles and defaults of config from the database
+func (s *SQLiteStore) loadConfig(config *AppConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tunnels, err := queryJSON[TunnelConfig](s.db, "SELECT config FROM tunnels ORDER BY position")
+	if err != nil {
+		return fmt.Errorf("failed to read tunnels: %w", err)
+	}
+	profiles, err := queryJSON[Profile](s.db, "SELECT config FROM profiles ORDER BY position")
+	if err != nil {
+		return fmt.Errorf("failed to read profiles: %w", err)
+	}
+	config.Tunnels = append([]TunnelConfig{}, tunnels...)
+	config.Profiles = profiles
+
+	config.Defaults = nil
+	var defaults string
+	err = s.db.QueryRow("SELECT value FROM settings WHERE key = 'defaults'").Scan(&defaults)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return fmt.Errorf("failed to read defaults: %w", err)
+	default:
+		if err := json.Unmarshal([]byte(defaults), &config.Defaults); err != nil {
+			return fmt.Errorf("failed to parse defaults: %w", err)
+		}
+	}
+	return nil
+}
+
+// queryJSON decodes the JSON column of each row a query returns
+func queryJSON[T any](db *sql.DB, query string) ([]T, error) {
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []T
+	for rows.Next() {
+		var data string
+		var value T
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// empty reports whether the database holds no tunnels, profiles or defaults
+func (s *SQLiteStore) empty() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int
+	err := s.db.QueryRow("SELECT (SELECT COUNT(*) FROM tunnels) + (SELECT COUNT(*) FROM profiles) + (SELECT COUNT(*) FROM settings)").Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to read database: %w", err)
+	}
+	return count == 0, nil
+}
+
+// saveConfig replaces the tunnels, profiles and defaults in the database
+// with those of config in a single transaction
+func (s *SQLiteStore) saveConfig(config *AppConfig) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to save to database: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"tunnels", "profiles", "settings"} {
+		if _, err := tx.Exec("DELETE FROM " + table); err != nil {
+			return fmt.Errorf("failed to save to database: %w", err)
+		}
+	}
+
+	for i, tc := range config.Tunnels {
+		tc.Source = ""
+		data, err := json.Marshal(tc)
+		if err != nil {
+			return fmt.Errorf("failed to marshal tunnel: %w", err)
+		}
+		if _, err := tx.Exec("INSERT INTO tunnels (position, id, name, profile, config) VALUES (?, ?, ?, ?, ?)",
+			i, tc.ID, tc.Name, tc.Profile, string(data)); err != nil {
+			return fmt.Errorf("failed to save tunnel %s: %w", tc.Name, err)
+		}
+	}
+
+	for i, p := range config.Profiles {
+		p.Source = ""
+		data, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("failed to marshal profile: %w", err)
+		}
+		if _, err := tx.Exec("INSERT INTO profiles (position, name, config) VALUES (?, ?, ?)",
+			i, p.Name, string(data)); err != nil {
+			return fmt.Errorf("failed to save profile %s: %w", p.Name, err)
+		}
+	}
+
+	if config.Defaults != nil {
+		data, err := json.Marshal(config.Defaults)
+		if err != nil {
+			return fmt.Errorf("failed to marshal defaults: %w", err)
+		}
+		if _, err := tx.Exec("INSERT INTO settings (key, value) VALUES ('defaults', ?)", string(data)); err != nil {
+			return fmt.Errorf("failed to save defaults: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to save to database: %w", err)
+	}
+	return nil
+}
+
+// Load returns the statistics of all tunnels
+func (s *SQLiteStore) Load() (*StatsData, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows, err := s.db.Query("SELECT tunnel_id, connects, failures, restarts, uptime_seconds, last_connected, last_failure FROM stats")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	defer rows.Close()
+
+	statsData := &StatsData{Stats: make(map[string]TunnelStats)}
+	for rows.Next() {
+		var id string
+		stats, err := scanStats(rows, &id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read stats: %w", err)
+		}
+		statsData.Stats[id] = stats
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stats: %w", err)
+	}
+	return statsData, nil
+}
+
+// Update applies fn to the statistics of a tunnel and saves them
+func (s *SQLiteStore) Update(tunnelID string, fn func(stats *TunnelStats)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to update stats: %w", err)
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRow("SELECT tunnel_id, connects, failures, restarts, uptime_seconds, last_connected, last_failure FROM stats WHERE tunnel_id = ?", tunnelID)
+	var id string
+	stats, err := scanStats(row, &id)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to read stats: %w", err)
+	}
+	fn(&stats)
+	if err := s.writeStats(tx, tunnelID, stats); err != nil {
+		return fmt.Errorf("failed to update stats: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update stats: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the statistics of a tunnel
+func (s *SQLiteStore) Remove(tunnelID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.db.Exec("DELETE FROM stats WHERE tunnel_id = ?", tunnelID); err != nil {
+		return fmt.Errorf("failed to remove stats: %w", err)
+	}
+	return nil
+}
+
+// execer runs statements on a database or within a transaction
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// writeStats inserts or replaces the statistics of a tunnel
+func (s *SQLiteStore) writeStats(db execer, tunnelID string, stats TunnelStats) error {
+	_, err := db.Exec(`INSERT OR REPLACE INTO stats
+		(tunnel_id, connects, failures, restarts, uptime_seconds, last_connected, last_failure)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		tunnelID, stats.Connects, stats.Failures, stats.Restarts, stats.UptimeSeconds,
+		nullTime(stats.LastConnected), nullTime(stats.LastFailure))
+	return err
+}
+
+// scanStats reads a row of the stats table
+func scanStats(row interface{ Scan(...any) error }, id *string) (TunnelStats, error) {
+	var stats TunnelStats
+	var lastConnected, lastFailure sql.NullTime
+	if err := row.Scan(id, &stats.Connects, &stats.Failures, &stats.Restarts, &stats.UptimeSeconds, &lastConnected, &lastFailure); err != nil {
+		return TunnelStats{}, err
+	}
+	if lastConnected.Valid {
+		stats.LastConnected = &lastConnected.Time
+	}
+	if lastFailure.Valid {
+		stats.LastFailure = &lastFailure.Time
+	}
+	return stats, nil
+}
+
+// nullTime converts an optional time for the database
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// DatabasePath returns the database file of storage, relative paths being
+// relative to the config directory
+func (fcs *FileConfigStore) DatabasePath(storage *Storage) string {
+	path := storage.Path
+	if path == "" {
+		path = DefaultDatabaseName
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(fcs.configPath), path)
+	}
+	return path
+}
+
+// database returns the SQLite store of storage, opening it on first use
+func (fcs *FileConfigStore) database(storage *Storage) (*SQLiteStore, error) {
+	path := fcs.DatabasePath(storage)
+
+	fcs.mu.Lock()
+	defer fcs.mu.Unlock()
+	if fcs.db != nil && fcs.db.Path() == path {
+		return fcs.db, nil
+	}
+	db, err := OpenSQLiteStore(path)
+	if err != nil {
+		return nil, err
+	}
+	if fcs.db != nil {
+		fcs.db.Close()
+	}
+	fcs.db = db
+	return db, nil
+}
+
+// loadDatabase fills config from its SQLite database. A config file that
+// still holds tunnels, profiles or defaults, as when it was just switched to
+// SQLite by hand, keeps them while the database is empty, so they are moved
+// into it by the next save rather than lost.
+func (fcs *FileConfigStore) loadDatabase(config *AppConfig) error {
+	db, err := fcs.database(config.Storage)
+	if err != nil {
+		return err
+	}
+	if len(config.Tunnels) > 0 || len(config.Profiles) > 0 || config.Defaults != nil {
+		empty, err := db.empty()
+		if err != nil {
+			return err
+		}
+		if empty {
+			return nil
+		}
+	}
+	return db.loadConfig(config)
+}
+
+// saveDatabase saves the tunnels, profiles and defaults of the main config
+// part to its SQLite database and returns what is left for the config file
+func (fcs *FileConfigStore) saveDatabase(config *AppConfig) (*AppConfig, error) {
+	db, err := fcs.database(config.Storage)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.saveConfig(config); err != nil {
+		return nil, err
+	}
+	main := *config
+	main.Tunnels = []TunnelConfig{}
+	main.Profiles = nil
+	main.Defaults = nil
+	return &main, nil
+}
+
+// StatsStore returns where tunnel statistics are kept: the SQLite database
+// with the sqlite storage backend as last loaded, else the state directory
+func (fcs *FileConfigStore) StatsStore() (StatsStore, error) {
+	fcs.mu.Lock()
+	db, sqlite := fcs.db, fcs.sqlite
+	fcs.mu.Unlock()
+	if sqlite && db != nil {
+		return db, nil
+	}
+	return NewFileStatsStore()
+}
+
+// SetStorage moves the tunnels, profiles and defaults to a storage backend;
+// nil keeps them in the config file. Statistics already in a database stay
+// there.
+func (fcs *FileConfigStore) SetStorage(storage *Storage) error {
+	if err := storage.Validate(); err != nil {
+		return err
+	}
+	if storage != nil && storage.Backend == StorageJSON {
+		storage = nil
+	}
+	return fcs.updateConfig(func(config *AppConfig) (bool, error) {
+		encrypted := fcs.Encrypted()
+		if storage.IsSQLite() && encrypted {
+			return false, fmt.Errorf("an encrypted config cannot use sqlite storage; decrypt it first")
+		}
+		config.Storage = storage
+		return encrypted, nil
+	})
+}
@@ -0,0 +1,142 @@
+// Package store provides persistent per-tunnel connection statistics.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// TunnelStats holds the connection counters of a tunnel
+type TunnelStats struct {
+	Connects      int        `json:"connects,omitempty"`
+	Failures      int        `json:"failures,omitempty"`
+	Restarts      int        `json:"restarts,omitempty"`
+	UptimeSeconds int64      `json:"uptimeSeconds,omitempty"`
+	LastConnected *time.Time `json:"lastConnected,omitempty"`
+	LastFailure   *time.Time `json:"lastFailure,omitempty"`
+}
+
+// StatsData represents the statistics storage data
+type StatsData struct {
+	Stats map[string]TunnelStats `json:"stats"`
+}
+
+// StatsStore stores the connection statistics of tunnels
+type StatsStore interface {
+	// Load returns the statistics of all tunnels
+	Load() (*StatsData, error)
+
+	// Update applies fn to the statistics of a tunnel and saves them
+	Update(tunnelID string, fn func(stats *TunnelStats)) error
+
+	// Remove deletes the statistics of a tunnel
+	Remove(tunnelID string) error
+}
+
+// FileStatsStore stores tunnel statistics in the state directory
+type FileStatsStore struct {
+	mu       sync.Mutex
+	filePath string
+}
+
+// NewFileStatsStore creates a statistics store in the default state directory
+func NewFileStatsStore() (*FileStatsStore, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return nil, err
+	}
+	return &FileStatsStore{filePath: filepath.Join(stateDir, "stats.json")}, nil
+}
+
+// Load returns the statistics of all tunnels
+func (fss *FileStatsStore) Load() (*StatsData, error) {
+	fss.mu.Lock()
+	defer fss.mu.Unlock()
+	return fss.read()
+}
+
+// Update applies fn to the statistics of a tunnel and saves them, holding
+// the lock file so counts by other tunnelman processes are not lost
+func (fss *FileStatsStore) Update(tunnelID string, fn func(stats *TunnelStats)) error {
+	fss.mu.Lock()
+	defer fss.mu.Unlock()
+
+	lock, err := lockFile(fss.filePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	statsData, err := fss.read()
+	if err != nil {
+		return err
+	}
+	stats := statsData.Stats[tunnelID]
+	fn(&stats)
+	statsData.Stats[tunnelID] = stats
+	return fss.write(statsData)
+}
+
+// Remove deletes the statistics of a tunnel
+func (fss *FileStatsStore) Remove(tunnelID string) error {
+	fss.mu.Lock()
+	defer fss.mu.Unlock()
+
+	lock, err := lockFile(fss.filePath)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	statsData, err := fss.read()
+	if err != nil {
+		return err
+	}
+	if _, exists := statsData.Stats[tunnelID]; !exists {
+		return nil
+	}
+	delete(statsData.Stats, tunnelID)
+	return fss.write(statsData)
+}
+
+// read reads the statistics file; the caller must hold fss.mu
+func (fss *FileStatsStore) read() (*StatsData, error) {
+	data, err := os.ReadFile(fss.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &StatsData{Stats: make(map[string]TunnelStats)}, nil
+		}
+		return nil, fmt.Errorf("failed to read stats file: %w", err)
+	}
+
+	var statsData StatsData
+	if err := json.Unmarshal(data, &statsData); err != nil {
+		return nil, fmt.Errorf("failed to parse stats file: %w", err)
+	}
+	if statsData.Stats == nil {
+		statsData.Stats = make(map[string]TunnelStats)
+	}
+	return &statsData, nil
+}
+
+// write atomically replaces the statistics file; the caller must hold fss.mu
+func (fss *FileStatsStore) write(statsData *StatsData) error {
+	data, err := json.MarshalIndent(statsData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal stats: %w", err)
+	}
+
+	tempFile := fss.filePath + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write stats file: %w", err)
+	}
+	if err := os.Rename(tempFile, fss.filePath); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to save stats file: %w", err)
+	}
+	return nil
+}
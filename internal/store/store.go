@@ -7,15 +7,68 @@ import (
 
 // TunnelConfig represents a tunnel configuration for storage
 type TunnelConfig struct {
-	ID          string   `json:"id"`
-	Name        string   `json:"name"`
-	Host        string   `json:"host"`
-	LocalPort   int      `json:"localPort"`
-	RemotePort  int      `json:"remotePort"`
-	Mode        string   `json:"mode"`
-	Profile     string   `json:"profile,omitempty"`
-	Options     []string `json:"options,omitempty"`
-	AutoConnect bool     `json:"auto_connect,omitempty"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Host        string          `json:"host"`
+	JumpHost    string          `json:"jumpHost,omitempty"`
+	LocalPort   int             `json:"localPort"`
+	RemoteHost  string          `json:"remoteHost,omitempty"`
+	RemotePort  int             `json:"remotePort"`
+	Mode        string          `json:"mode"`
+	Profile     string          `json:"profile,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Options     []string        `json:"options,omitempty"`
+	Forwards    []ForwardConfig `json:"forwards,omitempty"`
+	AutoConnect bool            `json:"auto_connect,omitempty"`
+
+	BindAddress       string `json:"bindAddress,omitempty"`
+	RemoteBindAddress string `json:"remoteBindAddress,omitempty"`
+	Compression       bool   `json:"compression,omitempty"`
+	Multiplex         bool   `json:"multiplex,omitempty"`
+	IdentityFile      string `json:"identityFile,omitempty"`
+	Secret            string `json:"secret,omitempty"`
+	HostKey           string `json:"hostKey,omitempty"`
+
+	CertificateFile    string `json:"certificateFile,omitempty"`
+	CertificateCommand string `json:"certificateCommand,omitempty"`
+
+	ServerAliveInterval  int   `json:"serverAliveInterval,omitempty"`
+	ServerAliveCountMax  int   `json:"serverAliveCountMax,omitempty"`
+	ExitOnForwardFailure *bool `json:"exitOnForwardFailure,omitempty"`
+
+	Hooks *HookConfig `json:"hooks,omitempty"`
+
+	// ImportedFrom names what the tunnel was imported from, e.g.
+	// "ssh-config:db", so a re-import can update it
+	ImportedFrom string `json:"importedFrom,omitempty"`
+
+	// PortTemplates holds ports written as strings, e.g. "${DB_PORT}", by
+	// key (localPort or remotePort). They are expanded when tunnels are
+	// loaded and written in place of the port numbers.
+	PortTemplates map[string]string `json:"-"`
+
+	// Source is the included file the tunnel was loaded from, relative to
+	// the config directory, or empty for the main config file
+	Source string `json:"-"`
+}
+
+// HookConfig holds shell commands run when a tunnel connects, disconnects or fails
+type HookConfig struct {
+	OnConnect    string `json:"onConnect,omitempty"`
+	OnDisconnect string `json:"onDisconnect,omitempty"`
+	OnFailure    string `json:"onFailure,omitempty"`
+}
+
+// ForwardConfig represents an additional forward of a tunnel for storage
+type ForwardConfig struct {
+	Mode        string `json:"mode"`
+	BindAddress string `json:"bindAddress,omitempty"`
+	LocalPort   int    `json:"localPort"`
+	RemoteHost  string `json:"remoteHost,omitempty"`
+	RemotePort  int    `json:"remotePort,omitempty"`
+
+	RemoteBindAddress string `json:"remoteBindAddress,omitempty"`
 }
 
 // PidInfo represents process information for storage
@@ -31,6 +84,99 @@ type AppConfig struct {
 	Version  string         `json:"version"`
 	Tunnels  []TunnelConfig `json:"tunnels"`
 	Profiles []Profile      `json:"profiles,omitempty"`
+	Defaults *Defaults      `json:"defaults,omitempty"`
+
+	// Includes are files, or glob patterns of files, whose tunnels and
+	// profiles are added to the config, relative to the config directory
+	Includes []string `json:"includes,omitempty"`
+
+	// Storage selects where tunnels, profiles, defaults and statistics are
+	// kept; nil keeps them in the config file
+	Storage *Storage `json:"storage,omitempty"`
+
+	// MigratedFrom is the version the file was written in if it was
+	// migrated to ConfigVersion when loaded
+	MigratedFrom string `json:"-"`
+}
+
+// Storage selects a storage backend
+type Storage struct {
+	// Backend is "json" to keep everything in the config file, or "sqlite"
+	Backend string `json:"backend"`
+
+	// Path is the SQLite database, relative to the config directory;
+	// empty means tunnelman.db
+	Path string `json:"path,omitempty"`
+}
+
+// Defaults holds settings applied to tunnels that do not set their own
+type Defaults struct {
+	ServerAliveInterval  int   `json:"serverAliveInterval,omitempty"`
+	ServerAliveCountMax  int   `json:"serverAliveCountMax,omitempty"`
+	ExitOnForwardFailure *bool `json:"exitOnForwardFailure,omitempty"`
+
+	// SSHPath is the ssh executable; TUNNELMAN_SSH overrides it
+	SSHPath string `json:"sshPath,omitempty"`
+
+	// BindAddress is the address new tunnels listen on
+	BindAddress string `json:"bindAddress,omitempty"`
+
+	// StrictHostKeyChecking is ssh's policy for unknown host keys: ask,
+	// accept-new, yes or no. Empty asks when the TUI can prompt and accepts
+	// new keys otherwise.
+	StrictHostKeyChecking string `json:"strictHostKeyChecking,omitempty"`
+
+	// Reconnect sets how supervised tunnels are restarted
+	Reconnect *ReconnectPolicy `json:"reconnect,omitempty"`
+
+	// Hooks run for every tunnel, before the tunnel's own hooks
+	Hooks *HookConfig `json:"hooks,omitempty"`
+
+	// Notifications shows desktop notifications when tunnels drop or
+	// reconnect; nil means on
+	Notifications *bool `json:"notifications,omitempty"`
+
+	// Webhooks are posted to on tunnel status transitions
+	Webhooks []WebhookConfig `json:"webhooks,omitempty"`
+
+	// ASCII draws the TUI's icons and borders with ASCII characters for
+	// terminals and fonts that render the unicode ones badly
+	ASCII bool `json:"ascii,omitempty"`
+
+	// Colors is the color scheme of the TUI: default, high-contrast or
+	// color-blind
+	Colors string `json:"colors,omitempty"`
+
+	// Language is the language of the TUI, en or ja; empty follows LC_ALL,
+	// LC_MESSAGES or LANG
+	Language string `json:"language,omitempty"`
+}
+
+// ReconnectPolicy is the delay between restarts of a supervised tunnel, in
+// seconds. Zero values use the built-in policy.
+type ReconnectPolicy struct {
+	// InitialBackoff is the delay before the first restart, doubled on
+	// each further attempt
+	InitialBackoff int `json:"initialBackoff,omitempty"`
+
+	// MaxBackoff caps the delay
+	MaxBackoff int `json:"maxBackoff,omitempty"`
+
+	// StableAfter is how long a tunnel must stay up before the delay resets
+	StableAfter int `json:"stableAfter,omitempty"`
+}
+
+// WebhookConfig is an HTTP endpoint notified of tunnel status transitions
+type WebhookConfig struct {
+	URL string `json:"url"`
+
+	// Events limits the webhook to connect, disconnect or failure events; empty means all
+	Events []string `json:"events,omitempty"`
+
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Template is a Go text/template for the request body; empty posts the event as JSON
+	Template string `json:"template,omitempty"`
 }
 
 // Profile represents a named collection of tunnels
@@ -39,6 +185,31 @@ type Profile struct {
 	Description string   `json:"description,omitempty"`
 	TunnelIDs   []string `json:"tunnelIds"`
 	AutoConnect bool     `json:"autoConnect,omitempty"`
+
+	// StopPrevious stops the tunnels of the profile switched from when
+	// switching to this one
+	StopPrevious bool `json:"stopPrevious,omitempty"`
+
+	// Defaults are SSH settings the profile's tunnels inherit
+	Defaults *ProfileDefaults `json:"defaults,omitempty"`
+
+	// Source is the included file the profile was declared in, like
+	// TunnelConfig.Source
+	Source string `json:"-"`
+}
+
+// ProfileDefaults are SSH settings the tunnels of a profile use when they do
+// not set their own
+type ProfileDefaults struct {
+	JumpHost     string `json:"jumpHost,omitempty"`
+	IdentityFile string `json:"identityFile,omitempty"`
+
+	// Options are added after the tunnel's own options, which ssh lets
+	// take precedence
+	Options []string `json:"options,omitempty"`
+
+	ServerAliveInterval int `json:"serverAliveInterval,omitempty"`
+	ServerAliveCountMax int `json:"serverAliveCountMax,omitempty"`
 }
 
 // PidData represents the PID storage data
@@ -0,0 +1,59 @@
+// Package store provides the token clients present to the REST API.
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// APITokenEnv is the environment variable that sets the REST API token
+const APITokenEnv = "TUNNELMAN_API_TOKEN"
+
+// GetAPITokenPath returns the file holding the REST API token
+func GetAPITokenPath() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "api-token"), nil
+}
+
+// GetAPIToken returns the token clients of the REST API present to change
+// tunnels. It is taken from TUNNELMAN_API_TOKEN if set, otherwise from the
+// token file, which is created with a random token that only this user can
+// read on first use.
+func GetAPIToken() (string, error) {
+	if token := os.Getenv(APITokenEnv); token != "" {
+		return token, nil
+	}
+
+	path, err := GetAPITokenPath()
+	if err != nil {
+		return "", err
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read API token: %w", err)
+	}
+
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	token := hex.EncodeToString(b[:])
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("failed to create state directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write API token: %w", err)
+	}
+	return token, nil
+}
@@ -0,0 +1,78 @@
+// Package store provides the TUI choices kept between sessions.
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// UIState holds the TUI choices kept between sessions
+type UIState struct {
+	// SortBy is the field the tunnel list is sorted by, "" sorting by name
+	SortBy string `json:"sortBy,omitempty"`
+
+	// SortDescending reverses the sort order
+	SortDescending bool `json:"sortDescending,omitempty"`
+
+	// GroupByHost nests the tunnels of the list under their SSH host
+	GroupByHost bool `json:"groupByHost,omitempty"`
+
+	// StartOnDashboard opens the TUI on the dashboard instead of the tunnel list
+	StartOnDashboard bool `json:"startOnDashboard,omitempty"`
+}
+
+// getUIStatePath returns the path of the TUI state file
+func getUIStatePath() (string, error) {
+	stateDir, err := getStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(stateDir, "tui.json"), nil
+}
+
+// LoadUIState loads the saved TUI choices; a missing file gives the defaults
+func LoadUIState() (*UIState, error) {
+	path, err := getUIStatePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &UIState{}, nil
+		}
+		return nil, fmt.Errorf("failed to read TUI state: %w", err)
+	}
+
+	var state UIState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse TUI state: %w", err)
+	}
+	return &state, nil
+}
+
+// SaveUIState atomically replaces the saved TUI choices
+func SaveUIState(state *UIState) error {
+	path, err := getUIStatePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal TUI state: %w", err)
+	}
+
+	tempFile := path + ".tmp"
+	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write TUI state: %w", err)
+	}
+	if err := os.Rename(tempFile, path); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to save TUI state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+// Package tui provides the history of status messages and tunnel events
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// activityLimit is the number of messages kept in the activity history
+const activityLimit = 500
+
+// activityEntry is a status bar message or tunnel status change
+type activityEntry struct {
+	time    time.Time
+	tunnel  string
+	message string
+}
+
+// logActivity adds a message to the activity history. The message may
+// contain color tags.
+func (a *App) logActivity(tunnel, message string) {
+	a.addActivity(activityEntry{time: time.Now(), tunnel: tunnel, message: message})
+}
+
+// logStatusChange adds a tunnel status change to the activity history
+func (a *App) logStatusChange(event core.StatusEvent) {
+	name := event.TunnelName
+	if name == "" {
+		name = event.TunnelID
+	}
+	_, color := a.formatStatus(event.NewStatus)
+	message := fmt.Sprintf("%s %s [%s]%s[-]", tr(string(event.OldStatus)), a.symbols.arrow, getColorName(color), tr(string(event.NewStatus)))
+	if event.Error != "" {
+		message += fmt.Sprintf(": %s", tview.Escape(event.Error))
+	}
+	a.addActivity(activityEntry{time: event.Time, tunnel: name, message: message})
+}
+
+// addActivity appends an entry to the activity history, dropping the oldest
+// beyond activityLimit, and refreshes the history if it is shown
+func (a *App) addActivity(entry activityEntry) {
+	a.activity = append(a.activity, entry)
+	if len(a.activity) > activityLimit {
+		a.activity = a.activity[len(a.activity)-activityLimit:]
+	}
+	if a.activityView != nil {
+		a.activityView.SetText(a.formatActivity())
+		a.activityView.ScrollToEnd()
+	}
+}
+
+// formatActivity renders the activity history, oldest first
+func (a *App) formatActivity() string {
+	if len(a.activity) == 0 {
+		return tr("[gray]Nothing has happened yet[-]")
+	}
+
+	var text strings.Builder
+	for _, entry := range a.activity {
+		text.WriteString(fmt.Sprintf("[gray]%s[-] ", entry.time.Format("15:04:05")))
+		if entry.tunnel != "" {
+			text.WriteString(fmt.Sprintf("[aqua]%s[-] ", tview.Escape(entry.tunnel)))
+		}
+		text.WriteString(strings.TrimSpace(a.statusMessage(entry.message)) + "\n")
+	}
+	return text.String()
+}
+
+// showActivity shows the scrollable history of status messages and tunnel
+// status changes, which keeps growing while it is open
+func (a *App) showActivity() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true)
+	view.SetBorder(true).
+		SetTitle(tr(" Activity (↑/↓ scroll, Esc close) ")).
+		SetTitleAlign(tview.AlignCenter)
+
+	view.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'm' || event.Rune() == 'q' {
+			a.activityView = nil
+			a.modals.pop("activity")
+			return nil
+		}
+		return event
+	})
+
+	a.activityView = view
+	view.SetText(a.formatActivity())
+	view.ScrollToEnd()
+
+	a.modals.push("activity", a.createModalOverlay(view, 100, 30), view)
+}
@@ -2,7 +2,9 @@
 package tui
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,30 +21,64 @@ type App struct {
 	configStore   *store.ConfigStore
 
 	// UI components
-	pages       *tview.Pages
-	headerBar   *tview.TextView
-	tunnelList  *tview.Table
-	statusBar   *tview.TextView
-	detailView  *tview.TextView
-	helpView    *tview.TextView
-	footerBar   *tview.TextView
+	pages      *tview.Pages
+	modals     *modalStack
+	headerBar  *tview.TextView
+	tunnelList *tview.Table
+	statusBar  *tview.TextView
+	detailView *tview.TextView
+	helpView   *tview.TextView
+	footerBar  *tview.TextView
+	mainFlex   *tview.Flex
+	logPane    *logPane
 
 	// State
 	selectedTunnel *core.Tunnel
 	lastUpdate     time.Time
 	searchMode     *SearchMode
 	currentProfile string
+	sortField      core.SortField
+	sortDescending bool
+	filter         string
+	shownTunnels   int
+	groupByHost    bool
+	collapsedHosts map[string]bool
+	selectedGroup  string
+
+	// Detail view text with uptimeMarker in place of the uptime of the
+	// tunnel started at detailStarted, filled in every second
+	detailText    string
+	detailStarted *time.Time
+
+	// Activity history
+	activity     []activityEntry
+	activityView *tview.TextView
+
+	// Icons drawn, ASCII ones if forced by the --ascii flag
+	symbols    *symbolSet
+	forceASCII bool
+	colors     *colorScheme
+
+	// Dashboard
+	dashboard        *dashboard
+	startOnDashboard bool
+	events           []core.StatusEvent
 }
 
 // NewApp creates a new TUI application
 func NewApp(tunnelManager *core.TunnelManager, configStore *store.ConfigStore) *App {
-	return &App{
+	a := &App{
 		app:            tview.NewApplication(),
 		tunnelManager:  tunnelManager,
 		configStore:    configStore,
 		lastUpdate:     time.Now(),
 		currentProfile: "default",
+		collapsedHosts: make(map[string]bool),
+		symbols:        unicodeSymbols,
+		colors:         colorSchemeNamed(""),
 	}
+	a.loadUIState()
+	return a
 }
 
 // Run starts the TUI application
@@ -50,11 +86,46 @@ func (a *App) Run() error {
 	// Initialize UI components
 	a.initUI()
 
+	// Ask for passwords and passphrases of the tunnels started from here
+	if stopPrompts, err := a.tunnelManager.EnablePrompts(a.promptSSH); err != nil {
+		core.Warn("Password prompts are unavailable: %v", err)
+	} else {
+		defer stopPrompts()
+	}
+
 	// Start status update goroutine
 	go a.watchStatusChanges()
 
-	// Start auto-connect tunnels
-	a.tunnelManager.StartAutoConnectTunnels()
+	// Start health checks of running tunnels
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go core.NewHealthChecker(a.tunnelManager, core.WithHealthChangeFunc(a.onHealthChange)).Run(ctx)
+
+	// Catch running tunnels whose local ports do not accept connections
+	go core.NewListenChecker(a.tunnelManager, core.WithListenChangeFunc(a.onListenChange)).Run(ctx)
+
+	// Measure latency to each tunnel's SSH host
+	go core.NewLatencyMonitor(a.tunnelManager, core.WithLatencyChangeFunc(a.onLatencyChange)).Run(ctx)
+
+	// Pick up edits of the config file by other programs
+	go core.NewConfigWatcher(a.tunnelManager, core.WithConfigReloadFunc(a.onConfigReload)).Run(ctx)
+
+	// Guide a first run instead of showing an empty list
+	if !a.configStore.Exists() {
+		a.showSetupWizard()
+	}
+
+	// Offer to adopt or kill leftover ssh processes before auto-connect
+	// starts duplicates of them
+	autoConnect := func() {
+		a.tunnelManager.StartAutoConnectTunnels()
+		if err := a.tunnelManager.SwitchProfile("", a.currentProfile); err != nil {
+			core.Error("Failed to auto-connect profile %s: %v", a.currentProfile, err)
+		}
+	}
+	if !a.confirmOrphans(func() { go autoConnect() }) {
+		autoConnect()
+	}
 
 	// Run the application
 	return a.app.Run()
@@ -72,6 +143,9 @@ func (a *App) SetInitialProfile(profile string) {
 
 // initUI initializes the user interface
 func (a *App) initUI() {
+	// Pick the icons and colors before anything is drawn
+	a.applyAppearance()
+
 	// Initialize search mode
 	a.initSearchMode()
 
@@ -82,27 +156,39 @@ func (a *App) initUI() {
 	a.createStatusBar()
 	a.createFooterBar()
 	a.createHelpView()
+	a.createLogPane()
 
-	// Create layout with flexbox
-	mainFlex := tview.NewFlex().
+	// Create layout with flexbox; the log pane has no height until toggled
+	a.mainFlex = tview.NewFlex().
 		SetDirection(tview.FlexRow).
 		AddItem(a.headerBar, 3, 0, false).
 		AddItem(a.createMainContent(), 0, 1, true).
+		AddItem(a.logPane.view, 0, 0, false).
 		AddItem(a.statusBar, 1, 0, false).
 		AddItem(a.footerBar, 2, 0, false)
 
 	// Create pages for modal dialogs
 	a.pages = tview.NewPages().
-		AddPage("main", mainFlex, true, true).
+		AddPage("main", a.mainFlex, true, true).
 		AddPage("help", a.createHelpModal(), true, false)
 
+	// The footer shows the keys of the dialog in front
+	a.pages.SetChangedFunc(a.updateFooterBar)
+	a.modals = newModalStack(a.app, a.pages)
+
 	// Set up application
 	a.app.SetRoot(a.pages, true).
+		EnableMouse(true).
 		SetFocus(a.tunnelList).
 		SetInputCapture(a.handleGlobalKeys)
 
 	// Initial tunnel list update
 	a.updateTunnelList()
+
+	// Land on the dashboard if it was open when the last session ended
+	if a.startOnDashboard {
+		a.showDashboard()
+	}
 }
 
 // createMainContent creates the main content area
@@ -158,9 +244,12 @@ func (a *App) createTunnelList() {
 	// Set up input handler
 	a.tunnelList.SetInputCapture(a.handleListKeys)
 
+	// Clicking a column header sorts by it
+	a.tunnelList.SetMouseCapture(a.handleListMouse)
+
 	// Style the list
 	a.tunnelList.SetBorder(true).
-		SetTitle(" Tunnels ").
+		SetTitle(tr(" Tunnels ")).
 		SetTitleAlign(tview.AlignLeft)
 }
 
@@ -172,7 +261,7 @@ func (a *App) createDetailView() {
 		SetWrap(true)
 
 	a.detailView.SetBorder(true).
-		SetTitle(" Details ").
+		SetTitle(tr(" Details ")).
 		SetTitleAlign(tview.AlignLeft)
 }
 
@@ -185,9 +274,8 @@ func (a *App) createStatusBar() {
 	a.updateStatusBar("")
 }
 
-// createHelpView creates the help view
-func (a *App) createHelpView() {
-	helpText := `[::b]Keyboard Shortcuts[::-]
+// helpText is the text of the help view
+const helpText = `[::b]Keyboard Shortcuts[::-]
 
 [yellow]Navigation:[::-]
   ↑/k     Move up
@@ -200,19 +288,38 @@ func (a *App) createHelpView() {
   u       Start tunnel
   d       Stop tunnel
   e       Edit tunnel
+  n       Rename tunnel
+  L       Change local port
   c       Create new tunnel
+  C       Duplicate tunnel and edit the copy
   r       Remove (delete) tunnel
   a       Toggle auto-connect
+  v       View the ssh command (dry run)
+  y       Copy the ssh command, endpoint or JSON
+  l       Show/hide the live log of the tunnel
 
 [yellow]Batch Operations:[::-]
   A       Start all tunnels in profile
   X       Stop all tunnels in profile
   g       Switch profile
-  p       Profile management (add/delete)
-  f       Filter view
+  p       Profile management (add/rename/clone/delete)
+  f       Toggle forward/reverse mode
+  F       Filter view (status, type, auto-connect, tag)
+  x       Clear the filter
+  o       Sort by the next column (click a header to sort by it)
+  O       Reverse the sort order
+  t       Show, start or stop tunnels by tag
+  G       Group tunnels by SSH host
+  Space   Collapse/expand a host group (also ←/→)
 
 [yellow]Application:[::-]
+  b       Dashboard (status per profile, failures, events)
+  m       Message history (status messages and tunnel events)
+  Ctrl+P  Command palette (actions and tunnels of all profiles)
   ?       Show this help
+  S       Settings (defaults for all tunnels)
+  z       Undo the last config change
+  Z       Restore the last deleted tunnel
   q       Quit (tunnels keep running)
   Ctrl+C  Force quit
 
@@ -220,12 +327,15 @@ func (a *App) createHelpView() {
   Local (-L):   Forward local port to remote
   Remote (-R):  Forward remote port to local
   Dynamic (-D): SOCKS proxy
+  Reverse Dynamic (-R port): SOCKS proxy on the SSH host
 
 Press any key to close this help.`
 
+// createHelpView creates the help view
+func (a *App) createHelpView() {
 	a.helpView = tview.NewTextView().
 		SetDynamicColors(true).
-		SetText(helpText).
+		SetText(tr(helpText)).
 		SetScrollable(true)
 }
 
@@ -242,12 +352,11 @@ func (a *App) createHelpModal() *tview.Flex {
 		AddItem(nil, 0, 1, false)
 
 	a.helpView.SetBorder(true).
-		SetTitle(" Help ").
+		SetTitle(tr(" Help ")).
 		SetTitleAlign(tview.AlignCenter)
 
 	a.helpView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		a.pages.HidePage("help")
-		a.app.SetFocus(a.tunnelList)
+		a.modals.pop("help")
 		return nil
 	})
 
@@ -259,8 +368,12 @@ func (a *App) updateTunnelList() {
 	a.tunnelList.Clear()
 
 	// Add header row with updated columns
-	headers := []string{"St", "Name", "Host", "Local", "Remote", "Mode", "Started"}
+	headers := []string{"St", "Name", "Host", "Tags", "Local", "Remote", "Mode", "Health", "Latency", "Started"}
 	for col, header := range headers {
+		header = tr(header)
+		if field, ok := columnSortFields[col]; ok && field == a.sortField {
+			header += a.sortArrow()
+		}
 		cell := tview.NewTableCell(header).
 			SetTextColor(tcell.ColorYellow).
 			SetAttributes(tcell.AttrBold).
@@ -276,91 +389,180 @@ func (a *App) updateTunnelList() {
 	} else {
 		tunnels = a.tunnelManager.GetTunnels()
 	}
-	for row, tunnel := range tunnels {
-		rowNum := row + 1
-
-		// Status indicator
-		var statusIcon string
-		var statusColor tcell.Color
-		switch tunnel.Status {
-		case core.StatusRunning:
-			statusIcon = "●"
-			statusColor = tcell.ColorGreen
-		case core.StatusStopped:
-			statusIcon = "○"
-			statusColor = tcell.ColorGray
-		case core.StatusError:
-			statusIcon = "×"
-			statusColor = tcell.ColorRed
-		case core.StatusConnecting:
-			statusIcon = "◐"
-			statusColor = tcell.ColorYellow
-		default:
-			statusIcon = "○"
-			statusColor = tcell.ColorGray
+	core.SortTunnels(tunnels, a.sortField, a.sortDescending)
+
+	// Hide the tunnels the view filter leaves out
+	title := trf(" Tunnels (by %s) ", a.sortLabel())
+	if a.filter != "" {
+		total := len(tunnels)
+		tunnels = slices.DeleteFunc(tunnels, func(t *core.Tunnel) bool {
+			return !matchesFilter(t, a.filter)
+		})
+		title = trf(" Tunnels [yellow][filtered: %s, %d of %d][-] (by %s) ", tview.Escape(a.filterLabel()), len(tunnels), total, a.sortLabel())
+	}
+	if a.groupByHost {
+		title += tr("[::d]grouped by host[::-] ")
+	}
+	a.tunnelList.SetTitle(title)
+
+	// In the grouped view each SSH host gets a row above its tunnels, which
+	// are left out while the group is collapsed
+	a.shownTunnels = len(tunnels)
+	rowNum := 1
+	for _, group := range a.groupTunnels(tunnels) {
+		if a.groupByHost {
+			a.setGroupRow(rowNum, group)
+			rowNum++
+			if a.collapsedHosts[group.host] {
+				continue
+			}
 		}
-
-		// Mode indicator
-		var modeIcon string
-		var modeColor tcell.Color
-		switch tunnel.Type {
-		case core.LocalForward:
-			modeIcon = "→"
-			modeColor = tcell.ColorBlue
-		case core.RemoteForward:
-			modeIcon = "←"
-			modeColor = tcell.ColorOrange
-		case core.DynamicForward:
-			modeIcon = "⇄"
-			modeColor = tcell.ColorPurple
+		for _, tunnel := range group.tunnels {
+			a.setTunnelRow(rowNum, tunnel)
+			rowNum++
 		}
+	}
 
-		// Started time
-		var startedStr string
-		if tunnel.StartedAt != nil {
-			duration := time.Since(*tunnel.StartedAt)
-			startedStr = formatDuration(duration)
-		} else {
-			startedStr = "-"
+	// Restore selection if possible, otherwise select the first row shown. A
+	// tunnel in a collapsed group leaves its group selected.
+	row := -1
+	switch {
+	case a.selectedTunnel != nil:
+		id, host := a.selectedTunnel.ID, a.selectedTunnel.SSHHost
+		row = a.findTunnelRow(id)
+		if row < 0 && a.groupByHost {
+			row = a.findGroupRow(host)
 		}
+	case a.selectedGroup != "":
+		row = a.findGroupRow(a.selectedGroup)
+	}
+	if row < 0 && a.tunnelList.GetRowCount() > 1 {
+		row = 1
+	}
+	if row > 0 {
+		a.tunnelList.Select(row, 1)
+	} else {
+		a.selectedTunnel = nil
+		a.selectedGroup = ""
+		a.updateDetailView(nil)
+		a.followTunnelLog(nil)
+	}
+	a.updateFooterBar()
+}
 
-		// Create cells
-		cells := []struct {
-			text  string
-			color tcell.Color
-			align int
-		}{
-			{statusIcon, statusColor, tview.AlignCenter},
-			{tunnel.Name, tcell.ColorWhite, tview.AlignLeft},
-			{tunnel.SSHHost, tcell.ColorAqua, tview.AlignLeft},
-			{fmt.Sprintf("%d", tunnel.LocalPort), tcell.ColorWhite, tview.AlignRight},
-			{fmt.Sprintf("%d", tunnel.RemotePort), tcell.ColorWhite, tview.AlignRight},
-			{modeIcon, modeColor, tview.AlignCenter},
-			{startedStr, tcell.ColorWhite, tview.AlignRight},
-		}
+// setTunnelRow fills a row of the tunnel list with a tunnel
+func (a *App) setTunnelRow(rowNum int, tunnel *core.Tunnel) {
+	// Status indicator
+	var statusIcon string
+	var statusColor tcell.Color
+	switch tunnel.Status {
+	case core.StatusRunning:
+		statusIcon = a.symbols.running
+		statusColor = a.colors.good
+	case core.StatusStopped:
+		statusIcon = a.symbols.stopped
+		statusColor = tcell.ColorGray
+	case core.StatusError:
+		statusIcon = a.symbols.failed
+		statusColor = a.colors.bad
+	case core.StatusConnecting:
+		statusIcon = a.symbols.connecting
+		statusColor = a.colors.warn
+	default:
+		statusIcon = a.symbols.stopped
+		statusColor = tcell.ColorGray
+	}
+	if a.colors.labels {
+		statusIcon, statusColor = a.formatStatus(tunnel.Status)
+	}
+	if tunnel.Status == core.StatusRunning && tunnel.NotListening != "" {
+		statusIcon, statusColor = a.formatNotListening()
+	}
 
-		for col, cell := range cells {
-			tableCell := tview.NewTableCell(cell.text).
-				SetTextColor(cell.color).
-				SetReference(tunnel).
-				SetAlign(cell.align)
+	// Mode indicator
+	var modeIcon string
+	var modeColor tcell.Color
+	switch tunnel.Type {
+	case core.LocalForward:
+		modeIcon = a.symbols.local
+		modeColor = tcell.ColorBlue
+	case core.RemoteForward:
+		modeIcon = a.symbols.remote
+		modeColor = tcell.ColorOrange
+	case core.DynamicForward:
+		modeIcon = a.symbols.dynamic
+		modeColor = tcell.ColorPurple
+	case core.ReverseDynamicForward:
+		modeIcon = a.symbols.reverseDynamic
+		modeColor = tcell.ColorFuchsia
+	}
+
+	// Forwards continuing the primary one on consecutive ports are shown
+	// as a port range
+	forwards := tunnel.AllForwards()
+	run := core.LeadingRunLength(forwards)
+	last := forwards[run-1]
 
-			a.tunnelList.SetCell(rowNum, col, tableCell)
+	// Reverse dynamic forwards have no local port; local listeners show
+	// their bind address when it is not loopback
+	localStr := formatPortRange(tunnel.LocalPort, last.LocalPort)
+	remoteStr := formatPortRange(tunnel.RemotePort, last.RemotePort)
+	switch tunnel.Type {
+	case core.ReverseDynamicForward:
+		localStr = "-"
+	case core.LocalForward, core.DynamicForward:
+		if tunnel.LocalHost != "" && tunnel.LocalHost != core.DefaultBindAddress {
+			localStr = fmt.Sprintf("%s:%s", core.BracketHost(tunnel.LocalHost), localStr)
 		}
 	}
 
-	// Restore selection if possible
-	if a.selectedTunnel != nil {
-		for row := 1; row < a.tunnelList.GetRowCount(); row++ {
-			if cell := a.tunnelList.GetCell(row, 1); cell != nil {
-				if t, ok := cell.GetReference().(*core.Tunnel); ok && t.ID == a.selectedTunnel.ID {
-					a.tunnelList.Select(row, 1)
-					break
-				}
-			}
-		}
-	} else if a.tunnelList.GetRowCount() > 1 {
-		a.tunnelList.Select(1, 1)
+	// Additional forwards share the row
+	if extra := len(forwards) - run; extra > 0 {
+		modeIcon = fmt.Sprintf("%s+%d", modeIcon, extra)
+	}
+
+	// Health indicator
+	healthStr, healthColor := "-", tcell.ColorGray
+	if tunnel.Status == core.StatusRunning && tunnel.Health != core.HealthUnknown {
+		healthStr, healthColor = a.formatHealth(tunnel.Health)
+	}
+
+	latencyStr, latencyColor := a.formatLatency(tunnel)
+
+	// Started time
+	var startedStr string
+	if tunnel.StartedAt != nil {
+		duration := time.Since(*tunnel.StartedAt)
+		startedStr = core.FormatDuration(duration)
+	} else {
+		startedStr = "-"
+	}
+
+	// Create cells
+	cells := []struct {
+		text  string
+		color tcell.Color
+		align int
+	}{
+		{statusIcon, statusColor, tview.AlignCenter},
+		{tunnel.Name, tcell.ColorWhite, tview.AlignLeft},
+		{tunnel.SSHHost, tcell.ColorAqua, tview.AlignLeft},
+		{strings.Join(tunnel.Tags, ","), tcell.ColorGray, tview.AlignLeft},
+		{localStr, tcell.ColorWhite, tview.AlignRight},
+		{remoteStr, tcell.ColorWhite, tview.AlignRight},
+		{modeIcon, modeColor, tview.AlignCenter},
+		{healthStr, healthColor, tview.AlignCenter},
+		{latencyStr, latencyColor, tview.AlignRight},
+		{startedStr, tcell.ColorWhite, tview.AlignRight},
+	}
+
+	for col, cell := range cells {
+		tableCell := tview.NewTableCell(cell.text).
+			SetTextColor(cell.color).
+			SetReference(tunnel).
+			SetAlign(cell.align)
+
+		a.tunnelList.SetCell(rowNum, col, tableCell)
 	}
 }
 
@@ -368,18 +570,149 @@ func (a *App) updateTunnelList() {
 func (a *App) formatStatus(status core.TunnelStatus) (string, tcell.Color) {
 	switch status {
 	case core.StatusRunning:
-		return "● Running", tcell.ColorGreen
+		return a.symbols.running + " " + tr("Running"), a.colors.good
 	case core.StatusStopped:
-		return "○ Stopped", tcell.ColorSilver
+		return a.symbols.stopped + " " + tr("Stopped"), a.colors.off
 	case core.StatusConnecting:
-		return "◐ Connecting", tcell.ColorYellow
+		return a.symbols.connecting + " " + tr("Connecting"), a.colors.warn
 	case core.StatusError:
-		return "✗ Error", tcell.ColorRed
+		return a.symbols.failed + " " + tr("Error"), a.colors.bad
 	default:
 		return string(status), tcell.ColorWhite
 	}
 }
 
+// formatNotListening formats the status of a running tunnel whose local port
+// refuses connections
+func (a *App) formatNotListening() (string, tcell.Color) {
+	if a.colors.labels {
+		return a.symbols.notListening + " " + tr("Not listening"), a.colors.warn
+	}
+	return a.symbols.notListening, a.colors.warn
+}
+
+// hopColor returns the color for a hop state in a tunnel chain
+func (a *App) hopColor(state core.HopState) tcell.Color {
+	switch state {
+	case core.HopUp:
+		return a.colors.good
+	case core.HopConnecting:
+		return a.colors.warn
+	case core.HopFailed:
+		return a.colors.bad
+	default:
+		return a.colors.off
+	}
+}
+
+// formatHealth formats tunnel health with appropriate color
+func (a *App) formatHealth(health core.TunnelHealth) (string, tcell.Color) {
+	switch health {
+	case core.HealthHealthy:
+		return tr("healthy"), a.colors.good
+	case core.HealthDegraded:
+		return tr("degraded"), a.colors.warn
+	case core.HealthUnhealthy:
+		return tr("unhealthy"), a.colors.bad
+	default:
+		return tr("unknown"), tcell.ColorGray
+	}
+}
+
+// formatPortRange formats the ports from first to last for the list
+func formatPortRange(first, last int) string {
+	if first == last {
+		return fmt.Sprintf("%d", first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
+
+// Latency thresholds for the list colors
+const (
+	latencySlow     = 100 * time.Millisecond
+	latencyVerySlow = 300 * time.Millisecond
+)
+
+// formatLatency formats the latency to a tunnel's SSH host, colored by how slow it is
+func (a *App) formatLatency(tunnel *core.Tunnel) (string, tcell.Color) {
+	switch {
+	case tunnel.LatencyError != nil:
+		return tr("fail"), a.colors.bad
+	case tunnel.Latency == 0:
+		return "-", tcell.ColorGray
+	case tunnel.Latency >= latencyVerySlow:
+		return formatMillis(tunnel.Latency), a.colors.bad
+	case tunnel.Latency >= latencySlow:
+		return formatMillis(tunnel.Latency), a.colors.warn
+	default:
+		return formatMillis(tunnel.Latency), a.colors.good
+	}
+}
+
+// formatMillis formats a duration in whole milliseconds, showing sub-millisecond ones as <1ms
+func formatMillis(d time.Duration) string {
+	if d < time.Millisecond {
+		return "<1ms"
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// onLatencyChange redraws the tunnel list and details after latency is measured
+func (a *App) onLatencyChange() {
+	a.app.QueueUpdateDraw(func() {
+		a.updateTunnelList()
+		if a.selectedTunnel != nil {
+			if tunnel, err := a.tunnelManager.GetTunnel(a.selectedTunnel.ID); err == nil {
+				a.updateDetailView(tunnel)
+			}
+		}
+	})
+}
+
+// onConfigReload refreshes the tunnel list after the config file was changed
+// by another program
+func (a *App) onConfigReload(err error) {
+	a.app.QueueUpdateDraw(func() {
+		a.applyAppearance()
+		a.translateViews()
+		a.updateTunnelList()
+		if a.selectedTunnel != nil {
+			if tunnel, err := a.tunnelManager.GetTunnel(a.selectedTunnel.ID); err == nil {
+				a.updateDetailView(tunnel)
+			}
+		}
+		if err != nil {
+			a.updateStatusBar(trf("Error: failed to reload changed config: %v", err))
+		} else {
+			a.updateStatusBar(tr("↻ Config changed on disk and was reloaded"))
+		}
+	})
+}
+
+// onHealthChange redraws the tunnel list when a health check changes a tunnel's health
+func (a *App) onHealthChange(tunnelID string, health core.TunnelHealth) {
+	a.redrawTunnel(tunnelID)
+}
+
+// onListenChange redraws the tunnel list when a tunnel's local ports start or
+// stop accepting connections
+func (a *App) onListenChange(tunnelID string) {
+	a.redrawTunnel(tunnelID)
+}
+
+// redrawTunnel redraws the tunnel list, and the details of the tunnel if it
+// is selected
+func (a *App) redrawTunnel(tunnelID string) {
+	a.app.QueueUpdateDraw(func() {
+		a.updateTunnelList()
+		if a.selectedTunnel != nil && a.selectedTunnel.ID == tunnelID {
+			if tunnel, err := a.tunnelManager.GetTunnel(tunnelID); err == nil {
+				a.updateDetailView(tunnel)
+			}
+		}
+	})
+}
+
 // onTunnelSelected handles tunnel selection
 func (a *App) onTunnelSelected(row, column int) {
 	if row == 0 || row >= a.tunnelList.GetRowCount() {
@@ -391,73 +724,252 @@ func (a *App) onTunnelSelected(row, column int) {
 		return
 	}
 
-	if tunnel, ok := cell.GetReference().(*core.Tunnel); ok {
-		a.selectedTunnel = tunnel
-		a.updateDetailView(tunnel)
+	switch ref := cell.GetReference().(type) {
+	case *core.Tunnel:
+		a.selectedTunnel = ref
+		a.selectedGroup = ""
+		a.updateDetailView(ref)
+		a.followTunnelLog(ref)
+	case *hostGroup:
+		a.selectedTunnel = nil
+		a.selectedGroup = ref.host
+		a.showGroupDetail(ref)
+		a.followTunnelLog(nil)
 	}
+	a.updateFooterBar()
 }
 
 // updateDetailView updates the detail view for a tunnel
 func (a *App) updateDetailView(tunnel *core.Tunnel) {
 	if tunnel == nil {
-		a.detailView.Clear()
+		a.setDetailText("", nil)
 		return
 	}
 
 	details := strings.Builder{}
-	details.WriteString(fmt.Sprintf("[::b]%s[::-]\n\n", tunnel.Name))
+	details.WriteString(fmt.Sprintf("[::b]%s[::-]\n", tunnel.Name))
+	if len(tunnel.Tags) > 0 {
+		details.WriteString(trf("[gray]Tags: %s[::-]\n", tview.Escape(strings.Join(tunnel.Tags, ", "))))
+	}
+	if tunnel.Description != "" {
+		details.WriteString(fmt.Sprintf("[::i]%s[::-]\n", tview.Escape(tunnel.Description)))
+	}
+	if tunnel.Source != "" {
+		details.WriteString(trf("[gray]From: %s[::-]\n", tview.Escape(tunnel.Source)))
+	}
+	details.WriteString("\n")
 
 	// Connection details
-	details.WriteString("[yellow]Connection:[::-]\n")
-	details.WriteString(fmt.Sprintf("  SSH: %s\n", tunnel.SSHHost))
+	details.WriteString(tr("[yellow]Connection:[::-]\n"))
+	details.WriteString(trf("  SSH: %s\n", tunnel.SSHHost))
+	if tunnel.JumpHost != "" {
+		hops := tunnel.Hops()
+		chain := make([]string, len(hops))
+		for i, hop := range hops {
+			chain[i] = fmt.Sprintf("[%s]%s[::-]", getColorName(a.hopColor(hop.State)), hop.Host)
+		}
+		details.WriteString(trf("  Via: %s\n", strings.Join(chain, " "+a.symbols.arrow+" ")))
+	}
+	if tunnel.IdentityFile != "" {
+		details.WriteString(trf("  Key: %s (only)\n", tview.Escape(tunnel.IdentityFile)))
+	}
+	details.WriteString(a.formatHostSettings(tunnel))
+	if tunnel.Secret != "" {
+		details.WriteString(trf("  Secret: %s (keychain)\n", tview.Escape(tunnel.Secret)))
+	}
+	if tunnel.CertificateFile != "" {
+		details.WriteString(trf("  Certificate: %s\n", formatCertificate(tunnel.CertificateFile)))
+	}
+	if tunnel.HostKey != "" {
+		details.WriteString(trf("  Host Key: %s (pinned)\n", tunnel.HostKey))
+	}
+	if tunnel.Status == core.StatusRunning {
+		if method := a.tunnelManager.AuthMethod(tunnel.ID); method != "" {
+			details.WriteString(trf("  Authenticated: %s\n", method))
+		}
+	}
 	details.WriteString("\n")
 
 	// Forwarding details
-	details.WriteString("[yellow]Forwarding:[::-]\n")
+	details.WriteString(tr("[yellow]Forwarding:[::-]\n"))
 	switch tunnel.Type {
 	case core.LocalForward:
-		details.WriteString(fmt.Sprintf("  Type: Local Forward (-L)\n"))
-		details.WriteString(fmt.Sprintf("  Local: %s:%d\n", tunnel.LocalHost, tunnel.LocalPort))
-		details.WriteString(fmt.Sprintf("  Remote: %s:%d\n", tunnel.RemoteHost, tunnel.RemotePort))
+		details.WriteString(tr("  Type: Local Forward (-L)\n"))
+		details.WriteString(trf("  Local: %s:%d\n", core.BracketHost(tunnel.LocalHost), tunnel.LocalPort))
+		details.WriteString(trf("  Remote: %s:%d\n", core.BracketHost(tunnel.RemoteHost), tunnel.RemotePort))
 	case core.RemoteForward:
-		details.WriteString(fmt.Sprintf("  Type: Remote Forward (-R)\n"))
-		details.WriteString(fmt.Sprintf("  Remote Port: %d\n", tunnel.RemotePort))
-		details.WriteString(fmt.Sprintf("  Local: %s:%d\n", tunnel.LocalHost, tunnel.LocalPort))
+		details.WriteString(tr("  Type: Remote Forward (-R)\n"))
+		details.WriteString(trf("  Remote Port: %d\n", tunnel.RemotePort))
+		if tunnel.RemoteBindAddress != "" {
+			details.WriteString(trf("  Remote Bind: %s\n", tunnel.RemoteBindAddress))
+		}
+		details.WriteString(trf("  Local: %s:%d\n", core.BracketHost(tunnel.LocalHost), tunnel.LocalPort))
 	case core.DynamicForward:
-		details.WriteString(fmt.Sprintf("  Type: Dynamic (SOCKS)\n"))
-		details.WriteString(fmt.Sprintf("  Local: %s:%d\n", tunnel.LocalHost, tunnel.LocalPort))
+		details.WriteString(tr("  Type: Dynamic (SOCKS)\n"))
+		details.WriteString(trf("  Local: %s:%d\n", core.BracketHost(tunnel.LocalHost), tunnel.LocalPort))
+	case core.ReverseDynamicForward:
+		details.WriteString(tr("  Type: Reverse Dynamic (remote SOCKS, -R)\n"))
+		details.WriteString(trf("  Remote Port: %d\n", tunnel.RemotePort))
+		if tunnel.RemoteBindAddress != "" {
+			details.WriteString(trf("  Remote Bind: %s\n", tunnel.RemoteBindAddress))
+		}
+	}
+	for _, forward := range tunnel.Forwards {
+		details.WriteString(trf("  Also: %s\n", forward.Summary()))
+	}
+	if tunnel.NeedsGatewayPorts() {
+		details.WriteString(trf("  [yellow]%s Binding a non-loopback address needs GatewayPorts yes or\n", a.symbols.warning))
+		details.WriteString(tr("    clientspecified in the server's sshd_config; otherwise sshd\n"))
+		details.WriteString(tr("    listens on loopback only[::-]\n"))
 	}
 	details.WriteString("\n")
 
 	// Status details
-	details.WriteString("[yellow]Status:[::-]\n")
+	details.WriteString(tr("[yellow]Status:[::-]\n"))
 	status, color := a.formatStatus(tunnel.Status)
-	details.WriteString(fmt.Sprintf("  State: [%s]%s[::-]\n", getColorName(color), status))
+	if tunnel.Status == core.StatusRunning && tunnel.NotListening != "" {
+		status, color = a.symbols.notListening+" "+tr("Not listening"), a.colors.warn
+	}
+	details.WriteString(trf("  State: [%s]%s[::-]\n", getColorName(color), status))
+	if tunnel.Status == core.StatusRunning && tunnel.NotListening != "" {
+		details.WriteString(trf("  [yellow]ssh is up, but nothing accepts connections on %s[::-]\n", tunnel.NotListening))
+	}
 	if tunnel.PID > 0 {
-		details.WriteString(fmt.Sprintf("  PID: %d\n", tunnel.PID))
+		details.WriteString(trf("  PID: %d\n", tunnel.PID))
 	}
 	if tunnel.StartedAt != nil {
-		duration := time.Since(*tunnel.StartedAt)
-		details.WriteString(fmt.Sprintf("  Uptime: %s\n", formatDuration(duration)))
+		details.WriteString(trf("  Uptime: %s\n", uptimeMarker))
 	}
 	if tunnel.LastError != nil {
-		details.WriteString(fmt.Sprintf("  [red]Error: %v[::-]\n", tunnel.LastError))
+		details.WriteString(trf("  [red]Error: %v[::-]\n", tunnel.LastError))
+		if hint := core.FailureHint(tunnel.LastError); hint != "" {
+			details.WriteString(trf("  [yellow]Hint: %s[::-]\n", hint))
+		}
+	}
+	if tunnel.Status == core.StatusRunning && tunnel.Health != core.HealthUnknown {
+		health, color := a.formatHealth(tunnel.Health)
+		details.WriteString(trf("  Health: [%s]%s[::-]\n", getColorName(color), health))
+		if tunnel.HealthError != nil {
+			details.WriteString(trf("  [yellow]Probe: %v[::-]\n", tunnel.HealthError))
+		}
+	}
+	if tunnel.LatencyError != nil {
+		details.WriteString(trf("  [red]Latency: %v[::-]\n", tunnel.LatencyError))
+	} else if tunnel.Latency > 0 {
+		latency, color := a.formatLatency(tunnel)
+		details.WriteString(trf("  Latency: [%s]%s[::-]\n", getColorName(color), latency))
 	}
 	details.WriteString("\n")
 
+	// Statistics kept across restarts
+	if stats, err := a.tunnelManager.Stats(tunnel.ID); err == nil && (stats.Connects > 0 || stats.Failures > 0) {
+		details.WriteString(tr("[yellow]Statistics:[::-]\n"))
+		details.WriteString(trf("  Connects: %d  Failures: %d  Restarts: %d\n", stats.Connects, stats.Failures, stats.Restarts))
+		details.WriteString(trf("  Total uptime: %s\n", core.FormatDuration(stats.Uptime)))
+		if stats.LastFailure != nil {
+			details.WriteString(trf("  Last failure: %s\n", stats.LastFailure.Local().Format("2006-01-02 15:04:05")))
+		}
+		details.WriteString("\n")
+	}
+
 	// Options
-	details.WriteString("[yellow]Options:[::-]\n")
-	details.WriteString(fmt.Sprintf("  Auto-connect: %v\n", tunnel.AutoConnect))
+	details.WriteString(tr("[yellow]Options:[::-]\n"))
+	details.WriteString(trf("  Auto-connect: %v\n", tunnel.AutoConnect))
+	if tunnel.Compression {
+		details.WriteString(tr("  Compression: on\n"))
+	}
+	if tunnel.Multiplex {
+		details.WriteString(tr("  Connection: shared with other tunnels to the host\n"))
+	}
+	if tunnel.ServerAliveInterval < 0 {
+		details.WriteString(tr("  Keepalive: off\n"))
+	} else if tunnel.ServerAliveInterval > 0 || tunnel.ServerAliveCountMax > 0 {
+		details.WriteString(trf("  Keepalive: %s\n", formatKeepalive(tunnel)))
+	}
+	if tunnel.ExitOnForwardFailure != nil {
+		details.WriteString(trf("  Exit on forward failure: %v\n", *tunnel.ExitOnForwardFailure))
+	}
 	if len(tunnel.ExtraArgs) > 0 {
-		details.WriteString(fmt.Sprintf("  Extra args: %s\n", strings.Join(tunnel.ExtraArgs, " ")))
+		details.WriteString(trf("  Extra args: %s\n", strings.Join(tunnel.ExtraArgs, " ")))
+	}
+	for _, event := range []core.HookEvent{core.HookConnect, core.HookDisconnect, core.HookFailure} {
+		if command := tunnel.Hooks.Command(event); command != "" {
+			details.WriteString(trf("  On %s: %s\n", event, tview.Escape(command)))
+		}
 	}
 
 	// SSH Command
-	details.WriteString("\n[yellow]SSH Command:[::-]\n")
-	cmd := strings.Join(tunnel.BuildSSHCommand(), " ")
-	details.WriteString(fmt.Sprintf("  [dim]%s[::-]\n", cmd))
+	details.WriteString(tr("\n[yellow]SSH Command:[::-]\n"))
+	cmd := core.QuoteCommand(a.tunnelManager.SSHCommand(tunnel))
+	details.WriteString(fmt.Sprintf("  [dim]%s[::-]\n", tview.Escape(cmd)))
 
-	a.detailView.SetText(details.String())
+	a.setDetailText(details.String(), tunnel.StartedAt)
+}
+
+// uptimeMarker stands for the uptime in the detail view text until it is shown
+const uptimeMarker = "\x00uptime\x00"
+
+// setDetailText shows text in the detail view, with the uptime since
+// started in place of uptimeMarker
+func (a *App) setDetailText(text string, started *time.Time) {
+	a.detailText, a.detailStarted = text, started
+	a.showDetailText()
+}
+
+// showDetailText redraws the detail view text with the current uptime
+func (a *App) showDetailText() {
+	text := a.detailText
+	if a.detailStarted != nil {
+		text = strings.Replace(text, uptimeMarker, core.FormatDuration(time.Since(*a.detailStarted)), 1)
+	}
+	a.detailView.SetText(text)
+}
+
+// startedColumn is the column of the tunnel list showing how long tunnels
+// have been up
+const startedColumn = 9
+
+// refreshUptimes updates the uptimes in the tunnel list and the detail view
+// without rebuilding either
+func (a *App) refreshUptimes() {
+	for row := 1; row < a.tunnelList.GetRowCount(); row++ {
+		cell := a.tunnelList.GetCell(row, startedColumn)
+		if tunnel, ok := cell.GetReference().(*core.Tunnel); ok && tunnel.StartedAt != nil {
+			cell.SetText(core.FormatDuration(time.Since(*tunnel.StartedAt)))
+		}
+	}
+	if a.detailStarted != nil {
+		a.showDetailText()
+	}
+}
+
+// formatHostSettings describes where the tunnel's connection really goes, as
+// resolved from the SSH config. Settings not resolved yet are resolved in the
+// background and the detail view redrawn once they are.
+func (a *App) formatHostSettings(tunnel *core.Tunnel) string {
+	settings, err := a.tunnelManager.CachedHostSettings(tunnel, func() {
+		a.app.QueueUpdateDraw(func() {
+			if a.selectedTunnel != nil && a.selectedTunnel.ID == tunnel.ID {
+				a.updateDetailView(a.selectedTunnel)
+			}
+		})
+	})
+	switch {
+	case err != nil:
+		return trf("  [red]Resolves: %s[::-]\n", tview.Escape(err.Error()))
+	case settings == nil:
+		return tr("  [gray]Resolves: resolving...[::-]\n")
+	}
+
+	var b strings.Builder
+	b.WriteString(trf("  Resolves: %s [gray](%s)[::-]\n", tview.Escape(settings.Address()), settings.Source))
+	if settings.ProxyJump != "" && tunnel.JumpHost == "" {
+		b.WriteString(trf("  Jump: %s [gray](ssh config)[::-]\n", tview.Escape(settings.ProxyJump)))
+	}
+	if len(settings.IdentityFiles) > 0 && tunnel.IdentityFile == "" {
+		b.WriteString(trf("  Keys: %s\n", tview.Escape(strings.Join(settings.IdentityFiles, ", "))))
+	}
+	return b.String()
 }
 
 // updateHeaderBar updates the header bar
@@ -470,36 +982,37 @@ func (a *App) updateHeaderBar() {
 		}
 	}
 
-	headerText := fmt.Sprintf(
-		"[::b]TUNNELMAN[::-] | Profile: [yellow]%s[::-] | Connections: [green]%d/%d[::-] | [dim]? Help | / Search | q Quit[::-]",
+	headerText := trf(
+		"[::b]TUNNELMAN[::-] | Profile: [yellow]%s[::-] | Connections: [%s]%d/%d[::-] | [dim]? Help | / Search | q Quit[::-]",
 		a.currentProfile,
+		getColorName(a.colors.good),
 		running,
 		len(tunnels),
 	)
 	a.headerBar.SetText(headerText)
 }
 
-// updateFooterBar updates the footer bar with current shortcuts
+// updateFooterBar shows the keys of the current context in the footer bar
 func (a *App) updateFooterBar() {
-	shortcuts := []string{
-		"[yellow]u/d[::-] Start/Stop",
-		"[yellow]A[::-] All Start",
-		"[yellow]X[::-] All Stop",
-		"[yellow]c[::-] Create",
-		"[yellow]r[::-] Remove",
-		"[yellow]f[::-] Mode(→/←)",
-		"[yellow]g[::-] Profile",
-		"[yellow]/[::-] Search",
+	if a.pages == nil {
+		return
 	}
-
-	footerText := fmt.Sprintf(" %s", strings.Join(shortcuts, " | "))
-	a.footerBar.SetText(footerText)
+	a.footerBar.SetText(formatKeys(a.currentKeys()))
 }
 
 // updateStatusBar updates the status bar
 func (a *App) updateStatusBar(message string) {
 	if message != "" {
-		a.statusBar.SetText(fmt.Sprintf(" %s", message))
+		a.logActivity("", message)
+	}
+	a.showStatus(message)
+}
+
+// showStatus shows a message in the status bar, or the tunnel counts if it
+// is empty, without adding it to the activity history
+func (a *App) showStatus(message string) {
+	if message != "" {
+		a.statusBar.SetText(fmt.Sprintf(" %s", a.statusMessage(message)))
 		return
 	}
 
@@ -512,7 +1025,10 @@ func (a *App) updateStatusBar(message string) {
 		}
 	}
 
-	status := fmt.Sprintf(" Ready | %d tunnel(s), %d active", len(tunnels), running)
+	status := trf(" Ready | %d tunnel(s), %d active", len(tunnels), running)
+	if issues := a.tunnelManager.ConfigIssues(); len(issues) > 0 {
+		status += trf(" | [yellow]%s %d config problem(s): %s[-]", a.symbols.warning, len(issues), tview.Escape(issues[0].String()))
+	}
 	a.statusBar.SetText(status)
 }
 
@@ -521,27 +1037,49 @@ func (a *App) watchStatusChanges() {
 	ticker := time.NewTicker(time.Second)
 	defer ticker.Stop()
 
-	statusChanges := a.tunnelManager.GetStatusChanges()
+	statusChanges := a.tunnelManager.Subscribe()
+	defer a.tunnelManager.Unsubscribe(statusChanges)
 
 	for {
 		select {
 		case change := <-statusChanges:
 			a.app.QueueUpdateDraw(func() {
+				a.recordEvent(change)
+				a.updateDashboard()
 				a.updateTunnelList()
 				if a.selectedTunnel != nil && a.selectedTunnel.ID == change.TunnelID {
 					if tunnel, err := a.tunnelManager.GetTunnel(change.TunnelID); err == nil {
 						a.updateDetailView(tunnel)
 					}
 				}
+				// The change is already in the activity history with its error
 				if change.Error != nil {
-					a.updateStatusBar(fmt.Sprintf("Error: %v", change.Error))
+					a.showStatus(trf("Error: %v", change.Error))
 				} else {
-					a.updateStatusBar("")
+					a.showStatus("")
+				}
+
+				// Offer to trust a changed host key rather than fail on every retry
+				if core.FailureKindOf(change.Error) == core.FailureHostKey {
+					tunnel, err := a.tunnelManager.GetTunnel(change.TunnelID)
+					if problem := a.tunnelManager.HostKeyProblem(change.TunnelID); err == nil && problem != nil {
+						a.showHostKeyProblem(tunnel, problem)
+					}
 				}
 			})
 
 		case <-ticker.C:
-			// Periodic UI update for uptime display
+			// Pick up tunnels started or stopped by the daemon or other processes
+			a.tunnelManager.RefreshStates()
+
+			// Stream new output of the tunnel shown in the log pane, and
+			// count up the uptimes shown
+			a.app.QueueUpdateDraw(func() {
+				a.pollLogPane()
+				a.refreshUptimes()
+			})
+
+			// Periodic UI update for the statistics and the dashboard
 			if time.Since(a.lastUpdate) > 5*time.Second {
 				a.app.QueueUpdateDraw(func() {
 					if a.selectedTunnel != nil {
@@ -549,6 +1087,7 @@ func (a *App) watchStatusChanges() {
 							a.updateDetailView(tunnel)
 						}
 					}
+					a.updateDashboard()
 					a.lastUpdate = time.Now()
 				})
 			}
@@ -568,25 +1107,38 @@ func getColorName(color tcell.Color) string {
 		return "yellow"
 	case tcell.ColorSilver:
 		return "gray"
-	default:
+	case tcell.ColorDefault:
 		return "white"
+	default:
+		return color.CSS()
 	}
 }
 
-func formatDuration(d time.Duration) string {
-	days := int(d.Hours()) / 24
-	hours := int(d.Hours()) % 24
-	minutes := int(d.Minutes()) % 60
-	seconds := int(d.Seconds()) % 60
-
-	if days > 0 {
-		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+// formatCertificate describes a certificate file and when it expires
+func formatCertificate(path string) string {
+	cert, err := core.ReadCertificate(path)
+	switch {
+	case err != nil:
+		return trf("%s [red](unreadable)[::-]", tview.Escape(path))
+	case cert.ValidBefore.IsZero():
+		return trf("%s (never expires)", tview.Escape(path))
+	case cert.Expired(time.Now()):
+		return trf("%s [red](expired %s ago)[::-]", tview.Escape(path), core.FormatDuration(time.Since(cert.ValidBefore)))
+	case cert.ExpiresWithin(core.CertificateRenewMargin):
+		return trf("%s [yellow](expires in %s)[::-]", tview.Escape(path), core.FormatDuration(time.Until(cert.ValidBefore)))
 	}
-	if hours > 0 {
-		return fmt.Sprintf("%dh %dm %ds", hours, minutes, seconds)
+	return trf("%s (expires in %s)", tview.Escape(path), core.FormatDuration(time.Until(cert.ValidBefore)))
+}
+
+// formatKeepalive describes a tunnel's own keepalive settings, leaving unset
+// ones to the defaults
+func formatKeepalive(tunnel *core.Tunnel) string {
+	interval, count := tr("default"), tr("default")
+	if tunnel.ServerAliveInterval > 0 {
+		interval = fmt.Sprintf("%ds", tunnel.ServerAliveInterval)
 	}
-	if minutes > 0 {
-		return fmt.Sprintf("%dm %ds", minutes, seconds)
+	if tunnel.ServerAliveCountMax > 0 {
+		count = fmt.Sprintf("%d", tunnel.ServerAliveCountMax)
 	}
-	return fmt.Sprintf("%ds", seconds)
-}
\ No newline at end of file
+	return trf("every %s, up to %s missed", interval, count)
+}
@@ -0,0 +1,101 @@
+// Package tui provides copying tunnel details to the clipboard
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// showCopyMenu offers to copy the ssh command, local endpoint or JSON
+// definition of a tunnel
+func (a *App) showCopyMenu(tunnel *core.Tunnel) {
+	modal := tview.NewModal().
+		SetText(trf("Copy from '%s':", tview.Escape(tunnel.Name))).
+		AddButtons([]string{tr("SSH Command"), tr("Endpoint"), "JSON", tr("Cancel")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.modals.pop("copy")
+
+			switch buttonIndex {
+			case 0:
+				a.copySSHCommand(tunnel)
+			case 1:
+				a.copyEndpoint(tunnel)
+			case 2:
+				a.copyJSON(tunnel)
+			}
+		})
+
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.modals.pop("copy")
+			return nil
+		}
+		return event
+	})
+
+	a.modals.push("copy", modal, modal)
+}
+
+// copySSHCommand copies the ssh command a tunnel runs
+func (a *App) copySSHCommand(tunnel *core.Tunnel) {
+	command, err := a.tunnelManager.Preview(tunnel.ID)
+	if err != nil {
+		a.showErrorModal(tr("Tunnel Would Not Start"), tview.Escape(err.Error()))
+		return
+	}
+	a.copyToClipboard(tr("ssh command"), core.QuoteCommand(command))
+}
+
+// copyEndpoint copies the local address clients of a tunnel connect to
+func (a *App) copyEndpoint(tunnel *core.Tunnel) {
+	address, ok := tunnel.DialAddress()
+	if !ok {
+		a.updateStatusBar(trf("%s listens on the SSH host and has no local endpoint", tunnel.Name))
+		return
+	}
+	if host, port, err := net.SplitHostPort(address); err == nil && host == "127.0.0.1" {
+		address = net.JoinHostPort("localhost", port)
+	}
+	a.copyToClipboard(tr("endpoint"), address)
+}
+
+// copyJSON copies the stored definition of a tunnel
+func (a *App) copyJSON(tunnel *core.Tunnel) {
+	config, err := a.tunnelManager.ExportTunnel(tunnel.ID)
+	if err != nil {
+		a.showErrorModal(tr("Copy Failed"), tview.Escape(err.Error()))
+		return
+	}
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		a.showErrorModal(tr("Copy Failed"), tview.Escape(err.Error()))
+		return
+	}
+	a.copyToClipboard(tr("JSON definition"), string(data))
+}
+
+// copyToClipboard puts text on the system clipboard, falling back to asking
+// the terminal to do so with OSC 52 in SSH sessions or without a clipboard tool
+func (a *App) copyToClipboard(what, text string) {
+	tool, err := core.CopyToClipboard(text)
+	switch {
+	case err == nil:
+		a.updateStatusBar(trf("✓ Copied %s to the clipboard (%s)", what, tool))
+	case errors.Is(err, core.ErrNoClipboard):
+		// The sequence is written whole between draws, so it does not
+		// interleave with the screen output
+		if _, err := os.Stdout.WriteString(core.OSC52(text)); err != nil {
+			a.updateStatusBar(trf("[red]Failed to copy %s: %v[-]", what, err))
+			return
+		}
+		a.updateStatusBar(trf("✓ Sent %s to the terminal clipboard (OSC 52)", what))
+	default:
+		a.updateStatusBar(trf("[red]Failed to copy %s: %v[-]", what, tview.Escape(err.Error())))
+	}
+}
@@ -0,0 +1,47 @@
+// Package tui provides the color schemes of the TUI
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// colorScheme is the colors the TUI shows tunnel states with
+type colorScheme struct {
+	// good, warn and bad color running, connecting and failed tunnels and
+	// their health; off colors stopped ones
+	good, warn, bad, off tcell.Color
+
+	// labels names the status next to its icon in the tunnel list, so it
+	// does not depend on telling colors apart
+	labels bool
+}
+
+// colorSchemeNames are the names of the color schemes, the default first
+var colorSchemeNames = []string{"default", "high-contrast", "color-blind"}
+
+// colorSchemes are the color schemes by name
+var colorSchemes = map[string]*colorScheme{
+	"default": {
+		good: tcell.ColorGreen, warn: tcell.ColorYellow, bad: tcell.ColorRed, off: tcell.ColorSilver,
+	},
+	// Bright colors far apart in lightness
+	"high-contrast": {
+		good: tcell.ColorLime, warn: tcell.ColorYellow, bad: tcell.ColorFuchsia, off: tcell.ColorWhite,
+		labels: true,
+	},
+	// Blue, yellow and vermilion from the Okabe-Ito palette stay apart
+	// with deuteranopia and protanopia
+	"color-blind": {
+		good: tcell.NewHexColor(0x56b4e9), warn: tcell.NewHexColor(0xf0e442), bad: tcell.NewHexColor(0xd55e00), off: tcell.ColorSilver,
+		labels: true,
+	},
+}
+
+// colorSchemeNamed returns the color scheme of a name, the default one for
+// an empty or unknown name
+func colorSchemeNamed(name string) *colorScheme {
+	if scheme, ok := colorSchemes[name]; ok {
+		return scheme
+	}
+	return colorSchemes["default"]
+}
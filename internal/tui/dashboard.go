@@ -0,0 +1,201 @@
+// Package tui provides the dashboard overview screen
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+const (
+	// recentEventLimit is the number of status changes kept for the dashboard
+	recentEventLimit = 100
+
+	// dashboardListLength is the number of entries in each dashboard list
+	dashboardListLength = 10
+)
+
+// dashboard is the overview screen shown instead of the tunnel list
+type dashboard struct {
+	root     *tview.Flex
+	profiles *tview.TextView
+	failures *tview.TextView
+	longest  *tview.TextView
+	events   *tview.TextView
+}
+
+// recordEvent keeps a status change for the dashboard, dropping the oldest
+// beyond recentEventLimit, and adds it to the activity history
+func (a *App) recordEvent(change core.TunnelStatusChange) {
+	event := a.tunnelManager.StatusEvent(change)
+	a.logStatusChange(event)
+	a.events = append(a.events, event)
+	if len(a.events) > recentEventLimit {
+		a.events = a.events[len(a.events)-recentEventLimit:]
+	}
+}
+
+// newDashboardPanel creates one titled panel of the dashboard
+func newDashboardPanel(title string) *tview.TextView {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(false)
+	view.SetBorder(true).
+		SetTitle(fmt.Sprintf(" %s ", title)).
+		SetTitleAlign(tview.AlignLeft)
+	return view
+}
+
+// showDashboard shows the overview screen, which stays the landing screen
+// of later sessions until the tunnel list is shown again
+func (a *App) showDashboard() {
+	if a.dashboard != nil {
+		return
+	}
+
+	d := &dashboard{
+		profiles: newDashboardPanel(tr("Profiles")),
+		failures: newDashboardPanel(tr("Recently Failed")),
+		longest:  newDashboardPanel(tr("Longest Running")),
+		events:   newDashboardPanel(tr("Recent Events")),
+	}
+
+	hint := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(tr(" [yellow]b[::-]/[yellow]Esc[::-] Tunnel list | [yellow]Ctrl+P[::-] Commands | [yellow]m[::-] Messages | [yellow]q[::-] Quit"))
+
+	d.root = tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexColumn).
+			AddItem(d.profiles, 0, 1, false).
+			AddItem(d.failures, 0, 1, false), 0, 1, false).
+		AddItem(tview.NewFlex().
+			SetDirection(tview.FlexColumn).
+			AddItem(d.longest, 0, 1, false).
+			AddItem(d.events, 0, 1, false), 0, 1, false).
+		AddItem(hint, 1, 0, false)
+	d.root.SetBorder(true).
+		SetTitle(tr(" tunnelman dashboard ")).
+		SetTitleAlign(tview.AlignCenter)
+
+	d.root.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape, event.Rune() == 'b':
+			a.hideDashboard()
+		case event.Key() == tcell.KeyCtrlP:
+			a.showCommandPalette()
+		case event.Rune() == 'm':
+			a.showActivity()
+		case event.Key() == tcell.KeyCtrlC:
+			a.shutdown()
+		case event.Rune() == 'q':
+			a.confirmQuit()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	a.dashboard = d
+	a.modals.push("dashboard", d.root, d.root)
+	a.updateDashboard()
+	a.saveScreen(true)
+}
+
+// hideDashboard returns from the overview screen to the tunnel list
+func (a *App) hideDashboard() {
+	if a.dashboard == nil {
+		return
+	}
+	a.dashboard = nil
+	a.modals.pop("dashboard")
+	a.saveScreen(false)
+}
+
+// saveScreen remembers whether the dashboard is the landing screen
+func (a *App) saveScreen(dashboard bool) {
+	a.startOnDashboard = dashboard
+	if err := a.saveUIState(); err != nil {
+		a.updateStatusBar(trf("[red]Failed to save view: %v[-]", err))
+	}
+}
+
+// updateDashboard refreshes the overview screen if it is shown
+func (a *App) updateDashboard() {
+	d := a.dashboard
+	if d == nil {
+		return
+	}
+
+	tunnels := a.tunnelManager.GetTunnels()
+
+	// Status counts per profile
+	var profiles strings.Builder
+	profiles.WriteString(fmt.Sprintf("[::b]%-16s %7s %10s %7s %7s[::-]\n", tr("Profile"), tr("Running"), tr("Connecting"), tr("Error"), tr("Stopped")))
+	for _, summary := range core.SummarizeProfiles(tunnels) {
+		name := summary.Profile
+		if name == a.currentProfile {
+			name += " *"
+		}
+		profiles.WriteString(fmt.Sprintf("%-16s [%s]%7d[-] [%s]%10d[-] [%s]%7d[-] [gray]%7d[-]\n",
+			tview.Escape(name), getColorName(a.colors.good), summary.Running, getColorName(a.colors.warn), summary.Connecting, getColorName(a.colors.bad), summary.Failed, summary.Stopped))
+	}
+	d.profiles.SetText(profiles.String())
+
+	// Most recent failures, with the error of tunnels still failed
+	var failures strings.Builder
+	stats, err := a.tunnelManager.AllStats()
+	if err != nil {
+		failures.WriteString(fmt.Sprintf("[red]%s[-]\n", tview.Escape(err.Error())))
+	}
+	recent := core.RecentFailures(tunnels, stats, dashboardListLength)
+	if len(recent) == 0 && err == nil {
+		failures.WriteString(tr("[gray]No failures recorded[-]") + "\n")
+	}
+	for _, failure := range recent {
+		failures.WriteString(trf("%s  %s [gray]%s ago[-]\n",
+			failure.Time.Format("01-02 15:04"), tview.Escape(failure.Tunnel.Name), core.FormatDuration(time.Since(failure.Time))))
+		if failure.Tunnel.Status == core.StatusError && failure.Tunnel.LastError != nil {
+			failures.WriteString(fmt.Sprintf("  [red]%s[-]\n", tview.Escape(failure.Tunnel.LastError.Error())))
+		}
+	}
+	d.failures.SetText(failures.String())
+
+	// Tunnels up the longest
+	var longest strings.Builder
+	running := core.LongestRunning(tunnels, dashboardListLength)
+	if len(running) == 0 {
+		longest.WriteString(tr("[gray]No tunnels running[-]") + "\n")
+	}
+	for _, tunnel := range running {
+		longest.WriteString(fmt.Sprintf("[%s]%10s[-]  %s [gray](%s)[-]\n",
+			getColorName(a.colors.good), core.FormatDuration(time.Since(*tunnel.StartedAt)), tview.Escape(tunnel.Name), tview.Escape(tunnel.ForwardSummary())))
+	}
+	d.longest.SetText(longest.String())
+
+	// Status changes seen by this session, newest first
+	var events strings.Builder
+	if len(a.events) == 0 {
+		events.WriteString(tr("[gray]No status changes since the TUI started[-]") + "\n")
+	}
+	for i := len(a.events) - 1; i >= 0; i-- {
+		event := a.events[i]
+		name := event.TunnelName
+		if name == "" {
+			name = event.TunnelID
+		}
+		_, color := a.formatStatus(event.NewStatus)
+		line := fmt.Sprintf("%s  %s %s %s [%s]%s[-]", event.Time.Format("15:04:05"), tview.Escape(name), tr(string(event.OldStatus)), a.symbols.arrow, getColorName(color), tr(string(event.NewStatus)))
+		if event.Error != "" {
+			line += fmt.Sprintf(" [gray]%s[-]", tview.Escape(event.Error))
+		}
+		events.WriteString(line + "\n")
+	}
+	d.events.SetText(events.String())
+}
@@ -0,0 +1,162 @@
+// Package tui provides grouping of the tunnel list by SSH host
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// hostGroup is the tunnels of the list going through one SSH host
+type hostGroup struct {
+	host    string
+	tunnels []*core.Tunnel
+	running int
+}
+
+// groupTunnels splits the sorted tunnels by SSH host, ordering the groups by
+// host name. Outside the grouped view all tunnels form one group.
+func (a *App) groupTunnels(tunnels []*core.Tunnel) []*hostGroup {
+	if !a.groupByHost {
+		return []*hostGroup{{tunnels: tunnels}}
+	}
+
+	groups := make(map[string]*hostGroup)
+	for _, tunnel := range tunnels {
+		group, ok := groups[tunnel.SSHHost]
+		if !ok {
+			group = &hostGroup{host: tunnel.SSHHost}
+			groups[tunnel.SSHHost] = group
+		}
+		group.tunnels = append(group.tunnels, tunnel)
+		if tunnel.Status == core.StatusRunning {
+			group.running++
+		}
+	}
+
+	sorted := make([]*hostGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].host < sorted[j].host
+	})
+	return sorted
+}
+
+// setGroupRow fills a row of the tunnel list with the header of a host group
+func (a *App) setGroupRow(rowNum int, group *hostGroup) {
+	icon := a.symbols.expanded
+	if a.collapsedHosts[group.host] {
+		icon = a.symbols.collapsed
+	}
+	countColor := tcell.ColorGray
+	if group.running > 0 {
+		countColor = a.colors.good
+	}
+
+	cells := []*tview.TableCell{
+		tview.NewTableCell(icon).SetTextColor(tcell.ColorYellow).SetAlign(tview.AlignCenter),
+		tview.NewTableCell(group.host).SetTextColor(tcell.ColorAqua).SetAttributes(tcell.AttrBold),
+		tview.NewTableCell(trf("%d/%d running", group.running, len(group.tunnels))).SetTextColor(countColor),
+	}
+	for col := range a.tunnelList.GetColumnCount() {
+		cell := tview.NewTableCell("")
+		if col < len(cells) {
+			cell = cells[col]
+		}
+		a.tunnelList.SetCell(rowNum, col, cell.SetReference(group))
+	}
+}
+
+// toggleGroupView switches between the flat and the grouped tunnel list
+func (a *App) toggleGroupView() {
+	a.groupByHost = !a.groupByHost
+	a.updateTunnelList()
+
+	if err := a.saveUIState(); err != nil {
+		a.updateStatusBar(trf("[red]Failed to save view: %v[-]", err))
+		return
+	}
+	if a.groupByHost {
+		a.updateStatusBar(tr("Grouped by SSH host | Space: collapse/expand"))
+	} else {
+		a.updateStatusBar("")
+	}
+}
+
+// setGroupCollapsed collapses or expands the group of a host
+func (a *App) setGroupCollapsed(host string, collapsed bool) {
+	if collapsed {
+		a.collapsedHosts[host] = true
+	} else {
+		delete(a.collapsedHosts, host)
+	}
+	a.updateTunnelList()
+}
+
+// handleGroupKeys expands and collapses host groups, reporting whether it
+// handled the key
+func (a *App) handleGroupKeys(event *tcell.EventKey) bool {
+	if !a.groupByHost {
+		return false
+	}
+
+	host := a.selectedGroup
+	if a.selectedTunnel != nil {
+		host = a.selectedTunnel.SSHHost
+	}
+	if host == "" {
+		return false
+	}
+
+	switch {
+	case event.Key() == tcell.KeyLeft:
+		a.selectedTunnel, a.selectedGroup = nil, host
+		a.setGroupCollapsed(host, true)
+	case event.Key() == tcell.KeyRight && a.selectedTunnel == nil:
+		a.setGroupCollapsed(host, false)
+	case (event.Key() == tcell.KeyEnter || event.Rune() == ' ') && a.selectedTunnel == nil:
+		a.setGroupCollapsed(host, !a.collapsedHosts[host])
+	default:
+		return false
+	}
+	return true
+}
+
+// showGroupDetail shows the tunnels of a host group in the detail view
+func (a *App) showGroupDetail(group *hostGroup) {
+	details := strings.Builder{}
+	details.WriteString(fmt.Sprintf("[::b]%s[::-]\n", tview.Escape(group.host)))
+	details.WriteString(trf("[gray]%d tunnel(s), %d running[::-]\n\n", len(group.tunnels), group.running))
+	for _, tunnel := range group.tunnels {
+		status, color := a.formatStatus(tunnel.Status)
+		details.WriteString(fmt.Sprintf("[%s]%s[-] %s  [gray]%s[-]\n", getColorName(color), status, tview.Escape(tunnel.Name), tview.Escape(tunnel.ForwardSummary())))
+	}
+	a.setDetailText(details.String(), nil)
+	a.detailView.ScrollToBeginning()
+}
+
+// findTunnelRow returns the list row of a tunnel, or -1 if it is not shown
+func (a *App) findTunnelRow(id string) int {
+	for row := 1; row < a.tunnelList.GetRowCount(); row++ {
+		if tunnel, ok := a.tunnelList.GetCell(row, 1).GetReference().(*core.Tunnel); ok && tunnel.ID == id {
+			return row
+		}
+	}
+	return -1
+}
+
+// findGroupRow returns the list row of a host group, or -1 if it is not shown
+func (a *App) findGroupRow(host string) int {
+	for row := 1; row < a.tunnelList.GetRowCount(); row++ {
+		if group, ok := a.tunnelList.GetCell(row, 1).GetReference().(*hostGroup); ok && group.host == host {
+			return row
+		}
+	}
+	return -1
+}
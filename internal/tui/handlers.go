@@ -2,7 +2,9 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -12,14 +14,9 @@ import (
 
 // handleGlobalKeys handles global keyboard shortcuts
 func (a *App) handleGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
-	// Check if any modal dialog is active
-	// Modal pages that should block global shortcuts
-	modalPages := []string{"add-tunnel", "edit-tunnel", "delete-confirm", "error", "filter-menu", "profile", "confirm", "ssh-import", "profile-mgmt"}
-	for _, page := range modalPages {
-		if a.pages.HasPage(page) {
-			// Let the modal handle the input
-			return event
-		}
+	// Let an open dialog handle the input
+	if a.modals.captures() {
+		return event
 	}
 
 	// Check if search mode is active
@@ -33,6 +30,10 @@ func (a *App) handleGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
 		a.shutdown()
 		return nil
 
+	case tcell.KeyCtrlP:
+		a.showCommandPalette()
+		return nil
+
 	case tcell.KeyRune:
 		switch event.Rune() {
 		case 'q', 'Q':
@@ -43,10 +44,14 @@ func (a *App) handleGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
 			a.showHelp()
 			return nil
 
-		case 'c', 'C':
+		case 'c':
 			a.showAddTunnelForm()
 			return nil
 
+		case 'C':
+			a.duplicateTunnel()
+			return nil
+
 		case 'A':
 			a.startAllTunnels()
 			return nil
@@ -59,10 +64,34 @@ func (a *App) handleGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
 			a.startSearch()
 			return nil
 
-		case 'f', 'F':
+		case 'f':
 			a.toggleTunnelMode()
 			return nil
 
+		case 'F':
+			a.showFilterMenu()
+			return nil
+
+		case 'x':
+			a.clearFilter()
+			return nil
+
+		case 'G':
+			a.toggleGroupView()
+			return nil
+
+		case 'b':
+			a.showDashboard()
+			return nil
+
+		case 'm':
+			a.showActivity()
+			return nil
+
+		case 't':
+			a.showTagMenu()
+			return nil
+
 		case 'g':
 			// Switch profile
 			a.showProfileMenu()
@@ -77,6 +106,36 @@ func (a *App) handleGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
 			// Import from SSH config
 			a.showSSHConfigImport()
 			return nil
+
+		case 'o':
+			// Sort by the next column
+			a.cycleSortField()
+			return nil
+
+		case 'O':
+			// Reverse the sort order
+			a.sortBy(a.sortField)
+			return nil
+
+		case 'l':
+			// Live log of the selected tunnel
+			a.toggleLogPane()
+			return nil
+
+		case 'S':
+			// Global defaults
+			a.showSettings()
+			return nil
+
+		case 'z':
+			// Undo the last config change
+			a.undoConfigChange()
+			return nil
+
+		case 'Z':
+			// Restore the last deleted tunnel
+			a.restoreTunnel("")
+			return nil
 		}
 	}
 
@@ -85,12 +144,14 @@ func (a *App) handleGlobalKeys(event *tcell.EventKey) *tcell.EventKey {
 
 // handleListKeys handles keyboard input for the tunnel list
 func (a *App) handleListKeys(event *tcell.EventKey) *tcell.EventKey {
-	// Check if any modal dialog is active - if so, don't process list keys
-	modalPages := []string{"add-tunnel", "edit-tunnel", "delete-confirm", "error", "filter-menu", "profile", "confirm", "ssh-import", "profile-mgmt"}
-	for _, page := range modalPages {
-		if a.pages.HasPage(page) {
-			return event
-		}
+	// Keys go to an open dialog rather than the list
+	if a.modals.captures() {
+		return event
+	}
+
+	// Host groups of the grouped view expand and collapse
+	if a.handleGroupKeys(event) {
+		return nil
 	}
 
 	switch event.Key() {
@@ -115,7 +176,7 @@ func (a *App) handleListKeys(event *tcell.EventKey) *tcell.EventKey {
 		return nil
 
 	case tcell.KeyRune:
-		if a.selectedTunnel == nil && event.Rune() != 'c' && event.Rune() != 'C' {
+		if a.selectedTunnel == nil && !strings.ContainsRune("cCjk", event.Rune()) {
 			return event
 		}
 
@@ -148,6 +209,30 @@ func (a *App) handleListKeys(event *tcell.EventKey) *tcell.EventKey {
 			}
 			return nil
 
+		case 'n':
+			// Rename without opening the form
+			a.showRenamePrompt()
+			return nil
+
+		case 'L':
+			// Change the local port without opening the form
+			a.showLocalPortPrompt()
+			return nil
+
+		case 'v':
+			// Preview the ssh command
+			if a.selectedTunnel != nil {
+				a.showSSHCommand(a.selectedTunnel)
+			}
+			return nil
+
+		case 'y':
+			// Copy the ssh command, endpoint or definition
+			if a.selectedTunnel != nil {
+				a.showCopyMenu(a.selectedTunnel)
+			}
+			return nil
+
 		case 'a':
 			// Toggle auto-connect
 			if a.selectedTunnel != nil {
@@ -194,18 +279,30 @@ func (a *App) startTunnel() {
 	if a.selectedTunnel == nil {
 		return
 	}
+	a.startTunnelByID(a.selectedTunnel.ID)
+}
 
-	a.updateStatusBar("Starting tunnel...")
-	err := a.tunnelManager.StartTunnel(a.selectedTunnel.ID)
-	if err != nil {
-		a.showErrorModal("Start Failed", err.Error())
-	} else {
-		a.updateStatusBar("✓ Tunnel started")
+// startTunnelByID starts a tunnel, which need not be in the current profile
+func (a *App) startTunnelByID(id string) {
+	a.updateStatusBar(tr("Starting tunnel..."))
+	err := a.tunnelManager.StartTunnel(id)
+	switch {
+	case err == nil:
+		a.updateStatusBar(tr("✓ Tunnel started"))
+	case errors.Is(err, core.ErrAlreadyRunning):
+		a.updateStatusBar(tr("Tunnel is already running"))
+	case errors.Is(err, core.ErrPortInUse):
+		a.showErrorModal(tr("Port In Use"), fmt.Sprintf("%v\n\n%s", err, core.FailureHint(err)))
+	default:
+		a.showErrorModal(tr("Start Failed"), err.Error())
 	}
 
 	// Update UI
 	a.updateTunnelList()
 	a.updateHeaderBar()
+	if a.selectedTunnel == nil {
+		return
+	}
 	if tunnel, err := a.tunnelManager.GetTunnel(a.selectedTunnel.ID); err == nil {
 		a.selectedTunnel = tunnel
 		a.updateDetailView(tunnel)
@@ -217,18 +314,28 @@ func (a *App) stopTunnel() {
 	if a.selectedTunnel == nil {
 		return
 	}
+	a.stopTunnelByID(a.selectedTunnel.ID)
+}
 
-	a.updateStatusBar("Stopping tunnel...")
-	err := a.tunnelManager.StopTunnel(a.selectedTunnel.ID)
-	if err != nil {
-		a.showErrorModal("Stop Failed", err.Error())
-	} else {
-		a.updateStatusBar("✓ Tunnel stopped")
+// stopTunnelByID stops a tunnel, which need not be in the current profile
+func (a *App) stopTunnelByID(id string) {
+	a.updateStatusBar(tr("Stopping tunnel..."))
+	err := a.tunnelManager.StopTunnel(id)
+	switch {
+	case err == nil:
+		a.updateStatusBar(tr("✓ Tunnel stopped"))
+	case errors.Is(err, core.ErrNotRunning):
+		a.updateStatusBar(tr("Tunnel is not running"))
+	default:
+		a.showErrorModal(tr("Stop Failed"), err.Error())
 	}
 
 	// Update UI
 	a.updateTunnelList()
 	a.updateHeaderBar()
+	if a.selectedTunnel == nil {
+		return
+	}
 	if tunnel, err := a.tunnelManager.GetTunnel(a.selectedTunnel.ID); err == nil {
 		a.selectedTunnel = tunnel
 		a.updateDetailView(tunnel)
@@ -237,12 +344,12 @@ func (a *App) stopTunnel() {
 
 // startAllTunnels starts all tunnels in the current profile
 func (a *App) startAllTunnels() {
-	a.updateStatusBar(fmt.Sprintf("Starting all tunnels in profile '%s'...", a.currentProfile))
+	a.updateStatusBar(trf("Starting all tunnels in profile '%s'...", a.currentProfile))
 	err := a.tunnelManager.StartProfileTunnels(a.currentProfile)
 	if err != nil {
-		a.updateStatusBar(fmt.Sprintf("Some tunnels failed to start: %v", err))
+		a.updateStatusBar(trf("Some tunnels failed to start: %v", err))
 	} else {
-		a.updateStatusBar(fmt.Sprintf("✓ Started all tunnels in profile '%s'", a.currentProfile))
+		a.updateStatusBar(trf("✓ Started all tunnels in profile '%s'", a.currentProfile))
 	}
 
 	a.updateTunnelList()
@@ -251,32 +358,149 @@ func (a *App) startAllTunnels() {
 
 // stopAllTunnels stops all running tunnels in the current profile
 func (a *App) stopAllTunnels() {
-	a.updateStatusBar(fmt.Sprintf("Stopping all tunnels in profile '%s'...", a.currentProfile))
+	a.updateStatusBar(trf("Stopping all tunnels in profile '%s'...", a.currentProfile))
 	err := a.tunnelManager.StopProfileTunnels(a.currentProfile)
 	if err != nil {
-		a.updateStatusBar(fmt.Sprintf("Some tunnels failed to stop: %v", err))
+		a.updateStatusBar(trf("Some tunnels failed to stop: %v", err))
 	} else {
-		a.updateStatusBar(fmt.Sprintf("✓ Stopped all tunnels in profile '%s'", a.currentProfile))
+		a.updateStatusBar(trf("✓ Stopped all tunnels in profile '%s'", a.currentProfile))
 	}
 
 	a.updateTunnelList()
 	a.updateHeaderBar()
 }
 
+// undoConfigChange restores the config before the last change
+func (a *App) undoConfigChange() {
+	snapshot, err := a.tunnelManager.UndoConfigChange()
+	if errors.Is(err, store.ErrNoHistory) {
+		a.updateStatusBar(tr("Nothing to undo"))
+		return
+	}
+	if err != nil {
+		a.showErrorModal(tr("Undo Failed"), err.Error())
+		return
+	}
+
+	a.updateStatusBar(trf("↶ Restored the config of %s", snapshot.Time.Local().Format("2006-01-02 15:04:05")))
+	a.updateTunnelList()
+	a.updateHeaderBar()
+	if a.selectedTunnel != nil {
+		if tunnel, err := a.tunnelManager.GetTunnel(a.selectedTunnel.ID); err == nil {
+			a.updateDetailView(tunnel)
+		}
+	}
+}
+
+// restoreTunnel restores a tunnel deleted in this session, the most recently
+// deleted one if id is empty, and selects it
+func (a *App) restoreTunnel(id string) {
+	tunnel, err := a.tunnelManager.RestoreTunnel(id)
+	if errors.Is(err, core.ErrTrashEmpty) {
+		a.updateStatusBar(tr("No deleted tunnel to restore"))
+		return
+	}
+	if err != nil {
+		a.showErrorModal(tr("Restore Failed"), err.Error())
+		return
+	}
+
+	a.updateStatusBar(trf("↶ Restored tunnel %s", tview.Escape(tunnel.Name)))
+	a.updateHeaderBar()
+	a.goToTunnel(tunnel)
+}
+
+// showTagMenu lets the user pick a tag to show, start or stop its tunnels
+func (a *App) showTagMenu() {
+	tags := a.tunnelManager.GetTags()
+	if len(tags) == 0 {
+		a.updateStatusBar(tr("No tagged tunnels (add tags in the tunnel form)"))
+		return
+	}
+
+	options := append(append([]string(nil), tags...), tr("Cancel"))
+	modal := tview.NewModal().
+		SetText(tr("Select tag:")).
+		AddButtons(options).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.modals.pop("tags")
+			if buttonIndex < 0 || buttonIndex >= len(tags) {
+				return
+			}
+			a.showTagActions(tags[buttonIndex])
+		})
+
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.modals.pop("tags")
+			return nil
+		}
+		return event
+	})
+
+	a.modals.push("tags", modal, modal)
+}
+
+// showTagActions offers to highlight, start or stop the tunnels with a tag
+func (a *App) showTagActions(tag string) {
+	count := len(a.tunnelManager.GetTunnelsByTag(tag))
+
+	modal := tview.NewModal().
+		SetText(trf("Tag '%s': %d tunnel(s)", tview.Escape(tag), count)).
+		AddButtons([]string{tr("Show"), tr("Start All"), tr("Stop All"), tr("Cancel")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.modals.pop("tags")
+
+			switch buttonLabel {
+			case tr("Show"):
+				a.FilterTunnels("tag:" + tag)
+				return
+			case tr("Start All"):
+				a.updateStatusBar(trf("Starting tunnels tagged '%s'...", tag))
+				if err := a.tunnelManager.StartByTag(tag); err != nil {
+					a.updateStatusBar(trf("Some tunnels failed to start: %v", err))
+				} else {
+					a.updateStatusBar(trf("✓ Started tunnels tagged '%s'", tag))
+				}
+			case tr("Stop All"):
+				a.updateStatusBar(trf("Stopping tunnels tagged '%s'...", tag))
+				if err := a.tunnelManager.StopByTag(tag); err != nil {
+					a.updateStatusBar(trf("Some tunnels failed to stop: %v", err))
+				} else {
+					a.updateStatusBar(trf("✓ Stopped tunnels tagged '%s'", tag))
+				}
+			default:
+				return
+			}
+			a.updateTunnelList()
+			a.updateHeaderBar()
+		})
+
+	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.modals.pop("tags")
+			return nil
+		}
+		return event
+	})
+
+	a.modals.push("tags", modal, modal)
+}
+
 // restartTunnel restarts the selected tunnel
 func (a *App) restartTunnel() {
 	if a.selectedTunnel == nil {
 		return
 	}
 
-	a.updateStatusBar("Restarting tunnel...")
+	a.updateStatusBar(tr("Restarting tunnel..."))
 
 	if err := a.tunnelManager.RestartTunnel(a.selectedTunnel.ID); err != nil {
-		a.showErrorModal("Restart Failed", err.Error())
+		a.showErrorModal(tr("Restart Failed"), err.Error())
 		return
 	}
 
-	a.updateStatusBar("Tunnel restarted")
+	a.updateStatusBar(tr("Tunnel restarted"))
 	a.updateTunnelList()
 
 	if tunnel, err := a.tunnelManager.GetTunnel(a.selectedTunnel.ID); err == nil {
@@ -295,15 +519,15 @@ func (a *App) toggleAutoConnect() {
 	tunnel.AutoConnect = !tunnel.AutoConnect
 
 	if err := a.tunnelManager.UpdateTunnel(tunnel); err != nil {
-		a.showErrorModal("Update Failed", err.Error())
+		a.showErrorModal(tr("Update Failed"), err.Error())
 		return
 	}
 
-	status := "disabled"
+	status := tr("disabled")
 	if tunnel.AutoConnect {
-		status = "enabled"
+		status = tr("enabled")
 	}
-	a.updateStatusBar(fmt.Sprintf("✓ Auto-connect %s", status))
+	a.updateStatusBar(trf("✓ Auto-connect %s", status))
 
 	a.selectedTunnel = tunnel
 	a.updateTunnelList()
@@ -313,34 +537,37 @@ func (a *App) toggleAutoConnect() {
 // toggleTunnelMode toggles the selected tunnel between forward and reverse mode
 func (a *App) toggleTunnelMode() {
 	if a.selectedTunnel == nil {
-		a.updateStatusBar("⚠ No tunnel selected")
+		a.updateStatusBar(tr("⚠ No tunnel selected"))
 		return
 	}
 
 	// Check if tunnel is running
 	if a.selectedTunnel.Status == core.StatusRunning {
-		a.updateStatusBar("⚠ Stop the tunnel before changing mode")
+		a.updateStatusBar(tr("⚠ Stop the tunnel before changing mode"))
 		return
 	}
 
 	// Remember current selection position
 	currentRow, _ := a.tunnelList.GetSelection()
 
-	// Toggle between forward and reverse (skip dynamic)
+	// Toggle between forward and reverse, and between local and remote SOCKS
 	switch a.selectedTunnel.Type {
 	case core.LocalForward:
 		a.selectedTunnel.Type = core.RemoteForward
 	case core.RemoteForward:
 		a.selectedTunnel.Type = core.LocalForward
 	case core.DynamicForward:
-		// Dynamic forward stays as is
-		a.updateStatusBar("⚠ Dynamic forward mode cannot be toggled")
-		return
+		// The SOCKS proxy moves to the SSH host, on the same port
+		a.selectedTunnel.Type = core.ReverseDynamicForward
+		a.selectedTunnel.RemotePort = a.selectedTunnel.LocalPort
+	case core.ReverseDynamicForward:
+		a.selectedTunnel.Type = core.DynamicForward
+		a.selectedTunnel.LocalPort = a.selectedTunnel.RemotePort
 	}
 
 	// Save the change
 	if err := a.tunnelManager.UpdateTunnel(a.selectedTunnel); err != nil {
-		a.showErrorModal("Update Failed", err.Error())
+		a.showErrorModal(tr("Update Failed"), err.Error())
 		return
 	}
 
@@ -354,33 +581,41 @@ func (a *App) toggleTunnelMode() {
 
 	a.updateDetailView(a.selectedTunnel)
 
-	modeStr := "forward"
-	if a.selectedTunnel.Type == core.RemoteForward {
-		modeStr = "reverse"
+	modeStr := tr("forward")
+	switch a.selectedTunnel.Type {
+	case core.RemoteForward:
+		modeStr = tr("reverse")
+	case core.DynamicForward:
+		modeStr = tr("dynamic")
+	case core.ReverseDynamicForward:
+		modeStr = tr("reverse dynamic")
 	}
-	a.updateStatusBar(fmt.Sprintf("✓ Mode changed to %s", modeStr))
+	a.updateStatusBar(trf("✓ Mode changed to %s", modeStr))
 }
 
 // showFilterMenu shows the filter menu
 func (a *App) showFilterMenu() {
 	filterOptions := []string{
-		"All Tunnels",
-		"Running",
-		"Stopped",
-		"Error",
-		"Auto-connect",
-		"Local Forward",
-		"Remote Forward",
-		"Dynamic/SOCKS",
+		tr("All Tunnels"),
+		tr("Running"),
+		tr("Stopped"),
+		tr("Error"),
+		tr("Auto-connect"),
+		tr("Local Forward"),
+		tr("Remote Forward"),
+		tr("Dynamic/SOCKS"),
+		tr("By Tag..."),
 	}
 
 	modal := tview.NewModal().
-		SetText("Select filter:").
+		SetText(tr("Select filter:")).
 		AddButtons(filterOptions).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.modals.pop("filter-menu")
+
 			switch buttonIndex {
 			case 0:
-				a.updateTunnelList() // Show all
+				a.FilterTunnels("") // Show all
 			case 1:
 				a.FilterTunnels("running")
 			case 2:
@@ -395,23 +630,40 @@ func (a *App) showFilterMenu() {
 				a.FilterTunnels("remote")
 			case 7:
 				a.FilterTunnels("dynamic")
+			case 8:
+				a.showTagMenu()
 			}
-			a.pages.RemovePage("filter")
-			a.app.SetFocus(a.tunnelList)
 		})
 
-	a.pages.AddPage("filter", modal, true, true)
-	a.app.SetFocus(modal)
+	a.modals.push("filter-menu", modal, modal)
 }
 
 // showHelp displays the help modal
 func (a *App) showHelp() {
-	a.pages.ShowPage("help")
-	a.app.SetFocus(a.helpView)
+	a.modals.show("help", a.helpView, a.helpView)
 }
 
 // Removed - now using showAddTunnelForm from modals.go
 
+// duplicateTunnel copies the selected tunnel and opens the copy for editing
+func (a *App) duplicateTunnel() {
+	if a.selectedTunnel == nil {
+		return
+	}
+
+	duplicate, err := a.tunnelManager.DuplicateTunnel(a.selectedTunnel.ID)
+	if err != nil {
+		a.showErrorModal(tr("Duplicate Failed"), err.Error())
+		return
+	}
+
+	a.selectedTunnel = duplicate
+	a.updateTunnelList()
+	a.updateDetailView(duplicate)
+	a.updateStatusBar(trf("✓ Duplicated as '%s'", duplicate.Name))
+	a.showEditTunnelDialog()
+}
+
 // showEditTunnelDialog shows the dialog for editing a tunnel
 func (a *App) showEditTunnelDialog() {
 	if a.selectedTunnel == nil {
@@ -419,7 +671,7 @@ func (a *App) showEditTunnelDialog() {
 	}
 
 	if a.selectedTunnel.Status == core.StatusRunning {
-		a.showErrorModal("Cannot Edit", "Stop the tunnel before editing")
+		a.showErrorModal(tr("Cannot Edit"), tr("Stop the tunnel before editing"))
 		return
 	}
 
@@ -429,8 +681,7 @@ func (a *App) showEditTunnelDialog() {
 	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// Allow ESC to close the form
 		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("edit-tunnel")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("edit-tunnel")
 			return nil
 		}
 		// Let the form handle all other input
@@ -438,8 +689,44 @@ func (a *App) showEditTunnelDialog() {
 	})
 
 	modal := a.createModalOverlay(form, 70, 25)
-	a.pages.AddPage("edit-tunnel", modal, true, true)
-	a.app.SetFocus(form)
+	a.modals.push("edit-tunnel", modal, form)
+}
+
+// showSSHCommand shows the exact ssh command a tunnel would run, or why it
+// would not start
+func (a *App) showSSHCommand(tunnel *core.Tunnel) {
+	command, err := a.tunnelManager.Preview(tunnel.ID)
+	if err != nil {
+		a.showErrorModal(tr("Tunnel Would Not Start"), tview.Escape(err.Error()))
+		return
+	}
+
+	text := tview.NewTextView().
+		SetDynamicColors(true).
+		SetWrap(true).
+		SetText(fmt.Sprintf("[yellow]%s[::-]\n\n%s", tview.Escape(tunnel.Name), tview.Escape(core.QuoteCommand(command))))
+
+	button := a.createButton(tr("OK"), func() {
+		a.modals.pop("ssh-command")
+	})
+
+	buttonContainer := tview.NewFlex().
+		SetDirection(tview.FlexColumn).
+		AddItem(nil, 0, 1, false).
+		AddItem(button, 10, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	container := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(text, 0, 1, false).
+		AddItem(buttonContainer, 3, 0, true)
+
+	container.SetBorder(true).
+		SetTitle(tr(" SSH Command ")).
+		SetTitleAlign(tview.AlignCenter)
+
+	modal := a.createModalOverlay(container, 80, 16)
+	a.modals.push("ssh-command", modal, button)
 }
 
 // Removed - using forms from modals.go
@@ -457,25 +744,73 @@ func (a *App) confirmQuit() {
 		}
 	}
 
-	message := "Are you sure you want to quit?"
+	message := tr("Are you sure you want to quit?")
 	if runningCount > 0 {
-		message = fmt.Sprintf("%d tunnel(s) are still running.\n%s", runningCount, message)
+		message = trf("%d tunnel(s) are still running.\n%s", runningCount, message)
 	}
 
 	modal := tview.NewModal().
 		SetText(message).
-		AddButtons([]string{"Quit", "Cancel"}).
+		AddButtons([]string{tr("Quit"), tr("Cancel")}).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel == "Quit" {
+			if buttonLabel == tr("Quit") {
 				a.shutdown()
 			} else {
-				a.pages.RemovePage("confirm")
-				a.app.SetFocus(a.tunnelList)
+				a.modals.pop("confirm")
+			}
+		})
+
+	a.modals.push("confirm", modal, modal)
+}
+
+// confirmOrphans asks what to do with untracked ssh processes that match
+// configured tunnels, calling then once the user has chosen. It reports whether
+// a dialog was shown.
+func (a *App) confirmOrphans(then func()) bool {
+	orphans, err := a.tunnelManager.FindOrphanedProcesses()
+	if err != nil {
+		core.Debug("Failed to look for untracked ssh processes: %v", err)
+		return false
+	}
+
+	var matched []core.OrphanProcess
+	var lines []string
+	for _, orphan := range orphans {
+		if orphan.TunnelID != "" {
+			matched = append(matched, orphan)
+			lines = append(lines, trf("%s (PID %d)", orphan.TunnelName, orphan.PID))
+		}
+	}
+	if len(matched) == 0 {
+		return false
+	}
+
+	message := trf("Found ssh processes of these tunnels that are not tracked:\n\n%s\n\nAdopt them, or kill them?",
+		strings.Join(lines, "\n"))
+
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{tr("Adopt"), tr("Kill"), tr("Ignore")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			for _, orphan := range matched {
+				var err error
+				switch buttonLabel {
+				case tr("Adopt"):
+					err = a.tunnelManager.AdoptOrphan(orphan)
+				case tr("Kill"):
+					err = a.tunnelManager.KillOrphan(orphan)
+				}
+				if err != nil {
+					core.Error("Failed to handle PID %d of tunnel %s: %v", orphan.PID, orphan.TunnelName, err)
+				}
 			}
+			a.modals.pop("orphans")
+			a.updateTunnelList()
+			then()
 		})
 
-	a.pages.AddPage("confirm", modal, true, true)
-	a.app.SetFocus(modal)
+	a.modals.push("orphans", modal, modal)
+	return true
 }
 
 // Removed - now using showErrorModal from modals.go
@@ -490,7 +825,7 @@ func (a *App) shutdown() {
 func (a *App) showProfileMenu() {
 	config, err := a.configStore.LoadConfig()
 	if err != nil {
-		a.showErrorModal("Error", "Failed to load profiles")
+		a.showErrorModal(tr("Error"), tr("Failed to load profiles"))
 		return
 	}
 
@@ -500,87 +835,106 @@ func (a *App) showProfileMenu() {
 			profileOptions = append(profileOptions, profile.Name)
 		}
 	}
-	profileOptions = append(profileOptions, "Cancel")
+	profileOptions = append(profileOptions, tr("Cancel"))
 
 	modal := tview.NewModal().
-		SetText(fmt.Sprintf("Current profile: %s\n\nSelect profile:", a.currentProfile)).
+		SetText(trf("Current profile: %s\n\nSelect profile:", a.currentProfile)).
 		AddButtons(profileOptions).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			if buttonLabel != "Cancel" && buttonIndex < len(profileOptions)-1 {
-				a.currentProfile = buttonLabel
-				a.updateStatusBar(fmt.Sprintf("Switched to profile: %s", a.currentProfile))
-				a.updateTunnelList()
-				a.updateHeaderBar()
+			if buttonLabel != tr("Cancel") && buttonIndex < len(profileOptions)-1 {
+				a.switchProfile(buttonLabel)
 			}
-			a.pages.RemovePage("profile")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("profile")
 		})
 
 	// Add InputCapture to handle ESC key properly
 	modal.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("profile")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("profile")
 			return nil
 		}
 		// Let the modal handle all other input
 		return event
 	})
 
-	a.pages.AddPage("profile", modal, true, true)
-	a.app.SetFocus(modal)
+	a.modals.push("profile", modal, modal)
+}
+
+// switchProfile shows the tunnels of another profile and applies its settings
+func (a *App) switchProfile(name string) {
+	previous := a.currentProfile
+	a.currentProfile = name
+	a.updateStatusBar(trf("Switched to profile: %s", a.currentProfile))
+	a.updateTunnelList()
+	a.updateHeaderBar()
+	go a.applyProfileSwitch(previous, name)
+}
+
+// applyProfileSwitch starts and stops tunnels as the settings of the profile
+// switched to ask for, reporting failures in the status bar
+func (a *App) applyProfileSwitch(from, to string) {
+	err := a.tunnelManager.SwitchProfile(from, to)
+	a.app.QueueUpdateDraw(func() {
+		if err != nil {
+			a.updateStatusBar(trf("Profile %s: %v", to, err))
+		}
+		a.updateTunnelList()
+		a.updateHeaderBar()
+	})
 }
 
 // showProfileManagement shows the profile management dialog
 func (a *App) showProfileManagement() {
 	form := tview.NewForm()
 	form.SetBorder(true).
-		SetTitle(" Profile Management ").
+		SetTitle(tr(" Profile Management ")).
 		SetTitleAlign(tview.AlignCenter)
 
 	// Add dropdown for action selection
-	actions := []string{"Create New Profile", "Delete Profile", "Cancel"}
-	form.AddDropDown("Action", actions, 0, nil)
+	actions := []string{tr("Create New Profile"), tr("Rename Profile"), tr("Clone Profile"), tr("Delete Profile"), tr("Cancel")}
+	form.AddDropDown(tr("Action"), actions, 0, nil)
 
 	// Add input field for profile name
-	form.AddInputField("Profile Name", "", 30, nil, nil)
+	form.AddInputField(tr("Profile Name"), "", 30, nil, nil)
+
+	// Rename moves the profile's tunnels to the new name; clone copies them
+	form.AddInputField(tr("New Name"), "", 30, nil, nil)
 
 	// Set InputCapture to prevent global key handlers from interfering
 	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// Allow ESC to close the form
 		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("profile-mgmt")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("profile-mgmt")
 			return nil
 		}
 		// Let the form handle all other input
 		return event
 	})
 
-	form.AddButton("Execute", func() {
-		_, action := form.GetFormItemByLabel("Action").(*tview.DropDown).GetCurrentOption()
-		profileName := form.GetFormItemByLabel("Profile Name").(*tview.InputField).GetText()
+	form.AddButton(tr("Execute"), func() {
+		_, action := form.GetFormItemByLabel(tr("Action")).(*tview.DropDown).GetCurrentOption()
+		profileName := form.GetFormItemByLabel(tr("Profile Name")).(*tview.InputField).GetText()
 
-		if profileName == "" && action != "Cancel" {
-			a.showErrorModal("Error", "Profile name is required")
+		if profileName == "" && action != tr("Cancel") {
+			a.showErrorModal(tr("Error"), tr("Profile name is required"))
 			return
 		}
 
 		switch action {
-		case "Create New Profile":
+		case tr("Create New Profile"):
 			// Create a new profile
 			config, err := a.configStore.LoadConfig()
 			if err != nil {
-				a.pages.RemovePage("profile-mgmt")
-				a.showErrorModal("Error", "Failed to load config")
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), tr("Failed to load config"))
 				return
 			}
 
 			// Check if profile already exists
 			for _, p := range config.Profiles {
 				if p.Name == profileName {
-					a.pages.RemovePage("profile-mgmt")
-					a.showErrorModal("Error", "Profile already exists")
+					a.modals.pop("profile-mgmt")
+					a.showErrorModal(tr("Error"), tr("Profile already exists"))
 					return
 				}
 			}
@@ -594,25 +948,58 @@ func (a *App) showProfileManagement() {
 
 			// Save config
 			if err := a.configStore.SaveConfig(config); err != nil {
-				a.pages.RemovePage("profile-mgmt")
-				a.showErrorModal("Error", "Failed to save profile")
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), tr("Failed to save profile"))
 				return
 			}
 
-			a.updateStatusBar(fmt.Sprintf("✓ Created profile: %s", profileName))
+			a.updateStatusBar(trf("✓ Created profile: %s", profileName))
 
-		case "Delete Profile":
+		case tr("Rename Profile"):
+			newName := strings.TrimSpace(form.GetFormItemByLabel(tr("New Name")).(*tview.InputField).GetText())
+			if err := a.tunnelManager.RenameProfile(profileName, newName); err != nil {
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), err.Error())
+				return
+			}
+
+			// Keep showing the renamed profile
+			if a.currentProfile == profileName {
+				a.currentProfile = newName
+			}
+			a.updateTunnelList()
+			a.updateHeaderBar()
+
+			a.updateStatusBar(trf("✓ Renamed profile %s to %s", profileName, newName))
+
+		case tr("Clone Profile"):
+			newName := strings.TrimSpace(form.GetFormItemByLabel(tr("New Name")).(*tview.InputField).GetText())
+			copies, err := a.tunnelManager.CloneProfile(profileName, newName)
+			if err != nil {
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), err.Error())
+				return
+			}
+
+			// Show the copies, which are stopped until started from there
+			a.currentProfile = newName
+			a.updateTunnelList()
+			a.updateHeaderBar()
+
+			a.updateStatusBar(trf("✓ Cloned profile %s to %s (%d tunnels)", profileName, newName, len(copies)))
+
+		case tr("Delete Profile"):
 			if profileName == "default" {
-				a.pages.RemovePage("profile-mgmt")
-				a.showErrorModal("Error", "Cannot delete default profile")
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), tr("Cannot delete default profile"))
 				return
 			}
 
 			// Load config and remove profile
 			config, err := a.configStore.LoadConfig()
 			if err != nil {
-				a.pages.RemovePage("profile-mgmt")
-				a.showErrorModal("Error", "Failed to load config")
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), tr("Failed to load config"))
 				return
 			}
 
@@ -628,8 +1015,8 @@ func (a *App) showProfileManagement() {
 			}
 
 			if !found {
-				a.pages.RemovePage("profile-mgmt")
-				a.showErrorModal("Error", "Profile not found")
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), tr("Profile not found"))
 				return
 			}
 
@@ -637,8 +1024,8 @@ func (a *App) showProfileManagement() {
 
 			// Save config
 			if err := a.configStore.SaveConfig(config); err != nil {
-				a.pages.RemovePage("profile-mgmt")
-				a.showErrorModal("Error", "Failed to delete profile")
+				a.modals.pop("profile-mgmt")
+				a.showErrorModal(tr("Error"), tr("Failed to delete profile"))
 				return
 			}
 
@@ -649,16 +1036,14 @@ func (a *App) showProfileManagement() {
 				a.updateHeaderBar()
 			}
 
-			a.updateStatusBar(fmt.Sprintf("✓ Deleted profile: %s", profileName))
+			a.updateStatusBar(trf("✓ Deleted profile: %s", profileName))
 		}
 
-		a.pages.RemovePage("profile-mgmt")
-		a.app.SetFocus(a.tunnelList)
+		a.modals.pop("profile-mgmt")
 	})
 
-	form.AddButton("Cancel", func() {
-		a.pages.RemovePage("profile-mgmt")
-		a.app.SetFocus(a.tunnelList)
+	form.AddButton(tr("Cancel"), func() {
+		a.modals.pop("profile-mgmt")
 	})
 
 	// Set form styles
@@ -667,9 +1052,8 @@ func (a *App) showProfileManagement() {
 	form.SetFieldTextColor(tcell.ColorWhite)
 	form.SetLabelColor(tcell.ColorYellow)
 
-	modal := a.createModalOverlay(form, 50, 12)
-	a.pages.AddPage("profile-mgmt", modal, true, true)
-	a.app.SetFocus(form)
+	modal := a.createModalOverlay(form, 50, 14)
+	a.modals.push("profile-mgmt", modal, form)
 }
 
 // showSSHConfigImport shows the SSH config import dialog
@@ -677,23 +1061,23 @@ func (a *App) showSSHConfigImport() {
 	// Load available SSH hosts
 	hosts, err := a.tunnelManager.LoadSSHConfigHosts()
 	if err != nil {
-		a.showErrorModal("Error", fmt.Sprintf("Failed to load SSH config: %v", err))
+		a.showErrorModal(tr("Error"), trf("Failed to load SSH config: %v", err))
 		return
 	}
 
 	if len(hosts) == 0 {
-		a.showErrorModal("No Hosts", "No hosts found in SSH config")
+		a.showErrorModal(tr("No Hosts"), tr("No hosts found in SSH config"))
 		return
 	}
 
 	// Create form for host selection
 	form := tview.NewForm()
 	form.SetBorder(true).
-		SetTitle(" Import from SSH Config ").
+		SetTitle(tr(" Import from SSH Config ")).
 		SetTitleAlign(tview.AlignCenter)
 
-	// Add dropdown for host selection
-	form.AddDropDown("Select Host", hosts, 0, nil)
+	// Add dropdown for host selection, offering all hosts at once first
+	form.AddDropDown(tr("Select Host"), append([]string{tr(sshImportAllHosts)}, hosts...), 0, nil)
 
 	// Load existing profiles for selection
 	config, _ := a.configStore.LoadConfig()
@@ -714,58 +1098,69 @@ func (a *App) showSSHConfigImport() {
 	}
 
 	// Add profile selection dropdown
-	form.AddDropDown("Import to Profile", profileOptions, defaultProfileIndex, nil)
+	form.AddDropDown(tr("Import to Profile"), profileOptions, defaultProfileIndex, nil)
 
 	// Add input field for new profile name
-	form.AddInputField("Or Create New Profile", "", 30, nil, nil)
+	form.AddInputField(tr("Or Create New Profile"), "", 30, nil, nil)
+
+	// Re-importing updates and removes the tunnels imported before
+	form.AddCheckbox(tr("Update Imported Tunnels"), false, nil)
 
 	// Set InputCapture to prevent global key handlers from interfering
 	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// Allow ESC to close the form
 		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("ssh-import")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("ssh-import")
 			return nil
 		}
 		// Let the form handle all other input
 		return event
 	})
 
-	form.AddButton("Import", func() {
-		_, selectedHost := form.GetFormItemByLabel("Select Host").(*tview.DropDown).GetCurrentOption()
+	form.AddButton(tr("Import"), func() {
+		hostIndex, selectedHost := form.GetFormItemByLabel(tr("Select Host")).(*tview.DropDown).GetCurrentOption()
 
 		// Get selected or new profile
-		newProfileName := form.GetFormItemByLabel("Or Create New Profile").(*tview.InputField).GetText()
+		newProfileName := form.GetFormItemByLabel(tr("Or Create New Profile")).(*tview.InputField).GetText()
 		var targetProfile string
 
 		if newProfileName != "" {
 			targetProfile = newProfileName
 		} else {
-			_, targetProfile = form.GetFormItemByLabel("Import to Profile").(*tview.DropDown).GetCurrentOption()
+			_, targetProfile = form.GetFormItemByLabel(tr("Import to Profile")).(*tview.DropDown).GetCurrentOption()
 		}
 
-		// Import tunnels from selected host
-		imported, err := a.tunnelManager.ImportFromSSHConfig(selectedHost)
-		if err != nil {
-			a.showErrorModal("Import Failed", err.Error())
-		} else {
-			// Update profile for imported tunnels
-			for _, tunnel := range imported {
-				tunnel.Profile = targetProfile
-				a.tunnelManager.UpdateTunnel(tunnel)
+		if form.GetFormItemByLabel(tr("Update Imported Tunnels")).(*tview.Checkbox).IsChecked() {
+			host := selectedHost
+			if hostIndex == 0 {
+				host = ""
 			}
-
-			a.updateTunnelList()
-			a.updateStatusBar(fmt.Sprintf("✓ Imported %d tunnel(s) from %s to profile '%s'", len(imported), selectedHost, targetProfile))
+			a.modals.pop("ssh-import")
+			a.syncSSHConfig(host, targetProfile)
+			return
 		}
 
-		a.pages.RemovePage("ssh-import")
-		a.app.SetFocus(a.tunnelList)
+		// Preview the tunnels of the selected host, or of all hosts
+		var tunnels []*core.Tunnel
+		var err error
+		if hostIndex == 0 {
+			tunnels, err = a.tunnelManager.DiscoverSSHConfigTunnels()
+			if err == nil && len(tunnels) == 0 {
+				err = fmt.Errorf("no host in the SSH config has LocalForward, RemoteForward or DynamicForward lines")
+			}
+		} else {
+			tunnels, err = a.tunnelManager.SSHConfigHostTunnels(selectedHost)
+		}
+		a.modals.pop("ssh-import")
+		if err != nil {
+			a.showErrorModal(tr("Import Failed"), err.Error())
+			return
+		}
+		a.showSSHConfigImportPreview(tunnels, targetProfile)
 	})
 
-	form.AddButton("Cancel", func() {
-		a.pages.RemovePage("ssh-import")
-		a.app.SetFocus(a.tunnelList)
+	form.AddButton(tr("Cancel"), func() {
+		a.modals.pop("ssh-import")
 	})
 
 	// Set form styles
@@ -774,9 +1169,181 @@ func (a *App) showSSHConfigImport() {
 	form.SetFieldTextColor(tcell.ColorWhite)
 	form.SetLabelColor(tcell.ColorYellow)
 
-	modal := a.createModalOverlay(form, 60, 12)
-	a.pages.AddPage("ssh-import", modal, true, true)
-	a.app.SetFocus(form)
+	modal := a.createModalOverlay(form, 60, 14)
+	a.modals.push("ssh-import", modal, form)
+}
+
+// syncSSHConfig imports a host from the SSH config again, or all hosts if
+// host is empty, updating the tunnels imported before, and shows what changed
+func (a *App) syncSSHConfig(host, profile string) {
+	result, err := a.tunnelManager.SyncSSHConfig(host, profile)
+	if err != nil {
+		a.showErrorModal(tr("Import Failed"), err.Error())
+		return
+	}
+	a.updateTunnelList()
+
+	var summary strings.Builder
+	for _, group := range []struct {
+		label string
+		names []string
+	}{
+		{tr("Added"), result.Added},
+		{tr("Updated"), result.Updated},
+		{tr("Removed"), result.Removed},
+		{tr("Skipped (running)"), result.Skipped},
+	} {
+		if len(group.names) > 0 {
+			summary.WriteString(fmt.Sprintf("%s (%d): %s\n", group.label, len(group.names), strings.Join(group.names, ", ")))
+		}
+	}
+	if summary.Len() == 0 {
+		summary.WriteString(tr("The imported tunnels match the SSH config"))
+	}
+
+	modal := tview.NewModal().
+		SetText(tview.Escape(strings.TrimSpace(summary.String()))).
+		AddButtons([]string{tr("OK")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.modals.pop("ssh-import-sync")
+		})
+	a.modals.push("ssh-import-sync", modal, modal)
+	a.updateStatusBar(trf("✓ Synced from SSH config: %d added, %d updated, %d removed", len(result.Added), len(result.Updated), len(result.Removed)))
+}
+
+// sshImportAllHosts is the host choice of the import dialog that imports
+// from all hosts at once
+const sshImportAllHosts = "All hosts with forwards..."
+
+// showSSHConfigImportPreview lists the tunnels an SSH config import would
+// create with checkboxes and editable names, and imports the selected ones
+// into profile in one pass
+func (a *App) showSSHConfigImportPreview(tunnels []*core.Tunnel, profile string) {
+	// Tunnels imported before are shown but cannot be selected again
+	selected := make([]bool, len(tunnels))
+	imported := make([]bool, len(tunnels))
+	for i, tunnel := range tunnels {
+		_, err := a.tunnelManager.GetTunnel(tunnel.ID)
+		imported[i] = err == nil
+		selected[i] = !imported[i]
+	}
+
+	table := tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	table.SetBorder(true).
+		SetTitle(trf(" Import into '%s': Space select, a all, r rename, Enter import, Esc cancel ", profile)).
+		SetTitleAlign(tview.AlignCenter)
+
+	for col, header := range []string{"", tr("Host"), tr("Name"), tr("Type"), tr("Ports")} {
+		table.SetCell(0, col, tview.NewTableCell(header).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false))
+	}
+	render := func(i int) {
+		mark, color := "[ ]", tcell.ColorWhite
+		switch {
+		case imported[i]:
+			mark, color = tr("imported"), tcell.ColorGray
+		case selected[i]:
+			mark = "[x]"
+		}
+		tunnel := tunnels[i]
+		table.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(mark)).SetTextColor(color))
+		table.SetCell(i+1, 1, tview.NewTableCell(tview.Escape(tunnel.SSHHost)).SetTextColor(color))
+		table.SetCell(i+1, 2, tview.NewTableCell(tview.Escape(tunnel.Name)).SetTextColor(color).SetExpansion(1))
+		table.SetCell(i+1, 3, tview.NewTableCell(string(tunnel.Type)).SetTextColor(color))
+		table.SetCell(i+1, 4, tview.NewTableCell(tview.Escape(tunnel.ForwardSummary())).SetTextColor(color))
+	}
+	for i := range tunnels {
+		render(i)
+	}
+	table.Select(1, 0)
+
+	closeDialog := func() {
+		a.modals.pop("ssh-import-preview")
+	}
+
+	// rename asks for a new name for the tunnel of row i
+	rename := func(i int) {
+		input := tview.NewInputField().
+			SetLabel(tr("Name: ")).
+			SetText(tunnels[i].Name).
+			SetFieldWidth(40)
+		input.SetBorder(true).SetTitle(tr(" Rename Tunnel "))
+		input.SetDoneFunc(func(key tcell.Key) {
+			if name := strings.TrimSpace(input.GetText()); key == tcell.KeyEnter && name != "" {
+				tunnels[i].Name = name
+				render(i)
+			}
+			a.modals.pop("ssh-import-rename")
+		})
+		a.modals.push("ssh-import-rename", a.createModalOverlay(input, 50, 3), input)
+	}
+
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		row, _ := table.GetSelection()
+		i := row - 1
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			closeDialog()
+			return nil
+
+		case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+			if i >= 0 && i < len(tunnels) && !imported[i] {
+				selected[i] = !selected[i]
+				render(i)
+			}
+			return nil
+
+		case event.Key() == tcell.KeyRune && event.Rune() == 'a':
+			// Select all, or none when all are selected
+			all := true
+			for j := range tunnels {
+				all = all && (selected[j] || imported[j])
+			}
+			for j := range tunnels {
+				if !imported[j] {
+					selected[j] = !all
+					render(j)
+				}
+			}
+			return nil
+
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			if i >= 0 && i < len(tunnels) && !imported[i] {
+				rename(i)
+			}
+			return nil
+
+		case event.Key() == tcell.KeyEnter:
+			var chosen []*core.Tunnel
+			hosts := make(map[string]bool)
+			for j, tunnel := range tunnels {
+				if selected[j] {
+					chosen = append(chosen, tunnel)
+					hosts[tunnel.SSHHost] = true
+				}
+			}
+			closeDialog()
+			if len(chosen) == 0 {
+				a.updateStatusBar(tr("Nothing selected to import"))
+				return nil
+			}
+			added, err := a.tunnelManager.AddImportedTunnels(chosen, profile)
+			if err != nil {
+				a.showErrorModal(tr("Import Failed"), err.Error())
+				return nil
+			}
+			a.updateTunnelList()
+			a.updateStatusBar(trf("✓ Imported %d tunnel(s) from %d host(s) to profile '%s'", len(added), len(hosts), profile))
+			return nil
+		}
+		return event
+	})
+
+	modal := a.createModalOverlay(table, 100, 20)
+	a.modals.push("ssh-import-preview", modal, table)
 }
 
 // Removed - helper functions no longer needed
\ No newline at end of file
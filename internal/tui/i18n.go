@@ -0,0 +1,75 @@
+// Package tui provides the translation of the TUI's messages
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// languages are the languages the TUI's messages are translated to, English
+// first, which the messages are written in
+var languages = []string{"en", "ja"}
+
+// languageNames are the names of the languages in themselves
+var languageNames = map[string]string{
+	"en": "English",
+	"ja": "日本語",
+}
+
+// catalogs translate the TUI's messages, by language and English message
+var catalogs = map[string]map[string]string{
+	"ja": japaneseMessages,
+}
+
+// language is the language the TUI's messages are shown in
+var language = "en"
+
+// setLanguage shows the TUI's messages in the language of a config setting,
+// or of the environment if the setting is empty
+func setLanguage(setting string) {
+	language = detectLanguage(setting, os.Getenv)
+}
+
+// detectLanguage returns the language of a config setting, or else of the
+// first of LC_ALL, LC_MESSAGES and LANG that is set, English if it has no
+// translation
+func detectLanguage(setting string, getenv func(string) string) string {
+	locale := setting
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale != "" {
+			break
+		}
+		locale = getenv(name)
+	}
+
+	// Locales look like ja_JP.UTF-8
+	code, _, _ := strings.Cut(strings.ToLower(locale), "_")
+	code, _, _ = strings.Cut(code, ".")
+	if _, ok := catalogs[code]; ok {
+		return code
+	}
+	return "en"
+}
+
+// tr returns the translation of a message, or the message itself if it has
+// none
+func tr(message string) string {
+	if translated, ok := catalogs[language][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// trf formats the translation of a message like fmt.Sprintf. Translations
+// may reorder the arguments with explicit indexes such as %[2]s.
+func trf(format string, args ...any) string {
+	return fmt.Sprintf(tr(format), args...)
+}
+
+// translateViews sets the texts the views got when they were created in the
+// current language, after the language setting changed
+func (a *App) translateViews() {
+	a.detailView.SetTitle(tr(" Details "))
+	a.helpView.SetText(tr(helpText)).SetTitle(tr(" Help "))
+}
@@ -0,0 +1,94 @@
+// Package tui provides the keymap behind the footer's key hints
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// keyHint is a key shown in the footer with the action it runs
+type keyHint struct {
+	key    string
+	action string
+}
+
+// Key hints shared by several contexts
+var (
+	formKeys   = []keyHint{{"Tab", "Next field"}, {"Enter", "Select"}, {"Esc", "Cancel"}}
+	promptKeys = []keyHint{{"Enter", "Save"}, {"Esc", "Cancel"}}
+	dialogKeys = []keyHint{{"←/→", "Choose"}, {"Enter", "Select"}, {"Esc", "Close"}}
+)
+
+// pageKeys are the keys of the screens and dialogs shown over the tunnel
+// list, by page; pages not listed are dialogs taking dialogKeys
+var pageKeys = map[string][]keyHint{
+	"help":               {{"any key", "Close"}},
+	"search":             {{"Tab", "Next result"}, {"Enter", "Select"}, {"Esc", "Cancel"}},
+	"palette":            {{"↑/↓", "Choose"}, {"Enter", "Run"}, {"Esc", "Close"}},
+	"dashboard":          {{"b/Esc", "Tunnel list"}, {"Ctrl+P", "Commands"}, {"m", "Messages"}, {"q", "Quit"}},
+	"activity":           {{"↑/↓", "Scroll"}, {"Esc", "Close"}},
+	"delete-confirm":     {{"D", "Delete"}, {"C/Esc", "Cancel"}, {"Tab", "Switch button"}},
+	"add-tunnel":         formKeys,
+	"edit-tunnel":        formKeys,
+	"settings":           formKeys,
+	"profile-mgmt":       formKeys,
+	"ssh-import":         formKeys,
+	"ssh-import-preview": {{"Space", "Select"}, {"a", "All"}, {"r", "Rename"}, {"Enter", "Import"}, {"Esc", "Cancel"}},
+	"ssh-import-rename":  promptKeys,
+	"quick-edit":         promptKeys,
+}
+
+// listKeys returns the keys of the tunnel list for the selected row, the
+// filter and the view
+func (a *App) listKeys() []keyHint {
+	// The selected tunnel is as of its selection; its status may have changed
+	tunnel := a.selectedTunnel
+	if tunnel != nil {
+		if current, err := a.tunnelManager.GetTunnel(tunnel.ID); err == nil {
+			tunnel = current
+		}
+	}
+
+	var keys []keyHint
+	switch {
+	case tunnel == nil && a.selectedGroup != "":
+		keys = append(keys, keyHint{"Space", "Collapse/Expand"})
+	case tunnel == nil:
+	case tunnel.Status == core.StatusRunning || tunnel.Status == core.StatusConnecting:
+		keys = append(keys, keyHint{"d", "Stop"}, keyHint{"y", "Copy"}, keyHint{"l", "Log"})
+	default:
+		keys = append(keys, keyHint{"u", "Start"}, keyHint{"e", "Edit"}, keyHint{"r", "Remove"}, keyHint{"f", "Mode"})
+	}
+
+	keys = append(keys, keyHint{"c", "Create"})
+	if a.filter != "" {
+		keys = append(keys, keyHint{"x", "Clear filter"})
+	} else {
+		keys = append(keys, keyHint{"F", "Filter"})
+	}
+	return append(keys, keyHint{"/", "Search"}, keyHint{"g", "Profile"}, keyHint{"Ctrl+P", "Commands"}, keyHint{"?", "Help"})
+}
+
+// currentKeys returns the keys of the dialog or screen in front, or of the
+// tunnel list if none is shown
+func (a *App) currentKeys() []keyHint {
+	page, _ := a.pages.GetFrontPage()
+	if page == "main" {
+		return a.listKeys()
+	}
+	if keys, ok := pageKeys[page]; ok {
+		return keys
+	}
+	return dialogKeys
+}
+
+// formatKeys formats key hints for the footer
+func formatKeys(keys []keyHint) string {
+	hints := make([]string, len(keys))
+	for i, hint := range keys {
+		hints[i] = fmt.Sprintf("[yellow]%s[::-] %s", hint.key, tr(hint.action))
+	}
+	return " " + strings.Join(hints, " | ")
+}
@@ -0,0 +1,169 @@
+// Package tui provides the live log pane of the selected tunnel
+package tui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+const (
+	// logPaneHeight is the height of the log pane when it is shown
+	logPaneHeight = 12
+
+	// logPaneHistory is the number of earlier log lines shown when a tunnel is selected
+	logPaneHistory = 100
+
+	// logPaneMaxLines is the number of lines the log pane keeps while following
+	logPaneMaxLines = 1000
+)
+
+// logTimestampPattern matches the timestamp tunnelman prefixes its own log lines with
+var logTimestampPattern = regexp.MustCompile(`^\[\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}\] `)
+
+// logPane follows the SSH output log of the selected tunnel
+type logPane struct {
+	view     *tview.TextView
+	visible  bool
+	tunnelID string
+	path     string
+	offset   int64
+	partial  string
+}
+
+// createLogPane creates the log pane, hidden until toggled
+func (a *App) createLogPane() {
+	view := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWrap(true).
+		SetMaxLines(logPaneMaxLines)
+
+	view.SetBorder(true).
+		SetTitle(tr(" Log ")).
+		SetTitleAlign(tview.AlignLeft)
+
+	a.logPane = &logPane{view: view}
+}
+
+// toggleLogPane shows or hides the log pane below the tunnel list
+func (a *App) toggleLogPane() {
+	pane := a.logPane
+	pane.visible = !pane.visible
+	if !pane.visible {
+		a.mainFlex.ResizeItem(pane.view, 0, 0)
+		pane.tunnelID = ""
+		pane.path = ""
+		pane.view.Clear()
+		return
+	}
+
+	a.mainFlex.ResizeItem(pane.view, logPaneHeight, 0)
+	a.followTunnelLog(a.selectedTunnel)
+}
+
+// followTunnelLog switches the log pane to a tunnel, showing the end of its
+// log so far
+func (a *App) followTunnelLog(tunnel *core.Tunnel) {
+	pane := a.logPane
+	if !pane.visible {
+		return
+	}
+	if tunnel == nil {
+		pane.tunnelID, pane.path = "", ""
+		pane.view.Clear().SetTitle(tr(" Log "))
+		return
+	}
+	if tunnel.ID == pane.tunnelID {
+		return
+	}
+
+	pane.tunnelID, pane.path, pane.offset, pane.partial = tunnel.ID, "", 0, ""
+	pane.view.Clear().SetTitle(trf(" Log: %s ", tview.Escape(tunnel.Name)))
+
+	path, err := a.tunnelManager.LogPath(tunnel.ID)
+	if err != nil {
+		fmt.Fprintf(pane.view, "[gray]%s[-]\n", tview.Escape(err.Error()))
+		return
+	}
+	pane.path = path
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintln(pane.view, trf("[red]Failed to read log: %s[-]", tview.Escape(err.Error())))
+		return
+	}
+	pane.offset = int64(len(data))
+
+	lines := strings.Split(string(data), "\n")
+	pane.partial = lines[len(lines)-1]
+	lines = lines[:len(lines)-1]
+	if len(lines) > logPaneHistory {
+		lines = lines[len(lines)-logPaneHistory:]
+	}
+	if len(lines) == 0 {
+		fmt.Fprintln(pane.view, tr("[gray]No output captured yet[-]"))
+	}
+	for _, line := range lines {
+		fmt.Fprintln(pane.view, tview.Escape(strings.TrimRight(line, "\r")))
+	}
+	pane.view.ScrollToEnd()
+}
+
+// pollLogPane appends the output written to the followed log since it was
+// last read, stamped with the time it was seen
+func (a *App) pollLogPane() {
+	pane := a.logPane
+	if !pane.visible || pane.path == "" {
+		return
+	}
+
+	file, err := os.Open(pane.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return
+	}
+	if info.Size() < pane.offset {
+		// The log was rotated or truncated
+		pane.offset, pane.partial = 0, ""
+	}
+	if info.Size() == pane.offset {
+		return
+	}
+	if _, err := file.Seek(pane.offset, io.SeekStart); err != nil {
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(file, info.Size()-pane.offset))
+	if err != nil {
+		return
+	}
+	pane.offset += int64(len(data))
+
+	lines := strings.Split(pane.partial+string(data), "\n")
+	pane.partial = lines[len(lines)-1]
+	now := time.Now()
+	for _, line := range lines[:len(lines)-1] {
+		fmt.Fprintln(pane.view, formatLogLine(strings.TrimRight(line, "\r"), now))
+	}
+	pane.view.ScrollToEnd()
+}
+
+// formatLogLine prefixes a log line with the time it was seen, unless
+// tunnelman already stamped it when writing it
+func formatLogLine(line string, seen time.Time) string {
+	if logTimestampPattern.MatchString(line) {
+		return fmt.Sprintf("[gray]%s[-]", tview.Escape(line))
+	}
+	return fmt.Sprintf("[gray]%s[-] %s", seen.Format("15:04:05"), tview.Escape(line))
+}
@@ -0,0 +1,545 @@
+// Package tui provides the Japanese translation of the TUI's messages
+package tui
+
+// japaneseMessages translates the TUI's messages to Japanese, by English
+// message
+var japaneseMessages = map[string]string{
+	// Activity history
+	"[gray]Nothing has happened yet[-]":  "[gray]まだ何も起きていません[-]",
+	" Activity (↑/↓ scroll, Esc close) ": " アクティビティ (↑/↓ スクロール, Esc 閉じる) ",
+
+	// Tunnel states
+	"running":    "実行中",
+	"stopped":    "停止",
+	"connecting": "接続中",
+	"error":      "エラー",
+
+	// Copying
+	"Copy from '%s':":        "'%s' からコピー:",
+	"SSH Command":            "SSH コマンド",
+	"Endpoint":               "エンドポイント",
+	"Cancel":                 "キャンセル",
+	"Tunnel Would Not Start": "トンネルを開始できません",
+	"ssh command":            "ssh コマンド",
+	"%s listens on the SSH host and has no local endpoint": "%s は SSH ホスト側で待ち受けるため、ローカルのエンドポイントがありません",
+	"endpoint":                                     "エンドポイント",
+	"Copy Failed":                                  "コピーに失敗しました",
+	"JSON definition":                              "JSON 定義",
+	"✓ Copied %s to the clipboard (%s)":            "✓ %s をクリップボードにコピーしました (%s)",
+	"[red]Failed to copy %s: %v[-]":                "[red]%s のコピーに失敗しました: %v[-]",
+	"✓ Sent %s to the terminal clipboard (OSC 52)": "✓ %s を端末のクリップボードに送りました (OSC 52)",
+
+	// Dashboard
+	"Profiles":        "プロファイル",
+	"Recently Failed": "最近の失敗",
+	"Longest Running": "稼働時間が長い順",
+	"Recent Events":   "最近のイベント",
+	" [yellow]b[::-]/[yellow]Esc[::-] Tunnel list | [yellow]Ctrl+P[::-] Commands | [yellow]m[::-] Messages | [yellow]q[::-] Quit": " [yellow]b[::-]/[yellow]Esc[::-] トンネル一覧 | [yellow]Ctrl+P[::-] コマンド | [yellow]m[::-] メッセージ | [yellow]q[::-] 終了",
+	" tunnelman dashboard ":           " tunnelman ダッシュボード ",
+	"[red]Failed to save view: %v[-]": "[red]表示設定を保存できませんでした: %v[-]",
+	"Profile":                         "プロファイル",
+	"Running":                         "実行中",
+	"Connecting":                      "接続中",
+	"Error":                           "エラー",
+	"Stopped":                         "停止",
+	"[gray]No failures recorded[-]":   "[gray]失敗の記録はありません[-]",
+	"%s  %s [gray]%s ago[-]\n":        "%s  %s [gray]%s 前[-]\n",
+	"[gray]No tunnels running[-]":     "[gray]実行中のトンネルはありません[-]",
+	"[gray]No status changes since the TUI started[-]": "[gray]TUI の起動後、状態の変化はありません[-]",
+
+	// Host groups
+	"%d/%d running": "%d/%d 実行中",
+	"Grouped by SSH host | Space: collapse/expand": "SSH ホストごとにグループ化 | Space: 折りたたみ/展開",
+	"[gray]%d tunnel(s), %d running[::-]\n\n":      "[gray]%d 個のトンネル、%d 個が実行中[::-]\n\n",
+
+	// Log pane
+	" Log ":                           " ログ ",
+	" Log: %s ":                       " ログ: %s ",
+	"[red]Failed to read log: %s[-]":  "[red]ログを読めませんでした: %s[-]",
+	"[gray]No output captured yet[-]": "[gray]まだ出力はありません[-]",
+
+	// Quick edit
+	"Name":                                "名前",
+	"name cannot be empty":                "名前を空にはできません",
+	"Local Port":                          "ローカルポート",
+	"invalid port: %q":                    "無効なポート: %q",
+	"⚠ Stop the tunnel before editing it": "⚠ 編集する前にトンネルを停止してください",
+	" %s (Enter: save, Esc: cancel) ":     " %s (Enter: 保存, Esc: キャンセル) ",
+	"✓ %s of %s changed to %s":            "✓ %[2]s の%[1]sを %[3]s に変更しました",
+
+	// Sorting
+	"[red]Failed to save sort order: %v[-]": "[red]並び順を保存できませんでした: %v[-]",
+	"Sorted by %s":                          "%s で並べ替えました",
+	"name":                                  "名前",
+	"host":                                  "ホスト",
+	"status":                                "状態",
+	"port":                                  "ポート",
+	"uptime":                                "稼働時間",
+	"profile":                               "プロファイル",
+
+	// Settings
+	" Settings ":                     " 設定 ",
+	"Bind Address":                   "バインドアドレス",
+	"Keepalive Interval (s, -1 off)": "キープアライブ間隔 (秒, -1 で無効)",
+	"Keepalive Count":                "キープアライブ回数",
+	"default":                        "既定",
+	"yes":                            "はい",
+	"no":                             "いいえ",
+	"Exit on Forward Failure":        "転送失敗時に終了",
+	"Host Key Checking":              "ホスト鍵の確認",
+	"SSH Path":                       "SSH のパス",
+	"Reconnect Delay (s)":            "再接続の待ち時間 (秒)",
+	"Max Reconnect Delay (s)":        "再接続の最大待ち時間 (秒)",
+	"Stable After (s)":               "安定とみなすまで (秒)",
+	"ASCII Icons and Borders":        "ASCII のアイコンと枠線",
+	"Colors":                         "配色",
+	"high-contrast":                  "ハイコントラスト",
+	"color-blind":                    "色覚多様性対応",
+	"from LANG":                      "LANG に従う",
+	"Language":                       "言語",
+	"Save":                           "保存",
+	"Invalid Settings":               "無効な設定",
+	"✓ Saved settings (running tunnels keep theirs until restarted)": "✓ 設定を保存しました (実行中のトンネルは再起動するまで以前の設定のままです)",
+
+	// Search and filters
+	"[dim]ESC: cancel | TAB: next | Enter: select[::-]": "[dim]ESC: キャンセル | TAB: 次へ | Enter: 選択[::-]",
+	" Search ":                                  " 検索 ",
+	"Search: %d result(s) for '%s'":             "検索: '%[2]s' に %[1]d 件一致",
+	"Search: No results for '%s'":               "検索: '%s' に一致するものはありません",
+	"Search result %d of %d":                    "検索結果 %d / %d",
+	"Filter: %s (%d tunnels) | x: clear filter": "フィルター: %s (%d 個のトンネル) | x: フィルター解除",
+	"tag %s":         "タグ %s",
+	"auto-connect":   "自動接続",
+	"local forward":  "ローカル転送",
+	"remote forward": "リモート転送",
+	"dynamic":        "ダイナミック",
+
+	// Command palette
+	"create tunnel":        "トンネルを作成",
+	"import ssh config":    "ssh config から取り込む",
+	"start all tunnels":    "すべてのトンネルを開始",
+	"stop all tunnels":     "すべてのトンネルを停止",
+	"manage profiles":      "プロファイルを管理",
+	"filter view":          "表示を絞り込む",
+	"clear filter":         "フィルターを解除",
+	"toggle log pane":      "ログ表示の切り替え",
+	"group by host":        "ホストごとにグループ化",
+	"dashboard":            "ダッシュボード",
+	"tunnel list":          "トンネル一覧",
+	"message history":      "メッセージ履歴",
+	"show tags":            "タグを表示",
+	"settings":             "設定",
+	"undo config change":   "設定の変更を元に戻す",
+	"help":                 "ヘルプ",
+	"quit":                 "終了",
+	"sort by %s":           "%s で並べ替え",
+	"deleted %s":           "%s に削除",
+	"restore %s":           "%s を復元",
+	"switch profile %s":    "プロファイル %s に切り替え",
+	"stop %s":              "%s を停止",
+	"start %s":             "%s を開始",
+	"go to %s":             "%s へ移動",
+	"edit %s":              "%s を編集",
+	"rename %s":            "%s の名前を変更",
+	"change local port %s": "%s のローカルポートを変更",
+	"copy %s":              "%s をコピー",
+	"view ssh command %s":  "%s の ssh コマンドを表示",
+	" Command Palette (Enter: run, Esc: close) ": " コマンドパレット (Enter: 実行, Esc: 閉じる) ",
+
+	// main screen
+	" Tunnels ":         " トンネル ",
+	" Details ":         " 詳細 ",
+	" Help ":            " ヘルプ ",
+	"St":                "状態",
+	"Host":              "ホスト",
+	"Tags":              "タグ",
+	"Local":             "ローカル",
+	"Remote":            "リモート",
+	"Mode":              "モード",
+	"Health":            "ヘルス",
+	"Latency":           "遅延",
+	"Started":           "開始",
+	" Tunnels (by %s) ": " トンネル (%s順) ",
+	" Tunnels [yellow][filtered: %s, %d of %d][-] (by %s) ": " トンネル [yellow][絞り込み: %s, %[3]d 件中 %[2]d 件][-] (%[4]s順) ",
+	"[::d]grouped by host[::-] ":                            "[::d]ホスト別[::-] ",
+	"healthy":                                               "正常",
+	"degraded":                                              "低下",
+	"unhealthy":                                             "異常",
+	"unknown":                                               "不明",
+	"fail":                                                  "失敗",
+	"Error: failed to reload changed config: %v":   "エラー: 変更された設定を再読み込みできませんでした: %v",
+	"↻ Config changed on disk and was reloaded":    "↻ ディスク上の設定が変更されたため再読み込みしました",
+	"[gray]Tags: %s[::-]\n":                        "[gray]タグ: %s[::-]\n",
+	"[gray]From: %s[::-]\n":                        "[gray]取り込み元: %s[::-]\n",
+	"[yellow]Connection:[::-]\n":                   "[yellow]接続:[::-]\n",
+	"  SSH: %s\n":                                  "  SSH: %s\n",
+	"  Via: %s\n":                                  "  経由: %s\n",
+	"  Key: %s (only)\n":                           "  鍵: %s (限定)\n",
+	"  Secret: %s (keychain)\n":                    "  シークレット: %s (キーチェーン)\n",
+	"  Certificate: %s\n":                          "  証明書: %s\n",
+	"  Host Key: %s (pinned)\n":                    "  ホスト鍵: %s (固定)\n",
+	"  Authenticated: %s\n":                        "  認証方式: %s\n",
+	"[yellow]Forwarding:[::-]\n":                   "[yellow]転送:[::-]\n",
+	"  Type: Local Forward (-L)\n":                 "  種類: ローカル転送 (-L)\n",
+	"  Type: Remote Forward (-R)\n":                "  種類: リモート転送 (-R)\n",
+	"  Type: Dynamic (SOCKS)\n":                    "  種類: ダイナミック (SOCKS)\n",
+	"  Type: Reverse Dynamic (remote SOCKS, -R)\n": "  種類: リバースダイナミック (リモート SOCKS, -R)\n",
+	"  Local: %s:%d\n":                             "  ローカル: %s:%d\n",
+	"  Remote: %s:%d\n":                            "  リモート: %s:%d\n",
+	"  Remote Port: %d\n":                          "  リモートポート: %d\n",
+	"  Remote Bind: %s\n":                          "  リモートバインド: %s\n",
+	"  Also: %s\n":                                 "  追加: %s\n",
+	"  [yellow]%s Binding a non-loopback address needs GatewayPorts yes or\n": "  [yellow]%s ループバック以外のアドレスにバインドするには、サーバーの\n",
+	"    clientspecified in the server's sshd_config; otherwise sshd\n":       "    sshd_config で GatewayPorts yes か clientspecified が必要です。\n",
+	"    listens on loopback only[::-]\n":                                     "    ないと sshd はループバックでのみ待ち受けます[::-]\n",
+	"[yellow]Status:[::-]\n":                                                  "[yellow]状態:[::-]\n",
+	"  State: [%s]%s[::-]\n":                                                  "  状態: [%s]%s[::-]\n",
+	"  PID: %d\n":                                                             "  PID: %d\n",
+	"  Uptime: %s\n":                                                          "  稼働時間: %s\n",
+	"  [red]Error: %v[::-]\n":                                                 "  [red]エラー: %v[::-]\n",
+	"  [yellow]Hint: %s[::-]\n":                                               "  [yellow]ヒント: %s[::-]\n",
+	"  Health: [%s]%s[::-]\n":                                                 "  ヘルス: [%s]%s[::-]\n",
+	"  [yellow]Probe: %v[::-]\n":                                              "  [yellow]プローブ: %v[::-]\n",
+	"  [red]Latency: %v[::-]\n":                                               "  [red]遅延: %v[::-]\n",
+	"  Latency: [%s]%s[::-]\n":                                                "  遅延: [%s]%s[::-]\n",
+	"[yellow]Statistics:[::-]\n":                                              "[yellow]統計:[::-]\n",
+	"  Connects: %d  Failures: %d  Restarts: %d\n":                            "  接続: %d  失敗: %d  再起動: %d\n",
+	"  Total uptime: %s\n":                                                    "  累計稼働時間: %s\n",
+	"  Last failure: %s\n":                                                    "  最終失敗: %s\n",
+	"[yellow]Options:[::-]\n":                                                 "[yellow]オプション:[::-]\n",
+	"  Auto-connect: %v\n":                                                    "  自動接続: %v\n",
+	"  Compression: on\n":                                                     "  圧縮: オン\n",
+	"  Connection: shared with other tunnels to the host\n":                   "  接続: 同じホストへの他のトンネルと共有\n",
+	"  Keepalive: off\n":                                                      "  キープアライブ: オフ\n",
+	"  Keepalive: %s\n":                                                       "  キープアライブ: %s\n",
+	"  Exit on forward failure: %v\n":                                         "  転送失敗時に終了: %v\n",
+	"  Extra args: %s\n":                                                      "  追加引数: %s\n",
+	"  On %s: %s\n":                                                           "  %s 時: %s\n",
+	"\n[yellow]SSH Command:[::-]\n":                                           "\n[yellow]SSH コマンド:[::-]\n",
+	"  [red]Resolves: %s[::-]\n":                                              "  [red]解決先: %s[::-]\n",
+	"  [gray]Resolves: resolving...[::-]\n":                                   "  [gray]解決先: 解決中...[::-]\n",
+	"  Resolves: %s [gray](%s)[::-]\n":                                        "  解決先: %s [gray](%s)[::-]\n",
+	"  Jump: %s [gray](ssh config)[::-]\n":                                    "  踏み台: %s [gray](ssh config)[::-]\n",
+	"  Keys: %s\n":                                                            "  鍵: %s\n",
+	"[::b]TUNNELMAN[::-] | Profile: [yellow]%s[::-] | Connections: [%s]%d/%d[::-] | [dim]? Help | / Search | q Quit[::-]": "[::b]TUNNELMAN[::-] | プロファイル: [yellow]%s[::-] | 接続: [%s]%d/%d[::-] | [dim]? ヘルプ | / 検索 | q 終了[::-]",
+	" Ready | %d tunnel(s), %d active":          " 準備完了 | トンネル %d 件、稼働中 %d 件",
+	" | [yellow]%s %d config problem(s): %s[-]": " | [yellow]%s 設定の問題 %d 件: %s[-]",
+	"Error: %v":                       "エラー: %v",
+	"%s [red](unreadable)[::-]":       "%s [red](読み込めません)[::-]",
+	"%s (never expires)":              "%s (無期限)",
+	"%s [red](expired %s ago)[::-]":   "%s [red](%s 前に期限切れ)[::-]",
+	"%s [yellow](expires in %s)[::-]": "%s [yellow](あと %s で期限切れ)[::-]",
+	"%s (expires in %s)":              "%s (あと %s で期限切れ)",
+	"every %s, up to %s missed":       "%s ごと、%s 回まで応答なしを許容",
+	helpText:                          japaneseHelpText,
+
+	// tunnel operations
+	"Starting tunnel...":                              "トンネルを開始しています...",
+	"✓ Tunnel started":                                "✓ トンネルを開始しました",
+	"Tunnel is already running":                       "トンネルはすでに稼働中です",
+	"Port In Use":                                     "ポートが使用中です",
+	"Start Failed":                                    "開始に失敗しました",
+	"Stopping tunnel...":                              "トンネルを停止しています...",
+	"✓ Tunnel stopped":                                "✓ トンネルを停止しました",
+	"Tunnel is not running":                           "トンネルは稼働していません",
+	"Stop Failed":                                     "停止に失敗しました",
+	"Starting all tunnels in profile '%s'...":         "プロファイル '%s' の全トンネルを開始しています...",
+	"Some tunnels failed to start: %v":                "一部のトンネルを開始できませんでした: %v",
+	"✓ Started all tunnels in profile '%s'":           "✓ プロファイル '%s' の全トンネルを開始しました",
+	"Stopping all tunnels in profile '%s'...":         "プロファイル '%s' の全トンネルを停止しています...",
+	"Some tunnels failed to stop: %v":                 "一部のトンネルを停止できませんでした: %v",
+	"✓ Stopped all tunnels in profile '%s'":           "✓ プロファイル '%s' の全トンネルを停止しました",
+	"Nothing to undo":                                 "元に戻す変更はありません",
+	"Undo Failed":                                     "元に戻せませんでした",
+	"↶ Restored the config of %s":                     "↶ %s 時点の設定に戻しました",
+	"No deleted tunnel to restore":                    "復元できる削除済みトンネルはありません",
+	"Restore Failed":                                  "復元に失敗しました",
+	"↶ Restored tunnel %s":                            "↶ トンネル %s を復元しました",
+	"No tagged tunnels (add tags in the tunnel form)": "タグ付きのトンネルはありません (トンネルのフォームでタグを追加できます)",
+	"Select tag:":                                     "タグを選択:",
+	"Tag '%s': %d tunnel(s)":                          "タグ '%s': トンネル %d 件",
+	"Show":                                            "表示",
+	"Start All":                                       "すべて開始",
+	"Stop All":                                        "すべて停止",
+	"Starting tunnels tagged '%s'...":                 "タグ '%s' のトンネルを開始しています...",
+	"✓ Started tunnels tagged '%s'":                   "✓ タグ '%s' のトンネルを開始しました",
+	"Stopping tunnels tagged '%s'...":                 "タグ '%s' のトンネルを停止しています...",
+	"✓ Stopped tunnels tagged '%s'":                   "✓ タグ '%s' のトンネルを停止しました",
+	"Restarting tunnel...":                            "トンネルを再起動しています...",
+	"Restart Failed":                                  "再起動に失敗しました",
+	"Tunnel restarted":                                "トンネルを再起動しました",
+	"Update Failed":                                   "更新に失敗しました",
+	"disabled":                                        "無効",
+	"enabled":                                         "有効",
+	"✓ Auto-connect %s":                               "✓ 自動接続を%sにしました",
+	"⚠ No tunnel selected":                            "⚠ トンネルが選択されていません",
+	"⚠ Stop the tunnel before changing mode":          "⚠ モードを変更する前にトンネルを停止してください",
+	"forward":                                         "フォワード",
+	"reverse":                                         "リバース",
+	"reverse dynamic":                                 "リバースダイナミック",
+	"✓ Mode changed to %s":                            "✓ モードを%sに変更しました",
+	"All Tunnels":                                     "すべてのトンネル",
+	"Auto-connect":                                    "自動接続",
+	"Local Forward":                                   "ローカル転送",
+	"Remote Forward":                                  "リモート転送",
+	"Dynamic/SOCKS":                                   "ダイナミック/SOCKS",
+	"By Tag...":                                       "タグで...",
+	"Select filter:":                                  "絞り込みを選択:",
+	"Duplicate Failed":                                "複製に失敗しました",
+	"✓ Duplicated as '%s'":                            "✓ '%s' として複製しました",
+	"Cannot Edit":                                     "編集できません",
+	"Stop the tunnel before editing":                  "編集する前にトンネルを停止してください",
+	"OK":                                              "OK",
+	" SSH Command ":                                   " SSH コマンド ",
+	"Are you sure you want to quit?":                  "終了しますか?",
+	"%d tunnel(s) are still running.\n%s":             "%d 件のトンネルがまだ稼働中です。\n%s",
+	"Quit":                                            "終了",
+	"%s (PID %d)":                                     "%s (PID %d)",
+	"Found ssh processes of these tunnels that are not tracked:\n\n%s\n\nAdopt them, or kill them?": "管理されていない次のトンネルの ssh プロセスが見つかりました:\n\n%s\n\n引き継ぎますか、それとも終了させますか?",
+	"Adopt":  "引き継ぐ",
+	"Kill":   "終了させる",
+	"Ignore": "無視",
+
+	// profiles
+	"Failed to load profiles":                "プロファイルを読み込めませんでした",
+	"Current profile: %s\n\nSelect profile:": "現在のプロファイル: %s\n\nプロファイルを選択:",
+	"Switched to profile: %s":                "プロファイルを切り替えました: %s",
+	"Profile %s: %v":                         "プロファイル %s: %v",
+	" Profile Management ":                   " プロファイル管理 ",
+	"Create New Profile":                     "新しいプロファイルを作成",
+	"Rename Profile":                         "プロファイルの名前を変更",
+	"Clone Profile":                          "プロファイルを複製",
+	"Delete Profile":                         "プロファイルを削除",
+	"Action":                                 "操作",
+	"Profile Name":                           "プロファイル名",
+	"New Name":                               "新しい名前",
+	"Execute":                                "実行",
+	"Profile name is required":               "プロファイル名を入力してください",
+	"Failed to load config":                  "設定を読み込めませんでした",
+	"Profile already exists":                 "プロファイルはすでに存在します",
+	"Failed to save profile":                 "プロファイルを保存できませんでした",
+	"✓ Created profile: %s":                  "✓ プロファイルを作成しました: %s",
+	"✓ Renamed profile %s to %s":             "✓ プロファイル %s の名前を %s に変更しました",
+	"✓ Cloned profile %s to %s (%d tunnels)": "✓ プロファイル %s を %s に複製しました (トンネル %d 件)",
+	"Cannot delete default profile":          "default プロファイルは削除できません",
+	"Profile not found":                      "プロファイルが見つかりません",
+	"Failed to delete profile":               "プロファイルを削除できませんでした",
+	"✓ Deleted profile: %s":                  "✓ プロファイルを削除しました: %s",
+
+	// ssh config import
+	"Failed to load SSH config: %v": "SSH 設定を読み込めませんでした: %v",
+	"No Hosts":                      "ホストがありません",
+	"No hosts found in SSH config":  "SSH 設定にホストが見つかりません",
+	" Import from SSH Config ":      " SSH 設定から取り込み ",
+	"Select Host":                   "ホストを選択",
+	"All hosts with forwards...":    "転送のあるすべてのホスト...",
+	"Import to Profile":             "取り込み先プロファイル",
+	"Or Create New Profile":         "または新しいプロファイルを作成",
+	"Update Imported Tunnels":       "取り込み済みトンネルを更新",
+	"Import":                        "取り込む",
+	"Import Failed":                 "取り込みに失敗しました",
+	"Added":                         "追加",
+	"Updated":                       "更新",
+	"Removed":                       "削除",
+	"Skipped (running)":             "スキップ (稼働中)",
+	"The imported tunnels match the SSH config":                                   "取り込み済みのトンネルは SSH 設定と一致しています",
+	"✓ Synced from SSH config: %d added, %d updated, %d removed":                  "✓ SSH 設定と同期しました: 追加 %d 件、更新 %d 件、削除 %d 件",
+	" Import into '%s': Space select, a all, r rename, Enter import, Esc cancel ": " '%s' に取り込み: Space 選択, a 全選択, r 名前変更, Enter 取り込み, Esc キャンセル ",
+	"Type":                       "種類",
+	"Ports":                      "ポート",
+	"imported":                   "取り込み済み",
+	"Name: ":                     "名前: ",
+	" Rename Tunnel ":            " トンネルの名前を変更 ",
+	"Nothing selected to import": "取り込む項目が選択されていません",
+	"✓ Imported %d tunnel(s) from %d host(s) to profile '%s'": "✓ %[2]d 件のホストからトンネル %[1]d 件をプロファイル '%[3]s' に取り込みました",
+
+	// tunnel form
+	"[yellow]%s Delete Confirmation[::-]\n\nAre you sure you want to delete tunnel:\n\n[white]%s[::-]\n[dim](%s)[::-]\n\nIt can be restored with Z until tunnelman exits.": "[yellow]%s 削除の確認[::-]\n\n次のトンネルを削除しますか:\n\n[white]%s[::-]\n[dim](%s)[::-]\n\ntunnelman を終了するまでは Z で復元できます。",
+	"Delete (D)":      "削除 (D)",
+	"Cancel (C)":      "キャンセル (C)",
+	" Delete Tunnel ": " トンネルの削除 ",
+	"Delete Failed":   "削除に失敗しました",
+	"✓ Deleted tunnel %s | Press Z to undo":        "✓ トンネル %s を削除しました | Z で元に戻せます",
+	" %s New Tunnel ":                              " %s 新しいトンネル ",
+	" %s Edit Tunnel ":                             " %s トンネルの編集 ",
+	"Basic Information":                            "基本情報",
+	"[yellow]Basic Information[::-]":               "[yellow]基本情報[::-]",
+	"Local Forward (-L)":                           "ローカル転送 (-L)",
+	"Remote Forward (-R)":                          "リモート転送 (-R)",
+	"Dynamic/SOCKS (-D)":                           "ダイナミック/SOCKS (-D)",
+	"Reverse Dynamic/SOCKS (-R)":                   "リバースダイナミック/SOCKS (-R)",
+	"SSH Connection":                               "SSH 接続",
+	"[yellow]SSH Connection[::-]":                  "[yellow]SSH 接続[::-]",
+	"SSH Host":                                     "SSH ホスト",
+	"Jump Host":                                    "踏み台ホスト",
+	"Identity File":                                "秘密鍵ファイル",
+	"Keychain Secret":                              "キーチェーンのシークレット",
+	"Certificate File":                             "証明書ファイル",
+	"Certificate Command":                          "証明書コマンド",
+	"Port Forwarding":                              "ポート転送",
+	"[yellow]Port Forwarding[::-]":                 "[yellow]ポート転送[::-]",
+	"Remote Host":                                  "リモートホスト",
+	"Remote Port":                                  "リモートポート",
+	"Remote Bind Address (-R)":                     "リモートバインドアドレス (-R)",
+	"Additional Forwards":                          "追加の転送",
+	"Options":                                      "オプション",
+	"[yellow]Options[::-]":                         "[yellow]オプション[::-]",
+	"Description":                                  "説明",
+	"Auto-connect on startup":                      "起動時に自動接続",
+	"Compression (-C)":                             "圧縮 (-C)",
+	"Share connection (ControlMaster)":             "接続を共有 (ControlMaster)",
+	"Extra SSH Arguments":                          "追加の SSH 引数",
+	"On Connect":                                   "接続時",
+	"On Disconnect":                                "切断時",
+	"On Failure":                                   "失敗時",
+	"Validation Error":                             "入力エラー",
+	"✓ Tunnel created successfully":                "✓ トンネルを作成しました",
+	"✓ Tunnel updated successfully":                "✓ トンネルを更新しました",
+	" %s New Tunnel - Local Forward (-L) ":         " %s 新しいトンネル - ローカル転送 (-L) ",
+	" %s New Tunnel - Remote Forward (-R) ":        " %s 新しいトンネル - リモート転送 (-R) ",
+	" %s New Tunnel - Dynamic/SOCKS (-D) ":         " %s 新しいトンネル - ダイナミック/SOCKS (-D) ",
+	" %s New Tunnel - Reverse Dynamic/SOCKS (-R) ": " %s 新しいトンネル - リバースダイナミック/SOCKS (-R) ",
+	" Error ":                                      " エラー ",
+
+	// ssh prompts
+	"Yes":     "はい",
+	"No":      "いいえ",
+	"Dismiss": "閉じる",
+	"Answer":  "応答",
+	"Prompt":  "プロンプト",
+	"Accept":  "受け入れる",
+	"Pin":     "固定する",
+	"Reject":  "拒否",
+	"[yellow]%s[::-]\n\nUnknown host %s\n\n%s key fingerprint:\n%s\n\nAccept adds the key to known_hosts. Pin also makes this tunnel refuse any other key.": "[yellow]%s[::-]\n\n未知のホスト %s\n\n%s 鍵のフィンガープリント:\n%s\n\n受け入れると鍵を known_hosts に追加します。固定すると、このトンネルは他の鍵も拒否するようになります。",
+	"not shown by ssh": "ssh は表示していません",
+	"[red]The host key of %s has changed![::-]\n\nThis can mean someone is intercepting the connection, or the host was reinstalled.\n\nNew key: %s": "[red]%s のホスト鍵が変更されました![::-]\n\n誰かが接続を傍受しているか、ホストが再インストールされた可能性があります。\n\n新しい鍵: %s",
+	"\nKnown key: %s line %d": "\n既知の鍵: %s の %d 行目",
+	"[red]%s did not present the pinned host key[::-]\n\nPinned key: %s":                        "[red]%s が固定されたホスト鍵を提示しませんでした[::-]\n\n固定された鍵: %s",
+	"[yellow]%s[::-]\n\n%s\n\nForget the known key and reconnect only if you trust the change.": "[yellow]%s[::-]\n\n%s\n\n変更を信頼できる場合にのみ、既知の鍵を削除して再接続してください。",
+	"Forget & Reconnect":        "削除して再接続",
+	"Keep":                      "そのまま",
+	"Failed to Forget Host Key": "ホスト鍵を削除できませんでした",
+
+	// setup wizard
+	"This wizard imports the forwards of your SSH config and creates a tunnel.":     "このウィザードでは SSH 設定の転送を取り込み、トンネルを作成します。",
+	"There is no config yet, so let's set up your first tunnels.":                   "設定がまだないので、最初のトンネルを準備しましょう。",
+	"No hosts were found in ~/.ssh/config; tunnels can use any host ssh can reach.": "~/.ssh/config にホストが見つかりませんでした。トンネルには ssh で接続できる任意のホストを使えます。",
+	"Found %d host(s) in ~/.ssh/config.":                                            "~/.ssh/config に %d 件のホストが見つかりました。",
+	"[::b]Welcome to tunnelman![::-]\n\n%s\n\n%s":                                   "[::b]tunnelman へようこそ![::-]\n\n%s\n\n%s",
+	"Next":       "次へ",
+	"Skip Setup": "セットアップを省略",
+	" Setup ":    " セットアップ ",
+	"%d host(s) in your SSH config define forwards, which make %d tunnel(s).\n\nImport them into the default profile?": "SSH 設定の %d 件のホストに転送が定義されており、トンネル %d 件になります。\n\ndefault プロファイルに取り込みますか?",
+	"Skip":                      "省略",
+	"[red]Import failed: %v[-]": "[red]取り込みに失敗しました: %v[-]",
+	"✓ Imported %d tunnel(s) from the SSH config":                                    "✓ SSH 設定からトンネル %d 件を取り込みました",
+	" Setup: Create Your First Tunnel ":                                              " セットアップ: 最初のトンネルを作成 ",
+	"A local forward makes a port on the SSH host's side reachable on this machine.": "ローカル転送は、SSH ホスト側のポートをこのマシンから使えるようにします。",
+	"Create":              "作成",
+	"[red]%s[-]":          "[red]%s[-]",
+	"✓ Created tunnel %s": "✓ トンネル %s を作成しました",
+	"[::b]The main keys[::-]\n\nEnter: start or stop the selected tunnel\nc: create a tunnel, e: edit it\ni: import from the SSH config\nb: dashboard of all profiles\nCtrl+P: command palette\n?: all keys, q: quit": "[::b]主なキー[::-]\n\nEnter: 選択したトンネルの開始/停止\nc: トンネルを作成, e: 編集\ni: SSH 設定から取り込み\nb: 全プロファイルのダッシュボード\nCtrl+P: コマンドパレット\n?: すべてのキー, q: 終了",
+	"Start Using tunnelman":               "tunnelman を使い始める",
+	"[red]Failed to save config: %v[-]":   "[red]設定を保存できませんでした: %v[-]",
+	"✓ Setup done | Press ? for all keys": "✓ セットアップ完了 | ? ですべてのキーを表示",
+	"setup wizard":                        "セットアップウィザード",
+
+	// key hints
+	"Next field":      "次の項目",
+	"Select":          "選択",
+	"Choose":          "選ぶ",
+	"Close":           "閉じる",
+	"any key":         "任意のキー",
+	"Next result":     "次の結果",
+	"Run":             "実行",
+	"Tunnel list":     "トンネル一覧",
+	"Commands":        "コマンド",
+	"Messages":        "メッセージ",
+	"Scroll":          "スクロール",
+	"Delete":          "削除",
+	"Switch button":   "ボタン切り替え",
+	"All":             "すべて",
+	"Rename":          "名前変更",
+	"Collapse/Expand": "折りたたみ/展開",
+	"Stop":            "停止",
+	"Copy":            "コピー",
+	"Log":             "ログ",
+	"Start":           "開始",
+	"Edit":            "編集",
+	"Remove":          "削除",
+	"Clear filter":    "絞り込み解除",
+	"Filter":          "絞り込み",
+	"Search":          "検索",
+	"Help":            "ヘルプ",
+
+	// port warning
+	"Port %d is in use by tunnel '%s'":     "ポート %d はトンネル '%s' が使用中です",
+	"Port %d is in use by %s":              "ポート %d は %s が使用中です",
+	"Port %d is in use by another process": "ポート %d は他のプロセスが使用中です",
+	"Also configured for: %s":              "他の設定でも使用: %s",
+
+	// clash confirm
+	"%s This tunnel will fail to start while others run:\n\n%s\n\nSave anyway?": "%s このトンネルは次のトンネルの実行中には起動できません:\n\n%s\n\nこのまま保存しますか?",
+	"Back": "戻る",
+
+	// listen state
+	"Not listening": "待ち受けなし",
+	"  [yellow]ssh is up, but nothing accepts connections on %s[::-]\n": "  [yellow]ssh は動作中ですが、%s で接続を受け付けていません[::-]\n",
+}
+
+// japaneseHelpText is the text of the help view in Japanese
+const japaneseHelpText = `[::b]キーボードショートカット[::-]
+
+[yellow]移動:[::-]
+  ↑/k     上へ
+  ↓/j     下へ
+  Tab     フォーカスの切り替え
+  /       トンネルを検索
+
+[yellow]トンネル操作:[::-]
+  Enter   トンネルの開始/停止
+  u       トンネルを開始
+  d       トンネルを停止
+  e       トンネルを編集
+  n       トンネルの名前を変更
+  L       ローカルポートを変更
+  c       トンネルを新規作成
+  C       トンネルを複製してコピーを編集
+  r       トンネルを削除
+  a       自動接続の切り替え
+  v       ssh コマンドを表示 (実行しない)
+  y       ssh コマンド、エンドポイント、JSON をコピー
+  l       トンネルのライブログの表示/非表示
+
+[yellow]一括操作:[::-]
+  A       プロファイルの全トンネルを開始
+  X       プロファイルの全トンネルを停止
+  g       プロファイルを切り替え
+  p       プロファイル管理 (追加/名前変更/複製/削除)
+  f       フォワード/リバースモードの切り替え
+  F       絞り込み (状態、種類、自動接続、タグ)
+  x       絞り込みを解除
+  o       次の列で並べ替え (見出しのクリックでその列)
+  O       並び順を反転
+  t       タグ別にトンネルを表示、開始、停止
+  G       SSH ホスト別にまとめる
+  Space   ホストグループの折りたたみ/展開 (←/→ でも可)
+
+[yellow]アプリケーション:[::-]
+  b       ダッシュボード (プロファイル別の状態、失敗、イベント)
+  m       メッセージ履歴 (状態メッセージとトンネルのイベント)
+  Ctrl+P  コマンドパレット (全プロファイルの操作とトンネル)
+  ?       このヘルプを表示
+  S       設定 (全トンネルのデフォルト)
+  z       最後の設定変更を元に戻す
+  Z       最後に削除したトンネルを復元
+  q       終了 (トンネルは動き続けます)
+  Ctrl+C  強制終了
+
+[yellow]トンネルの種類:[::-]
+  Local (-L):   ローカルポートをリモートへ転送
+  Remote (-R):  リモートポートをローカルへ転送
+  Dynamic (-D): SOCKS プロキシ
+  Reverse Dynamic (-R port): SSH ホスト上の SOCKS プロキシ
+
+何かキーを押すとこのヘルプを閉じます。`
@@ -2,9 +2,13 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -28,36 +32,28 @@ func (a *App) showDeleteConfirmation(tunnel *core.Tunnel) {
 	text := tview.NewTextView().
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter).
-		SetText(fmt.Sprintf(
-			"[yellow]⚠ Delete Confirmation[::-]\n\n"+
+		SetText(trf(
+			"[yellow]%s Delete Confirmation[::-]\n\n"+
 				"Are you sure you want to delete tunnel:\n\n"+
 				"[white]%s[::-]\n"+
 				"[dim](%s)[::-]\n\n"+
-				"This action cannot be undone.",
+				"It can be restored with Z until tunnelman exits.",
+			a.symbols.warning,
 			tunnel.Name,
 			tunnel.SSHHost,
 		))
 
 	// Create buttons
-	deleteBtn := tview.NewButton("Delete (D)").
+	deleteBtn := tview.NewButton(tr("Delete (D)")).
 		SetSelectedFunc(func() {
-			if err := a.tunnelManager.DeleteTunnel(tunnel.ID); err != nil {
-				a.showErrorModal("Delete Failed", err.Error())
-			} else {
-				a.selectedTunnel = nil
-				a.updateTunnelList()
-				a.updateDetailView(nil)
-				a.updateStatusBar("✓ Tunnel deleted successfully")
-			}
-			a.pages.RemovePage("delete-confirm")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("delete-confirm")
+			a.deleteTunnel(tunnel)
 		})
 	deleteBtn.SetBackgroundColor(tcell.ColorRed)
 
-	cancelBtn := tview.NewButton("Cancel (C)").
+	cancelBtn := tview.NewButton(tr("Cancel (C)")).
 		SetSelectedFunc(func() {
-			a.pages.RemovePage("delete-confirm")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("delete-confirm")
 		})
 	cancelBtn.SetBackgroundColor(tcell.ColorBlue)
 
@@ -80,7 +76,7 @@ func (a *App) showDeleteConfirmation(tunnel *core.Tunnel) {
 		AddItem(buttons, 3, 0, true)
 
 	container.SetBorder(true).
-		SetTitle(" Delete Tunnel ").
+		SetTitle(tr(" Delete Tunnel ")).
 		SetTitleAlign(tview.AlignCenter).
 		SetBorderColor(tcell.ColorRed)
 
@@ -89,8 +85,7 @@ func (a *App) showDeleteConfirmation(tunnel *core.Tunnel) {
 		switch event.Key() {
 		case tcell.KeyEscape:
 			// Close on ESC
-			a.pages.RemovePage("delete-confirm")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("delete-confirm")
 			return nil
 		case tcell.KeyTab:
 			// Tab to next button
@@ -126,21 +121,12 @@ func (a *App) showDeleteConfirmation(tunnel *core.Tunnel) {
 		switch event.Rune() {
 		case 'd', 'D':
 			// Delete shortcut
-			if err := a.tunnelManager.DeleteTunnel(tunnel.ID); err != nil {
-				a.showErrorModal("Delete Failed", err.Error())
-			} else {
-				a.selectedTunnel = nil
-				a.updateTunnelList()
-				a.updateDetailView(nil)
-				a.updateStatusBar("✓ Tunnel deleted successfully")
-			}
-			a.pages.RemovePage("delete-confirm")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("delete-confirm")
+			a.deleteTunnel(tunnel)
 			return nil
 		case 'c', 'C':
 			// Cancel shortcut
-			a.pages.RemovePage("delete-confirm")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("delete-confirm")
 			return nil
 		}
 
@@ -149,11 +135,22 @@ func (a *App) showDeleteConfirmation(tunnel *core.Tunnel) {
 
 	// Create modal overlay
 	modal := a.createModalOverlay(container, 50, 15)
-	a.pages.AddPage("delete-confirm", modal, true, true)
-	a.app.SetFocus(cancelBtn)  // Start with Cancel button focused for safety
+	a.modals.push("delete-confirm", modal, cancelBtn)  // Start with Cancel button focused for safety
 	currentFocus = 1
 }
 
+// deleteTunnel deletes a tunnel, which stays restorable for the session
+func (a *App) deleteTunnel(tunnel *core.Tunnel) {
+	if err := a.tunnelManager.DeleteTunnel(tunnel.ID); err != nil {
+		a.showErrorModal(tr("Delete Failed"), err.Error())
+		return
+	}
+	a.selectedTunnel = nil
+	a.updateTunnelList()
+	a.updateDetailView(nil)
+	a.updateStatusBar(trf("✓ Deleted tunnel %s | Press Z to undo", tview.Escape(tunnel.Name)))
+}
+
 // showAddTunnelForm shows the form for adding a new tunnel
 func (a *App) showAddTunnelForm() {
 	form := a.createAdvancedTunnelForm(nil)
@@ -162,8 +159,7 @@ func (a *App) showAddTunnelForm() {
 	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		// Allow ESC to close the form
 		if event.Key() == tcell.KeyEscape {
-			a.pages.RemovePage("add-tunnel")
-			a.app.SetFocus(a.tunnelList)
+			a.modals.pop("add-tunnel")
 			return nil
 		}
 		// Let the form handle all other input
@@ -171,8 +167,7 @@ func (a *App) showAddTunnelForm() {
 	})
 
 	modal := a.createModalOverlay(form, 70, 25)
-	a.pages.AddPage("add-tunnel", modal, true, true)
-	a.app.SetFocus(form)
+	a.modals.push("add-tunnel", modal, form)
 }
 
 // createAdvancedTunnelForm creates an advanced tunnel configuration form
@@ -182,7 +177,7 @@ func (a *App) createAdvancedTunnelForm(tunnel *core.Tunnel) *tview.Form {
 		tunnel = &core.Tunnel{
 			ID:        core.NewTunnel("", core.LocalForward).ID,
 			Type:      core.LocalForward,
-			LocalHost: "0.0.0.0",
+			LocalHost: a.tunnelManager.DefaultBindAddress(),
 			LocalPort: 8080,
 			RemoteHost: "localhost",
 			RemotePort: 80,
@@ -192,9 +187,9 @@ func (a *App) createAdvancedTunnelForm(tunnel *core.Tunnel) *tview.Form {
 	form := tview.NewForm()
 
 	// Set form title and style
-	title := " ✚ New Tunnel "
+	title := trf(" %s New Tunnel ", a.symbols.add)
 	if !isNew {
-		title = " ✎ Edit Tunnel "
+		title = trf(" %s Edit Tunnel ", a.symbols.edit)
 	}
 	form.SetBorder(true).
 		SetTitle(title).
@@ -204,22 +199,28 @@ func (a *App) createAdvancedTunnelForm(tunnel *core.Tunnel) *tview.Form {
 	// Track current tunnel type for dynamic field updates
 	currentType := tunnel.Type
 
+	// checkLocalPort warns about the local port as it is typed; it is set once
+	// the port fields exist
+	var checkLocalPort func()
+
 	// Basic Information Section
-	form.AddTextView("Basic Information", "[yellow]Basic Information[::-]", 0, 1, true, false)
+	form.AddTextView(tr("Basic Information"), tr("[yellow]Basic Information[::-]"), 0, 1, true, false)
 
-	form.AddInputField("Name", tunnel.Name, 40, nil, nil).
+	form.AddInputField(tr("Name"), tunnel.Name, 40, nil, nil).
 		SetFieldBackgroundColor(tcell.ColorBlack)
 
-	typeOptions := []string{"Local Forward (-L)", "Remote Forward (-R)", "Dynamic/SOCKS (-D)"}
+	typeOptions := []string{tr("Local Forward (-L)"), tr("Remote Forward (-R)"), tr("Dynamic/SOCKS (-D)"), tr("Reverse Dynamic/SOCKS (-R)")}
 	typeIndex := 0
 	switch tunnel.Type {
 	case core.RemoteForward:
 		typeIndex = 1
 	case core.DynamicForward:
 		typeIndex = 2
+	case core.ReverseDynamicForward:
+		typeIndex = 3
 	}
 
-	typeDropdown := form.AddDropDown("Type", typeOptions, typeIndex, func(option string, index int) {
+	typeDropdown := form.AddDropDown(tr("Type"), typeOptions, typeIndex, func(option string, index int) {
 		// Update currentType based on selection
 		switch index {
 		case 0:
@@ -228,24 +229,44 @@ func (a *App) createAdvancedTunnelForm(tunnel *core.Tunnel) *tview.Form {
 			currentType = core.RemoteForward
 		case 2:
 			currentType = core.DynamicForward
+		case 3:
+			currentType = core.ReverseDynamicForward
 		}
 		// Dynamically update form fields based on type
 		a.updateFormFieldsForType(form, currentType)
+		if checkLocalPort != nil {
+			checkLocalPort()
+		}
 	})
 	typeDropdown.SetFieldBackgroundColor(tcell.ColorBlack)
 
 	// SSH Connection Section
 	form.AddTextView("", "", 0, 0, false, false) // Spacer
-	form.AddTextView("SSH Connection", "[yellow]SSH Connection[::-]", 0, 1, true, false)
+	form.AddTextView(tr("SSH Connection"), tr("[yellow]SSH Connection[::-]"), 0, 1, true, false)
+
+	form.AddInputField(tr("SSH Host"), tunnel.SSHHost, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("Jump Host"), tunnel.JumpHost, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
 
-	form.AddInputField("SSH Host", tunnel.SSHHost, 40, nil, nil).
+	form.AddInputField(tr("Identity File"), tunnel.IdentityFile, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("Keychain Secret"), tunnel.Secret, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("Certificate File"), tunnel.CertificateFile, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("Certificate Command"), tunnel.CertificateCommand, 40, nil, nil).
 		SetFieldBackgroundColor(tcell.ColorBlack)
 
 	// Port Forwarding Section
 	form.AddTextView("", "", 0, 0, false, false) // Spacer
-	form.AddTextView("Port Forwarding", "[yellow]Port Forwarding[::-]", 0, 1, true, false)
+	form.AddTextView(tr("Port Forwarding"), tr("[yellow]Port Forwarding[::-]"), 0, 1, true, false)
 
-	form.AddInputField("Local Port", fmt.Sprintf("%d", tunnel.LocalPort), 10, func(textToCheck string, lastChar rune) bool {
+	form.AddInputField(tr("Local Port"), fmt.Sprintf("%d", tunnel.LocalPort), 10, func(textToCheck string, lastChar rune) bool {
 		if textToCheck == "" {
 			return true
 		}
@@ -253,23 +274,44 @@ func (a *App) createAdvancedTunnelForm(tunnel *core.Tunnel) *tview.Form {
 		return err == nil
 	}, nil).SetFieldBackgroundColor(tcell.ColorBlack)
 
+	// Bind address of local and dynamic forwards; for remote forwards the
+	// local side is the destination and stays on loopback
+	bindAddress := tunnel.LocalHost
+	if tunnel.Type == core.RemoteForward || tunnel.Type == core.ReverseDynamicForward {
+		bindAddress = core.DefaultBindAddress
+	}
+	form.AddInputField(tr("Bind Address"), bindAddress, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddTextView("", "", 0, 1, true, false)
+	portWarning := form.GetFormItem(form.GetFormItemCount() - 1).(*tview.TextView)
+	checkLocalPort = a.watchLocalPort(form, tunnel.ID, portWarning, func() bool {
+		return currentType == core.RemoteForward || currentType == core.ReverseDynamicForward
+	})
+
 	// Add remote fields only for non-dynamic tunnels
 	if currentType != core.DynamicForward {
-		form.AddInputField("Remote Host", tunnel.RemoteHost, 40, nil, nil).
+		form.AddInputField(tr("Remote Host"), tunnel.RemoteHost, 40, nil, nil).
 			SetFieldBackgroundColor(tcell.ColorBlack)
 
-		form.AddInputField("Remote Port", fmt.Sprintf("%d", tunnel.RemotePort), 10, func(textToCheck string, lastChar rune) bool {
+		form.AddInputField(tr("Remote Port"), fmt.Sprintf("%d", tunnel.RemotePort), 10, func(textToCheck string, lastChar rune) bool {
 			if textToCheck == "" {
 				return true
 			}
 			_, err := strconv.Atoi(textToCheck)
 			return err == nil
 		}, nil).SetFieldBackgroundColor(tcell.ColorBlack)
+
+		form.AddInputField(tr("Remote Bind Address (-R)"), tunnel.RemoteBindAddress, 40, nil, nil).
+			SetFieldBackgroundColor(tcell.ColorBlack)
 	}
 
+	form.AddInputField(tr("Additional Forwards"), core.FormatForwards(tunnel.Forwards), 50, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
 	// Options Section
 	form.AddTextView("", "", 0, 0, false, false) // Spacer
-	form.AddTextView("Options", "[yellow]Options[::-]", 0, 1, true, false)
+	form.AddTextView(tr("Options"), tr("[yellow]Options[::-]"), 0, 1, true, false)
 
 	// Profile selection
 	config, _ := a.configStore.LoadConfig()
@@ -293,38 +335,101 @@ func (a *App) createAdvancedTunnelForm(tunnel *core.Tunnel) *tview.Form {
 		}
 	}
 
-	form.AddDropDown("Profile", profileOptions, profileIndex, nil)
+	form.AddDropDown(tr("Profile"), profileOptions, profileIndex, nil)
+
+	form.AddInputField(tr("Tags"), strings.Join(tunnel.Tags, ", "), 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("Description"), tunnel.Description, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddCheckbox(tr("Auto-connect on startup"), tunnel.AutoConnect, nil)
+
+	form.AddCheckbox(tr("Compression (-C)"), tunnel.Compression, nil)
+
+	form.AddCheckbox(tr("Share connection (ControlMaster)"), tunnel.Multiplex, nil)
+
+	// Keepalive and forward failure settings; empty fields use the defaults
+	keepaliveInterval, keepaliveCount := "", ""
+	if tunnel.ServerAliveInterval != 0 {
+		keepaliveInterval = strconv.Itoa(tunnel.ServerAliveInterval)
+	}
+	if tunnel.ServerAliveCountMax != 0 {
+		keepaliveCount = strconv.Itoa(tunnel.ServerAliveCountMax)
+	}
+	form.AddInputField(tr("Keepalive Interval (s, -1 off)"), keepaliveInterval, 10, func(textToCheck string, lastChar rune) bool {
+		if textToCheck == "" || textToCheck == "-" {
+			return true
+		}
+		_, err := strconv.Atoi(textToCheck)
+		return err == nil
+	}, nil).SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("Keepalive Count"), keepaliveCount, 10, func(textToCheck string, lastChar rune) bool {
+		if textToCheck == "" {
+			return true
+		}
+		_, err := strconv.Atoi(textToCheck)
+		return err == nil
+	}, nil).SetFieldBackgroundColor(tcell.ColorBlack)
 
-	form.AddCheckbox("Auto-connect on startup", tunnel.AutoConnect, nil)
+	exitOptions := []string{tr("default"), tr("yes"), tr("no")}
+	exitIndex := 0
+	if tunnel.ExitOnForwardFailure != nil {
+		exitIndex = 2
+		if *tunnel.ExitOnForwardFailure {
+			exitIndex = 1
+		}
+	}
+	form.AddDropDown(tr("Exit on Forward Failure"), exitOptions, exitIndex, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
 
 	extraArgs := strings.Join(tunnel.ExtraArgs, " ")
-	form.AddInputField("Extra SSH Arguments", extraArgs, 50, nil, nil).
+	form.AddInputField(tr("Extra SSH Arguments"), extraArgs, 50, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	// Shell commands run on lifecycle events
+	form.AddInputField(tr("On Connect"), tunnel.Hooks.OnConnect, 50, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	form.AddInputField(tr("On Disconnect"), tunnel.Hooks.OnDisconnect, 50, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	form.AddInputField(tr("On Failure"), tunnel.Hooks.OnFailure, 50, nil, nil).
 		SetFieldBackgroundColor(tcell.ColorBlack)
 
 	// Buttons
-	form.AddButton("Save", func() {
-		if err := a.saveTunnelFromAdvancedForm(form, isNew, tunnel.ID, currentType); err != nil {
-			a.showErrorModal("Validation Error", err.Error())
+	form.AddButton(tr("Save"), func() {
+		edited, err := a.tunnelFromAdvancedForm(form, tunnel.ID, currentType)
+		if err != nil {
+			a.showErrorModal(tr("Validation Error"), err.Error())
 			return
 		}
-		if isNew {
-			a.pages.RemovePage("add-tunnel")
-			a.updateStatusBar("✓ Tunnel created successfully")
-		} else {
-			a.pages.RemovePage("edit-tunnel")
-			a.updateStatusBar("✓ Tunnel updated successfully")
+		save := func() {
+			if err := a.saveEditedTunnel(edited, isNew); err != nil {
+				a.showErrorModal(tr("Validation Error"), err.Error())
+				return
+			}
+			if isNew {
+				a.modals.pop("add-tunnel")
+				a.updateStatusBar(tr("✓ Tunnel created successfully"))
+			} else {
+				a.modals.pop("edit-tunnel")
+				a.updateStatusBar(tr("✓ Tunnel updated successfully"))
+			}
+			a.updateTunnelList()
 		}
-		a.app.SetFocus(a.tunnelList)
-		a.updateTunnelList()
+		if clashes := a.tunnelManager.ClashesWith(edited); len(clashes) > 0 {
+			a.confirmClashes(clashes, save)
+			return
+		}
+		save()
 	})
 
-	form.AddButton("Cancel", func() {
+	form.AddButton(tr("Cancel"), func() {
 		if isNew {
-			a.pages.RemovePage("add-tunnel")
+			a.modals.pop("add-tunnel")
 		} else {
-			a.pages.RemovePage("edit-tunnel")
+			a.modals.pop("edit-tunnel")
 		}
-		a.app.SetFocus(a.tunnelList)
 	})
 
 	// Set button colors
@@ -336,6 +441,73 @@ func (a *App) createAdvancedTunnelForm(tunnel *core.Tunnel) *tview.Form {
 	return form
 }
 
+// watchLocalPort shows in warning whether the local port of the form is taken
+// or used by another tunnel, checking again whenever the port or bind address
+// changes. The check may look up the owner of the port, so it runs in the
+// background once typing pauses. remote reports whether the local port is the
+// destination of a remote forward, which needs no check. It returns the
+// function that starts a check.
+func (a *App) watchLocalPort(form *tview.Form, tunnelID string, warning *tview.TextView, remote func() bool) func() {
+	portField := form.GetFormItemByLabel(tr("Local Port")).(*tview.InputField)
+	bindField := form.GetFormItemByLabel(tr("Bind Address")).(*tview.InputField)
+
+	var (
+		timer *time.Timer
+		check int
+	)
+	start := func() {
+		// Only the latest check may update the warning
+		check++
+		current := check
+		if timer != nil {
+			timer.Stop()
+		}
+		port, err := strconv.Atoi(portField.GetText())
+		if remote() || err != nil || port <= 0 {
+			warning.SetText("")
+			return
+		}
+		host := core.NormalizeHost(bindField.GetText())
+		timer = time.AfterFunc(300*time.Millisecond, func() {
+			names, err := a.tunnelManager.CheckLocalPort(tunnelID, host, port)
+			a.app.QueueUpdateDraw(func() {
+				if current == check {
+					warning.SetText(a.formatPortWarning(port, names, err))
+				}
+			})
+		})
+	}
+
+	portField.SetChangedFunc(func(string) { start() })
+	bindField.SetChangedFunc(func(string) { start() })
+	start()
+	return start
+}
+
+// formatPortWarning describes why a local port may not be usable, or returns
+// an empty string if nothing stands in the way
+func (a *App) formatPortWarning(port int, tunnels []string, err error) string {
+	var problems []string
+	var conflict *core.PortConflictError
+	if errors.As(err, &conflict) {
+		switch {
+		case conflict.TunnelName != "":
+			problems = append(problems, trf("Port %d is in use by tunnel '%s'", port, conflict.TunnelName))
+		case conflict.Owner != "":
+			problems = append(problems, trf("Port %d is in use by %s", port, conflict.Owner))
+		default:
+			problems = append(problems, trf("Port %d is in use by another process", port))
+		}
+	}
+	if len(tunnels) > 0 {
+		problems = append(problems, trf("Also configured for: %s", strings.Join(tunnels, ", ")))
+	}
+	if len(problems) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[%s]%s %s[-]", getColorName(a.colors.warn), a.symbols.warning, tview.Escape(strings.Join(problems, "; ")))
+}
+
 // updateFormFieldsForType updates form fields based on tunnel type
 func (a *App) updateFormFieldsForType(form *tview.Form, tunnelType core.TunnelType) {
 	// This is a simplified version - in a real implementation,
@@ -343,26 +515,73 @@ func (a *App) updateFormFieldsForType(form *tview.Form, tunnelType core.TunnelTy
 	// For now, we'll just update the help text
 	switch tunnelType {
 	case core.LocalForward:
-		form.SetTitle(" ✚ New Tunnel - Local Forward (-L) ")
+		form.SetTitle(trf(" %s New Tunnel - Local Forward (-L) ", a.symbols.add))
 	case core.RemoteForward:
-		form.SetTitle(" ✚ New Tunnel - Remote Forward (-R) ")
+		form.SetTitle(trf(" %s New Tunnel - Remote Forward (-R) ", a.symbols.add))
 	case core.DynamicForward:
-		form.SetTitle(" ✚ New Tunnel - Dynamic/SOCKS (-D) ")
+		form.SetTitle(trf(" %s New Tunnel - Dynamic/SOCKS (-D) ", a.symbols.add))
+	case core.ReverseDynamicForward:
+		form.SetTitle(trf(" %s New Tunnel - Reverse Dynamic/SOCKS (-R) ", a.symbols.add))
+	}
+}
+
+// confirmClashes warns that the tunnel of the form will fail to start while
+// others run, e.g. because it duplicates one, calling save if it is saved
+// anyway
+func (a *App) confirmClashes(clashes []core.ConfigIssue, save func()) {
+	var lines []string
+	for _, clash := range clashes {
+		lines = append(lines, "- "+clash.Message)
 	}
+	message := trf("%s This tunnel will fail to start while others run:\n\n%s\n\nSave anyway?",
+		a.symbols.warning, strings.Join(lines, "\n"))
+
+	modal := tview.NewModal().
+		SetText(message).
+		AddButtons([]string{tr("Save"), tr("Back")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.modals.pop("clash-confirm")
+			if buttonIndex == 0 {
+				save()
+			}
+		})
+
+	a.modals.push("clash-confirm", modal, modal)
 }
 
-// saveTunnelFromAdvancedForm extracts and saves tunnel data from the advanced form
-func (a *App) saveTunnelFromAdvancedForm(form *tview.Form, isNew bool, tunnelID string, tunnelType core.TunnelType) error {
+// tunnelFromAdvancedForm extracts and validates tunnel data from the advanced form
+func (a *App) tunnelFromAdvancedForm(form *tview.Form, tunnelID string, tunnelType core.TunnelType) (*core.Tunnel, error) {
 	// Extract form values
-	name := form.GetFormItemByLabel("Name").(*tview.InputField).GetText()
-	sshHost := form.GetFormItemByLabel("SSH Host").(*tview.InputField).GetText()
-	localPortStr := form.GetFormItemByLabel("Local Port").(*tview.InputField).GetText()
-	_, profileName := form.GetFormItemByLabel("Profile").(*tview.DropDown).GetCurrentOption()
-	autoConnect := form.GetFormItemByLabel("Auto-connect on startup").(*tview.Checkbox).IsChecked()
-	extraArgsStr := form.GetFormItemByLabel("Extra SSH Arguments").(*tview.InputField).GetText()
+	name := form.GetFormItemByLabel(tr("Name")).(*tview.InputField).GetText()
+	sshHost := form.GetFormItemByLabel(tr("SSH Host")).(*tview.InputField).GetText()
+	jumpHost := form.GetFormItemByLabel(tr("Jump Host")).(*tview.InputField).GetText()
+	identityFile := strings.TrimSpace(form.GetFormItemByLabel(tr("Identity File")).(*tview.InputField).GetText())
+	secret := strings.TrimSpace(form.GetFormItemByLabel(tr("Keychain Secret")).(*tview.InputField).GetText())
+	certificateFile := strings.TrimSpace(form.GetFormItemByLabel(tr("Certificate File")).(*tview.InputField).GetText())
+	certificateCommand := strings.TrimSpace(form.GetFormItemByLabel(tr("Certificate Command")).(*tview.InputField).GetText())
+	localPortStr := form.GetFormItemByLabel(tr("Local Port")).(*tview.InputField).GetText()
+	bindAddress := core.NormalizeHost(form.GetFormItemByLabel(tr("Bind Address")).(*tview.InputField).GetText())
+	_, profileName := form.GetFormItemByLabel(tr("Profile")).(*tview.DropDown).GetCurrentOption()
+	tags := core.ParseTags(form.GetFormItemByLabel(tr("Tags")).(*tview.InputField).GetText())
+	description := strings.TrimSpace(form.GetFormItemByLabel(tr("Description")).(*tview.InputField).GetText())
+	autoConnect := form.GetFormItemByLabel(tr("Auto-connect on startup")).(*tview.Checkbox).IsChecked()
+	compression := form.GetFormItemByLabel(tr("Compression (-C)")).(*tview.Checkbox).IsChecked()
+	multiplex := form.GetFormItemByLabel(tr("Share connection (ControlMaster)")).(*tview.Checkbox).IsChecked()
+	keepaliveIntervalStr := form.GetFormItemByLabel(tr("Keepalive Interval (s, -1 off)")).(*tview.InputField).GetText()
+	keepaliveCountStr := form.GetFormItemByLabel(tr("Keepalive Count")).(*tview.InputField).GetText()
+	_, exitOnFailure := form.GetFormItemByLabel(tr("Exit on Forward Failure")).(*tview.DropDown).GetCurrentOption()
+	extraArgsStr := form.GetFormItemByLabel(tr("Extra SSH Arguments")).(*tview.InputField).GetText()
+	forwardsStr := form.GetFormItemByLabel(tr("Additional Forwards")).(*tview.InputField).GetText()
+	hooks := core.Hooks{
+		OnConnect:    strings.TrimSpace(form.GetFormItemByLabel(tr("On Connect")).(*tview.InputField).GetText()),
+		OnDisconnect: strings.TrimSpace(form.GetFormItemByLabel(tr("On Disconnect")).(*tview.InputField).GetText()),
+		OnFailure:    strings.TrimSpace(form.GetFormItemByLabel(tr("On Failure")).(*tview.InputField).GetText()),
+	}
 
 	// Parse integers
 	localPort, _ := strconv.Atoi(localPortStr)
+	keepaliveInterval, _ := strconv.Atoi(keepaliveIntervalStr)
+	keepaliveCount, _ := strconv.Atoi(keepaliveCountStr)
 
 	// Create tunnel object
 	tunnel := &core.Tunnel{
@@ -370,10 +589,35 @@ func (a *App) saveTunnelFromAdvancedForm(form *tview.Form, isNew bool, tunnelID
 		Name:        name,
 		Type:        tunnelType,
 		SSHHost:     sshHost,
-		LocalHost:   "0.0.0.0",
+		JumpHost:    strings.TrimSpace(jumpHost),
+		LocalHost:   bindAddress,
 		LocalPort:   localPort,
 		Profile:     profileName,
+		Tags:        tags,
+		Description: description,
 		AutoConnect: autoConnect,
+		Compression: compression,
+		Multiplex:   multiplex,
+
+		IdentityFile: identityFile,
+		Secret:       secret,
+
+		CertificateFile:    certificateFile,
+		CertificateCommand: certificateCommand,
+
+		ServerAliveInterval: keepaliveInterval,
+		ServerAliveCountMax: keepaliveCount,
+
+		Hooks: hooks,
+	}
+	if exitOnFailure != tr("default") {
+		exit := exitOnFailure == tr("yes")
+		tunnel.ExitOnForwardFailure = &exit
+	}
+
+	// Remote forwards connect to the local port on loopback
+	if tunnelType == core.RemoteForward || tunnelType == core.ReverseDynamicForward || bindAddress == "" {
+		tunnel.LocalHost = core.DefaultBindAddress
 	}
 
 	// Parse extra arguments
@@ -381,25 +625,43 @@ func (a *App) saveTunnelFromAdvancedForm(form *tview.Form, isNew bool, tunnelID
 		tunnel.ExtraArgs = strings.Fields(extraArgsStr)
 	}
 
+	// Parse additional forwards, e.g. "-L 6379:redis:6379 -D 1080"
+	forwards, err := core.ParseForwards(forwardsStr)
+	if err != nil {
+		return nil, fmt.Errorf("additional forwards: %w", err)
+	}
+	tunnel.Forwards = forwards
+
 	// Handle type-specific fields
 	if tunnelType != core.DynamicForward {
-		remoteHost := form.GetFormItemByLabel("Remote Host").(*tview.InputField).GetText()
-		remotePortStr := form.GetFormItemByLabel("Remote Port").(*tview.InputField).GetText()
+		remoteHost := form.GetFormItemByLabel(tr("Remote Host")).(*tview.InputField).GetText()
+		remotePortStr := form.GetFormItemByLabel(tr("Remote Port")).(*tview.InputField).GetText()
 		remotePort, _ := strconv.Atoi(remotePortStr)
+		remoteBind := form.GetFormItemByLabel(tr("Remote Bind Address (-R)")).(*tview.InputField).GetText()
 
-		tunnel.RemoteHost = remoteHost
+		tunnel.RemoteHost = core.NormalizeHost(remoteHost)
 		tunnel.RemotePort = remotePort
+		if tunnelType == core.RemoteForward || tunnelType == core.ReverseDynamicForward {
+			tunnel.RemoteBindAddress = core.NormalizeHost(remoteBind)
+		}
 	}
 
 	// Validate
 	if err := tunnel.Validate(); err != nil {
-		return err
+		return nil, err
 	}
+	return tunnel, nil
+}
 
-	// Save
+// saveEditedTunnel saves a tunnel from the advanced form, keeping the host key
+// pinned from a prompt
+func (a *App) saveEditedTunnel(tunnel *core.Tunnel, isNew bool) error {
 	if isNew {
 		return a.tunnelManager.AddTunnel(tunnel)
 	}
+	if existing, err := a.tunnelManager.GetTunnel(tunnel.ID); err == nil {
+		tunnel.HostKey = existing.HostKey
+	}
 	return a.tunnelManager.UpdateTunnel(tunnel)
 }
 
@@ -409,14 +671,14 @@ func (a *App) showErrorModal(title, message string) {
 		SetDynamicColors(true).
 		SetTextAlign(tview.AlignCenter).
 		SetText(fmt.Sprintf(
-			"[red]✗ %s[::-]\n\n%s",
+			"[red]%s %s[::-]\n\n%s",
+			a.symbols.failed,
 			title,
 			message,
 		))
 
-	button := a.createButton("OK", func() {
-		a.pages.RemovePage("error")
-		a.app.SetFocus(a.tunnelList)
+	button := a.createButton(tr("OK"), func() {
+		a.modals.pop("error")
 	})
 
 	buttonContainer := tview.NewFlex().
@@ -431,13 +693,12 @@ func (a *App) showErrorModal(title, message string) {
 		AddItem(buttonContainer, 3, 0, true)
 
 	container.SetBorder(true).
-		SetTitle(" Error ").
+		SetTitle(tr(" Error ")).
 		SetTitleAlign(tview.AlignCenter).
 		SetBorderColor(tcell.ColorRed)
 
 	modal := a.createModalOverlay(container, 50, 12)
-	a.pages.AddPage("error", modal, true, true)
-	a.app.SetFocus(button)
+	a.modals.push("error", modal, button)
 }
 
 // createButton creates a styled button
@@ -461,4 +722,189 @@ func (a *App) createModalOverlay(content tview.Primitive, width, height int) *tv
 			AddItem(content, width, 1, true).
 			AddItem(nil, 0, 1, false), height, 1, true).
 		AddItem(nil, 0, 1, false)
-}
\ No newline at end of file
+}
+// promptResult is the user's answer to an ssh prompt
+type promptResult struct {
+	answer string
+	err    error
+}
+
+// promptSSH shows a question a tunnel's ssh asks while connecting and waits
+// for the answer. It runs outside the UI goroutine, and withdraws the prompt
+// when ssh stops waiting.
+func (a *App) promptSSH(ctx context.Context, prompt core.Prompt) (string, error) {
+	results := make(chan promptResult, 1)
+
+	// The modal stack returns focus to wherever it was, as the prompt can pop
+	// up over a form
+	closePrompt := func() {
+		a.modals.pop("ssh-prompt")
+	}
+
+	var once sync.Once
+	a.app.QueueUpdateDraw(func() {
+		a.showSSHPrompt(prompt, func(answer string, err error) {
+			once.Do(func() {
+				closePrompt()
+				results <- promptResult{answer: answer, err: err}
+			})
+		})
+	})
+
+	select {
+	case result := <-results:
+		return result.answer, result.err
+	case <-ctx.Done():
+		a.app.QueueUpdateDraw(closePrompt)
+		return "", ctx.Err()
+	}
+}
+
+// showSSHPrompt shows an ssh prompt, calling done with the answer
+func (a *App) showSSHPrompt(prompt core.Prompt, done func(answer string, err error)) {
+	message := tview.Escape(strings.TrimSpace(prompt.Message))
+
+	if hostKey, ok := core.ParseHostKeyPrompt(prompt.Message); ok {
+		a.showHostKeyPrompt(prompt, hostKey, done)
+		return
+	}
+
+	switch prompt.Kind {
+	case core.PromptConfirm, core.PromptNotice:
+		buttons := []string{tr("Yes"), tr("No")}
+		if prompt.Kind == core.PromptNotice {
+			buttons = []string{tr("Dismiss")}
+		}
+		modal := tview.NewModal().
+			SetText(fmt.Sprintf("[yellow]%s[::-]\n\n%s", tview.Escape(prompt.TunnelName), message)).
+			AddButtons(buttons).
+			SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+				switch buttonLabel {
+				case tr("Yes"):
+					done("yes", nil)
+				case tr("No"):
+					done("no", nil)
+				default:
+					done("", core.ErrPromptCancelled)
+				}
+			})
+		a.modals.push("ssh-prompt", modal, modal)
+		return
+	}
+
+	// Passwords, passphrases and one-time codes are typed masked
+	field := tview.NewInputField().
+		SetLabel(tr("Answer")).
+		SetFieldWidth(40).
+		SetMaskCharacter('*').
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form := tview.NewForm().
+		AddTextView(tr("Prompt"), message, 40, 2, true, false).
+		AddFormItem(field).
+		AddButton(tr("OK"), func() {
+			done(field.GetText(), nil)
+		}).
+		AddButton(tr("Cancel"), func() {
+			done("", core.ErrPromptCancelled)
+		})
+	form.SetCancelFunc(func() {
+		done("", core.ErrPromptCancelled)
+	})
+	// Answer on Enter rather than moving on to the buttons
+	field.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEnter:
+			done(field.GetText(), nil)
+			return nil
+		case tcell.KeyEscape:
+			done("", core.ErrPromptCancelled)
+			return nil
+		}
+		return event
+	})
+
+	form.SetBorder(true).
+		SetTitle(fmt.Sprintf(" 🔑 %s ", tview.Escape(prompt.TunnelName))).
+		SetTitleAlign(tview.AlignCenter).
+		SetBorderColor(tcell.ColorYellow)
+	form.SetButtonBackgroundColor(tcell.ColorBlue)
+	form.SetButtonTextColor(tcell.ColorWhite)
+	form.SetFieldTextColor(tcell.ColorWhite)
+	form.SetLabelColor(tcell.ColorYellow)
+
+	modal := a.createModalOverlay(form, 60, 11)
+	a.modals.push("ssh-prompt", modal, field)
+}
+
+// showHostKeyPrompt asks whether to trust the key of a host ssh has no key
+// for: accepting adds it to known_hosts, pinning also makes the tunnel refuse
+// any other key from then on
+func (a *App) showHostKeyPrompt(prompt core.Prompt, hostKey core.HostKeyPrompt, done func(answer string, err error)) {
+	// A pin applies to the tunnel's own SSH host, not to the jump hosts before it
+	buttons := []string{tr("Accept"), tr("Pin"), tr("Reject")}
+	if tunnel, err := a.tunnelManager.GetTunnel(prompt.TunnelID); err != nil || tunnel.JumpHost != "" {
+		buttons = []string{tr("Accept"), tr("Reject")}
+	}
+
+	text := trf("[yellow]%s[::-]\n\nUnknown host %s\n\n%s key fingerprint:\n%s\n\nAccept adds the key to known_hosts. Pin also makes this tunnel refuse any other key.",
+		tview.Escape(prompt.TunnelName), tview.Escape(hostKey.Host), tview.Escape(hostKey.KeyType), hostKey.Fingerprint)
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case tr("Pin"):
+				if err := a.tunnelManager.PinHostKey(prompt.TunnelID, hostKey.Fingerprint); err != nil {
+					core.Error("Failed to pin host key of tunnel %s: %v", prompt.TunnelName, err)
+				}
+				done("yes", nil)
+			case tr("Accept"):
+				done("yes", nil)
+			default:
+				done("no", nil)
+			}
+		})
+	a.modals.push("ssh-prompt", modal, modal)
+}
+
+// showHostKeyProblem explains a host key ssh refused, offering to forget the
+// known key and reconnect, which asks about the host's current key
+func (a *App) showHostKeyProblem(tunnel *core.Tunnel, problem *core.HostKeyProblem) {
+	if a.modals.isOpen("host-key") {
+		return
+	}
+
+	fingerprint := problem.Fingerprint
+	if fingerprint == "" {
+		fingerprint = tr("not shown by ssh")
+	}
+	var text string
+	if problem.Changed {
+		text = trf("[red]The host key of %s has changed![::-]\n\nThis can mean someone is intercepting the connection, or the host was reinstalled.\n\nNew key: %s",
+			tview.Escape(problem.Host), fingerprint)
+		if problem.KnownHostsFile != "" {
+			text += trf("\nKnown key: %s line %d", tview.Escape(problem.KnownHostsFile), problem.Line)
+		}
+	} else {
+		text = trf("[red]%s did not present the pinned host key[::-]\n\nPinned key: %s", tview.Escape(problem.Host), tunnel.HostKey)
+	}
+	text = trf("[yellow]%s[::-]\n\n%s\n\nForget the known key and reconnect only if you trust the change.", tview.Escape(tunnel.Name), text)
+
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{tr("Forget & Reconnect"), tr("Keep")}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			a.modals.pop("host-key")
+			if buttonLabel != tr("Forget & Reconnect") {
+				return
+			}
+			if err := a.tunnelManager.ForgetHostKey(tunnel.ID); err != nil {
+				a.showErrorModal(tr("Failed to Forget Host Key"), tview.Escape(err.Error()))
+				return
+			}
+			go a.tunnelManager.StartTunnel(tunnel.ID)
+		})
+	a.modals.push("host-key", modal, modal)
+}
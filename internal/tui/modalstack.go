@@ -0,0 +1,134 @@
+// Package tui provides the stack of dialogs open over the main page
+package tui
+
+import (
+	"slices"
+
+	"github.com/rivo/tview"
+)
+
+// openModal is a dialog on the modal stack
+type openModal struct {
+	page string
+
+	// item is the dialog's page, which has focus while the dialog is in front
+	item tview.Primitive
+
+	// focus is what the dialog gets focused on when it takes focus back
+	focus tview.Primitive
+
+	// previous had focus before the dialog opened and gets it back when the
+	// dialog closes
+	previous tview.Primitive
+
+	// keep hides the page on close rather than removing it, for pages added
+	// up front such as the help
+	keep bool
+}
+
+// modalStack tracks the dialogs open over the main page, front last. While a
+// dialog is open keys belong to it rather than to the shortcuts of the main
+// page, and closing it focuses whatever had focus before it opened, which
+// may be the dialog it was opened from.
+type modalStack struct {
+	app   *tview.Application
+	pages *tview.Pages
+	open  []*openModal
+}
+
+// newModalStack creates an empty modal stack for the pages of app
+func newModalStack(app *tview.Application, pages *tview.Pages) *modalStack {
+	return &modalStack{app: app, pages: pages}
+}
+
+// push adds a dialog page in front of everything and focuses focus. A dialog
+// of the same name is replaced, and its focus is restored on close.
+func (m *modalStack) push(page string, item, focus tview.Primitive) {
+	m.add(&openModal{page: page, item: item, focus: focus})
+	m.pages.AddPage(page, item, true, true)
+	m.app.SetFocus(focus)
+}
+
+// show shows a page that was added up front, such as the help, as a dialog
+func (m *modalStack) show(page string, item, focus tview.Primitive) {
+	m.add(&openModal{page: page, item: item, focus: focus, keep: true})
+	m.pages.ShowPage(page)
+	m.pages.SendToFront(page)
+	m.app.SetFocus(focus)
+}
+
+// add puts a dialog on top of the stack, taking over the focus to restore
+// from a dialog of the same name
+func (m *modalStack) add(modal *openModal) {
+	modal.previous = m.app.GetFocus()
+	if i := m.index(modal.page); i >= 0 {
+		modal.previous = m.open[i].previous
+		m.remove(i)
+	}
+	m.open = append(m.open, modal)
+}
+
+// pop closes a dialog. If it was in front, focus returns to what had it
+// before the dialog opened; closing one behind others leaves focus alone.
+// Closing a dialog that is not open does nothing.
+func (m *modalStack) pop(page string) {
+	i := m.index(page)
+	if i < 0 {
+		return
+	}
+	modal := m.open[i]
+	front := i == len(m.open)-1
+	m.remove(i)
+
+	if modal.keep {
+		m.pages.HidePage(page)
+	} else {
+		m.pages.RemovePage(page)
+	}
+	if front && modal.previous != nil {
+		m.app.SetFocus(modal.previous)
+	}
+}
+
+// remove takes a dialog off the stack. A dialog opened over it gets its focus
+// to restore, since the dialog it was opened from is gone.
+func (m *modalStack) remove(i int) {
+	if i+1 < len(m.open) {
+		m.open[i+1].previous = m.open[i].previous
+	}
+	m.open = slices.Delete(m.open, i, i+1)
+}
+
+// index returns the position of a dialog on the stack, or -1
+func (m *modalStack) index(page string) int {
+	return slices.IndexFunc(m.open, func(modal *openModal) bool {
+		return modal.page == page
+	})
+}
+
+// isOpen reports whether the named dialog is open
+func (m *modalStack) isOpen(page string) bool {
+	return m.index(page) >= 0
+}
+
+// front returns the name of the dialog in front, or an empty string
+func (m *modalStack) front() string {
+	if len(m.open) == 0 {
+		return ""
+	}
+	return m.open[len(m.open)-1].page
+}
+
+// captures reports whether a dialog is open and so gets the keys instead of
+// the main page. If focus has moved behind the dialog, e.g. to the tunnel
+// list after a refresh, it is given back first so the key reaches the dialog.
+func (m *modalStack) captures() bool {
+	if len(m.open) == 0 {
+		return false
+	}
+	modal := m.open[len(m.open)-1]
+	if !modal.item.HasFocus() {
+		m.app.SetFocus(modal.focus)
+	}
+	return true
+}
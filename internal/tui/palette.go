@@ -0,0 +1,286 @@
+// Package tui provides the command palette
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// paletteMaxResults is the number of matching commands the palette lists
+const paletteMaxResults = 50
+
+// paletteCommand is an action offered by the command palette
+type paletteCommand struct {
+	label  string
+	detail string
+	run    func()
+
+	// english is the untranslated label, which matches as well
+	english string
+}
+
+// newCommand creates a palette command labeled with a translated message
+func newCommand(detail string, run func(), format string, args ...any) paletteCommand {
+	return paletteCommand{label: trf(format, args...), detail: detail, run: run, english: fmt.Sprintf(format, args...)}
+}
+
+// paletteCommands returns the actions of the application and of the tunnels
+// in every profile
+func (a *App) paletteCommands() []paletteCommand {
+	commands := []paletteCommand{
+		newCommand("", a.showAddTunnelForm, "create tunnel"),
+		newCommand("", a.showSSHConfigImport, "import ssh config"),
+		newCommand(a.currentProfile, a.startAllTunnels, "start all tunnels"),
+		newCommand(a.currentProfile, a.stopAllTunnels, "stop all tunnels"),
+		newCommand("", a.showProfileManagement, "manage profiles"),
+		newCommand("", a.showFilterMenu, "filter view"),
+		newCommand("", a.clearFilter, "clear filter"),
+		newCommand("", a.toggleLogPane, "toggle log pane"),
+		newCommand("", a.toggleGroupView, "group by host"),
+		newCommand("", a.showDashboard, "dashboard"),
+		newCommand("", a.hideDashboard, "tunnel list"),
+		newCommand("", a.showActivity, "message history"),
+		newCommand("", a.showTagMenu, "show tags"),
+		newCommand("", a.showSettings, "settings"),
+		newCommand("", a.showSetupWizard, "setup wizard"),
+		newCommand("", a.undoConfigChange, "undo config change"),
+		newCommand("", a.showHelp, "help"),
+		newCommand("", a.confirmQuit, "quit"),
+	}
+	for _, field := range core.SortFields {
+		commands = append(commands, newCommand("", func() { a.sortBy(field) }, "sort by %s", field))
+	}
+	for _, deleted := range a.tunnelManager.DeletedTunnels() {
+		commands = append(commands, newCommand(trf("deleted %s", deleted.DeletedAt.Format("15:04:05")), func() { a.restoreTunnel(deleted.Config.ID) }, "restore %s", deleted.Config.Name))
+	}
+	for _, profile := range a.tunnelManager.GetProfileNames() {
+		if profile != a.currentProfile {
+			commands = append(commands, newCommand("", func() { a.switchProfile(profile) }, "switch profile %s", profile))
+		}
+	}
+
+	for _, tunnel := range a.tunnelManager.GetTunnels() {
+		profile := tunnel.Profile
+		if profile == "" {
+			profile = "default"
+		}
+		if tunnel.Status == core.StatusRunning {
+			commands = append(commands, newCommand(profile, func() { a.stopTunnelByID(tunnel.ID) }, "stop %s", tunnel.Name))
+		} else {
+			commands = append(commands, newCommand(profile, func() { a.startTunnelByID(tunnel.ID) }, "start %s", tunnel.Name))
+		}
+		commands = append(commands,
+			newCommand(profile, func() { a.goToTunnel(tunnel) }, "go to %s", tunnel.Name),
+			newCommand(profile, func() {
+				if a.goToTunnel(tunnel) {
+					a.showEditTunnelDialog()
+				}
+			}, "edit %s", tunnel.Name),
+			newCommand(profile, func() {
+				if a.goToTunnel(tunnel) {
+					a.showRenamePrompt()
+				}
+			}, "rename %s", tunnel.Name),
+			newCommand(profile, func() {
+				if a.goToTunnel(tunnel) {
+					a.showLocalPortPrompt()
+				}
+			}, "change local port %s", tunnel.Name),
+			newCommand(profile, func() { a.showCopyMenu(tunnel) }, "copy %s", tunnel.Name),
+			newCommand(profile, func() { a.showSSHCommand(tunnel) }, "view ssh command %s", tunnel.Name),
+		)
+	}
+	return commands
+}
+
+// goToTunnel selects a tunnel in the list, leaving the dashboard, switching
+// to the tunnel's profile and clearing a filter that hides it
+func (a *App) goToTunnel(tunnel *core.Tunnel) bool {
+	a.hideDashboard()
+	profile := tunnel.Profile
+	if profile == "" {
+		profile = "default"
+	}
+	if profile != a.currentProfile {
+		a.switchProfile(profile)
+	}
+	if !matchesFilter(tunnel, a.filter) {
+		a.FilterTunnels("")
+	}
+	a.selectTunnelByID(tunnel.ID)
+	return a.selectedTunnel != nil && a.selectedTunnel.ID == tunnel.ID
+}
+
+// showCommandPalette opens a palette that fuzzy-searches all actions and
+// tunnels and runs the chosen one
+func (a *App) showCommandPalette() {
+	commands := a.paletteCommands()
+	var matches []paletteCommand
+
+	list := tview.NewList().
+		ShowSecondaryText(false).
+		SetHighlightFullLine(true)
+
+	closePalette := func() {
+		a.modals.pop("palette")
+	}
+	runSelected := func() {
+		index := list.GetCurrentItem()
+		if index < 0 || index >= len(matches) {
+			return
+		}
+		closePalette()
+		matches[index].run()
+	}
+	update := func(query string) {
+		matches = matchCommands(commands, query)
+		list.Clear()
+		for _, command := range matches {
+			text := tview.Escape(command.label)
+			if command.detail != "" {
+				text += fmt.Sprintf("  [gray](%s)[-]", tview.Escape(command.detail))
+			}
+			list.AddItem(text, "", 0, nil)
+		}
+	}
+
+	input := tview.NewInputField().
+		SetLabel("> ").
+		SetLabelColor(tcell.ColorYellow).
+		SetFieldBackgroundColor(tcell.ColorBlack).
+		SetChangedFunc(update)
+
+	input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			closePalette()
+			return nil
+		case tcell.KeyEnter:
+			runSelected()
+			return nil
+		case tcell.KeyUp, tcell.KeyCtrlP:
+			if current := list.GetCurrentItem(); current > 0 {
+				list.SetCurrentItem(current - 1)
+			}
+			return nil
+		case tcell.KeyDown, tcell.KeyCtrlN, tcell.KeyTab:
+			if current := list.GetCurrentItem(); current < list.GetItemCount()-1 {
+				list.SetCurrentItem(current + 1)
+			}
+			return nil
+		}
+		return event
+	})
+	update("")
+
+	container := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(input, 1, 0, true).
+		AddItem(list, 0, 1, false)
+
+	container.SetBorder(true).
+		SetTitle(tr(" Command Palette (Enter: run, Esc: close) ")).
+		SetTitleAlign(tview.AlignCenter)
+
+	modal := a.createModalOverlay(container, 70, 20)
+	a.modals.push("palette", modal, input)
+}
+
+// matchCommands returns the commands matching a query, best matches first
+func matchCommands(commands []paletteCommand, query string) []paletteCommand {
+	type scored struct {
+		command paletteCommand
+		score   int
+	}
+
+	var results []scored
+	for _, command := range commands {
+		score, ok := fuzzyScore(command.label+" "+command.detail, query)
+		if english, englishOK := fuzzyScore(command.english+" "+command.detail, query); englishOK && (!ok || english > score) {
+			score, ok = english, true
+		}
+		if ok {
+			results = append(results, scored{command, score})
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+
+	if len(results) > paletteMaxResults {
+		results = results[:paletteMaxResults]
+	}
+	matches := make([]paletteCommand, len(results))
+	for i, result := range results {
+		matches[i] = result.command
+	}
+	return matches
+}
+
+// fuzzyScore reports whether every word of query matches text, as a
+// substring or as characters in order, and how well. Substrings, matches at
+// word starts and consecutive characters score higher.
+func fuzzyScore(text, query string) (int, bool) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return 0, true
+	}
+
+	text = strings.ToLower(text)
+	score := 0
+	for _, word := range words {
+		wordScore, ok := fuzzyWordScore(text, word)
+		if !ok {
+			return 0, false
+		}
+		score += wordScore
+	}
+	// Prefer shorter texts among equal matches
+	return score*100 - len(text), true
+}
+
+// fuzzyWordScore scores a single query word against text
+func fuzzyWordScore(text, word string) (int, bool) {
+	if i := strings.Index(text, word); i >= 0 {
+		score := 20 * utf8.RuneCountInString(word)
+		if previous, _ := utf8.DecodeLastRuneInString(text[:i]); i == 0 || !isWordRune(previous) {
+			score += 10
+		}
+		return score, true
+	}
+
+	runes, wanted := []rune(text), []rune(word)
+	score, matched, last := 0, 0, -2
+	for i, r := range runes {
+		if matched == len(wanted) {
+			break
+		}
+		if r != wanted[matched] {
+			continue
+		}
+		score += 2
+		if i == last+1 {
+			score += 3
+		}
+		if i == 0 || !isWordRune(runes[i-1]) {
+			score += 4
+		}
+		matched, last = matched+1, i
+	}
+	if matched < len(wanted) {
+		return 0, false
+	}
+	return score, true
+}
+
+// isWordRune reports whether r is part of a word rather than a separator
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
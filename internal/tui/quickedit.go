@@ -0,0 +1,100 @@
+// Package tui provides quick editing of single tunnel fields
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// showRenamePrompt asks for a new name for the selected tunnel
+func (a *App) showRenamePrompt() {
+	a.showQuickEdit(tr("Name"), func(tunnel *core.Tunnel) string {
+		return tunnel.Name
+	}, nil, func(tunnel *core.Tunnel, text string) error {
+		name := strings.TrimSpace(text)
+		if name == "" {
+			return errors.New(tr("name cannot be empty"))
+		}
+		tunnel.Name = name
+		return nil
+	})
+}
+
+// showLocalPortPrompt asks for a new local port for the selected tunnel
+func (a *App) showLocalPortPrompt() {
+	acceptPort := func(textToCheck string, lastChar rune) bool {
+		_, err := strconv.Atoi(textToCheck)
+		return textToCheck == "" || err == nil
+	}
+	a.showQuickEdit(tr("Local Port"), func(tunnel *core.Tunnel) string {
+		return strconv.Itoa(tunnel.LocalPort)
+	}, acceptPort, func(tunnel *core.Tunnel, text string) error {
+		port, err := strconv.Atoi(text)
+		if err != nil {
+			return errors.New(trf("invalid port: %q", text))
+		}
+		tunnel.LocalPort = port
+		return nil
+	})
+}
+
+// showQuickEdit shows a single-field prompt over the tunnel list that
+// changes one field of the selected tunnel and saves it on Enter
+func (a *App) showQuickEdit(label string, value func(*core.Tunnel) string, accept func(string, rune) bool, apply func(*core.Tunnel, string) error) {
+	if a.selectedTunnel == nil {
+		return
+	}
+	if a.selectedTunnel.Status == core.StatusRunning {
+		a.updateStatusBar(tr("⚠ Stop the tunnel before editing it"))
+		return
+	}
+	original := a.selectedTunnel
+
+	input := tview.NewInputField().
+		SetLabel(label + ": ").
+		SetText(value(original)).
+		SetFieldWidth(40).
+		SetAcceptanceFunc(accept).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	input.SetBorder(true).
+		SetTitle(trf(" %s (Enter: save, Esc: cancel) ", tview.Escape(original.Name))).
+		SetTitleAlign(tview.AlignCenter)
+
+	closePrompt := func() {
+		a.modals.pop("quick-edit")
+	}
+	input.SetDoneFunc(func(key tcell.Key) {
+		if key != tcell.KeyEnter {
+			closePrompt()
+			return
+		}
+		if input.GetText() == value(original) {
+			closePrompt()
+			return
+		}
+
+		tunnel := original.Clone()
+		if err := apply(tunnel, input.GetText()); err != nil {
+			a.updateStatusBar(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+			return
+		}
+		if err := a.tunnelManager.UpdateTunnel(tunnel); err != nil {
+			a.updateStatusBar(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+			return
+		}
+
+		closePrompt()
+		a.updateStatusBar(trf("✓ %s of %s changed to %s", label, tview.Escape(original.Name), tview.Escape(value(tunnel))))
+		a.selectedTunnel = tunnel
+		a.updateTunnelList()
+		a.updateDetailView(tunnel)
+	})
+
+	a.modals.push("quick-edit", a.createModalOverlay(input, 60, 3), input)
+}
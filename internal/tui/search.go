@@ -71,10 +71,10 @@ func (a *App) startSearch() {
 		AddItem(searchInput, 35, 0, true).
 		AddItem(tview.NewTextView().
 			SetDynamicColors(true).
-			SetText("[dim]ESC: cancel | TAB: next | Enter: select[::-]"), 0, 1, false)
+			SetText(tr("[dim]ESC: cancel | TAB: next | Enter: select[::-]")), 0, 1, false)
 
 	searchBar.SetBorder(true).
-		SetTitle(" Search ").
+		SetTitle(tr(" Search ")).
 		SetTitleAlign(tview.AlignLeft).
 		SetBorderColor(tcell.ColorYellow)
 
@@ -102,6 +102,7 @@ func (a *App) performSearch() {
 	a.searchMode.currentIndex = 0
 
 	tunnels := a.tunnelManager.GetTunnels()
+	core.SortTunnels(tunnels, a.sortField, a.sortDescending)
 
 	// Clear previous highlights
 	a.updateTunnelList()
@@ -123,11 +124,11 @@ func (a *App) performSearch() {
 
 	// Update status bar with search info
 	if len(a.searchMode.results) > 0 {
-		a.updateStatusBar(fmt.Sprintf("Search: %d result(s) for '%s'", len(a.searchMode.results), query))
+		a.updateStatusBar(trf("Search: %d result(s) for '%s'", len(a.searchMode.results), query))
 		// Select first result
 		a.selectTunnelByID(a.searchMode.results[0].ID)
 	} else {
-		a.updateStatusBar(fmt.Sprintf("Search: No results for '%s'", query))
+		a.updateStatusBar(trf("Search: No results for '%s'", query))
 	}
 }
 
@@ -142,6 +143,8 @@ func (a *App) matchesTunnel(tunnel *core.Tunnel, query string) bool {
 		fmt.Sprintf("%d", tunnel.RemotePort),
 		strings.ToLower(tunnel.RemoteHost),
 		strings.ToLower(string(tunnel.Status)),
+		strings.ToLower(strings.Join(tunnel.Tags, " ")),
+		strings.ToLower(tunnel.Description),
 	}
 
 	for _, field := range searchFields {
@@ -194,7 +197,7 @@ func (a *App) nextSearchResult() {
 	a.searchMode.currentIndex = (a.searchMode.currentIndex + 1) % len(a.searchMode.results)
 	tunnel := a.searchMode.results[a.searchMode.currentIndex]
 	a.selectTunnelByID(tunnel.ID)
-	a.updateStatusBar(fmt.Sprintf("Search result %d of %d", a.searchMode.currentIndex+1, len(a.searchMode.results)))
+	a.updateStatusBar(trf("Search result %d of %d", a.searchMode.currentIndex+1, len(a.searchMode.results)))
 }
 
 // selectSearchResult selects the current search result
@@ -207,8 +210,14 @@ func (a *App) selectSearchResult() {
 	a.selectTunnelByID(tunnel.ID)
 }
 
-// selectTunnelByID selects a tunnel in the list by its ID
+// selectTunnelByID selects a tunnel in the list by its ID, expanding its
+// host group if it is collapsed
 func (a *App) selectTunnelByID(tunnelID string) {
+	if tunnel, err := a.tunnelManager.GetTunnel(tunnelID); err == nil && a.groupByHost && a.collapsedHosts[tunnel.SSHHost] {
+		delete(a.collapsedHosts, tunnel.SSHHost)
+		a.updateTunnelList()
+	}
+
 	for row := 1; row < a.tunnelList.GetRowCount(); row++ {
 		cell := a.tunnelList.GetCell(row, 1)
 		if cell == nil {
@@ -239,61 +248,70 @@ func (a *App) exitSearch() {
 	a.updateStatusBar("")
 }
 
-// FilterTunnels filters tunnels based on various criteria
+// filterLabels describe the view filters in the list title
+var filterLabels = map[string]string{
+	"running": "running",
+	"stopped": "stopped",
+	"error":   "error",
+	"auto":    "auto-connect",
+	"local":   "local forward",
+	"remote":  "remote forward",
+	"dynamic": "dynamic",
+}
+
+// FilterTunnels limits the tunnel list to the tunnels matching filterType
+// until the filter is cleared; an empty filterType clears it
 func (a *App) FilterTunnels(filterType string) {
-	tunnels := a.tunnelManager.GetTunnels()
-	var filtered []*core.Tunnel
+	a.filter = filterType
+	a.updateTunnelList()
+	if filterType == "" {
+		a.updateStatusBar("")
+		return
+	}
+	a.updateStatusBar(trf("Filter: %s (%d tunnels) | x: clear filter", a.filterLabel(), a.shownTunnels))
+}
 
+// clearFilter shows all tunnels again
+func (a *App) clearFilter() {
+	if a.filter == "" {
+		return
+	}
+	a.FilterTunnels("")
+}
+
+// filterLabel describes the current view filter
+func (a *App) filterLabel() string {
+	if tag, ok := strings.CutPrefix(a.filter, "tag:"); ok {
+		return trf("tag %s", tag)
+	}
+	if label, ok := filterLabels[a.filter]; ok {
+		return tr(label)
+	}
+	return a.filter
+}
+
+// matchesFilter reports whether a tunnel is shown under a view filter
+func matchesFilter(t *core.Tunnel, filterType string) bool {
 	switch filterType {
+	case "":
+		return true
 	case "running":
-		for _, t := range tunnels {
-			if t.Status == core.StatusRunning {
-				filtered = append(filtered, t)
-			}
-		}
+		return t.Status == core.StatusRunning
 	case "stopped":
-		for _, t := range tunnels {
-			if t.Status == core.StatusStopped {
-				filtered = append(filtered, t)
-			}
-		}
+		return t.Status == core.StatusStopped
 	case "error":
-		for _, t := range tunnels {
-			if t.Status == core.StatusError {
-				filtered = append(filtered, t)
-			}
-		}
+		return t.Status == core.StatusError
 	case "auto":
-		for _, t := range tunnels {
-			if t.AutoConnect {
-				filtered = append(filtered, t)
-			}
-		}
+		return t.AutoConnect
 	case "local":
-		for _, t := range tunnels {
-			if t.Type == core.LocalForward {
-				filtered = append(filtered, t)
-			}
-		}
+		return t.Type == core.LocalForward
 	case "remote":
-		for _, t := range tunnels {
-			if t.Type == core.RemoteForward {
-				filtered = append(filtered, t)
-			}
-		}
+		return t.Type == core.RemoteForward || t.Type == core.ReverseDynamicForward
 	case "dynamic":
-		for _, t := range tunnels {
-			if t.Type == core.DynamicForward {
-				filtered = append(filtered, t)
-			}
-		}
-	default:
-		// No filter, show all
-		return
+		return t.Type == core.DynamicForward
 	}
-
-	// Update display with filtered results
-	a.searchMode.results = filtered
-	a.highlightSearchResults()
-	a.updateStatusBar(fmt.Sprintf("Filter: %s (%d tunnels)", filterType, len(filtered)))
-}
\ No newline at end of file
+	if tag, ok := strings.CutPrefix(filterType, "tag:"); ok {
+		return t.HasTag(tag)
+	}
+	return true
+}
@@ -0,0 +1,176 @@
+// Package tui provides the settings screen for the global defaults
+package tui
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// showSettings shows a form for the global defaults in the config
+func (a *App) showSettings() {
+	defaults := a.tunnelManager.GetDefaults()
+
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(tr(" Settings ")).
+		SetTitleAlign(tview.AlignCenter)
+
+	// Empty fields use the built-in settings
+	form.AddInputField(tr("Bind Address"), defaults.BindAddress, 30, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("Keepalive Interval (s, -1 off)"), formatSetting(defaults.ServerAliveInterval), 10, acceptInt, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	form.AddInputField(tr("Keepalive Count"), formatSetting(defaults.ServerAliveCountMax), 10, acceptInt, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	exitOptions := []string{tr("default"), tr("yes"), tr("no")}
+	exitIndex := 0
+	if defaults.ExitOnForwardFailure != nil {
+		exitIndex = 2
+		if *defaults.ExitOnForwardFailure {
+			exitIndex = 1
+		}
+	}
+	form.AddDropDown(tr("Exit on Forward Failure"), exitOptions, exitIndex, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	policyOptions := append([]string{tr("default")}, core.HostKeyPolicies...)
+	policyIndex := slices.Index(core.HostKeyPolicies, defaults.StrictHostKeyChecking) + 1
+	form.AddDropDown(tr("Host Key Checking"), policyOptions, policyIndex, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddInputField(tr("SSH Path"), defaults.SSHPath, 40, nil, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	// Restart delays of supervised tunnels
+	reconnect := store.ReconnectPolicy{}
+	if defaults.Reconnect != nil {
+		reconnect = *defaults.Reconnect
+	}
+	form.AddInputField(tr("Reconnect Delay (s)"), formatSetting(reconnect.InitialBackoff), 10, acceptInt, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	form.AddInputField(tr("Max Reconnect Delay (s)"), formatSetting(reconnect.MaxBackoff), 10, acceptInt, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+	form.AddInputField(tr("Stable After (s)"), formatSetting(reconnect.StableAfter), 10, acceptInt, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	form.AddCheckbox(tr("ASCII Icons and Borders"), defaults.ASCII, nil)
+	colorsOptions := make([]string, len(colorSchemeNames))
+	for i, name := range colorSchemeNames {
+		colorsOptions[i] = tr(name)
+	}
+	colorsIndex := max(0, slices.Index(colorSchemeNames, defaults.Colors))
+	form.AddDropDown(tr("Colors"), colorsOptions, colorsIndex, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	// The environment picks the language unless it is set
+	languageOptions := []string{tr("from LANG")}
+	for _, code := range languages {
+		languageOptions = append(languageOptions, languageNames[code])
+	}
+	languageIndex := slices.Index(languages, defaults.Language) + 1
+	form.AddDropDown(tr("Language"), languageOptions, languageIndex, nil).
+		SetFieldBackgroundColor(tcell.ColorBlack)
+
+	closeSettings := func() {
+		a.modals.pop("settings")
+	}
+
+	form.AddButton(tr("Save"), func() {
+		text := func(label string) string {
+			return strings.TrimSpace(form.GetFormItemByLabel(tr(label)).(*tview.InputField).GetText())
+		}
+		number := func(label string) int {
+			n, _ := strconv.Atoi(text(label))
+			return n
+		}
+		option := func(label string) (int, string) {
+			return form.GetFormItemByLabel(tr(label)).(*tview.DropDown).GetCurrentOption()
+		}
+
+		defaults.BindAddress = text("Bind Address")
+		defaults.ServerAliveInterval = number("Keepalive Interval (s, -1 off)")
+		defaults.ServerAliveCountMax = number("Keepalive Count")
+		defaults.SSHPath = text("SSH Path")
+		defaults.ASCII = form.GetFormItemByLabel(tr("ASCII Icons and Borders")).(*tview.Checkbox).IsChecked()
+		defaults.Colors = ""
+		if colors, _ := option("Colors"); colors > 0 {
+			defaults.Colors = colorSchemeNames[colors]
+		}
+		defaults.Language = ""
+		if language, _ := option("Language"); language > 0 {
+			defaults.Language = languages[language-1]
+		}
+
+		switch exit, _ := option("Exit on Forward Failure"); exit {
+		case 1, 2:
+			value := exit == 1
+			defaults.ExitOnForwardFailure = &value
+		default:
+			defaults.ExitOnForwardFailure = nil
+		}
+
+		defaults.StrictHostKeyChecking = ""
+		if policy, _ := option("Host Key Checking"); policy > 0 {
+			defaults.StrictHostKeyChecking = core.HostKeyPolicies[policy-1]
+		}
+
+		reconnect := store.ReconnectPolicy{
+			InitialBackoff: number("Reconnect Delay (s)"),
+			MaxBackoff:     number("Max Reconnect Delay (s)"),
+			StableAfter:    number("Stable After (s)"),
+		}
+		defaults.Reconnect = nil
+		if reconnect != (store.ReconnectPolicy{}) {
+			defaults.Reconnect = &reconnect
+		}
+
+		if err := a.tunnelManager.SetDefaults(defaults); err != nil {
+			a.showErrorModal(tr("Invalid Settings"), err.Error())
+			return
+		}
+		closeSettings()
+		a.applyAppearance()
+		a.translateViews()
+		a.updateTunnelList()
+		a.updateFooterBar()
+		a.updateHeaderBar()
+		a.updateStatusBar(tr("✓ Saved settings (running tunnels keep theirs until restarted)"))
+	})
+	form.AddButton(tr("Cancel"), closeSettings)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeSettings()
+			return nil
+		}
+		return event
+	})
+
+	modal := a.createModalOverlay(form, 60, 31)
+	a.modals.push("settings", modal, form)
+}
+
+// formatSetting shows an unset number as an empty field
+func formatSetting(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+// acceptInt lets an input field take only integers, possibly negative
+func acceptInt(textToCheck string, lastChar rune) bool {
+	if textToCheck == "" || textToCheck == "-" {
+		return true
+	}
+	_, err := strconv.Atoi(textToCheck)
+	return err == nil
+}
@@ -0,0 +1,72 @@
+// Package tui provides sorting of the tunnel list
+package tui
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// columnSortFields maps tunnel list columns to the field clicking their header sorts by
+var columnSortFields = map[int]core.SortField{
+	0: core.SortByStatus,
+	1: core.SortByName,
+	2: core.SortByHost,
+	4: core.SortByPort,
+	9: core.SortByUptime,
+}
+
+// sortBy sorts the tunnel list by field, reversing the order if it is
+// already sorted by it, and saves the choice
+func (a *App) sortBy(field core.SortField) {
+	if field == a.sortField {
+		a.sortDescending = !a.sortDescending
+	} else {
+		a.sortField, a.sortDescending = field, false
+	}
+	a.updateTunnelList()
+
+	if err := a.saveUIState(); err != nil {
+		a.updateStatusBar(trf("[red]Failed to save sort order: %v[-]", err))
+		return
+	}
+	a.updateStatusBar(trf("Sorted by %s", a.sortLabel()))
+}
+
+// cycleSortField sorts the tunnel list by the next sort field
+func (a *App) cycleSortField() {
+	next := (slices.Index(core.SortFields, a.sortField) + 1) % len(core.SortFields)
+	a.sortBy(core.SortFields[next])
+}
+
+// sortLabel describes the current sort order
+func (a *App) sortLabel() string {
+	return fmt.Sprintf("%s %s", tr(string(a.sortField)), a.sortArrow())
+}
+
+// sortArrow returns the arrow showing the sort direction
+func (a *App) sortArrow() string {
+	if a.sortDescending {
+		return a.symbols.descending
+	}
+	return a.symbols.ascending
+}
+
+// handleListMouse sorts the tunnel list when a column header is clicked
+func (a *App) handleListMouse(action tview.MouseAction, event *tcell.EventMouse) (tview.MouseAction, *tcell.EventMouse) {
+	if action != tview.MouseLeftClick {
+		return action, event
+	}
+	row, column := a.tunnelList.CellAt(event.Position())
+	if row != 0 {
+		return action, event
+	}
+	if field, ok := columnSortFields[column]; ok {
+		a.sortBy(field)
+		return tview.MouseConsumed, nil
+	}
+	return action, event
+}
@@ -0,0 +1,37 @@
+// Package tui provides the view choices kept between sessions
+package tui
+
+import (
+	"github.com/takaaki-s/tunnelman/internal/core"
+	"github.com/takaaki-s/tunnelman/internal/store"
+)
+
+// loadUIState restores the sort order and view choices of an earlier session
+func (a *App) loadUIState() {
+	a.sortField = core.SortByName
+	state, err := store.LoadUIState()
+	if err != nil {
+		core.Warn("Failed to load TUI state: %v", err)
+		return
+	}
+	a.groupByHost = state.GroupByHost
+	a.startOnDashboard = state.StartOnDashboard
+
+	field, err := core.ParseSortField(state.SortBy)
+	if err != nil {
+		core.Warn("Ignoring saved sort order: %v", err)
+		return
+	}
+	a.sortField, a.sortDescending = field, state.SortDescending
+}
+
+// saveUIState saves the sort order and view choices for the next session
+func (a *App) saveUIState() error {
+	return store.SaveUIState(&store.UIState{
+		SortBy:         string(a.sortField),
+		SortDescending: a.sortDescending,
+		GroupByHost:    a.groupByHost,
+
+		StartOnDashboard: a.startOnDashboard,
+	})
+}
@@ -0,0 +1,87 @@
+// Package tui provides the icons of the TUI and their ASCII equivalents
+package tui
+
+import (
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// symbolSet is the icons the TUI draws status, modes and markers with
+type symbolSet struct {
+	running, stopped, connecting, failed   string
+	local, remote, dynamic, reverseDynamic string
+
+	// notListening marks running tunnels whose local ports refuse connections
+	notListening string
+
+	arrow, warning        string
+	ascending, descending string
+	expanded, collapsed   string
+	add, edit             string
+
+	// messages replaces the icons of status bar messages; nil keeps them
+	messages *strings.Replacer
+}
+
+// unicodeSymbols are the icons drawn by default
+var unicodeSymbols = &symbolSet{
+	running: "●", stopped: "○", connecting: "◐", failed: "✗",
+	local: "→", remote: "←", dynamic: "⇄", reverseDynamic: "⇆",
+	notListening: "◌", arrow: "→", warning: "⚠",
+	ascending: "▲", descending: "▼",
+	expanded: "▾", collapsed: "▸",
+	add: "✚", edit: "✎",
+}
+
+// asciiSymbols are the icons drawn for terminals and fonts that render the
+// unicode ones badly
+var asciiSymbols = &symbolSet{
+	running: "*", stopped: "o", connecting: "~", failed: "x",
+	local: "->", remote: "<-", dynamic: "<->", reverseDynamic: "<=>",
+	notListening: "?", arrow: "->", warning: "!",
+	ascending: "^", descending: "v",
+	expanded: "-", collapsed: "+",
+	add: "+", edit: "*",
+	messages: strings.NewReplacer("✓", "OK", "✗", "x", "⚠", "!", "↶", "<-", "↻", "~", "→", "->", "←", "<-"),
+}
+
+// unicodeBorders are tview's default box drawing borders
+var unicodeBorders = tview.Borders
+
+// SetASCII draws the TUI with ASCII characters only, whatever the config says
+func (a *App) SetASCII(ascii bool) {
+	a.forceASCII = ascii
+}
+
+// applyAppearance picks the icons and borders from the --ascii flag and the
+// ascii setting of the config, and the colors and language from the config
+func (a *App) applyAppearance() {
+	defaults := a.tunnelManager.GetDefaults()
+	a.colors = colorSchemeNamed(defaults.Colors)
+	setLanguage(defaults.Language)
+
+	if a.forceASCII || defaults.ASCII {
+		a.symbols = asciiSymbols
+		tview.Borders.Horizontal, tview.Borders.HorizontalFocus = '-', '='
+		tview.Borders.Vertical, tview.Borders.VerticalFocus = '|', '|'
+		tview.Borders.TopLeft, tview.Borders.TopRight = '+', '+'
+		tview.Borders.BottomLeft, tview.Borders.BottomRight = '+', '+'
+		tview.Borders.TopLeftFocus, tview.Borders.TopRightFocus = '+', '+'
+		tview.Borders.BottomLeftFocus, tview.Borders.BottomRightFocus = '+', '+'
+		tview.Borders.LeftT, tview.Borders.RightT = '+', '+'
+		tview.Borders.TopT, tview.Borders.BottomT, tview.Borders.Cross = '+', '+', '+'
+		return
+	}
+	a.symbols = unicodeSymbols
+	tview.Borders = unicodeBorders
+}
+
+// statusMessage rewrites the icons of a status bar message for the symbols
+// in use
+func (a *App) statusMessage(message string) string {
+	if a.symbols.messages == nil {
+		return message
+	}
+	return a.symbols.messages.Replace(message)
+}
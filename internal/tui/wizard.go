@@ -0,0 +1,191 @@
+// Package tui provides the setup wizard shown on the first run
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/takaaki-s/tunnelman/internal/core"
+)
+
+// showSetupWizard guides a user without a config through importing the
+// forwards of their SSH config, creating a first tunnel and the main keys
+func (a *App) showSetupWizard() {
+	hosts, err := a.tunnelManager.LoadSSHConfigHosts()
+	if err != nil {
+		core.Debug("Setup wizard could not read the SSH config: %v", err)
+	}
+
+	intro := tr("This wizard imports the forwards of your SSH config and creates a tunnel.")
+	if !a.configStore.Exists() {
+		intro = tr("There is no config yet, so let's set up your first tunnels.")
+	}
+	found := tr("No hosts were found in ~/.ssh/config; tunnels can use any host ssh can reach.")
+	if len(hosts) > 0 {
+		found = trf("Found %d host(s) in ~/.ssh/config.", len(hosts))
+	}
+	a.showWizardStep(
+		trf("[::b]Welcome to tunnelman![::-]\n\n%s\n\n%s", intro, found),
+		[]string{tr("Next"), tr("Skip Setup")},
+		func(index int) {
+			if index != 0 {
+				a.finishSetupWizard()
+				return
+			}
+			a.showWizardImport(hosts)
+		})
+}
+
+// showWizardStep shows one step of the setup wizard as a dialog, calling
+// done with the index of the button pressed, or -1 on Esc
+func (a *App) showWizardStep(text string, buttons []string, done func(index int)) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			done(buttonIndex)
+		})
+	modal.SetTitle(tr(" Setup ")).SetBorder(true)
+
+	a.modals.push("wizard", modal, modal)
+}
+
+// showWizardImport offers to import the forwards defined in the SSH config
+func (a *App) showWizardImport(hosts []string) {
+	tunnels, err := a.tunnelManager.DiscoverSSHConfigTunnels()
+	if err != nil || len(tunnels) == 0 {
+		a.showWizardTunnel(hosts)
+		return
+	}
+
+	forwardHosts := make(map[string]bool)
+	for _, tunnel := range tunnels {
+		forwardHosts[tunnel.SSHHost] = true
+	}
+	a.showWizardStep(
+		trf("%d host(s) in your SSH config define forwards, which make %d tunnel(s).\n\nImport them into the default profile?", len(forwardHosts), len(tunnels)),
+		[]string{tr("Import"), tr("Skip")},
+		func(index int) {
+			if index == 0 {
+				added, err := a.tunnelManager.AddImportedTunnels(tunnels, "default")
+				if err != nil {
+					a.updateStatusBar(trf("[red]Import failed: %v[-]", err))
+				} else {
+					a.updateTunnelList()
+					a.updateStatusBar(trf("✓ Imported %d tunnel(s) from the SSH config", len(added)))
+				}
+			}
+			a.showWizardTunnel(hosts)
+		})
+}
+
+// showWizardTunnel asks for a first local forward, offering the hosts of the
+// SSH config
+func (a *App) showWizardTunnel(hosts []string) {
+	form := tview.NewForm()
+	form.SetBorder(true).
+		SetTitle(tr(" Setup: Create Your First Tunnel ")).
+		SetTitleAlign(tview.AlignCenter)
+
+	message := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText(tr("A local forward makes a port on the SSH host's side reachable on this machine."))
+
+	numeric := func(text string, lastChar rune) bool {
+		_, err := strconv.Atoi(text)
+		return err == nil
+	}
+	form.AddInputField(tr("Name"), "", 30, nil, nil)
+	if len(hosts) > 0 {
+		form.AddDropDown(tr("SSH Host"), hosts, 0, nil)
+	} else {
+		form.AddInputField(tr("SSH Host"), "", 30, nil, nil)
+	}
+	form.AddInputField(tr("Local Port"), "8080", 10, numeric, nil)
+	form.AddInputField(tr("Remote Host"), "localhost", 30, nil, nil)
+	form.AddInputField(tr("Remote Port"), "80", 10, numeric, nil)
+
+	form.AddButton(tr("Create"), func() {
+		var host string
+		switch item := form.GetFormItemByLabel(tr("SSH Host")).(type) {
+		case *tview.DropDown:
+			_, host = item.GetCurrentOption()
+		case *tview.InputField:
+			host = strings.TrimSpace(item.GetText())
+		}
+		name := strings.TrimSpace(form.GetFormItemByLabel(tr("Name")).(*tview.InputField).GetText())
+		if name == "" {
+			name = host
+		}
+		localPort, _ := strconv.Atoi(form.GetFormItemByLabel(tr("Local Port")).(*tview.InputField).GetText())
+		remotePort, _ := strconv.Atoi(form.GetFormItemByLabel(tr("Remote Port")).(*tview.InputField).GetText())
+
+		tunnel := &core.Tunnel{
+			ID:         core.NewTunnel(name, core.LocalForward).ID,
+			Name:       name,
+			Type:       core.LocalForward,
+			SSHHost:    host,
+			LocalHost:  a.tunnelManager.DefaultBindAddress(),
+			LocalPort:  localPort,
+			RemoteHost: core.NormalizeHost(form.GetFormItemByLabel(tr("Remote Host")).(*tview.InputField).GetText()),
+			RemotePort: remotePort,
+			Profile:    "default",
+		}
+		err := tunnel.Validate()
+		if err == nil {
+			err = a.tunnelManager.AddTunnel(tunnel)
+		}
+		if err != nil {
+			message.SetText(trf("[red]%s[-]", tview.Escape(err.Error())))
+			return
+		}
+		a.updateTunnelList()
+		a.updateStatusBar(trf("✓ Created tunnel %s", tview.Escape(name)))
+		a.showWizardKeys()
+	})
+	form.AddButton(tr("Skip"), a.showWizardKeys)
+
+	form.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			a.showWizardKeys()
+			return nil
+		}
+		return event
+	})
+
+	container := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(form, 0, 1, true).
+		AddItem(message, 2, 0, false)
+
+	a.modals.push("wizard", a.createModalOverlay(container, 60, 18), form)
+}
+
+// showWizardKeys explains the main keys and ends the setup wizard
+func (a *App) showWizardKeys() {
+	a.showWizardStep(
+		tr("[::b]The main keys[::-]\n\nEnter: start or stop the selected tunnel\nc: create a tunnel, e: edit it\ni: import from the SSH config\nb: dashboard of all profiles\nCtrl+P: command palette\n?: all keys, q: quit"),
+		[]string{tr("Start Using tunnelman")},
+		func(int) { a.finishSetupWizard() })
+}
+
+// finishSetupWizard closes the setup wizard and saves the config, so the
+// wizard is not shown again
+func (a *App) finishSetupWizard() {
+	a.modals.pop("wizard")
+	a.updateTunnelList()
+
+	if !a.configStore.Exists() {
+		config, err := a.configStore.LoadConfig()
+		if err == nil {
+			err = a.configStore.SaveConfig(config)
+		}
+		if err != nil {
+			a.updateStatusBar(trf("[red]Failed to save config: %v[-]", err))
+			return
+		}
+	}
+	a.updateStatusBar(tr("✓ Setup done | Press ? for all keys"))
+}